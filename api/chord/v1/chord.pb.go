@@ -0,0 +1,6221 @@
+// The Chord DHT API, version 1. See the compatibility policy in the
+// README: within chord.v1 fields and RPCs are only ever added, and the
+// protocol versions below are negotiated on every RPC.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: chord/v1/chord.proto
+
+package chordv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The range of protocol versions this revision of the API describes. Nodes
+// speak every version in it; clients that send their version as the
+// chord-protocol-version and chord-protocol-min-version gRPC metadata are
+// refused by nodes that share none of them.
+type ProtocolVersion int32
+
+const (
+	ProtocolVersion_PROTOCOL_VERSION_UNSPECIFIED ProtocolVersion = 0
+	ProtocolVersion_PROTOCOL_VERSION_MIN         ProtocolVersion = 2 // Oldest version nodes still speak
+	ProtocolVersion_PROTOCOL_VERSION_CURRENT     ProtocolVersion = 2 // Newest version
+)
+
+// Enum value maps for ProtocolVersion.
+var (
+	ProtocolVersion_name = map[int32]string{
+		0: "PROTOCOL_VERSION_UNSPECIFIED",
+		2: "PROTOCOL_VERSION_MIN",
+		// Duplicate value: 2: "PROTOCOL_VERSION_CURRENT",
+	}
+	ProtocolVersion_value = map[string]int32{
+		"PROTOCOL_VERSION_UNSPECIFIED": 0,
+		"PROTOCOL_VERSION_MIN":         2,
+		"PROTOCOL_VERSION_CURRENT":     2,
+	}
+)
+
+func (x ProtocolVersion) Enum() *ProtocolVersion {
+	p := new(ProtocolVersion)
+	*p = x
+	return p
+}
+
+func (x ProtocolVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProtocolVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_chord_v1_chord_proto_enumTypes[0].Descriptor()
+}
+
+func (ProtocolVersion) Type() protoreflect.EnumType {
+	return &file_chord_v1_chord_proto_enumTypes[0]
+}
+
+func (x ProtocolVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProtocolVersion.Descriptor instead.
+func (ProtocolVersion) EnumDescriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{0}
+}
+
+// Chord node representation
+type Node struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`           // SHA-1 hash as hex string
+	Address string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"` // IP:Port
+	// What the node advertises about itself; empty when unknown
+	Zone            string `protobuf:"bytes,3,opt,name=zone,proto3" json:"zone,omitempty"`                                               // Datacenter or availability zone label
+	Weight          uint32 `protobuf:"varint,4,opt,name=weight,proto3" json:"weight,omitempty"`                                          // Capacity relative to other nodes, 0 for the default of 1
+	ProtocolVersion uint32 `protobuf:"varint,5,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // Version of the Chord protocol the node speaks
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_chord_v1_chord_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Node) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Node) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Node) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+// Request/Response messages for FindSuccessor
+type FindSuccessorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Requester     *Node                  `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	Join          bool                   `protobuf:"varint,3,opt,name=join,proto3" json:"join,omitempty"`           // Set by a joining node; subject to the bootstrap's join limit
+	Serialize     bool                   `protobuf:"varint,4,opt,name=serialize,proto3" json:"serialize,omitempty"` // With join, the joining node reports JoinSettled, so the bootstrap holds later joins until then
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindSuccessorRequest) Reset() {
+	*x = FindSuccessorRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindSuccessorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindSuccessorRequest) ProtoMessage() {}
+
+func (x *FindSuccessorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindSuccessorRequest.ProtoReflect.Descriptor instead.
+func (*FindSuccessorRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FindSuccessorRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *FindSuccessorRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+func (x *FindSuccessorRequest) GetJoin() bool {
+	if x != nil {
+		return x.Join
+	}
+	return false
+}
+
+func (x *FindSuccessorRequest) GetSerialize() bool {
+	if x != nil {
+		return x.Serialize
+	}
+	return false
+}
+
+type FindSuccessorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Successor     *Node                  `protobuf:"bytes,1,opt,name=successor,proto3" json:"successor,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Hops          int32                  `protobuf:"varint,4,opt,name=hops,proto3" json:"hops,omitempty"`    // Times the lookup was forwarded to another node
+	Copies        []*Node                `protobuf:"bytes,5,rep,name=copies,proto3" json:"copies,omitempty"` // Nodes on the lookup path holding a hot copy of the key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindSuccessorResponse) Reset() {
+	*x = FindSuccessorResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindSuccessorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindSuccessorResponse) ProtoMessage() {}
+
+func (x *FindSuccessorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindSuccessorResponse.ProtoReflect.Descriptor instead.
+func (*FindSuccessorResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FindSuccessorResponse) GetSuccessor() *Node {
+	if x != nil {
+		return x.Successor
+	}
+	return nil
+}
+
+func (x *FindSuccessorResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *FindSuccessorResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FindSuccessorResponse) GetHops() int32 {
+	if x != nil {
+		return x.Hops
+	}
+	return 0
+}
+
+func (x *FindSuccessorResponse) GetCopies() []*Node {
+	if x != nil {
+		return x.Copies
+	}
+	return nil
+}
+
+// Request/Response messages for Notify
+type NotifyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Gossip        []*GossipEntry         `protobuf:"bytes,2,rep,name=gossip,proto3" json:"gossip,omitempty"` // Part of the notifier's membership view, empty with gossip off
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyRequest) Reset() {
+	*x = NotifyRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyRequest) ProtoMessage() {}
+
+func (x *NotifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyRequest.ProtoReflect.Descriptor instead.
+func (*NotifyRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NotifyRequest) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetGossip() []*GossipEntry {
+	if x != nil {
+		return x.Gossip
+	}
+	return nil
+}
+
+type NotifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Predecessor   *Node                  `protobuf:"bytes,3,opt,name=predecessor,proto3" json:"predecessor,omitempty"` // The notified node's predecessor after the notification
+	Replaced      *Node                  `protobuf:"bytes,4,opt,name=replaced,proto3" json:"replaced,omitempty"`       // The predecessor the notifier replaced, if it did
+	Gossip        []*GossipEntry         `protobuf:"bytes,5,rep,name=gossip,proto3" json:"gossip,omitempty"`           // Part of the notified node's membership view, empty with gossip off
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyResponse) Reset() {
+	*x = NotifyResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyResponse) ProtoMessage() {}
+
+func (x *NotifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyResponse.ProtoReflect.Descriptor instead.
+func (*NotifyResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NotifyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *NotifyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NotifyResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *NotifyResponse) GetReplaced() *Node {
+	if x != nil {
+		return x.Replaced
+	}
+	return nil
+}
+
+func (x *NotifyResponse) GetGossip() []*GossipEntry {
+	if x != nil {
+		return x.Gossip
+	}
+	return nil
+}
+
+// A node's metadata and health as spread by gossip
+type GossipEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Heartbeat     uint64                 `protobuf:"varint,2,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`                     // When the node described itself, in its own clock; only compared between entries of the same node
+	Pressure      int32                  `protobuf:"varint,3,opt,name=pressure,proto3" json:"pressure,omitempty"`                       // Resource pressure level of the node (0 normal, 1 elevated, 2 critical)
+	StoredKeys    int64                  `protobuf:"varint,4,opt,name=stored_keys,json=storedKeys,proto3" json:"stored_keys,omitempty"` // Entries in the node's store
+	MaxKeys       int64                  `protobuf:"varint,5,opt,name=max_keys,json=maxKeys,proto3" json:"max_keys,omitempty"`          // The node's storage limit in entries, 0 if unlimited
+	AgeMs         int64                  `protobuf:"varint,6,opt,name=age_ms,json=ageMs,proto3" json:"age_ms,omitempty"`                // How long ago the sender first heard this heartbeat, 0 from older senders
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GossipEntry) Reset() {
+	*x = GossipEntry{}
+	mi := &file_chord_v1_chord_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GossipEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GossipEntry) ProtoMessage() {}
+
+func (x *GossipEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GossipEntry.ProtoReflect.Descriptor instead.
+func (*GossipEntry) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GossipEntry) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GossipEntry) GetHeartbeat() uint64 {
+	if x != nil {
+		return x.Heartbeat
+	}
+	return 0
+}
+
+func (x *GossipEntry) GetPressure() int32 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+func (x *GossipEntry) GetStoredKeys() int64 {
+	if x != nil {
+		return x.StoredKeys
+	}
+	return 0
+}
+
+func (x *GossipEntry) GetMaxKeys() int64 {
+	if x != nil {
+		return x.MaxKeys
+	}
+	return 0
+}
+
+func (x *GossipEntry) GetAgeMs() int64 {
+	if x != nil {
+		return x.AgeMs
+	}
+	return 0
+}
+
+// Request/Response messages for JoinSettled
+type JoinSettledRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinSettledRequest) Reset() {
+	*x = JoinSettledRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinSettledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinSettledRequest) ProtoMessage() {}
+
+func (x *JoinSettledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinSettledRequest.ProtoReflect.Descriptor instead.
+func (*JoinSettledRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *JoinSettledRequest) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type JoinSettledResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinSettledResponse) Reset() {
+	*x = JoinSettledResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinSettledResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinSettledResponse) ProtoMessage() {}
+
+func (x *JoinSettledResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinSettledResponse.ProtoReflect.Descriptor instead.
+func (*JoinSettledResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{7}
+}
+
+// Request/Response messages for GetInfo
+type GetInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{8}
+}
+
+type GetInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Predecessor   *Node                  `protobuf:"bytes,2,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	Successor     *Node                  `protobuf:"bytes,3,opt,name=successor,proto3" json:"successor,omitempty"`
+	Fingers       []*Node                `protobuf:"bytes,4,rep,name=fingers,proto3" json:"fingers,omitempty"`
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Pressure      int32                  `protobuf:"varint,7,opt,name=pressure,proto3" json:"pressure,omitempty"` // Resource pressure level of the node (0 normal, 1 elevated, 2 critical)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoResponse) Reset() {
+	*x = GetInfoResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoResponse) ProtoMessage() {}
+
+func (x *GetInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetInfoResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetInfoResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetSuccessor() *Node {
+	if x != nil {
+		return x.Successor
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetFingers() []*Node {
+	if x != nil {
+		return x.Fingers
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetInfoResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetPressure() int32 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+// Request/Response messages for Ping
+type PingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requester     *Node                  `protobuf:"bytes,1,opt,name=requester,proto3" json:"requester,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PingRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+type PingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alive         bool                   `protobuf:"varint,1,opt,name=alive,proto3" json:"alive,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Pressure      int32                  `protobuf:"varint,3,opt,name=pressure,proto3" json:"pressure,omitempty"` // Resource pressure level of the node (0 normal, 1 elevated, 2 critical)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingResponse) ProtoMessage() {}
+
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PingResponse) GetAlive() bool {
+	if x != nil {
+		return x.Alive
+	}
+	return false
+}
+
+func (x *PingResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PingResponse) GetPressure() int32 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+// Additional messages for Closest Preceding Finger
+type ClosestPrecedingFingerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClosestPrecedingFingerRequest) Reset() {
+	*x = ClosestPrecedingFingerRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClosestPrecedingFingerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClosestPrecedingFingerRequest) ProtoMessage() {}
+
+func (x *ClosestPrecedingFingerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClosestPrecedingFingerRequest.ProtoReflect.Descriptor instead.
+func (*ClosestPrecedingFingerRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ClosestPrecedingFingerRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ClosestPrecedingFingerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClosestPrecedingFingerResponse) Reset() {
+	*x = ClosestPrecedingFingerResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClosestPrecedingFingerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClosestPrecedingFingerResponse) ProtoMessage() {}
+
+func (x *ClosestPrecedingFingerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClosestPrecedingFingerResponse.ProtoReflect.Descriptor instead.
+func (*ClosestPrecedingFingerResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ClosestPrecedingFingerResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *ClosestPrecedingFingerResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ClosestPrecedingFingerResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Key/value pair stored in the DHT
+type KeyValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyValue) Reset() {
+	*x = KeyValue{}
+	mi := &file_chord_v1_chord_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyValue) ProtoMessage() {}
+
+func (x *KeyValue) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyValue.ProtoReflect.Descriptor instead.
+func (*KeyValue) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *KeyValue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyValue) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// Request/Response messages for Put
+type PutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutRequest) Reset() {
+	*x = PutRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutRequest) ProtoMessage() {}
+
+func (x *PutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutRequest.ProtoReflect.Descriptor instead.
+func (*PutRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PutRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PutRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type PutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Buffered      bool                   `protobuf:"varint,3,opt,name=buffered,proto3" json:"buffered,omitempty"` // Held by an isolated node until it rejoins the ring
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutResponse) Reset() {
+	*x = PutResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutResponse) ProtoMessage() {}
+
+func (x *PutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResponse.ProtoReflect.Descriptor instead.
+func (*PutResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PutResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PutResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PutResponse) GetBuffered() bool {
+	if x != nil {
+		return x.Buffered
+	}
+	return false
+}
+
+// Request/Response messages for Get
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Replica       bool                   `protobuf:"varint,2,opt,name=replica,proto3" json:"replica,omitempty"` // Serve a replica copy if this node does not own the key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetRequest) GetReplica() bool {
+	if x != nil {
+		return x.Replica
+	}
+	return false
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Stale         bool                   `protobuf:"varint,5,opt,name=stale,proto3" json:"stale,omitempty"` // Served by an isolated node, may be outdated
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *GetResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *GetResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+// Request/Response messages for PutBatch
+type PutBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*KeyValue            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutBatchRequest) Reset() {
+	*x = PutBatchRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutBatchRequest) ProtoMessage() {}
+
+func (x *PutBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutBatchRequest.ProtoReflect.Descriptor instead.
+func (*PutBatchRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PutBatchRequest) GetItems() []*KeyValue {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PutBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Buffered      bool                   `protobuf:"varint,3,opt,name=buffered,proto3" json:"buffered,omitempty"` // Held by an isolated node until it rejoins the ring
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutBatchResponse) Reset() {
+	*x = PutBatchResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutBatchResponse) ProtoMessage() {}
+
+func (x *PutBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutBatchResponse.ProtoReflect.Descriptor instead.
+func (*PutBatchResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PutBatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PutBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PutBatchResponse) GetBuffered() bool {
+	if x != nil {
+		return x.Buffered
+	}
+	return false
+}
+
+// Request/Response messages for GetBatch
+type GetBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBatchRequest) Reset() {
+	*x = GetBatchRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchRequest) ProtoMessage() {}
+
+func (x *GetBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetBatchRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetBatchRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type GetBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*KeyValue            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Only keys that were found
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Stale         bool                   `protobuf:"varint,4,opt,name=stale,proto3" json:"stale,omitempty"` // Served by an isolated node, may be outdated
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBatchResponse) Reset() {
+	*x = GetBatchResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchResponse) ProtoMessage() {}
+
+func (x *GetBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetBatchResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetBatchResponse) GetItems() []*KeyValue {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *GetBatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetBatchResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+// Request/Response messages for ConditionalPut
+type ConditionalPutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Expected      []byte                 `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`                              // Required current value (ignored if expect_absent)
+	ExpectAbsent  bool                   `protobuf:"varint,4,opt,name=expect_absent,json=expectAbsent,proto3" json:"expect_absent,omitempty"` // Apply only if the key is absent or expired
+	TtlMs         int64                  `protobuf:"varint,5,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`                      // Lease duration; 0 means the value never expires
+	Delete        bool                   `protobuf:"varint,6,opt,name=delete,proto3" json:"delete,omitempty"`                                 // Remove the key instead of writing value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionalPutRequest) Reset() {
+	*x = ConditionalPutRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionalPutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionalPutRequest) ProtoMessage() {}
+
+func (x *ConditionalPutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionalPutRequest.ProtoReflect.Descriptor instead.
+func (*ConditionalPutRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ConditionalPutRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ConditionalPutRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ConditionalPutRequest) GetExpected() []byte {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *ConditionalPutRequest) GetExpectAbsent() bool {
+	if x != nil {
+		return x.ExpectAbsent
+	}
+	return false
+}
+
+func (x *ConditionalPutRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *ConditionalPutRequest) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+type ConditionalPutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Applied       bool                   `protobuf:"varint,1,opt,name=applied,proto3" json:"applied,omitempty"`
+	Current       []byte                 `protobuf:"bytes,2,opt,name=current,proto3" json:"current,omitempty"`  // Current value when the write was not applied
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"` // Version after the call; increases on every write
+	Success       bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionalPutResponse) Reset() {
+	*x = ConditionalPutResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionalPutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionalPutResponse) ProtoMessage() {}
+
+func (x *ConditionalPutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionalPutResponse.ProtoReflect.Descriptor instead.
+func (*ConditionalPutResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ConditionalPutResponse) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *ConditionalPutResponse) GetCurrent() []byte {
+	if x != nil {
+		return x.Current
+	}
+	return nil
+}
+
+func (x *ConditionalPutResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ConditionalPutResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ConditionalPutResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UndeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Route         bool                   `protobuf:"varint,2,opt,name=route,proto3" json:"route,omitempty"` // Forward to the key's owner instead of requiring this node to own it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteRequest) Reset() {
+	*x = UndeleteRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteRequest) ProtoMessage() {}
+
+func (x *UndeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UndeleteRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UndeleteRequest) GetRoute() bool {
+	if x != nil {
+		return x.Route
+	}
+	return false
+}
+
+type UndeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // Version of the restored value
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteResponse) Reset() {
+	*x = UndeleteResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteResponse) ProtoMessage() {}
+
+func (x *UndeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UndeleteResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UndeleteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UndeleteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for GetPeers (neighbor exchange)
+type GetPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requester     *Node                  `protobuf:"bytes,1,opt,name=requester,proto3" json:"requester,omitempty"`
+	MaxFingers    int32                  `protobuf:"varint,2,opt,name=max_fingers,json=maxFingers,proto3" json:"max_fingers,omitempty"` // Maximum number of sampled fingers to return
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPeersRequest) Reset() {
+	*x = GetPeersRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeersRequest) ProtoMessage() {}
+
+func (x *GetPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeersRequest.ProtoReflect.Descriptor instead.
+func (*GetPeersRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetPeersRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+func (x *GetPeersRequest) GetMaxFingers() int32 {
+	if x != nil {
+		return x.MaxFingers
+	}
+	return 0
+}
+
+type GetPeersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Predecessor   *Node                  `protobuf:"bytes,2,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	Successors    []*Node                `protobuf:"bytes,3,rep,name=successors,proto3" json:"successors,omitempty"` // Successor list, closest first
+	Fingers       []*Node                `protobuf:"bytes,4,rep,name=fingers,proto3" json:"fingers,omitempty"`       // Random sample of distinct finger entries
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPeersResponse) Reset() {
+	*x = GetPeersResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeersResponse) ProtoMessage() {}
+
+func (x *GetPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeersResponse.ProtoReflect.Descriptor instead.
+func (*GetPeersResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetPeersResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetPeersResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *GetPeersResponse) GetSuccessors() []*Node {
+	if x != nil {
+		return x.Successors
+	}
+	return nil
+}
+
+func (x *GetPeersResponse) GetFingers() []*Node {
+	if x != nil {
+		return x.Fingers
+	}
+	return nil
+}
+
+func (x *GetPeersResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetPeersResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for GetSuccessorList
+type GetSuccessorListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSuccessorListRequest) Reset() {
+	*x = GetSuccessorListRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSuccessorListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSuccessorListRequest) ProtoMessage() {}
+
+func (x *GetSuccessorListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSuccessorListRequest.ProtoReflect.Descriptor instead.
+func (*GetSuccessorListRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{29}
+}
+
+type GetSuccessorListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Predecessor   *Node                  `protobuf:"bytes,2,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	Successors    []*Node                `protobuf:"bytes,3,rep,name=successors,proto3" json:"successors,omitempty"` // Successor list, closest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSuccessorListResponse) Reset() {
+	*x = GetSuccessorListResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSuccessorListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSuccessorListResponse) ProtoMessage() {}
+
+func (x *GetSuccessorListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSuccessorListResponse.ProtoReflect.Descriptor instead.
+func (*GetSuccessorListResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetSuccessorListResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetSuccessorListResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *GetSuccessorListResponse) GetSuccessors() []*Node {
+	if x != nil {
+		return x.Successors
+	}
+	return nil
+}
+
+// An entry of a finger table
+type Finger struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Entry i points at the successor of node + 2^i
+	Start         string                 `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`  // node + 2^i
+	Node          *Node                  `protobuf:"bytes,3,opt,name=node,proto3" json:"node,omitempty"`    // Unset if the entry is empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Finger) Reset() {
+	*x = Finger{}
+	mi := &file_chord_v1_chord_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Finger) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Finger) ProtoMessage() {}
+
+func (x *Finger) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Finger.ProtoReflect.Descriptor instead.
+func (*Finger) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Finger) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Finger) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *Finger) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+// Request/Response messages for GetRoutingTable
+type GetRoutingTableRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoutingTableRequest) Reset() {
+	*x = GetRoutingTableRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoutingTableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoutingTableRequest) ProtoMessage() {}
+
+func (x *GetRoutingTableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoutingTableRequest.ProtoReflect.Descriptor instead.
+func (*GetRoutingTableRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{32}
+}
+
+type GetRoutingTableResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Predecessor   *Node                  `protobuf:"bytes,2,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	Successors    []*Node                `protobuf:"bytes,3,rep,name=successors,proto3" json:"successors,omitempty"` // Successor list, closest first
+	Fingers       []*Finger              `protobuf:"bytes,4,rep,name=fingers,proto3" json:"fingers,omitempty"`       // Every finger table entry, in index order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoutingTableResponse) Reset() {
+	*x = GetRoutingTableResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoutingTableResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoutingTableResponse) ProtoMessage() {}
+
+func (x *GetRoutingTableResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoutingTableResponse.ProtoReflect.Descriptor instead.
+func (*GetRoutingTableResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetRoutingTableResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetRoutingTableResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *GetRoutingTableResponse) GetSuccessors() []*Node {
+	if x != nil {
+		return x.Successors
+	}
+	return nil
+}
+
+func (x *GetRoutingTableResponse) GetFingers() []*Finger {
+	if x != nil {
+		return x.Fingers
+	}
+	return nil
+}
+
+// Request/Response messages for GetDensity (keyspace density estimation)
+type GetDensityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LocalOnly     bool                   `protobuf:"varint,1,opt,name=local_only,json=localOnly,proto3" json:"local_only,omitempty"` // Skip querying successors for the neighborhood estimate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDensityRequest) Reset() {
+	*x = GetDensityRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDensityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDensityRequest) ProtoMessage() {}
+
+func (x *GetDensityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDensityRequest.ProtoReflect.Descriptor instead.
+func (*GetDensityRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetDensityRequest) GetLocalOnly() bool {
+	if x != nil {
+		return x.LocalOnly
+	}
+	return false
+}
+
+type GetDensityResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Node                *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Predecessor         *Node                  `protobuf:"bytes,2,opt,name=predecessor,proto3" json:"predecessor,omitempty"`
+	KeyCount            int64                  `protobuf:"varint,3,opt,name=key_count,json=keyCount,proto3" json:"key_count,omitempty"`                                   // Live keys in (predecessor, node]
+	ArcFraction         float64                `protobuf:"fixed64,4,opt,name=arc_fraction,json=arcFraction,proto3" json:"arc_fraction,omitempty"`                         // Share of the keyspace in (predecessor, node]
+	Density             float64                `protobuf:"fixed64,5,opt,name=density,proto3" json:"density,omitempty"`                                                    // Local keys per unit keyspace
+	NeighborhoodDensity float64                `protobuf:"fixed64,6,opt,name=neighborhood_density,json=neighborhoodDensity,proto3" json:"neighborhood_density,omitempty"` // Density over this node and its successor list
+	Success             bool                   `protobuf:"varint,7,opt,name=success,proto3" json:"success,omitempty"`
+	Error               string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetDensityResponse) Reset() {
+	*x = GetDensityResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDensityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDensityResponse) ProtoMessage() {}
+
+func (x *GetDensityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDensityResponse.ProtoReflect.Descriptor instead.
+func (*GetDensityResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetDensityResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetDensityResponse) GetPredecessor() *Node {
+	if x != nil {
+		return x.Predecessor
+	}
+	return nil
+}
+
+func (x *GetDensityResponse) GetKeyCount() int64 {
+	if x != nil {
+		return x.KeyCount
+	}
+	return 0
+}
+
+func (x *GetDensityResponse) GetArcFraction() float64 {
+	if x != nil {
+		return x.ArcFraction
+	}
+	return 0
+}
+
+func (x *GetDensityResponse) GetDensity() float64 {
+	if x != nil {
+		return x.Density
+	}
+	return 0
+}
+
+func (x *GetDensityResponse) GetNeighborhoodDensity() float64 {
+	if x != nil {
+		return x.NeighborhoodDensity
+	}
+	return 0
+}
+
+func (x *GetDensityResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetDensityResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for RelayBroadcast
+type BroadcastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`     // Unique message ID, used to drop duplicates
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // Selects the registered handler
+	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Origin        *Node                  `protobuf:"bytes,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	Limit         string                 `protobuf:"bytes,5,opt,name=limit,proto3" json:"limit,omitempty"` // Receiver covers the nodes in (receiver, limit); hex ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BroadcastRequest) Reset() {
+	*x = BroadcastRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BroadcastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BroadcastRequest) ProtoMessage() {}
+
+func (x *BroadcastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BroadcastRequest.ProtoReflect.Descriptor instead.
+func (*BroadcastRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BroadcastRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BroadcastRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *BroadcastRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *BroadcastRequest) GetOrigin() *Node {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+func (x *BroadcastRequest) GetLimit() string {
+	if x != nil {
+		return x.Limit
+	}
+	return ""
+}
+
+type BroadcastResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reached       int32                  `protobuf:"varint,1,opt,name=reached,proto3" json:"reached,omitempty"` // Nodes that delivered the message in this subtree
+	Depth         int32                  `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`     // Depth of this subtree, 1 for a leaf
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BroadcastResponse) Reset() {
+	*x = BroadcastResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BroadcastResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BroadcastResponse) ProtoMessage() {}
+
+func (x *BroadcastResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BroadcastResponse.ProtoReflect.Descriptor instead.
+func (*BroadcastResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *BroadcastResponse) GetReached() int32 {
+	if x != nil {
+		return x.Reached
+	}
+	return 0
+}
+
+func (x *BroadcastResponse) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *BroadcastResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BroadcastResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A stored entry with its version and expiry, as moved by hand-offs and
+// replication
+type StoredEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	ExpiresAtMs   int64                  `protobuf:"varint,4,opt,name=expires_at_ms,json=expiresAtMs,proto3" json:"expires_at_ms,omitempty"` // Unix milliseconds, 0 if the entry never expires
+	DeletedAtMs   int64                  `protobuf:"varint,5,opt,name=deleted_at_ms,json=deletedAtMs,proto3" json:"deleted_at_ms,omitempty"` // Unix milliseconds, 0 unless the entry is a tombstone
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoredEntry) Reset() {
+	*x = StoredEntry{}
+	mi := &file_chord_v1_chord_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoredEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredEntry) ProtoMessage() {}
+
+func (x *StoredEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredEntry.ProtoReflect.Descriptor instead.
+func (*StoredEntry) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *StoredEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StoredEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *StoredEntry) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *StoredEntry) GetExpiresAtMs() int64 {
+	if x != nil {
+		return x.ExpiresAtMs
+	}
+	return 0
+}
+
+func (x *StoredEntry) GetDeletedAtMs() int64 {
+	if x != nil {
+		return x.DeletedAtMs
+	}
+	return 0
+}
+
+// Request/Response messages for the two-phase ownership hand-off
+type PrepareHandoffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requester     *Node                  `protobuf:"bytes,1,opt,name=requester,proto3" json:"requester,omitempty"` // Joining node taking over (start, requester]
+	Stream        bool                   `protobuf:"varint,2,opt,name=stream,proto3" json:"stream,omitempty"`      // Leave the entries to StreamHandoff
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareHandoffRequest) Reset() {
+	*x = PrepareHandoffRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareHandoffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareHandoffRequest) ProtoMessage() {}
+
+func (x *PrepareHandoffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareHandoffRequest.ProtoReflect.Descriptor instead.
+func (*PrepareHandoffRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *PrepareHandoffRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+func (x *PrepareHandoffRequest) GetStream() bool {
+	if x != nil {
+		return x.Stream
+	}
+	return false
+}
+
+type PrepareHandoffResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransferId    string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	Start         string                 `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"` // Exclusive start of the handed-off range; hex ID
+	Entries       []*StoredEntry         `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	Success       bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Streamed      bool                   `protobuf:"varint,6,opt,name=streamed,proto3" json:"streamed,omitempty"`                             // Entries are left out, to fetch with StreamHandoff
+	TotalEntries  uint64                 `protobuf:"varint,7,opt,name=total_entries,json=totalEntries,proto3" json:"total_entries,omitempty"` // Entries in the range
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareHandoffResponse) Reset() {
+	*x = PrepareHandoffResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareHandoffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareHandoffResponse) ProtoMessage() {}
+
+func (x *PrepareHandoffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareHandoffResponse.ProtoReflect.Descriptor instead.
+func (*PrepareHandoffResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PrepareHandoffResponse) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *PrepareHandoffResponse) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *PrepareHandoffResponse) GetEntries() []*StoredEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *PrepareHandoffResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PrepareHandoffResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PrepareHandoffResponse) GetStreamed() bool {
+	if x != nil {
+		return x.Streamed
+	}
+	return false
+}
+
+func (x *PrepareHandoffResponse) GetTotalEntries() uint64 {
+	if x != nil {
+		return x.TotalEntries
+	}
+	return 0
+}
+
+type StreamHandoffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransferId    string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	Requester     *Node                  `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	ResumeToken   string                 `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"` // Token of the last chunk received, empty to start over
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamHandoffRequest) Reset() {
+	*x = StreamHandoffRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamHandoffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamHandoffRequest) ProtoMessage() {}
+
+func (x *StreamHandoffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamHandoffRequest.ProtoReflect.Descriptor instead.
+func (*StreamHandoffRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *StreamHandoffRequest) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *StreamHandoffRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+func (x *StreamHandoffRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type HandoffChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*StoredEntry         `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	ResumeToken   string                 `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"` // Resumes the stream after this chunk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandoffChunk) Reset() {
+	*x = HandoffChunk{}
+	mi := &file_chord_v1_chord_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandoffChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandoffChunk) ProtoMessage() {}
+
+func (x *HandoffChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandoffChunk.ProtoReflect.Descriptor instead.
+func (*HandoffChunk) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *HandoffChunk) GetEntries() []*StoredEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *HandoffChunk) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type CommitHandoffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransferId    string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	Requester     *Node                  `protobuf:"bytes,2,opt,name=requester,proto3" json:"requester,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitHandoffRequest) Reset() {
+	*x = CommitHandoffRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitHandoffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitHandoffRequest) ProtoMessage() {}
+
+func (x *CommitHandoffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitHandoffRequest.ProtoReflect.Descriptor instead.
+func (*CommitHandoffRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CommitHandoffRequest) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *CommitHandoffRequest) GetRequester() *Node {
+	if x != nil {
+		return x.Requester
+	}
+	return nil
+}
+
+type CommitHandoffResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitHandoffResponse) Reset() {
+	*x = CommitHandoffResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitHandoffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitHandoffResponse) ProtoMessage() {}
+
+func (x *CommitHandoffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitHandoffResponse.ProtoReflect.Descriptor instead.
+func (*CommitHandoffResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CommitHandoffResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CommitHandoffResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for Replicate (owner to successor copies)
+type ReplicateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         *Node                  `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Entries       []*StoredEntry         `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicateRequest) Reset() {
+	*x = ReplicateRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicateRequest) ProtoMessage() {}
+
+func (x *ReplicateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicateRequest.ProtoReflect.Descriptor instead.
+func (*ReplicateRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ReplicateRequest) GetOwner() *Node {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *ReplicateRequest) GetEntries() []*StoredEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ReplicateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicateResponse) Reset() {
+	*x = ReplicateResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicateResponse) ProtoMessage() {}
+
+func (x *ReplicateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicateResponse.ProtoReflect.Descriptor instead.
+func (*ReplicateResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ReplicateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReplicateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Maintenance windows: pausing data migrations and replication
+type MaintenanceStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Node               *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Paused             bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	Reason             string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	SinceMs            int64                  `protobuf:"varint,4,opt,name=since_ms,json=sinceMs,proto3" json:"since_ms,omitempty"`                                  // When the pause started
+	PendingHandoff     bool                   `protobuf:"varint,5,opt,name=pending_handoff,json=pendingHandoff,proto3" json:"pending_handoff,omitempty"`             // The node waits to pull its key range
+	PendingReplication int64                  `protobuf:"varint,6,opt,name=pending_replication,json=pendingReplication,proto3" json:"pending_replication,omitempty"` // Keys whose copies to replicas were deferred
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MaintenanceStatus) Reset() {
+	*x = MaintenanceStatus{}
+	mi := &file_chord_v1_chord_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintenanceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintenanceStatus) ProtoMessage() {}
+
+func (x *MaintenanceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintenanceStatus.ProtoReflect.Descriptor instead.
+func (*MaintenanceStatus) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *MaintenanceStatus) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *MaintenanceStatus) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *MaintenanceStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *MaintenanceStatus) GetSinceMs() int64 {
+	if x != nil {
+		return x.SinceMs
+	}
+	return 0
+}
+
+func (x *MaintenanceStatus) GetPendingHandoff() bool {
+	if x != nil {
+		return x.PendingHandoff
+	}
+	return false
+}
+
+func (x *MaintenanceStatus) GetPendingReplication() int64 {
+	if x != nil {
+		return x.PendingReplication
+	}
+	return 0
+}
+
+type SetMaintenanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paused        bool                   `protobuf:"varint,1,opt,name=paused,proto3" json:"paused,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Ring          bool                   `protobuf:"varint,3,opt,name=ring,proto3" json:"ring,omitempty"` // Broadcast to every node instead of applying locally only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetMaintenanceRequest) Reset() {
+	*x = SetMaintenanceRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetMaintenanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceRequest) ProtoMessage() {}
+
+func (x *SetMaintenanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceRequest.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *SetMaintenanceRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *SetMaintenanceRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SetMaintenanceRequest) GetRing() bool {
+	if x != nil {
+		return x.Ring
+	}
+	return false
+}
+
+type SetMaintenanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *MaintenanceStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`    // Status of the contacted node before the change
+	Reached       int32                  `protobuf:"varint,2,opt,name=reached,proto3" json:"reached,omitempty"` // Nodes reached by a ring-wide change
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetMaintenanceResponse) Reset() {
+	*x = SetMaintenanceResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetMaintenanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceResponse) ProtoMessage() {}
+
+func (x *SetMaintenanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceResponse.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *SetMaintenanceResponse) GetStatus() *MaintenanceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *SetMaintenanceResponse) GetReached() int32 {
+	if x != nil {
+		return x.Reached
+	}
+	return 0
+}
+
+func (x *SetMaintenanceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetMaintenanceResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetMaintenanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMaintenanceRequest) Reset() {
+	*x = GetMaintenanceRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMaintenanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMaintenanceRequest) ProtoMessage() {}
+
+func (x *GetMaintenanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMaintenanceRequest.ProtoReflect.Descriptor instead.
+func (*GetMaintenanceRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{50}
+}
+
+type GetMaintenanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *MaintenanceStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMaintenanceResponse) Reset() {
+	*x = GetMaintenanceResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMaintenanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMaintenanceResponse) ProtoMessage() {}
+
+func (x *GetMaintenanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMaintenanceResponse.ProtoReflect.Descriptor instead.
+func (*GetMaintenanceResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetMaintenanceResponse) GetStatus() *MaintenanceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// Membership history: changes of predecessor and successor seen by a node
+type MembershipEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`                     // Increasing per node, starting at 1
+	TimeMs        int64                  `protobuf:"varint,2,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"` // Unix milliseconds
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`                    // "joined" or "left"
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`                    // "self", "predecessor" or "successor"
+	Node          *Node                  `protobuf:"bytes,5,opt,name=node,proto3" json:"node,omitempty"`
+	Previous      *Node                  `protobuf:"bytes,6,opt,name=previous,proto3" json:"previous,omitempty"` // The node it replaced, if any
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MembershipEvent) Reset() {
+	*x = MembershipEvent{}
+	mi := &file_chord_v1_chord_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MembershipEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MembershipEvent) ProtoMessage() {}
+
+func (x *MembershipEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MembershipEvent.ProtoReflect.Descriptor instead.
+func (*MembershipEvent) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *MembershipEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *MembershipEvent) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *MembershipEvent) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *MembershipEvent) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *MembershipEvent) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *MembershipEvent) GetPrevious() *Node {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+type GetMembershipHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceSeq      uint64                 `protobuf:"varint,1,opt,name=since_seq,json=sinceSeq,proto3" json:"since_seq,omitempty"` // Only return events after this one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMembershipHistoryRequest) Reset() {
+	*x = GetMembershipHistoryRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMembershipHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMembershipHistoryRequest) ProtoMessage() {}
+
+func (x *GetMembershipHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMembershipHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetMembershipHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetMembershipHistoryRequest) GetSinceSeq() uint64 {
+	if x != nil {
+		return x.SinceSeq
+	}
+	return 0
+}
+
+type GetMembershipHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Events        []*MembershipEvent     `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	FirstSeq      uint64                 `protobuf:"varint,3,opt,name=first_seq,json=firstSeq,proto3" json:"first_seq,omitempty"` // Oldest event still kept; older ones were dropped
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMembershipHistoryResponse) Reset() {
+	*x = GetMembershipHistoryResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMembershipHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMembershipHistoryResponse) ProtoMessage() {}
+
+func (x *GetMembershipHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMembershipHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetMembershipHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetMembershipHistoryResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GetMembershipHistoryResponse) GetEvents() []*MembershipEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetMembershipHistoryResponse) GetFirstSeq() uint64 {
+	if x != nil {
+		return x.FirstSeq
+	}
+	return 0
+}
+
+// Coordinated statistics sampling
+type StatsSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Epoch         uint64                 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	TimeMs        int64                  `protobuf:"varint,3,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"` // When the node took the sample
+	Messages      int64                  `protobuf:"varint,4,opt,name=messages,proto3" json:"messages,omitempty"`
+	Lookups       int64                  `protobuf:"varint,5,opt,name=lookups,proto3" json:"lookups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsSample) Reset() {
+	*x = StatsSample{}
+	mi := &file_chord_v1_chord_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsSample) ProtoMessage() {}
+
+func (x *StatsSample) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsSample.ProtoReflect.Descriptor instead.
+func (*StatsSample) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *StatsSample) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *StatsSample) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *StatsSample) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *StatsSample) GetMessages() int64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+func (x *StatsSample) GetLookups() int64 {
+	if x != nil {
+		return x.Lookups
+	}
+	return 0
+}
+
+type GetStatsSampleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Epoch         uint64                 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Start         bool                   `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"` // Start a new epoch across the ring and return this node's sample of it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsSampleRequest) Reset() {
+	*x = GetStatsSampleRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsSampleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsSampleRequest) ProtoMessage() {}
+
+func (x *GetStatsSampleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsSampleRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsSampleRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *GetStatsSampleRequest) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *GetStatsSampleRequest) GetStart() bool {
+	if x != nil {
+		return x.Start
+	}
+	return false
+}
+
+type GetStatsSampleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sample        *StatsSample           `protobuf:"bytes,1,opt,name=sample,proto3" json:"sample,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"` // False if the node has no sample of the epoch
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsSampleResponse) Reset() {
+	*x = GetStatsSampleResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsSampleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsSampleResponse) ProtoMessage() {}
+
+func (x *GetStatsSampleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsSampleResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsSampleResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetStatsSampleResponse) GetSample() *StatsSample {
+	if x != nil {
+		return x.Sample
+	}
+	return nil
+}
+
+func (x *GetStatsSampleResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+// Node statistics
+type NodeStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Messages            int64                  `protobuf:"varint,1,opt,name=messages,proto3" json:"messages,omitempty"`
+	Lookups             int64                  `protobuf:"varint,2,opt,name=lookups,proto3" json:"lookups,omitempty"`
+	Rpcs                map[string]int64       `protobuf:"bytes,3,rep,name=rpcs,proto3" json:"rpcs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // RPCs served by method name
+	BytesSent           int64                  `protobuf:"varint,4,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesReceived       int64                  `protobuf:"varint,5,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	ActiveConnections   int32                  `protobuf:"varint,6,opt,name=active_connections,json=activeConnections,proto3" json:"active_connections,omitempty"`
+	StoredKeys          int64                  `protobuf:"varint,7,opt,name=stored_keys,json=storedKeys,proto3" json:"stored_keys,omitempty"`
+	UptimeMs            int64                  `protobuf:"varint,8,opt,name=uptime_ms,json=uptimeMs,proto3" json:"uptime_ms,omitempty"`
+	LastStabilizationMs int64                  `protobuf:"varint,9,opt,name=last_stabilization_ms,json=lastStabilizationMs,proto3" json:"last_stabilization_ms,omitempty"` // Unix time of the last stabilization round, 0 if none
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *NodeStats) Reset() {
+	*x = NodeStats{}
+	mi := &file_chord_v1_chord_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeStats) ProtoMessage() {}
+
+func (x *NodeStats) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeStats.ProtoReflect.Descriptor instead.
+func (*NodeStats) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *NodeStats) GetMessages() int64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+func (x *NodeStats) GetLookups() int64 {
+	if x != nil {
+		return x.Lookups
+	}
+	return 0
+}
+
+func (x *NodeStats) GetRpcs() map[string]int64 {
+	if x != nil {
+		return x.Rpcs
+	}
+	return nil
+}
+
+func (x *NodeStats) GetBytesSent() int64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *NodeStats) GetBytesReceived() int64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *NodeStats) GetActiveConnections() int32 {
+	if x != nil {
+		return x.ActiveConnections
+	}
+	return 0
+}
+
+func (x *NodeStats) GetStoredKeys() int64 {
+	if x != nil {
+		return x.StoredKeys
+	}
+	return 0
+}
+
+func (x *NodeStats) GetUptimeMs() int64 {
+	if x != nil {
+		return x.UptimeMs
+	}
+	return 0
+}
+
+func (x *NodeStats) GetLastStabilizationMs() int64 {
+	if x != nil {
+		return x.LastStabilizationMs
+	}
+	return 0
+}
+
+type GetNodeStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeStatsRequest) Reset() {
+	*x = GetNodeStatsRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeStatsRequest) ProtoMessage() {}
+
+func (x *GetNodeStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeStatsRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{59}
+}
+
+type GetNodeStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stats         *NodeStats             `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeStatsResponse) Reset() {
+	*x = GetNodeStatsResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeStatsResponse) ProtoMessage() {}
+
+func (x *GetNodeStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeStatsResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *GetNodeStatsResponse) GetStats() *NodeStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+// Cache invalidation
+type AdvertiseCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`                       // Keys the caller caches copies of
+	LeaseMs       int64                  `protobuf:"varint,2,opt,name=lease_ms,json=leaseMs,proto3" json:"lease_ms,omitempty"` // How long to send invalidations for them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdvertiseCacheRequest) Reset() {
+	*x = AdvertiseCacheRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdvertiseCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdvertiseCacheRequest) ProtoMessage() {}
+
+func (x *AdvertiseCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdvertiseCacheRequest.ProtoReflect.Descriptor instead.
+func (*AdvertiseCacheRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *AdvertiseCacheRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *AdvertiseCacheRequest) GetLeaseMs() int64 {
+	if x != nil {
+		return x.LeaseMs
+	}
+	return 0
+}
+
+type AdvertiseCacheResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdvertiseCacheResponse) Reset() {
+	*x = AdvertiseCacheResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdvertiseCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdvertiseCacheResponse) ProtoMessage() {}
+
+func (x *AdvertiseCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdvertiseCacheResponse.ProtoReflect.Descriptor instead.
+func (*AdvertiseCacheResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *AdvertiseCacheResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AdvertiseCacheResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Replicated data types
+type UpdateCRDTRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`     // Data type of the key: counter or set
+	Delta         int64                  `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`  // Added to a counter, negative to decrement
+	Add           []string               `protobuf:"bytes,4,rep,name=add,proto3" json:"add,omitempty"`       // Elements added to a set
+	Remove        []string               `protobuf:"bytes,5,rep,name=remove,proto3" json:"remove,omitempty"` // Elements removed from a set
+	Merge         []byte                 `protobuf:"bytes,6,opt,name=merge,proto3" json:"merge,omitempty"`   // Encoded state merged in, as by read repair
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCRDTRequest) Reset() {
+	*x = UpdateCRDTRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCRDTRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCRDTRequest) ProtoMessage() {}
+
+func (x *UpdateCRDTRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCRDTRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCRDTRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *UpdateCRDTRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UpdateCRDTRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UpdateCRDTRequest) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *UpdateCRDTRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *UpdateCRDTRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+func (x *UpdateCRDTRequest) GetMerge() []byte {
+	if x != nil {
+		return x.Merge
+	}
+	return nil
+}
+
+type UpdateCRDTResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         []byte                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // Encoded state after the update
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCRDTResponse) Reset() {
+	*x = UpdateCRDTResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCRDTResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCRDTResponse) ProtoMessage() {}
+
+func (x *UpdateCRDTResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCRDTResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCRDTResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *UpdateCRDTResponse) GetState() []byte {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *UpdateCRDTResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateCRDTResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Tag index
+type QueryTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryTagRequest) Reset() {
+	*x = QueryTagRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryTagRequest) ProtoMessage() {}
+
+func (x *QueryTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryTagRequest.ProtoReflect.Descriptor instead.
+func (*QueryTagRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *QueryTagRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type QueryTagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"` // Keys indexed under the tag on its owner
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryTagResponse) Reset() {
+	*x = QueryTagResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryTagResponse) ProtoMessage() {}
+
+func (x *QueryTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryTagResponse.ProtoReflect.Descriptor instead.
+func (*QueryTagResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *QueryTagResponse) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *QueryTagResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *QueryTagResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Hot keys
+type CacheHotKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         *Node                  `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Items         []*KeyValue            `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`               // Hot keys to serve copies of
+	Dropped       []string               `protobuf:"bytes,3,rep,name=dropped,proto3" json:"dropped,omitempty"`           // Keys whose copies to drop
+	TtlMs         int64                  `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"` // How long the copies are served unless refreshed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheHotKeysRequest) Reset() {
+	*x = CacheHotKeysRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheHotKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheHotKeysRequest) ProtoMessage() {}
+
+func (x *CacheHotKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheHotKeysRequest.ProtoReflect.Descriptor instead.
+func (*CacheHotKeysRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *CacheHotKeysRequest) GetOwner() *Node {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *CacheHotKeysRequest) GetItems() []*KeyValue {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CacheHotKeysRequest) GetDropped() []string {
+	if x != nil {
+		return x.Dropped
+	}
+	return nil
+}
+
+func (x *CacheHotKeysRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type CacheHotKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheHotKeysResponse) Reset() {
+	*x = CacheHotKeysResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheHotKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheHotKeysResponse) ProtoMessage() {}
+
+func (x *CacheHotKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheHotKeysResponse.ProtoReflect.Descriptor instead.
+func (*CacheHotKeysResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *CacheHotKeysResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CacheHotKeysResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type HotKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Rate          float64                `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`    // Reads per second served by the owner
+	Copies        int32                  `protobuf:"varint,3,opt,name=copies,proto3" json:"copies,omitempty"` // Nodes holding a hot copy of the key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HotKey) Reset() {
+	*x = HotKey{}
+	mi := &file_chord_v1_chord_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotKey) ProtoMessage() {}
+
+func (x *HotKey) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotKey.ProtoReflect.Descriptor instead.
+func (*HotKey) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *HotKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *HotKey) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *HotKey) GetCopies() int32 {
+	if x != nil {
+		return x.Copies
+	}
+	return 0
+}
+
+type GetHotKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // Number of keys to return, hottest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHotKeysRequest) Reset() {
+	*x = GetHotKeysRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHotKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHotKeysRequest) ProtoMessage() {}
+
+func (x *GetHotKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHotKeysRequest.ProtoReflect.Descriptor instead.
+func (*GetHotKeysRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *GetHotKeysRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetHotKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*HotKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHotKeysResponse) Reset() {
+	*x = GetHotKeysResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHotKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHotKeysResponse) ProtoMessage() {}
+
+func (x *GetHotKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHotKeysResponse.ProtoReflect.Descriptor instead.
+func (*GetHotKeysResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *GetHotKeysResponse) GetKeys() []*HotKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Buckets
+type ListBucketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	After         string                 `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`  // List the keys sorted after this one
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // Number of keys to return, DefaultBucketPage if zero
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBucketRequest) Reset() {
+	*x = ListBucketRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBucketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBucketRequest) ProtoMessage() {}
+
+func (x *ListBucketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBucketRequest.ProtoReflect.Descriptor instead.
+func (*ListBucketRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *ListBucketRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *ListBucketRequest) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *ListBucketRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListBucketResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`  // Keys within the bucket, without its name
+	More          bool                   `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"` // More keys follow the last one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBucketResponse) Reset() {
+	*x = ListBucketResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBucketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBucketResponse) ProtoMessage() {}
+
+func (x *ListBucketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBucketResponse.ProtoReflect.Descriptor instead.
+func (*ListBucketResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *ListBucketResponse) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *ListBucketResponse) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+// Request/Response messages for key enumeration
+type ListKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`  // List only the keys starting with this prefix
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`  // Cursor of the previous page, empty for the first
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`   // Number of keys to return, DefaultKeyPage if zero
+	Values        bool                   `protobuf:"varint,4,opt,name=values,proto3" json:"values,omitempty"` // Return the keys' values as well
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKeysRequest) Reset() {
+	*x = ListKeysRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeysRequest) ProtoMessage() {}
+
+func (x *ListKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListKeysRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *ListKeysRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListKeysRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListKeysRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListKeysRequest) GetValues() bool {
+	if x != nil {
+		return x.Values
+	}
+	return false
+}
+
+type ListKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*KeyValue            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`                             // Owned keys in order, with values if asked
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Cursor of the next page, empty after the last
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKeysResponse) Reset() {
+	*x = ListKeysResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeysResponse) ProtoMessage() {}
+
+func (x *ListKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListKeysResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ListKeysResponse) GetItems() []*KeyValue {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListKeysResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type BucketStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Keys          int64                  `protobuf:"varint,2,opt,name=keys,proto3" json:"keys,omitempty"`   // Live keys owned by the node
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"` // Value bytes of those keys
+	Reads         int64                  `protobuf:"varint,4,opt,name=reads,proto3" json:"reads,omitempty"`
+	Writes        int64                  `protobuf:"varint,5,opt,name=writes,proto3" json:"writes,omitempty"`
+	MaxKeys       int64                  `protobuf:"varint,6,opt,name=max_keys,json=maxKeys,proto3" json:"max_keys,omitempty"` // Quota on the node, zero if unlimited
+	MaxBytes      int64                  `protobuf:"varint,7,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BucketStats) Reset() {
+	*x = BucketStats{}
+	mi := &file_chord_v1_chord_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BucketStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BucketStats) ProtoMessage() {}
+
+func (x *BucketStats) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BucketStats.ProtoReflect.Descriptor instead.
+func (*BucketStats) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *BucketStats) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *BucketStats) GetKeys() int64 {
+	if x != nil {
+		return x.Keys
+	}
+	return 0
+}
+
+func (x *BucketStats) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *BucketStats) GetReads() int64 {
+	if x != nil {
+		return x.Reads
+	}
+	return 0
+}
+
+func (x *BucketStats) GetWrites() int64 {
+	if x != nil {
+		return x.Writes
+	}
+	return 0
+}
+
+func (x *BucketStats) GetMaxKeys() int64 {
+	if x != nil {
+		return x.MaxKeys
+	}
+	return 0
+}
+
+func (x *BucketStats) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type GetBucketStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bucket        string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"` // Bucket to report, every bucket if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBucketStatsRequest) Reset() {
+	*x = GetBucketStatsRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBucketStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBucketStatsRequest) ProtoMessage() {}
+
+func (x *GetBucketStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBucketStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetBucketStatsRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *GetBucketStatsRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+type GetBucketStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Buckets       []*BucketStats         `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBucketStatsResponse) Reset() {
+	*x = GetBucketStatsResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBucketStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBucketStatsResponse) ProtoMessage() {}
+
+func (x *GetBucketStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBucketStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetBucketStatsResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *GetBucketStatsResponse) GetBuckets() []*BucketStats {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+// Node snapshots
+type GetSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnapshotRequest) Reset() {
+	*x = GetSnapshotRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRequest) ProtoMessage() {}
+
+func (x *GetSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{79}
+}
+
+type SnapshotChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"` // Next bytes of the snapshot written by Node.Snapshot
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	mi := &file_chord_v1_chord_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *SnapshotChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Coordinated ring snapshots
+type RingSnapshotPiece struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Id            uint64                 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	TimeMs        int64                  `protobuf:"varint,3,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`            // When the node took its piece
+	RangeStart    string                 `protobuf:"bytes,4,opt,name=range_start,json=rangeStart,proto3" json:"range_start,omitempty"` // Exclusive start of the key range in the piece, the node's predecessor; hex ID, empty if unknown
+	File          string                 `protobuf:"bytes,5,opt,name=file,proto3" json:"file,omitempty"`                               // Path of the piece on the node's host
+	Entries       int64                  `protobuf:"varint,6,opt,name=entries,proto3" json:"entries,omitempty"`
+	Bytes         int64                  `protobuf:"varint,7,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Checksum      string                 `protobuf:"bytes,8,opt,name=checksum,proto3" json:"checksum,omitempty"` // SHA-256 of the file, hex
+	Pending       bool                   `protobuf:"varint,9,opt,name=pending,proto3" json:"pending,omitempty"`  // The piece is still being written
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`      // Why the piece could not be written
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RingSnapshotPiece) Reset() {
+	*x = RingSnapshotPiece{}
+	mi := &file_chord_v1_chord_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RingSnapshotPiece) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RingSnapshotPiece) ProtoMessage() {}
+
+func (x *RingSnapshotPiece) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RingSnapshotPiece.ProtoReflect.Descriptor instead.
+func (*RingSnapshotPiece) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *RingSnapshotPiece) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *RingSnapshotPiece) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RingSnapshotPiece) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *RingSnapshotPiece) GetRangeStart() string {
+	if x != nil {
+		return x.RangeStart
+	}
+	return ""
+}
+
+func (x *RingSnapshotPiece) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *RingSnapshotPiece) GetEntries() int64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *RingSnapshotPiece) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *RingSnapshotPiece) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *RingSnapshotPiece) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+func (x *RingSnapshotPiece) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetRingSnapshotPieceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Start         bool                   `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"` // Start a new ring snapshot and return this node's piece of it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRingSnapshotPieceRequest) Reset() {
+	*x = GetRingSnapshotPieceRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRingSnapshotPieceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRingSnapshotPieceRequest) ProtoMessage() {}
+
+func (x *GetRingSnapshotPieceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRingSnapshotPieceRequest.ProtoReflect.Descriptor instead.
+func (*GetRingSnapshotPieceRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *GetRingSnapshotPieceRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetRingSnapshotPieceRequest) GetStart() bool {
+	if x != nil {
+		return x.Start
+	}
+	return false
+}
+
+type GetRingSnapshotPieceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Piece         *RingSnapshotPiece     `protobuf:"bytes,1,opt,name=piece,proto3" json:"piece,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"` // False if the node has no piece of the snapshot
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRingSnapshotPieceResponse) Reset() {
+	*x = GetRingSnapshotPieceResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRingSnapshotPieceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRingSnapshotPieceResponse) ProtoMessage() {}
+
+func (x *GetRingSnapshotPieceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRingSnapshotPieceResponse.ProtoReflect.Descriptor instead.
+func (*GetRingSnapshotPieceResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *GetRingSnapshotPieceResponse) GetPiece() *RingSnapshotPiece {
+	if x != nil {
+		return x.Piece
+	}
+	return nil
+}
+
+func (x *GetRingSnapshotPieceResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+// NAT traversal
+type CheckReachabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"` // Address the caller advertises
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckReachabilityRequest) Reset() {
+	*x = CheckReachabilityRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckReachabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckReachabilityRequest) ProtoMessage() {}
+
+func (x *CheckReachabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckReachabilityRequest.ProtoReflect.Descriptor instead.
+func (*CheckReachabilityRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *CheckReachabilityRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type CheckReachabilityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reachable     bool                   `protobuf:"varint,1,opt,name=reachable,proto3" json:"reachable,omitempty"` // The called node could call the caller back at address
+	Observed      string                 `protobuf:"bytes,2,opt,name=observed,proto3" json:"observed,omitempty"`    // Address the caller's request came from
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`          // Why the callback failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckReachabilityResponse) Reset() {
+	*x = CheckReachabilityResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckReachabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckReachabilityResponse) ProtoMessage() {}
+
+func (x *CheckReachabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckReachabilityResponse.ProtoReflect.Descriptor instead.
+func (*CheckReachabilityResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *CheckReachabilityResponse) GetReachable() bool {
+	if x != nil {
+		return x.Reachable
+	}
+	return false
+}
+
+func (x *CheckReachabilityResponse) GetObserved() string {
+	if x != nil {
+		return x.Observed
+	}
+	return ""
+}
+
+func (x *CheckReachabilityResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RelayHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayHeader) Reset() {
+	*x = RelayHeader{}
+	mi := &file_chord_v1_chord_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayHeader) ProtoMessage() {}
+
+func (x *RelayHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayHeader.ProtoReflect.Descriptor instead.
+func (*RelayHeader) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *RelayHeader) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RelayHeader) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// Frames of the Relay stream. The node behind NAT first sends its node;
+// the relay acknowledges with an empty frame, then sends calls (method set)
+// and hole-punching requests (punch_to set), which the node answers with
+// replies carrying the same call_id.
+type RelayFrame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	CallId        uint64                 `protobuf:"varint,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"` // Full method name of a relayed call
+	Headers       []*RelayHeader         `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`                // Request or reply message
+	Status        []byte                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`                  // google.rpc.Status of a failed call
+	PunchTo       string                 `protobuf:"bytes,7,opt,name=punch_to,json=punchTo,proto3" json:"punch_to,omitempty"` // UDP address to send hole-punching probes to
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayFrame) Reset() {
+	*x = RelayFrame{}
+	mi := &file_chord_v1_chord_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayFrame) ProtoMessage() {}
+
+func (x *RelayFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayFrame.ProtoReflect.Descriptor instead.
+func (*RelayFrame) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *RelayFrame) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *RelayFrame) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *RelayFrame) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RelayFrame) GetHeaders() []*RelayHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *RelayFrame) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *RelayFrame) GetStatus() []byte {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *RelayFrame) GetPunchTo() string {
+	if x != nil {
+		return x.PunchTo
+	}
+	return ""
+}
+
+type RendezvousRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"` // ID of a node registered with the relay; hex
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RendezvousRequest) Reset() {
+	*x = RendezvousRequest{}
+	mi := &file_chord_v1_chord_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RendezvousRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RendezvousRequest) ProtoMessage() {}
+
+func (x *RendezvousRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RendezvousRequest.ProtoReflect.Descriptor instead.
+func (*RendezvousRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *RendezvousRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type RendezvousResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"` // UDP address the target's QUIC traffic comes from
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RendezvousResponse) Reset() {
+	*x = RendezvousResponse{}
+	mi := &file_chord_v1_chord_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RendezvousResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RendezvousResponse) ProtoMessage() {}
+
+func (x *RendezvousResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_chord_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RendezvousResponse.ProtoReflect.Descriptor instead.
+func (*RendezvousResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_chord_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *RendezvousResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RendezvousResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RendezvousResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_chord_v1_chord_proto protoreflect.FileDescriptor
+
+const file_chord_v1_chord_proto_rawDesc = "" +
+	"\n" +
+	"\x14chord/v1/chord.proto\x12\bchord.v1\"\x87\x01\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04zone\x18\x03 \x01(\tR\x04zone\x12\x16\n" +
+	"\x06weight\x18\x04 \x01(\rR\x06weight\x12)\n" +
+	"\x10protocol_version\x18\x05 \x01(\rR\x0fprotocolVersion\"\x88\x01\n" +
+	"\x14FindSuccessorRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\trequester\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\trequester\x12\x12\n" +
+	"\x04join\x18\x03 \x01(\bR\x04join\x12\x1c\n" +
+	"\tserialize\x18\x04 \x01(\bR\tserialize\"\xb1\x01\n" +
+	"\x15FindSuccessorResponse\x12,\n" +
+	"\tsuccessor\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\tsuccessor\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04hops\x18\x04 \x01(\x05R\x04hops\x12&\n" +
+	"\x06copies\x18\x05 \x03(\v2\x0e.chord.v1.NodeR\x06copies\"b\n" +
+	"\rNotifyRequest\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12-\n" +
+	"\x06gossip\x18\x02 \x03(\v2\x15.chord.v1.GossipEntryR\x06gossip\"\xcd\x01\n" +
+	"\x0eNotifyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x120\n" +
+	"\vpredecessor\x18\x03 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12*\n" +
+	"\breplaced\x18\x04 \x01(\v2\x0e.chord.v1.NodeR\breplaced\x12-\n" +
+	"\x06gossip\x18\x05 \x03(\v2\x15.chord.v1.GossipEntryR\x06gossip\"\xbe\x01\n" +
+	"\vGossipEntry\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x1c\n" +
+	"\theartbeat\x18\x02 \x01(\x04R\theartbeat\x12\x1a\n" +
+	"\bpressure\x18\x03 \x01(\x05R\bpressure\x12\x1f\n" +
+	"\vstored_keys\x18\x04 \x01(\x03R\n" +
+	"storedKeys\x12\x19\n" +
+	"\bmax_keys\x18\x05 \x01(\x03R\amaxKeys\x12\x15\n" +
+	"\x06age_ms\x18\x06 \x01(\x03R\x05ageMs\"8\n" +
+	"\x12JoinSettledRequest\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\"\x15\n" +
+	"\x13JoinSettledResponse\"\x10\n" +
+	"\x0eGetInfoRequest\"\x8b\x02\n" +
+	"\x0fGetInfoResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x120\n" +
+	"\vpredecessor\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12,\n" +
+	"\tsuccessor\x18\x03 \x01(\v2\x0e.chord.v1.NodeR\tsuccessor\x12(\n" +
+	"\afingers\x18\x04 \x03(\v2\x0e.chord.v1.NodeR\afingers\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1a\n" +
+	"\bpressure\x18\a \x01(\x05R\bpressure\";\n" +
+	"\vPingRequest\x12,\n" +
+	"\trequester\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\trequester\"^\n" +
+	"\fPingResponse\x12\x14\n" +
+	"\x05alive\x18\x01 \x01(\bR\x05alive\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1a\n" +
+	"\bpressure\x18\x03 \x01(\x05R\bpressure\"1\n" +
+	"\x1dClosestPrecedingFingerRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"t\n" +
+	"\x1eClosestPrecedingFingerResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"2\n" +
+	"\bKeyValue\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"4\n" +
+	"\n" +
+	"PutRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"Y\n" +
+	"\vPutResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1a\n" +
+	"\bbuffered\x18\x03 \x01(\bR\bbuffered\"8\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\areplica\x18\x02 \x01(\bR\areplica\"\x7f\n" +
+	"\vGetResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x14\n" +
+	"\x05stale\x18\x05 \x01(\bR\x05stale\";\n" +
+	"\x0fPutBatchRequest\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.chord.v1.KeyValueR\x05items\"^\n" +
+	"\x10PutBatchResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1a\n" +
+	"\bbuffered\x18\x03 \x01(\bR\bbuffered\"%\n" +
+	"\x0fGetBatchRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"\x82\x01\n" +
+	"\x10GetBatchResponse\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.chord.v1.KeyValueR\x05items\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x14\n" +
+	"\x05stale\x18\x04 \x01(\bR\x05stale\"\xaf\x01\n" +
+	"\x15ConditionalPutRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\fR\bexpected\x12#\n" +
+	"\rexpect_absent\x18\x04 \x01(\bR\fexpectAbsent\x12\x15\n" +
+	"\x06ttl_ms\x18\x05 \x01(\x03R\x05ttlMs\x12\x16\n" +
+	"\x06delete\x18\x06 \x01(\bR\x06delete\"\x96\x01\n" +
+	"\x16ConditionalPutResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x01(\bR\aapplied\x12\x18\n" +
+	"\acurrent\x18\x02 \x01(\fR\acurrent\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"9\n" +
+	"\x0fUndeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05route\x18\x02 \x01(\bR\x05route\"\\\n" +
+	"\x10UndeleteResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"`\n" +
+	"\x0fGetPeersRequest\x12,\n" +
+	"\trequester\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\trequester\x12\x1f\n" +
+	"\vmax_fingers\x18\x02 \x01(\x05R\n" +
+	"maxFingers\"\xf2\x01\n" +
+	"\x10GetPeersResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x120\n" +
+	"\vpredecessor\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12.\n" +
+	"\n" +
+	"successors\x18\x03 \x03(\v2\x0e.chord.v1.NodeR\n" +
+	"successors\x12(\n" +
+	"\afingers\x18\x04 \x03(\v2\x0e.chord.v1.NodeR\afingers\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\x19\n" +
+	"\x17GetSuccessorListRequest\"\xa0\x01\n" +
+	"\x18GetSuccessorListResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x120\n" +
+	"\vpredecessor\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12.\n" +
+	"\n" +
+	"successors\x18\x03 \x03(\v2\x0e.chord.v1.NodeR\n" +
+	"successors\"X\n" +
+	"\x06Finger\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\tR\x05start\x12\"\n" +
+	"\x04node\x18\x03 \x01(\v2\x0e.chord.v1.NodeR\x04node\"\x18\n" +
+	"\x16GetRoutingTableRequest\"\xcb\x01\n" +
+	"\x17GetRoutingTableResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x120\n" +
+	"\vpredecessor\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12.\n" +
+	"\n" +
+	"successors\x18\x03 \x03(\v2\x0e.chord.v1.NodeR\n" +
+	"successors\x12*\n" +
+	"\afingers\x18\x04 \x03(\v2\x10.chord.v1.FingerR\afingers\"2\n" +
+	"\x11GetDensityRequest\x12\x1d\n" +
+	"\n" +
+	"local_only\x18\x01 \x01(\bR\tlocalOnly\"\xa7\x02\n" +
+	"\x12GetDensityResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x120\n" +
+	"\vpredecessor\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\vpredecessor\x12\x1b\n" +
+	"\tkey_count\x18\x03 \x01(\x03R\bkeyCount\x12!\n" +
+	"\farc_fraction\x18\x04 \x01(\x01R\varcFraction\x12\x18\n" +
+	"\adensity\x18\x05 \x01(\x01R\adensity\x121\n" +
+	"\x14neighborhood_density\x18\x06 \x01(\x01R\x13neighborhoodDensity\x12\x18\n" +
+	"\asuccess\x18\a \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\"\x8e\x01\n" +
+	"\x10BroadcastRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x12&\n" +
+	"\x06origin\x18\x04 \x01(\v2\x0e.chord.v1.NodeR\x06origin\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\tR\x05limit\"s\n" +
+	"\x11BroadcastResponse\x12\x18\n" +
+	"\areached\x18\x01 \x01(\x05R\areached\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x97\x01\n" +
+	"\vStoredEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\x12\"\n" +
+	"\rexpires_at_ms\x18\x04 \x01(\x03R\vexpiresAtMs\x12\"\n" +
+	"\rdeleted_at_ms\x18\x05 \x01(\x03R\vdeletedAtMs\"]\n" +
+	"\x15PrepareHandoffRequest\x12,\n" +
+	"\trequester\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\trequester\x12\x16\n" +
+	"\x06stream\x18\x02 \x01(\bR\x06stream\"\xf1\x01\n" +
+	"\x16PrepareHandoffResponse\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\tR\x05start\x12/\n" +
+	"\aentries\x18\x03 \x03(\v2\x15.chord.v1.StoredEntryR\aentries\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1a\n" +
+	"\bstreamed\x18\x06 \x01(\bR\bstreamed\x12#\n" +
+	"\rtotal_entries\x18\a \x01(\x04R\ftotalEntries\"\x88\x01\n" +
+	"\x14StreamHandoffRequest\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x12,\n" +
+	"\trequester\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\trequester\x12!\n" +
+	"\fresume_token\x18\x03 \x01(\tR\vresumeToken\"b\n" +
+	"\fHandoffChunk\x12/\n" +
+	"\aentries\x18\x01 \x03(\v2\x15.chord.v1.StoredEntryR\aentries\x12!\n" +
+	"\fresume_token\x18\x02 \x01(\tR\vresumeToken\"e\n" +
+	"\x14CommitHandoffRequest\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x12,\n" +
+	"\trequester\x18\x02 \x01(\v2\x0e.chord.v1.NodeR\trequester\"G\n" +
+	"\x15CommitHandoffResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"i\n" +
+	"\x10ReplicateRequest\x12$\n" +
+	"\x05owner\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x05owner\x12/\n" +
+	"\aentries\x18\x02 \x03(\v2\x15.chord.v1.StoredEntryR\aentries\"C\n" +
+	"\x11ReplicateResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xdc\x01\n" +
+	"\x11MaintenanceStatus\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x19\n" +
+	"\bsince_ms\x18\x04 \x01(\x03R\asinceMs\x12'\n" +
+	"\x0fpending_handoff\x18\x05 \x01(\bR\x0ependingHandoff\x12/\n" +
+	"\x13pending_replication\x18\x06 \x01(\x03R\x12pendingReplication\"[\n" +
+	"\x15SetMaintenanceRequest\x12\x16\n" +
+	"\x06paused\x18\x01 \x01(\bR\x06paused\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04ring\x18\x03 \x01(\bR\x04ring\"\x97\x01\n" +
+	"\x16SetMaintenanceResponse\x123\n" +
+	"\x06status\x18\x01 \x01(\v2\x1b.chord.v1.MaintenanceStatusR\x06status\x12\x18\n" +
+	"\areached\x18\x02 \x01(\x05R\areached\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x17\n" +
+	"\x15GetMaintenanceRequest\"M\n" +
+	"\x16GetMaintenanceResponse\x123\n" +
+	"\x06status\x18\x01 \x01(\v2\x1b.chord.v1.MaintenanceStatusR\x06status\"\xb4\x01\n" +
+	"\x0fMembershipEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x17\n" +
+	"\atime_ms\x18\x02 \x01(\x03R\x06timeMs\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\"\n" +
+	"\x04node\x18\x05 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12*\n" +
+	"\bprevious\x18\x06 \x01(\v2\x0e.chord.v1.NodeR\bprevious\":\n" +
+	"\x1bGetMembershipHistoryRequest\x12\x1b\n" +
+	"\tsince_seq\x18\x01 \x01(\x04R\bsinceSeq\"\x92\x01\n" +
+	"\x1cGetMembershipHistoryResponse\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x121\n" +
+	"\x06events\x18\x02 \x03(\v2\x19.chord.v1.MembershipEventR\x06events\x12\x1b\n" +
+	"\tfirst_seq\x18\x03 \x01(\x04R\bfirstSeq\"\x96\x01\n" +
+	"\vStatsSample\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x14\n" +
+	"\x05epoch\x18\x02 \x01(\x04R\x05epoch\x12\x17\n" +
+	"\atime_ms\x18\x03 \x01(\x03R\x06timeMs\x12\x1a\n" +
+	"\bmessages\x18\x04 \x01(\x03R\bmessages\x12\x18\n" +
+	"\alookups\x18\x05 \x01(\x03R\alookups\"C\n" +
+	"\x15GetStatsSampleRequest\x12\x14\n" +
+	"\x05epoch\x18\x01 \x01(\x04R\x05epoch\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\bR\x05start\"]\n" +
+	"\x16GetStatsSampleResponse\x12-\n" +
+	"\x06sample\x18\x01 \x01(\v2\x15.chord.v1.StatsSampleR\x06sample\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"\x94\x03\n" +
+	"\tNodeStats\x12\x1a\n" +
+	"\bmessages\x18\x01 \x01(\x03R\bmessages\x12\x18\n" +
+	"\alookups\x18\x02 \x01(\x03R\alookups\x121\n" +
+	"\x04rpcs\x18\x03 \x03(\v2\x1d.chord.v1.NodeStats.RpcsEntryR\x04rpcs\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\x04 \x01(\x03R\tbytesSent\x12%\n" +
+	"\x0ebytes_received\x18\x05 \x01(\x03R\rbytesReceived\x12-\n" +
+	"\x12active_connections\x18\x06 \x01(\x05R\x11activeConnections\x12\x1f\n" +
+	"\vstored_keys\x18\a \x01(\x03R\n" +
+	"storedKeys\x12\x1b\n" +
+	"\tuptime_ms\x18\b \x01(\x03R\buptimeMs\x122\n" +
+	"\x15last_stabilization_ms\x18\t \x01(\x03R\x13lastStabilizationMs\x1a7\n" +
+	"\tRpcsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x15\n" +
+	"\x13GetNodeStatsRequest\"A\n" +
+	"\x14GetNodeStatsResponse\x12)\n" +
+	"\x05stats\x18\x01 \x01(\v2\x13.chord.v1.NodeStatsR\x05stats\"F\n" +
+	"\x15AdvertiseCacheRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12\x19\n" +
+	"\blease_ms\x18\x02 \x01(\x03R\aleaseMs\"H\n" +
+	"\x16AdvertiseCacheResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x8f\x01\n" +
+	"\x11UpdateCRDTRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\x03R\x05delta\x12\x10\n" +
+	"\x03add\x18\x04 \x03(\tR\x03add\x12\x16\n" +
+	"\x06remove\x18\x05 \x03(\tR\x06remove\x12\x14\n" +
+	"\x05merge\x18\x06 \x01(\fR\x05merge\"Z\n" +
+	"\x12UpdateCRDTResponse\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\fR\x05state\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"#\n" +
+	"\x0fQueryTagRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\"V\n" +
+	"\x10QueryTagResponse\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x96\x01\n" +
+	"\x13CacheHotKeysRequest\x12$\n" +
+	"\x05owner\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x05owner\x12(\n" +
+	"\x05items\x18\x02 \x03(\v2\x12.chord.v1.KeyValueR\x05items\x12\x18\n" +
+	"\adropped\x18\x03 \x03(\tR\adropped\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x03R\x05ttlMs\"F\n" +
+	"\x14CacheHotKeysResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"F\n" +
+	"\x06HotKey\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x01R\x04rate\x12\x16\n" +
+	"\x06copies\x18\x03 \x01(\x05R\x06copies\")\n" +
+	"\x11GetHotKeysRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\":\n" +
+	"\x12GetHotKeysResponse\x12$\n" +
+	"\x04keys\x18\x01 \x03(\v2\x10.chord.v1.HotKeyR\x04keys\"W\n" +
+	"\x11ListBucketRequest\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x14\n" +
+	"\x05after\x18\x02 \x01(\tR\x05after\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"<\n" +
+	"\x12ListBucketResponse\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12\x12\n" +
+	"\x04more\x18\x02 \x01(\bR\x04more\"o\n" +
+	"\x0fListKeysRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06values\x18\x04 \x01(\bR\x06values\"]\n" +
+	"\x10ListKeysResponse\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.chord.v1.KeyValueR\x05items\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\xb5\x01\n" +
+	"\vBucketStats\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x12\n" +
+	"\x04keys\x18\x02 \x01(\x03R\x04keys\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12\x14\n" +
+	"\x05reads\x18\x04 \x01(\x03R\x05reads\x12\x16\n" +
+	"\x06writes\x18\x05 \x01(\x03R\x06writes\x12\x19\n" +
+	"\bmax_keys\x18\x06 \x01(\x03R\amaxKeys\x12\x1b\n" +
+	"\tmax_bytes\x18\a \x01(\x03R\bmaxBytes\"/\n" +
+	"\x15GetBucketStatsRequest\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\"I\n" +
+	"\x16GetBucketStatsResponse\x12/\n" +
+	"\abuckets\x18\x01 \x03(\v2\x15.chord.v1.BucketStatsR\abuckets\"\x14\n" +
+	"\x12GetSnapshotRequest\"#\n" +
+	"\rSnapshotChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x91\x02\n" +
+	"\x11RingSnapshotPiece\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x04R\x02id\x12\x17\n" +
+	"\atime_ms\x18\x03 \x01(\x03R\x06timeMs\x12\x1f\n" +
+	"\vrange_start\x18\x04 \x01(\tR\n" +
+	"rangeStart\x12\x12\n" +
+	"\x04file\x18\x05 \x01(\tR\x04file\x12\x18\n" +
+	"\aentries\x18\x06 \x01(\x03R\aentries\x12\x14\n" +
+	"\x05bytes\x18\a \x01(\x03R\x05bytes\x12\x1a\n" +
+	"\bchecksum\x18\b \x01(\tR\bchecksum\x12\x18\n" +
+	"\apending\x18\t \x01(\bR\apending\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\"C\n" +
+	"\x1bGetRingSnapshotPieceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\bR\x05start\"g\n" +
+	"\x1cGetRingSnapshotPieceResponse\x121\n" +
+	"\x05piece\x18\x01 \x01(\v2\x1b.chord.v1.RingSnapshotPieceR\x05piece\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"4\n" +
+	"\x18CheckReachabilityRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\"k\n" +
+	"\x19CheckReachabilityResponse\x12\x1c\n" +
+	"\treachable\x18\x01 \x01(\bR\treachable\x12\x1a\n" +
+	"\bobserved\x18\x02 \x01(\tR\bobserved\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"5\n" +
+	"\vRelayHeader\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xdf\x01\n" +
+	"\n" +
+	"RelayFrame\x12\"\n" +
+	"\x04node\x18\x01 \x01(\v2\x0e.chord.v1.NodeR\x04node\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\x04R\x06callId\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12/\n" +
+	"\aheaders\x18\x04 \x03(\v2\x15.chord.v1.RelayHeaderR\aheaders\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\fR\x06status\x12\x19\n" +
+	"\bpunch_to\x18\a \x01(\tR\apunchTo\"+\n" +
+	"\x11RendezvousRequest\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\"^\n" +
+	"\x12RendezvousResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error*o\n" +
+	"\x0fProtocolVersion\x12 \n" +
+	"\x1cPROTOCOL_VERSION_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14PROTOCOL_VERSION_MIN\x10\x02\x12\x1c\n" +
+	"\x18PROTOCOL_VERSION_CURRENT\x10\x02\x1a\x02\x10\x012\xb3\x17\n" +
+	"\fChordService\x12P\n" +
+	"\rFindSuccessor\x12\x1e.chord.v1.FindSuccessorRequest\x1a\x1f.chord.v1.FindSuccessorResponse\x12;\n" +
+	"\x06Notify\x12\x17.chord.v1.NotifyRequest\x1a\x18.chord.v1.NotifyResponse\x12J\n" +
+	"\vJoinSettled\x12\x1c.chord.v1.JoinSettledRequest\x1a\x1d.chord.v1.JoinSettledResponse\x12>\n" +
+	"\aGetInfo\x12\x18.chord.v1.GetInfoRequest\x1a\x19.chord.v1.GetInfoResponse\x125\n" +
+	"\x04Ping\x12\x15.chord.v1.PingRequest\x1a\x16.chord.v1.PingResponse\x12k\n" +
+	"\x16ClosestPrecedingFinger\x12'.chord.v1.ClosestPrecedingFingerRequest\x1a(.chord.v1.ClosestPrecedingFingerResponse\x12A\n" +
+	"\bGetPeers\x12\x19.chord.v1.GetPeersRequest\x1a\x1a.chord.v1.GetPeersResponse\x12Y\n" +
+	"\x10GetSuccessorList\x12!.chord.v1.GetSuccessorListRequest\x1a\".chord.v1.GetSuccessorListResponse\x12V\n" +
+	"\x0fGetRoutingTable\x12 .chord.v1.GetRoutingTableRequest\x1a!.chord.v1.GetRoutingTableResponse\x12G\n" +
+	"\n" +
+	"GetDensity\x12\x1b.chord.v1.GetDensityRequest\x1a\x1c.chord.v1.GetDensityResponse\x12I\n" +
+	"\x0eRelayBroadcast\x12\x1a.chord.v1.BroadcastRequest\x1a\x1b.chord.v1.BroadcastResponse\x12S\n" +
+	"\x0ePrepareHandoff\x12\x1f.chord.v1.PrepareHandoffRequest\x1a .chord.v1.PrepareHandoffResponse\x12P\n" +
+	"\rCommitHandoff\x12\x1e.chord.v1.CommitHandoffRequest\x1a\x1f.chord.v1.CommitHandoffResponse\x12I\n" +
+	"\rStreamHandoff\x12\x1e.chord.v1.StreamHandoffRequest\x1a\x16.chord.v1.HandoffChunk0\x01\x122\n" +
+	"\x03Put\x12\x14.chord.v1.PutRequest\x1a\x15.chord.v1.PutResponse\x122\n" +
+	"\x03Get\x12\x14.chord.v1.GetRequest\x1a\x15.chord.v1.GetResponse\x12A\n" +
+	"\bPutBatch\x12\x19.chord.v1.PutBatchRequest\x1a\x1a.chord.v1.PutBatchResponse\x12A\n" +
+	"\bGetBatch\x12\x19.chord.v1.GetBatchRequest\x1a\x1a.chord.v1.GetBatchResponse\x12S\n" +
+	"\x0eConditionalPut\x12\x1f.chord.v1.ConditionalPutRequest\x1a .chord.v1.ConditionalPutResponse\x12A\n" +
+	"\bUndelete\x12\x19.chord.v1.UndeleteRequest\x1a\x1a.chord.v1.UndeleteResponse\x12D\n" +
+	"\tReplicate\x12\x1a.chord.v1.ReplicateRequest\x1a\x1b.chord.v1.ReplicateResponse\x12A\n" +
+	"\bQueryTag\x12\x19.chord.v1.QueryTagRequest\x1a\x1a.chord.v1.QueryTagResponse\x12G\n" +
+	"\n" +
+	"UpdateCRDT\x12\x1b.chord.v1.UpdateCRDTRequest\x1a\x1c.chord.v1.UpdateCRDTResponse\x12S\n" +
+	"\x0eAdvertiseCache\x12\x1f.chord.v1.AdvertiseCacheRequest\x1a .chord.v1.AdvertiseCacheResponse\x12M\n" +
+	"\fCacheHotKeys\x12\x1d.chord.v1.CacheHotKeysRequest\x1a\x1e.chord.v1.CacheHotKeysResponse\x12G\n" +
+	"\n" +
+	"GetHotKeys\x12\x1b.chord.v1.GetHotKeysRequest\x1a\x1c.chord.v1.GetHotKeysResponse\x12G\n" +
+	"\n" +
+	"ListBucket\x12\x1b.chord.v1.ListBucketRequest\x1a\x1c.chord.v1.ListBucketResponse\x12S\n" +
+	"\x0eGetBucketStats\x12\x1f.chord.v1.GetBucketStatsRequest\x1a .chord.v1.GetBucketStatsResponse\x12A\n" +
+	"\bListKeys\x12\x19.chord.v1.ListKeysRequest\x1a\x1a.chord.v1.ListKeysResponse\x12S\n" +
+	"\x0eSetMaintenance\x12\x1f.chord.v1.SetMaintenanceRequest\x1a .chord.v1.SetMaintenanceResponse\x12S\n" +
+	"\x0eGetMaintenance\x12\x1f.chord.v1.GetMaintenanceRequest\x1a .chord.v1.GetMaintenanceResponse\x12e\n" +
+	"\x14GetMembershipHistory\x12%.chord.v1.GetMembershipHistoryRequest\x1a&.chord.v1.GetMembershipHistoryResponse\x12S\n" +
+	"\x0eGetStatsSample\x12\x1f.chord.v1.GetStatsSampleRequest\x1a .chord.v1.GetStatsSampleResponse\x12M\n" +
+	"\fGetNodeStats\x12\x1d.chord.v1.GetNodeStatsRequest\x1a\x1e.chord.v1.GetNodeStatsResponse\x12F\n" +
+	"\vGetSnapshot\x12\x1c.chord.v1.GetSnapshotRequest\x1a\x17.chord.v1.SnapshotChunk0\x01\x12e\n" +
+	"\x14GetRingSnapshotPiece\x12%.chord.v1.GetRingSnapshotPieceRequest\x1a&.chord.v1.GetRingSnapshotPieceResponse\x12\\\n" +
+	"\x11CheckReachability\x12\".chord.v1.CheckReachabilityRequest\x1a#.chord.v1.CheckReachabilityResponse\x127\n" +
+	"\x05Relay\x12\x14.chord.v1.RelayFrame\x1a\x14.chord.v1.RelayFrame(\x010\x01\x12G\n" +
+	"\n" +
+	"Rendezvous\x12\x1b.chord.v1.RendezvousRequest\x1a\x1c.chord.v1.RendezvousResponseB Z\x1echord-dht/api/chord/v1;chordv1b\x06proto3"
+
+var (
+	file_chord_v1_chord_proto_rawDescOnce sync.Once
+	file_chord_v1_chord_proto_rawDescData []byte
+)
+
+func file_chord_v1_chord_proto_rawDescGZIP() []byte {
+	file_chord_v1_chord_proto_rawDescOnce.Do(func() {
+		file_chord_v1_chord_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chord_v1_chord_proto_rawDesc), len(file_chord_v1_chord_proto_rawDesc)))
+	})
+	return file_chord_v1_chord_proto_rawDescData
+}
+
+var file_chord_v1_chord_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_chord_v1_chord_proto_msgTypes = make([]protoimpl.MessageInfo, 91)
+var file_chord_v1_chord_proto_goTypes = []any{
+	(ProtocolVersion)(0),                   // 0: chord.v1.ProtocolVersion
+	(*Node)(nil),                           // 1: chord.v1.Node
+	(*FindSuccessorRequest)(nil),           // 2: chord.v1.FindSuccessorRequest
+	(*FindSuccessorResponse)(nil),          // 3: chord.v1.FindSuccessorResponse
+	(*NotifyRequest)(nil),                  // 4: chord.v1.NotifyRequest
+	(*NotifyResponse)(nil),                 // 5: chord.v1.NotifyResponse
+	(*GossipEntry)(nil),                    // 6: chord.v1.GossipEntry
+	(*JoinSettledRequest)(nil),             // 7: chord.v1.JoinSettledRequest
+	(*JoinSettledResponse)(nil),            // 8: chord.v1.JoinSettledResponse
+	(*GetInfoRequest)(nil),                 // 9: chord.v1.GetInfoRequest
+	(*GetInfoResponse)(nil),                // 10: chord.v1.GetInfoResponse
+	(*PingRequest)(nil),                    // 11: chord.v1.PingRequest
+	(*PingResponse)(nil),                   // 12: chord.v1.PingResponse
+	(*ClosestPrecedingFingerRequest)(nil),  // 13: chord.v1.ClosestPrecedingFingerRequest
+	(*ClosestPrecedingFingerResponse)(nil), // 14: chord.v1.ClosestPrecedingFingerResponse
+	(*KeyValue)(nil),                       // 15: chord.v1.KeyValue
+	(*PutRequest)(nil),                     // 16: chord.v1.PutRequest
+	(*PutResponse)(nil),                    // 17: chord.v1.PutResponse
+	(*GetRequest)(nil),                     // 18: chord.v1.GetRequest
+	(*GetResponse)(nil),                    // 19: chord.v1.GetResponse
+	(*PutBatchRequest)(nil),                // 20: chord.v1.PutBatchRequest
+	(*PutBatchResponse)(nil),               // 21: chord.v1.PutBatchResponse
+	(*GetBatchRequest)(nil),                // 22: chord.v1.GetBatchRequest
+	(*GetBatchResponse)(nil),               // 23: chord.v1.GetBatchResponse
+	(*ConditionalPutRequest)(nil),          // 24: chord.v1.ConditionalPutRequest
+	(*ConditionalPutResponse)(nil),         // 25: chord.v1.ConditionalPutResponse
+	(*UndeleteRequest)(nil),                // 26: chord.v1.UndeleteRequest
+	(*UndeleteResponse)(nil),               // 27: chord.v1.UndeleteResponse
+	(*GetPeersRequest)(nil),                // 28: chord.v1.GetPeersRequest
+	(*GetPeersResponse)(nil),               // 29: chord.v1.GetPeersResponse
+	(*GetSuccessorListRequest)(nil),        // 30: chord.v1.GetSuccessorListRequest
+	(*GetSuccessorListResponse)(nil),       // 31: chord.v1.GetSuccessorListResponse
+	(*Finger)(nil),                         // 32: chord.v1.Finger
+	(*GetRoutingTableRequest)(nil),         // 33: chord.v1.GetRoutingTableRequest
+	(*GetRoutingTableResponse)(nil),        // 34: chord.v1.GetRoutingTableResponse
+	(*GetDensityRequest)(nil),              // 35: chord.v1.GetDensityRequest
+	(*GetDensityResponse)(nil),             // 36: chord.v1.GetDensityResponse
+	(*BroadcastRequest)(nil),               // 37: chord.v1.BroadcastRequest
+	(*BroadcastResponse)(nil),              // 38: chord.v1.BroadcastResponse
+	(*StoredEntry)(nil),                    // 39: chord.v1.StoredEntry
+	(*PrepareHandoffRequest)(nil),          // 40: chord.v1.PrepareHandoffRequest
+	(*PrepareHandoffResponse)(nil),         // 41: chord.v1.PrepareHandoffResponse
+	(*StreamHandoffRequest)(nil),           // 42: chord.v1.StreamHandoffRequest
+	(*HandoffChunk)(nil),                   // 43: chord.v1.HandoffChunk
+	(*CommitHandoffRequest)(nil),           // 44: chord.v1.CommitHandoffRequest
+	(*CommitHandoffResponse)(nil),          // 45: chord.v1.CommitHandoffResponse
+	(*ReplicateRequest)(nil),               // 46: chord.v1.ReplicateRequest
+	(*ReplicateResponse)(nil),              // 47: chord.v1.ReplicateResponse
+	(*MaintenanceStatus)(nil),              // 48: chord.v1.MaintenanceStatus
+	(*SetMaintenanceRequest)(nil),          // 49: chord.v1.SetMaintenanceRequest
+	(*SetMaintenanceResponse)(nil),         // 50: chord.v1.SetMaintenanceResponse
+	(*GetMaintenanceRequest)(nil),          // 51: chord.v1.GetMaintenanceRequest
+	(*GetMaintenanceResponse)(nil),         // 52: chord.v1.GetMaintenanceResponse
+	(*MembershipEvent)(nil),                // 53: chord.v1.MembershipEvent
+	(*GetMembershipHistoryRequest)(nil),    // 54: chord.v1.GetMembershipHistoryRequest
+	(*GetMembershipHistoryResponse)(nil),   // 55: chord.v1.GetMembershipHistoryResponse
+	(*StatsSample)(nil),                    // 56: chord.v1.StatsSample
+	(*GetStatsSampleRequest)(nil),          // 57: chord.v1.GetStatsSampleRequest
+	(*GetStatsSampleResponse)(nil),         // 58: chord.v1.GetStatsSampleResponse
+	(*NodeStats)(nil),                      // 59: chord.v1.NodeStats
+	(*GetNodeStatsRequest)(nil),            // 60: chord.v1.GetNodeStatsRequest
+	(*GetNodeStatsResponse)(nil),           // 61: chord.v1.GetNodeStatsResponse
+	(*AdvertiseCacheRequest)(nil),          // 62: chord.v1.AdvertiseCacheRequest
+	(*AdvertiseCacheResponse)(nil),         // 63: chord.v1.AdvertiseCacheResponse
+	(*UpdateCRDTRequest)(nil),              // 64: chord.v1.UpdateCRDTRequest
+	(*UpdateCRDTResponse)(nil),             // 65: chord.v1.UpdateCRDTResponse
+	(*QueryTagRequest)(nil),                // 66: chord.v1.QueryTagRequest
+	(*QueryTagResponse)(nil),               // 67: chord.v1.QueryTagResponse
+	(*CacheHotKeysRequest)(nil),            // 68: chord.v1.CacheHotKeysRequest
+	(*CacheHotKeysResponse)(nil),           // 69: chord.v1.CacheHotKeysResponse
+	(*HotKey)(nil),                         // 70: chord.v1.HotKey
+	(*GetHotKeysRequest)(nil),              // 71: chord.v1.GetHotKeysRequest
+	(*GetHotKeysResponse)(nil),             // 72: chord.v1.GetHotKeysResponse
+	(*ListBucketRequest)(nil),              // 73: chord.v1.ListBucketRequest
+	(*ListBucketResponse)(nil),             // 74: chord.v1.ListBucketResponse
+	(*ListKeysRequest)(nil),                // 75: chord.v1.ListKeysRequest
+	(*ListKeysResponse)(nil),               // 76: chord.v1.ListKeysResponse
+	(*BucketStats)(nil),                    // 77: chord.v1.BucketStats
+	(*GetBucketStatsRequest)(nil),          // 78: chord.v1.GetBucketStatsRequest
+	(*GetBucketStatsResponse)(nil),         // 79: chord.v1.GetBucketStatsResponse
+	(*GetSnapshotRequest)(nil),             // 80: chord.v1.GetSnapshotRequest
+	(*SnapshotChunk)(nil),                  // 81: chord.v1.SnapshotChunk
+	(*RingSnapshotPiece)(nil),              // 82: chord.v1.RingSnapshotPiece
+	(*GetRingSnapshotPieceRequest)(nil),    // 83: chord.v1.GetRingSnapshotPieceRequest
+	(*GetRingSnapshotPieceResponse)(nil),   // 84: chord.v1.GetRingSnapshotPieceResponse
+	(*CheckReachabilityRequest)(nil),       // 85: chord.v1.CheckReachabilityRequest
+	(*CheckReachabilityResponse)(nil),      // 86: chord.v1.CheckReachabilityResponse
+	(*RelayHeader)(nil),                    // 87: chord.v1.RelayHeader
+	(*RelayFrame)(nil),                     // 88: chord.v1.RelayFrame
+	(*RendezvousRequest)(nil),              // 89: chord.v1.RendezvousRequest
+	(*RendezvousResponse)(nil),             // 90: chord.v1.RendezvousResponse
+	nil,                                    // 91: chord.v1.NodeStats.RpcsEntry
+}
+var file_chord_v1_chord_proto_depIdxs = []int32{
+	1,   // 0: chord.v1.FindSuccessorRequest.requester:type_name -> chord.v1.Node
+	1,   // 1: chord.v1.FindSuccessorResponse.successor:type_name -> chord.v1.Node
+	1,   // 2: chord.v1.FindSuccessorResponse.copies:type_name -> chord.v1.Node
+	1,   // 3: chord.v1.NotifyRequest.node:type_name -> chord.v1.Node
+	6,   // 4: chord.v1.NotifyRequest.gossip:type_name -> chord.v1.GossipEntry
+	1,   // 5: chord.v1.NotifyResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 6: chord.v1.NotifyResponse.replaced:type_name -> chord.v1.Node
+	6,   // 7: chord.v1.NotifyResponse.gossip:type_name -> chord.v1.GossipEntry
+	1,   // 8: chord.v1.GossipEntry.node:type_name -> chord.v1.Node
+	1,   // 9: chord.v1.JoinSettledRequest.node:type_name -> chord.v1.Node
+	1,   // 10: chord.v1.GetInfoResponse.node:type_name -> chord.v1.Node
+	1,   // 11: chord.v1.GetInfoResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 12: chord.v1.GetInfoResponse.successor:type_name -> chord.v1.Node
+	1,   // 13: chord.v1.GetInfoResponse.fingers:type_name -> chord.v1.Node
+	1,   // 14: chord.v1.PingRequest.requester:type_name -> chord.v1.Node
+	1,   // 15: chord.v1.ClosestPrecedingFingerResponse.node:type_name -> chord.v1.Node
+	15,  // 16: chord.v1.PutBatchRequest.items:type_name -> chord.v1.KeyValue
+	15,  // 17: chord.v1.GetBatchResponse.items:type_name -> chord.v1.KeyValue
+	1,   // 18: chord.v1.GetPeersRequest.requester:type_name -> chord.v1.Node
+	1,   // 19: chord.v1.GetPeersResponse.node:type_name -> chord.v1.Node
+	1,   // 20: chord.v1.GetPeersResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 21: chord.v1.GetPeersResponse.successors:type_name -> chord.v1.Node
+	1,   // 22: chord.v1.GetPeersResponse.fingers:type_name -> chord.v1.Node
+	1,   // 23: chord.v1.GetSuccessorListResponse.node:type_name -> chord.v1.Node
+	1,   // 24: chord.v1.GetSuccessorListResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 25: chord.v1.GetSuccessorListResponse.successors:type_name -> chord.v1.Node
+	1,   // 26: chord.v1.Finger.node:type_name -> chord.v1.Node
+	1,   // 27: chord.v1.GetRoutingTableResponse.node:type_name -> chord.v1.Node
+	1,   // 28: chord.v1.GetRoutingTableResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 29: chord.v1.GetRoutingTableResponse.successors:type_name -> chord.v1.Node
+	32,  // 30: chord.v1.GetRoutingTableResponse.fingers:type_name -> chord.v1.Finger
+	1,   // 31: chord.v1.GetDensityResponse.node:type_name -> chord.v1.Node
+	1,   // 32: chord.v1.GetDensityResponse.predecessor:type_name -> chord.v1.Node
+	1,   // 33: chord.v1.BroadcastRequest.origin:type_name -> chord.v1.Node
+	1,   // 34: chord.v1.PrepareHandoffRequest.requester:type_name -> chord.v1.Node
+	39,  // 35: chord.v1.PrepareHandoffResponse.entries:type_name -> chord.v1.StoredEntry
+	1,   // 36: chord.v1.StreamHandoffRequest.requester:type_name -> chord.v1.Node
+	39,  // 37: chord.v1.HandoffChunk.entries:type_name -> chord.v1.StoredEntry
+	1,   // 38: chord.v1.CommitHandoffRequest.requester:type_name -> chord.v1.Node
+	1,   // 39: chord.v1.ReplicateRequest.owner:type_name -> chord.v1.Node
+	39,  // 40: chord.v1.ReplicateRequest.entries:type_name -> chord.v1.StoredEntry
+	1,   // 41: chord.v1.MaintenanceStatus.node:type_name -> chord.v1.Node
+	48,  // 42: chord.v1.SetMaintenanceResponse.status:type_name -> chord.v1.MaintenanceStatus
+	48,  // 43: chord.v1.GetMaintenanceResponse.status:type_name -> chord.v1.MaintenanceStatus
+	1,   // 44: chord.v1.MembershipEvent.node:type_name -> chord.v1.Node
+	1,   // 45: chord.v1.MembershipEvent.previous:type_name -> chord.v1.Node
+	1,   // 46: chord.v1.GetMembershipHistoryResponse.node:type_name -> chord.v1.Node
+	53,  // 47: chord.v1.GetMembershipHistoryResponse.events:type_name -> chord.v1.MembershipEvent
+	1,   // 48: chord.v1.StatsSample.node:type_name -> chord.v1.Node
+	56,  // 49: chord.v1.GetStatsSampleResponse.sample:type_name -> chord.v1.StatsSample
+	91,  // 50: chord.v1.NodeStats.rpcs:type_name -> chord.v1.NodeStats.RpcsEntry
+	59,  // 51: chord.v1.GetNodeStatsResponse.stats:type_name -> chord.v1.NodeStats
+	1,   // 52: chord.v1.CacheHotKeysRequest.owner:type_name -> chord.v1.Node
+	15,  // 53: chord.v1.CacheHotKeysRequest.items:type_name -> chord.v1.KeyValue
+	70,  // 54: chord.v1.GetHotKeysResponse.keys:type_name -> chord.v1.HotKey
+	15,  // 55: chord.v1.ListKeysResponse.items:type_name -> chord.v1.KeyValue
+	77,  // 56: chord.v1.GetBucketStatsResponse.buckets:type_name -> chord.v1.BucketStats
+	1,   // 57: chord.v1.RingSnapshotPiece.node:type_name -> chord.v1.Node
+	82,  // 58: chord.v1.GetRingSnapshotPieceResponse.piece:type_name -> chord.v1.RingSnapshotPiece
+	1,   // 59: chord.v1.RelayFrame.node:type_name -> chord.v1.Node
+	87,  // 60: chord.v1.RelayFrame.headers:type_name -> chord.v1.RelayHeader
+	2,   // 61: chord.v1.ChordService.FindSuccessor:input_type -> chord.v1.FindSuccessorRequest
+	4,   // 62: chord.v1.ChordService.Notify:input_type -> chord.v1.NotifyRequest
+	7,   // 63: chord.v1.ChordService.JoinSettled:input_type -> chord.v1.JoinSettledRequest
+	9,   // 64: chord.v1.ChordService.GetInfo:input_type -> chord.v1.GetInfoRequest
+	11,  // 65: chord.v1.ChordService.Ping:input_type -> chord.v1.PingRequest
+	13,  // 66: chord.v1.ChordService.ClosestPrecedingFinger:input_type -> chord.v1.ClosestPrecedingFingerRequest
+	28,  // 67: chord.v1.ChordService.GetPeers:input_type -> chord.v1.GetPeersRequest
+	30,  // 68: chord.v1.ChordService.GetSuccessorList:input_type -> chord.v1.GetSuccessorListRequest
+	33,  // 69: chord.v1.ChordService.GetRoutingTable:input_type -> chord.v1.GetRoutingTableRequest
+	35,  // 70: chord.v1.ChordService.GetDensity:input_type -> chord.v1.GetDensityRequest
+	37,  // 71: chord.v1.ChordService.RelayBroadcast:input_type -> chord.v1.BroadcastRequest
+	40,  // 72: chord.v1.ChordService.PrepareHandoff:input_type -> chord.v1.PrepareHandoffRequest
+	44,  // 73: chord.v1.ChordService.CommitHandoff:input_type -> chord.v1.CommitHandoffRequest
+	42,  // 74: chord.v1.ChordService.StreamHandoff:input_type -> chord.v1.StreamHandoffRequest
+	16,  // 75: chord.v1.ChordService.Put:input_type -> chord.v1.PutRequest
+	18,  // 76: chord.v1.ChordService.Get:input_type -> chord.v1.GetRequest
+	20,  // 77: chord.v1.ChordService.PutBatch:input_type -> chord.v1.PutBatchRequest
+	22,  // 78: chord.v1.ChordService.GetBatch:input_type -> chord.v1.GetBatchRequest
+	24,  // 79: chord.v1.ChordService.ConditionalPut:input_type -> chord.v1.ConditionalPutRequest
+	26,  // 80: chord.v1.ChordService.Undelete:input_type -> chord.v1.UndeleteRequest
+	46,  // 81: chord.v1.ChordService.Replicate:input_type -> chord.v1.ReplicateRequest
+	66,  // 82: chord.v1.ChordService.QueryTag:input_type -> chord.v1.QueryTagRequest
+	64,  // 83: chord.v1.ChordService.UpdateCRDT:input_type -> chord.v1.UpdateCRDTRequest
+	62,  // 84: chord.v1.ChordService.AdvertiseCache:input_type -> chord.v1.AdvertiseCacheRequest
+	68,  // 85: chord.v1.ChordService.CacheHotKeys:input_type -> chord.v1.CacheHotKeysRequest
+	71,  // 86: chord.v1.ChordService.GetHotKeys:input_type -> chord.v1.GetHotKeysRequest
+	73,  // 87: chord.v1.ChordService.ListBucket:input_type -> chord.v1.ListBucketRequest
+	78,  // 88: chord.v1.ChordService.GetBucketStats:input_type -> chord.v1.GetBucketStatsRequest
+	75,  // 89: chord.v1.ChordService.ListKeys:input_type -> chord.v1.ListKeysRequest
+	49,  // 90: chord.v1.ChordService.SetMaintenance:input_type -> chord.v1.SetMaintenanceRequest
+	51,  // 91: chord.v1.ChordService.GetMaintenance:input_type -> chord.v1.GetMaintenanceRequest
+	54,  // 92: chord.v1.ChordService.GetMembershipHistory:input_type -> chord.v1.GetMembershipHistoryRequest
+	57,  // 93: chord.v1.ChordService.GetStatsSample:input_type -> chord.v1.GetStatsSampleRequest
+	60,  // 94: chord.v1.ChordService.GetNodeStats:input_type -> chord.v1.GetNodeStatsRequest
+	80,  // 95: chord.v1.ChordService.GetSnapshot:input_type -> chord.v1.GetSnapshotRequest
+	83,  // 96: chord.v1.ChordService.GetRingSnapshotPiece:input_type -> chord.v1.GetRingSnapshotPieceRequest
+	85,  // 97: chord.v1.ChordService.CheckReachability:input_type -> chord.v1.CheckReachabilityRequest
+	88,  // 98: chord.v1.ChordService.Relay:input_type -> chord.v1.RelayFrame
+	89,  // 99: chord.v1.ChordService.Rendezvous:input_type -> chord.v1.RendezvousRequest
+	3,   // 100: chord.v1.ChordService.FindSuccessor:output_type -> chord.v1.FindSuccessorResponse
+	5,   // 101: chord.v1.ChordService.Notify:output_type -> chord.v1.NotifyResponse
+	8,   // 102: chord.v1.ChordService.JoinSettled:output_type -> chord.v1.JoinSettledResponse
+	10,  // 103: chord.v1.ChordService.GetInfo:output_type -> chord.v1.GetInfoResponse
+	12,  // 104: chord.v1.ChordService.Ping:output_type -> chord.v1.PingResponse
+	14,  // 105: chord.v1.ChordService.ClosestPrecedingFinger:output_type -> chord.v1.ClosestPrecedingFingerResponse
+	29,  // 106: chord.v1.ChordService.GetPeers:output_type -> chord.v1.GetPeersResponse
+	31,  // 107: chord.v1.ChordService.GetSuccessorList:output_type -> chord.v1.GetSuccessorListResponse
+	34,  // 108: chord.v1.ChordService.GetRoutingTable:output_type -> chord.v1.GetRoutingTableResponse
+	36,  // 109: chord.v1.ChordService.GetDensity:output_type -> chord.v1.GetDensityResponse
+	38,  // 110: chord.v1.ChordService.RelayBroadcast:output_type -> chord.v1.BroadcastResponse
+	41,  // 111: chord.v1.ChordService.PrepareHandoff:output_type -> chord.v1.PrepareHandoffResponse
+	45,  // 112: chord.v1.ChordService.CommitHandoff:output_type -> chord.v1.CommitHandoffResponse
+	43,  // 113: chord.v1.ChordService.StreamHandoff:output_type -> chord.v1.HandoffChunk
+	17,  // 114: chord.v1.ChordService.Put:output_type -> chord.v1.PutResponse
+	19,  // 115: chord.v1.ChordService.Get:output_type -> chord.v1.GetResponse
+	21,  // 116: chord.v1.ChordService.PutBatch:output_type -> chord.v1.PutBatchResponse
+	23,  // 117: chord.v1.ChordService.GetBatch:output_type -> chord.v1.GetBatchResponse
+	25,  // 118: chord.v1.ChordService.ConditionalPut:output_type -> chord.v1.ConditionalPutResponse
+	27,  // 119: chord.v1.ChordService.Undelete:output_type -> chord.v1.UndeleteResponse
+	47,  // 120: chord.v1.ChordService.Replicate:output_type -> chord.v1.ReplicateResponse
+	67,  // 121: chord.v1.ChordService.QueryTag:output_type -> chord.v1.QueryTagResponse
+	65,  // 122: chord.v1.ChordService.UpdateCRDT:output_type -> chord.v1.UpdateCRDTResponse
+	63,  // 123: chord.v1.ChordService.AdvertiseCache:output_type -> chord.v1.AdvertiseCacheResponse
+	69,  // 124: chord.v1.ChordService.CacheHotKeys:output_type -> chord.v1.CacheHotKeysResponse
+	72,  // 125: chord.v1.ChordService.GetHotKeys:output_type -> chord.v1.GetHotKeysResponse
+	74,  // 126: chord.v1.ChordService.ListBucket:output_type -> chord.v1.ListBucketResponse
+	79,  // 127: chord.v1.ChordService.GetBucketStats:output_type -> chord.v1.GetBucketStatsResponse
+	76,  // 128: chord.v1.ChordService.ListKeys:output_type -> chord.v1.ListKeysResponse
+	50,  // 129: chord.v1.ChordService.SetMaintenance:output_type -> chord.v1.SetMaintenanceResponse
+	52,  // 130: chord.v1.ChordService.GetMaintenance:output_type -> chord.v1.GetMaintenanceResponse
+	55,  // 131: chord.v1.ChordService.GetMembershipHistory:output_type -> chord.v1.GetMembershipHistoryResponse
+	58,  // 132: chord.v1.ChordService.GetStatsSample:output_type -> chord.v1.GetStatsSampleResponse
+	61,  // 133: chord.v1.ChordService.GetNodeStats:output_type -> chord.v1.GetNodeStatsResponse
+	81,  // 134: chord.v1.ChordService.GetSnapshot:output_type -> chord.v1.SnapshotChunk
+	84,  // 135: chord.v1.ChordService.GetRingSnapshotPiece:output_type -> chord.v1.GetRingSnapshotPieceResponse
+	86,  // 136: chord.v1.ChordService.CheckReachability:output_type -> chord.v1.CheckReachabilityResponse
+	88,  // 137: chord.v1.ChordService.Relay:output_type -> chord.v1.RelayFrame
+	90,  // 138: chord.v1.ChordService.Rendezvous:output_type -> chord.v1.RendezvousResponse
+	100, // [100:139] is the sub-list for method output_type
+	61,  // [61:100] is the sub-list for method input_type
+	61,  // [61:61] is the sub-list for extension type_name
+	61,  // [61:61] is the sub-list for extension extendee
+	0,   // [0:61] is the sub-list for field type_name
+}
+
+func init() { file_chord_v1_chord_proto_init() }
+func file_chord_v1_chord_proto_init() {
+	if File_chord_v1_chord_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chord_v1_chord_proto_rawDesc), len(file_chord_v1_chord_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   91,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chord_v1_chord_proto_goTypes,
+		DependencyIndexes: file_chord_v1_chord_proto_depIdxs,
+		EnumInfos:         file_chord_v1_chord_proto_enumTypes,
+		MessageInfos:      file_chord_v1_chord_proto_msgTypes,
+	}.Build()
+	File_chord_v1_chord_proto = out.File
+	file_chord_v1_chord_proto_goTypes = nil
+	file_chord_v1_chord_proto_depIdxs = nil
+}
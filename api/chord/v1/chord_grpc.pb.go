@@ -0,0 +1,1596 @@
+// The Chord DHT API, version 1. See the compatibility policy in the
+// README: within chord.v1 fields and RPCs are only ever added, and the
+// protocol versions below are negotiated on every RPC.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: chord/v1/chord.proto
+
+package chordv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChordService_FindSuccessor_FullMethodName          = "/chord.v1.ChordService/FindSuccessor"
+	ChordService_Notify_FullMethodName                 = "/chord.v1.ChordService/Notify"
+	ChordService_JoinSettled_FullMethodName            = "/chord.v1.ChordService/JoinSettled"
+	ChordService_GetInfo_FullMethodName                = "/chord.v1.ChordService/GetInfo"
+	ChordService_Ping_FullMethodName                   = "/chord.v1.ChordService/Ping"
+	ChordService_ClosestPrecedingFinger_FullMethodName = "/chord.v1.ChordService/ClosestPrecedingFinger"
+	ChordService_GetPeers_FullMethodName               = "/chord.v1.ChordService/GetPeers"
+	ChordService_GetSuccessorList_FullMethodName       = "/chord.v1.ChordService/GetSuccessorList"
+	ChordService_GetRoutingTable_FullMethodName        = "/chord.v1.ChordService/GetRoutingTable"
+	ChordService_GetDensity_FullMethodName             = "/chord.v1.ChordService/GetDensity"
+	ChordService_RelayBroadcast_FullMethodName         = "/chord.v1.ChordService/RelayBroadcast"
+	ChordService_PrepareHandoff_FullMethodName         = "/chord.v1.ChordService/PrepareHandoff"
+	ChordService_CommitHandoff_FullMethodName          = "/chord.v1.ChordService/CommitHandoff"
+	ChordService_StreamHandoff_FullMethodName          = "/chord.v1.ChordService/StreamHandoff"
+	ChordService_Put_FullMethodName                    = "/chord.v1.ChordService/Put"
+	ChordService_Get_FullMethodName                    = "/chord.v1.ChordService/Get"
+	ChordService_PutBatch_FullMethodName               = "/chord.v1.ChordService/PutBatch"
+	ChordService_GetBatch_FullMethodName               = "/chord.v1.ChordService/GetBatch"
+	ChordService_ConditionalPut_FullMethodName         = "/chord.v1.ChordService/ConditionalPut"
+	ChordService_Undelete_FullMethodName               = "/chord.v1.ChordService/Undelete"
+	ChordService_Replicate_FullMethodName              = "/chord.v1.ChordService/Replicate"
+	ChordService_QueryTag_FullMethodName               = "/chord.v1.ChordService/QueryTag"
+	ChordService_UpdateCRDT_FullMethodName             = "/chord.v1.ChordService/UpdateCRDT"
+	ChordService_AdvertiseCache_FullMethodName         = "/chord.v1.ChordService/AdvertiseCache"
+	ChordService_CacheHotKeys_FullMethodName           = "/chord.v1.ChordService/CacheHotKeys"
+	ChordService_GetHotKeys_FullMethodName             = "/chord.v1.ChordService/GetHotKeys"
+	ChordService_ListBucket_FullMethodName             = "/chord.v1.ChordService/ListBucket"
+	ChordService_GetBucketStats_FullMethodName         = "/chord.v1.ChordService/GetBucketStats"
+	ChordService_ListKeys_FullMethodName               = "/chord.v1.ChordService/ListKeys"
+	ChordService_SetMaintenance_FullMethodName         = "/chord.v1.ChordService/SetMaintenance"
+	ChordService_GetMaintenance_FullMethodName         = "/chord.v1.ChordService/GetMaintenance"
+	ChordService_GetMembershipHistory_FullMethodName   = "/chord.v1.ChordService/GetMembershipHistory"
+	ChordService_GetStatsSample_FullMethodName         = "/chord.v1.ChordService/GetStatsSample"
+	ChordService_GetNodeStats_FullMethodName           = "/chord.v1.ChordService/GetNodeStats"
+	ChordService_GetSnapshot_FullMethodName            = "/chord.v1.ChordService/GetSnapshot"
+	ChordService_GetRingSnapshotPiece_FullMethodName   = "/chord.v1.ChordService/GetRingSnapshotPiece"
+	ChordService_CheckReachability_FullMethodName      = "/chord.v1.ChordService/CheckReachability"
+	ChordService_Relay_FullMethodName                  = "/chord.v1.ChordService/Relay"
+	ChordService_Rendezvous_FullMethodName             = "/chord.v1.ChordService/Rendezvous"
+)
+
+// ChordServiceClient is the client API for ChordService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// gRPC Service Definition
+type ChordServiceClient interface {
+	// Core Chord operations
+	FindSuccessor(ctx context.Context, in *FindSuccessorRequest, opts ...grpc.CallOption) (*FindSuccessorResponse, error)
+	Notify(ctx context.Context, in *NotifyRequest, opts ...grpc.CallOption) (*NotifyResponse, error)
+	JoinSettled(ctx context.Context, in *JoinSettledRequest, opts ...grpc.CallOption) (*JoinSettledResponse, error)
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// Additional helpful operations
+	ClosestPrecedingFinger(ctx context.Context, in *ClosestPrecedingFingerRequest, opts ...grpc.CallOption) (*ClosestPrecedingFingerResponse, error)
+	GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*GetPeersResponse, error)
+	GetSuccessorList(ctx context.Context, in *GetSuccessorListRequest, opts ...grpc.CallOption) (*GetSuccessorListResponse, error)
+	GetRoutingTable(ctx context.Context, in *GetRoutingTableRequest, opts ...grpc.CallOption) (*GetRoutingTableResponse, error)
+	GetDensity(ctx context.Context, in *GetDensityRequest, opts ...grpc.CallOption) (*GetDensityResponse, error)
+	RelayBroadcast(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error)
+	// Ownership hand-off
+	PrepareHandoff(ctx context.Context, in *PrepareHandoffRequest, opts ...grpc.CallOption) (*PrepareHandoffResponse, error)
+	CommitHandoff(ctx context.Context, in *CommitHandoffRequest, opts ...grpc.CallOption) (*CommitHandoffResponse, error)
+	StreamHandoff(ctx context.Context, in *StreamHandoffRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HandoffChunk], error)
+	// Storage operations
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	PutBatch(ctx context.Context, in *PutBatchRequest, opts ...grpc.CallOption) (*PutBatchResponse, error)
+	GetBatch(ctx context.Context, in *GetBatchRequest, opts ...grpc.CallOption) (*GetBatchResponse, error)
+	ConditionalPut(ctx context.Context, in *ConditionalPutRequest, opts ...grpc.CallOption) (*ConditionalPutResponse, error)
+	Undelete(ctx context.Context, in *UndeleteRequest, opts ...grpc.CallOption) (*UndeleteResponse, error)
+	Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (*ReplicateResponse, error)
+	QueryTag(ctx context.Context, in *QueryTagRequest, opts ...grpc.CallOption) (*QueryTagResponse, error)
+	UpdateCRDT(ctx context.Context, in *UpdateCRDTRequest, opts ...grpc.CallOption) (*UpdateCRDTResponse, error)
+	AdvertiseCache(ctx context.Context, in *AdvertiseCacheRequest, opts ...grpc.CallOption) (*AdvertiseCacheResponse, error)
+	CacheHotKeys(ctx context.Context, in *CacheHotKeysRequest, opts ...grpc.CallOption) (*CacheHotKeysResponse, error)
+	GetHotKeys(ctx context.Context, in *GetHotKeysRequest, opts ...grpc.CallOption) (*GetHotKeysResponse, error)
+	// Buckets
+	ListBucket(ctx context.Context, in *ListBucketRequest, opts ...grpc.CallOption) (*ListBucketResponse, error)
+	GetBucketStats(ctx context.Context, in *GetBucketStatsRequest, opts ...grpc.CallOption) (*GetBucketStatsResponse, error)
+	// Key enumeration
+	ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (*ListKeysResponse, error)
+	// Maintenance windows
+	SetMaintenance(ctx context.Context, in *SetMaintenanceRequest, opts ...grpc.CallOption) (*SetMaintenanceResponse, error)
+	GetMaintenance(ctx context.Context, in *GetMaintenanceRequest, opts ...grpc.CallOption) (*GetMaintenanceResponse, error)
+	// Membership history
+	GetMembershipHistory(ctx context.Context, in *GetMembershipHistoryRequest, opts ...grpc.CallOption) (*GetMembershipHistoryResponse, error)
+	// Coordinated statistics sampling
+	GetStatsSample(ctx context.Context, in *GetStatsSampleRequest, opts ...grpc.CallOption) (*GetStatsSampleResponse, error)
+	GetNodeStats(ctx context.Context, in *GetNodeStatsRequest, opts ...grpc.CallOption) (*GetNodeStatsResponse, error)
+	// Node snapshots
+	GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error)
+	GetRingSnapshotPiece(ctx context.Context, in *GetRingSnapshotPieceRequest, opts ...grpc.CallOption) (*GetRingSnapshotPieceResponse, error)
+	// NAT traversal
+	CheckReachability(ctx context.Context, in *CheckReachabilityRequest, opts ...grpc.CallOption) (*CheckReachabilityResponse, error)
+	Relay(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RelayFrame, RelayFrame], error)
+	Rendezvous(ctx context.Context, in *RendezvousRequest, opts ...grpc.CallOption) (*RendezvousResponse, error)
+}
+
+type chordServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChordServiceClient(cc grpc.ClientConnInterface) ChordServiceClient {
+	return &chordServiceClient{cc}
+}
+
+func (c *chordServiceClient) FindSuccessor(ctx context.Context, in *FindSuccessorRequest, opts ...grpc.CallOption) (*FindSuccessorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindSuccessorResponse)
+	err := c.cc.Invoke(ctx, ChordService_FindSuccessor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Notify(ctx context.Context, in *NotifyRequest, opts ...grpc.CallOption) (*NotifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotifyResponse)
+	err := c.cc.Invoke(ctx, ChordService_Notify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) JoinSettled(ctx context.Context, in *JoinSettledRequest, opts ...grpc.CallOption) (*JoinSettledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinSettledResponse)
+	err := c.cc.Invoke(ctx, ChordService_JoinSettled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, ChordService_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) ClosestPrecedingFinger(ctx context.Context, in *ClosestPrecedingFingerRequest, opts ...grpc.CallOption) (*ClosestPrecedingFingerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClosestPrecedingFingerResponse)
+	err := c.cc.Invoke(ctx, ChordService_ClosestPrecedingFinger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*GetPeersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPeersResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetPeers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetSuccessorList(ctx context.Context, in *GetSuccessorListRequest, opts ...grpc.CallOption) (*GetSuccessorListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSuccessorListResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetSuccessorList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetRoutingTable(ctx context.Context, in *GetRoutingTableRequest, opts ...grpc.CallOption) (*GetRoutingTableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRoutingTableResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetRoutingTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetDensity(ctx context.Context, in *GetDensityRequest, opts ...grpc.CallOption) (*GetDensityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDensityResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetDensity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) RelayBroadcast(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BroadcastResponse)
+	err := c.cc.Invoke(ctx, ChordService_RelayBroadcast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) PrepareHandoff(ctx context.Context, in *PrepareHandoffRequest, opts ...grpc.CallOption) (*PrepareHandoffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrepareHandoffResponse)
+	err := c.cc.Invoke(ctx, ChordService_PrepareHandoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) CommitHandoff(ctx context.Context, in *CommitHandoffRequest, opts ...grpc.CallOption) (*CommitHandoffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitHandoffResponse)
+	err := c.cc.Invoke(ctx, ChordService_CommitHandoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) StreamHandoff(ctx context.Context, in *StreamHandoffRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HandoffChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChordService_ServiceDesc.Streams[0], ChordService_StreamHandoff_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamHandoffRequest, HandoffChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_StreamHandoffClient = grpc.ServerStreamingClient[HandoffChunk]
+
+func (c *chordServiceClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutResponse)
+	err := c.cc.Invoke(ctx, ChordService_Put_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, ChordService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) PutBatch(ctx context.Context, in *PutBatchRequest, opts ...grpc.CallOption) (*PutBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutBatchResponse)
+	err := c.cc.Invoke(ctx, ChordService_PutBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetBatch(ctx context.Context, in *GetBatchRequest, opts ...grpc.CallOption) (*GetBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBatchResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) ConditionalPut(ctx context.Context, in *ConditionalPutRequest, opts ...grpc.CallOption) (*ConditionalPutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConditionalPutResponse)
+	err := c.cc.Invoke(ctx, ChordService_ConditionalPut_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Undelete(ctx context.Context, in *UndeleteRequest, opts ...grpc.CallOption) (*UndeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndeleteResponse)
+	err := c.cc.Invoke(ctx, ChordService_Undelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (*ReplicateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplicateResponse)
+	err := c.cc.Invoke(ctx, ChordService_Replicate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) QueryTag(ctx context.Context, in *QueryTagRequest, opts ...grpc.CallOption) (*QueryTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryTagResponse)
+	err := c.cc.Invoke(ctx, ChordService_QueryTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) UpdateCRDT(ctx context.Context, in *UpdateCRDTRequest, opts ...grpc.CallOption) (*UpdateCRDTResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateCRDTResponse)
+	err := c.cc.Invoke(ctx, ChordService_UpdateCRDT_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) AdvertiseCache(ctx context.Context, in *AdvertiseCacheRequest, opts ...grpc.CallOption) (*AdvertiseCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdvertiseCacheResponse)
+	err := c.cc.Invoke(ctx, ChordService_AdvertiseCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) CacheHotKeys(ctx context.Context, in *CacheHotKeysRequest, opts ...grpc.CallOption) (*CacheHotKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheHotKeysResponse)
+	err := c.cc.Invoke(ctx, ChordService_CacheHotKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetHotKeys(ctx context.Context, in *GetHotKeysRequest, opts ...grpc.CallOption) (*GetHotKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHotKeysResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetHotKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) ListBucket(ctx context.Context, in *ListBucketRequest, opts ...grpc.CallOption) (*ListBucketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBucketResponse)
+	err := c.cc.Invoke(ctx, ChordService_ListBucket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetBucketStats(ctx context.Context, in *GetBucketStatsRequest, opts ...grpc.CallOption) (*GetBucketStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBucketStatsResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetBucketStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (*ListKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListKeysResponse)
+	err := c.cc.Invoke(ctx, ChordService_ListKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) SetMaintenance(ctx context.Context, in *SetMaintenanceRequest, opts ...grpc.CallOption) (*SetMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetMaintenanceResponse)
+	err := c.cc.Invoke(ctx, ChordService_SetMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetMaintenance(ctx context.Context, in *GetMaintenanceRequest, opts ...grpc.CallOption) (*GetMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMaintenanceResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetMembershipHistory(ctx context.Context, in *GetMembershipHistoryRequest, opts ...grpc.CallOption) (*GetMembershipHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMembershipHistoryResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetMembershipHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetStatsSample(ctx context.Context, in *GetStatsSampleRequest, opts ...grpc.CallOption) (*GetStatsSampleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsSampleResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetStatsSample_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetNodeStats(ctx context.Context, in *GetNodeStatsRequest, opts ...grpc.CallOption) (*GetNodeStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNodeStatsResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetNodeStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChordService_ServiceDesc.Streams[1], ChordService_GetSnapshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetSnapshotRequest, SnapshotChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_GetSnapshotClient = grpc.ServerStreamingClient[SnapshotChunk]
+
+func (c *chordServiceClient) GetRingSnapshotPiece(ctx context.Context, in *GetRingSnapshotPieceRequest, opts ...grpc.CallOption) (*GetRingSnapshotPieceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRingSnapshotPieceResponse)
+	err := c.cc.Invoke(ctx, ChordService_GetRingSnapshotPiece_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) CheckReachability(ctx context.Context, in *CheckReachabilityRequest, opts ...grpc.CallOption) (*CheckReachabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckReachabilityResponse)
+	err := c.cc.Invoke(ctx, ChordService_CheckReachability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chordServiceClient) Relay(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RelayFrame, RelayFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChordService_ServiceDesc.Streams[2], ChordService_Relay_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RelayFrame, RelayFrame]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_RelayClient = grpc.BidiStreamingClient[RelayFrame, RelayFrame]
+
+func (c *chordServiceClient) Rendezvous(ctx context.Context, in *RendezvousRequest, opts ...grpc.CallOption) (*RendezvousResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RendezvousResponse)
+	err := c.cc.Invoke(ctx, ChordService_Rendezvous_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChordServiceServer is the server API for ChordService service.
+// All implementations must embed UnimplementedChordServiceServer
+// for forward compatibility.
+//
+// gRPC Service Definition
+type ChordServiceServer interface {
+	// Core Chord operations
+	FindSuccessor(context.Context, *FindSuccessorRequest) (*FindSuccessorResponse, error)
+	Notify(context.Context, *NotifyRequest) (*NotifyResponse, error)
+	JoinSettled(context.Context, *JoinSettledRequest) (*JoinSettledResponse, error)
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// Additional helpful operations
+	ClosestPrecedingFinger(context.Context, *ClosestPrecedingFingerRequest) (*ClosestPrecedingFingerResponse, error)
+	GetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error)
+	GetSuccessorList(context.Context, *GetSuccessorListRequest) (*GetSuccessorListResponse, error)
+	GetRoutingTable(context.Context, *GetRoutingTableRequest) (*GetRoutingTableResponse, error)
+	GetDensity(context.Context, *GetDensityRequest) (*GetDensityResponse, error)
+	RelayBroadcast(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
+	// Ownership hand-off
+	PrepareHandoff(context.Context, *PrepareHandoffRequest) (*PrepareHandoffResponse, error)
+	CommitHandoff(context.Context, *CommitHandoffRequest) (*CommitHandoffResponse, error)
+	StreamHandoff(*StreamHandoffRequest, grpc.ServerStreamingServer[HandoffChunk]) error
+	// Storage operations
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	PutBatch(context.Context, *PutBatchRequest) (*PutBatchResponse, error)
+	GetBatch(context.Context, *GetBatchRequest) (*GetBatchResponse, error)
+	ConditionalPut(context.Context, *ConditionalPutRequest) (*ConditionalPutResponse, error)
+	Undelete(context.Context, *UndeleteRequest) (*UndeleteResponse, error)
+	Replicate(context.Context, *ReplicateRequest) (*ReplicateResponse, error)
+	QueryTag(context.Context, *QueryTagRequest) (*QueryTagResponse, error)
+	UpdateCRDT(context.Context, *UpdateCRDTRequest) (*UpdateCRDTResponse, error)
+	AdvertiseCache(context.Context, *AdvertiseCacheRequest) (*AdvertiseCacheResponse, error)
+	CacheHotKeys(context.Context, *CacheHotKeysRequest) (*CacheHotKeysResponse, error)
+	GetHotKeys(context.Context, *GetHotKeysRequest) (*GetHotKeysResponse, error)
+	// Buckets
+	ListBucket(context.Context, *ListBucketRequest) (*ListBucketResponse, error)
+	GetBucketStats(context.Context, *GetBucketStatsRequest) (*GetBucketStatsResponse, error)
+	// Key enumeration
+	ListKeys(context.Context, *ListKeysRequest) (*ListKeysResponse, error)
+	// Maintenance windows
+	SetMaintenance(context.Context, *SetMaintenanceRequest) (*SetMaintenanceResponse, error)
+	GetMaintenance(context.Context, *GetMaintenanceRequest) (*GetMaintenanceResponse, error)
+	// Membership history
+	GetMembershipHistory(context.Context, *GetMembershipHistoryRequest) (*GetMembershipHistoryResponse, error)
+	// Coordinated statistics sampling
+	GetStatsSample(context.Context, *GetStatsSampleRequest) (*GetStatsSampleResponse, error)
+	GetNodeStats(context.Context, *GetNodeStatsRequest) (*GetNodeStatsResponse, error)
+	// Node snapshots
+	GetSnapshot(*GetSnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error
+	GetRingSnapshotPiece(context.Context, *GetRingSnapshotPieceRequest) (*GetRingSnapshotPieceResponse, error)
+	// NAT traversal
+	CheckReachability(context.Context, *CheckReachabilityRequest) (*CheckReachabilityResponse, error)
+	Relay(grpc.BidiStreamingServer[RelayFrame, RelayFrame]) error
+	Rendezvous(context.Context, *RendezvousRequest) (*RendezvousResponse, error)
+	mustEmbedUnimplementedChordServiceServer()
+}
+
+// UnimplementedChordServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChordServiceServer struct{}
+
+func (UnimplementedChordServiceServer) FindSuccessor(context.Context, *FindSuccessorRequest) (*FindSuccessorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSuccessor not implemented")
+}
+func (UnimplementedChordServiceServer) Notify(context.Context, *NotifyRequest) (*NotifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Notify not implemented")
+}
+func (UnimplementedChordServiceServer) JoinSettled(context.Context, *JoinSettledRequest) (*JoinSettledResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinSettled not implemented")
+}
+func (UnimplementedChordServiceServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedChordServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedChordServiceServer) ClosestPrecedingFinger(context.Context, *ClosestPrecedingFingerRequest) (*ClosestPrecedingFingerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosestPrecedingFinger not implemented")
+}
+func (UnimplementedChordServiceServer) GetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeers not implemented")
+}
+func (UnimplementedChordServiceServer) GetSuccessorList(context.Context, *GetSuccessorListRequest) (*GetSuccessorListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSuccessorList not implemented")
+}
+func (UnimplementedChordServiceServer) GetRoutingTable(context.Context, *GetRoutingTableRequest) (*GetRoutingTableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoutingTable not implemented")
+}
+func (UnimplementedChordServiceServer) GetDensity(context.Context, *GetDensityRequest) (*GetDensityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDensity not implemented")
+}
+func (UnimplementedChordServiceServer) RelayBroadcast(context.Context, *BroadcastRequest) (*BroadcastResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RelayBroadcast not implemented")
+}
+func (UnimplementedChordServiceServer) PrepareHandoff(context.Context, *PrepareHandoffRequest) (*PrepareHandoffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareHandoff not implemented")
+}
+func (UnimplementedChordServiceServer) CommitHandoff(context.Context, *CommitHandoffRequest) (*CommitHandoffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitHandoff not implemented")
+}
+func (UnimplementedChordServiceServer) StreamHandoff(*StreamHandoffRequest, grpc.ServerStreamingServer[HandoffChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHandoff not implemented")
+}
+func (UnimplementedChordServiceServer) Put(context.Context, *PutRequest) (*PutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (UnimplementedChordServiceServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedChordServiceServer) PutBatch(context.Context, *PutBatchRequest) (*PutBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutBatch not implemented")
+}
+func (UnimplementedChordServiceServer) GetBatch(context.Context, *GetBatchRequest) (*GetBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBatch not implemented")
+}
+func (UnimplementedChordServiceServer) ConditionalPut(context.Context, *ConditionalPutRequest) (*ConditionalPutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConditionalPut not implemented")
+}
+func (UnimplementedChordServiceServer) Undelete(context.Context, *UndeleteRequest) (*UndeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undelete not implemented")
+}
+func (UnimplementedChordServiceServer) Replicate(context.Context, *ReplicateRequest) (*ReplicateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
+func (UnimplementedChordServiceServer) QueryTag(context.Context, *QueryTagRequest) (*QueryTagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryTag not implemented")
+}
+func (UnimplementedChordServiceServer) UpdateCRDT(context.Context, *UpdateCRDTRequest) (*UpdateCRDTResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCRDT not implemented")
+}
+func (UnimplementedChordServiceServer) AdvertiseCache(context.Context, *AdvertiseCacheRequest) (*AdvertiseCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdvertiseCache not implemented")
+}
+func (UnimplementedChordServiceServer) CacheHotKeys(context.Context, *CacheHotKeysRequest) (*CacheHotKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheHotKeys not implemented")
+}
+func (UnimplementedChordServiceServer) GetHotKeys(context.Context, *GetHotKeysRequest) (*GetHotKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHotKeys not implemented")
+}
+func (UnimplementedChordServiceServer) ListBucket(context.Context, *ListBucketRequest) (*ListBucketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBucket not implemented")
+}
+func (UnimplementedChordServiceServer) GetBucketStats(context.Context, *GetBucketStatsRequest) (*GetBucketStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBucketStats not implemented")
+}
+func (UnimplementedChordServiceServer) ListKeys(context.Context, *ListKeysRequest) (*ListKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKeys not implemented")
+}
+func (UnimplementedChordServiceServer) SetMaintenance(context.Context, *SetMaintenanceRequest) (*SetMaintenanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMaintenance not implemented")
+}
+func (UnimplementedChordServiceServer) GetMaintenance(context.Context, *GetMaintenanceRequest) (*GetMaintenanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMaintenance not implemented")
+}
+func (UnimplementedChordServiceServer) GetMembershipHistory(context.Context, *GetMembershipHistoryRequest) (*GetMembershipHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMembershipHistory not implemented")
+}
+func (UnimplementedChordServiceServer) GetStatsSample(context.Context, *GetStatsSampleRequest) (*GetStatsSampleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatsSample not implemented")
+}
+func (UnimplementedChordServiceServer) GetNodeStats(context.Context, *GetNodeStatsRequest) (*GetNodeStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNodeStats not implemented")
+}
+func (UnimplementedChordServiceServer) GetSnapshot(*GetSnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedChordServiceServer) GetRingSnapshotPiece(context.Context, *GetRingSnapshotPieceRequest) (*GetRingSnapshotPieceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRingSnapshotPiece not implemented")
+}
+func (UnimplementedChordServiceServer) CheckReachability(context.Context, *CheckReachabilityRequest) (*CheckReachabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckReachability not implemented")
+}
+func (UnimplementedChordServiceServer) Relay(grpc.BidiStreamingServer[RelayFrame, RelayFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method Relay not implemented")
+}
+func (UnimplementedChordServiceServer) Rendezvous(context.Context, *RendezvousRequest) (*RendezvousResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rendezvous not implemented")
+}
+func (UnimplementedChordServiceServer) mustEmbedUnimplementedChordServiceServer() {}
+func (UnimplementedChordServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeChordServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChordServiceServer will
+// result in compilation errors.
+type UnsafeChordServiceServer interface {
+	mustEmbedUnimplementedChordServiceServer()
+}
+
+func RegisterChordServiceServer(s grpc.ServiceRegistrar, srv ChordServiceServer) {
+	// If the following call pancis, it indicates UnimplementedChordServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChordService_ServiceDesc, srv)
+}
+
+func _ChordService_FindSuccessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSuccessorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).FindSuccessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_FindSuccessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).FindSuccessor(ctx, req.(*FindSuccessorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Notify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Notify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Notify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Notify(ctx, req.(*NotifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_JoinSettled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinSettledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).JoinSettled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_JoinSettled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).JoinSettled(ctx, req.(*JoinSettledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_ClosestPrecedingFinger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosestPrecedingFingerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).ClosestPrecedingFinger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_ClosestPrecedingFinger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).ClosestPrecedingFinger(ctx, req.(*ClosestPrecedingFingerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetPeers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetPeers(ctx, req.(*GetPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetSuccessorList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSuccessorListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetSuccessorList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetSuccessorList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetSuccessorList(ctx, req.(*GetSuccessorListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetRoutingTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRoutingTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetRoutingTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetRoutingTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetRoutingTable(ctx, req.(*GetRoutingTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetDensity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDensityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetDensity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetDensity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetDensity(ctx, req.(*GetDensityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_RelayBroadcast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).RelayBroadcast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_RelayBroadcast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).RelayBroadcast(ctx, req.(*BroadcastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_PrepareHandoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareHandoffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).PrepareHandoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_PrepareHandoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).PrepareHandoff(ctx, req.(*PrepareHandoffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_CommitHandoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitHandoffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).CommitHandoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_CommitHandoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).CommitHandoff(ctx, req.(*CommitHandoffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_StreamHandoff_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamHandoffRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChordServiceServer).StreamHandoff(m, &grpc.GenericServerStream[StreamHandoffRequest, HandoffChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_StreamHandoffServer = grpc.ServerStreamingServer[HandoffChunk]
+
+func _ChordService_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Put_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_PutBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).PutBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_PutBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).PutBatch(ctx, req.(*PutBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetBatch(ctx, req.(*GetBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_ConditionalPut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConditionalPutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).ConditionalPut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_ConditionalPut_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).ConditionalPut(ctx, req.(*ConditionalPutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Undelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Undelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Undelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Undelete(ctx, req.(*UndeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Replicate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Replicate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Replicate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Replicate(ctx, req.(*ReplicateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_QueryTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).QueryTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_QueryTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).QueryTag(ctx, req.(*QueryTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_UpdateCRDT_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCRDTRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).UpdateCRDT(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_UpdateCRDT_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).UpdateCRDT(ctx, req.(*UpdateCRDTRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_AdvertiseCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdvertiseCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).AdvertiseCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_AdvertiseCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).AdvertiseCache(ctx, req.(*AdvertiseCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_CacheHotKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheHotKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).CacheHotKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_CacheHotKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).CacheHotKeys(ctx, req.(*CacheHotKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetHotKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHotKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetHotKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetHotKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetHotKeys(ctx, req.(*GetHotKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_ListBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).ListBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_ListBucket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).ListBucket(ctx, req.(*ListBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetBucketStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBucketStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetBucketStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetBucketStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetBucketStats(ctx, req.(*GetBucketStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_ListKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).ListKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_ListKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).ListKeys(ctx, req.(*ListKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_SetMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).SetMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_SetMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).SetMaintenance(ctx, req.(*SetMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetMaintenance(ctx, req.(*GetMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetMembershipHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMembershipHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetMembershipHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetMembershipHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetMembershipHistory(ctx, req.(*GetMembershipHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetStatsSample_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsSampleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetStatsSample(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetStatsSample_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetStatsSample(ctx, req.(*GetStatsSampleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetNodeStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetNodeStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetNodeStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetNodeStats(ctx, req.(*GetNodeStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_GetSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetSnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChordServiceServer).GetSnapshot(m, &grpc.GenericServerStream[GetSnapshotRequest, SnapshotChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_GetSnapshotServer = grpc.ServerStreamingServer[SnapshotChunk]
+
+func _ChordService_GetRingSnapshotPiece_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRingSnapshotPieceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).GetRingSnapshotPiece(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_GetRingSnapshotPiece_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).GetRingSnapshotPiece(ctx, req.(*GetRingSnapshotPieceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_CheckReachability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckReachabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).CheckReachability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_CheckReachability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).CheckReachability(ctx, req.(*CheckReachabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChordService_Relay_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChordServiceServer).Relay(&grpc.GenericServerStream[RelayFrame, RelayFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChordService_RelayServer = grpc.BidiStreamingServer[RelayFrame, RelayFrame]
+
+func _ChordService_Rendezvous_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RendezvousRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChordServiceServer).Rendezvous(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChordService_Rendezvous_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChordServiceServer).Rendezvous(ctx, req.(*RendezvousRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChordService_ServiceDesc is the grpc.ServiceDesc for ChordService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChordService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chord.v1.ChordService",
+	HandlerType: (*ChordServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FindSuccessor",
+			Handler:    _ChordService_FindSuccessor_Handler,
+		},
+		{
+			MethodName: "Notify",
+			Handler:    _ChordService_Notify_Handler,
+		},
+		{
+			MethodName: "JoinSettled",
+			Handler:    _ChordService_JoinSettled_Handler,
+		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _ChordService_GetInfo_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _ChordService_Ping_Handler,
+		},
+		{
+			MethodName: "ClosestPrecedingFinger",
+			Handler:    _ChordService_ClosestPrecedingFinger_Handler,
+		},
+		{
+			MethodName: "GetPeers",
+			Handler:    _ChordService_GetPeers_Handler,
+		},
+		{
+			MethodName: "GetSuccessorList",
+			Handler:    _ChordService_GetSuccessorList_Handler,
+		},
+		{
+			MethodName: "GetRoutingTable",
+			Handler:    _ChordService_GetRoutingTable_Handler,
+		},
+		{
+			MethodName: "GetDensity",
+			Handler:    _ChordService_GetDensity_Handler,
+		},
+		{
+			MethodName: "RelayBroadcast",
+			Handler:    _ChordService_RelayBroadcast_Handler,
+		},
+		{
+			MethodName: "PrepareHandoff",
+			Handler:    _ChordService_PrepareHandoff_Handler,
+		},
+		{
+			MethodName: "CommitHandoff",
+			Handler:    _ChordService_CommitHandoff_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _ChordService_Put_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _ChordService_Get_Handler,
+		},
+		{
+			MethodName: "PutBatch",
+			Handler:    _ChordService_PutBatch_Handler,
+		},
+		{
+			MethodName: "GetBatch",
+			Handler:    _ChordService_GetBatch_Handler,
+		},
+		{
+			MethodName: "ConditionalPut",
+			Handler:    _ChordService_ConditionalPut_Handler,
+		},
+		{
+			MethodName: "Undelete",
+			Handler:    _ChordService_Undelete_Handler,
+		},
+		{
+			MethodName: "Replicate",
+			Handler:    _ChordService_Replicate_Handler,
+		},
+		{
+			MethodName: "QueryTag",
+			Handler:    _ChordService_QueryTag_Handler,
+		},
+		{
+			MethodName: "UpdateCRDT",
+			Handler:    _ChordService_UpdateCRDT_Handler,
+		},
+		{
+			MethodName: "AdvertiseCache",
+			Handler:    _ChordService_AdvertiseCache_Handler,
+		},
+		{
+			MethodName: "CacheHotKeys",
+			Handler:    _ChordService_CacheHotKeys_Handler,
+		},
+		{
+			MethodName: "GetHotKeys",
+			Handler:    _ChordService_GetHotKeys_Handler,
+		},
+		{
+			MethodName: "ListBucket",
+			Handler:    _ChordService_ListBucket_Handler,
+		},
+		{
+			MethodName: "GetBucketStats",
+			Handler:    _ChordService_GetBucketStats_Handler,
+		},
+		{
+			MethodName: "ListKeys",
+			Handler:    _ChordService_ListKeys_Handler,
+		},
+		{
+			MethodName: "SetMaintenance",
+			Handler:    _ChordService_SetMaintenance_Handler,
+		},
+		{
+			MethodName: "GetMaintenance",
+			Handler:    _ChordService_GetMaintenance_Handler,
+		},
+		{
+			MethodName: "GetMembershipHistory",
+			Handler:    _ChordService_GetMembershipHistory_Handler,
+		},
+		{
+			MethodName: "GetStatsSample",
+			Handler:    _ChordService_GetStatsSample_Handler,
+		},
+		{
+			MethodName: "GetNodeStats",
+			Handler:    _ChordService_GetNodeStats_Handler,
+		},
+		{
+			MethodName: "GetRingSnapshotPiece",
+			Handler:    _ChordService_GetRingSnapshotPiece_Handler,
+		},
+		{
+			MethodName: "CheckReachability",
+			Handler:    _ChordService_CheckReachability_Handler,
+		},
+		{
+			MethodName: "Rendezvous",
+			Handler:    _ChordService_Rendezvous_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamHandoff",
+			Handler:       _ChordService_StreamHandoff_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetSnapshot",
+			Handler:       _ChordService_GetSnapshot_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Relay",
+			Handler:       _ChordService_Relay_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "chord/v1/chord.proto",
+}
@@ -0,0 +1,677 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: chord/v1/pubsub.proto
+
+package chordv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A message published to a topic
+type TopicMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`  // Assigned by the topic owner, increases per topic
+	Publisher     string                 `protobuf:"bytes,4,opt,name=publisher,proto3" json:"publisher,omitempty"` // Address of the node the message was published through
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicMessage) Reset() {
+	*x = TopicMessage{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicMessage) ProtoMessage() {}
+
+func (x *TopicMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicMessage.ProtoReflect.Descriptor instead.
+func (*TopicMessage) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TopicMessage) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *TopicMessage) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *TopicMessage) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *TopicMessage) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+// Request message for Subscribe
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+// Request/Response messages for Publish
+type PublishRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Publisher     string                 `protobuf:"bytes,3,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishRequest) Reset() {
+	*x = PublishRequest{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishRequest) ProtoMessage() {}
+
+func (x *PublishRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishRequest.ProtoReflect.Descriptor instead.
+func (*PublishRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PublishRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PublishRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PublishRequest) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+type PublishResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishResponse) Reset() {
+	*x = PublishResponse{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishResponse) ProtoMessage() {}
+
+func (x *PublishResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishResponse.ProtoReflect.Descriptor instead.
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PublishResponse) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *PublishResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PublishResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for JoinTopic (child starts receiving the topic)
+type JoinTopicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Child         string                 `protobuf:"bytes,2,opt,name=child,proto3" json:"child,omitempty"` // Address of the joining node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinTopicRequest) Reset() {
+	*x = JoinTopicRequest{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinTopicRequest) ProtoMessage() {}
+
+func (x *JoinTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinTopicRequest.ProtoReflect.Descriptor instead.
+func (*JoinTopicRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *JoinTopicRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *JoinTopicRequest) GetChild() string {
+	if x != nil {
+		return x.Child
+	}
+	return ""
+}
+
+type JoinTopicResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinTopicResponse) Reset() {
+	*x = JoinTopicResponse{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinTopicResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinTopicResponse) ProtoMessage() {}
+
+func (x *JoinTopicResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinTopicResponse.ProtoReflect.Descriptor instead.
+func (*JoinTopicResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JoinTopicResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *JoinTopicResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for LeaveTopic
+type LeaveTopicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Child         string                 `protobuf:"bytes,2,opt,name=child,proto3" json:"child,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveTopicRequest) Reset() {
+	*x = LeaveTopicRequest{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveTopicRequest) ProtoMessage() {}
+
+func (x *LeaveTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveTopicRequest.ProtoReflect.Descriptor instead.
+func (*LeaveTopicRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LeaveTopicRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *LeaveTopicRequest) GetChild() string {
+	if x != nil {
+		return x.Child
+	}
+	return ""
+}
+
+type LeaveTopicResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveTopicResponse) Reset() {
+	*x = LeaveTopicResponse{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveTopicResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveTopicResponse) ProtoMessage() {}
+
+func (x *LeaveTopicResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveTopicResponse.ProtoReflect.Descriptor instead.
+func (*LeaveTopicResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LeaveTopicResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *LeaveTopicResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request/Response messages for Deliver (parent forwards a message)
+type DeliverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *TopicMessage          `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliverRequest) Reset() {
+	*x = DeliverRequest{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliverRequest) ProtoMessage() {}
+
+func (x *DeliverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliverRequest.ProtoReflect.Descriptor instead.
+func (*DeliverRequest) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeliverRequest) GetMessage() *TopicMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type DeliverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliverResponse) Reset() {
+	*x = DeliverResponse{}
+	mi := &file_chord_v1_pubsub_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliverResponse) ProtoMessage() {}
+
+func (x *DeliverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chord_v1_pubsub_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliverResponse.ProtoReflect.Descriptor instead.
+func (*DeliverResponse) Descriptor() ([]byte, []int) {
+	return file_chord_v1_pubsub_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeliverResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeliverResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_chord_v1_pubsub_proto protoreflect.FileDescriptor
+
+const file_chord_v1_pubsub_proto_rawDesc = "" +
+	"\n" +
+	"\x15chord/v1/pubsub.proto\x12\bchord.v1\"x\n" +
+	"\fTopicMessage\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x12\x1c\n" +
+	"\tpublisher\x18\x04 \x01(\tR\tpublisher\"(\n" +
+	"\x10SubscribeRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\"^\n" +
+	"\x0ePublishRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x1c\n" +
+	"\tpublisher\x18\x03 \x01(\tR\tpublisher\"]\n" +
+	"\x0fPublishResponse\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\">\n" +
+	"\x10JoinTopicRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x14\n" +
+	"\x05child\x18\x02 \x01(\tR\x05child\"C\n" +
+	"\x11JoinTopicResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"?\n" +
+	"\x11LeaveTopicRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x14\n" +
+	"\x05child\x18\x02 \x01(\tR\x05child\"D\n" +
+	"\x12LeaveTopicResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"B\n" +
+	"\x0eDeliverRequest\x120\n" +
+	"\amessage\x18\x01 \x01(\v2\x16.chord.v1.TopicMessageR\amessage\"A\n" +
+	"\x0fDeliverResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xe1\x02\n" +
+	"\rPubSubService\x12A\n" +
+	"\tSubscribe\x12\x1a.chord.v1.SubscribeRequest\x1a\x16.chord.v1.TopicMessage0\x01\x12>\n" +
+	"\aPublish\x12\x18.chord.v1.PublishRequest\x1a\x19.chord.v1.PublishResponse\x12D\n" +
+	"\tJoinTopic\x12\x1a.chord.v1.JoinTopicRequest\x1a\x1b.chord.v1.JoinTopicResponse\x12G\n" +
+	"\n" +
+	"LeaveTopic\x12\x1b.chord.v1.LeaveTopicRequest\x1a\x1c.chord.v1.LeaveTopicResponse\x12>\n" +
+	"\aDeliver\x12\x18.chord.v1.DeliverRequest\x1a\x19.chord.v1.DeliverResponseB Z\x1echord-dht/api/chord/v1;chordv1b\x06proto3"
+
+var (
+	file_chord_v1_pubsub_proto_rawDescOnce sync.Once
+	file_chord_v1_pubsub_proto_rawDescData []byte
+)
+
+func file_chord_v1_pubsub_proto_rawDescGZIP() []byte {
+	file_chord_v1_pubsub_proto_rawDescOnce.Do(func() {
+		file_chord_v1_pubsub_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chord_v1_pubsub_proto_rawDesc), len(file_chord_v1_pubsub_proto_rawDesc)))
+	})
+	return file_chord_v1_pubsub_proto_rawDescData
+}
+
+var file_chord_v1_pubsub_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_chord_v1_pubsub_proto_goTypes = []any{
+	(*TopicMessage)(nil),       // 0: chord.v1.TopicMessage
+	(*SubscribeRequest)(nil),   // 1: chord.v1.SubscribeRequest
+	(*PublishRequest)(nil),     // 2: chord.v1.PublishRequest
+	(*PublishResponse)(nil),    // 3: chord.v1.PublishResponse
+	(*JoinTopicRequest)(nil),   // 4: chord.v1.JoinTopicRequest
+	(*JoinTopicResponse)(nil),  // 5: chord.v1.JoinTopicResponse
+	(*LeaveTopicRequest)(nil),  // 6: chord.v1.LeaveTopicRequest
+	(*LeaveTopicResponse)(nil), // 7: chord.v1.LeaveTopicResponse
+	(*DeliverRequest)(nil),     // 8: chord.v1.DeliverRequest
+	(*DeliverResponse)(nil),    // 9: chord.v1.DeliverResponse
+}
+var file_chord_v1_pubsub_proto_depIdxs = []int32{
+	0, // 0: chord.v1.DeliverRequest.message:type_name -> chord.v1.TopicMessage
+	1, // 1: chord.v1.PubSubService.Subscribe:input_type -> chord.v1.SubscribeRequest
+	2, // 2: chord.v1.PubSubService.Publish:input_type -> chord.v1.PublishRequest
+	4, // 3: chord.v1.PubSubService.JoinTopic:input_type -> chord.v1.JoinTopicRequest
+	6, // 4: chord.v1.PubSubService.LeaveTopic:input_type -> chord.v1.LeaveTopicRequest
+	8, // 5: chord.v1.PubSubService.Deliver:input_type -> chord.v1.DeliverRequest
+	0, // 6: chord.v1.PubSubService.Subscribe:output_type -> chord.v1.TopicMessage
+	3, // 7: chord.v1.PubSubService.Publish:output_type -> chord.v1.PublishResponse
+	5, // 8: chord.v1.PubSubService.JoinTopic:output_type -> chord.v1.JoinTopicResponse
+	7, // 9: chord.v1.PubSubService.LeaveTopic:output_type -> chord.v1.LeaveTopicResponse
+	9, // 10: chord.v1.PubSubService.Deliver:output_type -> chord.v1.DeliverResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_chord_v1_pubsub_proto_init() }
+func file_chord_v1_pubsub_proto_init() {
+	if File_chord_v1_pubsub_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chord_v1_pubsub_proto_rawDesc), len(file_chord_v1_pubsub_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chord_v1_pubsub_proto_goTypes,
+		DependencyIndexes: file_chord_v1_pubsub_proto_depIdxs,
+		MessageInfos:      file_chord_v1_pubsub_proto_msgTypes,
+	}.Build()
+	File_chord_v1_pubsub_proto = out.File
+	file_chord_v1_pubsub_proto_goTypes = nil
+	file_chord_v1_pubsub_proto_depIdxs = nil
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: chord/v1/pubsub.proto
+
+package chordv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PubSubService_Subscribe_FullMethodName  = "/chord.v1.PubSubService/Subscribe"
+	PubSubService_Publish_FullMethodName    = "/chord.v1.PubSubService/Publish"
+	PubSubService_JoinTopic_FullMethodName  = "/chord.v1.PubSubService/JoinTopic"
+	PubSubService_LeaveTopic_FullMethodName = "/chord.v1.PubSubService/LeaveTopic"
+	PubSubService_Deliver_FullMethodName    = "/chord.v1.PubSubService/Deliver"
+)
+
+// PubSubServiceClient is the client API for PubSubService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Publish/subscribe service served next to ChordService
+type PubSubServiceClient interface {
+	// Client-facing operations
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TopicMessage], error)
+	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Dissemination between nodes
+	JoinTopic(ctx context.Context, in *JoinTopicRequest, opts ...grpc.CallOption) (*JoinTopicResponse, error)
+	LeaveTopic(ctx context.Context, in *LeaveTopicRequest, opts ...grpc.CallOption) (*LeaveTopicResponse, error)
+	Deliver(ctx context.Context, in *DeliverRequest, opts ...grpc.CallOption) (*DeliverResponse, error)
+}
+
+type pubSubServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPubSubServiceClient(cc grpc.ClientConnInterface) PubSubServiceClient {
+	return &pubSubServiceClient{cc}
+}
+
+func (c *pubSubServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TopicMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PubSubService_ServiceDesc.Streams[0], PubSubService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, TopicMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PubSubService_SubscribeClient = grpc.ServerStreamingClient[TopicMessage]
+
+func (c *pubSubServiceClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishResponse)
+	err := c.cc.Invoke(ctx, PubSubService_Publish_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubServiceClient) JoinTopic(ctx context.Context, in *JoinTopicRequest, opts ...grpc.CallOption) (*JoinTopicResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinTopicResponse)
+	err := c.cc.Invoke(ctx, PubSubService_JoinTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubServiceClient) LeaveTopic(ctx context.Context, in *LeaveTopicRequest, opts ...grpc.CallOption) (*LeaveTopicResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaveTopicResponse)
+	err := c.cc.Invoke(ctx, PubSubService_LeaveTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubServiceClient) Deliver(ctx context.Context, in *DeliverRequest, opts ...grpc.CallOption) (*DeliverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeliverResponse)
+	err := c.cc.Invoke(ctx, PubSubService_Deliver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PubSubServiceServer is the server API for PubSubService service.
+// All implementations must embed UnimplementedPubSubServiceServer
+// for forward compatibility.
+//
+// Publish/subscribe service served next to ChordService
+type PubSubServiceServer interface {
+	// Client-facing operations
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[TopicMessage]) error
+	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	// Dissemination between nodes
+	JoinTopic(context.Context, *JoinTopicRequest) (*JoinTopicResponse, error)
+	LeaveTopic(context.Context, *LeaveTopicRequest) (*LeaveTopicResponse, error)
+	Deliver(context.Context, *DeliverRequest) (*DeliverResponse, error)
+	mustEmbedUnimplementedPubSubServiceServer()
+}
+
+// UnimplementedPubSubServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPubSubServiceServer struct{}
+
+func (UnimplementedPubSubServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[TopicMessage]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedPubSubServiceServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
+}
+func (UnimplementedPubSubServiceServer) JoinTopic(context.Context, *JoinTopicRequest) (*JoinTopicResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinTopic not implemented")
+}
+func (UnimplementedPubSubServiceServer) LeaveTopic(context.Context, *LeaveTopicRequest) (*LeaveTopicResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveTopic not implemented")
+}
+func (UnimplementedPubSubServiceServer) Deliver(context.Context, *DeliverRequest) (*DeliverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deliver not implemented")
+}
+func (UnimplementedPubSubServiceServer) mustEmbedUnimplementedPubSubServiceServer() {}
+func (UnimplementedPubSubServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafePubSubServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PubSubServiceServer will
+// result in compilation errors.
+type UnsafePubSubServiceServer interface {
+	mustEmbedUnimplementedPubSubServiceServer()
+}
+
+func RegisterPubSubServiceServer(s grpc.ServiceRegistrar, srv PubSubServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPubSubServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PubSubService_ServiceDesc, srv)
+}
+
+func _PubSubService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PubSubServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, TopicMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PubSubService_SubscribeServer = grpc.ServerStreamingServer[TopicMessage]
+
+func _PubSubService_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServiceServer).Publish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSubService_Publish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServiceServer).Publish(ctx, req.(*PublishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSubService_JoinTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServiceServer).JoinTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSubService_JoinTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServiceServer).JoinTopic(ctx, req.(*JoinTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSubService_LeaveTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServiceServer).LeaveTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSubService_LeaveTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServiceServer).LeaveTopic(ctx, req.(*LeaveTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSubService_Deliver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeliverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServiceServer).Deliver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSubService_Deliver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServiceServer).Deliver(ctx, req.(*DeliverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PubSubService_ServiceDesc is the grpc.ServiceDesc for PubSubService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PubSubService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chord.v1.PubSubService",
+	HandlerType: (*PubSubServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Publish",
+			Handler:    _PubSubService_Publish_Handler,
+		},
+		{
+			MethodName: "JoinTopic",
+			Handler:    _PubSubService_JoinTopic_Handler,
+		},
+		{
+			MethodName: "LeaveTopic",
+			Handler:    _PubSubService_LeaveTopic_Handler,
+		},
+		{
+			MethodName: "Deliver",
+			Handler:    _PubSubService_Deliver_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _PubSubService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "chord/v1/pubsub.proto",
+}
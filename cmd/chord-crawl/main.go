@@ -0,0 +1,147 @@
+// Command chord-crawl walks a Chord ring from any node and prints its
+// topology as JSON or Graphviz DOT, flagging inconsistent routing state.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/crawl"
+)
+
+// jsonNode is a node reference in the JSON output
+type jsonNode struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+	Zone    string `json:"zone,omitempty"`
+	Weight  uint32 `json:"weight,omitempty"`
+	Version uint32 `json:"version,omitempty"`
+}
+
+// jsonFinger is a distinct finger table entry with the first index using it
+type jsonFinger struct {
+	Index int `json:"index"`
+	jsonNode
+}
+
+// jsonState is a node's routing state in the JSON output
+type jsonState struct {
+	jsonNode
+	Predecessor *jsonNode    `json:"predecessor,omitempty"`
+	Successor   *jsonNode    `json:"successor,omitempty"`
+	Successors  []jsonNode   `json:"successors,omitempty"`
+	Fingers     []jsonFinger `json:"fingers,omitempty"`
+}
+
+// jsonIssue is an inconsistency in the JSON output
+type jsonIssue struct {
+	Kind    crawl.IssueKind `json:"kind"`
+	Address string          `json:"address"`
+	Detail  string          `json:"detail"`
+}
+
+// jsonTopology is the JSON output document
+type jsonTopology struct {
+	Start  string      `json:"start"`
+	Nodes  []jsonState `json:"nodes"`
+	Issues []jsonIssue `json:"issues"`
+}
+
+func main() {
+	var (
+		start      = flag.String("start", "localhost:5000", "Address of the node to start the walk at")
+		format     = flag.String("format", "json", "Output format: json or dot")
+		output     = flag.String("out", "", "Output file (defaults to stdout)")
+		timeout    = flag.Duration("timeout", crawl.DefaultTimeout, "Timeout per RPC")
+		maxNodes   = flag.Int("max-nodes", crawl.DefaultMaxNodes, "Stop walking after this many nodes")
+		fingers    = flag.Bool("fingers", false, "Draw finger edges in DOT output")
+		failIssues = flag.Bool("fail-on-issues", false, "Exit with status 1 if any inconsistency is found")
+	)
+	flag.Parse()
+
+	if *format != "json" && *format != "dot" {
+		log.Fatalf("Unknown format %q (want json or dot)", *format)
+	}
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = *timeout
+	crawler.MaxNodes = *maxNodes
+
+	topology, err := crawler.Topology(context.Background(), *start)
+	if err != nil {
+		log.Fatalf("Crawl failed: %v", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if *format == "dot" {
+		err = topology.WriteDOT(out, *fingers)
+	} else {
+		err = writeJSON(out, *start, topology)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write topology: %v", err)
+	}
+
+	log.Printf("Crawled %d nodes from %s, %d issues", len(topology.Nodes), *start, len(topology.Issues))
+	for _, issue := range topology.Issues {
+		log.Printf("  %-15s %s: %s", issue.Kind, issue.Node.Address, issue.Detail)
+	}
+	if *failIssues && len(topology.Issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+// toJSONNode converts an optional node reference
+func toJSONNode(node *chord.NodeInfo) *jsonNode {
+	if node == nil {
+		return nil
+	}
+	return &jsonNode{ID: node.ID.String(), Address: node.Address, Zone: node.Zone,
+		Weight: node.Weight, Version: node.Version}
+}
+
+// writeJSON writes the topology as an indented JSON document
+func writeJSON(w io.Writer, start string, topology *crawl.Topology) error {
+	doc := jsonTopology{Start: start, Nodes: []jsonState{}, Issues: []jsonIssue{}}
+	for _, state := range topology.Nodes {
+		js := jsonState{
+			jsonNode:    *toJSONNode(state.Node),
+			Predecessor: toJSONNode(state.Predecessor),
+			Successor:   toJSONNode(state.Successor),
+		}
+		for _, succ := range state.Successors {
+			js.Successors = append(js.Successors, *toJSONNode(succ))
+		}
+		seen := make(map[string]bool)
+		for i, finger := range state.Fingers {
+			if finger == nil || seen[finger.Address] {
+				continue
+			}
+			seen[finger.Address] = true
+			js.Fingers = append(js.Fingers, jsonFinger{Index: i, jsonNode: *toJSONNode(finger)})
+		}
+		doc.Nodes = append(doc.Nodes, js)
+	}
+	for _, issue := range topology.Issues {
+		doc.Issues = append(doc.Issues, jsonIssue{Kind: issue.Kind, Address: issue.Node.Address, Detail: issue.Detail})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,181 @@
+// Command chord-dns serves DNS from a Chord ring: A, AAAA and TXT queries
+// are answered from the records stored in the ring under the queried name.
+// Its set, get and delete commands manage those records.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	var (
+		ring    = flag.String("ring", "localhost:5000", "Address of any node in the ring")
+		listen  = flag.String("listen", ":5353", "UDP and TCP address to serve DNS on")
+		zone    = flag.String("zone", "", "Only answer names in this zone, refusing others (every name if empty)")
+		ttl     = flag.Uint("ttl", 60, "TTL in seconds of answers from records that set none")
+		timeout = flag.Duration("timeout", 2*time.Second, "Timeout of each RPC to the ring")
+	)
+	flag.Usage = usage
+	flag.Parse()
+
+	client := newRingClient(*ring, *timeout)
+	defer client.Close()
+
+	var err error
+	switch flag.Arg(0) {
+	case "", "serve":
+		s := &server{ring: client, zone: canonicalName(*zone), ttl: uint32(*ttl), timeout: *timeout}
+		err = serve(s, *listen)
+	case "set":
+		err = runSet(client, flag.Args()[1:])
+	case "get":
+		err = runGet(client, flag.Args()[1:])
+	case "delete":
+		err = runDelete(client, flag.Args()[1:])
+	default:
+		log.Printf("Unknown command %q", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// usage prints the commands and flags
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: chord-dns [flags] [command]\n\nCommands:\n")
+	fmt.Fprintf(out, "  serve                        answer DNS queries on --listen (the default)\n")
+	fmt.Fprintf(out, "  set NAME TYPE=VALUE...       replace the records of NAME; TYPE is a, aaaa, txt or ttl\n")
+	fmt.Fprintf(out, "  get NAME                     print the records of NAME as JSON\n")
+	fmt.Fprintf(out, "  delete NAME                  delete the records of NAME\n")
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// serve answers queries over UDP and TCP until either listener fails
+func serve(s *server, addr string) error {
+	packets, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", addr, err)
+	}
+	defer packets.Close()
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on tcp %s: %w", addr, err)
+	}
+	defer listener.Close()
+
+	zone := s.zone
+	if zone == "" {
+		zone = "every name"
+	}
+	log.Printf("Serving DNS for %s on %s through %s", zone, addr, s.ring.entry)
+
+	errs := make(chan error, 2)
+	go func() { errs <- s.serveUDP(packets) }()
+	go func() { errs <- s.serveTCP(listener) }()
+	return <-errs
+}
+
+// runSet replaces the records of a name
+func runSet(client *ringClient, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: chord-dns set NAME TYPE=VALUE...")
+	}
+	name := canonicalName(args[0])
+	var recs records
+	for _, arg := range args[1:] {
+		typ, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("record %q is not TYPE=VALUE", arg)
+		}
+		switch strings.ToLower(typ) {
+		case "a":
+			ip, err := netip.ParseAddr(value)
+			if err != nil || !ip.Is4() {
+				return fmt.Errorf("invalid IPv4 address %q", value)
+			}
+			recs.A = append(recs.A, ip.String())
+		case "aaaa":
+			ip, err := netip.ParseAddr(value)
+			if err != nil || !ip.Is6() {
+				return fmt.Errorf("invalid IPv6 address %q", value)
+			}
+			recs.AAAA = append(recs.AAAA, ip.String())
+		case "txt":
+			recs.TXT = append(recs.TXT, value)
+		case "ttl":
+			ttl, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid TTL %q", value)
+			}
+			recs.TTL = uint32(ttl)
+		default:
+			return fmt.Errorf("unknown record type %q (want a, aaaa, txt or ttl)", typ)
+		}
+	}
+
+	value, err := json.Marshal(recs)
+	if err != nil {
+		return err
+	}
+	if err := client.put(context.Background(), name, value); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", name, value)
+	return nil
+}
+
+// runGet prints the records of a name
+func runGet(client *ringClient, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chord-dns get NAME")
+	}
+	name := canonicalName(args[0])
+	value, found, err := client.get(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%s has no records", name)
+	}
+	fmt.Printf("%s\n", value)
+	return nil
+}
+
+// runDelete deletes the records of a name
+func runDelete(client *ringClient, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chord-dns delete NAME")
+	}
+	ctx := context.Background()
+	name := canonicalName(args[0])
+	value, found, err := client.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%s has no records", name)
+	}
+	deleted, err := client.delete(ctx, name, value)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("the records of %s changed meanwhile, not deleted", name)
+	}
+	fmt.Printf("%s: deleted\n", name)
+	return nil
+}
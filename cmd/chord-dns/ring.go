@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ringClient reads and writes keys on their owners, found through a node of
+// the ring
+type ringClient struct {
+	entry   string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// newRingClient returns a client that looks keys up through entry
+func newRingClient(entry string, timeout time.Duration) *ringClient {
+	return &ringClient{entry: entry, timeout: timeout, conns: make(map[string]*grpc.ClientConn)}
+}
+
+// client returns a client of the node at address, reusing connections
+func (c *ringClient) client(address string) (pb.ChordServiceClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, ok := c.conns[address]
+	if !ok {
+		var err error
+		options := append(chord.RequestDialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err = grpc.NewClient(address, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+		c.conns[address] = conn
+	}
+	return pb.NewChordServiceClient(conn), nil
+}
+
+// owner returns a client of the node responsible for key
+func (c *ringClient) owner(ctx context.Context, key string) (pb.ChordServiceClient, error) {
+	entry, err := c.client(c.entry)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := entry.FindSuccessor(ctx, &pb.FindSuccessorRequest{Key: hash.NewHashFromString(key).String()})
+	if err != nil {
+		return nil, fmt.Errorf("lookup of %q failed: %w", key, err)
+	}
+	if !resp.Success || resp.Successor == nil {
+		return nil, fmt.Errorf("lookup of %q failed: %s", key, resp.Error)
+	}
+	return c.client(resp.Successor.Address)
+}
+
+// get reads key from its owner
+func (c *ringClient) get(ctx context.Context, key string) ([]byte, bool, error) {
+	owner, err := c.owner(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := owner.Get(ctx, &pb.GetRequest{Key: key})
+	if err != nil {
+		return nil, false, fmt.Errorf("get of %q failed: %w", key, err)
+	}
+	if !resp.Success {
+		return nil, false, fmt.Errorf("get of %q failed: %s", key, resp.Error)
+	}
+	return resp.Value, resp.Found, nil
+}
+
+// put writes key on its owner
+func (c *ringClient) put(ctx context.Context, key string, value []byte) error {
+	owner, err := c.owner(ctx, key)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := owner.Put(ctx, &pb.PutRequest{Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("put of %q failed: %w", key, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("put of %q failed: %s", key, resp.Error)
+	}
+	return nil
+}
+
+// delete deletes key on its owner if it still holds value, and reports
+// whether it did
+func (c *ringClient) delete(ctx context.Context, key string, value []byte) (bool, error) {
+	owner, err := c.owner(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := owner.ConditionalPut(ctx, &pb.ConditionalPutRequest{Key: key, Expected: value, Delete: true})
+	if err != nil {
+		return false, fmt.Errorf("delete of %q failed: %w", key, err)
+	}
+	if resp.Error != "" {
+		return false, fmt.Errorf("delete of %q failed: %s", key, resp.Error)
+	}
+	return resp.Applied, nil
+}
+
+// Close closes the connections to nodes
+func (c *ringClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// maxUDPSize is the largest UDP answer to a query without EDNS
+	maxUDPSize = 512
+	// ednsUDPSize is the UDP payload size this server advertises with EDNS
+	ednsUDPSize = 4096
+	// maxTXTString is the longest character string of a TXT record
+	maxTXTString = 255
+	// tcpIdleTimeout closes TCP connections that send no query for a while
+	tcpIdleTimeout = 10 * time.Second
+)
+
+// records are the DNS records of a name, stored as JSON under the name
+type records struct {
+	// TTL is the TTL of answers in seconds; the --ttl flag if zero
+	TTL  uint32   `json:"ttl,omitempty"`
+	A    []string `json:"a,omitempty"`
+	AAAA []string `json:"aaaa,omitempty"`
+	TXT  []string `json:"txt,omitempty"`
+}
+
+// canonicalName returns the key of a domain name: lower case without the
+// trailing dot
+func canonicalName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// server answers DNS queries from records stored in the ring
+type server struct {
+	ring    *ringClient
+	zone    string
+	ttl     uint32
+	timeout time.Duration
+}
+
+// inZone reports whether name is served
+func (s *server) inZone(name string) bool {
+	return s.zone == "" || name == s.zone || strings.HasSuffix(name, "."+s.zone)
+}
+
+// serveUDP answers queries arriving on conn until it is closed
+func (s *server) serveUDP(conn net.PacketConn) error {
+	buf := make([]byte, ednsUDPSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		query := append([]byte(nil), buf[:n]...)
+		go func() {
+			if resp := s.answer(query, true); resp != nil {
+				conn.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+// serveTCP answers queries on the connections accepted by listener until it
+// is closed
+func (s *server) serveTCP(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveTCPConn(conn)
+	}
+}
+
+// serveTCPConn answers the length-prefixed queries of one connection
+func (s *server) serveTCPConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+		resp := s.answer(query, false)
+		if resp == nil {
+			return
+		}
+		out := binary.BigEndian.AppendUint16(nil, uint16(len(resp)))
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer builds the response to a query, nil if the query cannot be
+// parsed far enough to answer. UDP answers larger than the client accepts
+// are truncated, telling it to retry over TCP.
+func (s *server) answer(query []byte, udp bool) []byte {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	if err != nil || header.Response {
+		return nil
+	}
+	resp := dnsmessage.Header{
+		ID:               header.ID,
+		Response:         true,
+		OpCode:           header.OpCode,
+		RecursionDesired: header.RecursionDesired,
+	}
+
+	question, err := p.Question()
+	if err != nil {
+		resp.RCode = dnsmessage.RCodeFormatError
+		return build(resp, nil, nil, false)
+	}
+	limit, edns := maxUDPSize, false
+	if p.SkipAllQuestions() == nil && p.SkipAllAnswers() == nil && p.SkipAllAuthorities() == nil {
+		for {
+			h, err := p.AdditionalHeader()
+			if err != nil {
+				break
+			}
+			if h.Type == dnsmessage.TypeOPT {
+				edns = true
+				limit = min(max(int(h.Class), maxUDPSize), ednsUDPSize)
+			}
+			if p.SkipAdditional() != nil {
+				break
+			}
+		}
+	}
+
+	var answers []answerRecord
+	switch {
+	case header.OpCode != 0:
+		resp.RCode = dnsmessage.RCodeNotImplemented
+	case question.Class != dnsmessage.ClassINET || !s.inZone(canonicalName(question.Name.String())):
+		resp.RCode = dnsmessage.RCodeRefused
+	default:
+		resp.Authoritative = true
+		answers, resp.RCode = s.resolve(question)
+	}
+
+	msg := build(resp, &question, answers, edns)
+	if udp && len(msg) > limit {
+		resp.Truncated = true
+		msg = build(resp, &question, nil, edns)
+	}
+	return msg
+}
+
+// answerRecord is one record of an answer
+type answerRecord struct {
+	header dnsmessage.ResourceHeader
+	body   dnsmessage.ResourceBody
+}
+
+// resolve reads the records of the queried name from the ring and returns
+// those of the queried type. A name without records is NXDOMAIN; one
+// without records of the type is answered with none.
+func (s *server) resolve(question dnsmessage.Question) ([]answerRecord, dnsmessage.RCode) {
+	name := canonicalName(question.Name.String())
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	value, found, err := s.ring.get(ctx, name)
+	if err != nil {
+		log.Printf("Failed to read records of %s: %v", name, err)
+		return nil, dnsmessage.RCodeServerFailure
+	}
+	if !found {
+		return nil, dnsmessage.RCodeNameError
+	}
+	var recs records
+	if err := json.Unmarshal(value, &recs); err != nil {
+		log.Printf("Invalid records of %s: %v", name, err)
+		return nil, dnsmessage.RCodeServerFailure
+	}
+
+	ttl := recs.TTL
+	if ttl == 0 {
+		ttl = s.ttl
+	}
+	header := func(typ dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: question.Name, Type: typ, Class: dnsmessage.ClassINET, TTL: ttl}
+	}
+
+	var answers []answerRecord
+	switch question.Type {
+	case dnsmessage.TypeA:
+		for _, addr := range recs.A {
+			ip, err := netip.ParseAddr(addr)
+			if err != nil || !ip.Is4() {
+				log.Printf("Invalid A record %q of %s", addr, name)
+				continue
+			}
+			answers = append(answers, answerRecord{header(dnsmessage.TypeA), &dnsmessage.AResource{A: ip.As4()}})
+		}
+	case dnsmessage.TypeAAAA:
+		for _, addr := range recs.AAAA {
+			ip, err := netip.ParseAddr(addr)
+			if err != nil || !ip.Is6() {
+				log.Printf("Invalid AAAA record %q of %s", addr, name)
+				continue
+			}
+			answers = append(answers, answerRecord{header(dnsmessage.TypeAAAA), &dnsmessage.AAAAResource{AAAA: ip.As16()}})
+		}
+	case dnsmessage.TypeTXT:
+		for _, text := range recs.TXT {
+			answers = append(answers, answerRecord{header(dnsmessage.TypeTXT), &dnsmessage.TXTResource{TXT: splitTXT(text)}})
+		}
+	}
+	return answers, dnsmessage.RCodeSuccess
+}
+
+// splitTXT splits text into the character strings of a TXT record
+func splitTXT(text string) []string {
+	if text == "" {
+		return []string{""}
+	}
+	var parts []string
+	for len(text) > maxTXTString {
+		parts = append(parts, text[:maxTXTString])
+		text = text[maxTXTString:]
+	}
+	return append(parts, text)
+}
+
+// build encodes a response, with an EDNS record if the query had one
+func build(header dnsmessage.Header, question *dnsmessage.Question, answers []answerRecord, edns bool) []byte {
+	b := dnsmessage.NewBuilder(nil, header)
+	b.EnableCompression()
+	msg, err := func() ([]byte, error) {
+		if err := b.StartQuestions(); err != nil {
+			return nil, err
+		}
+		if question != nil {
+			if err := b.Question(*question); err != nil {
+				return nil, err
+			}
+		}
+		if err := b.StartAnswers(); err != nil {
+			return nil, err
+		}
+		for _, answer := range answers {
+			var err error
+			switch body := answer.body.(type) {
+			case *dnsmessage.AResource:
+				err = b.AResource(answer.header, *body)
+			case *dnsmessage.AAAAResource:
+				err = b.AAAAResource(answer.header, *body)
+			case *dnsmessage.TXTResource:
+				err = b.TXTResource(answer.header, *body)
+			default:
+				err = fmt.Errorf("unexpected record %T", body)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if edns {
+			if err := b.StartAdditionals(); err != nil {
+				return nil, err
+			}
+			var opt dnsmessage.ResourceHeader
+			if err := opt.SetEDNS0(ednsUDPSize, dnsmessage.RCodeSuccess, false); err != nil {
+				return nil, err
+			}
+			if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+				return nil, err
+			}
+		}
+		return b.Finish()
+	}()
+	if err != nil {
+		log.Printf("Failed to build response: %v", err)
+		return nil
+	}
+	return msg
+}
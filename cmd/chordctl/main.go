@@ -0,0 +1,995 @@
+// Command chordctl administers a running Chord ring through any of its
+// nodes.
+//
+// Usage:
+//
+//	chordctl [flags] status          show the maintenance state of every node
+//	chordctl [flags] pause [reason]  pause data migrations across the ring
+//	chordctl [flags] resume          resume them and report the backlog
+//	chordctl [flags] history         show recent joins and departures
+//	chordctl [flags] topology        show every node's zone, weight and version
+//	chordctl [flags] stats           sample every node's counters at one epoch
+//	chordctl [flags] inspect         show the detailed counters of the --addr node
+//	chordctl [flags] hotkeys [N]     show the N most read keys of the --addr node
+//	chordctl [flags] load [TARGET]   show the keyspace and key share of every node
+//	chordctl [flags] snapshot FILE   save a snapshot of the --addr node
+//	chordctl [flags] undelete KEY... restore deleted keys from the trash
+//	chordctl [flags] buckets [NAME]  show the keys, bytes and traffic of buckets
+//	chordctl [flags] ls BUCKET       list the keys of a bucket
+//	chordctl [flags] export --out FILE [--prefix P] [--parallelism N] [--batch-size N]
+//	                                 dump every key of the ring to NDJSON
+//	chordctl [flags] import [--rate N] [--parallelism N] [--batch-size N] FILE
+//	                                 bulk-load the keys of an NDJSON file
+//	chordctl [flags] backup FILE     snapshot the whole ring, writing the manifest to FILE
+//	chordctl [flags] restore [--rate N] [--parallelism N] [--batch-size N] FILE
+//	                                 write the keys of a ring snapshot to the ring
+//	chordctl [flags] token SECRET_FILE SUBJECT SCOPE...
+//	                                 issue an access token for clients
+//	chordctl [flags] shell [--admin-addr ADDR]
+//	                                 run commands interactively against the --addr node
+//
+// Every command prints a table by default, or a JSON document with
+// --output json.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/middleware"
+	"chord-dht/internal/crawl"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// command is a chordctl subcommand
+type command struct {
+	usage string
+	run   func(ctx context.Context, addr string, args []string) error
+}
+
+var commands = map[string]command{
+	"status":   {"show the maintenance state of every node", runStatus},
+	"pause":    {"pause data migrations and anti-entropy across the ring", runPause},
+	"resume":   {"resume them and report the work deferred meanwhile", runResume},
+	"history":  {"show the joins and departures seen by every node", runHistory},
+	"topology": {"show the zone, capacity weight and protocol version of every node", runTopology},
+	"stats":    {"sample the message and lookup counters of every node at one moment", runStats},
+	"inspect":  {"show the RPC, traffic and storage counters of the --addr node", runInspect},
+	"hotkeys":  {"show the keys owned by the --addr node with the highest read rates", runHotKeys},
+	"load":     {"show the keyspace arc and stored keys of every node, and the virtual nodes for an imbalance target", runLoad},
+	"snapshot": {"save the ID, routing state and keys of the --addr node to a file", runSnapshot},
+	"undelete": {"restore the last deleted value of keys still in their owner's trash", runUndelete},
+	"buckets":  {"show the keys, bytes, reads, writes and quota of every bucket, or of one, across the ring", runBuckets},
+	"ls":       {"list the keys of a bucket across the ring", runList},
+	"export":   {"write every key and value of the ring to an NDJSON file, or - for stdout (--out)", runExport},
+	"import":   {"write the keys and values of an NDJSON file, or - for stdin, to their owners in batches", runImport},
+	"backup":   {"snapshot the key range of every node at one marker broadcast and write the manifest tying the pieces together to a file", runBackup},
+	"restore":  {"check the pieces of a ring snapshot against its manifest and write their keys to the ring", runRestore},
+	"token":    {"issue an access token with scopes such as photos:read or *:write, signed with the ring's secret", runToken},
+}
+
+var (
+	timeout  time.Duration
+	output   string
+	tokenTTL time.Duration
+	// placement is how the ring places keys, for the commands finding the
+	// owners of keys themselves
+	placement hash.Provider
+)
+
+// placements are the hash functions --hash selects
+var placements = map[string]hash.Provider{
+	"sha1":     hash.SHA1,
+	"identity": hash.Identity,
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:5000", "Address of any node in the ring")
+	flag.DurationVar(&timeout, "timeout", crawl.DefaultTimeout, "Timeout per RPC")
+	flag.StringVar(&output, "output", outputTable, "Output format: table or json")
+	client := flag.String("client", "chordctl", "Client name sent with every RPC, seen by the nodes' logs and middleware")
+	token := flag.String("token", "", "Access token sent with every RPC, for rings whose nodes require one (see the token command)")
+	flag.DurationVar(&tokenTTL, "token-ttl", 24*time.Hour, "Lifetime of the tokens issued by the token command (0 never expires)")
+	hashName := flag.String("hash", "sha1", "Hash function the ring places keys with, for import, restore and the shell: sha1 or identity")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := checkOutput(output); err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	var ok bool
+	if placement, ok = placements[*hashName]; !ok {
+		log.Printf("Unknown --hash %q (want sha1 or identity)", *hashName)
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		log.Printf("Unknown command %q", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	// One trace for all the RPCs of the command
+	ctx := chord.WithRequest(context.Background(), chord.Request{Client: *client, TraceID: chord.NewTraceID()})
+	if *token != "" {
+		ctx = middleware.WithAccessToken(ctx, *token)
+	}
+	if err := cmd.run(ctx, *addr, flag.Args()[1:]); err != nil {
+		log.Fatalf("%s failed: %v", flag.Arg(0), err)
+	}
+}
+
+// usage prints the commands and flags
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: chordctl [flags] <command> [args]\n\nCommands:\n")
+	for _, name := range []string{"status", "pause", "resume", "history", "topology", "stats", "inspect", "hotkeys", "load", "snapshot", "undelete", "buckets", "ls", "export", "import", "backup", "restore", "token", "shell"} {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// dialOptions returns the options of chordctl's connections to nodes
+func dialOptions() []grpc.DialOption {
+	return append(chord.RequestDialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// runStatus prints the maintenance state of every node in the ring
+func runStatus(ctx context.Context, addr string, args []string) error {
+	statuses, err := ringMaintenance(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(toJSONStatus(statuses))
+	}
+	printBacklog(statuses)
+	return nil
+}
+
+// runPause pauses the ring with an optional reason
+func runPause(ctx context.Context, addr string, args []string) error {
+	reason := strings.Join(args, " ")
+	if reason == "" {
+		reason = "maintenance window"
+	}
+
+	resp, err := setMaintenance(ctx, addr, &pb.SetMaintenanceRequest{Paused: true, Reason: reason, Ring: true})
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(jsonChange{Action: "pause", Reached: resp.Reached, Reason: reason})
+	}
+	fmt.Printf("Paused %d nodes: %s\n", resp.Reached, reason)
+	return nil
+}
+
+// runResume reports the backlog of every node and resumes the ring
+func runResume(ctx context.Context, addr string, args []string) error {
+	statuses, err := ringMaintenance(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	resp, err := setMaintenance(ctx, addr, &pb.SetMaintenanceRequest{Paused: false, Ring: true})
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(jsonChange{Action: "resume", Reached: resp.Reached, Backlog: toJSONStatus(statuses)})
+	}
+	fmt.Printf("Resumed %d nodes, catching up on:\n\n", resp.Reached)
+	printBacklog(statuses)
+	return nil
+}
+
+// runHistory prints the membership events of every node as one timeline
+func runHistory(ctx context.Context, addr string, args []string) error {
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	events, err := crawler.History(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(toJSONHistory(events))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tOBSERVER\tEVENT\tROLE\tNODE\tADDRESS\tREPLACED")
+	for _, event := range events {
+		replaced := "-"
+		if event.Previous != nil {
+			replaced = event.Previous.ID.Short()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", event.Time.Format("15:04:05.000"),
+			event.Observer.ID.Short(), event.Kind, event.Role, event.Node.ID.Short(),
+			event.Node.Address, replaced)
+	}
+	w.Flush()
+	fmt.Printf("\n%d events\n", len(events))
+	return nil
+}
+
+// runTopology prints what every node advertises about itself, and how the
+// ring's nodes and capacity weight spread over zones
+func runTopology(ctx context.Context, addr string, args []string) error {
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	topology, err := crawler.Topology(ctx, addr)
+	if err != nil {
+		return err
+	}
+	nodes := make([]*chord.NodeInfo, 0, len(topology.Nodes))
+	for _, state := range topology.Nodes {
+		nodes = append(nodes, state.Node)
+	}
+	zones := zoneTotals(nodes)
+	if output == outputJSON {
+		return writeJSON(toJSONTopology(nodes, zones))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tZONE\tWEIGHT\tVERSION")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", node.ID.Short(), node.Address, zoneName(node.Zone),
+			node.EffectiveWeight(), versionName(node.Version))
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ZONE\tNODES\tWEIGHT\tSHARE")
+	for _, zone := range zones {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\n", zoneName(zone.Zone), zone.Nodes, zone.Weight, 100*zone.Share)
+	}
+	w.Flush()
+	for _, issue := range topology.Issues {
+		if issue.Kind == crawl.IssueUnreachable {
+			fmt.Printf("\nWalk incomplete: %s: %s\n", issue.Node.Address, issue.Detail)
+		}
+	}
+	return nil
+}
+
+// runStats starts a stats epoch and prints the counters every node sampled
+// at it, with the ring-wide messages per lookup
+func runStats(ctx context.Context, addr string, args []string) error {
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	stats, err := crawler.StatsEpoch(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(toJSONStats(stats))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tSAMPLED\tMESSAGES\tLOOKUPS")
+	for _, sample := range stats.Samples {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", sample.Node.ID.Short(), sample.Node.Address,
+			sample.Time.Format("15:04:05.000"), sample.Messages, sample.Lookups)
+	}
+	w.Flush()
+	fmt.Printf("\nEpoch %d: %d nodes sampled within %v, %d messages, %d lookups, %.2f messages per lookup\n",
+		stats.Epoch, len(stats.Samples), stats.Spread, stats.Messages, stats.Lookups, stats.MessagesPerLookup())
+	if len(stats.Missing) > 0 {
+		fmt.Printf("Not sampled: %s\n", strings.Join(stats.Missing, ", "))
+	}
+	return nil
+}
+
+// runInspect prints the counters of the node at addr
+func runInspect(ctx context.Context, addr string, args []string) error {
+	conn, err := grpc.NewClient(addr, dialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := pb.NewChordServiceClient(conn).GetNodeStats(rpcCtx, &pb.GetNodeStatsRequest{})
+	if err != nil {
+		return err
+	}
+	stats := chord.StatsFromProto(resp.Stats)
+	if output == outputJSON {
+		return writeJSON(toJSONInspect(addr, stats))
+	}
+
+	last := "never"
+	if !stats.LastStabilization.IsZero() {
+		last = time.Since(stats.LastStabilization).Truncate(time.Millisecond).String() + " ago"
+	}
+	fmt.Printf("Node %s, up %v\n", addr, stats.Uptime.Truncate(time.Second))
+	fmt.Printf("Messages: %d, lookups: %d\n", stats.Messages, stats.Lookups)
+	fmt.Printf("Traffic: %d bytes sent, %d received over %d open connections\n",
+		stats.BytesSent, stats.BytesReceived, stats.ActiveConnections)
+	fmt.Printf("Stored keys: %d\n", stats.StoredKeys)
+	fmt.Printf("Last stabilization: %s\n\n", last)
+
+	methods := make([]string, 0, len(stats.RPCs))
+	for method := range stats.RPCs {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return stats.RPCs[methods[i]] > stats.RPCs[methods[j]]
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RPC\tSERVED")
+	for _, method := range methods {
+		fmt.Fprintf(w, "%s\t%d\n", method, stats.RPCs[method])
+	}
+	return w.Flush()
+}
+
+// runHotKeys prints the hottest keys owned by the node at addr, ten unless
+// a number is given
+func runHotKeys(ctx context.Context, addr string, args []string) error {
+	limit := chord.DefaultHotKeys
+	if len(args) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(args[0]); err != nil || limit <= 0 {
+			return fmt.Errorf("usage: chordctl hotkeys [N]")
+		}
+	}
+
+	conn, err := grpc.NewClient(addr, dialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := pb.NewChordServiceClient(conn).GetHotKeys(rpcCtx, &pb.GetHotKeysRequest{Limit: int32(limit)})
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		doc := jsonHotKeys{Address: addr, Keys: make([]jsonHotKey, 0, len(resp.Keys))}
+		for _, key := range resp.Keys {
+			doc.Keys = append(doc.Keys, jsonHotKey{Key: key.Key, Rate: key.Rate, Copies: key.Copies})
+		}
+		return writeJSON(doc)
+	}
+
+	if len(resp.Keys) == 0 {
+		fmt.Printf("Node %s has served no recent reads of its keys\n", addr)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tREADS/S\tCOPIES")
+	for _, key := range resp.Keys {
+		fmt.Fprintf(w, "%s\t%.2f\t%d\n", key.Key, key.Rate, key.Copies)
+	}
+	return w.Flush()
+}
+
+// runLoad prints the arc and stored keys of every node against its share of
+// the ring's weight, and the virtual nodes per node that would bring the arc
+// imbalance down to a target, 1.25 unless given
+func runLoad(ctx context.Context, addr string, args []string) error {
+	target := crawl.DefaultTargetImbalance
+	if len(args) > 0 {
+		var err error
+		if target, err = strconv.ParseFloat(args[0], 64); err != nil || target < 1 {
+			return fmt.Errorf("usage: chordctl load [TARGET], with TARGET at least 1")
+		}
+	}
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	report, err := crawler.Load(ctx, addr)
+	if err != nil {
+		return err
+	}
+	expected := crawl.ExpectedImbalance(len(report.Nodes), 1)
+	vnodes, reached := crawl.VirtualNodesFor(len(report.Nodes), target)
+	if output == outputJSON {
+		doc := jsonLoad{
+			Nodes:             make([]jsonNodeLoad, 0, len(report.Nodes)),
+			Keys:              report.Keys,
+			ArcImbalance:      report.ArcImbalance,
+			KeyImbalance:      report.KeyImbalance,
+			ExpectedImbalance: expected,
+			TargetImbalance:   target,
+			VirtualNodes:      vnodes,
+			TargetReached:     reached,
+		}
+		for _, load := range report.Nodes {
+			doc.Nodes = append(doc.Nodes, jsonNodeLoad{
+				ID:          load.Node.ID.String(),
+				Address:     load.Node.Address,
+				Weight:      load.Node.EffectiveWeight(),
+				Share:       load.Share,
+				ArcFraction: load.ArcFraction,
+				Keys:        load.Keys,
+			})
+		}
+		return writeJSON(doc)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tSHARE\tARC\tKEYS\tARC/SHARE\tKEYS/SHARE")
+	for _, load := range report.Nodes {
+		keys := "-"
+		if report.Keys > 0 {
+			keys = fmt.Sprintf("%.2f", float64(load.Keys)/float64(report.Keys)/load.Share)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.4f\t%.4f\t%d\t%.2f\t%s\n", load.Node.ID.Short(), load.Node.Address,
+			load.Share, load.ArcFraction, load.Keys, load.ArcFraction/load.Share, keys)
+	}
+	w.Flush()
+	fmt.Printf("\n%d nodes, %d keys: arc imbalance %.2f, key imbalance %.2f (%.2f expected with one position per node)\n",
+		len(report.Nodes), report.Keys, report.ArcImbalance, report.KeyImbalance, expected)
+	if reached {
+		fmt.Printf("%d virtual nodes per node would bring the expected arc imbalance to %.2f, within %.2f\n",
+			vnodes, crawl.ExpectedImbalance(len(report.Nodes), vnodes), target)
+	} else {
+		fmt.Printf("Even %d virtual nodes per node would not bring the expected arc imbalance within %.2f\n",
+			vnodes, target)
+	}
+	return nil
+}
+
+// zoneTotal is the share of a ring's nodes and capacity in one zone
+type zoneTotal struct {
+	Zone   string
+	Nodes  int
+	Weight uint32
+	// Share is the zone's fraction of the ring's total weight
+	Share float64
+}
+
+// zoneTotals sums nodes and weights by zone, largest share first
+func zoneTotals(nodes []*chord.NodeInfo) []zoneTotal {
+	byZone := make(map[string]*zoneTotal)
+	var totals []*zoneTotal
+	var weight uint32
+	for _, node := range nodes {
+		total, ok := byZone[node.Zone]
+		if !ok {
+			total = &zoneTotal{Zone: node.Zone}
+			byZone[node.Zone] = total
+			totals = append(totals, total)
+		}
+		total.Nodes++
+		total.Weight += node.EffectiveWeight()
+		weight += node.EffectiveWeight()
+	}
+
+	result := make([]zoneTotal, 0, len(totals))
+	for _, total := range totals {
+		total.Share = float64(total.Weight) / float64(weight)
+		result = append(result, *total)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Weight > result[j].Weight
+	})
+	return result
+}
+
+// zoneName formats a zone label, which is empty when unknown
+func zoneName(zone string) string {
+	if zone == "" {
+		return "-"
+	}
+	return zone
+}
+
+// versionName formats a protocol version, which is zero when unknown
+func versionName(version uint32) string {
+	if version == 0 {
+		return "-"
+	}
+	return strconv.FormatUint(uint64(version), 10)
+}
+
+// runSnapshot streams a snapshot of the node at addr to a file, checks that
+// it reads back and reports what it holds
+func runSnapshot(ctx context.Context, addr string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chordctl snapshot FILE")
+	}
+	path := args[0]
+
+	conn, err := grpc.NewClient(addr, dialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewChordServiceClient(conn).GetSnapshot(ctx, &pb.GetSnapshotRequest{})
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	size, err := receiveSnapshot(stream, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	file, err = os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := chord.ReadSnapshot(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if output == outputJSON {
+		return writeJSON(jsonSnapshot{File: path, Node: info.State.ID, Address: info.State.Address,
+			Entries: info.Entries, Bytes: size})
+	}
+	fmt.Printf("Saved node %s (%s) to %s: %d keys, %d bytes\n",
+		info.State.ID[:8], info.State.Address, path, info.Entries, size)
+	return nil
+}
+
+// runUndelete restores deleted keys through the node at addr, which forwards
+// each to its owner
+func runUndelete(ctx context.Context, addr string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: chordctl undelete KEY...")
+	}
+
+	conn, err := grpc.NewClient(addr, dialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+	client := pb.NewChordServiceClient(conn)
+
+	results := make([]jsonUndeletedKey, 0, len(args))
+	failed := 0
+	for _, key := range args {
+		result := jsonUndeletedKey{Key: key}
+		rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := client.Undelete(rpcCtx, &pb.UndeleteRequest{Key: key, Route: true})
+		cancel()
+		if err == nil && !resp.Success {
+			err = fmt.Errorf("%s", resp.Error)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			result.Version = resp.Version
+		}
+		results = append(results, result)
+	}
+
+	if output == outputJSON {
+		if err := writeJSON(jsonUndelete{Keys: results}); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Printf("%s: %s\n", result.Key, result.Error)
+				continue
+			}
+			fmt.Printf("%s: restored at version %d\n", result.Key, result.Version)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys not restored", failed, len(args))
+	}
+	return nil
+}
+
+// runBuckets prints the stats of every bucket across the ring, or of the
+// one named
+func runBuckets(ctx context.Context, addr string, args []string) error {
+	bucket := ""
+	if len(args) > 0 {
+		bucket = args[0]
+		if err := chord.ValidBucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	stats, err := crawler.Buckets(ctx, addr, bucket)
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		doc := jsonBuckets{Buckets: make([]jsonBucket, 0, len(stats))}
+		for _, s := range stats {
+			doc.Buckets = append(doc.Buckets, jsonBucket{
+				Bucket:   s.Bucket,
+				Keys:     s.Keys,
+				Bytes:    s.Bytes,
+				Reads:    s.Reads,
+				Writes:   s.Writes,
+				MaxKeys:  s.Quota.MaxKeys,
+				MaxBytes: s.Quota.MaxBytes,
+			})
+		}
+		return writeJSON(doc)
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No bucket stores keys in the ring")
+		return nil
+	}
+	limit := func(n int64) string {
+		if n == 0 {
+			return "-"
+		}
+		return strconv.FormatInt(n, 10)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BUCKET\tKEYS\tBYTES\tREADS\tWRITES\tMAX KEYS/NODE\tMAX BYTES/NODE")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Bucket, s.Keys, s.Bytes, s.Reads, s.Writes,
+			limit(s.Quota.MaxKeys), limit(s.Quota.MaxBytes))
+	}
+	return w.Flush()
+}
+
+// runList prints the keys of a bucket across the ring
+func runList(ctx context.Context, addr string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chordctl ls BUCKET")
+	}
+	if err := chord.ValidBucket(args[0]); err != nil {
+		return err
+	}
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	keys, err := crawler.BucketKeys(ctx, addr, args[0])
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		return writeJSON(jsonBucketKeys{Bucket: args[0], Keys: keys})
+	}
+	for _, key := range keys {
+		fmt.Println(key)
+	}
+	return nil
+}
+
+// datasetFlags parses the flags of export and import, which follow the
+// command name
+func datasetFlags(name string, args []string, out *string) (crawl.DatasetOptions, []string, error) {
+	opts := crawl.DatasetOptions{Placement: placement}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.IntVar(&opts.Parallelism, "parallelism", crawl.DefaultParallelism, "Number of batches in flight at once")
+	flags.IntVar(&opts.BatchSize, "batch-size", crawl.DefaultBatchSize, "Number of keys per batch RPC")
+	if out != nil {
+		flags.StringVar(out, "out", "", "File to write the keys to, - for stdout")
+		flags.StringVar(&opts.Prefix, "prefix", "", "Export only the keys starting with this prefix")
+	} else {
+		flags.Float64Var(&opts.Rate, "rate", 0, "Keys written per second (0 for no limit)")
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	return opts, flags.Args(), nil
+}
+
+// reportProgress returns a progress callback printing the totals to stderr
+// at most once a second
+func reportProgress(verb string) func(crawl.DatasetProgress) {
+	last := time.Now()
+	return func(p crawl.DatasetProgress) {
+		if time.Since(last) < time.Second {
+			return
+		}
+		last = time.Now()
+		fmt.Fprintf(os.Stderr, "%s %d keys, %d bytes\n", verb, p.Keys, p.Bytes)
+	}
+}
+
+// printDataset prints the totals of an export or import, on stderr if the
+// keys went to stdout
+func printDataset(verb, file string, totals crawl.DatasetProgress) error {
+	if output == outputJSON {
+		return writeJSON(jsonDataset{File: file, Keys: totals.Keys, Bytes: totals.Bytes})
+	}
+	w := os.Stdout
+	if file == "-" {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "%s %d keys, %d bytes (%s)\n", verb, totals.Keys, totals.Bytes, file)
+	return nil
+}
+
+// runExport writes every key of the ring and its value to a file, one JSON
+// record per line, for seeding experiments or moving the data to another
+// ring with import
+func runExport(ctx context.Context, addr string, args []string) error {
+	var path string
+	opts, rest, err := datasetFlags("export", args, &path)
+	if err != nil {
+		return err
+	}
+	if path == "" || len(rest) != 0 {
+		return fmt.Errorf("usage: chordctl export --out FILE [--prefix P] [--parallelism N] [--batch-size N]")
+	}
+	if path == "-" && output == outputJSON {
+		return fmt.Errorf("--output json needs stdout, export with --out FILE")
+	}
+	opts.Progress = reportProgress("Exported")
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	var file *os.File
+	if path == "-" {
+		file = os.Stdout
+	} else if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600); err != nil {
+		return err
+	}
+	totals, err := crawler.Export(ctx, addr, file, opts)
+	if path != "-" {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return printDataset("Exported", path, totals)
+}
+
+// runImport writes the keys of a file written by export, or of any file of
+// {"key": ..., "value": base64} lines, to the ring
+func runImport(ctx context.Context, addr string, args []string) error {
+	opts, rest, err := datasetFlags("import", args, nil)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: chordctl import [--rate N] [--parallelism N] [--batch-size N] FILE")
+	}
+	path := rest[0]
+	opts.Progress = reportProgress("Imported")
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	file := os.Stdin
+	if path != "-" {
+		if file, err = os.Open(path); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+	totals, err := crawler.Import(ctx, addr, file, opts)
+	if err != nil {
+		return fmt.Errorf("%w (%d keys imported)", err, totals.Keys)
+	}
+	return printDataset("Imported", path, totals)
+}
+
+// runBackup takes a ring snapshot and saves its manifest. The nodes write
+// their pieces to their own snapshot directories.
+func runBackup(ctx context.Context, addr string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: chordctl backup FILE")
+	}
+	path := args[0]
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	manifest, err := crawler.RingSnapshot(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if err := crawl.SaveManifest(path, manifest); err != nil {
+		return err
+	}
+
+	doc := jsonBackup{Manifest: path, ID: manifest.ID, Pieces: len(manifest.Pieces),
+		Complete: manifest.Complete, Missing: manifest.Missing}
+	for _, piece := range manifest.Pieces {
+		doc.Entries += piece.Entries
+		doc.Bytes += piece.Bytes
+	}
+	if output == outputJSON {
+		return writeJSON(doc)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tENTRIES\tBYTES\tFILE")
+	for _, piece := range manifest.Pieces {
+		file := piece.File
+		if piece.Error != "" {
+			file = "error: " + piece.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", shortID(piece.Node), piece.Address, piece.Entries, piece.Bytes, file)
+	}
+	w.Flush()
+	for _, address := range manifest.Missing {
+		fmt.Printf("No piece from %s\n", address)
+	}
+	state := "complete"
+	if !manifest.Complete {
+		state = "incomplete"
+	}
+	fmt.Printf("\nRing snapshot %d %s: %d pieces, %d entries, %d bytes; manifest %s\n",
+		manifest.ID, state, doc.Pieces, doc.Entries, doc.Bytes, path)
+	return nil
+}
+
+// runRestore writes the live keys of a ring snapshot to the ring, which may
+// be another one than was snapshotted
+func runRestore(ctx context.Context, addr string, args []string) error {
+	opts, rest, err := datasetFlags("restore", args, nil)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: chordctl restore [--rate N] [--parallelism N] [--batch-size N] FILE")
+	}
+	opts.Progress = reportProgress("Restored")
+
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	totals, err := crawler.RestoreRingSnapshot(ctx, addr, rest[0], opts)
+	if err != nil {
+		return fmt.Errorf("%w (%d keys restored)", err, totals.Keys)
+	}
+	return printDataset("Restored", rest[0], totals)
+}
+
+// runToken prints an access token for a subject with the given scopes,
+// signed with the secret in a file
+func runToken(ctx context.Context, addr string, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: chordctl token SECRET_FILE SUBJECT SCOPE...")
+	}
+	secret, err := middleware.LoadAccessSecret(args[0])
+	if err != nil {
+		return err
+	}
+	claims := middleware.AccessClaims{Subject: args[1], Scopes: args[2:]}
+	if tokenTTL > 0 {
+		claims.ExpiresAt = time.Now().Add(tokenTTL)
+	}
+	token, err := middleware.IssueToken(secret, claims)
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		doc := jsonToken{Token: token, Subject: claims.Subject, Scopes: claims.Scopes}
+		if !claims.ExpiresAt.IsZero() {
+			doc.ExpiresAt = &claims.ExpiresAt
+		}
+		return writeJSON(doc)
+	}
+	fmt.Println(token)
+	return nil
+}
+
+// receiveSnapshot writes the chunks of a snapshot stream to w and returns
+// the number of bytes written
+func receiveSnapshot(stream grpc.ServerStreamingClient[pb.SnapshotChunk], w io.Writer) (int64, error) {
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return size, err
+		}
+		n, err := w.Write(chunk.Data)
+		size += int64(n)
+		if err != nil {
+			return size, err
+		}
+	}
+}
+
+// ringMaintenance collects the maintenance status of every node
+func ringMaintenance(ctx context.Context, addr string) ([]chord.MaintenanceStatus, error) {
+	crawler := crawl.New()
+	defer crawler.Close()
+	crawler.Timeout = timeout
+
+	return crawler.Maintenance(ctx, addr)
+}
+
+// setMaintenance sends a SetMaintenance request to the node at addr
+func setMaintenance(ctx context.Context, addr string, req *pb.SetMaintenanceRequest) (*pb.SetMaintenanceResponse, error) {
+	conn, err := grpc.NewClient(addr, dialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := pb.NewChordServiceClient(conn).SetMaintenance(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	return resp, nil
+}
+
+// printBacklog prints one row per node with its pause state and deferred work
+func printBacklog(statuses []chord.MaintenanceStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tSTATE\tPAUSED FOR\tPENDING HANDOFF\tPENDING REPLICATION\tREASON")
+
+	var keys, handoffs int
+	for _, status := range statuses {
+		state, pausedFor := "running", "-"
+		if status.Paused {
+			state = "paused"
+			pausedFor = time.Since(status.Since).Truncate(time.Second).String()
+		}
+		if status.PendingHandoff {
+			handoffs++
+		}
+		keys += status.PendingReplication
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\t%d\t%s\n", status.Node.ID.Short(), status.Node.Address,
+			state, pausedFor, status.PendingHandoff, status.PendingReplication, status.Reason)
+	}
+	w.Flush()
+	fmt.Printf("\n%d nodes, %d pending hand-offs, %d keys awaiting replication\n", len(statuses), handoffs, keys)
+}
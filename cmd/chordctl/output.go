@@ -0,0 +1,436 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/crawl"
+)
+
+// Output formats selected with --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// jsonNodeStatus is a node's maintenance state in the JSON output
+type jsonNodeStatus struct {
+	ID                 string     `json:"id"`
+	Address            string     `json:"address"`
+	Paused             bool       `json:"paused"`
+	Reason             string     `json:"reason,omitempty"`
+	Since              *time.Time `json:"since,omitempty"`
+	PendingHandoff     bool       `json:"pending_handoff"`
+	PendingReplication int        `json:"pending_replication"`
+}
+
+// jsonTotals sums the deferred work of the ring in the JSON output
+type jsonTotals struct {
+	Nodes              int `json:"nodes"`
+	PendingHandoffs    int `json:"pending_handoffs"`
+	PendingReplication int `json:"pending_replication"`
+}
+
+// jsonStatus is the output document of status, and the backlog of resume
+type jsonStatus struct {
+	Nodes  []jsonNodeStatus `json:"nodes"`
+	Totals jsonTotals       `json:"totals"`
+}
+
+// jsonChange is the output document of pause and resume
+type jsonChange struct {
+	Action  string      `json:"action"`
+	Reached int32       `json:"reached"`
+	Reason  string      `json:"reason,omitempty"`
+	Backlog *jsonStatus `json:"backlog,omitempty"`
+}
+
+// jsonNode is a node reference in the JSON output
+type jsonNode struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// jsonEvent is a membership event in the JSON output
+type jsonEvent struct {
+	Time     time.Time `json:"time"`
+	Observer jsonNode  `json:"observer"`
+	Seq      uint64    `json:"seq"`
+	Kind     string    `json:"kind"`
+	Role     string    `json:"role"`
+	Node     jsonNode  `json:"node"`
+	Previous *jsonNode `json:"previous,omitempty"`
+}
+
+// jsonHistory is the output document of history
+type jsonHistory struct {
+	Events []jsonEvent `json:"events"`
+}
+
+// jsonSnapshot is the output document of snapshot
+type jsonSnapshot struct {
+	File    string `json:"file"`
+	Node    string `json:"node"`
+	Address string `json:"address"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// jsonSample is a node's sampled counters in the JSON output
+type jsonSample struct {
+	ID       string    `json:"id"`
+	Address  string    `json:"address"`
+	Time     time.Time `json:"time"`
+	Messages int64     `json:"messages"`
+	Lookups  int64     `json:"lookups"`
+}
+
+// jsonInspect is the output document of inspect
+type jsonInspect struct {
+	Address           string           `json:"address"`
+	Messages          int64            `json:"messages"`
+	Lookups           int64            `json:"lookups"`
+	RPCs              map[string]int64 `json:"rpcs"`
+	BytesSent         int64            `json:"bytes_sent"`
+	BytesReceived     int64            `json:"bytes_received"`
+	ActiveConnections int              `json:"active_connections"`
+	StoredKeys        int              `json:"stored_keys"`
+	UptimeSeconds     float64          `json:"uptime_seconds"`
+	LastStabilization *time.Time       `json:"last_stabilization,omitempty"`
+}
+
+// jsonHotKey is a key in the output document of hotkeys
+type jsonHotKey struct {
+	Key    string  `json:"key"`
+	Rate   float64 `json:"rate"`
+	Copies int32   `json:"copies"`
+}
+
+// jsonHotKeys is the output document of hotkeys
+type jsonHotKeys struct {
+	Address string       `json:"address"`
+	Keys    []jsonHotKey `json:"keys"`
+}
+
+// jsonNodeLoad is a node in the output document of load
+type jsonNodeLoad struct {
+	ID          string  `json:"id"`
+	Address     string  `json:"address"`
+	Weight      uint32  `json:"weight"`
+	Share       float64 `json:"share"`
+	ArcFraction float64 `json:"arc_fraction"`
+	Keys        int64   `json:"keys"`
+}
+
+// jsonLoad is the output document of load
+type jsonLoad struct {
+	Nodes             []jsonNodeLoad `json:"nodes"`
+	Keys              int64          `json:"keys"`
+	ArcImbalance      float64        `json:"arc_imbalance"`
+	KeyImbalance      float64        `json:"key_imbalance"`
+	ExpectedImbalance float64        `json:"expected_imbalance"`
+	TargetImbalance   float64        `json:"target_imbalance"`
+	VirtualNodes      int            `json:"virtual_nodes"`
+	TargetReached     bool           `json:"target_reached"`
+}
+
+// jsonStats is the output document of stats
+type jsonStats struct {
+	Epoch             uint64       `json:"epoch"`
+	Nodes             []jsonSample `json:"nodes"`
+	Missing           []string     `json:"missing,omitempty"`
+	Messages          int64        `json:"messages"`
+	Lookups           int64        `json:"lookups"`
+	MessagesPerLookup float64      `json:"messages_per_lookup"`
+	SpreadMs          int64        `json:"spread_ms"`
+}
+
+// jsonUndeletedKey is a key in the output document of undelete
+type jsonUndeletedKey struct {
+	Key     string `json:"key"`
+	Version uint64 `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// jsonUndelete is the output document of undelete
+type jsonUndelete struct {
+	Keys []jsonUndeletedKey `json:"keys"`
+}
+
+// jsonBucket is a bucket in the output document of buckets
+type jsonBucket struct {
+	Bucket   string `json:"bucket"`
+	Keys     int64  `json:"keys"`
+	Bytes    int64  `json:"bytes"`
+	Reads    int64  `json:"reads"`
+	Writes   int64  `json:"writes"`
+	MaxKeys  int64  `json:"max_keys,omitempty"`
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+}
+
+// jsonBuckets is the output document of buckets
+type jsonBuckets struct {
+	Buckets []jsonBucket `json:"buckets"`
+}
+
+// jsonBucketKeys is the output document of ls
+type jsonBucketKeys struct {
+	Bucket string   `json:"bucket"`
+	Keys   []string `json:"keys"`
+}
+
+// jsonDataset is the output document of export and import
+type jsonDataset struct {
+	File  string `json:"file"`
+	Keys  int64  `json:"keys"`
+	Bytes int64  `json:"bytes"`
+}
+
+// jsonBackup is the output document of backup
+type jsonBackup struct {
+	Manifest string   `json:"manifest"`
+	ID       uint64   `json:"id"`
+	Pieces   int      `json:"pieces"`
+	Entries  int      `json:"entries"`
+	Bytes    int64    `json:"bytes"`
+	Complete bool     `json:"complete"`
+	Missing  []string `json:"missing,omitempty"`
+}
+
+// jsonToken is the output document of token
+type jsonToken struct {
+	Token     string     `json:"token"`
+	Subject   string     `json:"subject"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// jsonTopologyNode is what a node advertises in the JSON output
+type jsonTopologyNode struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+	Zone    string `json:"zone,omitempty"`
+	Weight  uint32 `json:"weight"`
+	Version uint32 `json:"version,omitempty"`
+}
+
+// jsonZone is a zone's share of the ring in the JSON output
+type jsonZone struct {
+	Zone   string  `json:"zone"`
+	Nodes  int     `json:"nodes"`
+	Weight uint32  `json:"weight"`
+	Share  float64 `json:"share"`
+}
+
+// jsonTopology is the output document of topology
+type jsonTopology struct {
+	Nodes []jsonTopologyNode `json:"nodes"`
+	Zones []jsonZone         `json:"zones"`
+}
+
+// jsonLookup is the output document of the shell's lookup
+type jsonLookup struct {
+	Key     string   `json:"key"`
+	KeyID   string   `json:"key_id"`
+	Owner   jsonNode `json:"owner"`
+	Hops    int32    `json:"hops"`
+	Latency int64    `json:"latency_us"`
+}
+
+// jsonValue is the output document of the shell's get
+type jsonValue struct {
+	Key   string   `json:"key"`
+	Owner jsonNode `json:"owner"`
+	Found bool     `json:"found"`
+	Value []byte   `json:"value,omitempty"`
+	Stale bool     `json:"stale,omitempty"`
+}
+
+// jsonPut is the output document of the shell's put
+type jsonPut struct {
+	Key      string   `json:"key"`
+	Owner    jsonNode `json:"owner"`
+	Buffered bool     `json:"buffered"`
+}
+
+// jsonRingNode is a node met walking the ring in the JSON output
+type jsonRingNode struct {
+	ID          string `json:"id"`
+	Address     string `json:"address"`
+	Predecessor string `json:"predecessor,omitempty"`
+	Pressure    string `json:"pressure"`
+}
+
+// jsonRing is the output document of the shell's ring
+type jsonRing struct {
+	Nodes []jsonRingNode `json:"nodes"`
+}
+
+// jsonFinger is a finger table entry in the JSON output
+type jsonFinger struct {
+	Index int       `json:"index"`
+	Start string    `json:"start"`
+	Node  *jsonNode `json:"node,omitempty"`
+}
+
+// jsonRoutingTable is the output document of the shell's fingers
+type jsonRoutingTable struct {
+	Node        jsonNode     `json:"node"`
+	Predecessor *jsonNode    `json:"predecessor,omitempty"`
+	Successors  []jsonNode   `json:"successors"`
+	Fingers     []jsonFinger `json:"fingers"`
+}
+
+// toJSONNodeInfo converts a node reference, nil if unset
+func toJSONNodeInfo(node *chord.NodeInfo) *jsonNode {
+	if node == nil {
+		return nil
+	}
+	return &jsonNode{ID: node.ID.String(), Address: node.Address}
+}
+
+// toJSONRoutingTable converts a node's neighbors and finger table
+func toJSONRoutingTable(table *chord.RoutingTable) *jsonRoutingTable {
+	doc := &jsonRoutingTable{
+		Node:        *toJSONNodeInfo(table.Self),
+		Predecessor: toJSONNodeInfo(table.Predecessor),
+		Successors:  []jsonNode{},
+		Fingers:     make([]jsonFinger, 0, len(table.Fingers)),
+	}
+	for _, successor := range table.Successors {
+		doc.Successors = append(doc.Successors, *toJSONNodeInfo(successor))
+	}
+	for _, finger := range table.Fingers {
+		doc.Fingers = append(doc.Fingers, jsonFinger{
+			Index: finger.Index,
+			Start: finger.Start.String(),
+			Node:  toJSONNodeInfo(finger.Node),
+		})
+	}
+	return doc
+}
+
+// toJSONTopology converts the nodes of the ring and their zone totals
+func toJSONTopology(nodes []*chord.NodeInfo, zones []zoneTotal) *jsonTopology {
+	doc := &jsonTopology{Nodes: []jsonTopologyNode{}, Zones: []jsonZone{}}
+	for _, node := range nodes {
+		doc.Nodes = append(doc.Nodes, jsonTopologyNode{
+			ID:      node.ID.String(),
+			Address: node.Address,
+			Zone:    node.Zone,
+			Weight:  node.EffectiveWeight(),
+			Version: node.Version,
+		})
+	}
+	for _, zone := range zones {
+		doc.Zones = append(doc.Zones, jsonZone(zone))
+	}
+	return doc
+}
+
+// toJSONHistory converts the membership timeline of the ring
+func toJSONHistory(events []crawl.ObservedEvent) *jsonHistory {
+	doc := &jsonHistory{Events: []jsonEvent{}}
+	for _, event := range events {
+		e := jsonEvent{
+			Time:     event.Time.UTC(),
+			Observer: jsonNode{ID: event.Observer.ID.String(), Address: event.Observer.Address},
+			Seq:      event.Seq,
+			Kind:     string(event.Kind),
+			Role:     event.Role,
+			Node:     jsonNode{ID: event.Node.ID.String(), Address: event.Node.Address},
+		}
+		if event.Previous != nil {
+			e.Previous = &jsonNode{ID: event.Previous.ID.String(), Address: event.Previous.Address}
+		}
+		doc.Events = append(doc.Events, e)
+	}
+	return doc
+}
+
+// toJSONStatus converts the maintenance state of the ring
+func toJSONStatus(statuses []chord.MaintenanceStatus) *jsonStatus {
+	doc := &jsonStatus{Nodes: []jsonNodeStatus{}}
+	for _, status := range statuses {
+		node := jsonNodeStatus{
+			ID:                 status.Node.ID.String(),
+			Address:            status.Node.Address,
+			Paused:             status.Paused,
+			Reason:             status.Reason,
+			PendingHandoff:     status.PendingHandoff,
+			PendingReplication: status.PendingReplication,
+		}
+		if status.Paused {
+			since := status.Since.UTC()
+			node.Since = &since
+		}
+		if status.PendingHandoff {
+			doc.Totals.PendingHandoffs++
+		}
+		doc.Totals.PendingReplication += status.PendingReplication
+		doc.Nodes = append(doc.Nodes, node)
+	}
+	doc.Totals.Nodes = len(statuses)
+	return doc
+}
+
+// toJSONStats converts the samples of a stats epoch
+func toJSONStats(stats *crawl.EpochStats) *jsonStats {
+	doc := &jsonStats{
+		Epoch:             stats.Epoch,
+		Nodes:             make([]jsonSample, 0, len(stats.Samples)),
+		Missing:           stats.Missing,
+		Messages:          stats.Messages,
+		Lookups:           stats.Lookups,
+		MessagesPerLookup: stats.MessagesPerLookup(),
+		SpreadMs:          stats.Spread.Milliseconds(),
+	}
+	for _, sample := range stats.Samples {
+		doc.Nodes = append(doc.Nodes, jsonSample{
+			ID:       sample.Node.ID.String(),
+			Address:  sample.Node.Address,
+			Time:     sample.Time,
+			Messages: sample.Messages,
+			Lookups:  sample.Lookups,
+		})
+	}
+	return doc
+}
+
+// toJSONInspect converts the counters of the node at address
+func toJSONInspect(address string, stats chord.Stats) *jsonInspect {
+	doc := &jsonInspect{
+		Address:           address,
+		Messages:          stats.Messages,
+		Lookups:           stats.Lookups,
+		RPCs:              stats.RPCs,
+		BytesSent:         stats.BytesSent,
+		BytesReceived:     stats.BytesReceived,
+		ActiveConnections: stats.ActiveConnections,
+		StoredKeys:        stats.StoredKeys,
+		UptimeSeconds:     stats.Uptime.Seconds(),
+	}
+	if !stats.LastStabilization.IsZero() {
+		last := stats.LastStabilization.UTC()
+		doc.LastStabilization = &last
+	}
+	return doc
+}
+
+// writeJSON writes doc to stdout as an indented JSON document
+func writeJSON(doc any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// checkOutput validates the --output flag
+func checkOutput(format string) error {
+	if format != outputTable && format != outputJSON {
+		return fmt.Errorf("unknown output format %q (want %s or %s)", format, outputTable, outputJSON)
+	}
+	return nil
+}
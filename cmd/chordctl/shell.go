@@ -0,0 +1,651 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/internal/crawl"
+	"chord-dht/pkg/hash"
+
+	"golang.org/x/term"
+	"google.golang.org/grpc"
+)
+
+// shellCommand is a command only the interactive shell has; every other
+// chordctl command runs in the shell against the node it is connected to
+type shellCommand struct {
+	args  string
+	usage string
+	run   func(s *shell, ctx context.Context, args []string) error
+}
+
+var shellCommands = map[string]shellCommand{
+	"connect": {"ADDR [ADMIN_ADDR]", "switch to another node, and its admin server for watch", (*shell).connect},
+	"lookup":  {"KEY", "show the owner of a key and the hops to find it", (*shell).lookup},
+	"get":     {"KEY", "read a key from its owner", (*shell).get},
+	"put":     {"KEY VALUE", "write the rest of the line to a key's owner", (*shell).put},
+	"ring":    {"", "walk the ring by successor pointers from the node", (*shell).ring},
+	"fingers": {"", "show the node's predecessor, successor list and finger table", (*shell).fingers},
+	"watch":   {"[TYPES]", "stream the node's topology, transfer and lookup events until Ctrl-C", (*shell).watch},
+}
+
+// The shell runs the other commands, so it joins them once they are set
+func init() {
+	commands["shell"] = command{"run commands against the --addr node interactively, with tab completion and history", runShell}
+}
+
+// shell is the state of an interactive session
+type shell struct {
+	addr      string
+	adminAddr string
+	conns     map[string]*grpc.ClientConn
+	// Addresses and keys met during the session, offered by tab completion
+	addresses map[string]bool
+	keys      map[string]bool
+}
+
+// runShell reads commands from stdin until exit or end of input, with line
+// editing, history and tab completion on a terminal
+func runShell(ctx context.Context, addr string, args []string) error {
+	flags := flag.NewFlagSet("shell", flag.ContinueOnError)
+	adminAddr := flags.String("admin-addr", "", "Admin server of the node, for watch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("usage: chordctl shell [--admin-addr ADDR]")
+	}
+
+	s := &shell{
+		addr:      addr,
+		adminAddr: *adminAddr,
+		conns:     make(map[string]*grpc.ClientConn),
+		addresses: map[string]bool{addr: true},
+		keys:      make(map[string]bool),
+	}
+	defer s.close()
+
+	readLine := s.lineReader()
+	for {
+		line, err := readLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "exit" || fields[0] == "quit" {
+			return nil
+		}
+
+		// Ctrl-C stops the command rather than the shell, and every
+		// command is a trace of its own
+		cmdCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		if req, ok := chord.RequestFrom(ctx); ok {
+			req.TraceID = chord.NewTraceID()
+			cmdCtx = chord.WithRequest(cmdCtx, req)
+		}
+		err = s.run(cmdCtx, fields[0], fields[1:])
+		if cmdCtx.Err() != nil {
+			// Past the ^C the terminal echoed
+			fmt.Println()
+		}
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", fields[0], err)
+		}
+	}
+}
+
+// lineReader returns the function reading the next command: from a
+// terminal in raw mode with line editing, or line by line from a pipe
+func (s *shell) lineReader() func() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		scanner := bufio.NewScanner(os.Stdin)
+		return func() (string, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			return scanner.Text(), nil
+		}
+	}
+
+	fmt.Printf("Connected to %s. Tab completes, help lists the commands, Ctrl-D exits.\n", s.addr)
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	t.AutoCompleteCallback = s.complete
+	// Raw mode only lasts while a line is edited, so commands print as
+	// they do outside the shell and Ctrl-C interrupts them
+	return func() (string, error) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", err
+		}
+		defer term.Restore(fd, state)
+		t.SetPrompt(s.addr + "> ")
+		return t.ReadLine()
+	}
+}
+
+// run runs a shell or chordctl command
+func (s *shell) run(ctx context.Context, name string, args []string) error {
+	if name == "help" {
+		s.help()
+		return nil
+	}
+	if cmd, ok := shellCommands[name]; ok {
+		return cmd.run(s, ctx, args)
+	}
+	if cmd, ok := commands[name]; ok && name != "shell" {
+		return cmd.run(ctx, s.addr, args)
+	}
+	return fmt.Errorf("unknown command, see help")
+}
+
+// help prints the shell's commands, then chordctl's
+func (s *shell) help() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range sortedNames(shellCommands) {
+		cmd := shellCommands[name]
+		fmt.Fprintf(w, "%s %s\t%s\n", name, cmd.args, cmd.usage)
+	}
+	fmt.Fprintf(w, "exit\tleave the shell\n")
+	w.Flush()
+	fmt.Printf("\nAny chordctl command also runs against the node, such as inspect for its counters:\n")
+	fmt.Printf("  %s\n", strings.Join(otherCommands(), " "))
+}
+
+// complete completes the word before the cursor on tab: command names
+// first, then the node addresses, keys and event types met so far
+func (s *shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	prefix := line[start:pos]
+
+	var candidates []string
+	switch fields := strings.Fields(line[:start]); {
+	case len(fields) == 0:
+		candidates = append(sortedNames(shellCommands), "help", "exit")
+		candidates = append(candidates, otherCommands()...)
+	case fields[0] == "connect":
+		candidates = sortedNames(s.addresses)
+	case fields[0] == "lookup" || fields[0] == "get" || fields[0] == "put":
+		if len(fields) == 1 {
+			candidates = sortedNames(s.keys)
+		}
+	case fields[0] == "watch":
+		candidates = []string{chord.EventTopology, chord.EventTransfer, chord.EventLookup}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	// Complete up to where the matches differ, and past a unique one
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(matches) == 1 {
+		common += " "
+	}
+	return line[:start] + common + line[pos:], start + len(common), true
+}
+
+// client returns a client of the node at address over a connection kept
+// for the session
+func (s *shell) client(address string) (pb.ChordServiceClient, error) {
+	conn, ok := s.conns[address]
+	if !ok {
+		var err error
+		if conn, err = grpc.NewClient(address, dialOptions()...); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+		s.conns[address] = conn
+	}
+	return pb.NewChordServiceClient(conn), nil
+}
+
+// close closes the session's connections
+func (s *shell) close() {
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// connect switches the session to the node at args[0]
+func (s *shell) connect(ctx context.Context, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: connect ADDR [ADMIN_ADDR]")
+	}
+	client, err := s.client(args[0])
+	if err != nil {
+		return err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.GetInfo(rpcCtx, &pb.GetInfoRequest{})
+	if err != nil {
+		return err
+	}
+
+	s.addr = args[0]
+	s.addresses[s.addr] = true
+	s.adminAddr = ""
+	if len(args) == 2 {
+		s.adminAddr = args[1]
+	}
+	if output == outputJSON {
+		return writeJSON(jsonNode{ID: resp.Node.GetId(), Address: s.addr})
+	}
+	fmt.Printf("Connected to %s (%s)\n", shortID(resp.Node.GetId()), s.addr)
+	return nil
+}
+
+// keyID returns the position of key on the ring, as hex, placed with the
+// --hash function
+func keyID(key string) string {
+	return placement.Hash(key).String()
+}
+
+// findOwner looks key up through the node, returning its owner and the
+// hops the lookup took
+func (s *shell) findOwner(ctx context.Context, key string) (*pb.Node, int32, error) {
+	client, err := s.client(s.addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.FindSuccessor(rpcCtx, &pb.FindSuccessorRequest{Key: keyID(key)})
+	if err != nil {
+		return nil, 0, err
+	}
+	if !resp.Success {
+		return nil, 0, fmt.Errorf("%s", resp.Error)
+	}
+	s.keys[key] = true
+	s.addresses[resp.Successor.GetAddress()] = true
+	return resp.Successor, resp.Hops, nil
+}
+
+// lookup prints the owner of args[0]
+func (s *shell) lookup(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: lookup KEY")
+	}
+	started := time.Now()
+	owner, hops, err := s.findOwner(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	latency := time.Since(started)
+	if output == outputJSON {
+		return writeJSON(jsonLookup{Key: args[0], KeyID: keyID(args[0]),
+			Owner: jsonNode{ID: owner.Id, Address: owner.Address}, Hops: hops, Latency: latency.Microseconds()})
+	}
+	fmt.Printf("%s (%s) is owned by %s (%s): %d hops in %v\n", args[0], shortID(keyID(args[0])),
+		shortID(owner.Id), owner.Address, hops, latency.Truncate(time.Microsecond))
+	return nil
+}
+
+// get prints the value of args[0] as its owner stores it
+func (s *shell) get(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: get KEY")
+	}
+	owner, _, err := s.findOwner(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	client, err := s.client(owner.Address)
+	if err != nil {
+		return err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.Get(rpcCtx, &pb.GetRequest{Key: args[0]})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	if output == outputJSON {
+		doc := jsonValue{Key: args[0], Owner: jsonNode{ID: owner.Id, Address: owner.Address}, Found: resp.Found}
+		if resp.Found {
+			doc.Value, doc.Stale = resp.Value, resp.Stale
+		}
+		return writeJSON(doc)
+	}
+	if !resp.Found {
+		fmt.Printf("%s: not found on %s\n", args[0], owner.Address)
+		return nil
+	}
+	stale := ""
+	if resp.Stale {
+		stale = " (stale, the owner is isolated)"
+	}
+	fmt.Printf("%s%s\n", resp.Value, stale)
+	return nil
+}
+
+// put writes the rest of the line after the key to its owner
+func (s *shell) put(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: put KEY VALUE")
+	}
+	key, value := args[0], strings.Join(args[1:], " ")
+	owner, _, err := s.findOwner(ctx, key)
+	if err != nil {
+		return err
+	}
+	client, err := s.client(owner.Address)
+	if err != nil {
+		return err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.Put(rpcCtx, &pb.PutRequest{Key: key, Value: []byte(value)})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	if output == outputJSON {
+		return writeJSON(jsonPut{Key: key, Owner: jsonNode{ID: owner.Id, Address: owner.Address}, Buffered: resp.Buffered})
+	}
+	if resp.Buffered {
+		fmt.Printf("Buffered by %s until it rejoins the ring\n", owner.Address)
+		return nil
+	}
+	fmt.Printf("Stored on %s (%s)\n", shortID(owner.Id), owner.Address)
+	return nil
+}
+
+// ring prints every node met following successor pointers from the node,
+// and the nodes met before the walk failed if it did
+func (s *shell) ring(ctx context.Context, args []string) error {
+	nodes, walkErr := s.walk(ctx)
+	if output == outputJSON {
+		if walkErr != nil {
+			return walkErr
+		}
+		return writeJSON(jsonRing{Nodes: nodes})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tADDRESS\tPREDECESSOR\tPRESSURE")
+	for i, node := range nodes {
+		predecessor := "-"
+		if node.Predecessor != "" {
+			predecessor = shortID(node.Predecessor)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, shortID(node.ID), node.Address, predecessor, node.Pressure)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return walkErr
+}
+
+// walk follows successor pointers from the node until it is back at a node
+// it met
+func (s *shell) walk(ctx context.Context) ([]jsonRingNode, error) {
+	nodes := []jsonRingNode{}
+	visited := make(map[string]bool)
+	address := s.addr
+	for !visited[address] {
+		if len(visited) >= crawl.DefaultMaxNodes {
+			return nodes, fmt.Errorf("walk did not close after %d nodes", len(visited))
+		}
+		visited[address] = true
+		s.addresses[address] = true
+
+		client, err := s.client(address)
+		if err != nil {
+			return nodes, err
+		}
+		rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := client.GetInfo(rpcCtx, &pb.GetInfoRequest{})
+		cancel()
+		if err != nil {
+			return nodes, fmt.Errorf("get info from %s failed: %w", address, err)
+		}
+		nodes = append(nodes, jsonRingNode{
+			ID:          resp.Node.GetId(),
+			Address:     address,
+			Predecessor: resp.Predecessor.GetId(),
+			Pressure:    chord.PressureLevel(resp.Pressure).String(),
+		})
+		if resp.Successor == nil {
+			return nodes, fmt.Errorf("node %s has no successor", address)
+		}
+		address = resp.Successor.Address
+	}
+	return nodes, nil
+}
+
+// fingers prints the routing table of the node, with runs of fingers
+// pointing at the same node on one line
+func (s *shell) fingers(ctx context.Context, args []string) error {
+	client, err := s.client(s.addr)
+	if err != nil {
+		return err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.GetRoutingTable(rpcCtx, &pb.GetRoutingTableRequest{})
+	if err != nil {
+		return err
+	}
+	table, err := chord.RoutingTableFromProto(resp)
+	if err != nil {
+		return fmt.Errorf("invalid routing table: %w", err)
+	}
+
+	s.addresses[table.Self.Address] = true
+	if table.Predecessor != nil {
+		s.addresses[table.Predecessor.Address] = true
+	}
+	for _, successor := range table.Successors {
+		s.addresses[successor.Address] = true
+	}
+	for _, finger := range table.Fingers {
+		if finger.Node != nil {
+			s.addresses[finger.Node.Address] = true
+		}
+	}
+	if output == outputJSON {
+		return writeJSON(toJSONRoutingTable(table))
+	}
+
+	describe := func(node *chord.NodeInfo) string {
+		if node == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s (%s)", node.ID.Short(), node.Address)
+	}
+	fmt.Printf("Node:        %s\n", describe(table.Self))
+	fmt.Printf("Predecessor: %s\n", describe(table.Predecessor))
+	for i, successor := range table.Successors {
+		fmt.Printf("Successor %d: %s\n", i, describe(successor))
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FINGERS\tSTART\tNODE")
+	for i := 0; i < len(table.Fingers); {
+		j := i
+		for j+1 < len(table.Fingers) && sameNode(table.Fingers[j+1].Node, table.Fingers[i].Node) {
+			j++
+		}
+		fmt.Fprintf(w, "%d-%d\t%s\t%s\n", table.Fingers[i].Index, table.Fingers[j].Index,
+			table.Fingers[i].Start.Short(), describe(table.Fingers[i].Node))
+		i = j + 1
+	}
+	return w.Flush()
+}
+
+// sameNode reports whether a and b are the same node, or both unset
+func sameNode(a, b *chord.NodeInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Address == b.Address
+}
+
+// watchEvent is an event of the admin server's /events stream
+type watchEvent struct {
+	chord.RingEvent
+	// Dropped is set on the events telling how many were lost
+	Dropped int64 `json:"dropped"`
+}
+
+// watch prints the events streamed by the node's admin server until the
+// stream ends or Ctrl-C
+func (s *shell) watch(ctx context.Context, args []string) error {
+	if s.adminAddr == "" {
+		return fmt.Errorf("no admin server, see connect ADDR ADMIN_ADDR or --admin-addr")
+	}
+	events := url.URL{Scheme: "http", Host: s.adminAddr, Path: "/events"}
+	if len(args) > 0 {
+		events.RawQuery = url.Values{"types": {strings.Join(args, ",")}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, events.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	fmt.Printf("Watching %s, Ctrl-C stops\n", s.adminAddr)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if output == outputJSON {
+			fmt.Println(data)
+			continue
+		}
+		var event watchEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("invalid event: %w", err)
+		}
+		fmt.Println(describeEvent(event))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// describeEvent formats an event on one line
+func describeEvent(event watchEvent) string {
+	at := event.Time.Local().Format("15:04:05.000")
+	switch {
+	case event.Type == "dropped":
+		return fmt.Sprintf("%s events dropped, %d in total", time.Now().Format("15:04:05.000"), event.Dropped)
+	case event.Lookup != nil:
+		l := event.Lookup
+		line := fmt.Sprintf("%s lookup   %s in %d hops, %v", at, shortID(l.Key), l.Hops,
+			time.Duration(l.Latency)*time.Microsecond)
+		if l.Error != "" {
+			line += ": " + l.Error
+		}
+		return line
+	case event.Change != nil:
+		c := event.Change
+		detail := c.Role
+		if c.Direction != "" {
+			detail = c.Direction
+		}
+		line := fmt.Sprintf("%s %-8s %s", at, c.Kind, detail)
+		if c.Peer != nil {
+			line += fmt.Sprintf(" %s (%s)", shortID(c.Peer.ID), c.Peer.Address)
+		}
+		if c.Previous != nil {
+			line += fmt.Sprintf(" replacing %s (%s)", shortID(c.Previous.ID), c.Previous.Address)
+		}
+		if c.Kind == chord.AuditTransfer {
+			line += fmt.Sprintf(" (%s, %s], %d keys", shortID(c.Start), shortID(c.End), c.Keys)
+		}
+		return line
+	}
+	return at + " " + event.Type
+}
+
+// shortID abbreviates a hex ID as the nodes' logs do, leaving a shorter
+// one whole
+func shortID(id string) string {
+	if len(id) > hash.ShortLen {
+		return id[:hash.ShortLen]
+	}
+	return id
+}
+
+// otherCommands returns the names of the chordctl commands the shell runs
+func otherCommands() []string {
+	var names []string
+	for _, name := range sortedNames(commands) {
+		if name != "shell" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// sortedNames returns the keys of m in order
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
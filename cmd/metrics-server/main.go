@@ -0,0 +1,39 @@
+// Command chord-metrics-server aggregates the snapshots chord-node pushes
+// with --metrics-server into live ring-wide stats, served as JSON at /ring
+// and to Prometheus at /metrics.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"chord-dht/internal/metrics"
+)
+
+func main() {
+	var (
+		addr   = flag.String("addr", ":9100", "Address of the HTTP server nodes push to and /ring and /metrics are served on")
+		ttl    = flag.Duration("ttl", metrics.DefaultSnapshotTTL, "Forget nodes that have not pushed a snapshot for this long")
+		report = flag.Duration("report", 0, "Log the ring-wide stats at this interval (0 disables)")
+	)
+	flag.Parse()
+
+	aggregator := metrics.NewAggregator(*ttl)
+	if *report > 0 {
+		go func() {
+			for range time.Tick(*report) {
+				ring := aggregator.Ring()
+				log.Printf("Ring: %d nodes, %d messages, %d lookups (avg %.2fms, %.2f hops), %d keys, maintenance overhead %.1f%%",
+					ring.Nodes, ring.Messages, ring.Lookups, ring.AvgLookupMs, ring.AvgHops, ring.StoredKeys,
+					100*ring.MaintenanceOverhead)
+			}
+		}()
+	}
+
+	log.Printf("Aggregating node snapshots on http://%s (/push, /ring, /metrics)", *addr)
+	if err := http.ListenAndServe(*addr, aggregator); err != nil {
+		log.Fatalf("Metrics server stopped: %v", err)
+	}
+}
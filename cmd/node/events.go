@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"chord-dht/internal/chord"
+
+	"golang.org/x/net/websocket"
+)
+
+// eventKeepAlive is how often an idle event stream is written to, so that
+// proxies do not time it out
+const eventKeepAlive = 15 * time.Second
+
+// jsonDropped tells an event stream subscriber how many events it lost by
+// falling behind, in total
+type jsonDropped struct {
+	Type    string `json:"type"`
+	Dropped int64  `json:"dropped"`
+}
+
+// eventsHandler serves /events: the node's ring events as JSON, as
+// WebSocket messages when the request asks for an upgrade and as
+// server-sent events otherwise. ?types= takes a comma-separated list of the
+// event types to stream, all by default.
+func eventsHandler(node *chord.Node) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		types, err := eventTypes(r.URL.Query().Get("types"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			// Any origin may subscribe, as with the other admin endpoints
+			websocket.Server{Handler: func(ws *websocket.Conn) {
+				streamEventsWebSocket(node, ws, types)
+			}}.ServeHTTP(w, r)
+			return
+		}
+		streamEventsSSE(node, w, r, types)
+	}
+}
+
+// eventTypes parses the ?types= filter, nil for all types
+func eventTypes(s string) (map[string]bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	types := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		switch t = strings.TrimSpace(t); t {
+		case chord.EventTopology, chord.EventLookup, chord.EventTransfer:
+			types[t] = true
+		default:
+			return nil, fmt.Errorf("invalid types: unknown event type %q", t)
+		}
+	}
+	return types, nil
+}
+
+// streamEventsSSE writes events as server-sent events until the client
+// goes away
+func streamEventsSSE(node *chord.Node, w http.ResponseWriter, r *http.Request, types map[string]bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	sub := node.SubscribeEvents(0)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	var dropped int64
+	send := func(kind string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", kind, data)
+		return err
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event := <-sub.Events():
+			if n := sub.Dropped(); n > dropped {
+				dropped = n
+				if err := send("dropped", jsonDropped{Type: "dropped", Dropped: n}); err != nil {
+					return
+				}
+			}
+			if types != nil && !types[event.Type] {
+				continue
+			}
+			if err := send(event.Type, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// streamEventsWebSocket sends events as JSON messages until the client
+// closes the connection
+func streamEventsWebSocket(node *chord.Node, ws *websocket.Conn, types map[string]bool) {
+	sub := node.SubscribeEvents(0)
+	defer sub.Close()
+
+	// Clients send nothing; reading only notices when they close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	// Writes other than events are keep-alive pings
+	ws.PayloadType = websocket.PingFrame
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	var dropped int64
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepAlive.C:
+			if _, err := ws.Write(nil); err != nil {
+				return
+			}
+		case event := <-sub.Events():
+			if n := sub.Dropped(); n > dropped {
+				dropped = n
+				if err := websocket.JSON.Send(ws, jsonDropped{Type: "dropped", Dropped: n}); err != nil {
+					return
+				}
+			}
+			if types != nil && !types[event.Type] {
+				continue
+			}
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		}
+	}
+}
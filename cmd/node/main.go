@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/atrest"
+	"chord-dht/internal/chord/memcache"
+	"chord-dht/internal/chord/middleware"
+	"chord-dht/internal/chord/objects"
 	"chord-dht/internal/metrics"
 	"chord-dht/pkg/hash"
 )
@@ -17,14 +28,99 @@ import (
 func main() {
 	// Define command line flags
 	var (
-		addr      = flag.String("addr", "localhost:5000", "Node address (IP:port)")
+		addr      = flag.String("addr", "localhost:5000", "Node address (host:port, with IPv6 literals in brackets as in [::1]:5000, or a Unix domain socket as in unix:///tmp/chord-0.sock)")
 		publicAddr = flag.String("public", "", "Public address for advertising to other nodes (defaults to addr)")
 		bootstrap = flag.String("bootstrap", "", "Bootstrap node address (empty for first node)")
 		nodeID    = flag.String("id", "", "Node ID (hex string, auto-generated if empty)")
+		perturbID = flag.Bool("perturb-id", false, "If another node of the ring has this node's ID, move to a free ID a little past it before joining")
 		metricsDir = flag.String("metrics", "results", "Directory to save metrics CSV files")
+		experiment = flag.String("experiment-id", "", "Experiment ID the metrics files are named after (generated if empty)")
+		authToken = flag.String("auth-token", "", "Shared token required on every RPC between nodes (disabled if empty)")
+		accessSecret = flag.String("access-secret-file", "", "File with the secret client access tokens are signed with; key-value RPCs then need a token scoped to their buckets (disabled if empty)")
+		encryptionKeys = flag.String("encryption-key-file", "", "Key file to encrypt stored values and snapshots with, one ID, base64 AES-256 key and optional bucket per line (disabled if empty)")
+		auditLogPath = flag.String("audit-log", "", "Append joins, leaves, failures and key range transfers seen by the node to this file as JSON lines, served at /audit on the admin server (disabled if empty)")
+		logRPCs   = flag.Bool("log-rpcs", false, "Log every incoming and outgoing RPC")
+		replication = flag.Int("replication", 1, "Number of nodes holding each key (owner plus successors)")
+		reReplicationDelay = flag.Duration("rereplication-delay", chord.DefaultReReplicationDelay, "Wait this long after a node joins the replica set, such as in place of a failed replica, before copying the owned keys to it (0 disables)")
+		hedgePercentile = flag.Float64("hedge-percentile", 0, "Hedge reads to a replica after this percentile of read latency (0 disables)")
+		hedgeMaxDelay = flag.Duration("hedge-max-delay", 100*time.Millisecond, "Upper bound on the hedge delay")
+		lookupHedgePercentile = flag.Float64("lookup-hedge-percentile", 0, "Hedge lookup hops to the next best finger after this percentile of hop latency (0 disables)")
+		lookupHedgeMaxDelay = flag.Duration("lookup-hedge-max-delay", 100*time.Millisecond, "Upper bound on the lookup hedge delay")
+		maintenanceTransport = flag.String("maintenance-transport", "grpc", "Transport of stabilize, notify and ping RPCs to peers: grpc or udp (on the port of --addr)")
+		compression = flag.String("compression", "none", "Compression of RPCs to peers: none, gzip or zstd (hand-offs, replication and batches, plus requests of --compression-min-size)")
+		compressionMinSize = flag.Int("compression-min-size", 0, "Smallest request in bytes compressed for other RPCs (0 compresses only the bulk ones)")
+		relay = flag.String("relay", "", "Public node to register with and be reached through if it cannot call this node back, such as behind NAT")
+		serveRelay = flag.Bool("serve-relay", false, "Relay calls to nodes behind NAT that register with this node")
+		holePunch = flag.Bool("hole-punch", false, "Connect directly to nodes behind NAT by hole punching through their relay (needs --transport quic)")
+		network = flag.String("transport", "tcp", "Network of connections to peers: tcp or quic (on the UDP port of --addr; every node of the ring must use it)")
+		addressPreference = flag.String("prefer", "system", "Address family dialed first when a peer's host name has both: system, ipv4 or ipv6")
+		readPolicy = flag.String("read-policy", "primary-first", "Replica to read from: primary-first, primary-only, round-robin, closest-rtt or local-zone")
+		zone = flag.String("zone", "", "Datacenter or availability zone label advertised to peers, preferred by --read-policy local-zone")
+		weight = flag.Uint("weight", 1, "Capacity of this node relative to other nodes, advertised to peers")
+		joinRate = flag.Float64("join-rate", 0, "Joins per second admitted when acting as bootstrap (0 disables the limit)")
+		joinBurst = flag.Int("join-burst", 1, "Joins admitted back to back before --join-rate applies")
+		joinQueue = flag.Int("join-queue", 8, "Joins held waiting for admission before telling nodes to retry later")
+		rateLimit = flag.Float64("rate-limit", 0, "Incoming RPCs per second admitted overall, not counting ring maintenance (0 disables)")
+		rateBurst = flag.Int("rate-burst", 100, "Incoming RPCs admitted back to back before --rate-limit applies")
+		peerRateLimit = flag.Float64("peer-rate-limit", 0, "Incoming RPCs per second admitted from each peer host (0 disables)")
+		peerRateBurst = flag.Int("peer-rate-burst", 50, "RPCs a peer host may send back to back before --peer-rate-limit applies")
+		maxLookups = flag.Int("max-lookups", 0, "Lookups forwarded at once on behalf of peers and clients (0 disables the limit)")
+		lookupQueue = flag.Int("lookup-queue", 64, "Lookups held waiting for --max-lookups before telling callers to retry later")
+		maxCPU = flag.Float64("max-cpu", 0, "Fraction of CPUs at which the node is under critical pressure (0 disables)")
+		maxMemoryMB = flag.Uint64("max-memory-mb", 0, "Runtime memory in MB at which the node is under critical pressure (0 disables)")
+		maxConns = flag.Int("max-connections", 0, "Open connections at which the node is under critical pressure (0 disables)")
+		adaptiveStabilize = flag.Bool("adaptive-stabilize", false, "Speed stabilization and fix-fingers up after neighbor changes and slow them down while the ring is quiet")
+		stabilizeMin = flag.Duration("stabilize-min", chord.StabilizeInterval/5, "Stabilization period right after a neighbor change, with --adaptive-stabilize")
+		stabilizeMax = flag.Duration("stabilize-max", 4*chord.StabilizeInterval, "Stabilization period on a quiet ring, with --adaptive-stabilize")
+		livenessTTL = flag.Duration("liveness-ttl", chord.DefaultLivenessTTL, "How long a peer found up or down stays so before routing and maintenance ping it again (0 pings every time)")
+		blacklistStrikes = flag.Int("blacklist-strikes", 0, "Leave a peer out of routing after this many failed RPCs or malformed responses in a row (0 disables blacklisting)")
+		blacklistBan = flag.Duration("blacklist-ban", chord.DefaultBan, "How long a peer is first blacklisted for; each ban that follows doubles it")
+		blacklistMaxBan = flag.Duration("blacklist-max-ban", chord.DefaultMaxBan, "Longest a peer is blacklisted for at a time")
+		gossipOn = flag.Bool("gossip", false, "Gossip node metadata and health on stabilization notifications, building an approximate view of the ring served at /members")
+		gossipEntries = flag.Int("gossip-entries", chord.DefaultGossipEntries, "Entries of the membership view sent with each notification, with --gossip")
+		gossipTTL = flag.Duration("gossip-ttl", chord.DefaultGossipTTL, "How long a node not heard from stays in the membership view, with --gossip")
+		fingerCheck = flag.Duration("finger-check-interval", 0, "Check every finger against a lookup of its start at this interval and report the fraction correct (0 disables)")
+		hotKeyThreshold = flag.Float64("hot-key-threshold", 0, "Reads per second at which an owned key is copied to the node's predecessors, which serve reads of it (0 disables)")
+		hotKeyCopies = flag.Int("hot-key-copies", 1, "Predecessors holding copies of each hot key, with --hot-key-threshold")
+		invalidate = flag.Bool("invalidate-caches", false, "Broadcast an invalidation when a key advertised as cached by another node is overwritten or deleted")
+		transferRate = flag.Float64("transfer-rate", 0, "Bytes per second the node sends when streaming key ranges to joining nodes (0 for no limit)")
+		transferChunk = flag.Int("transfer-chunk-size", chord.DefaultTransferChunkBytes, "Largest chunk in bytes of a streamed key range")
+		trashRetention = flag.Duration("trash-retention", 0, "Keep deleted values restorable with chordctl undelete for this long (0 disables)")
+		storageMaxKeys = flag.Int64("storage-max-keys", 0, "Entries the node stores at most, replicas included (0 for no limit)")
+		storageMaxBytes = flag.Int64("storage-max-bytes", 0, "Bytes of keys and values the node stores at most, replicas included (0 for no limit)")
+		eviction = flag.String("eviction", "reject", "What a write over --storage-max-keys or --storage-max-bytes does: reject, expired (evict expired entries first) or lru (evict expired, then least recently used entries)")
+		tombstoneHorizon = flag.Duration("tombstone-horizon", chord.DefaultTombstoneHorizon, "Keep the tombstones of deleted keys, which stop replicas from bringing them back, for this long (0 keeps them forever)")
+		ringSnapshotDir = flag.String("ring-snapshot-dir", "", "Directory to write the node's pieces of ring snapshots to (see chordctl backup), the --metrics directory if empty")
+		isolationBuffer = flag.Int("isolation-buffer", 0, "Keys whose writes are held while the node is cut off from the ring, stored once it rejoins (0 rejects writes)")
+		adminAddr = flag.String("admin-addr", "", "Address of the admin HTTP server with /healthz, /readyz, /history, /audit, /stats, /storage, /hotkeys, /members, /events and /metrics (disabled if empty)")
+		prometheusAddr = flag.String("prometheus-addr", "", "Deprecated alias of --admin-addr")
+		gatewayAddr = flag.String("gateway-addr", "", "Address of the S3-style object gateway HTTP server, storing objects under /buckets/{bucket}/{object} in chunks across the ring (disabled if empty)")
+		memcacheAddr = flag.String("memcache-addr", "", "Address to serve the memcached text protocol on, mapping get, set, add, delete and flush_all to the ring (disabled if empty)")
+		metricsServer = flag.String("metrics-server", "", "URL of a chord-metrics-server to push snapshots of the node to, such as http://10.0.0.1:9100 (disabled if empty)")
+		pushInterval = flag.Duration("metrics-push-interval", 10*time.Second, "Period of the snapshots pushed to --metrics-server")
+		stateFile = flag.String("state-file", "", "Save the successor list and fingers here on shutdown and rejoin through them on restart (disabled if empty)")
+		keyFile = flag.String("key", "", "Ed25519 key file; the node ID is derived from its public key and RPCs are signed")
+		genKey = flag.String("gen-key", "", "Generate an Ed25519 key file at this path, print its node ID and exit")
+		restore = flag.String("restore", "", "Snapshot file (see chordctl snapshot) to take the node ID, keys and routing state from")
+		saveSnapshot = flag.String("save-snapshot", "", "Write a snapshot of the node to this file on shutdown, for a later run to --restore (disabled if empty)")
+		ring = flag.String("ring", "", "Logical ring the node belongs to, named on every RPC to peers so that nodes of other rings refuse it (empty for the default ring)")
+		staticMembers = flag.String("static-members", "", "Join a static ring of the nodes listed in this file, one address or hex ID and address per line, with routing state computed up front and never stabilized, for routing benchmarks (disabled if empty)")
 	)
+	var extraRings ringFlags
+	flag.Var(&extraRings, "extra-ring", "Also take part in this ring on the same address, with its own routing state and storage, as name or name=bootstrap (repeatable)")
+	var bucketQuotas bucketQuotaFlags
+	flag.Var(&bucketQuotas, "bucket-quota", "Limit the keys and value bytes a bucket stores on this node, as bucket=KEYS:BYTES with either left empty for no limit (repeatable)")
 	flag.Parse()
 
+	if *genKey != "" {
+		key, err := middleware.GenerateKey(*genKey)
+		if err != nil {
+			log.Fatalf("Failed to generate key: %v", err)
+		}
+		fmt.Printf("Wrote %s, node ID %s\n", *genKey, middleware.KeyID(key.Public().(ed25519.PublicKey)))
+		return
+	}
+
 	// Validate address
 	if *addr == "" {
 		log.Fatal("Node address (--addr) is required")
@@ -35,25 +131,202 @@ func main() {
 	if *publicAddr != "" {
 		advertiseAddr = *publicAddr
 	}
+	listenAddr, err := chord.NormalizeAddress(*addr)
+	if err != nil {
+		log.Fatalf("Invalid --addr: %v", err)
+	}
+	if advertiseAddr, err = chord.NormalizeAddress(advertiseAddr); err != nil {
+		log.Fatalf("Invalid --public: %v", err)
+	}
 
 	// Generate experiment ID
-	experimentID := fmt.Sprintf("exp_%d", time.Now().Unix())
+	experimentID := *experiment
+	if experimentID == "" {
+		experimentID = fmt.Sprintf("exp_%d", time.Now().Unix())
+	}
 
 	// Parse or generate node ID
 	var id *hash.Hash
-	var err error
-	if *nodeID != "" {
+	var key ed25519.PrivateKey
+	if *keyFile != "" {
+		if *nodeID != "" {
+			log.Fatal("--id cannot be used with --key, which derives the ID")
+		}
+		if *perturbID {
+			log.Fatal("--perturb-id cannot be used with --key, which derives the ID")
+		}
+		key, err = middleware.LoadKey(*keyFile)
+		if err != nil {
+			log.Fatalf("Failed to load key: %v", err)
+		}
+		id = middleware.KeyID(key.Public().(ed25519.PublicKey))
+	} else if *nodeID != "" {
 		id, err = hash.ParseNodeID(*nodeID)
 		if err != nil {
 			log.Fatalf("Invalid node ID: %v", err)
 		}
-	} else {
+	} else if *restore == "" {
 		// Auto-generate ID from advertise address for consistency
 		id = hash.GenerateID(advertiseAddr)
 	}
+	if *restore != "" {
+		id = snapshotID(*restore, id)
+	}
+	
+	// A static ring takes its routing state from the member list alone
+	var members []*chord.NodeInfo
+	if *staticMembers != "" {
+		if *bootstrap != "" || *restore != "" || *stateFile != "" || *perturbID || len(extraRings) > 0 {
+			log.Fatal("--static-members cannot be used with --bootstrap, --restore, --state-file, --perturb-id or --extra-ring")
+		}
+		if members, err = chord.LoadMembers(*staticMembers); err != nil {
+			log.Fatalf("Invalid --static-members: %v", err)
+		}
+		// The member list may give this node its ID
+		for _, member := range members {
+			if member.Address == advertiseAddr && *nodeID == "" && *keyFile == "" {
+				id = member.ID
+			}
+		}
+	}
 
-	log.Printf("Starting Chord node: ID=%s, Listen=%s, Advertise=%s", id.String()[:16], *addr, advertiseAddr)
+	log.Printf("Starting Chord node: ID=%s, Listen=%s, Advertise=%s", id.Short(), listenAddr, advertiseAddr)
 
+	selector, err := chord.ParseReplicaSelector(*readPolicy)
+	if err != nil {
+		log.Fatalf("Invalid --read-policy: %v", err)
+	}
+	transport, err := chord.ParseTransport(*maintenanceTransport)
+	if err != nil {
+		log.Fatalf("Invalid --maintenance-transport: %v", err)
+	}
+	peerNetwork, err := chord.ParseNetwork(*network)
+	if err != nil {
+		log.Fatalf("Invalid --transport: %v", err)
+	}
+	algorithm, err := chord.ParseCompression(*compression)
+	if err != nil {
+		log.Fatalf("Invalid --compression: %v", err)
+	}
+	evictionPolicy, err := chord.ParseEviction(*eviction)
+	if err != nil {
+		log.Fatalf("Invalid --eviction: %v", err)
+	}
+	preference, err := chord.ParseAddressPreference(*addressPreference)
+	if err != nil {
+		log.Fatalf("Invalid --prefer: %v", err)
+	}
+	
+	var access *chord.Middleware
+	if *accessSecret != "" {
+		secret, err := middleware.LoadAccessSecret(*accessSecret)
+		if err != nil {
+			log.Fatalf("Failed to load access secret: %v", err)
+		}
+		m, err := middleware.Access(secret)
+		if err != nil {
+			log.Fatalf("Invalid access secret: %v", err)
+		}
+		access = &m
+	}
+	
+	var atRestKeys *atrest.Keys
+	if *encryptionKeys != "" {
+		if atRestKeys, err = atrest.LoadKeys(*encryptionKeys); err != nil {
+			log.Fatalf("Failed to load encryption keys: %v", err)
+		}
+	}
+	
+	var auditLog *chord.AuditLog
+	if *auditLogPath != "" {
+		if auditLog, err = chord.OpenAuditLog(*auditLogPath); err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		// Deferred before the node stops, so it closes after
+		defer auditLog.Close()
+	}
+	
+	// configure applies the options to the node of every ring
+	configure := func(node *chord.Node) {
+		if *logRPCs {
+			node.Use(middleware.Logging(nil))
+		}
+		if *authToken != "" {
+			node.Use(middleware.Auth(*authToken))
+		}
+		if access != nil {
+			node.Use(*access)
+		}
+		if key != nil {
+			node.Use(middleware.Identity(key))
+		}
+		node.SetNodeMetadata(chord.NodeMetadata{Zone: *zone, Weight: uint32(*weight)})
+		node.SetReplication(*replication)
+		node.SetReReplication(chord.ReReplicationPolicy{Delay: *reReplicationDelay})
+		node.SetHedging(chord.HedgePolicy{Percentile: *hedgePercentile, MaxDelay: *hedgeMaxDelay})
+		node.SetLookupHedging(chord.HedgePolicy{Percentile: *lookupHedgePercentile, MaxDelay: *lookupHedgeMaxDelay})
+		node.SetReplicaSelector(selector)
+		node.SetJoinLimit(chord.JoinLimit{Rate: *joinRate, Burst: *joinBurst, MaxQueue: *joinQueue})
+		node.SetRateLimits(chord.RateLimits{PeerRate: *peerRateLimit, PeerBurst: *peerRateBurst, Rate: *rateLimit, Burst: *rateBurst})
+		node.SetLookupLimit(chord.LookupLimit{MaxInFlight: *maxLookups, MaxQueue: *lookupQueue})
+		node.SetPressureLimits(chord.PressureLimits{CPU: *maxCPU, Memory: *maxMemoryMB << 20, Connections: *maxConns})
+		node.SetIsolationPolicy(chord.IsolationPolicy{BufferWrites: *isolationBuffer})
+		node.SetStabilization(chord.StabilizationPolicy{Adaptive: *adaptiveStabilize, MinInterval: *stabilizeMin, MaxInterval: *stabilizeMax})
+		node.SetLivenessTTL(*livenessTTL)
+		node.SetReputation(chord.ReputationPolicy{Strikes: *blacklistStrikes, Ban: *blacklistBan, MaxBan: *blacklistMaxBan})
+		node.SetGossip(chord.GossipPolicy{Enabled: *gossipOn, Entries: *gossipEntries, TTL: *gossipTTL})
+		node.SetFingerCheck(chord.FingerCheckPolicy{Interval: *fingerCheck})
+		node.SetHotKeys(chord.HotKeyPolicy{Threshold: *hotKeyThreshold, Copies: *hotKeyCopies})
+		node.SetInvalidation(chord.InvalidationPolicy{Broadcast: *invalidate})
+		node.SetAuditLog(auditLog)
+		if atRestKeys != nil {
+			store, err := atrest.Wrap(node.Storage(), atRestKeys)
+			if err != nil {
+				log.Fatalf("Invalid --encryption-key-file: %v", err)
+			}
+			node.SetStorage(store)
+		}
+		node.SetTrash(chord.TrashPolicy{Retention: *trashRetention})
+		node.SetTombstones(chord.TombstonePolicy{Horizon: *tombstoneHorizon})
+		if *ringSnapshotDir != "" {
+			node.SetRingSnapshotDir(*ringSnapshotDir)
+		} else {
+			node.SetRingSnapshotDir(*metricsDir)
+		}
+		node.SetStorageLimits(chord.StorageLimits{MaxKeys: *storageMaxKeys, MaxBytes: *storageMaxBytes, Eviction: evictionPolicy})
+		node.SetTransferPolicy(chord.TransferPolicy{ChunkBytes: *transferChunk, Rate: *transferRate})
+		for _, quota := range bucketQuotas {
+			if err := node.SetBucketQuota(quota.bucket, quota.quota); err != nil {
+				log.Fatalf("Invalid --bucket-quota: %v", err)
+			}
+		}
+		node.SetMaintenanceTransport(transport)
+		node.SetNetwork(peerNetwork)
+		node.SetCompression(chord.CompressionPolicy{Algorithm: algorithm, MinSize: *compressionMinSize})
+		node.SetAddressPreference(preference)
+		node.SetNAT(chord.NATPolicy{Relay: *relay, ServeRelay: *serveRelay, HolePunch: *holePunch})
+	}
+	
+	// Create the Chord node. With extra rings, a host serves the node of
+	// every ring on the one address.
+	var host *chord.Host
+	var node *chord.Node
+	if len(extraRings) > 0 {
+		host = chord.NewHost(listenAddr, advertiseAddr)
+		if node, err = host.AddRing(*ring, id); err != nil {
+			log.Fatalf("Invalid --ring: %v", err)
+		}
+	} else {
+		node = chord.NewNodeWithAdvertise(listenAddr, advertiseAddr, id)
+		node.SetRing(*ring)
+	}
+	configure(node)
+	
+	// A taken ID is replaced before the node starts and names its metrics
+	if *perturbID && *bootstrap != "" && *restore == "" && *stateFile == "" {
+		id = freeID(node, *bootstrap)
+	}
+	
 	// Create metrics collector
 	var nodeMetrics *metrics.Metrics
 	if *metricsDir != "" {
@@ -64,30 +337,92 @@ func main() {
 		defer nodeMetrics.Close()
 		log.Printf("Metrics will be saved to: %s", *metricsDir)
 	}
-
-	// Create and start the Chord node
-	node := chord.NewNodeWithAdvertise(*addr, advertiseAddr, id)
+	if nodeMetrics != nil {
+		node.Use(middleware.Tenants(nodeMetrics))
+		node.SetMetrics(nodeMetrics)
+		node.ObserveLookups(func(key *hash.Hash, hops int, latency time.Duration, err error) {
+			nodeMetrics.RecordArcLookup(key, latency, err)
+		})
+	}
+	extraNodes := make([]*chord.Node, len(extraRings))
+	for i, extra := range extraRings {
+		if extraNodes[i], err = host.AddRing(extra.name, id); err != nil {
+			log.Fatalf("Invalid --extra-ring: %v", err)
+		}
+		configure(extraNodes[i])
+	}
+	
+	// Serve the admin endpoints before joining, so liveness probes pass
+	// while the node waits for its bootstrap
+	if *adminAddr == "" {
+		*adminAddr = *prometheusAddr
+	}
+	if *adminAddr != "" {
+		mux := adminMux(node, nodeMetrics)
+		go func() {
+			if err := http.ListenAndServe(*adminAddr, mux); err != nil {
+				log.Printf("Admin endpoint stopped: %v", err)
+			}
+		}()
+		log.Printf("Serving admin endpoints on http://%s (/healthz, /readyz, /history, /audit, /stats, /hotkeys, /members, /events, /metrics)", *adminAddr)
+	}
 	
-	if err := node.Start(); err != nil {
-		log.Fatalf("Failed to start node: %v", err)
+	if host != nil {
+		if err := host.Start(); err != nil {
+			log.Fatalf("Failed to start node: %v", err)
+		}
+		defer host.Stop()
+	} else {
+		if err := node.Start(); err != nil {
+			log.Fatalf("Failed to start node: %v", err)
+		}
+		defer node.Stop()
 	}
-	defer node.Stop()
 
 	// Join the ring
-	if *bootstrap == "" {
+	if members != nil {
+		log.Printf("Joining static ring of the %d nodes in %s", len(members), *staticMembers)
+		if err := node.JoinStatic(members); err != nil {
+			log.Fatalf("Failed to join static ring: %v", err)
+		}
+	} else if *restore != "" {
+		state := restoreSnapshot(node, *restore)
+		log.Printf("Rejoining through the peers in the snapshot (bootstrap: %q)", *bootstrap)
+		if err := node.RejoinState(state, *bootstrap); err != nil {
+			log.Fatalf("Failed to join ring: %v", err)
+		}
+	} else if *stateFile != "" {
+		log.Printf("Rejoining through the peers saved in %s (bootstrap: %q)", *stateFile, *bootstrap)
+		if err := node.Rejoin(*stateFile, *bootstrap); err != nil {
+			log.Fatalf("Failed to join ring: %v", err)
+		}
+	} else if *bootstrap == "" {
 		log.Printf("Creating new ring (bootstrap node)")
 		if err := node.Join(""); err != nil {
 			log.Fatalf("Failed to create ring: %v", err)
 		}
 	} else {
 		log.Printf("Joining existing ring via bootstrap: %s", *bootstrap)
-		if err := node.Join(*bootstrap); err != nil {
+		if err := node.Join(*bootstrap); errors.Is(err, chord.ErrIDCollision) {
+			log.Fatalf("Failed to join ring: %v; pick another --id or pass --perturb-id", err)
+		} else if err != nil {
 			log.Fatalf("Failed to join ring: %v", err)
 		}
 	}
 
 	log.Printf("Node successfully started and joined ring")
 
+	for i, extra := range extraRings {
+		if extra.bootstrap == "" {
+			log.Printf("Creating new ring %q", extra.name)
+		} else {
+			log.Printf("Joining ring %q via bootstrap: %s", extra.name, extra.bootstrap)
+		}
+		if err := extraNodes[i].Join(extra.bootstrap); err != nil {
+			log.Fatalf("Failed to join ring %q: %v", extra.name, err)
+		}
+	}
+
 	// Start metrics collection goroutine
 	if nodeMetrics != nil {
 		go func() {
@@ -97,25 +432,81 @@ func main() {
 			for {
 				select {
 				case <-ticker.C:
-				// Get current stats from node
-				_, lookups := node.GetStats()
+				// Metrics are optional work, skipped under pressure
+				if node.Pressure().Level >= chord.PressureElevated {
+					continue
+				}
 				
-				// Update metrics (node count would need to be determined via discovery)
-				nodeMetrics.UpdateNodeCount(1) // At least this node
-				nodeMetrics.RecordMessage()    // Called for each message					// Record lookups with dummy latency for now
-					if lookups > 0 {
-						nodeMetrics.RecordLookup(time.Millisecond * 50) // Placeholder
-					}
+				// Update metrics, with the ring size estimated from the
+				// node's neighborhood
+				nodeMetrics.UpdateNodeCount(node.EstimateRingSize().Nodes)
+				nodeMetrics.UpdateTenantUsage(tenantUsage(node.Storage()))
+				hedges := node.LookupHedgeStats()
+				nodeMetrics.UpdateLookupHedging(metrics.HedgeStats{
+					Hops:   hedges.Reads,
+					Hedged: hedges.Hedged,
+					Wins:   hedges.HedgeWins,
+					Delay:  hedges.Delay,
+				})
+				stabilization := node.StabilizationStatus()
+				nodeMetrics.UpdateMaintenance(metrics.MaintenanceStats{
+					StabilizeInterval:  stabilization.Interval,
+					FixFingersInterval: stabilization.FixFingersInterval,
+					StabilizeRounds:    stabilization.Rounds,
+					FixFingersRounds:   stabilization.FixFingersRounds,
+				})
+				stats := node.Stats()
+				nodeMetrics.UpdateNodeStats(metrics.NodeStats{
+					RPCs:              stats.RPCs,
+					BytesSent:         stats.BytesSent,
+					BytesReceived:     stats.BytesReceived,
+					ActiveConnections: stats.ActiveConnections,
+					StoredKeys:        stats.StoredKeys,
+					Uptime:            stats.Uptime,
+					LastStabilization: stats.LastStabilization,
+				})
 				}
 			}
 		}()
 	}
 
+	if *gatewayAddr != "" {
+		gateway := objects.New(node).NewServer(*gatewayAddr)
+		defer gateway.Close()
+		go func() {
+			if err := gateway.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Object gateway stopped: %v", err)
+			}
+		}()
+		log.Printf("Serving the object gateway on http://%s/buckets/", *gatewayAddr)
+	}
+
+	if *memcacheAddr != "" {
+		frontend := memcache.New(node)
+		defer frontend.Close()
+		go func() {
+			if err := frontend.ListenAndServe(*memcacheAddr); err != nil && err != memcache.ErrServerClosed {
+				log.Printf("Memcached frontend stopped: %v", err)
+			}
+		}()
+		log.Printf("Serving the memcached protocol on %s", *memcacheAddr)
+	}
+
+	if *metricsServer != "" {
+		go pushSnapshots(node, nodeMetrics, *metricsServer, *pushInterval)
+		log.Printf("Pushing snapshots to %s every %v", *metricsServer, *pushInterval)
+	}
+
 	// Print node information
 	log.Printf("Node is running:")
 	log.Printf("  ID: %s", id.String())
 	log.Printf("  Address: %s", *addr)
 	log.Printf("  Bootstrap: %s", *bootstrap)
+	if host != nil {
+		log.Printf("  Rings: %q", host.Rings())
+	} else if *ring != "" {
+		log.Printf("  Ring: %s", *ring)
+	}
 	if nodeMetrics != nil {
 		log.Printf("  Metrics: enabled")
 	}
@@ -129,6 +520,22 @@ func main() {
 	// Wait for shutdown signal
 	<-sigCh
 	log.Printf("Received shutdown signal, stopping...")
+	
+	if *stateFile != "" {
+		if err := node.SaveRoutingState(*stateFile); err != nil {
+			log.Printf("Failed to save routing state: %v", err)
+		} else {
+			log.Printf("Saved routing state to %s", *stateFile)
+		}
+	}
+
+	if *saveSnapshot != "" {
+		if err := node.SaveSnapshot(*saveSnapshot); err != nil {
+			log.Printf("Failed to save snapshot: %v", err)
+		} else {
+			log.Printf("Saved snapshot to %s", *saveSnapshot)
+		}
+	}
 
 	// Graceful shutdown
 	if nodeMetrics != nil {
@@ -144,4 +551,515 @@ func main() {
 	}
 
 	log.Printf("Node stopped gracefully")
-}
\ No newline at end of file
+}
+
+// ringFlag is an --extra-ring: a ring and the node to join it through,
+// empty to create it
+type ringFlag struct {
+	name      string
+	bootstrap string
+}
+
+// ringFlags collects the values of --extra-ring
+type ringFlags []ringFlag
+
+// String implements flag.Value
+func (r *ringFlags) String() string {
+	parts := make([]string, len(*r))
+	for i, ring := range *r {
+		parts[i] = ring.name
+		if ring.bootstrap != "" {
+			parts[i] += "=" + ring.bootstrap
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value, parsing name or name=bootstrap
+func (r *ringFlags) Set(value string) error {
+	name, bootstrap, _ := strings.Cut(value, "=")
+	if name == "" {
+		return fmt.Errorf("expected name or name=bootstrap, got %q", value)
+	}
+	if bootstrap != "" {
+		var err error
+		if bootstrap, err = chord.NormalizeAddress(bootstrap); err != nil {
+			return err
+		}
+	}
+	*r = append(*r, ringFlag{name: name, bootstrap: bootstrap})
+	return nil
+}
+
+// bucketQuotaFlag is a --bucket-quota: a bucket and its quota
+type bucketQuotaFlag struct {
+	bucket string
+	quota  chord.BucketQuota
+}
+
+// bucketQuotaFlags collects the values of --bucket-quota
+type bucketQuotaFlags []bucketQuotaFlag
+
+// String implements flag.Value
+func (b *bucketQuotaFlags) String() string {
+	parts := make([]string, len(*b))
+	for i, q := range *b {
+		parts[i] = fmt.Sprintf("%s=%d:%d", q.bucket, q.quota.MaxKeys, q.quota.MaxBytes)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value, parsing bucket=KEYS:BYTES
+func (b *bucketQuotaFlags) Set(value string) error {
+	bucket, limits, ok := strings.Cut(value, "=")
+	keys, bytes, _ := strings.Cut(limits, ":")
+	if !ok || bucket == "" {
+		return fmt.Errorf("expected bucket=KEYS:BYTES, got %q", value)
+	}
+	var quota chord.BucketQuota
+	for _, limit := range []struct {
+		value string
+		into  *int64
+	}{{keys, &quota.MaxKeys}, {bytes, &quota.MaxBytes}} {
+		if limit.value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(limit.value, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("expected a positive limit in %q, got %q", value, limit.value)
+		}
+		*limit.into = n
+	}
+	*b = append(*b, bucketQuotaFlag{bucket: bucket, quota: quota})
+	return nil
+}
+
+// snapshotID returns the node ID of the snapshot at path. It exits if id is
+// set and differs, as the snapshot could not be restored.
+func snapshotID(path string, id *hash.Hash) *hash.Hash {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer file.Close()
+
+	info, err := chord.ReadSnapshot(file)
+	if err != nil {
+		log.Fatalf("Invalid snapshot %s: %v", path, err)
+	}
+	snapshotID, err := hash.NewHashFromHex(info.State.ID)
+	if err != nil {
+		log.Fatalf("Invalid snapshot %s: %v", path, err)
+	}
+	if id != nil && !id.Equal(snapshotID) {
+		log.Fatalf("Snapshot %s is of node %s, not %s", path, snapshotID, id)
+	}
+	return snapshotID
+}
+
+// restoreSnapshot loads the keys of the snapshot at path into the node and
+// returns its routing state
+func restoreSnapshot(node *chord.Node, path string) *chord.RoutingState {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer file.Close()
+
+	state, err := node.RestoreSnapshot(file)
+	if err != nil {
+		log.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	log.Printf("Restored snapshot %s of %s taken %v ago", path, state.Address,
+		time.Since(state.SavedAt).Truncate(time.Second))
+	return state
+}
+
+// maxIDPerturbations bounds the IDs --perturb-id tries
+const maxIDPerturbations = 8
+
+// freeID moves the node, before it starts, to a perturbed ID for as long as
+// the bootstrap finds its ID taken, and returns the ID it ends up with
+func freeID(node *chord.Node, bootstrap string) *hash.Hash {
+	for i := 0; i < maxIDPerturbations; i++ {
+		err := node.CheckID(bootstrap)
+		if !errors.Is(err, chord.ErrIDCollision) {
+			// Other failures are reported by the join
+			return node.GetID()
+		}
+		id := chord.PerturbID(node.GetID())
+		log.Printf("%v; perturbing the node ID to %s", err, id)
+		if err := node.SetID(id); err != nil {
+			log.Fatalf("Failed to change the node ID: %v", err)
+		}
+	}
+	log.Fatalf("No free node ID found within %d perturbations", maxIDPerturbations)
+	return nil
+}
+
+// tenantUsage sums the live keys and value bytes stored per tenant
+func tenantUsage(storage chord.Storage) map[string]metrics.TenantUsage {
+	usage := make(map[string]metrics.TenantUsage)
+	now := time.Now()
+	err := storage.Range(func(key string, e chord.Entry) bool {
+		if e.Live(now) {
+			u := usage[metrics.TenantOf(key)]
+			u.Keys++
+			u.Bytes += int64(len(e.Value))
+			usage[metrics.TenantOf(key)] = u
+		}
+		return true
+	})
+	if err != nil {
+		log.Printf("Failed to measure tenant storage: %v", err)
+	}
+	return usage
+}
+
+// pushSnapshots pushes a snapshot of the node to the metrics server at url
+// every interval, skipping rounds under pressure like the metrics collection
+func pushSnapshots(node *chord.Node, nodeMetrics *metrics.Metrics, url string, interval time.Duration) {
+	client := &http.Client{Timeout: interval}
+	failing := false
+	for range time.Tick(interval) {
+		if node.Pressure().Level >= chord.PressureElevated {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := metrics.PushSnapshot(ctx, client, url, snapshot(node, nodeMetrics))
+		cancel()
+		// Log when pushes start and stop failing, not every failure
+		if err != nil && !failing {
+			log.Printf("Failed to push snapshot: %v", err)
+		} else if err == nil && failing {
+			log.Printf("Pushing snapshots to %s again", url)
+		}
+		failing = err != nil
+	}
+}
+
+// snapshot returns the node's state for the metrics server
+func snapshot(node *chord.Node, nodeMetrics *metrics.Metrics) metrics.Snapshot {
+	stats := node.Stats()
+	s := metrics.Snapshot{
+		NodeID:            node.GetID().String(),
+		Address:           node.GetAddress(),
+		Time:              time.Now(),
+		Messages:          stats.Messages,
+		Lookups:           stats.Lookups,
+		StoredKeys:        stats.StoredKeys,
+		ActiveConnections: stats.ActiveConnections,
+		UptimeSeconds:     stats.Uptime.Seconds(),
+		Bandwidth:         node.Bandwidth(),
+	}
+	if accuracy := node.FingerAccuracy(); accuracy.Checked > 0 {
+		fraction := accuracy.Fraction()
+		s.FingerAccuracy = &fraction
+	}
+	if nodeMetrics != nil {
+		_, _, _, s.AvgLookupMs = nodeMetrics.GetCurrentStats()
+		s.AvgHops = nodeMetrics.AverageHops()
+	}
+	return s
+}
+
+// adminMux returns the admin HTTP handlers: /healthz answers while the
+// process runs, /readyz once the node has joined the ring and stabilized,
+// /history the node's membership events after ?since=SEQ as JSON,
+// /audit the audit log from ?since=TIME (RFC 3339) on as JSON lines,
+// /stats the node's counters as JSON, /storage what the node stores against
+// its storage limits as JSON, /hotkeys the ?n= (default 10) most read
+// keys the node owns as JSON, /members the gossiped membership view as
+// JSON and /events the node's ring events as they happen (see events.go).
+// With metrics enabled, /metrics serves Prometheus metrics and /heatmap the
+// lookup latency by keyspace arc as JSON.
+func adminMux(node *chord.Node, nodeMetrics *metrics.Metrics) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := node.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		var since uint64
+		if s := r.URL.Query().Get("since"); s != "" {
+			var err error
+			if since, err = strconv.ParseUint(s, 10, 64); err != nil {
+				http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		events, first := node.MembershipHistory(since)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(historyJSON(events, first))
+	})
+	mux.HandleFunc("/audit", func(w http.ResponseWriter, r *http.Request) {
+		var since time.Time
+		if s := r.URL.Query().Get("since"); s != "" {
+			var err error
+			if since, err = time.Parse(time.RFC3339, s); err != nil {
+				http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		events, err := node.AuditEvents(since)
+		if errors.Is(err, chord.ErrNoAuditLog) {
+			http.Error(w, "audit log disabled, see --audit-log", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, event := range events {
+			enc.Encode(event)
+		}
+	})
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statsJSON(node.Stats()))
+	})
+	mux.HandleFunc("/storage", func(w http.ResponseWriter, r *http.Request) {
+		usage, err := node.StorageUsage()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(storageJSON(usage))
+	})
+	mux.HandleFunc("/hotkeys", func(w http.ResponseWriter, r *http.Request) {
+		limit := chord.DefaultHotKeys
+		if s := r.URL.Query().Get("n"); s != "" {
+			var err error
+			if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+				http.Error(w, "invalid n: must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hotKeysJSON(node.HotKeys(limit)))
+	})
+	mux.HandleFunc("/members", func(w http.ResponseWriter, r *http.Request) {
+		members := node.GossipMembers()
+		if members == nil {
+			http.Error(w, "gossip disabled, see --gossip", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(membersJSON(members, node.EstimateRingSize()))
+	})
+	mux.Handle("/events", eventsHandler(node))
+	if nodeMetrics != nil {
+		mux.Handle("/metrics", nodeMetrics)
+		mux.HandleFunc("/heatmap", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(heatmapJSON(nodeMetrics.ArcHeatmap()))
+		})
+	}
+	return mux
+}
+
+// jsonMember is a node in the /history output
+type jsonMember struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// jsonMembershipEvent is a membership event in the /history output
+type jsonMembershipEvent struct {
+	Seq      uint64      `json:"seq"`
+	Time     time.Time   `json:"time"`
+	Kind     string      `json:"kind"`
+	Role     string      `json:"role"`
+	Node     jsonMember  `json:"node"`
+	Previous *jsonMember `json:"previous,omitempty"`
+}
+
+// jsonHistory is the /history output document
+type jsonHistory struct {
+	// FirstSeq is the oldest event still kept; older ones were dropped
+	FirstSeq uint64                `json:"first_seq"`
+	Events   []jsonMembershipEvent `json:"events"`
+}
+
+// historyJSON converts membership events for the admin endpoint
+func historyJSON(events []chord.MembershipEvent, first uint64) jsonHistory {
+	doc := jsonHistory{FirstSeq: first, Events: []jsonMembershipEvent{}}
+	for _, event := range events {
+		e := jsonMembershipEvent{
+			Seq:  event.Seq,
+			Time: event.Time.UTC(),
+			Kind: string(event.Kind),
+			Role: event.Role,
+			Node: jsonMember{ID: event.Node.ID.String(), Address: event.Node.Address},
+		}
+		if event.Previous != nil {
+			e.Previous = &jsonMember{ID: event.Previous.ID.String(), Address: event.Previous.Address}
+		}
+		doc.Events = append(doc.Events, e)
+	}
+	return doc
+}
+
+// jsonStats is the /stats output document
+type jsonStats struct {
+	Messages          int64            `json:"messages"`
+	Lookups           int64            `json:"lookups"`
+	RPCs              map[string]int64 `json:"rpcs"`
+	BytesSent         int64            `json:"bytes_sent"`
+	BytesReceived     int64            `json:"bytes_received"`
+	ActiveConnections int              `json:"active_connections"`
+	StoredKeys        int              `json:"stored_keys"`
+	UptimeSeconds     float64          `json:"uptime_seconds"`
+	LastStabilization *time.Time       `json:"last_stabilization,omitempty"`
+}
+
+// statsJSON converts a node's counters for the admin endpoint
+func statsJSON(stats chord.Stats) jsonStats {
+	doc := jsonStats{
+		Messages:          stats.Messages,
+		Lookups:           stats.Lookups,
+		RPCs:              stats.RPCs,
+		BytesSent:         stats.BytesSent,
+		BytesReceived:     stats.BytesReceived,
+		ActiveConnections: stats.ActiveConnections,
+		StoredKeys:        stats.StoredKeys,
+		UptimeSeconds:     stats.Uptime.Seconds(),
+	}
+	if !stats.LastStabilization.IsZero() {
+		last := stats.LastStabilization.UTC()
+		doc.LastStabilization = &last
+	}
+	return doc
+}
+
+// jsonStorage is the /storage output document. Zero limits are unlimited.
+type jsonStorage struct {
+	Keys      int64  `json:"keys"`
+	Bytes     int64  `json:"bytes"`
+	MaxKeys   int64  `json:"max_keys"`
+	MaxBytes  int64  `json:"max_bytes"`
+	Eviction  string `json:"eviction"`
+	Evictions int64  `json:"evictions"`
+}
+
+// storageJSON converts a node's storage usage for the admin endpoint
+func storageJSON(usage chord.StorageUsage) jsonStorage {
+	return jsonStorage{
+		Keys:      usage.Keys,
+		Bytes:     usage.Bytes,
+		MaxKeys:   usage.Limits.MaxKeys,
+		MaxBytes:  usage.Limits.MaxBytes,
+		Eviction:  string(usage.Limits.Eviction),
+		Evictions: usage.Evictions,
+	}
+}
+
+// jsonGossipMember is a node in the /members output
+type jsonGossipMember struct {
+	ID         string    `json:"id"`
+	Address    string    `json:"address"`
+	Zone       string    `json:"zone,omitempty"`
+	Weight     uint32    `json:"weight,omitempty"`
+	Version    uint32    `json:"version,omitempty"`
+	Pressure   string    `json:"pressure"`
+	StoredKeys int64     `json:"stored_keys"`
+	MaxKeys    int64     `json:"max_keys,omitempty"`
+	Updated    time.Time `json:"updated"`
+}
+
+// jsonMembers is the /members output document
+type jsonMembers struct {
+	// Estimated is the ring size estimated from the node's neighborhood,
+	// next to the members gossip found
+	Estimated int                `json:"estimated_size"`
+	Members   []jsonGossipMember `json:"members"`
+}
+
+// membersJSON converts a node's gossiped membership view for the admin
+// endpoint
+func membersJSON(members []chord.GossipMember, estimate chord.RingSizeEstimate) jsonMembers {
+	doc := jsonMembers{Estimated: estimate.Nodes, Members: make([]jsonGossipMember, 0, len(members))}
+	for _, member := range members {
+		doc.Members = append(doc.Members, jsonGossipMember{
+			ID:         member.Node.ID.String(),
+			Address:    member.Node.Address,
+			Zone:       member.Node.Zone,
+			Weight:     member.Node.Weight,
+			Version:    member.Node.Version,
+			Pressure:   member.Pressure.String(),
+			StoredKeys: member.StoredKeys,
+			MaxKeys:    member.MaxKeys,
+			Updated:    member.Updated.UTC(),
+		})
+	}
+	return doc
+}
+
+// jsonHotKey is a key's read rate in the /hotkeys output
+type jsonHotKey struct {
+	Key    string  `json:"key"`
+	Rate   float64 `json:"rate"`
+	Copies int     `json:"copies"`
+}
+
+// jsonHotKeys is the /hotkeys output document
+type jsonHotKeys struct {
+	Keys []jsonHotKey `json:"keys"`
+}
+
+// hotKeysJSON converts a node's hottest keys for the admin endpoint
+func hotKeysJSON(keys []chord.HotKey) jsonHotKeys {
+	doc := jsonHotKeys{Keys: make([]jsonHotKey, 0, len(keys))}
+	for _, key := range keys {
+		doc.Keys = append(doc.Keys, jsonHotKey{Key: key.Key, Rate: key.Rate, Copies: key.Copies})
+	}
+	return doc
+}
+
+// jsonArc is the lookup latency of one keyspace arc in the /heatmap output
+type jsonArc struct {
+	Arc          string  `json:"arc"`
+	Lookups      int64   `json:"lookups"`
+	Errors       int64   `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	Buckets      []int64 `json:"buckets"`
+}
+
+// jsonHeatmap is the /heatmap output document
+type jsonHeatmap struct {
+	// BucketsMs are the upper bounds of the latency buckets; every arc has
+	// one more bucket for slower lookups
+	BucketsMs []float64 `json:"buckets_ms"`
+	Arcs      []jsonArc `json:"arcs"`
+}
+
+// heatmapJSON converts the lookup latency heatmap for the admin endpoint
+func heatmapJSON(heatmap metrics.ArcHeatmap) jsonHeatmap {
+	doc := jsonHeatmap{}
+	for _, bound := range metrics.LatencyBuckets {
+		doc.BucketsMs = append(doc.BucketsMs, float64(bound)/float64(time.Millisecond))
+	}
+	for arc, a := range heatmap {
+		buckets := a.Counts
+		if buckets == nil {
+			buckets = make([]int64, len(metrics.LatencyBuckets)+1)
+		}
+		doc.Arcs = append(doc.Arcs, jsonArc{
+			Arc:          metrics.ArcLabel(arc),
+			Lookups:      a.Lookups,
+			Errors:       a.Errors,
+			AvgLatencyMs: float64(a.Average()) / float64(time.Millisecond),
+			Buckets:      buckets,
+		})
+	}
+	return doc
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stopTimeout bounds how long a node is given to shut down gracefully
+// before it is killed
+const stopTimeout = 30 * time.Second
+
+// Runner runs shell commands on a host and copies files back from it.
+// Running nodes through an agent instead of ssh only takes another Runner.
+type Runner interface {
+	// Run runs script with sh in the host's workdir and returns its output
+	Run(ctx context.Context, script string) (string, error)
+	// Fetch copies the directory remote, relative to the workdir, to local
+	Fetch(ctx context.Context, remote, local string) error
+}
+
+// runnerFor returns the runner of host
+func runnerFor(host *Host, workdir string) Runner {
+	if host.SSH == "" {
+		return &localRunner{workdir: workdir}
+	}
+	return &sshRunner{destination: host.SSH, workdir: workdir}
+}
+
+// sshRunner runs commands on a remote host through the ssh and scp
+// commands, so the user's ssh configuration and agent apply
+type sshRunner struct {
+	destination string
+	workdir     string
+}
+
+// sshOptions make ssh fail instead of prompting
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+
+// Run runs script on the host
+func (r *sshRunner) Run(ctx context.Context, script string) (string, error) {
+	args := append(append([]string(nil), sshOptions...), r.destination, inWorkdir(r.workdir, script))
+	return runCommand(ctx, "ssh", args...)
+}
+
+// Fetch copies a directory from the host
+func (r *sshRunner) Fetch(ctx context.Context, remote, local string) error {
+	source := r.destination + ":" + remotePath(r.workdir, remote)
+	args := append(append([]string(nil), sshOptions...), "-r", source, local)
+	_, err := runCommand(ctx, "scp", args...)
+	return err
+}
+
+// localRunner runs commands on this machine
+type localRunner struct {
+	workdir string
+}
+
+// Run runs script here
+func (r *localRunner) Run(ctx context.Context, script string) (string, error) {
+	return runCommand(ctx, "sh", "-c", inWorkdir(r.workdir, script))
+}
+
+// Fetch copies a directory of the workdir
+func (r *localRunner) Fetch(ctx context.Context, remote, local string) error {
+	_, err := runCommand(ctx, "cp", "-R", remotePath(r.workdir, remote), local)
+	return err
+}
+
+// runCommand runs a command and returns its combined output
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// inWorkdir prefixes script with a change to workdir, creating it
+func inWorkdir(workdir, script string) string {
+	return fmt.Sprintf("mkdir -p %s && cd %s && %s", shellQuote(workdir), shellQuote(workdir), script)
+}
+
+// remotePath returns p relative to workdir, as a path on the host
+func remotePath(workdir, p string) string {
+	if path.IsAbs(p) {
+		return p
+	}
+	return path.Join(workdir, p)
+}
+
+// shellQuote quotes s as a single sh word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// startScript returns the script that starts a node in dir in the
+// background, with its log and PID file next to its results
+func startScript(binary, dir string, args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	binary = shellQuote(binary)
+	if !strings.HasPrefix(binary, "'/") {
+		// The node runs in its own directory, below the workdir
+		binary = `"$OLDPWD"/` + binary
+	}
+	return fmt.Sprintf("mkdir -p %s && cd %s && { nohup %s %s > node.log 2>&1 < /dev/null & echo $! > node.pid; }",
+		shellQuote(dir), shellQuote(dir), binary, strings.Join(quoted, " "))
+}
+
+// stopScript returns the script that stops the node started in dir with
+// SIGTERM, so it writes its final metrics, and kills it if it has not
+// exited within stopTimeout
+func stopScript(dir string) string {
+	pidFile := shellQuote(path.Join(dir, "node.pid"))
+	return fmt.Sprintf(`pid=$(cat %s 2>/dev/null) || exit 0
+kill -TERM "$pid" 2>/dev/null || exit 0
+i=0
+while kill -0 "$pid" 2>/dev/null; do
+	i=$((i+1))
+	if [ "$i" -ge %d ]; then kill -KILL "$pid"; exit 0; fi
+	sleep 0.2
+done`, pidFile, int(stopTimeout/(200*time.Millisecond)))
+}
+
+// gather copies the directory of every node to resultsDir/experiment, one
+// subdirectory per node
+func gather(ctx context.Context, plan *Plan, resultsDir string) error {
+	local := filepath.Join(resultsDir, plan.Experiment)
+	if err := os.MkdirAll(local, 0755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	var failed []string
+	for _, node := range plan.nodes() {
+		runner := runnerFor(node.host, plan.Workdir)
+		if err := runner.Fetch(ctx, plan.nodeDir(node), filepath.Join(local, node.name)); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", node.name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to gather %d nodes:\n  %s", len(failed), strings.Join(failed, "\n  "))
+	}
+	return nil
+}
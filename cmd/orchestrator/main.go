@@ -0,0 +1,180 @@
+// Command chord-orchestrator runs an experiment across several machines:
+// it starts cmd/node processes on every host of a plan over ssh, waits for
+// them to form a ring, drives a workload of lookups and puts, stops the
+// nodes and gathers their result directories here.
+//
+// Usage:
+//
+//	chord-orchestrator --plan config/experiments/local.yaml [--results-dir results] [--dry-run]
+//
+// Every host needs the chord-node binary at the plan's binary path, and
+// key-based ssh access unless it has no ssh destination, in which case its
+// nodes run on this machine. Results land in results-dir/experiment, one
+// directory per node next to workload.csv.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// readyTimeout bounds how long the nodes are given to form a ring
+const readyTimeout = 2 * time.Minute
+
+func main() {
+	var (
+		planFile   = flag.String("plan", "", "Experiment plan (YAML)")
+		resultsDir = flag.String("results-dir", "results", "Directory the results of every node are gathered in")
+		dryRun     = flag.Bool("dry-run", false, "Print the command starting every node and exit")
+	)
+	flag.Parse()
+
+	if *planFile == "" {
+		log.Fatal("An experiment plan (--plan) is required")
+	}
+	plan, err := LoadPlan(*planFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodes := plan.nodes()
+	if *dryRun {
+		for i, node := range nodes {
+			where := "local"
+			if node.host.SSH != "" {
+				where = node.host.SSH
+			}
+			fmt.Printf("%s (%s): %s\n", node.name, where, startScript(plan.Binary, plan.nodeDir(node), nodeArgs(plan, nodes, i)))
+		}
+		return
+	}
+
+	local := filepath.Join(*resultsDir, plan.Experiment)
+	if _, err := os.Stat(local); err == nil {
+		log.Fatalf("Results of experiment %s already exist in %s", plan.Experiment, local)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Experiment %s: %d nodes on %d hosts", plan.Experiment, len(nodes), len(plan.Hosts))
+	runErr := run(ctx, plan, nodes, local)
+	if runErr != nil {
+		log.Printf("Experiment failed: %v", runErr)
+	}
+
+	// Nodes are stopped and gathered even after a failure or an interrupt,
+	// so no process is left behind and partial results are kept
+	stopNodes(plan, nodes)
+	gatherCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := gather(gatherCtx, plan, *resultsDir); err != nil {
+		log.Printf("Failed to gather results: %v", err)
+	} else {
+		log.Printf("Results gathered in %s", local)
+	}
+	if runErr != nil {
+		os.Exit(1)
+	}
+}
+
+// nodeArgs returns the flags of the i-th node. The first node creates the
+// ring and the others join through it.
+func nodeArgs(plan *Plan, nodes []nodeSpec, i int) []string {
+	node := nodes[i]
+	bootstrap := ""
+	if i > 0 {
+		bootstrap = nodes[0].address()
+	}
+	args := []string{
+		"--addr=" + node.listenAddress(),
+		"--public=" + node.address(),
+		"--bootstrap=" + bootstrap,
+		"--metrics=results",
+		"--experiment-id=" + plan.Experiment,
+	}
+	return append(args, node.host.Flags...)
+}
+
+// run starts the nodes, waits for the ring, drives the workload and writes
+// its results to local
+func run(ctx context.Context, plan *Plan, nodes []nodeSpec, local string) error {
+	for i, node := range nodes {
+		runner := runnerFor(node.host, plan.Workdir)
+		if _, err := runner.Run(ctx, startScript(plan.Binary, plan.nodeDir(node), nodeArgs(plan, nodes, i))); err != nil {
+			return fmt.Errorf("failed to start %s: %w", node.name, err)
+		}
+		log.Printf("Started %s at %s", node.name, node.address())
+
+		// Give the first node time to listen before the others join
+		// through it
+		if i == 0 {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	readyCtx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+	if err := waitForRing(readyCtx, nodes[0].address(), len(nodes)); err != nil {
+		return err
+	}
+	log.Printf("Ring formed; settling for %v", plan.Workload.Settle)
+	select {
+	case <-time.After(plan.Workload.Settle):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	addresses := make([]string, len(nodes))
+	for i, node := range nodes {
+		addresses[i] = node.address()
+	}
+	log.Printf("Running %d lookups and %d puts over %v",
+		plan.Workload.Lookups, plan.Workload.Puts, plan.Workload.Duration)
+	results := runWorkload(ctx, plan, addresses)
+	reportResults(results)
+	reportRingStats(ctx, nodes[0].address())
+
+	if err := os.MkdirAll(local, 0755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+	if err := writeResults(filepath.Join(local, "workload.csv"), results); err != nil {
+		return fmt.Errorf("failed to write workload results: %w", err)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("interrupted")
+	}
+	return nil
+}
+
+// stopNodes stops every node, last started first
+func stopNodes(plan *Plan, nodes []nodeSpec) {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout+30*time.Second)
+	defer cancel()
+
+	var failed []string
+	for i := len(nodes) - 1; i >= 0; i-- {
+		runner := runnerFor(nodes[i].host, plan.Workdir)
+		if _, err := runner.Run(ctx, stopScript(plan.nodeDir(nodes[i]))); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", nodes[i].name, err))
+		}
+	}
+	if len(failed) > 0 {
+		log.Printf("Failed to stop %d nodes:\n  %s", len(failed), strings.Join(failed, "\n  "))
+		return
+	}
+	log.Printf("Stopped %d nodes", len(nodes))
+}
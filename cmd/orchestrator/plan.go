@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plan describes a multi-host experiment loaded from a YAML file:
+//
+//	experiment: geo-9
+//	binary: bin/chord-node
+//	workdir: /tmp/chord
+//	hosts:
+//	  - name: vm1
+//	    ssh: chord@34.38.96.126
+//	    address: 34.38.96.126
+//	    nodes: 3
+//	    flags: [--zone=us-central1]
+//	  - name: vm2
+//	    ssh: chord@35.199.69.216
+//	    address: 35.199.69.216
+//	    nodes: 3
+//	workload:
+//	  settle: 20s
+//	  duration: 60s
+//	  lookups: 1000
+//	  puts: 100
+//
+// The first node of the first host creates the ring and every other node
+// joins through it.
+type Plan struct {
+	// Experiment names the run; results are gathered under it
+	Experiment string `yaml:"experiment"`
+	// Binary is the path of chord-node on every host, relative to Workdir
+	// unless absolute
+	Binary string `yaml:"binary"`
+	// Workdir is where nodes run on every host, and keep their results
+	Workdir  string   `yaml:"workdir"`
+	Hosts    []Host   `yaml:"hosts"`
+	Workload Workload `yaml:"workload"`
+}
+
+// Host is a machine running some of the experiment's nodes
+type Host struct {
+	Name string `yaml:"name"`
+	// SSH is the ssh destination of the host, such as user@host; nodes run
+	// on this machine if it is empty
+	SSH string `yaml:"ssh"`
+	// Address is the host name or IP the nodes advertise to each other and
+	// the orchestrator dials
+	Address string `yaml:"address"`
+	// Listen is the address the nodes bind to, Address if empty
+	Listen string `yaml:"listen"`
+	Nodes  int    `yaml:"nodes"`
+	// BasePort is the port of the host's first node; the others use the
+	// following ports
+	BasePort int `yaml:"base_port"`
+	// Flags are passed to every node of the host
+	Flags []string `yaml:"flags"`
+}
+
+// Workload is what the orchestrator drives once the ring has formed
+type Workload struct {
+	// Settle is how long to wait after every node answers before the
+	// workload starts, for stabilization and fix-fingers to catch up
+	Settle time.Duration `yaml:"settle"`
+	// Duration is how long the workload runs; the requests are spread over it
+	Duration time.Duration `yaml:"duration"`
+	// Lookups is the number of random lookups, each sent to a random node
+	Lookups int `yaml:"lookups"`
+	// Puts is the number of keys written to their owner and read back
+	Puts int `yaml:"puts"`
+}
+
+// nodeSpec is one node of the experiment
+type nodeSpec struct {
+	name string
+	host *Host
+	port int
+}
+
+// address returns the address the node advertises
+func (n nodeSpec) address() string {
+	return fmt.Sprintf("%s:%d", n.host.Address, n.port)
+}
+
+// listenAddress returns the address the node binds to
+func (n nodeSpec) listenAddress() string {
+	if n.host.Listen == "" {
+		return n.address()
+	}
+	return fmt.Sprintf("%s:%d", n.host.Listen, n.port)
+}
+
+// Defaults of optional plan fields
+const (
+	defaultBinary   = "bin/chord-node"
+	defaultWorkdir  = "chord-experiments"
+	defaultBasePort = 8000
+	defaultSettle   = 20 * time.Second
+	defaultDuration = 60 * time.Second
+)
+
+// LoadPlan reads and validates a plan file
+func LoadPlan(file string) (*Plan, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan Plan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", file, err)
+	}
+	plan.setDefaults()
+	if err := plan.validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", file, err)
+	}
+	return &plan, nil
+}
+
+// setDefaults fills in the optional fields
+func (p *Plan) setDefaults() {
+	if p.Experiment == "" {
+		p.Experiment = fmt.Sprintf("orch_%d", time.Now().Unix())
+	}
+	if p.Binary == "" {
+		p.Binary = defaultBinary
+	}
+	if p.Workdir == "" {
+		p.Workdir = defaultWorkdir
+	}
+	for i := range p.Hosts {
+		if p.Hosts[i].BasePort == 0 {
+			p.Hosts[i].BasePort = defaultBasePort
+		}
+	}
+	if p.Workload.Settle == 0 {
+		p.Workload.Settle = defaultSettle
+	}
+	if p.Workload.Duration == 0 {
+		p.Workload.Duration = defaultDuration
+	}
+}
+
+// validate checks that every host is named once and runs at least one node
+func (p *Plan) validate() error {
+	if len(p.Hosts) == 0 {
+		return fmt.Errorf("no hosts")
+	}
+	if path.Base(p.Experiment) != p.Experiment {
+		return fmt.Errorf("experiment %q must not contain path separators", p.Experiment)
+	}
+
+	names := make(map[string]bool)
+	for i, host := range p.Hosts {
+		switch {
+		case host.Name == "":
+			return fmt.Errorf("host %d has no name", i)
+		case names[host.Name]:
+			return fmt.Errorf("host %q is listed twice", host.Name)
+		case path.Base(host.Name) != host.Name:
+			return fmt.Errorf("host %q must not contain path separators", host.Name)
+		case host.Address == "":
+			return fmt.Errorf("host %q has no address", host.Name)
+		case host.Nodes <= 0:
+			return fmt.Errorf("host %q runs no nodes", host.Name)
+		}
+		names[host.Name] = true
+	}
+	if p.Workload.Lookups < 0 || p.Workload.Puts < 0 {
+		return fmt.Errorf("workload counts must not be negative")
+	}
+	return nil
+}
+
+// nodes returns every node of the plan, host by host
+func (p *Plan) nodes() []nodeSpec {
+	var nodes []nodeSpec
+	for i := range p.Hosts {
+		host := &p.Hosts[i]
+		for j := 0; j < host.Nodes; j++ {
+			nodes = append(nodes, nodeSpec{
+				name: fmt.Sprintf("%s-%d", host.Name, j),
+				host: host,
+				port: host.BasePort + j,
+			})
+		}
+	}
+	return nodes
+}
+
+// nodeDir returns the directory a node runs in on its host, relative to
+// the workdir
+func (p *Plan) nodeDir(node nodeSpec) string {
+	return path.Join(p.Experiment, node.name)
+}
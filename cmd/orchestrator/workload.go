@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/internal/crawl"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// requestTimeout bounds every request of the workload
+const requestTimeout = 5 * time.Second
+
+// driver sends the workload's requests to the nodes
+type driver struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// newDriver creates a driver with no connections
+func newDriver() *driver {
+	return &driver{conns: make(map[string]*grpc.ClientConn)}
+}
+
+// client returns a cached client for address
+func (d *driver) client(address string) (pb.ChordServiceClient, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if conn, ok := d.conns[address]; ok {
+		return pb.NewChordServiceClient(conn), nil
+	}
+	conn, err := grpc.NewClient(address, append(chord.RequestDialOptions(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	d.conns[address] = conn
+	return pb.NewChordServiceClient(conn), nil
+}
+
+// close closes every connection
+func (d *driver) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for address, conn := range d.conns {
+		conn.Close()
+		delete(d.conns, address)
+	}
+}
+
+// waitForRing waits until a walk of the ring from start finds count nodes.
+// Failed walks are retried, since nodes may still be starting.
+func waitForRing(ctx context.Context, start string, count int) error {
+	crawler := crawl.New()
+	defer crawler.Close()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	seen := 0
+	var lastErr error
+	for {
+		topology, err := crawler.Topology(ctx, start)
+		if err == nil {
+			if seen = len(topology.Nodes); seen >= count {
+				return nil
+			}
+		}
+		lastErr = err
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("ring formed %d of %d nodes: %w", seen, count, lastErr)
+			}
+			return fmt.Errorf("ring formed %d of %d nodes: %w", seen, count, ctx.Err())
+		}
+	}
+}
+
+// result is the outcome of one request of the workload
+type result struct {
+	at      time.Time
+	op      string
+	node    string
+	latency time.Duration
+	hops    int
+	err     error
+}
+
+// workloadRun is a running workload
+type workloadRun struct {
+	plan      *Plan
+	addresses []string
+	driver    *driver
+
+	mu      sync.Mutex
+	results []result
+}
+
+// runWorkload sends the plan's lookups and puts to random nodes, spread
+// evenly over the workload duration, and returns their outcomes
+func runWorkload(ctx context.Context, plan *Plan, addresses []string) []result {
+	run := &workloadRun{plan: plan, addresses: addresses, driver: newDriver()}
+	defer run.driver.close()
+
+	ops := make([]string, 0, plan.Workload.Lookups+plan.Workload.Puts)
+	for i := 0; i < plan.Workload.Lookups; i++ {
+		ops = append(ops, "lookup")
+	}
+	for i := 0; i < plan.Workload.Puts; i++ {
+		ops = append(ops, "put")
+	}
+	rand.Shuffle(len(ops), func(i, j int) { ops[i], ops[j] = ops[j], ops[i] })
+	if len(ops) == 0 {
+		return nil
+	}
+
+	// Requests start on schedule even if earlier ones are slow
+	interval := plan.Workload.Duration / time.Duration(len(ops))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Add(1)
+		go func(i int, op string) {
+			defer wg.Done()
+			if op == "lookup" {
+				run.lookup(ctx, i)
+			} else {
+				run.put(ctx, i)
+			}
+		}(i, op)
+		if (i+1)%100 == 0 {
+			log.Printf("Sent %d/%d requests", i+1, len(ops))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(run.results, func(i, j int) bool { return run.results[i].at.Before(run.results[j].at) })
+	return run.results
+}
+
+// record adds the outcome of a request
+func (r *workloadRun) record(res result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, res)
+}
+
+// randomNode returns the address of a random node
+func (r *workloadRun) randomNode() string {
+	return r.addresses[rand.Intn(len(r.addresses))]
+}
+
+// findOwner asks node for the successor of key
+func (r *workloadRun) findOwner(ctx context.Context, node string, key *hash.Hash) (*pb.FindSuccessorResponse, error) {
+	client, err := r.driver.client(node)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	resp, err := client.FindSuccessor(ctx, &pb.FindSuccessorRequest{Key: key.String()})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success || resp.Successor == nil {
+		return nil, fmt.Errorf("lookup failed: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// lookup resolves a random key through a random node
+func (r *workloadRun) lookup(ctx context.Context, i int) {
+	node := r.randomNode()
+	key := hash.NewHashFromString(fmt.Sprintf("%s/lookup/%d/%d", r.plan.Experiment, i, rand.Int()))
+
+	start := time.Now()
+	resp, err := r.findOwner(ctx, node, key)
+	res := result{at: start, op: "lookup", node: node, latency: time.Since(start), err: err}
+	if err == nil {
+		res.hops = int(resp.Hops)
+	}
+	r.record(res)
+}
+
+// put writes a key to its owner, found through a random node, and reads it
+// back
+func (r *workloadRun) put(ctx context.Context, i int) {
+	node := r.randomNode()
+	key := fmt.Sprintf("%s/put/%d", r.plan.Experiment, i)
+	value := []byte(fmt.Sprintf("value_%d", i))
+
+	start := time.Now()
+	res := result{at: start, op: "put", node: node}
+	res.err = func() error {
+		resp, err := r.findOwner(ctx, node, hash.NewHashFromString(key))
+		if err != nil {
+			return err
+		}
+		res.hops = int(resp.Hops)
+		owner, err := r.driver.client(resp.Successor.Address)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+		put, err := owner.Put(ctx, &pb.PutRequest{Key: key, Value: value})
+		if err != nil {
+			return err
+		}
+		if !put.Success {
+			return fmt.Errorf("put failed: %s", put.Error)
+		}
+		get, err := owner.Get(ctx, &pb.GetRequest{Key: key})
+		if err != nil {
+			return err
+		}
+		if !get.Found || !bytes.Equal(get.Value, value) {
+			return fmt.Errorf("read back %q, found %v", get.Value, get.Found)
+		}
+		return nil
+	}()
+	res.latency = time.Since(start)
+	r.record(res)
+}
+
+// writeResults writes one row per request to path
+func writeResults(path string, results []result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"timestamp", "op", "node", "latency_ms", "hops", "error"})
+	for _, res := range results {
+		errText := ""
+		if res.err != nil {
+			errText = res.err.Error()
+		}
+		writer.Write([]string{
+			res.at.UTC().Format(time.RFC3339Nano),
+			res.op,
+			res.node,
+			fmt.Sprintf("%.3f", float64(res.latency.Microseconds())/1000),
+			strconv.Itoa(res.hops),
+			errText,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// reportResults logs the success rate, hops and latency percentiles of
+// every kind of request
+func reportResults(results []result) {
+	byOp := make(map[string][]result)
+	for _, res := range results {
+		byOp[res.op] = append(byOp[res.op], res)
+	}
+
+	for _, op := range []string{"lookup", "put"} {
+		ops := byOp[op]
+		if len(ops) == 0 {
+			continue
+		}
+		var (
+			latencies []time.Duration
+			hops      int
+		)
+		for _, res := range ops {
+			if res.err == nil {
+				latencies = append(latencies, res.latency)
+				hops += res.hops
+			}
+		}
+		failed := len(ops) - len(latencies)
+		if len(latencies) == 0 {
+			log.Printf("%ss: all %d failed", op, failed)
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		percentile := func(p float64) time.Duration {
+			return latencies[int(p*float64(len(latencies)-1))].Truncate(time.Microsecond)
+		}
+		log.Printf("%ss: %d ok, %d failed, avg hops %.2f, latency p50 %v, p95 %v, p99 %v",
+			op, len(latencies), failed, float64(hops)/float64(len(latencies)),
+			percentile(0.50), percentile(0.95), percentile(0.99))
+	}
+}
+
+// reportRingStats samples the counters of every node at one stats epoch
+func reportRingStats(ctx context.Context, start string) {
+	crawler := crawl.New()
+	defer crawler.Close()
+
+	stats, err := crawler.StatsEpoch(ctx, start)
+	if err != nil {
+		log.Printf("Failed to sample ring counters: %v", err)
+		return
+	}
+	log.Printf("Ring at epoch %d: %d nodes sampled (%d missing), %d messages, %d lookups, %.2f messages per lookup",
+		stats.Epoch, len(stats.Samples), len(stats.Missing), stats.Messages, stats.Lookups, stats.MessagesPerLookup())
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/disksim"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+)
+
+const (
+	// availabilityInterval is how often the dataset is probed while a node
+	// is down
+	availabilityInterval = time.Second
+	// probeKeys bounds the keys of the dataset read by one probe
+	probeKeys = 100
+	// probeWorkers is how many keys a probe reads at once
+	probeWorkers = 16
+	// probeTimeout bounds the read of one key by a probe
+	probeTimeout = 2 * time.Second
+)
+
+// nodeEvent is a --crash-node or --recover-node event: the node numbered
+// Index crashes or recovers At after the simulation started
+type nodeEvent struct {
+	Index   int
+	At      time.Duration
+	Recover bool
+}
+
+// nodeEvents collects the values of a repeatable node@time flag
+type nodeEvents []nodeEvent
+
+// String implements flag.Value
+func (e *nodeEvents) String() string {
+	var parts []string
+	for _, event := range *e {
+		parts = append(parts, fmt.Sprintf("%d@%v", event.Index, event.At))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set implements flag.Value, parsing an event such as 2@30s
+func (e *nodeEvents) Set(value string) error {
+	index, at, ok := strings.Cut(value, "@")
+	if !ok {
+		return fmt.Errorf("expected node@time, got %q", value)
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 {
+		return fmt.Errorf("invalid node index %q", index)
+	}
+	d, err := time.ParseDuration(at)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid time %q", at)
+	}
+	*e = append(*e, nodeEvent{Index: i, At: d})
+	return nil
+}
+
+// outage is the time a node spent crashed
+type outage struct {
+	index     int
+	id        *hash.Hash
+	address   string
+	crashed   time.Duration
+	recovered time.Duration // Zero while the node is down
+	snapshot  string
+	metrics   *metrics.Metrics
+
+	// repair counts the replication and transfer bytes the live nodes sent
+	// while the node was down
+	repair int64
+	// Availability of the dataset probed while the node was down
+	probes int
+	sum    float64
+	worst  float64
+}
+
+// crashRun crashes nodes of a fixed simulation and recovers them from the
+// snapshot they persisted, measuring how much of the dataset stays readable
+// and how much data the ring moves to repair itself meanwhile. A nil run
+// does nothing.
+type crashRun struct {
+	config SimulatorConfig
+	events []nodeEvent
+	next   int
+	dir    string
+	keys   []string
+
+	outages   []*outage
+	down      map[int]*outage
+	lastProbe time.Time
+	// elapsed is the time since the simulation started at the last step
+	elapsed time.Duration
+	// repairSent is the replication and transfer bytes each live node had
+	// sent at the last reading
+	repairSent map[*chord.Node]int64
+}
+
+// crashes runs the --crash-node and --recover-node events, if any
+var crashes *crashRun
+
+// newCrashRun checks the crash and recovery events of config and prepares
+// to run them
+func newCrashRun(config SimulatorConfig, crashed, recovered nodeEvents) (*crashRun, error) {
+	var events []nodeEvent
+	events = append(events, crashed...)
+	for _, event := range recovered {
+		event.Recover = true
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	if config.PreloadKeys == 0 {
+		return nil, fmt.Errorf("crash events measure the availability of the preloaded keys, set --preload-keys")
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+
+	down := make(map[int]bool)
+	downCount := 0
+	for _, event := range events {
+		switch {
+		case event.Index >= config.NumNodes:
+			return nil, fmt.Errorf("node %d at %v: there are only %d nodes", event.Index, event.At, config.NumNodes)
+		case event.At >= config.Duration:
+			return nil, fmt.Errorf("node %d at %v: the simulation ends at %v", event.Index, event.At, config.Duration)
+		case event.Recover && !down[event.Index]:
+			return nil, fmt.Errorf("node %d at %v: recovers without having crashed", event.Index, event.At)
+		case !event.Recover && down[event.Index]:
+			return nil, fmt.Errorf("node %d at %v: crashes while down", event.Index, event.At)
+		}
+		down[event.Index] = !event.Recover
+		if event.Recover {
+			downCount--
+		} else if downCount++; downCount == config.NumNodes {
+			return nil, fmt.Errorf("at %v every node is down", event.At)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "chord-crash-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	n := min(config.PreloadKeys, probeKeys)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("dataset_key_%d", i*config.PreloadKeys/n)
+	}
+	return &crashRun{
+		config:     config,
+		events:     events,
+		dir:        dir,
+		keys:       keys,
+		down:       make(map[int]*outage),
+		repairSent: make(map[*chord.Node]int64),
+	}, nil
+}
+
+// step runs the events due elapsed after the simulation started and probes
+// the dataset while a node is down. It reports whether nodes changed.
+func (c *crashRun) step(elapsed time.Duration, nodes []*chord.Node, nodeMetrics []*metrics.Metrics, disks *[]*disksim.Storage) bool {
+	if c == nil {
+		return false
+	}
+	c.elapsed = elapsed
+	c.readRepair(nodes)
+
+	changed := false
+	for c.next < len(c.events) && c.events[c.next].At <= elapsed {
+		event := c.events[c.next]
+		c.next++
+		if event.Recover {
+			c.recover(event.Index, elapsed, nodes, nodeMetrics, disks)
+		} else {
+			c.crash(event.Index, elapsed, nodes, nodeMetrics)
+		}
+		changed = true
+	}
+	if changed {
+		updateNodeCount(nodes, nodeMetrics)
+	}
+
+	if len(c.down) > 0 && time.Since(c.lastProbe) >= availabilityInterval {
+		c.lastProbe = time.Now()
+		c.probe(nodes)
+	}
+	return changed
+}
+
+// crash persists a snapshot of node index and stops it without handing off
+// its keys
+func (c *crashRun) crash(index int, elapsed time.Duration, nodes []*chord.Node, nodeMetrics []*metrics.Metrics) {
+	node := nodes[index]
+	if node == nil {
+		log.Printf("Node %d is already gone, not crashing it", index)
+		return
+	}
+
+	o := &outage{index: index, id: node.GetID(), address: node.GetAddress(), crashed: elapsed, worst: 1}
+	o.snapshot = filepath.Join(c.dir, fmt.Sprintf("node-%03d.snapshot", index))
+	if err := node.SaveSnapshot(o.snapshot); err != nil {
+		log.Printf("Failed to persist node %d, it will recover empty: %v", index, err)
+		o.snapshot = ""
+	}
+	delete(c.repairSent, node)
+	node.Stop()
+	nodes[index] = nil
+
+	// The metrics of the node carry on when it recovers
+	if m := nodeMetrics[index]; m != nil {
+		if err := m.WriteSnapshot(); err != nil {
+			log.Printf("Error writing metrics for node %d: %v", index, err)
+		}
+		o.metrics = m
+		nodeMetrics[index] = nil
+	}
+
+	c.outages = append(c.outages, o)
+	c.down[index] = o
+	c.lastProbe = time.Time{}
+	log.Printf("Node %d crashed at %v", index, elapsed.Truncate(time.Millisecond))
+}
+
+// recover restarts node index with the ID and address it had, restores its
+// persisted snapshot and rejoins it through the peers it had
+func (c *crashRun) recover(index int, elapsed time.Duration, nodes []*chord.Node, nodeMetrics []*metrics.Metrics, disks *[]*disksim.Storage) {
+	o := c.down[index]
+	if o == nil {
+		return
+	}
+	// Close the outage window first, so the catch-up of the recovering node
+	// is not counted as repair
+	delete(c.down, index)
+	o.recovered = elapsed
+
+	bootstrap := ""
+	for _, node := range nodes {
+		if node != nil {
+			bootstrap = node.GetAddress()
+			break
+		}
+	}
+
+	node := chord.NewNode(o.address, o.id)
+	node.SetJoinLimit(joinLimit)
+	node.SetMaintenanceTransport(c.config.Transport)
+	node.SetNetwork(c.config.Network)
+	node.SetCompression(c.config.Compression)
+	node.SetFingerCheck(c.config.FingerCheck)
+	node.SetLookupHedging(c.config.LookupHedge)
+	slow.slowDown(index, node)
+
+	var state *chord.RoutingState
+	if o.snapshot != "" {
+		state = c.restore(node, o.snapshot)
+	}
+	if c.config.simulateDisk() {
+		*disks = append(*disks, wrapDisk(node, c.config))
+	}
+	if err := node.Start(); err != nil {
+		log.Printf("Failed to restart node %d: %v", index, err)
+		return
+	}
+	if err := node.RejoinState(state, bootstrap); err != nil {
+		log.Printf("Failed to rejoin node %d: %v", index, err)
+		node.Stop()
+		return
+	}
+
+	nodes[index] = node
+	nodeMetrics[index] = o.metrics
+	o.metrics = nil
+	log.Printf("Node %d recovered at %v after %v down",
+		index, elapsed.Truncate(time.Millisecond), (o.recovered - o.crashed).Truncate(time.Millisecond))
+}
+
+// restore loads the snapshot at path into node, returning its routing state
+func (c *crashRun) restore(node *chord.Node, path string) *chord.RoutingState {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open snapshot %s: %v", path, err)
+		return nil
+	}
+	defer file.Close()
+
+	state, err := node.RestoreSnapshot(file)
+	if err != nil {
+		log.Printf("Failed to restore snapshot %s: %v", path, err)
+		return nil
+	}
+	return state
+}
+
+// readRepair adds the replication and transfer bytes the live nodes sent
+// since the last reading to every outage in progress
+func (c *crashRun) readRepair(nodes []*chord.Node) {
+	var sent int64
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		bandwidth := node.Bandwidth()
+		total := bandwidth[metrics.CategoryReplication].Sent + bandwidth[metrics.CategoryTransfer].Sent
+		if last, ok := c.repairSent[node]; ok {
+			sent += total - last
+		}
+		c.repairSent[node] = total
+	}
+	for _, o := range c.down {
+		o.repair += sent
+	}
+}
+
+// probe reads a sample of the dataset through a live node and records the
+// fraction readable in every outage in progress
+func (c *crashRun) probe(nodes []*chord.Node) {
+	var node *chord.Node
+	for _, n := range nodes {
+		if n != nil {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		return
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found int
+	)
+	keys := make(chan string)
+	for i := 0; i < probeWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+				_, err := node.FetchValue(ctx, key)
+				cancel()
+				if err == nil {
+					mu.Lock()
+					found++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, key := range c.keys {
+		keys <- key
+	}
+	close(keys)
+	wg.Wait()
+
+	availability := float64(found) / float64(len(c.keys))
+	for _, o := range c.down {
+		o.probes++
+		o.sum += availability
+		o.worst = min(o.worst, availability)
+	}
+}
+
+// report logs every outage and removes the persisted snapshots. Outages of
+// nodes still down end with the simulation.
+func (c *crashRun) report() {
+	if c == nil {
+		return
+	}
+	defer os.RemoveAll(c.dir)
+
+	if skipped := len(c.events) - c.next; skipped > 0 {
+		log.Printf("Crash events: %d not run before the simulation ended", skipped)
+	}
+	for _, o := range c.outages {
+		until := o.recovered
+		if until == 0 {
+			until = c.elapsed
+			if o.metrics != nil {
+				o.metrics.Close()
+			}
+		}
+		window := fmt.Sprintf("%v to %v", o.crashed.Truncate(time.Millisecond), until.Truncate(time.Millisecond))
+		if o.recovered == 0 {
+			window += " (never recovered)"
+		}
+		if o.probes == 0 {
+			log.Printf("Outage of node %d from %s: not probed, %d repair bytes", o.index, window, o.repair)
+			continue
+		}
+		log.Printf("Outage of node %d from %s: %.1f%% of the dataset readable on average, %.1f%% at worst over %d probes, %d repair bytes",
+			o.index, window, 100*o.sum/float64(o.probes), 100*o.worst, o.probes, o.repair)
+	}
+}
@@ -1,18 +1,30 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/disksim"
+	"chord-dht/internal/crawl"
 	"chord-dht/internal/metrics"
 	"chord-dht/pkg/hash"
 )
 
+// joinLimit paces the joins every simulated node admits as a bootstrap
+var joinLimit = chord.JoinLimit{Rate: 5, Burst: 1, MaxQueue: 4}
+
+// eventInterval is how often due crash and recovery events are run
+const eventInterval = 100 * time.Millisecond
+
 type SimulatorConfig struct {
 	NumNodes      int
 	BasePort      int
@@ -20,6 +32,31 @@ type SimulatorConfig struct {
 	Duration      time.Duration
 	ResultsDir    string
 	ExperimentID  string
+	PreloadKeys   int
+	DiskRead      time.Duration
+	DiskWrite     time.Duration
+	DiskErrorRate float64
+	DOTOut        string
+	Scenario      string
+	TUI           bool
+	WarmFrom      string
+	SaveWarm      string
+	Transport     chord.Transport
+	Network       chord.Network
+	Compression   chord.CompressionPolicy
+	SocketDir     string
+	FingerCheck   chord.FingerCheckPolicy
+	LookupHedge   chord.HedgePolicy
+	// ConvergeRounds and ConvergeTimeout bound the wait for the ring to
+	// stabilize (see waitConverged)
+	ConvergeRounds  int
+	ConvergeTimeout time.Duration
+	// StaticRing computes every node's routing state from the member list
+	// instead of joining and stabilizing (see chord.Node.JoinStatic)
+	StaticRing bool
+
+	ReplaceStragglers bool
+	Stragglers        stragglerPolicy
 }
 
 func main() {
@@ -32,40 +69,183 @@ func main() {
 	flag.DurationVar(&config.Duration, "duration", 60*time.Second, "Duration to run simulation")
 	flag.StringVar(&config.ResultsDir, "results-dir", "results", "Directory to save results")
 	flag.StringVar(&config.ExperimentID, "experiment-id", "", "Experiment ID (auto-generated if empty)")
+	flag.IntVar(&config.PreloadKeys, "preload-keys", 0, "Number of keys to load into the ring with batch puts before the simulation")
+	flag.DurationVar(&config.DiskRead, "disk-read-latency", 0, "Simulated disk latency added to every storage read")
+	flag.DurationVar(&config.DiskWrite, "disk-write-latency", 0, "Simulated disk latency added to every storage write")
+	flag.Float64Var(&config.DiskErrorRate, "disk-error-rate", 0, "Fraction of storage operations that fail on the simulated disk")
+	flag.StringVar(&config.DOTOut, "dot-out", "", "Write the stabilized ring with finger edges to this Graphviz DOT file")
+	flag.StringVar(&config.Scenario, "scenario", "", "Run the timed events of this YAML scenario file instead of the fixed simulation")
+	flag.StringVar(&config.WarmFrom, "warm-from", "", "Start from the node snapshots a previous run saved to this directory with --save-warm, instead of building and loading a fresh ring")
+	flag.StringVar(&config.SaveWarm, "save-warm", "", "Save a snapshot of every node to this directory at the end of the run, for --warm-from")
+	transport := flag.String("maintenance-transport", "grpc", "Transport of stabilize, notify and ping RPCs between nodes: grpc or udp")
+	network := flag.String("transport", "tcp", "Network of connections between nodes: tcp or quic")
+	compression := flag.String("compression", "none", "Compression of RPCs between nodes: none, gzip or zstd")
+	flag.IntVar(&config.Compression.MinSize, "compression-min-size", 0, "Smallest request in bytes compressed besides hand-offs, replication and batches (0 compresses only those)")
+	flag.StringVar(&config.SocketDir, "socket-dir", "", "Run the nodes on Unix domain sockets in this directory instead of ports from --base-port")
+	flag.DurationVar(&config.FingerCheck.Interval, "finger-check-interval", 0, "Check every node's fingers against lookups of their starts at this interval and report the fraction correct (0 disables)")
+	flag.IntVar(&config.ConvergeRounds, "converge-rounds", chord.DefaultConvergedRounds, "Stabilization rounds in a row without neighbor changes on every node before the ring counts as stable")
+	flag.DurationVar(&config.ConvergeTimeout, "converge-timeout", 60*time.Second, "Longest wait for the ring to stabilize before the simulation starts anyway")
+	flag.BoolVar(&config.StaticRing, "static-ring", false, "Compute every node's successors, predecessor and fingers from the full member list instead of joining and stabilizing, so routing measurements carry no convergence noise; the ring then never repairs itself")
+	flag.BoolVar(&config.TUI, "tui", false, "Show a live table of the nodes instead of log lines (the log goes to the results directory)")
+	flag.BoolVar(&config.ReplaceStragglers, "replace-stragglers", false, "Replace nodes whose lookups degrade past the straggler thresholds with freshly joined nodes")
+	flag.Float64Var(&config.Stragglers.MinSuccess, "straggler-min-success", 0.9, "Fraction of a node's lookups that must succeed")
+	flag.DurationVar(&config.Stragglers.MaxLatency, "straggler-max-latency", 0, "Highest average lookup latency of a node (0 disables the check)")
+	flag.IntVar(&config.Stragglers.MinLookups, "straggler-min-lookups", 10, "Lookups a node must start before it is judged")
+	flag.DurationVar(&config.Stragglers.Interval, "straggler-interval", 5*time.Second, "How often stragglers are looked for (at most one is replaced each time)")
+	flag.Float64Var(&config.LookupHedge.Percentile, "lookup-hedge-percentile", 0, "Hedge lookup hops to the next best preceding node after this percentile of hop latency (0 disables)")
+	flag.DurationVar(&config.LookupHedge.MaxDelay, "lookup-hedge-max-delay", 100*time.Millisecond, "Upper bound on the lookup hedge delay")
+	slowFraction := flag.Float64("slow-fraction", 0, "Fraction of the nodes, picked at random, that serve every RPC late by --slow-delay (0 disables)")
+	slowDelay := flag.Duration("slow-delay", 20*time.Millisecond, "Delay a slow node adds to every RPC it serves")
+	var crashed, recovered nodeEvents
+	flag.Var(&crashed, "crash-node", "Crash node i at time t after the simulation starts, as i@t (repeatable); it persists a snapshot and stops without handing off its keys")
+	flag.Var(&recovered, "recover-node", "Restart crashed node i at time t from its snapshot and rejoin it, as i@t (repeatable)")
 	flag.Parse()
 
+	var err error
+	if config.Transport, err = chord.ParseTransport(*transport); err != nil {
+		log.Fatalf("Invalid --maintenance-transport: %v", err)
+	}
+	if config.Network, err = chord.ParseNetwork(*network); err != nil {
+		log.Fatalf("Invalid --transport: %v", err)
+	}
+	if config.Compression.Algorithm, err = chord.ParseCompression(*compression); err != nil {
+		log.Fatalf("Invalid --compression: %v", err)
+	}
+	if config.SocketDir != "" {
+		// Socket addresses hold absolute paths
+		if config.SocketDir, err = filepath.Abs(config.SocketDir); err != nil {
+			log.Fatalf("Invalid --socket-dir: %v", err)
+		}
+		if err := os.MkdirAll(config.SocketDir, 0755); err != nil {
+			log.Fatalf("Failed to create socket directory: %v", err)
+		}
+	}
+
 	// Generate experiment ID if not provided
 	if config.ExperimentID == "" {
 		config.ExperimentID = fmt.Sprintf("sim_%d", time.Now().Unix())
 	}
 
+	var dash *dashboard
+	if config.TUI {
+		var err error
+		if dash, err = startDashboard(config); err != nil {
+			log.Fatalf("Failed to start dashboard: %v", err)
+		}
+	}
+
+	if config.ReplaceStragglers {
+		stragglers = newStragglerTracker(config.Stragglers)
+	}
+
+	if config.Scenario != "" {
+		if len(crashed) > 0 || len(recovered) > 0 {
+			log.Fatalf("--crash-node and --recover-node do not apply to --scenario; use kill events")
+		}
+		if *slowFraction > 0 {
+			log.Fatalf("--slow-fraction does not apply to --scenario")
+		}
+		runScenario(config, config.Scenario, dash)
+		return
+	}
+
+	if config.StaticRing && (config.WarmFrom != "" || len(crashed) > 0 || len(recovered) > 0 || config.ReplaceStragglers) {
+		log.Fatalf("--static-ring cannot be used with --warm-from, --crash-node, --recover-node or --replace-stragglers")
+	}
+
+	var warm []*warmNode
+	if config.WarmFrom != "" {
+		var err error
+		if warm, err = loadWarmRing(config.WarmFrom); err != nil {
+			log.Fatalf("Failed to load the saved ring: %v", err)
+		}
+		// The saved ring decides the nodes and their addresses
+		config.NumNodes = len(warm)
+	}
+	if crashes, err = newCrashRun(config, crashed, recovered); err != nil {
+		log.Fatalf("Invalid crash events: %v", err)
+	}
+	if slow, err = newSlowNodes(config, *slowFraction, *slowDelay); err != nil {
+		log.Fatalf("Invalid slow nodes: %v", err)
+	}
+
 	log.Printf("Starting Chord DHT Simulator")
 	log.Printf("Configuration:")
 	log.Printf("  Nodes: %d", config.NumNodes)
 	log.Printf("  Base Port: %d", config.BasePort)
+	if config.SocketDir != "" {
+		log.Printf("  Socket Dir: %s", config.SocketDir)
+	}
 	log.Printf("  Lookups: %d", config.LookupCount)
 	log.Printf("  Duration: %v", config.Duration)
 	log.Printf("  Results Dir: %s", config.ResultsDir)
 	log.Printf("  Experiment ID: %s", config.ExperimentID)
+	log.Printf("  Preload Keys: %d", config.PreloadKeys)
+	if config.WarmFrom != "" {
+		log.Printf("  Warm Start: %d nodes saved in %s", len(warm), config.WarmFrom)
+	}
+	log.Printf("  Disk Latency: read %v, write %v, error rate %.3f",
+		config.DiskRead, config.DiskWrite, config.DiskErrorRate)
+	if config.StaticRing {
+		log.Printf("  Static Ring: routing state computed from the member list")
+	}
+	if config.ReplaceStragglers {
+		log.Printf("  Straggler Replacement: below %.0f%% success or above %v latency over %d lookups, checked every %v",
+			config.Stragglers.MinSuccess*100, config.Stragglers.MaxLatency, config.Stragglers.MinLookups, config.Stragglers.Interval)
+	}
+	if crashes != nil {
+		log.Printf("  Crashes: %s, recoveries: %s", crashed.String(), recovered.String())
+	}
+	if slow != nil {
+		log.Printf("  Slow Nodes: %s, +%v per RPC", slow, slow.delay)
+	}
+	if config.LookupHedge.Percentile > 0 {
+		log.Printf("  Lookup Hedging: after p%.0f of hop latency, at most %v",
+			config.LookupHedge.Percentile*100, config.LookupHedge.MaxDelay)
+	}
 
 	// Create nodes
 	nodes := make([]*chord.Node, config.NumNodes)
 	addresses := make([]string, config.NumNodes)
+	var disks []*disksim.Storage
 	
 	// Initialize nodes
 	for i := 0; i < config.NumNodes; i++ {
 		port := config.BasePort + i
-		addr := fmt.Sprintf("localhost:%d", port)
+		addr := config.nodeAddress(port)
 		addresses[i] = addr
 		
 		// Generate unique node ID
 		nodeID := hash.GenerateID(addr)
+		if warm != nil {
+			addr, nodeID = warm[i].address, warm[i].id
+			addresses[i] = addr
+		}
 		nodes[i] = chord.NewNode(addr, nodeID)
+		nodes[i].SetJoinLimit(joinLimit)
+		nodes[i].SetMaintenanceTransport(config.Transport)
+		nodes[i].SetNetwork(config.Network)
+		nodes[i].SetCompression(config.Compression)
+		nodes[i].SetFingerCheck(config.FingerCheck)
+		nodes[i].SetLookupHedging(config.LookupHedge)
+		slow.slowDown(i, nodes[i])
+		if warm != nil {
+			// Restore before the simulated disk so it does not count
+			if err := warm[i].restore(nodes[i]); err != nil {
+				log.Fatalf("Failed to restore node %d from %s: %v", i, warm[i].path, err)
+			}
+		}
+		if config.simulateDisk() {
+			disks = append(disks, wrapDisk(nodes[i], config))
+		}
 		
 		log.Printf("Created node %d: ID=%s, Address=%s", 
-			i, nodeID.String()[:16], addr)
+			i, nodeID.Short(), addr)
 	}
 
+	dash.SetNodes(nodes, nil)
+	dash.SetPhase("Building ring", 0)
+
 	// Start all nodes
 	log.Printf("Starting all nodes...")
 	var wg sync.WaitGroup
@@ -82,35 +262,18 @@ func main() {
 	wg.Wait()
 	log.Printf("All nodes started")
 
-	// Create the ring - first node creates it, others join
-	log.Printf("Building Chord ring...")
-	
-	// First node creates the ring
-	if err := nodes[0].Join(""); err != nil {
-		log.Fatalf("Failed to create ring: %v", err)
+	if config.StaticRing {
+		buildStaticRing(nodes)
+	} else {
+		buildRing(config, nodes, addresses, warm, dash)
 	}
-	log.Printf("Ring created by node 0")
 
-	// Other nodes join the ring via the first node (bootstrap)
-	bootstrapAddr := addresses[0]
-	for i := 1; i < config.NumNodes; i++ {
-		if err := nodes[i].Join(bootstrapAddr); err != nil {
-			log.Printf("Failed to join node %d to ring: %v", i, err)
-			continue
-		}
-		log.Printf("Node %d joined ring", i)
-		
-		// Add small delay between joins to avoid overwhelming the bootstrap
-		time.Sleep(200 * time.Millisecond)
+	// Load the dataset, if requested
+	if config.PreloadKeys > 0 {
+		preloadDataset(nodes, config.PreloadKeys)
+		reportDensity(addresses[0])
 	}
 
-	// Wait for stabilization
-	log.Printf("Waiting for ring stabilization...")
-	time.Sleep(10 * time.Second)
-
-	// Initialize global metrics
-	globalMetrics := metrics.NewGlobalMetrics(config.ResultsDir, config.ExperimentID)
-
 	// Start metrics collection for all nodes
 	nodeMetrics := make([]*metrics.Metrics, config.NumNodes)
 	for i, node := range nodes {
@@ -135,6 +298,8 @@ func main() {
 
 	// Start the simulation
 	log.Printf("Starting simulation for %v...", config.Duration)
+	dash.SetNodes(nodes, nodeMetrics)
+	dash.SetPhase("Running", config.Duration)
 	
 	simulationDone := make(chan struct{})
 	
@@ -149,8 +314,12 @@ func main() {
 		
 		ticker := time.NewTicker(lookupInterval)
 		defer ticker.Stop()
+		// Crash events run on their own, finer schedule
+		events := time.NewTicker(eventInterval)
+		defer events.Stop()
 		
 		lookupCount := 0
+		replacements := 0
 		startTime := time.Now()
 		
 		for {
@@ -164,6 +333,17 @@ func main() {
 				performRandomLookup(nodes, nodeMetrics, lookupCount)
 				lookupCount++
 				
+				// Replacements join on ports after the initial nodes
+				if stragglers.due() && replaceStraggler(config, nodes, nodeMetrics, &disks, config.BasePort+config.NumNodes+replacements) {
+					replacements++
+					dash.SetNodes(nodes, nodeMetrics)
+				}
+				
+			case <-events.C:
+				if crashes.step(time.Since(startTime), nodes, nodeMetrics, &disks) {
+					dash.SetNodes(nodes, nodeMetrics)
+				}
+				
 			case <-time.After(config.Duration):
 				return
 			}
@@ -174,20 +354,125 @@ func main() {
 	<-simulationDone
 	log.Printf("Simulation completed")
 
+	// Check that a broadcast reaches every node of the stabilized ring
+	verifyBroadcast(nodes)
+
+	// Export the stabilized topology, if requested
+	if config.DOTOut != "" {
+		for _, node := range nodes {
+			if node != nil {
+				writeTopology(node.GetAddress(), config.DOTOut)
+				break
+			}
+		}
+	}
+
+	if config.SaveWarm != "" {
+		saveWarmRing(config.SaveWarm, nodes)
+	}
+
+	dash.Stop()
+	collectResults(config, nodes, nodeMetrics, disks)
+	stopNodes(nodes)
+
+	log.Printf("Simulation finished successfully")
+}
+
+// buildRing creates the ring on the first node, joins the others through
+// it and waits for the ring to stabilize
+func buildRing(config SimulatorConfig, nodes []*chord.Node, addresses []string, warm []*warmNode, dash *dashboard) {
+	// Create the ring - first node creates it, others join
+	log.Printf("Building Chord ring...")
+	
+	// First node creates the ring
+	if err := nodes[0].Join(""); err != nil {
+		log.Fatalf("Failed to create ring: %v", err)
+	}
+	log.Printf("Ring created by node 0")
+
+	// Other nodes join the ring via the first node (bootstrap), which
+	// paces them with its join limit
+	bootstrapAddr := addresses[0]
+	for i := 1; i < config.NumNodes; i++ {
+		if err := nodes[i].Join(bootstrapAddr); err != nil {
+			log.Printf("Failed to join node %d to ring: %v", i, err)
+			continue
+		}
+		log.Printf("Node %d joined ring", i)
+	}
+
+	// Wait for stabilization. A warm ring only needs its successors and
+	// predecessors settled: the fingers fixFingers would take many rounds
+	// to build are seeded from the saved ring.
+	if warm != nil {
+		warmFingers(nodes, warm)
+	}
+	log.Printf("Waiting for ring stabilization...")
+	dash.SetPhase("Stabilizing", 0)
+	waitConverged(config, nodes)
+}
+
+// buildStaticRing gives every node the routing state of the ring of all of
+// them, with no joins and no stabilization
+func buildStaticRing(nodes []*chord.Node) {
+	log.Printf("Building static Chord ring...")
+	members := make([]*chord.NodeInfo, len(nodes))
+	for i, node := range nodes {
+		members[i] = node.GetNodeInfo()
+	}
+	for i, node := range nodes {
+		if err := node.JoinStatic(members); err != nil {
+			log.Fatalf("Failed to add node %d to the static ring: %v", i, err)
+		}
+	}
+	log.Printf("Static ring of %d nodes built", len(nodes))
+}
+
+// collectResults writes the final metrics of every live node and logs the
+// simulation summary
+func collectResults(config SimulatorConfig, nodes []*chord.Node, nodeMetrics []*metrics.Metrics, disks []*disksim.Storage) {
+	globalMetrics := metrics.NewGlobalMetrics(config.ResultsDir, config.ExperimentID)
+
 	// Collect final metrics
 	log.Printf("Collecting final metrics...")
 	totalMessages := int64(0)
 	totalLookups := int64(0)
+	liveNodes := 0
+	var heatmap metrics.ArcHeatmap
+	
+	// Sample every node's counters at one epoch so that the totals are not
+	// skewed by nodes still serving lookups while others are read
+	var epoch uint64
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		var err error
+		if epoch, _, err = node.StartStatsEpoch(context.Background()); err != nil {
+			log.Printf("Failed to start stats epoch, reading counters one by one: %v", err)
+		}
+		break
+	}
 	
 	for i, node := range nodes {
+		if node != nil {
+			liveNodes++
+		}
 		if node == nil || nodeMetrics[i] == nil {
 			continue
 		}
 		
 		// Get final stats
 		messages, lookups := node.GetStats()
+		if sample, ok := node.StatsSample(epoch); ok {
+			messages, lookups = sample.Messages, sample.Lookups
+		}
 		totalMessages += messages
 		totalLookups += lookups
+		heatmap.Merge(nodeMetrics[i].ArcHeatmap())
+		if accuracy := node.FingerAccuracy(); accuracy.Checked > 0 {
+			nodeMetrics[i].Gauge(metrics.FingerAccuracy).Set(accuracy.Fraction())
+		}
 		
 		// Write final snapshot
 		if err := nodeMetrics[i].WriteSnapshot(); err != nil {
@@ -212,8 +497,38 @@ func main() {
 	if totalLookups > 0 {
 		log.Printf("Messages per Lookup: %.2f", float64(totalMessages)/float64(totalLookups))
 	}
+	lookups.report(liveNodes)
+	reportModel(nodes, globalMetrics)
+	stragglers.report()
+	crashes.report()
+	slow.report(nodes)
+	reportHeatmap(heatmap)
+	if len(disks) > 0 {
+		reportDisks(disks)
+	}
+	reportTransport(nodes)
+	reportCompression(nodes)
+	reportBandwidth(nodes, globalMetrics)
+	reportFingerAccuracy(nodes)
 	log.Printf("Results saved to: %s", config.ResultsDir)
+}
+
+// waitConverged waits until no node has changed successor or predecessor
+// for config.ConvergeRounds stabilization rounds, or for at most
+// config.ConvergeTimeout
+func waitConverged(config SimulatorConfig, nodes []*chord.Node) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConvergeTimeout)
+	defer cancel()
+	if err := chord.WaitConverged(ctx, nodes, config.ConvergeRounds); err != nil {
+		log.Printf("Starting on an unsettled ring: %v", err)
+		return
+	}
+	log.Printf("Ring converged in %v", time.Since(start).Truncate(time.Millisecond))
+}
 
+// stopNodes stops every live node
+func stopNodes(nodes []*chord.Node) {
 	// Stop all nodes
 	log.Printf("Stopping all nodes...")
 	for i, node := range nodes {
@@ -222,8 +537,6 @@ func main() {
 			log.Printf("Node %d stopped", i)
 		}
 	}
-
-	log.Printf("Simulation finished successfully")
 }
 
 // performRandomLookup performs a random lookup operation
@@ -241,27 +554,366 @@ func performRandomLookup(nodes []*chord.Node, nodeMetrics []*metrics.Metrics, lo
 
 	startTime := time.Now()
 	
-	// Perform lookup (this would call the actual FindSuccessor)
-	// For simulation, we just record the operation
-	successor := node.GetSuccessor() // Simplified - would be actual lookup
+	// Route the lookup through the ring with FindSuccessor
+	owner, hops, err := node.LookupHops(keyHash)
 	
 	latency := time.Since(startTime)
 	
-	if successor == nil {
-		log.Printf("Lookup %d failed: successor is nil", lookupID)
+	if nodeMetrics[nodeIdx] != nil {
+		nodeMetrics[nodeIdx].RecordArcLookup(keyHash, latency, err)
+	}
+	stragglers.record(node, latency, err)
+	slow.record(node, keyHash, latency, err)
+	if err != nil {
+		log.Printf("Lookup %d failed: %v", lookupID, err)
+		lookups.record(0, 0, err)
 		return
 	}
+	lookups.record(hops, latency, nil)
 
 	// Record metrics
 	if nodeMetrics[nodeIdx] != nil {
 		nodeMetrics[nodeIdx].RecordLookup(latency)
+		nodeMetrics[nodeIdx].RecordHops(hops)
 		nodeMetrics[nodeIdx].RecordMessage() // For the lookup request
 	}
 
 	if lookupID%10 == 0 {
-		log.Printf("Performed lookup %d: key=%s, latency=%v", 
-			lookupID, keyHash.String()[:16], latency)
+		log.Printf("Performed lookup %d: key=%s, owner=%s, hops=%d, latency=%v", 
+			lookupID, keyHash.Short(), owner.ID.Short(), hops, latency)
+	}
+}
+
+// lookupStats totals the outcome of the simulated lookups
+type lookupStats struct {
+	mu       sync.Mutex
+	count    int
+	failures int
+	hops     int
+	latency  time.Duration
+}
+
+// lookups are the simulated lookups of the run
+var lookups lookupStats
+
+// record adds the outcome of one lookup
+func (s *lookupStats) record(hops int, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failures++
+		return
+	}
+	s.count++
+	s.hops += hops
+	s.latency += latency
+}
+
+// report logs the average hops against the log2(N) bound of Chord routing
+func (s *lookupStats) report(liveNodes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return
+	}
+	log.Printf("Lookups: %d routed, %d failed", s.count, s.failures)
+	log.Printf("Average Hops: %.2f (log2 N = %.2f)", float64(s.hops)/float64(s.count), math.Log2(float64(liveNodes)))
+	log.Printf("Average Lookup Latency: %v", s.latency/time.Duration(s.count))
+}
+
+// averageHops returns the average hops of the routed lookups, if any
+func (s *lookupStats) averageHops() (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return 0, false
+	}
+	return float64(s.hops) / float64(s.count), true
+}
+
+// reportHeatmap logs the lookup latency of every keyspace arc and points
+// out the slowest one
+func reportHeatmap(heatmap metrics.ArcHeatmap) {
+	slowest := -1
+	for arc, a := range heatmap {
+		if a.Lookups+a.Errors == 0 {
+			continue
+		}
+		if slowest == -1 {
+			log.Printf("Lookup latency by keyspace arc:")
+		}
+		log.Printf("  arc %s: %d lookups, %d failed, avg %v",
+			metrics.ArcLabel(arc), a.Lookups, a.Errors, a.Average().Truncate(time.Microsecond))
+		if slowest == -1 || a.Average() > heatmap[slowest].Average() {
+			slowest = arc
+		}
+	}
+	if slowest != -1 {
+		log.Printf("Slowest arc: %s (avg %v)", metrics.ArcLabel(slowest), heatmap[slowest].Average().Truncate(time.Microsecond))
+	}
+}
+
+// preloadDataset stores count keys using batch puts and reads them back with
+// batch gets, issuing one RPC per responsible node instead of one per key
+func preloadDataset(nodes []*chord.Node, count int) {
+	node := nodes[rand.Intn(len(nodes))]
+	if node == nil {
+		return
+	}
+
+	items := make(map[string][]byte, count)
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("dataset_key_%d", i)
+		items[key] = []byte(fmt.Sprintf("dataset_value_%d", i))
+		keys = append(keys, key)
+	}
+
+	ctx := context.Background()
+	startTime := time.Now()
+	if err := node.StoreBatch(ctx, items); err != nil {
+		log.Printf("Dataset preload failed: %v", err)
+		return
+	}
+	log.Printf("Preloaded %d keys in %v", count, time.Since(startTime))
+
+	startTime = time.Now()
+	values, err := node.FetchBatch(ctx, keys)
+	if err != nil {
+		log.Printf("Dataset verification failed: %v", err)
+		return
+	}
+	log.Printf("Read back %d/%d keys in %v", len(values), count, time.Since(startTime))
+}
+
+// nodeAddress returns the address of the node numbered port: a Unix domain
+// socket in SocketDir if set, otherwise the port on localhost
+func (c SimulatorConfig) nodeAddress(port int) string {
+	if c.SocketDir != "" {
+		return fmt.Sprintf("unix://%s/chord-%d.sock", c.SocketDir, port)
+	}
+	return fmt.Sprintf("localhost:%d", port)
+}
+
+// simulateDisk reports whether nodes should store data on a simulated disk
+func (c SimulatorConfig) simulateDisk() bool {
+	return c.DiskRead > 0 || c.DiskWrite > 0 || c.DiskErrorRate > 0
+}
+
+// wrapDisk puts a node's local store behind a simulated disk
+func wrapDisk(node *chord.Node, config SimulatorConfig) *disksim.Storage {
+	disk := disksim.Wrap(node.Storage(), disksim.Config{
+		ReadLatency:    config.DiskRead,
+		WriteLatency:   config.DiskWrite,
+		Jitter:         (config.DiskRead + config.DiskWrite) / 4,
+		ReadErrorRate:  config.DiskErrorRate,
+		WriteErrorRate: config.DiskErrorRate,
+	})
+	node.SetStorage(disk)
+	return disk
+}
+
+// reportDisks logs the combined simulated disk counters
+func reportDisks(disks []*disksim.Storage) {
+	var total disksim.Stats
+	for _, disk := range disks {
+		stats := disk.Stats()
+		total.Reads += stats.Reads
+		total.Writes += stats.Writes
+		total.ReadErrors += stats.ReadErrors
+		total.WriteErrors += stats.WriteErrors
+		total.Delay += stats.Delay
+	}
+	log.Printf("Disk: %d reads (%d failed), %d writes (%d failed), %v injected latency",
+		total.Reads, total.ReadErrors, total.Writes, total.WriteErrors, total.Delay)
+}
+
+// reportTransport logs the traffic between the live nodes. Running the same
+// simulation with each --maintenance-transport compares their overhead.
+func reportTransport(nodes []*chord.Node) {
+	var total chord.TransportStats
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		stats := node.TransportStats()
+		total.Maintenance = stats.Maintenance
+		total.GRPCBytes += stats.GRPCBytes
+		total.UDPBytes += stats.UDPBytes
+		total.MaintenanceCalls += stats.MaintenanceCalls
+		total.UDPCalls += stats.UDPCalls
+		total.Fallbacks += stats.Fallbacks
+	}
+	log.Printf("Traffic between nodes: %d bytes (%d over gRPC, %d over UDP)",
+		total.GRPCBytes+total.UDPBytes, total.GRPCBytes, total.UDPBytes)
+	log.Printf("Maintenance over %s: %d calls, %d over UDP, %d fell back to gRPC",
+		total.Maintenance, total.MaintenanceCalls, total.UDPCalls, total.Fallbacks)
+}
+
+// reportCompression logs the size of the messages between the live nodes
+// before and after compression
+func reportCompression(nodes []*chord.Node) {
+	var total chord.CompressionStats
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		stats := node.CompressionStats()
+		total.Algorithm = stats.Algorithm
+		total.Compressed += stats.Compressed
+		total.PayloadBytes += stats.PayloadBytes
+		total.WireBytes += stats.WireBytes
+	}
+	if total.PayloadBytes == 0 {
+		return
+	}
+	log.Printf("Compression %s: %d calls compressed, %d payload bytes sent as %d (%.1f%%)",
+		total.Algorithm, total.Compressed, total.PayloadBytes, total.WireBytes,
+		100*float64(total.WireBytes)/float64(total.PayloadBytes))
+}
+
+// reportBandwidth logs the bytes the live nodes sent by category and the
+// share spent on maintenance, and writes each node's to the bandwidth CSV
+func reportBandwidth(nodes []*chord.Node, globalMetrics *metrics.GlobalMetrics) {
+	total := make(metrics.Bandwidth)
+	perNode := make(map[string]metrics.Bandwidth)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		bandwidth := node.Bandwidth()
+		total.Add(bandwidth)
+		perNode[node.GetAddress()] = bandwidth
+	}
+	if total.Total() == 0 {
+		return
+	}
+	for _, category := range metrics.Categories {
+		if t := total[category]; t.Total() > 0 {
+			log.Printf("Bandwidth %s: %d bytes sent, %d received", category, t.Sent, t.Received)
+		}
+	}
+	log.Printf("Maintenance overhead: %.1f%% of %d bytes", 100*total.MaintenanceOverhead(), total.Total())
+	if err := globalMetrics.WriteBandwidth(perNode); err != nil {
+		log.Printf("Error writing bandwidth: %v", err)
+	}
+}
+
+// reportFingerAccuracy logs the fraction of correct fingers found by the
+// live nodes' last finger table checks
+func reportFingerAccuracy(nodes []*chord.Node) {
+	var checked, correct, checking int
+	worst := 1.0
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		accuracy := node.FingerAccuracy()
+		if accuracy.Checked == 0 {
+			continue
+		}
+		checking++
+		checked += accuracy.Checked
+		correct += accuracy.Correct
+		worst = min(worst, accuracy.Fraction())
+	}
+	if checked == 0 {
+		return
+	}
+	log.Printf("Finger accuracy: %d of %d fingers correct (%.1f%%) across %d nodes, worst node %.1f%%",
+		correct, checked, 100*float64(correct)/float64(checked), checking, 100*worst)
+}
+
+// verifyBroadcast broadcasts from a random node and checks that every node
+// in the ring delivered the message exactly once
+func verifyBroadcast(nodes []*chord.Node) {
+	var mu sync.Mutex
+	delivered := make(map[string]int)
+	live := 0
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		live++
+		address := node.GetAddress()
+		node.HandleBroadcast("simulator-coverage", func(msg *chord.BroadcastMessage) {
+			mu.Lock()
+			delivered[address]++
+			mu.Unlock()
+		})
+	}
+
+	origin := nodes[rand.Intn(len(nodes))]
+	if origin == nil {
+		return
+	}
+
+	startTime := time.Now()
+	result, err := origin.Broadcast(context.Background(), "simulator-coverage", nil)
+	if err != nil {
+		log.Printf("Broadcast failed: %v", err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	duplicates := 0
+	for _, count := range delivered {
+		duplicates += count - 1
+	}
+	log.Printf("Broadcast reached %d/%d nodes (depth %d, %d duplicates) in %v",
+		len(delivered), live, result.Depth, duplicates, time.Since(startTime))
+	if len(delivered) != live {
+		log.Printf("WARNING: broadcast did not cover the whole ring")
+	}
+}
+
+// reportDensity crawls the ring and logs the keyspace density of every node
+func reportDensity(start string) {
+	crawler := crawl.New()
+	defer crawler.Close()
+
+	densities, err := crawler.DensityMap(context.Background(), start)
+	if err != nil {
+		log.Printf("Density crawl failed: %v", err)
+		return
+	}
+
+	log.Printf("\n=== Keyspace Density ===")
+	for _, estimate := range densities.Estimates {
+		log.Printf("  %s: %d keys over %.4f of the ring (density %.0f)",
+			estimate.Node.ID.Short(), estimate.Keys, estimate.ArcFraction, estimate.Density)
+	}
+	log.Printf("Mean density: %.0f, min: %.0f, max: %.0f, skew: %.2f",
+		densities.Mean, densities.Min, densities.Max, densities.Skew())
+}
+
+// writeTopology crawls the ring and writes it with finger edges as a DOT file
+func writeTopology(start, path string) {
+	crawler := crawl.New()
+	defer crawler.Close()
+
+	topology, err := crawler.Topology(context.Background(), start)
+	if err != nil {
+		log.Printf("Topology crawl failed: %v", err)
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Printf("Failed to create DOT file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if err := topology.WriteDOT(file, true); err != nil {
+		log.Printf("Failed to write DOT file: %v", err)
+		return
 	}
+	log.Printf("Wrote topology of %d nodes to %s (%d issues)", len(topology.Nodes), path, len(topology.Issues))
 }
 
 // Additional helper functions for analysis
@@ -278,17 +930,17 @@ func analyzeRingStructure(nodes []*chord.Node) {
 		predecessor := node.GetPredecessor()
 		
 		log.Printf("Node %d:", i)
-		log.Printf("  ID: %s", node.GetID().String()[:16])
+		log.Printf("  ID: %s", node.GetID().Short())
 		log.Printf("  Address: %s", node.GetAddress())
 		
 		if successor != nil {
-			log.Printf("  Successor: %s", successor.ID.String()[:16])
+			log.Printf("  Successor: %s", successor.ID.Short())
 		} else {
 			log.Printf("  Successor: nil")
 		}
 		
 		if predecessor != nil {
-			log.Printf("  Predecessor: %s", predecessor.ID.String()[:16])
+			log.Printf("  Predecessor: %s", predecessor.ID.Short())
 		} else {
 			log.Printf("  Predecessor: nil")
 		}
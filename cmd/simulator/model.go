@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/metrics"
+)
+
+// stabilizeRPCs is the number of maintenance RPCs a stabilization round
+// makes on a settled ring: GetInfo and Notify to the successor, and GetPeers
+// refreshing the successor list
+const stabilizeRPCs = 3
+
+// expectedHops is the average number of hops of a Chord lookup in a ring of
+// n nodes, ½·log₂N
+func expectedHops(n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	return math.Log2(float64(n)) / 2
+}
+
+// expectedMaintenanceRPCs is the number of maintenance RPCs a node up for
+// uptime makes at the default intervals: stabilizeRPCs every
+// StabilizeInterval and a predecessor ping every CheckPredecessorInterval.
+// Finger fixing is left out; its lookups count as routing.
+func expectedMaintenanceRPCs(uptime time.Duration) float64 {
+	return stabilizeRPCs*float64(uptime)/float64(chord.StabilizeInterval) +
+		float64(uptime)/float64(chord.CheckPredecessorInterval)
+}
+
+// reportModel logs the measured lookup hops and maintenance RPCs of the run
+// next to what the model of Chord predicts and records both, with their
+// ratios, in model_<experiment>.csv, so that a regression in routing
+// efficiency or maintenance cost stands out
+func reportModel(nodes []*chord.Node, globalMetrics *metrics.GlobalMetrics) {
+	var comparisons []metrics.ModelComparison
+	liveNodes := 0
+	var measured, expected float64
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		liveNodes++
+		measured += float64(node.Bandwidth()[metrics.CategoryMaintenance].Calls)
+		// A static ring runs no maintenance at all
+		if !node.Static() {
+			expected += expectedMaintenanceRPCs(node.Stats().Uptime)
+		}
+	}
+	if hops, ok := lookups.averageHops(); ok && liveNodes > 1 {
+		comparisons = append(comparisons, metrics.ModelComparison{
+			Name: "lookup_hops", Measured: hops, Expected: expectedHops(liveNodes),
+		})
+	}
+	if liveNodes > 0 {
+		comparisons = append(comparisons, metrics.ModelComparison{
+			Name:     "maintenance_rpcs_per_node",
+			Measured: measured / float64(liveNodes),
+			Expected: expected / float64(liveNodes),
+		})
+	}
+	if len(comparisons) == 0 {
+		return
+	}
+
+	log.Printf("Measured vs expected (½·log₂N hops, %d RPCs per %v stabilization, a ping per %v predecessor check):",
+		stabilizeRPCs, chord.StabilizeInterval, chord.CheckPredecessorInterval)
+	for _, c := range comparisons {
+		if c.Expected == 0 {
+			log.Printf("  %s: %.2f measured, none expected", c.Name, c.Measured)
+			continue
+		}
+		log.Printf("  %s: %.2f measured, %.2f expected, ratio %.2f", c.Name, c.Measured, c.Expected, c.Ratio())
+	}
+	if err := globalMetrics.WriteModel(comparisons); err != nil {
+		log.Printf("Error writing model comparison: %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/disksim"
+	"chord-dht/internal/chord/middleware"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a timed sequence of events loaded from a YAML file:
+//
+//	duration: 120s
+//	events:
+//	  - at: 0s
+//	    join: 5
+//	  - at: 10s
+//	    until: 90s
+//	    lookups: 500
+//	  - at: 30s
+//	    kill: [3]
+//	  - at: 60s
+//	    partition: [[0, 1, 2], [3, 4]]
+//	  - at: 80s
+//	    heal: true
+//
+// Nodes are numbered in the order they join, starting at 0.
+type Scenario struct {
+	// Duration is how long the scenario runs; it is extended to the last
+	// event if shorter
+	Duration time.Duration `yaml:"duration"`
+	Events   []Event       `yaml:"events"`
+}
+
+// Event is one step of a scenario. Exactly one action must be set.
+type Event struct {
+	At time.Duration `yaml:"at"`
+	// Until spreads lookups evenly between At and Until
+	Until time.Duration `yaml:"until"`
+
+	// Join starts this many new nodes and joins them to the ring
+	Join int `yaml:"join"`
+	// Kill stops the nodes with these indexes
+	Kill []int `yaml:"kill"`
+	// Partition splits the listed nodes into groups that cannot reach each
+	// other, replacing any earlier partition
+	Partition [][]int `yaml:"partition"`
+	// Heal removes the current partition
+	Heal bool `yaml:"heal"`
+	// Lookups is the number of random lookups to run
+	Lookups int `yaml:"lookups"`
+}
+
+// LoadScenario reads and validates a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	sort.SliceStable(scenario.Events, func(i, j int) bool {
+		return scenario.Events[i].At < scenario.Events[j].At
+	})
+	for _, event := range scenario.Events {
+		if end := event.end(); end > scenario.Duration {
+			scenario.Duration = end
+		}
+	}
+	return &scenario, nil
+}
+
+// validate checks that every event has exactly one action and refers only to
+// nodes that have joined by then
+func (s *Scenario) validate() error {
+	if len(s.Events) == 0 {
+		return fmt.Errorf("no events")
+	}
+
+	events := append([]Event(nil), s.Events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+
+	joined := 0
+	for i, event := range events {
+		actions := 0
+		for _, set := range []bool{event.Join > 0, len(event.Kill) > 0, len(event.Partition) > 0, event.Heal, event.Lookups > 0} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("event %d at %v must have exactly one of join, kill, partition, heal or lookups", i, event.At)
+		}
+		if event.Until != 0 && (event.Lookups == 0 || event.Until < event.At) {
+			return fmt.Errorf("event %d at %v: until must follow at and is only valid with lookups", i, event.At)
+		}
+
+		indexes := append([]int(nil), event.Kill...)
+		for _, group := range event.Partition {
+			indexes = append(indexes, group...)
+		}
+		for _, index := range indexes {
+			if index < 0 || index >= joined {
+				return fmt.Errorf("event %d at %v: node %d has not joined", i, event.At, index)
+			}
+		}
+		joined += event.Join
+	}
+	if joined == 0 {
+		return fmt.Errorf("no nodes join")
+	}
+	return nil
+}
+
+// end returns when the event finishes
+func (e Event) end() time.Duration {
+	if e.Until > e.At {
+		return e.Until
+	}
+	return e.At
+}
+
+// scenarioRun is the state of a running scenario
+type scenarioRun struct {
+	config SimulatorConfig
+	dash   *dashboard
+
+	mu          sync.Mutex
+	nodes       []*chord.Node
+	partitions  []*middleware.Partition
+	nodeMetrics []*metrics.Metrics
+	disks       []*disksim.Storage
+	lookups     int
+}
+
+// runScenario runs the events of the scenario at path against a fresh ring,
+// showing it on dash if not nil
+func runScenario(config SimulatorConfig, path string, dash *dashboard) {
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		log.Fatalf("Failed to load scenario: %v", err)
+	}
+	config.Duration = scenario.Duration
+
+	log.Printf("Starting Chord DHT Simulator")
+	log.Printf("Scenario: %s (%d events over %v)", path, len(scenario.Events), scenario.Duration)
+	log.Printf("  Base Port: %d", config.BasePort)
+	log.Printf("  Results Dir: %s", config.ResultsDir)
+	log.Printf("  Experiment ID: %s", config.ExperimentID)
+
+	run := &scenarioRun{config: config, dash: dash}
+	dash.SetPhase("Scenario", scenario.Duration)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	if stragglers != nil {
+		go run.replaceStragglers(done)
+	}
+	start := time.Now()
+	for _, event := range scenario.Events {
+		time.Sleep(time.Until(start.Add(event.At)))
+		log.Printf("t=%v: %s", event.At, event.describe())
+
+		switch {
+		case event.Join > 0:
+			run.join(event.Join)
+		case len(event.Kill) > 0:
+			run.kill(event.Kill)
+		case len(event.Partition) > 0:
+			run.partition(event.Partition)
+		case event.Heal:
+			run.heal()
+		case event.Lookups > 0:
+			wg.Add(1)
+			go func(event Event) {
+				defer wg.Done()
+				run.lookup(event.Lookups, event.Until-event.At)
+			}(event)
+		}
+	}
+	wg.Wait()
+	time.Sleep(time.Until(start.Add(scenario.Duration)))
+	close(done)
+	log.Printf("Scenario completed")
+
+	run.heal()
+	verifyBroadcast(run.nodes)
+	if config.DOTOut != "" {
+		for _, node := range run.nodes {
+			if node != nil {
+				writeTopology(node.GetAddress(), config.DOTOut)
+				break
+			}
+		}
+	}
+
+	dash.Stop()
+	config.NumNodes = len(run.nodes)
+	collectResults(config, run.nodes, run.nodeMetrics, run.disks)
+	stopNodes(run.nodes)
+	log.Printf("Simulation finished successfully")
+}
+
+// describe returns a short description of the event for the log
+func (e Event) describe() string {
+	switch {
+	case e.Join > 0:
+		return fmt.Sprintf("join %d nodes", e.Join)
+	case len(e.Kill) > 0:
+		return fmt.Sprintf("kill nodes %v", e.Kill)
+	case len(e.Partition) > 0:
+		return fmt.Sprintf("partition %v", e.Partition)
+	case e.Heal:
+		return "heal partition"
+	default:
+		return fmt.Sprintf("%d lookups over %v", e.Lookups, e.Until-e.At)
+	}
+}
+
+// join starts count new nodes and joins them one by one through the first
+// live node, which paces them with its join limit
+func (r *scenarioRun) join(count int) {
+	for i := 0; i < count; i++ {
+		r.mu.Lock()
+		index := len(r.nodes)
+		bootstrap := ""
+		for _, node := range r.nodes {
+			if node != nil {
+				bootstrap = node.GetAddress()
+				break
+			}
+		}
+		r.mu.Unlock()
+
+		addr := r.config.nodeAddress(r.config.BasePort+index)
+		node := chord.NewNode(addr, hash.GenerateID(addr))
+		node.SetJoinLimit(joinLimit)
+		node.SetMaintenanceTransport(r.config.Transport)
+		node.SetNetwork(r.config.Network)
+		node.SetCompression(r.config.Compression)
+	node.SetFingerCheck(r.config.FingerCheck)
+		node.SetLookupHedging(r.config.LookupHedge)
+		partition := middleware.NewPartition()
+		node.Use(partition.Middleware())
+
+		var disk *disksim.Storage
+		if r.config.simulateDisk() {
+			disk = wrapDisk(node, r.config)
+		}
+		if err := node.Start(); err != nil {
+			log.Printf("Failed to start node %d: %v", index, err)
+			node = nil
+		} else if err := node.Join(bootstrap); err != nil {
+			log.Printf("Failed to join node %d to ring: %v", index, err)
+			node.Stop()
+			node = nil
+		}
+
+		var nodeMetrics *metrics.Metrics
+		if node != nil {
+			var err error
+			nodeMetrics, err = metrics.NewMetrics(node.GetID().String(), r.config.ResultsDir, r.config.ExperimentID)
+			if err != nil {
+				log.Printf("Failed to initialize metrics for node %d: %v", index, err)
+			}
+			log.Printf("Node %d joined ring: ID=%s, Address=%s", index, node.GetID().Short(), addr)
+		}
+
+		r.mu.Lock()
+		r.nodes = append(r.nodes, node)
+		r.partitions = append(r.partitions, partition)
+		r.nodeMetrics = append(r.nodeMetrics, nodeMetrics)
+		if disk != nil {
+			r.disks = append(r.disks, disk)
+		}
+		r.updateNodeCount()
+		r.dash.SetNodes(r.nodes, r.nodeMetrics)
+		r.mu.Unlock()
+	}
+}
+
+// kill stops the given nodes and writes their final metrics
+func (r *scenarioRun) kill(indexes []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, index := range indexes {
+		node := r.nodes[index]
+		if node == nil {
+			continue
+		}
+		node.Stop()
+		r.nodes[index] = nil
+		if m := r.nodeMetrics[index]; m != nil {
+			if err := m.WriteSnapshot(); err != nil {
+				log.Printf("Error writing final metrics for node %d: %v", index, err)
+			}
+			m.Close()
+			r.nodeMetrics[index] = nil
+		}
+		log.Printf("Node %d stopped", index)
+	}
+	r.updateNodeCount()
+	r.dash.SetNodes(r.nodes, r.nodeMetrics)
+}
+
+// partition makes the nodes of each group unreachable from the others
+func (r *scenarioRun) partition(groups [][]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, partition := range r.partitions {
+		partition.Heal()
+	}
+	for i, group := range groups {
+		var others []string
+		for j, other := range groups {
+			if i == j {
+				continue
+			}
+			for _, index := range other {
+				others = append(others, r.config.nodeAddress(r.config.BasePort+index))
+			}
+		}
+		for _, index := range group {
+			r.partitions[index].Block(others...)
+		}
+	}
+}
+
+// heal removes the current partition
+func (r *scenarioRun) heal() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, partition := range r.partitions {
+		partition.Heal()
+	}
+}
+
+// lookup runs count random lookups spread evenly over span
+func (r *scenarioRun) lookup(count int, span time.Duration) {
+	interval := span / time.Duration(count)
+	for i := 0; i < count; i++ {
+		r.mu.Lock()
+		nodes := append([]*chord.Node(nil), r.nodes...)
+		nodeMetrics := append([]*metrics.Metrics(nil), r.nodeMetrics...)
+		lookupID := r.lookups
+		r.lookups++
+		r.mu.Unlock()
+
+		performRandomLookup(nodes, nodeMetrics, lookupID)
+		time.Sleep(interval)
+	}
+}
+
+// replaceStragglers replaces the worst straggler every policy interval,
+// with a kill and a fresh join, until done is closed
+func (r *scenarioRun) replaceStragglers(done <-chan struct{}) {
+	ticker := time.NewTicker(stragglers.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+
+		r.mu.Lock()
+		index, reason := stragglers.find(r.nodes)
+		r.mu.Unlock()
+		if index == -1 {
+			continue
+		}
+		log.Printf("Replacing straggler node %d: %s", index, reason)
+		r.kill([]int{index})
+		r.join(1)
+
+		r.mu.Lock()
+		node := r.nodes[len(r.nodes)-1]
+		r.mu.Unlock()
+		if node == nil {
+			stragglers.replacementFailed()
+			continue
+		}
+		stragglers.watch(node)
+	}
+}
+
+// updateNodeCount tells every live node's metrics the current ring size.
+// The caller must hold mu.
+func (r *scenarioRun) updateNodeCount() {
+	updateNodeCount(r.nodes, r.nodeMetrics)
+}
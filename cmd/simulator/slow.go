@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/middleware"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+)
+
+// slowNodes delays every RPC served by a fraction of the nodes of a fixed
+// simulation and compares the lookups whose first hop is a slow node with
+// the others. A nil value slows nothing and records nothing.
+type slowNodes struct {
+	delay   time.Duration
+	indexes []int
+	// addresses of the slow nodes; a crashed slow node recovers slow
+	addresses map[string]bool
+	// served counts the RPCs the slow nodes delayed
+	served atomic.Int64
+
+	mu sync.Mutex
+	// Latencies of the successful lookups by whether their first hop was a
+	// slow node, and the failed ones
+	slowHop   []time.Duration
+	normalHop []time.Duration
+	failures  [2]int
+}
+
+// slow holds the nodes slowed by --slow-fraction, if any
+var slow *slowNodes
+
+// newSlowNodes picks fraction of the nodes of config at random to slow down
+// by delay
+func newSlowNodes(config SimulatorConfig, fraction float64, delay time.Duration) (*slowNodes, error) {
+	if fraction == 0 {
+		return nil, nil
+	}
+	switch {
+	case fraction < 0 || fraction >= 1:
+		return nil, fmt.Errorf("--slow-fraction must be at least 0 and below 1, got %v", fraction)
+	case delay <= 0:
+		return nil, fmt.Errorf("--slow-delay must be positive, got %v", delay)
+	}
+	count := max(1, int(math.Round(fraction*float64(config.NumNodes))))
+	if count >= config.NumNodes {
+		return nil, fmt.Errorf("%d of %d nodes would be slow, leaving no normal node to compare with", count, config.NumNodes)
+	}
+	indexes := rand.Perm(config.NumNodes)[:count]
+	sort.Ints(indexes)
+	return &slowNodes{delay: delay, indexes: indexes, addresses: make(map[string]bool)}, nil
+}
+
+// String lists the indexes of the slow nodes
+func (s *slowNodes) String() string {
+	parts := make([]string, len(s.indexes))
+	for i, index := range s.indexes {
+		parts[i] = fmt.Sprint(index)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// slowDown makes node index slow if it was picked. It must be called before
+// the node starts.
+func (s *slowNodes) slowDown(index int, node *chord.Node) {
+	if s == nil {
+		return
+	}
+	if !s.addresses[node.GetAddress()] {
+		picked := false
+		for _, i := range s.indexes {
+			picked = picked || i == index
+		}
+		if !picked {
+			return
+		}
+		s.addresses[node.GetAddress()] = true
+	}
+	node.Use(s.counter(), middleware.FaultInjection(middleware.Faults{Delay: s.delay}))
+}
+
+// counter counts the calls served by a slow node
+func (s *slowNodes) counter() chord.Middleware {
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			s.served.Add(1)
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			s.served.Add(1)
+			return handler(srv, ss)
+		},
+	}
+}
+
+// record adds a lookup for key started by node. The first hop is where node
+// forwards key to once the lookup is done, which is where it went unless
+// the routing changed meanwhile. Lookups node answers itself are left out.
+func (s *slowNodes) record(node *chord.Node, key *hash.Hash, latency time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	first := node.NextHop(key)
+	if first == nil || first.Address == node.GetAddress() {
+		return
+	}
+	slowHop := s.addresses[first.Address]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil && slowHop:
+		s.failures[0]++
+	case err != nil:
+		s.failures[1]++
+	case slowHop:
+		s.slowHop = append(s.slowHop, latency)
+	default:
+		s.normalHop = append(s.normalHop, latency)
+	}
+}
+
+// report logs the latency of the lookups through slow and normal first hops
+// and how often the live nodes hedged their lookup hops
+func (s *slowNodes) report(nodes []*chord.Node) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Printf("Slow nodes: %s, each call they served delayed by %v (%d calls)", s, s.delay, s.served.Load())
+	log.Printf("  lookups with a slow first hop: %s, %d failed", latencySummary(s.slowHop), s.failures[0])
+	log.Printf("  lookups with a normal first hop: %s, %d failed", latencySummary(s.normalHop), s.failures[1])
+
+	var total chord.HedgeStats
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		stats := node.LookupHedgeStats()
+		total.Reads += stats.Reads
+		total.Hedged += stats.Hedged
+		total.HedgeWins += stats.HedgeWins
+	}
+	if total.Reads == 0 {
+		log.Printf("  lookup hops were not hedged (see --lookup-hedge-percentile)")
+		return
+	}
+	log.Printf("  lookup hops hedged: %d of %d (%.1f%%), %d won by the hedge",
+		total.Hedged, total.Reads, 100*float64(total.Hedged)/float64(total.Reads), total.HedgeWins)
+}
+
+// latencySummary describes the median, 99th percentile and maximum of
+// latencies
+func latencySummary(latencies []time.Duration) string {
+	if len(latencies) == 0 {
+		return "none"
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))].Truncate(time.Microsecond)
+	}
+	return fmt.Sprintf("%d, p50 %v, p99 %v, max %v", len(sorted), at(0.5), at(0.99), at(1))
+}
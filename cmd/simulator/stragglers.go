@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/disksim"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+)
+
+// readyTimeout bounds how long a replacement node is watched until it is
+// ready
+const readyTimeout = 30 * time.Second
+
+// stragglerPolicy decides when a node is replaced. A node is judged once it
+// has started MinLookups lookups since it was last judged.
+type stragglerPolicy struct {
+	// MinSuccess is the fraction of lookups that must succeed
+	MinSuccess float64
+	// MaxLatency is the highest average lookup latency allowed (0 disables
+	// the check)
+	MaxLatency time.Duration
+	MinLookups int
+	// Interval is how often stragglers are looked for; at most one node is
+	// replaced per interval
+	Interval time.Duration
+}
+
+// lookupWindow counts the lookups started by a node since it was last judged
+type lookupWindow struct {
+	lookups  int
+	failures int
+	latency  time.Duration
+}
+
+// successRate returns the fraction of lookups that succeeded
+func (w *lookupWindow) successRate() float64 {
+	if w.lookups == 0 {
+		return 1
+	}
+	return float64(w.lookups-w.failures) / float64(w.lookups)
+}
+
+// averageLatency returns the mean latency of the successful lookups
+func (w *lookupWindow) averageLatency() time.Duration {
+	if w.lookups == w.failures {
+		return 0
+	}
+	return w.latency / time.Duration(w.lookups-w.failures)
+}
+
+// add counts one lookup
+func (w *lookupWindow) add(latency time.Duration, err error) {
+	w.lookups++
+	if err != nil {
+		w.failures++
+		return
+	}
+	w.latency += latency
+}
+
+// stragglerTracker finds nodes whose lookups degrade past the policy and
+// measures how the ring absorbs their replacement. A nil tracker records
+// nothing and finds no stragglers.
+type stragglerTracker struct {
+	policy stragglerPolicy
+
+	mu        sync.Mutex
+	windows   map[*chord.Node]*lookupWindow
+	lastCheck time.Time
+	bySuccess int
+	byLatency int
+	failed    int
+	// Time from a replacement node's join until it was ready, and the
+	// replacements that never got there
+	settle   []time.Duration
+	unready  int
+	settling int
+	// Lookups while some replacement was settling, and otherwise
+	during lookupWindow
+	steady lookupWindow
+}
+
+// stragglers tracks the simulated lookups when --replace-stragglers is set
+var stragglers *stragglerTracker
+
+// newStragglerTracker creates a tracker applying policy
+func newStragglerTracker(policy stragglerPolicy) *stragglerTracker {
+	return &stragglerTracker{
+		policy:    policy,
+		windows:   make(map[*chord.Node]*lookupWindow),
+		lastCheck: time.Now(),
+	}
+}
+
+// record counts a lookup started by node
+func (t *stragglerTracker) record(node *chord.Node, latency time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	window, ok := t.windows[node]
+	if !ok {
+		window = &lookupWindow{}
+		t.windows[node] = window
+	}
+	window.add(latency, err)
+	if t.settling > 0 {
+		t.during.add(latency, err)
+	} else {
+		t.steady.add(latency, err)
+	}
+}
+
+// due reports whether an interval has passed since the last check, and
+// starts the next one if so
+func (t *stragglerTracker) due() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Since(t.lastCheck) < t.policy.Interval {
+		return false
+	}
+	t.lastCheck = time.Now()
+	return true
+}
+
+// find returns the index of the worst straggler among the live nodes and
+// why it is one, or -1. Every node with enough lookups is judged and starts
+// a new window. At least two live nodes are kept.
+func (t *stragglerTracker) find(nodes []*chord.Node) (int, string) {
+	if t == nil {
+		return -1, ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	live := 0
+	for _, node := range nodes {
+		if node != nil {
+			live++
+		}
+	}
+
+	worst, worstRate, worstLatency, reason := -1, 1.0, time.Duration(0), ""
+	for i, node := range nodes {
+		window := t.windows[node]
+		if node == nil || window == nil || window.lookups < t.policy.MinLookups {
+			continue
+		}
+		delete(t.windows, node)
+
+		rate, latency := window.successRate(), window.averageLatency()
+		switch {
+		case rate < t.policy.MinSuccess && rate < worstRate:
+			worst, worstRate, worstLatency = i, rate, latency
+			reason = fmt.Sprintf("%.0f%% of %d lookups succeeded", rate*100, window.lookups)
+		case t.policy.MaxLatency > 0 && latency > t.policy.MaxLatency &&
+			worstRate == 1 && latency > worstLatency:
+			worst, worstLatency = i, latency
+			reason = fmt.Sprintf("average lookup latency %v", latency.Truncate(time.Microsecond))
+		}
+	}
+	if worst == -1 || live <= 2 {
+		return -1, ""
+	}
+	if worstRate < 1 {
+		t.bySuccess++
+	} else {
+		t.byLatency++
+	}
+	return worst, reason
+}
+
+// watch measures how long a replacement node takes to become ready
+func (t *stragglerTracker) watch(node *chord.Node) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.settling++
+	t.mu.Unlock()
+
+	go func() {
+		start := time.Now()
+		for node.Ready() != nil && time.Since(start) < readyTimeout {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.settling--
+		if node.Ready() != nil {
+			t.unready++
+			return
+		}
+		t.settle = append(t.settle, time.Since(start))
+	}()
+}
+
+// replacementFailed counts a straggler that was stopped but could not be
+// replaced
+func (t *stragglerTracker) replacementFailed() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.failed++
+}
+
+// report logs the replacements and the lookups around them
+func (t *stragglerTracker) report() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	log.Printf("Stragglers replaced: %d (%d by success rate, %d by latency, %d replacements failed to join)",
+		t.bySuccess+t.byLatency, t.bySuccess, t.byLatency, t.failed)
+	if len(t.settle) > 0 {
+		var total, slowest time.Duration
+		for _, d := range t.settle {
+			total += d
+			if d > slowest {
+				slowest = d
+			}
+		}
+		log.Printf("Replacement time to ready: avg %v, max %v (%d never ready)",
+			(total / time.Duration(len(t.settle))).Truncate(time.Millisecond), slowest.Truncate(time.Millisecond), t.unready)
+	}
+	log.Printf("Lookup success while a replacement settled: %.1f%% of %d, otherwise %.1f%% of %d",
+		t.during.successRate()*100, t.during.lookups, t.steady.successRate()*100, t.steady.lookups)
+}
+
+// replaceStraggler stops the worst straggler of a fixed simulation and joins
+// a fresh node on port in its place, through another live node
+func replaceStraggler(config SimulatorConfig, nodes []*chord.Node, nodeMetrics []*metrics.Metrics, disks *[]*disksim.Storage, port int) bool {
+	index, reason := stragglers.find(nodes)
+	if index == -1 {
+		return false
+	}
+	log.Printf("Replacing straggler node %d (%s): %s", index, nodes[index].GetID().Short(), reason)
+
+	nodes[index].Stop()
+	nodes[index] = nil
+	if m := nodeMetrics[index]; m != nil {
+		if err := m.WriteSnapshot(); err != nil {
+			log.Printf("Error writing final metrics for node %d: %v", index, err)
+		}
+		m.Close()
+		nodeMetrics[index] = nil
+	}
+
+	bootstrap := ""
+	for _, node := range nodes {
+		if node != nil {
+			bootstrap = node.GetAddress()
+			break
+		}
+	}
+
+	addr := config.nodeAddress(port)
+	node := chord.NewNode(addr, hash.GenerateID(addr))
+	node.SetJoinLimit(joinLimit)
+	node.SetMaintenanceTransport(config.Transport)
+	node.SetNetwork(config.Network)
+	node.SetCompression(config.Compression)
+	node.SetFingerCheck(config.FingerCheck)
+	node.SetLookupHedging(config.LookupHedge)
+	if config.simulateDisk() {
+		*disks = append(*disks, wrapDisk(node, config))
+	}
+	if err := node.Start(); err != nil {
+		log.Printf("Failed to start replacement for node %d: %v", index, err)
+		stragglers.replacementFailed()
+		return true
+	}
+	if err := node.Join(bootstrap); err != nil {
+		log.Printf("Failed to join replacement for node %d: %v", index, err)
+		node.Stop()
+		stragglers.replacementFailed()
+		return true
+	}
+
+	m, err := metrics.NewMetrics(node.GetID().String(), config.ResultsDir, config.ExperimentID)
+	if err != nil {
+		log.Printf("Failed to initialize metrics for node %d: %v", index, err)
+	}
+	nodes[index] = node
+	nodeMetrics[index] = m
+	updateNodeCount(nodes, nodeMetrics)
+	stragglers.watch(node)
+	log.Printf("Node %d replaced: ID=%s, Address=%s", index, node.GetID().Short(), addr)
+	return true
+}
+
+// updateNodeCount tells every live node's metrics the current ring size
+func updateNodeCount(nodes []*chord.Node, nodeMetrics []*metrics.Metrics) {
+	live := 0
+	for _, node := range nodes {
+		if node != nil {
+			live++
+		}
+	}
+	for _, m := range nodeMetrics {
+		if m != nil {
+			m.UpdateNodeCount(live)
+		}
+	}
+}
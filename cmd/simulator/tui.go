@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/metrics"
+)
+
+const (
+	// dashboardInterval is how often the dashboard is redrawn
+	dashboardInterval = time.Second
+	// progressWidth is the width of the progress bar in characters
+	progressWidth = 40
+	// clearScreen moves the cursor home and clears the terminal
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// dashboard redraws a live view of the simulated ring in the terminal:
+// one row per node, aggregate lookup latency and the progress of the
+// current phase
+type dashboard struct {
+	out        io.Writer
+	experiment string
+	logFile    *os.File
+
+	mu          sync.Mutex
+	nodes       []*chord.Node
+	nodeMetrics []*metrics.Metrics
+	phase       string
+	phaseStart  time.Time
+	phaseLength time.Duration
+	messages    map[string]int64
+	sampledAt   time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startDashboard sends the log to a file in the results directory and starts
+// redrawing the dashboard on stdout
+func startDashboard(config SimulatorConfig) (*dashboard, error) {
+	if err := os.MkdirAll(config.ResultsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create results directory: %w", err)
+	}
+	path := filepath.Join(config.ResultsDir, fmt.Sprintf("simulator_%s.log", config.ExperimentID))
+	logFile, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	log.SetOutput(logFile)
+
+	d := &dashboard{
+		out:        os.Stdout,
+		experiment: config.ExperimentID,
+		logFile:    logFile,
+		messages:   make(map[string]int64),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+// Stop draws the dashboard one last time and sends the log back to stderr
+func (d *dashboard) Stop() {
+	if d == nil {
+		return
+	}
+	close(d.stop)
+	<-d.done
+
+	log.SetOutput(os.Stderr)
+	d.logFile.Close()
+	log.Printf("Simulator log written to %s", d.logFile.Name())
+}
+
+// SetNodes sets the nodes shown, nil entries being stopped nodes, and their
+// metrics if already collected
+func (d *dashboard) SetNodes(nodes []*chord.Node, nodeMetrics []*metrics.Metrics) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nodes = append([]*chord.Node(nil), nodes...)
+	d.nodeMetrics = append([]*metrics.Metrics(nil), nodeMetrics...)
+}
+
+// SetPhase names the current phase of the simulation. A non-zero length
+// shows a progress bar for it.
+func (d *dashboard) SetPhase(phase string, length time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.phase = phase
+	d.phaseStart = time.Now()
+	d.phaseLength = length
+}
+
+// run redraws the dashboard until stopped
+func (d *dashboard) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(dashboardInterval)
+	defer ticker.Stop()
+
+	for {
+		d.draw()
+		select {
+		case <-ticker.C:
+		case <-d.stop:
+			d.draw()
+			return
+		}
+	}
+}
+
+// draw renders one frame
+func (d *dashboard) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	interval := now.Sub(d.sampledAt).Seconds()
+	d.sampledAt = now
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	fmt.Fprintf(&b, "Chord DHT Simulator - %s\n\n", d.experiment)
+
+	elapsed := now.Sub(d.phaseStart).Truncate(time.Second)
+	if d.phaseLength > 0 {
+		fraction := min(float64(elapsed)/float64(d.phaseLength), 1)
+		filled := int(fraction * progressWidth)
+		fmt.Fprintf(&b, "%-14s [%s%s] %3.0f%%  %v / %v\n\n", d.phase,
+			strings.Repeat("#", filled), strings.Repeat(".", progressWidth-filled),
+			fraction*100, elapsed, d.phaseLength)
+	} else {
+		fmt.Fprintf(&b, "%-14s %v\n\n", d.phase, elapsed)
+	}
+
+	fmt.Fprintf(&b, "%-4s %-8s %-16s %-8s %-8s %8s %10s\n",
+		"#", "ID", "ADDRESS", "SUCC", "PRED", "KEYS", "MSGS/S")
+	live := 0
+	for i, node := range d.nodes {
+		if node == nil {
+			fmt.Fprintf(&b, "%-4d %-8s\n", i, "stopped")
+			continue
+		}
+		live++
+
+		messages, _ := node.GetStats()
+		address := node.GetAddress()
+		rate := 0.0
+		if previous, ok := d.messages[address]; ok && interval > 0 {
+			rate = float64(messages-previous) / interval
+		}
+		d.messages[address] = messages
+
+		fmt.Fprintf(&b, "%-4d %-8s %-16s %-8s %-8s %8d %10.1f\n", i,
+			node.GetID().Short(), address,
+			shortID(node.GetSuccessor()), shortID(node.GetPredecessor()),
+			node.LocalDensity().Keys, rate)
+	}
+
+	var lookups int64
+	var latencySum float64
+	for _, m := range d.nodeMetrics {
+		if m == nil {
+			continue
+		}
+		_, _, count, avgLatency := m.GetCurrentStats()
+		lookups += count
+		latencySum += avgLatency * float64(count)
+	}
+	avgLatency := 0.0
+	if lookups > 0 {
+		avgLatency = latencySum / float64(lookups)
+	}
+	fmt.Fprintf(&b, "\nLive nodes: %d/%d   Lookups: %d   Avg lookup latency: %.3fms\n",
+		live, len(d.nodes), lookups, avgLatency)
+
+	io.WriteString(d.out, b.String())
+}
+
+// shortID returns the first 8 hex digits of a node's ID, or "-"
+func shortID(node *chord.NodeInfo) string {
+	if node == nil {
+		return "-"
+	}
+	return node.ID.Short()
+}
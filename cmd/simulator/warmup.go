@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+)
+
+// warmSnapshotPattern matches the node snapshots of a saved ring
+const warmSnapshotPattern = "*.snapshot"
+
+// warmNode is a node of a ring saved by a previous run
+type warmNode struct {
+	path    string
+	id      *hash.Hash
+	address string
+	state   *chord.RoutingState
+}
+
+// loadWarmRing describes the node snapshots saved in dir, in file name order
+func loadWarmRing(dir string) ([]*warmNode, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, warmSnapshotPattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshots in %s", dir)
+	}
+
+	ring := make([]*warmNode, 0, len(paths))
+	for _, path := range paths {
+		info, err := readSnapshotInfo(path)
+		if err != nil {
+			return nil, err
+		}
+		id, err := hash.NewHashFromHex(info.State.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ring = append(ring, &warmNode{path: path, id: id, address: info.State.Address})
+	}
+	return ring, nil
+}
+
+// readSnapshotInfo checks the snapshot at path and describes it
+func readSnapshotInfo(path string) (*chord.SnapshotInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := chord.ReadSnapshot(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return info, nil
+}
+
+// restore loads the keys of the snapshot into node, which must not have
+// joined yet, and keeps its routing state for warmFingers
+func (w *warmNode) restore(node *chord.Node) error {
+	file, err := os.Open(w.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w.state, err = node.RestoreSnapshot(file)
+	return err
+}
+
+// warmFingers seeds the finger tables of the joined nodes with the fingers
+// they had in the saved ring
+func warmFingers(nodes []*chord.Node, ring []*warmNode) {
+	seeded := 0
+	for i, node := range nodes {
+		if node != nil && ring[i].state != nil {
+			seeded += node.WarmFingers(ring[i].state)
+		}
+	}
+	log.Printf("Seeded %d fingers from the saved ring", seeded)
+}
+
+// saveWarmRing writes a snapshot of every live node to dir, replacing the
+// snapshots of an earlier run, for a later run to start from with
+// --warm-from
+func saveWarmRing(dir string, nodes []*chord.Node) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Failed to create warm-up directory: %v", err)
+		return
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, warmSnapshotPattern))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+
+	startTime := time.Now()
+	saved := 0
+	for i, node := range nodes {
+		if node == nil {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("node-%03d.snapshot", i))
+		if err := node.SaveSnapshot(path); err != nil {
+			log.Printf("Failed to save snapshot of node %d: %v", i, err)
+			continue
+		}
+		saved++
+	}
+	log.Printf("Saved snapshots of %d nodes to %s in %v", saved, dir, time.Since(startTime))
+}
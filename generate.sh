@@ -1,8 +1,8 @@
 #!/bin/bash
 
-# Generate protobuf files
-protoc --go_out=. --go_opt=paths=source_relative \
-    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
-    proto/chord.proto
+# Generate the Go stubs of the API, which are checked in
+protoc -I api --go_out=api --go_opt=paths=source_relative \
+    --go-grpc_out=api --go-grpc_opt=paths=source_relative \
+    api/chord/v1/*.proto
 
 echo "Protobuf files generated successfully"
\ No newline at end of file
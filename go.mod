@@ -5,13 +5,28 @@ go 1.24.0
 toolchain go1.24.10
 
 require (
+	chord-dht/api v0.0.0-00010101000000-000000000000
+	chord-dht/pkg/hash v0.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.19.2
+	github.com/quic-go/quic-go v0.59.1
+	golang.org/x/net v0.47.0
+	golang.org/x/term v0.37.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251111163417-95abcf5c77ba
 	google.golang.org/grpc v1.77.0
 	google.golang.org/protobuf v1.36.10
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	golang.org/x/net v0.47.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	golang.org/x/crypto v0.44.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20251111163417-95abcf5c77ba // indirect
+)
+
+// The hash package and the generated gRPC API are separate modules, so that
+// clients can import them without the server's dependencies
+replace (
+	chord-dht/api => ./api
+	chord-dht/pkg/hash => ./pkg/hash
 )
@@ -0,0 +1,254 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AddressPreference orders the IP addresses a peer's host name resolves to
+// when the node dials it
+type AddressPreference string
+
+const (
+	// PreferSystem dials addresses in the resolver's order, racing the
+	// families as the Go dialer does
+	PreferSystem AddressPreference = "system"
+	// PreferIPv4 dials IPv4 addresses first, then IPv6 ones
+	PreferIPv4 AddressPreference = "ipv4"
+	// PreferIPv6 dials IPv6 addresses first, then IPv4 ones
+	PreferIPv6 AddressPreference = "ipv6"
+)
+
+// ParseAddressPreference returns the preference with the given name:
+// system, ipv4 or ipv6
+func ParseAddressPreference(name string) (AddressPreference, error) {
+	switch AddressPreference(name) {
+	case "", PreferSystem:
+		return PreferSystem, nil
+	case PreferIPv4, PreferIPv6:
+		return AddressPreference(name), nil
+	default:
+		return "", fmt.Errorf("unknown address preference %q", name)
+	}
+}
+
+// SetAddressPreference sets the order in which the node dials the addresses
+// of a peer whose host name resolves to both IPv4 and IPv6 addresses
+func (n *Node) SetAddressPreference(preference AddressPreference) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.addressPreference = preference
+}
+
+// NormalizeAddress checks that address is a host and port, and returns it
+// in the form nodes compare and hash: IPv6 literals in brackets and IPs in
+// their canonical form, so "[0:0::1]:5000" becomes "[::1]:5000". Relayed
+// addresses are normalized by their relay's address, and Unix domain socket
+// addresses by their cleaned path (see unix.go). An IPv6 literal without
+// brackets is refused, since its port cannot be told apart.
+func NormalizeAddress(address string) (string, error) {
+	if relay, id, ok := parseRelayAddress(address); ok {
+		relay, err := NormalizeAddress(relay)
+		if err != nil {
+			return "", err
+		}
+		return relay + relayPath + id, nil
+	}
+	if strings.HasPrefix(address, unixScheme) {
+		return normalizeUnixAddress(address)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		if strings.Count(address, ":") > 1 && !strings.HasPrefix(address, "[") {
+			return "", fmt.Errorf("address %q: IPv6 literals need brackets, as in [::1]:5000", address)
+		}
+		return "", fmt.Errorf("address %q: %w", address, err)
+	}
+	if port == "" {
+		return "", fmt.Errorf("address %q: missing port", address)
+	}
+	// Keep the zone of link-local addresses, as in [fe80::1%eth0]
+	literal, zone, zoned := strings.Cut(host, "%")
+	if ip := net.ParseIP(literal); ip != nil {
+		host = ip.String()
+		if zoned {
+			host += "%" + zone
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// orderAddresses sorts the IPs of a host by preference, keeping the
+// resolver's order within each family
+func orderAddresses(ips []net.IP, preference AddressPreference) []net.IP {
+	ordered := append([]net.IP(nil), ips...)
+	if preference != PreferIPv4 && preference != PreferIPv6 {
+		return ordered
+	}
+	first := func(ip net.IP) bool {
+		return (ip.To4() != nil) == (preference == PreferIPv4)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return first(ordered[i]) && !first(ordered[j])
+	})
+	return ordered
+}
+
+// resolveAddress returns the IP addresses to dial a peer at, in the node's
+// preferred order. An address with an IP literal, or any address when the
+// node has no preference, is returned as is.
+func (n *Node) resolveAddress(ctx context.Context, address string) ([]string, error) {
+	n.mu.RLock()
+	preference := n.addressPreference
+	n.mu.RUnlock()
+
+	host, port, err := net.SplitHostPort(address)
+	literal, _, _ := strings.Cut(host, "%")
+	if err != nil || preference == "" || preference == PreferSystem || net.ParseIP(literal) != nil {
+		return []string{address}, nil
+	}
+	resolved, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(resolved))
+	for i, addr := range resolved {
+		ips[i] = addr.IP
+	}
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range orderAddresses(ips, preference) {
+		addresses = append(addresses, net.JoinHostPort(ip.String(), port))
+	}
+	return addresses, nil
+}
+
+// dialTCP dials a peer at each of its addresses in the node's preferred
+// order until one answers
+func (n *Node) dialTCP(ctx context.Context, address string) (net.Conn, error) {
+	addresses, err := n.resolveAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addresses {
+		var conn net.Conn
+		if conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// listenAll listens on bindAddr. A host name resolving to several IPs, such
+// as localhost to 127.0.0.1 and ::1, is listened on at each of them on the
+// same port, so the node is dual-stack whichever address peers dial. An
+// empty or unspecified host is already dual-stack where the system allows
+// it. A unix:// address is listened on as a Unix domain socket.
+func listenAll(bindAddr string) (net.Listener, error) {
+	if path, ok := unixPath(bindAddr); ok {
+		return listenUnix(path)
+	}
+	host, port, err := net.SplitHostPort(bindAddr)
+	literal, _, _ := strings.Cut(host, "%")
+	if err != nil || host == "" || net.ParseIP(literal) != nil {
+		return net.Listen("tcp", bindAddr)
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(context.Background(), host)
+	if err != nil || len(ips) < 2 {
+		return net.Listen("tcp", bindAddr)
+	}
+	addresses := make([]string, len(ips))
+	for i, ip := range ips {
+		addresses[i] = net.JoinHostPort(ip.String(), port)
+	}
+	return listenEach(addresses)
+}
+
+// listenEach listens on every address, on the port of the first if the
+// others ask for any port, and merges the listeners into one
+func listenEach(addresses []string) (net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+	for i, addr := range addresses {
+		if i > 0 {
+			if host, port, err := net.SplitHostPort(addr); err == nil && port == "0" {
+				addr = net.JoinHostPort(host, fmt.Sprint(listeners[0].Addr().(*net.TCPAddr).Port))
+			}
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if len(listeners) == 1 {
+		return listeners[0], nil
+	}
+
+	m := &multiListener{listeners: listeners, conns: make(chan net.Conn), done: make(chan struct{})}
+	for _, l := range listeners {
+		go m.accept(l)
+	}
+	return m, nil
+}
+
+// multiListener accepts the connections of several listeners, such as one
+// per address family
+type multiListener struct {
+	listeners []net.Listener
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// accept hands l's connections to Accept until the listener closes
+func (m *multiListener) accept(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			m.Close()
+			return
+		}
+		select {
+		case m.conns <- conn:
+		case <-m.done:
+			conn.Close()
+			return
+		}
+	}
+}
+
+// Accept returns the next connection on any of the listeners
+func (m *multiListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-m.conns:
+		return conn, nil
+	case <-m.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes every listener
+func (m *multiListener) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+		for _, l := range m.listeners {
+			l.Close()
+		}
+	})
+	return nil
+}
+
+// Addr returns the address of the first listener
+func (m *multiListener) Addr() net.Addr {
+	return m.listeners[0].Addr()
+}
@@ -0,0 +1,136 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestIPv6Ring(t *testing.T) {
+	nodes := make([]*Node, 3)
+	for i := range nodes {
+		addr := fmt.Sprintf("[::1]:%d", 8530+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d on IPv6: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			// An equivalent form of the literal is normalized
+			bootstrap = "[0:0::1]:8530"
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d over IPv6: %v", i, err)
+		}
+	}
+	for round := 0; round < len(nodes)+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	checkRing(t, nodes)
+
+	if err := nodes[1].Join("::1:8530"); err == nil {
+		t.Error("Expected an IPv6 literal without brackets to be refused")
+	}
+}
+
+func TestDualStackListen(t *testing.T) {
+	// A wildcard host is served over both families
+	node := NewNodeWithAdvertise(":8533", "[::1]:8533", nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:8533", "[::1]:8533"} {
+		checkPing(t, addr)
+	}
+
+	// So is each address of a host name resolving to several
+	listener, err := listenEach([]string{"127.0.0.1:0", "[::1]:0"})
+	if err != nil {
+		t.Fatalf("Failed to listen on both families: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+	for _, host := range []string{"127.0.0.1", "::1"} {
+		conn, err := net.Dial("tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+		if err != nil {
+			t.Fatalf("Failed to dial %s: %v", host, err)
+		}
+		accepted, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("Failed to accept from %s: %v", host, err)
+		}
+		accepted.Close()
+		conn.Close()
+	}
+}
+
+// checkPing pings the node at addr over a fresh connection
+func checkPing(t *testing.T, addr string) {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client for %s: %v", addr, err)
+	}
+	defer conn.Close()
+	if _, err := pb.NewChordServiceClient(conn).Ping(context.Background(), &pb.PingRequest{}); err != nil {
+		t.Errorf("Ping of %s failed: %v", addr, err)
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	for address, want := range map[string]string{
+		"localhost:5000":                    "localhost:5000",
+		"10.0.0.1:5000":                     "10.0.0.1:5000",
+		"[::1]:5000":                        "[::1]:5000",
+		"[0:0:0::1]:5000":                   "[::1]:5000",
+		"[2001:DB8::1]:5000":                "[2001:db8::1]:5000",
+		"[fe80::1%eth0]:5000":               "[fe80::1%eth0]:5000",
+		":5000":                             ":5000",
+		"[::1]:5000/relay/ab":               "[::1]:5000/relay/ab",
+		"unix:///tmp//c.sock":               "unix:///tmp/c.sock",
+		"unix:///tmp/relay/c.sock/relay/ab": "unix:///tmp/relay/c.sock/relay/ab",
+	} {
+		got, err := NormalizeAddress(address)
+		if err != nil || got != want {
+			t.Errorf("NormalizeAddress(%q) = %q, %v; want %q", address, got, err, want)
+		}
+	}
+	for _, address := range []string{"::1:5000", "localhost", "[::1]", "10.0.0.1:", "unix://chord.sock"} {
+		if _, err := NormalizeAddress(address); err == nil {
+			t.Errorf("Expected %q to be refused", address)
+		}
+	}
+}
+
+func TestOrderAddresses(t *testing.T) {
+	ips := []net.IP{net.ParseIP("::1"), net.ParseIP("127.0.0.1"), net.ParseIP("fd00::2"), net.ParseIP("10.0.0.1")}
+	for preference, want := range map[AddressPreference][]string{
+		PreferSystem: {"::1", "127.0.0.1", "fd00::2", "10.0.0.1"},
+		PreferIPv4:   {"127.0.0.1", "10.0.0.1", "::1", "fd00::2"},
+		PreferIPv6:   {"::1", "fd00::2", "127.0.0.1", "10.0.0.1"},
+	} {
+		got := orderAddresses(ips, preference)
+		for i := range want {
+			if got[i].String() != want[i] {
+				t.Errorf("orderAddresses(%s) = %v; want %v", preference, got, want)
+				break
+			}
+		}
+	}
+}
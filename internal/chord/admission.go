@@ -0,0 +1,375 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// joinQueueTimeout bounds how long a join is held in the admission
+	// queue; it must leave the joining node time within its RPC timeout
+	joinQueueTimeout = RPCTimeout / 2
+	// joinSlotTimeout bounds how long a bootstrap serializing joins holds
+	// later joins for a joining node that does not report it settled
+	joinSlotTimeout = RPCTimeout / 2
+	// joinConflictRetries bounds how often a joining node moves in front of
+	// nodes that joined between it and its successor meanwhile
+	joinConflictRetries = 8
+)
+
+// JoinLimit configures how fast a bootstrap node admits joining nodes. Joins
+// beyond the rate wait in a bounded queue; once it is full they are rejected
+// with ErrJoinThrottled and a delay after which to retry.
+type JoinLimit struct {
+	// Rate is the number of joins admitted per second. Zero disables the
+	// limit.
+	Rate float64
+	// Burst is the number of joins admitted back to back (at least 1)
+	Burst int
+	// MaxQueue is the number of joins held waiting for admission
+	MaxQueue int
+}
+
+// JoinStats counts the joins handled by a bootstrap node
+type JoinStats struct {
+	Admitted int64
+	// Queued is the number of admitted joins that had to wait
+	Queued   int64
+	Rejected int64
+	// Serialized is the number of joins that waited for an earlier one to
+	// settle
+	Serialized int64
+	// Conflicts is the number of times this node's own join found a node
+	// that joined between it and its successor meanwhile
+	Conflicts int64
+}
+
+// joinLimiter is a token bucket admitting joins, with reservations for the
+// joins waiting in its queue, and the slot serializing joins
+type joinLimiter struct {
+	mu     sync.Mutex
+	limit  JoinLimit
+	tokens float64
+	last   time.Time
+	queued int
+	slot   *joinSlot
+	stats  JoinStats
+}
+
+// joinSlot is held by the joining node a bootstrap answered last until it
+// has notified its way in front of its successor
+type joinSlot struct {
+	holder   string
+	deadline time.Time
+	// released is closed when the holder reports it settled
+	released chan struct{}
+}
+
+// SetJoinLimit sets the rate at which this node admits joining nodes when it
+// is used as a bootstrap
+func (n *Node) SetJoinLimit(limit JoinLimit) {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	if limit.MaxQueue < 0 {
+		limit.MaxQueue = 0
+	}
+
+	n.joins.mu.Lock()
+	defer n.joins.mu.Unlock()
+
+	n.joins.limit = limit
+	n.joins.tokens = float64(limit.Burst)
+	n.joins.last = time.Now()
+}
+
+// JoinStats returns counters for the joins this node admitted or rejected
+func (n *Node) JoinStats() JoinStats {
+	n.joins.mu.Lock()
+	defer n.joins.mu.Unlock()
+
+	return n.joins.stats
+}
+
+// admit returns once a join may proceed, or an error carrying a retry delay
+// if the queue is full
+func (l *joinLimiter) admit(ctx context.Context) error {
+	l.mu.Lock()
+	if l.limit.Rate <= 0 {
+		l.stats.Admitted++
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.Rate
+	if l.tokens > float64(l.limit.Burst) {
+		l.tokens = float64(l.limit.Burst)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		l.stats.Admitted++
+		l.mu.Unlock()
+		return nil
+	}
+
+	// Reserve the next token; the wait is how long until it is refilled
+	wait := time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+	if l.queued >= l.limit.MaxQueue || wait > joinQueueTimeout {
+		l.stats.Rejected++
+		l.mu.Unlock()
+		return &retryableError{
+			err:   fmt.Errorf("%w: %d joins queued", ErrJoinThrottled, l.limit.MaxQueue),
+			after: wait,
+		}
+	}
+	l.tokens--
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		l.mu.Lock()
+		l.queued--
+		l.stats.Admitted++
+		l.stats.Queued++
+		l.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		// Give the reservation back to the joins behind us
+		l.mu.Lock()
+		l.queued--
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// serialize returns once no other join is settling and takes the slot for
+// requester, which holds later joins until it reports it settled or
+// joinSlotTimeout passes. A join kept waiting past wait is rejected with a
+// delay after which to retry.
+func (l *joinLimiter) serialize(ctx context.Context, requester string, wait time.Duration) error {
+	giveUp := time.NewTimer(wait)
+	defer giveUp.Stop()
+
+	waited := false
+	for {
+		l.mu.Lock()
+		slot := l.slot
+		now := time.Now()
+		if slot == nil || slot.holder == requester || now.After(slot.deadline) {
+			if slot == nil || slot.holder != requester {
+				slot = &joinSlot{holder: requester, released: make(chan struct{})}
+				l.slot = slot
+			}
+			slot.deadline = now.Add(joinSlotTimeout)
+			if waited {
+				l.stats.Serialized++
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		expired := time.NewTimer(slot.deadline.Sub(now))
+		select {
+		case <-slot.released:
+		case <-expired.C:
+		case <-giveUp.C:
+			expired.Stop()
+			l.mu.Lock()
+			l.stats.Rejected++
+			l.mu.Unlock()
+			return &retryableError{
+				err:   fmt.Errorf("%w: another join is settling", ErrJoinThrottled),
+				after: time.Until(slot.deadline),
+			}
+		case <-ctx.Done():
+			expired.Stop()
+			return ctx.Err()
+		}
+		expired.Stop()
+		waited = true
+	}
+}
+
+// settled releases the slot held by the joining node at address
+func (l *joinLimiter) settled(address string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.slot != nil && l.slot.holder == address {
+		close(l.slot.released)
+		l.slot = nil
+	}
+}
+
+// JoinSettled releases the join slot held at this bootstrap by a node that
+// has notified its way into the ring
+func (n *Node) JoinSettled(ctx context.Context, req *pb.JoinSettledRequest) (*pb.JoinSettledResponse, error) {
+	n.countMessage()
+
+	node, err := fromProtoNode(req.Node)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid node")
+	}
+	n.joins.settled(node.Address)
+	return &pb.JoinSettledResponse{}, nil
+}
+
+// remoteJoinSettled tells the bootstrap at address that this node's join
+// has settled
+func (n *Node) remoteJoinSettled(address string) error {
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	_, err = client.JoinSettled(ctx, &pb.JoinSettledRequest{Node: toProtoNode(n.GetNodeInfo())})
+	return fromStatus(address, err)
+}
+
+// findJoinSuccessor asks the bootstrap node for this node's successor,
+// waiting and retrying as long as the bootstrap throttles joins
+func (n *Node) findJoinSuccessor(bootstrapAddr string) (*pb.FindSuccessorResponse, error) {
+	client, err := n.getClient(bootstrapAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to bootstrap node: %w", err)
+	}
+
+	req := &pb.FindSuccessorRequest{
+		Key:       n.id.String(),
+		Requester: toProtoNode(n.GetNodeInfo()),
+		Join:      true,
+		Serialize: true,
+	}
+	var resp *pb.FindSuccessorResponse
+	err = n.RetryPolicies().Join.DoNotify(n.ctx, func(int) error {
+		ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+		defer cancel()
+
+		resp, err = client.FindSuccessor(ctx, req)
+		return fromStatus(bootstrapAddr, err)
+	}, func(err error, delay time.Duration) {
+		log.Printf("Node %s: bootstrap %s is throttling joins, retrying in %v",
+			n.id.Short(), bootstrapAddr, delay)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find successor: %w", err)
+	}
+	return resp, nil
+}
+
+// settleJoin notifies the successor of a joining node and takes over the
+// node's range from it. A bootstrap hands out one successor at a time and
+// holds later joins until the joining node has notified its way into the
+// ring, and a node takes over the predecessor its successor had, so every
+// node that joined before is on the chain of predecessors from the
+// successor: a node whose notification shows a closer predecessor moves in
+// front of that node. Its range may still be moving to the
+// successor; the hand-off is retried under the Settle policy meanwhile.
+// Concurrent joins so converge without waiting for stabilization.
+func (n *Node) settleJoin(bootstrap string, successor *NodeInfo) {
+	n.notifyJoinSuccessor(successor)
+	if bootstrap != "" {
+		if err := n.remoteJoinSettled(bootstrap); err != nil {
+			log.Printf("Node %s: failed to report join settled to %s: %v", n.id.Short(), bootstrap, err)
+		}
+	}
+
+	err := n.RetryPolicies().Settle.Do(n.ctx, func(attempt int) error {
+		if attempt > 1 {
+			// Nodes may have joined without going through our bootstrap
+			n.notifyJoinSuccessor(n.GetSuccessor())
+		}
+		return n.pullHandoff()
+	})
+	if err != nil {
+		// Stabilization retries
+		log.Printf("Node %s: hand-off after join not completed, will retry: %v", n.id.Short(), err)
+	}
+}
+
+// notifyJoinSuccessor notifies successor, moving in front of the nodes
+// found to have joined between this node and it
+func (n *Node) notifyJoinSuccessor(successor *NodeInfo) {
+	for conflicts := 0; successor != nil && successor.Address != n.address; conflicts++ {
+		predecessor, replaced, err := n.remoteNotify(successor.Address)
+		if err != nil {
+			log.Printf("Node %s: failed to notify successor after join: %v", n.id.Short(), err)
+			return
+		}
+		if replaced != nil {
+			n.inheritPredecessor(replaced)
+		}
+		closer := n.joinedBetween(successor, predecessor)
+		if closer == nil || conflicts == joinConflictRetries {
+			return
+		}
+		successor = n.moveInFront(successor, closer)
+	}
+}
+
+// inheritPredecessor makes the predecessor the successor had before this
+// node notified it this node's own, so that nodes joining behind it later
+// find it on the chain of predecessors
+func (n *Node) inheritPredecessor(predecessor *NodeInfo) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if predecessor.Address == n.address ||
+		n.predecessor != nil && !predecessor.ID.InRangeExclusive(n.predecessor.ID, n.id) {
+		return
+	}
+	previous := n.predecessor
+	n.predecessor = predecessor
+	n.recordMembership(MemberJoined, RolePredecessor, predecessor, previous)
+}
+
+// joinedBetween returns the successor's predecessor if it lies between this
+// node and the successor
+func (n *Node) joinedBetween(successor, predecessor *NodeInfo) *NodeInfo {
+	if predecessor == nil || predecessor.Address == n.address ||
+		!predecessor.ID.InRangeExclusive(n.id, successor.ID) {
+		return nil
+	}
+	return predecessor
+}
+
+// moveInFront makes closer the successor of this node in place of
+// successor, unless stabilization changed it meanwhile, and returns the
+// successor
+func (n *Node) moveInFront(successor, closer *NodeInfo) *NodeInfo {
+	n.mu.Lock()
+	if n.successor != nil && n.successor.Address == successor.Address {
+		n.successor = closer
+		n.successorList = []*NodeInfo{closer}
+		n.recordMembership(MemberJoined, RoleSuccessor, closer, successor)
+	}
+	current := n.successor
+	n.mu.Unlock()
+
+	n.joins.mu.Lock()
+	n.joins.stats.Conflicts++
+	n.joins.mu.Unlock()
+	log.Printf("Node %s: %s joined in front of successor %s meanwhile, moving in front of it",
+		n.id.Short(), closer.ID.Short(), successor.ID.Short())
+	return current
+}
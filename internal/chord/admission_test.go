@@ -0,0 +1,136 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestJoinLimiter(t *testing.T) {
+	node := NewNode("localhost:0", nil)
+	node.SetJoinLimit(JoinLimit{Rate: 10, Burst: 1, MaxQueue: 1})
+
+	ctx := context.Background()
+	if err := node.joins.admit(ctx); err != nil {
+		t.Fatalf("Expected the first join within the burst, got %v", err)
+	}
+
+	// The second join waits for the next token, the third finds the queue full
+	queued := make(chan error, 1)
+	start := time.Now()
+	go func() { queued <- node.joins.admit(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+
+	err := node.joins.admit(ctx)
+	if !errors.Is(err, ErrJoinThrottled) {
+		t.Fatalf("Expected ErrJoinThrottled with a full queue, got %v", err)
+	}
+	if delay, ok := RetryDelay(err); !ok || delay <= 0 {
+		t.Errorf("Expected a retry delay, got %v (%v)", delay, ok)
+	}
+
+	if err := <-queued; err != nil {
+		t.Fatalf("Queued join failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Queued join admitted after %v, expected to wait for a token", elapsed)
+	}
+
+	stats := node.JoinStats()
+	if stats.Admitted != 2 || stats.Queued != 1 || stats.Rejected != 1 {
+		t.Errorf("Unexpected join stats: %+v", stats)
+	}
+}
+
+func TestJoinThrottling(t *testing.T) {
+	bootstrap := NewNode("localhost:8400", hash.NewHashFromString("localhost:8400"))
+	bootstrap.SetJoinLimit(JoinLimit{Rate: 20, Burst: 1, MaxQueue: 1})
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start bootstrap: %v", err)
+	}
+	t.Cleanup(bootstrap.Stop)
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	// Joining all at once overflows the queue; rejected nodes retry later
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 1; i <= 4; i++ {
+		addr := fmt.Sprintf("localhost:%d", 8400+i)
+		node := NewNode(addr, hash.NewHashFromString(addr))
+		if err := node.Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(node.Stop)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- node.Join(bootstrap.GetAddress())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Join failed: %v", err)
+		}
+	}
+	stats := bootstrap.JoinStats()
+	if stats.Admitted != 4 {
+		t.Errorf("Expected 4 admitted joins, got %+v", stats)
+	}
+	if stats.Rejected == 0 {
+		t.Errorf("Expected some joins to be throttled, got %+v", stats)
+	}
+}
+
+func TestConcurrentJoins(t *testing.T) {
+	nodes := make([]*Node, 7)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", 8653+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+	}
+	if err := nodes[0].Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	// Every node joins through the bootstrap at once, without pauses
+	var wg sync.WaitGroup
+	for _, node := range nodes[1:] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := node.Join(nodes[0].GetAddress()); err != nil {
+				t.Errorf("Join failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The joins alone leave every node owning the range up to it from the
+	// node before it
+	sorted := append([]*Node(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetID().Less(sorted[j].GetID()) })
+	for i, node := range sorted {
+		previous := sorted[(i+len(sorted)-1)%len(sorted)]
+		node.own.mu.Lock()
+		start := node.own.start
+		node.own.mu.Unlock()
+		if start == nil || !start.Equal(previous.GetID()) {
+			t.Errorf("Node %s owns from %v, expected %s", node.GetID().Short(), start, previous.GetID().Short())
+		}
+	}
+}
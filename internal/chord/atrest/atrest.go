@@ -0,0 +1,208 @@
+// Package atrest encrypts the values a node stores, so its storage backend
+// and its snapshots hold only ciphertext. Every value is sealed with
+// envelope encryption under its key: a random data key per value, wrapped
+// with a key from a node-local keyring, which can differ per bucket. Keys
+// and versions are stored in the clear, and values travel between nodes
+// decrypted, each node sealing them with its own keys.
+package atrest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/envelope"
+)
+
+// Keys are the keyrings values are sealed with
+type Keys struct {
+	// Default seals the values of keys outside any bucket, and of buckets
+	// without a keyring of their own
+	Default *envelope.Keyring
+	// Buckets holds the keyrings of buckets with their own keys (see
+	// chord.SplitBucketKey)
+	Buckets map[string]*envelope.Keyring
+}
+
+// keyring returns the keyring that seals the value of key
+func (k *Keys) keyring(key string) (*envelope.Keyring, error) {
+	if bucket, _, ok := chord.SplitBucketKey(key); ok {
+		if keys, ok := k.Buckets[bucket]; ok {
+			return keys, nil
+		}
+	}
+	if k.Default == nil {
+		return nil, fmt.Errorf("%w: no key for %q", envelope.ErrNoPrimaryKey, key)
+	}
+	return k.Default, nil
+}
+
+// LoadKeys reads a key file. Every line holds a key ID, a base64-encoded
+// envelope.KeySize-byte key and optionally the bucket it is for; keys
+// without a bucket form the default keyring. The first key of a keyring
+// seals new values and the others only open old ones, so a key is rotated
+// by listing a new one first. Blank lines and lines starting with # are
+// ignored.
+func LoadKeys(path string) (*Keys, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys := &Keys{Buckets: make(map[string]*envelope.Keyring)}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: expected ID KEY [BUCKET]", path, line)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+
+		bucket, keyring := "", keys.Default
+		if len(fields) == 3 {
+			if err := chord.ValidBucket(fields[2]); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			bucket, keyring = fields[2], keys.Buckets[fields[2]]
+		}
+		if keyring == nil {
+			keyring = envelope.NewKeyring()
+			if bucket == "" {
+				keys.Default = keyring
+			} else {
+				keys.Buckets[bucket] = keyring
+			}
+		}
+		if err := keyring.Add(fields[0], key); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if keys.Default == nil {
+		return nil, fmt.Errorf("%s: no key without a bucket", path)
+	}
+	return keys, nil
+}
+
+// Storage is a chord.SealedStorage that keeps the values of another store
+// encrypted. Wrap it around any other wrapper, such as a simulated disk, so
+// that snapshots see it.
+type Storage struct {
+	inner chord.Storage
+	keys  *Keys
+}
+
+// Wrap encrypts the values stored in inner with keys. Values already in
+// inner must have been stored through a Storage.
+func Wrap(inner chord.Storage, keys *Keys) (*Storage, error) {
+	if keys == nil || keys.Default == nil || keys.Default.Primary() == "" {
+		return nil, fmt.Errorf("%w: no default key", envelope.ErrNoPrimaryKey)
+	}
+	return &Storage{inner: inner, keys: keys}, nil
+}
+
+// Inner returns the store holding the encrypted values
+func (s *Storage) Inner() chord.Storage {
+	return s.inner
+}
+
+// seal encrypts the value of an entry. Deleted entries keep no value.
+func (s *Storage) seal(key string, e chord.Entry) (chord.Entry, error) {
+	if e.Value == nil {
+		return e, nil
+	}
+	keys, err := s.keys.keyring(key)
+	if err != nil {
+		return chord.Entry{}, err
+	}
+	if e.Value, err = envelope.Seal(keys, key, e.Value); err != nil {
+		return chord.Entry{}, fmt.Errorf("failed to encrypt %q: %w", key, err)
+	}
+	return e, nil
+}
+
+// open decrypts the value of an entry
+func (s *Storage) open(key string, e chord.Entry) (chord.Entry, error) {
+	if e.Value == nil {
+		return e, nil
+	}
+	keys, err := s.keys.keyring(key)
+	if err != nil {
+		return chord.Entry{}, err
+	}
+	value, err := envelope.Open(keys, key, e.Value)
+	if err != nil {
+		return chord.Entry{}, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	e.Value = value
+	return e, nil
+}
+
+// Get implements chord.Storage
+func (s *Storage) Get(key string) (chord.Entry, bool, error) {
+	e, ok, err := s.inner.Get(key)
+	if err != nil || !ok {
+		return e, ok, err
+	}
+	e, err = s.open(key, e)
+	return e, err == nil, err
+}
+
+// Put implements chord.Storage
+func (s *Storage) Put(key string, e chord.Entry) error {
+	e, err := s.seal(key, e)
+	if err != nil {
+		return err
+	}
+	return s.inner.Put(key, e)
+}
+
+// Delete implements chord.Storage
+func (s *Storage) Delete(key string) error {
+	return s.inner.Delete(key)
+}
+
+// Range implements chord.Storage. It stops at the first value that does
+// not decrypt.
+func (s *Storage) Range(fn func(key string, e chord.Entry) bool) error {
+	var openErr error
+	err := s.inner.Range(func(key string, e chord.Entry) bool {
+		if e, openErr = s.open(key, e); openErr != nil {
+			return false
+		}
+		return fn(key, e)
+	})
+	if err != nil {
+		return err
+	}
+	return openErr
+}
+
+// RangeSealed implements chord.SealedStorage
+func (s *Storage) RangeSealed(fn func(key string, e chord.Entry) bool) error {
+	return s.inner.Range(fn)
+}
+
+// PutSealed implements chord.SealedStorage. The value must open with the
+// store's keys.
+func (s *Storage) PutSealed(key string, e chord.Entry) error {
+	if _, err := s.open(key, e); err != nil {
+		return err
+	}
+	return s.inner.Put(key, e)
+}
@@ -0,0 +1,108 @@
+package atrest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/chord/envelope"
+	"chord-dht/pkg/hash"
+)
+
+// writeKeys writes a key file with a default key and a key for photos
+func writeKeys(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "node.keys")
+	data := fmt.Sprintf("# id key [bucket]\nnode-1 %s\n\nphotos-1 %s photos\n",
+		base64.StdEncoding.EncodeToString(envelope.NewKey()), base64.StdEncoding.EncodeToString(envelope.NewKey()))
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	return path
+}
+
+func TestSealedValues(t *testing.T) {
+	keys, err := LoadKeys(writeKeys(t))
+	if err != nil {
+		t.Fatalf("LoadKeys failed: %v", err)
+	}
+	inner := chord.NewMemoryStorage()
+	store, err := Wrap(inner, keys)
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+
+	for key, keyID := range map[string]string{"photos/a": "photos-1", "docs/a": "node-1", "plain": "node-1"} {
+		if err := store.Put(key, chord.Entry{Value: []byte("secret"), Version: 1}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		raw, _, _ := inner.Get(key)
+		if bytes.Contains(raw.Value, []byte("secret")) {
+			t.Errorf("%s: inner store holds the plaintext", key)
+		}
+		if id, err := envelope.KeyID(raw.Value); err != nil || id != keyID {
+			t.Errorf("%s: sealed with %q (%v), expected %q", key, id, err, keyID)
+		}
+		e, ok, err := store.Get(key)
+		if err != nil || !ok || string(e.Value) != "secret" || e.Version != 1 {
+			t.Errorf("%s: Get returned %+v, %v, %v", key, e, ok, err)
+		}
+	}
+
+	// A value copied under another key does not open
+	raw, _, _ := inner.Get("docs/a")
+	if err := store.PutSealed("plain", raw); err == nil {
+		t.Error("Expected a value sealed under another key to be refused")
+	}
+	inner.Put("plain", raw)
+	if _, _, err := store.Get("plain"); err == nil {
+		t.Error("Expected a value sealed under another key not to open")
+	}
+}
+
+func TestSealedSnapshot(t *testing.T) {
+	keys, err := LoadKeys(writeKeys(t))
+	if err != nil {
+		t.Fatalf("LoadKeys failed: %v", err)
+	}
+	newNode := func(sealed bool) *chord.Node {
+		node := chord.NewNode("localhost:0", hash.GenerateID("node"))
+		if sealed {
+			store, err := Wrap(chord.NewMemoryStorage(), keys)
+			if err != nil {
+				t.Fatalf("Wrap failed: %v", err)
+			}
+			node.SetStorage(store)
+		}
+		return node
+	}
+
+	node := newNode(true)
+	node.Storage().Put("photos/a", chord.Entry{Value: []byte("secret"), Version: 3})
+	var snapshot bytes.Buffer
+	if err := node.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if bytes.Contains(snapshot.Bytes(), []byte(base64.StdEncoding.EncodeToString([]byte("secret")))) {
+		t.Error("Snapshot holds the plaintext")
+	}
+	if info, err := chord.ReadSnapshot(bytes.NewReader(snapshot.Bytes())); err != nil || !info.Sealed {
+		t.Errorf("Expected a sealed snapshot, got %+v, %v", info, err)
+	}
+
+	if _, err := newNode(false).RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err == nil {
+		t.Error("Expected a sealed snapshot not to restore into a plain store")
+	}
+	restored := newNode(true)
+	if _, err := restored.RestoreSnapshot(bytes.NewReader(snapshot.Bytes())); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	e, ok, err := restored.Storage().Get("photos/a")
+	if err != nil || !ok || string(e.Value) != "secret" || e.Version != 3 {
+		t.Errorf("Restored entry is %+v, %v, %v", e, ok, err)
+	}
+}
@@ -0,0 +1,230 @@
+package chord
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// ErrNoAuditLog is returned when reading the audit log of a node without one
+var ErrNoAuditLog = errors.New("no audit log")
+
+// AuditKind is the kind of an audit event
+type AuditKind string
+
+const (
+	// AuditJoin is recorded when this node enters a ring and when a node
+	// becomes its predecessor or successor
+	AuditJoin AuditKind = "join"
+	// AuditLeave is recorded when this node stops
+	AuditLeave AuditKind = "leave"
+	// AuditFailure is recorded when the predecessor or successor is found
+	// not answering
+	AuditFailure AuditKind = "failure"
+	// AuditTransfer is recorded when a key range changes owner
+	AuditTransfer AuditKind = "transfer"
+)
+
+// Directions of a key range transfer, relative to the recording node
+const (
+	TransferSent     = "sent"
+	TransferReceived = "received"
+	// TransferAdopted is a range taken over from a failed predecessor,
+	// whose keys the node already held as replicas or lost
+	TransferAdopted = "adopted"
+)
+
+// AuditPeer identifies a node in an audit event
+type AuditPeer struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// auditPeer returns the identity of node, nil for nil
+func auditPeer(node *NodeInfo) *AuditPeer {
+	if node == nil {
+		return nil
+	}
+	return &AuditPeer{ID: node.ID.String(), Address: node.Address}
+}
+
+// AuditEvent is one line of the audit log
+type AuditEvent struct {
+	Time time.Time `json:"time"`
+	Kind AuditKind `json:"kind"`
+	// Node is the node that recorded the event
+	Node AuditPeer `json:"node"`
+	// Role is what Peer is to Node in joins and failures (see RoleSelf)
+	Role string `json:"role,omitempty"`
+	// Peer is the node that joined, failed or took part in a transfer
+	Peer *AuditPeer `json:"peer,omitempty"`
+	// Previous is the node Peer replaced in its role, if any
+	Previous *AuditPeer `json:"previous,omitempty"`
+	// Direction, Start, End, Keys and TransferID describe a transfer of the
+	// range (Start, End]
+	Direction  string `json:"direction,omitempty"`
+	Start      string `json:"start,omitempty"`
+	End        string `json:"end,omitempty"`
+	Keys       int    `json:"keys,omitempty"`
+	TransferID string `json:"transfer_id,omitempty"`
+}
+
+// AuditLog appends audit events to a file as JSON lines. Lines are only
+// ever added, so the file survives restarts and can be shipped or
+// inspected with standard tools.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if
+// needed
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{path: path, file: file}, nil
+}
+
+// Path returns the file the log is written to
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Record appends event to the log
+func (l *AuditLog) Record(event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+	_, err = l.file.Write(append(line, '\n'))
+	return err
+}
+
+// Events reads the events of the log recorded at or after since, oldest
+// first
+func (l *AuditLog) Events(since time.Time) ([]AuditEvent, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadAuditLog(file, since)
+}
+
+// Close closes the log. Events recorded afterwards are dropped.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// ReadAuditLog reads the events of an audit log recorded at or after
+// since, oldest first. Lines left partly written by a crash are skipped.
+func ReadAuditLog(r io.Reader, since time.Time) ([]AuditEvent, error) {
+	var events []AuditEvent
+	reader := bufio.NewReader(r)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var event AuditEvent
+		if json.Unmarshal(data, &event) != nil {
+			continue
+		}
+		if !event.Time.Before(since) {
+			events = append(events, event)
+		}
+	}
+}
+
+// SetAuditLog records the node's membership events, key range transfers
+// and its own stop to l, nil to stop recording. The caller closes l after
+// the node stops.
+func (n *Node) SetAuditLog(l *AuditLog) {
+	n.audit.Store(l)
+}
+
+// AuditLog returns the log set with SetAuditLog, nil if none
+func (n *Node) AuditLog() *AuditLog {
+	return n.audit.Load()
+}
+
+// AuditEvents returns the events of the node's audit log recorded at or
+// after since, or ErrNoAuditLog
+func (n *Node) AuditEvents(since time.Time) ([]AuditEvent, error) {
+	l := n.audit.Load()
+	if l == nil {
+		return nil, ErrNoAuditLog
+	}
+	return l.Events(since)
+}
+
+// recordAudit fills in the time and recording node of event, streams it to
+// event subscribers (see events.go) and appends it to the audit log, if
+// any. Failures are logged, never returned: auditing does not stop the ring
+// from changing.
+func (n *Node) recordAudit(event AuditEvent) {
+	event.Time = time.Now().UTC()
+	event.Node = AuditPeer{ID: n.id.String(), Address: n.address}
+	n.publishChange(event)
+	l := n.audit.Load()
+	if l == nil {
+		return
+	}
+	if err := l.Record(event); err != nil {
+		log.Printf("Node %s: failed to write audit log: %v", n.id.Short(), err)
+	}
+}
+
+// auditMembership records a membership event of the history (see
+// history.go) in the audit log
+func (n *Node) auditMembership(kind MembershipChange, role string, node, previous *NodeInfo) {
+	event := AuditEvent{Kind: AuditJoin, Role: role, Peer: auditPeer(node), Previous: auditPeer(previous)}
+	if kind == MemberLeft {
+		event.Kind = AuditFailure
+	}
+	n.recordAudit(event)
+}
+
+// auditTransfer records a transfer of the range (start, end] with peer in
+// the audit log
+func (n *Node) auditTransfer(direction string, peer *NodeInfo, start, end *hash.Hash, keys int, id string) {
+	n.recordAudit(AuditEvent{
+		Kind:       AuditTransfer,
+		Peer:       auditPeer(peer),
+		Direction:  direction,
+		Start:      start.String(),
+		End:        end.String(),
+		Keys:       keys,
+		TransferID: id,
+	})
+}
@@ -0,0 +1,107 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestAuditLog(t *testing.T) {
+	start := time.Now().Add(-time.Second)
+	nodes := make([]*Node, 2)
+	logs := make([]*AuditLog, 2)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", 8598+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		var err error
+		if logs[i], err = OpenAuditLog(filepath.Join(t.TempDir(), "audit.jsonl")); err != nil {
+			t.Fatalf("OpenAuditLog failed: %v", err)
+		}
+		t.Cleanup(func() { logs[i].Close() })
+		nodes[i].SetAuditLog(logs[i])
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+	}
+	first, second := nodes[0], nodes[1]
+	t.Cleanup(first.Stop)
+
+	if err := first.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := first.StoreValue(context.Background(), fmt.Sprintf("key-%d", i), []byte("value")); err != nil {
+			t.Fatalf("StoreValue failed: %v", err)
+		}
+	}
+	if err := second.Join(first.GetAddress()); err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	for round := 0; round < 3; round++ {
+		first.stabilize()
+		second.stabilize()
+	}
+	second.Stop()
+	first.stabilize()
+	first.checkPredecessor()
+
+	// Both sides record the transfer of the range the second node took over
+	sent := findAudit(t, first, AuditTransfer, TransferSent)
+	received := findAudit(t, second, AuditTransfer, TransferReceived)
+	if sent.TransferID == "" || sent.TransferID != received.TransferID || sent.Keys != received.Keys {
+		t.Errorf("Expected matching transfers, got %+v and %+v", sent, received)
+	}
+	if sent.Peer == nil || sent.Peer.Address != second.GetAddress() || sent.End != second.GetID().String() {
+		t.Errorf("Expected the range up to %s sent to it, got %+v", second.GetAddress(), sent)
+	}
+
+	if joined := findAudit(t, second, AuditJoin, RoleSelf); joined.Node.Address != second.GetAddress() || joined.Time.Before(start) {
+		t.Errorf("Expected the second node's own join, got %+v", joined)
+	}
+	findAudit(t, second, AuditLeave, RoleSelf)
+	if failed := findAudit(t, first, AuditFailure, RoleSuccessor); failed.Peer.Address != second.GetAddress() {
+		t.Errorf("Expected the successor %s to fail, got %+v", second.GetAddress(), failed)
+	}
+
+	// Events before since are left out
+	events, err := first.AuditEvents(time.Now().Add(time.Hour))
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected no future events, got %+v, %v", events, err)
+	}
+}
+
+func TestReadAuditLogSkipsPartialLines(t *testing.T) {
+	data := `{"time":"2024-01-01T00:00:00Z","kind":"join","node":{"id":"01","address":"a:1"}}
+{"time":"2024-01-01T00:00:01Z","kind":"fail
+{"time":"2024-01-01T00:00:02Z","kind":"leave","node":{"id":"01","address":"a:1"}}
+{"time":"2024-01-01T00:00:03Z","ki`
+	events, err := ReadAuditLog(strings.NewReader(data), time.Time{})
+	if err != nil {
+		t.Fatalf("ReadAuditLog failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Kind != AuditJoin || events[1].Kind != AuditLeave {
+		t.Errorf("Expected the join and leave, got %+v", events)
+	}
+}
+
+// findAudit returns the first event of node's audit log of kind with the
+// given role or transfer direction
+func findAudit(t *testing.T, node *Node, kind AuditKind, detail string) AuditEvent {
+	t.Helper()
+	events, err := node.AuditEvents(time.Time{})
+	if err != nil {
+		t.Fatalf("AuditEvents failed: %v", err)
+	}
+	for _, event := range events {
+		if event.Kind == kind && (event.Role == detail || event.Direction == detail) {
+			return event
+		}
+	}
+	t.Fatalf("No %s %s event in %+v", kind, detail, events)
+	return AuditEvent{}
+}
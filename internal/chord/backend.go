@@ -0,0 +1,146 @@
+package chord
+
+import (
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// Entry is a value held in a node's local store
+type Entry struct {
+	Value     []byte
+	Version   uint64    // Incremented on every write to the key
+	ExpiresAt time.Time // Zero if the entry never expires
+	DeletedAt time.Time // Zero unless the entry is a tombstone
+}
+
+// Live reports whether the entry holds a value at the given time
+func (e Entry) Live(now time.Time) bool {
+	return !e.Tombstone() && (e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt))
+}
+
+// Tombstone reports whether the entry marks a deleted key (see
+// tombstones.go)
+func (e Entry) Tombstone() bool {
+	return !e.DeletedAt.IsZero()
+}
+
+// toProtoEntry converts an entry for transfer to another node
+func toProtoEntry(key string, e Entry) *pb.StoredEntry {
+	entry := &pb.StoredEntry{Key: key, Value: e.Value, Version: e.Version}
+	if !e.ExpiresAt.IsZero() {
+		entry.ExpiresAtMs = e.ExpiresAt.UnixMilli()
+	}
+	if e.Tombstone() {
+		entry.DeletedAtMs = e.DeletedAt.UnixMilli()
+	}
+	return entry
+}
+
+// fromProtoEntry converts an entry received from another node
+func fromProtoEntry(entry *pb.StoredEntry) Entry {
+	e := Entry{Value: entry.Value, Version: entry.Version}
+	if entry.ExpiresAtMs != 0 {
+		e.ExpiresAt = time.UnixMilli(entry.ExpiresAtMs)
+	}
+	if entry.DeletedAtMs != 0 {
+		e.DeletedAt = time.UnixMilli(entry.DeletedAtMs)
+	}
+	return e
+}
+
+// Storage is the backend a node keeps its local entries in. The node
+// serializes its own read-modify-write sequences, so implementations only
+// need to make individual calls safe for concurrent use.
+type Storage interface {
+	// Get returns the entry for key and whether it exists
+	Get(key string) (Entry, bool, error)
+	// Put creates or replaces the entry for key
+	Put(key string, e Entry) error
+	// Delete removes the entry for key, if any
+	Delete(key string) error
+	// Range calls fn for every entry until fn returns false
+	Range(fn func(key string, e Entry) bool) error
+}
+
+// SealedStorage is a Storage that keeps values encrypted in another store
+// (see atrest). Get and Range return them decrypted, while snapshots copy
+// them as stored, so a snapshot is encrypted too and restores only into a
+// SealedStorage holding the keys.
+type SealedStorage interface {
+	Storage
+	// RangeSealed calls fn for every entry, with its value as stored,
+	// until fn returns false
+	RangeSealed(fn func(key string, e Entry) bool) error
+	// PutSealed stores an entry whose value was read with RangeSealed
+	PutSealed(key string, e Entry) error
+}
+
+// MemoryStorage is the default in-memory Storage
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewMemoryStorage creates an empty in-memory store
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: make(map[string]Entry)}
+}
+
+// Get returns the entry for key and whether it exists
+func (s *MemoryStorage) Get(key string) (Entry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[key]
+	return e, ok, nil
+}
+
+// Put creates or replaces the entry for key
+func (s *MemoryStorage) Put(key string, e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = e
+	return nil
+}
+
+// Delete removes the entry for key, if any
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Range calls fn for every entry until fn returns false
+func (s *MemoryStorage) Range(fn func(key string, e Entry) bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for key, e := range s.entries {
+		if !fn(key, e) {
+			break
+		}
+	}
+	return nil
+}
+
+// SetStorage replaces the node's local store. It must be called before the
+// node starts serving requests.
+func (n *Node) SetStorage(storage Storage) {
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	n.storage = storage
+}
+
+// Storage returns the node's local store
+func (n *Node) Storage() Storage {
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	return n.storage
+}
@@ -0,0 +1,157 @@
+package chord
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
+
+	"google.golang.org/grpc/stats"
+)
+
+// methodCategories maps RPCs to the bandwidth category they are accounted
+// to; the rest, including services added with RegisterService, are
+// metrics.CategoryOther
+var methodCategories = map[string]string{
+	pb.ChordService_FindSuccessor_FullMethodName:          metrics.CategoryLookup,
+	pb.ChordService_ClosestPrecedingFinger_FullMethodName: metrics.CategoryLookup,
+
+	pb.ChordService_Notify_FullMethodName:            metrics.CategoryMaintenance,
+	pb.ChordService_JoinSettled_FullMethodName:       metrics.CategoryMaintenance,
+	pb.ChordService_GetInfo_FullMethodName:           metrics.CategoryMaintenance,
+	pb.ChordService_Ping_FullMethodName:              metrics.CategoryMaintenance,
+	pb.ChordService_GetPeers_FullMethodName:          metrics.CategoryMaintenance,
+	pb.ChordService_GetSuccessorList_FullMethodName:  metrics.CategoryMaintenance,
+	pb.ChordService_GetRoutingTable_FullMethodName:   metrics.CategoryMaintenance,
+	pb.ChordService_GetDensity_FullMethodName:        metrics.CategoryMaintenance,
+	pb.ChordService_CheckReachability_FullMethodName: metrics.CategoryMaintenance,
+
+	pb.ChordService_Replicate_FullMethodName:    metrics.CategoryReplication,
+	pb.ChordService_CacheHotKeys_FullMethodName: metrics.CategoryReplication,
+
+	pb.ChordService_PrepareHandoff_FullMethodName: metrics.CategoryTransfer,
+	pb.ChordService_CommitHandoff_FullMethodName:  metrics.CategoryTransfer,
+	pb.ChordService_StreamHandoff_FullMethodName:  metrics.CategoryTransfer,
+	pb.ChordService_GetSnapshot_FullMethodName:    metrics.CategoryTransfer,
+
+	pb.ChordService_Put_FullMethodName:            metrics.CategoryStorage,
+	pb.ChordService_Get_FullMethodName:            metrics.CategoryStorage,
+	pb.ChordService_PutBatch_FullMethodName:       metrics.CategoryStorage,
+	pb.ChordService_GetBatch_FullMethodName:       metrics.CategoryStorage,
+	pb.ChordService_ConditionalPut_FullMethodName: metrics.CategoryStorage,
+	pb.ChordService_Undelete_FullMethodName:       metrics.CategoryStorage,
+	pb.ChordService_QueryTag_FullMethodName:       metrics.CategoryStorage,
+	pb.ChordService_UpdateCRDT_FullMethodName:     metrics.CategoryStorage,
+	pb.ChordService_ListKeys_FullMethodName:       metrics.CategoryStorage,
+}
+
+// categoryOf returns the bandwidth category of an RPC
+func categoryOf(fullMethod string) string {
+	if category, ok := methodCategories[fullMethod]; ok {
+		return category
+	}
+	return metrics.CategoryOther
+}
+
+// bandwidth counts the bytes of a node's RPCs by category
+type bandwidth struct {
+	// counters maps categories to *traffic
+	counters sync.Map
+}
+
+// traffic is the counters of one category
+type traffic struct {
+	sent, received, calls atomic.Int64
+}
+
+// Bandwidth returns the bytes of the RPC messages the node sent and
+// received, incoming and outgoing calls alike, by category (see the
+// categories in package metrics), and the number of RPCs it made. Messages
+// are counted as sized on the wire, after compression; maintenance RPCs
+// over the UDP transport are counted by their datagrams.
+func (n *Node) Bandwidth() metrics.Bandwidth {
+	b := make(metrics.Bandwidth, len(metrics.Categories))
+	for _, category := range metrics.Categories {
+		b[category] = metrics.Traffic{}
+	}
+	n.bandwidth.counters.Range(func(category, t any) bool {
+		b[category.(string)] = metrics.Traffic{
+			Sent:     t.(*traffic).sent.Load(),
+			Received: t.(*traffic).received.Load(),
+			Calls:    t.(*traffic).calls.Load(),
+		}
+		return true
+	})
+	return b
+}
+
+// countBandwidth adds the bytes sent and received by an RPC of category
+func (n *Node) countBandwidth(category string, sent, received int) {
+	t := n.bandwidth.traffic(category)
+	t.sent.Add(int64(sent))
+	t.received.Add(int64(received))
+
+	instruments := n.instruments.Load()
+	if sent > 0 {
+		instruments.bytesSent[category].Add(int64(sent))
+	}
+	if received > 0 {
+		instruments.bytesReceived[category].Add(int64(received))
+	}
+}
+
+// countCall counts an RPC of category made by the node
+func (n *Node) countCall(category string) {
+	n.bandwidth.traffic(category).calls.Add(1)
+}
+
+// traffic returns the counters of category, adding them if needed
+func (b *bandwidth) traffic(category string) *traffic {
+	t, ok := b.counters.Load(category)
+	if !ok {
+		t, _ = b.counters.LoadOrStore(category, new(traffic))
+	}
+	return t.(*traffic)
+}
+
+// bandwidthCategory is the context key of an RPC's bandwidth category
+type bandwidthCategory struct{}
+
+// bandwidthCounter counts the bytes of the RPCs of a node's server and
+// outgoing connections by category
+type bandwidthCounter struct {
+	node *Node
+}
+
+// TagRPC records the category of the RPC
+func (c bandwidthCounter) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, bandwidthCategory{}, categoryOf(info.FullMethodName))
+}
+
+// HandleRPC counts the bytes of every message and the calls made
+func (c bandwidthCounter) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	category, _ := ctx.Value(bandwidthCategory{}).(string)
+	if category == "" {
+		category = metrics.CategoryOther
+	}
+	switch s := s.(type) {
+	case *stats.Begin:
+		if s.Client {
+			c.node.countCall(category)
+		}
+	case *stats.OutPayload:
+		c.node.countBandwidth(category, s.WireLength, 0)
+	case *stats.InPayload:
+		c.node.countBandwidth(category, 0, s.WireLength)
+	}
+}
+
+// TagConn returns ctx
+func (c bandwidthCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn ignores connection events
+func (c bandwidthCounter) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,69 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBandwidth(t *testing.T) {
+	sink := metrics.NewMemory()
+	nodes := startTransportRing(t, 8540, []Transport{TransportGRPC, TransportUDP}, Middleware{})
+	nodes[0].SetMetrics(sink)
+
+	for _, node := range nodes {
+		node.stabilize()
+	}
+	if _, err := nodes[1].findSuccessor(context.Background(), hash.NewHashFromString("key")); err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	conn, err := nodes[1].ClientConn(nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	client := pb.NewChordServiceClient(conn)
+
+	// Store a key the first node owns
+	for i := 0; ; i++ {
+		_, err := client.Put(context.Background(), &pb.PutRequest{Key: fmt.Sprintf("key-%d", i), Value: []byte("value")})
+		if err == nil {
+			break
+		}
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	bandwidth := nodes[0].Bandwidth()
+	for _, category := range []string{metrics.CategoryLookup, metrics.CategoryMaintenance, metrics.CategoryStorage} {
+		if bandwidth[category].Sent == 0 || bandwidth[category].Received == 0 {
+			t.Errorf("Expected %s traffic both ways: %+v", category, bandwidth)
+		}
+	}
+	// Only calls the node made count, not the ones it served
+	if bandwidth[metrics.CategoryMaintenance].Calls == 0 || bandwidth[metrics.CategoryStorage].Calls != 0 {
+		t.Errorf("Expected maintenance calls and no storage calls: %+v", bandwidth)
+	}
+	if bandwidth[metrics.CategoryReplication].Total() != 0 {
+		t.Errorf("Expected no replication: %+v", bandwidth)
+	}
+	if overhead := bandwidth.MaintenanceOverhead(); overhead <= 0 || overhead >= 1 {
+		t.Errorf("Expected maintenance to be part of the traffic, got %.2f", overhead)
+	}
+	if sink.CounterValue(metrics.BytesSent(metrics.CategoryMaintenance)) == 0 ||
+		sink.CounterValue(metrics.BytesReceived(metrics.CategoryStorage)) == 0 {
+		t.Error("Expected the traffic in the metrics sink")
+	}
+
+	// The node on the UDP transport counts its maintenance datagrams
+	if udp := nodes[1].Bandwidth()[metrics.CategoryMaintenance]; udp.Sent == 0 || udp.Received == 0 || udp.Calls == 0 {
+		t.Errorf("Expected maintenance traffic over UDP: %+v", udp)
+	}
+}
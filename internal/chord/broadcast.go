@@ -0,0 +1,253 @@
+package chord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// broadcastSeenTTL is how long a broadcast ID is remembered to drop
+// duplicates
+const broadcastSeenTTL = time.Minute
+
+// BroadcastMessage is a message delivered to every node in the ring
+type BroadcastMessage struct {
+	ID      string
+	Kind    string
+	Payload []byte
+	Origin  *NodeInfo
+}
+
+// BroadcastHandler is called once on every node a broadcast of its kind
+// reaches, including the origin
+type BroadcastHandler func(msg *BroadcastMessage)
+
+// BroadcastResult reports how far a broadcast got
+type BroadcastResult struct {
+	// Reached is the number of nodes that delivered the message
+	Reached int
+	// Depth is the longest forwarding chain, 1 if the origin is alone
+	Depth int
+}
+
+// broadcastChild is a node a broadcast is forwarded to, together with the
+// exclusive end of the range it is responsible for
+type broadcastChild struct {
+	node  *NodeInfo
+	limit *hash.Hash
+}
+
+// HandleBroadcast registers the handler for broadcasts of the given kind,
+// replacing any previous one. A nil handler removes the registration.
+func (n *Node) HandleBroadcast(kind string, handler BroadcastHandler) {
+	n.broadcastMu.Lock()
+	defer n.broadcastMu.Unlock()
+
+	if handler == nil {
+		delete(n.broadcastHandlers, kind)
+		return
+	}
+	n.broadcastHandlers[kind] = handler
+}
+
+// Broadcast delivers payload to the handler registered for kind on every
+// node in the ring. The ring is split along finger boundaries: each finger
+// is made responsible for the range up to the next finger and splits it the
+// same way, so N nodes are reached with N-1 messages in O(log N) rounds.
+func (n *Node) Broadcast(ctx context.Context, kind string, payload []byte) (*BroadcastResult, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate broadcast id: %w", err)
+	}
+
+	msg := &BroadcastMessage{
+		ID:      hex.EncodeToString(id),
+		Kind:    kind,
+		Payload: payload,
+		Origin:  n.GetNodeInfo(),
+	}
+
+	// A limit equal to our own ID stands for the whole ring
+	reached, depth := n.relayBroadcast(ctx, msg, n.id)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &BroadcastResult{Reached: reached, Depth: depth}, nil
+}
+
+// relayBroadcast delivers a broadcast locally and forwards it to the nodes
+// covering (self, limit). It returns the number of nodes reached and the
+// depth of the subtree rooted here.
+func (n *Node) relayBroadcast(ctx context.Context, msg *BroadcastMessage, limit *hash.Hash) (int, int) {
+	if !n.markBroadcastSeen(msg.ID) {
+		return 0, 0
+	}
+
+	children := n.broadcastChildren(limit)
+	responses := make([]*pb.BroadcastResponse, len(children))
+
+	var wg sync.WaitGroup
+	for i, child := range children {
+		wg.Add(1)
+		go func(i int, child broadcastChild) {
+			defer wg.Done()
+			resp, err := n.remoteBroadcast(ctx, child.node.Address, msg, child.limit)
+			if err != nil {
+				log.Printf("Node %s: broadcast %s to %s failed: %v",
+					n.id.Short(), msg.ID, child.node.Address, err)
+				return
+			}
+			responses[i] = resp
+		}(i, child)
+	}
+
+	n.deliverBroadcast(msg)
+	wg.Wait()
+
+	reached, depth := 1, 0
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		reached += int(resp.Reached)
+		if int(resp.Depth) > depth {
+			depth = int(resp.Depth)
+		}
+	}
+	return reached, depth + 1
+}
+
+// broadcastChildren picks the distinct successor and fingers in
+// (self, limit), closest first, and hands each the range up to the next one
+func (n *Node) broadcastChildren(limit *hash.Hash) []broadcastChild {
+	n.mu.RLock()
+	candidates := make([]*NodeInfo, 0, len(n.fingers)+1)
+	if n.successor != nil {
+		candidates = append(candidates, n.successor)
+	}
+	candidates = append(candidates, n.fingers...)
+	n.mu.RUnlock()
+
+	wholeRing := limit.Equal(n.id)
+	seen := make(map[string]bool)
+	var nodes []*NodeInfo
+	for _, candidate := range candidates {
+		if candidate == nil || candidate.Address == n.address || seen[candidate.Address] {
+			continue
+		}
+		if !wholeRing && !candidate.ID.InRangeExclusive(n.id, limit) {
+			continue
+		}
+		seen[candidate.Address] = true
+		nodes = append(nodes, candidate)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return n.id.Distance(nodes[i].ID).Cmp(n.id.Distance(nodes[j].ID)) < 0
+	})
+
+	children := make([]broadcastChild, len(nodes))
+	for i, node := range nodes {
+		children[i] = broadcastChild{node: node, limit: limit}
+		if i+1 < len(nodes) {
+			children[i].limit = nodes[i+1].ID
+		}
+	}
+	return children
+}
+
+// markBroadcastSeen records a broadcast ID, reporting false if it was
+// already seen. Expired IDs are forgotten on the way.
+func (n *Node) markBroadcastSeen(id string) bool {
+	n.broadcastMu.Lock()
+	defer n.broadcastMu.Unlock()
+
+	now := time.Now()
+	for seenID, at := range n.seenBroadcasts {
+		if now.Sub(at) > broadcastSeenTTL {
+			delete(n.seenBroadcasts, seenID)
+		}
+	}
+
+	if _, seen := n.seenBroadcasts[id]; seen {
+		return false
+	}
+	n.seenBroadcasts[id] = now
+	return true
+}
+
+// deliverBroadcast hands a broadcast to the handler registered for its kind
+func (n *Node) deliverBroadcast(msg *BroadcastMessage) {
+	n.broadcastMu.Lock()
+	handler := n.broadcastHandlers[msg.Kind]
+	n.broadcastMu.Unlock()
+
+	if handler != nil {
+		handler(msg)
+	}
+}
+
+// remoteBroadcast forwards a broadcast to the node at address
+func (n *Node) remoteBroadcast(ctx context.Context, address string, msg *BroadcastMessage, limit *hash.Hash) (*pb.BroadcastResponse, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.RelayBroadcast(ctx, &pb.BroadcastRequest{
+		Id:      msg.ID,
+		Kind:    msg.Kind,
+		Payload: msg.Payload,
+		Origin:  toProtoNode(msg.Origin),
+		Limit:   limit.String(),
+	})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("broadcast to %s failed: %s", address, resp.Error)
+	}
+	return resp, nil
+}
+
+// RelayBroadcast delivers a broadcast here and relays it over the given range
+func (n *Node) RelayBroadcast(ctx context.Context, req *pb.BroadcastRequest) (*pb.BroadcastResponse, error) {
+	n.countMessage()
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing broadcast id")
+	}
+	limit, err := hash.NewHashFromHex(req.Limit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: %v", err)
+	}
+	origin, err := fromProtoNode(req.Origin)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid origin: %v", err)
+	}
+
+	reached, depth := n.relayBroadcast(ctx, &BroadcastMessage{
+		ID:      req.Id,
+		Kind:    req.Kind,
+		Payload: req.Payload,
+		Origin:  origin,
+	}, limit)
+	return &pb.BroadcastResponse{
+		Reached: int32(reached),
+		Depth:   int32(depth),
+		Success: true,
+	}, nil
+}
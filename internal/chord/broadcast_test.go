@@ -0,0 +1,39 @@
+package chord
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestBroadcastCoverage(t *testing.T) {
+	nodes := startTestRing(t, 8370, 5)
+
+	var mu sync.Mutex
+	delivered := make(map[string]int)
+	for _, node := range nodes {
+		address := node.GetAddress()
+		node.HandleBroadcast("test", func(msg *BroadcastMessage) {
+			mu.Lock()
+			delivered[address]++
+			mu.Unlock()
+			if string(msg.Payload) != "hello" {
+				t.Errorf("Unexpected payload %q", msg.Payload)
+			}
+		})
+	}
+
+	result, err := nodes[2].Broadcast(context.Background(), "test", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Broadcast failed: %v", err)
+	}
+	if result.Reached != len(nodes) {
+		t.Errorf("Expected %d nodes reached, got %d", len(nodes), result.Reached)
+	}
+
+	for _, node := range nodes {
+		if got := delivered[node.GetAddress()]; got != 1 {
+			t.Errorf("Node %s delivered %d times, expected once", node.GetAddress(), got)
+		}
+	}
+}
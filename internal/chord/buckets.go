@@ -0,0 +1,547 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// BucketSeparator separates a bucket's name from the keys stored in it.
+	// A bucket is the namespace tenant accounting already uses (see
+	// metrics.TenantOf), so its keys are counted to the bucket's tenant.
+	BucketSeparator = "/"
+	// DefaultBucketPage is the number of keys a ListBucket call returns when
+	// it asks for none
+	DefaultBucketPage = 1000
+	// MaxBucketPage bounds the keys returned by one ListBucket call
+	MaxBucketPage = 10000
+	// bucketUsageTTL is how long the measured storage of the buckets is
+	// used to check quotas before it is measured again. Writes through the
+	// node are added to it meanwhile.
+	bucketUsageTTL = time.Second
+	// maxBucketWalk bounds the nodes visited to list a bucket
+	maxBucketWalk = 4096
+)
+
+// BucketKey returns the ring key of key in bucket
+func BucketKey(bucket, key string) string {
+	return bucket + BucketSeparator + key
+}
+
+// SplitBucketKey returns the bucket of a ring key and the key within it. It
+// returns false for keys outside any bucket, including the node's internal
+// keys.
+func SplitBucketKey(key string) (bucket, name string, ok bool) {
+	if strings.HasPrefix(key, "\x00") {
+		return "", "", false
+	}
+	i := strings.Index(key, BucketSeparator)
+	if i <= 0 {
+		return "", "", false
+	}
+	return key[:i], key[i+len(BucketSeparator):], true
+}
+
+// ValidBucket returns an error for names that cannot be used as buckets
+func ValidBucket(bucket string) error {
+	switch {
+	case bucket == "":
+		return fmt.Errorf("empty bucket name")
+	case strings.Contains(bucket, BucketSeparator):
+		return fmt.Errorf("bucket name %q contains %q", bucket, BucketSeparator)
+	case strings.HasPrefix(bucket, "\x00"):
+		return fmt.Errorf("bucket name %q is reserved", bucket)
+	}
+	return nil
+}
+
+// BucketQuota limits what a bucket stores on each node. Quotas are checked
+// by the owner of a key when it is written; replicas and ranges handed over
+// to the node are never refused, so a bucket can briefly exceed its quota
+// after a node leaves. Zero fields are unlimited.
+type BucketQuota struct {
+	MaxKeys  int64
+	MaxBytes int64
+}
+
+// BucketStats is the storage and traffic of one bucket, on one node or
+// summed across a ring
+type BucketStats struct {
+	Bucket string
+	// Keys and Bytes are the live keys owned, and their value bytes
+	Keys  int64
+	Bytes int64
+	// Reads and Writes count the keys of the bucket read and written on the
+	// node
+	Reads  int64
+	Writes int64
+	// Quota is the node's quota, or the largest of the nodes' quotas for a
+	// ring
+	Quota BucketQuota
+}
+
+// bucketUsage is the live keys and bytes a bucket stores on the node
+type bucketUsage struct {
+	keys  int64
+	bytes int64
+}
+
+// buckets holds the quotas and counters of the node's buckets
+type buckets struct {
+	mu     sync.Mutex
+	quotas map[string]BucketQuota
+	reads  map[string]int64
+	writes map[string]int64
+	// usage is the owned storage of every bucket measured at measuredAt,
+	// plus the writes checked since
+	usage      map[string]bucketUsage
+	measuredAt time.Time
+}
+
+// SetBucketQuota limits what bucket stores on this node. Writes to keys the
+// node owns that would take the bucket over its quota fail with
+// ErrQuotaExceeded. A zero quota removes the limit. Every node of the ring
+// should be given the same quotas.
+func (n *Node) SetBucketQuota(bucket string, quota BucketQuota) error {
+	if err := ValidBucket(bucket); err != nil {
+		return err
+	}
+
+	n.buckets.mu.Lock()
+	defer n.buckets.mu.Unlock()
+
+	if quota == (BucketQuota{}) {
+		delete(n.buckets.quotas, bucket)
+		return nil
+	}
+	if n.buckets.quotas == nil {
+		n.buckets.quotas = make(map[string]BucketQuota)
+	}
+	n.buckets.quotas[bucket] = quota
+	return nil
+}
+
+// countOps adds reads or writes of keys to their buckets' counters
+func (b *buckets) countOps(keys []string, write bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		bucket, _, ok := SplitBucketKey(key)
+		if !ok {
+			continue
+		}
+		counters := &b.reads
+		if write {
+			counters = &b.writes
+		}
+		if *counters == nil {
+			*counters = make(map[string]int64)
+		}
+		(*counters)[bucket]++
+	}
+}
+
+// measureBuckets sums the live keys and value bytes of every bucket stored
+// on this node whose keys it owns
+func (n *Node) measureBuckets() (map[string]bucketUsage, error) {
+	usage := make(map[string]bucketUsage)
+	now := time.Now()
+
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	err := n.storage.Range(func(key string, e Entry) bool {
+		bucket, _, ok := SplitBucketKey(key)
+		if !ok || !e.Live(now) || !n.ownsLocked(n.KeyID(key)) {
+			return true
+		}
+		u := usage[bucket]
+		u.keys++
+		u.bytes += int64(len(e.Value))
+		usage[bucket] = u
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure buckets: %w", err)
+	}
+	return usage, nil
+}
+
+// checkBucketQuotas returns ErrQuotaExceeded if writing batch would take a
+// bucket over its quota, and otherwise counts the batch in the buckets'
+// usage. The caller must hold dataMu and have checked that it owns the
+// keys.
+func (n *Node) checkBucketQuotas(batch []*pb.KeyValue) error {
+	n.buckets.mu.Lock()
+	defer n.buckets.mu.Unlock()
+
+	if len(n.buckets.quotas) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	delta := make(map[string]bucketUsage)
+	seen := make(map[string]bool, len(batch))
+	for _, item := range batch {
+		bucket, _, ok := SplitBucketKey(item.Key)
+		if _, limited := n.buckets.quotas[bucket]; !ok || !limited || seen[item.Key] {
+			continue
+		}
+		seen[item.Key] = true
+		e, exists, err := n.storage.Get(item.Key)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", item.Key, err)
+		}
+		d := delta[bucket]
+		if exists && e.Live(now) {
+			d.bytes -= int64(len(e.Value))
+		} else {
+			d.keys++
+		}
+		d.bytes += int64(len(item.Value))
+		delta[bucket] = d
+	}
+	if len(delta) == 0 {
+		return nil
+	}
+
+	if n.buckets.usage == nil || now.Sub(n.buckets.measuredAt) > bucketUsageTTL {
+		usage, err := n.measureBuckets()
+		if err != nil {
+			return err
+		}
+		n.buckets.usage, n.buckets.measuredAt = usage, now
+	}
+	for bucket, d := range delta {
+		u, quota := n.buckets.usage[bucket], n.buckets.quotas[bucket]
+		if d.keys > 0 && quota.MaxKeys > 0 && u.keys+d.keys > quota.MaxKeys {
+			return fmt.Errorf("%w: bucket %q holds %d keys on %s, at most %d", ErrQuotaExceeded, bucket, u.keys, n.address, quota.MaxKeys)
+		}
+		if d.bytes > 0 && quota.MaxBytes > 0 && u.bytes+d.bytes > quota.MaxBytes {
+			return fmt.Errorf("%w: bucket %q holds %d bytes on %s, at most %d", ErrQuotaExceeded, bucket, u.bytes, n.address, quota.MaxBytes)
+		}
+	}
+	for bucket, d := range delta {
+		u := n.buckets.usage[bucket]
+		u.keys += d.keys
+		u.bytes += d.bytes
+		n.buckets.usage[bucket] = u
+	}
+	return nil
+}
+
+// BucketStats returns the stats of the buckets on this node, sorted by
+// name. An empty bucket reports every bucket that stores keys, has been
+// used or has a quota.
+func (n *Node) BucketStats(bucket string) ([]BucketStats, error) {
+	usage, err := n.measureBuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	n.buckets.mu.Lock()
+	defer n.buckets.mu.Unlock()
+
+	names := make(map[string]bool)
+	for name := range usage {
+		names[name] = true
+	}
+	for _, m := range []map[string]int64{n.buckets.reads, n.buckets.writes} {
+		for name := range m {
+			names[name] = true
+		}
+	}
+	for name := range n.buckets.quotas {
+		names[name] = true
+	}
+	if bucket != "" {
+		names = map[string]bool{bucket: true}
+	}
+
+	stats := make([]BucketStats, 0, len(names))
+	for name := range names {
+		stats = append(stats, BucketStats{
+			Bucket: name,
+			Keys:   usage[name].keys,
+			Bytes:  usage[name].bytes,
+			Reads:  n.buckets.reads[name],
+			Writes: n.buckets.writes[name],
+			Quota:  n.buckets.quotas[name],
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Bucket < stats[j].Bucket })
+	return stats, nil
+}
+
+// BucketKeys returns up to limit keys of bucket owned by this node, sorted
+// and without the bucket's name, that sort after after. It also reports
+// whether more keys follow.
+func (n *Node) BucketKeys(bucket, after string, limit int) ([]string, bool, error) {
+	if err := ValidBucket(bucket); err != nil {
+		return nil, false, err
+	}
+	if limit <= 0 {
+		limit = DefaultBucketPage
+	}
+	limit = min(limit, MaxBucketPage)
+
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	now := time.Now()
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		keyBucket, name, ok := SplitBucketKey(key)
+		if ok && keyBucket == bucket && name > after && e.Live(now) && n.ownsLocked(n.KeyID(key)) {
+			keys = append(keys, name)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list bucket %q: %w", bucket, err)
+	}
+	sort.Strings(keys)
+	if len(keys) > limit {
+		return keys[:limit], true, nil
+	}
+	return keys, false, nil
+}
+
+// RemoteBucketKeys asks the node at address for a page of the keys of
+// bucket it owns
+func (n *Node) RemoteBucketKeys(ctx context.Context, address, bucket, after string, limit int) ([]string, bool, error) {
+	if address == n.address {
+		return n.BucketKeys(bucket, after, limit)
+	}
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.ListBucket(ctx, &pb.ListBucketRequest{Bucket: bucket, After: after, Limit: int32(limit)})
+	if err != nil {
+		return nil, false, fromStatus(address, err)
+	}
+	return resp.Keys, resp.More, nil
+}
+
+// RemoteBucketStats asks the node at address for the stats of bucket, or of
+// every bucket if empty
+func (n *Node) RemoteBucketStats(ctx context.Context, address, bucket string) ([]BucketStats, error) {
+	if address == n.address {
+		return n.BucketStats(bucket)
+	}
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetBucketStats(ctx, &pb.GetBucketStatsRequest{Bucket: bucket})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	return BucketStatsFromProto(resp.Buckets), nil
+}
+
+// BucketStatsFromProto converts the stats of a GetBucketStats response
+func BucketStatsFromProto(buckets []*pb.BucketStats) []BucketStats {
+	stats := make([]BucketStats, 0, len(buckets))
+	for _, b := range buckets {
+		stats = append(stats, BucketStats{
+			Bucket: b.Bucket,
+			Keys:   b.Keys,
+			Bytes:  b.Bytes,
+			Reads:  b.Reads,
+			Writes: b.Writes,
+			Quota:  BucketQuota{MaxKeys: b.MaxKeys, MaxBytes: b.MaxBytes},
+		})
+	}
+	return stats
+}
+
+// MergeBucketStats adds the stats of one node to those of a ring, keeping
+// the largest quota
+func MergeBucketStats(total map[string]*BucketStats, stats []BucketStats) {
+	for _, s := range stats {
+		t, ok := total[s.Bucket]
+		if !ok {
+			t = &BucketStats{Bucket: s.Bucket}
+			total[s.Bucket] = t
+		}
+		t.Keys += s.Keys
+		t.Bytes += s.Bytes
+		t.Reads += s.Reads
+		t.Writes += s.Writes
+		t.Quota.MaxKeys = max(t.Quota.MaxKeys, s.Quota.MaxKeys)
+		t.Quota.MaxBytes = max(t.Quota.MaxBytes, s.Quota.MaxBytes)
+	}
+}
+
+// walkRing calls visit for every node of the ring, starting with this one
+// and following successors until back at it
+func (n *Node) walkRing(ctx context.Context, visit func(address string) error) error {
+	visited := make(map[string]bool)
+	address := n.address
+	for len(visited) < maxBucketWalk {
+		if visited[address] {
+			return nil
+		}
+		visited[address] = true
+		if err := visit(address); err != nil {
+			return err
+		}
+
+		var successor *NodeInfo
+		if address == n.address {
+			successor = n.GetSuccessor()
+		} else {
+			peers, err := n.RemotePeers(ctx, address, 0)
+			if err != nil {
+				return err
+			}
+			if len(peers.Successors) > 0 {
+				successor = peers.Successors[0]
+			}
+		}
+		if successor == nil {
+			return fmt.Errorf("node %s has no successor", address)
+		}
+		address = successor.Address
+	}
+	return fmt.Errorf("walk from %s did not close after %d nodes", n.address, maxBucketWalk)
+}
+
+// Bucket is a namespace of keys in the ring, so applications sharing a ring
+// do not collide on key names. Its keys are stored as BucketKey(name, key).
+type Bucket struct {
+	node *Node
+	name string
+}
+
+// Bucket returns the bucket named name, which needs no creating
+func (n *Node) Bucket(name string) (*Bucket, error) {
+	if err := ValidBucket(name); err != nil {
+		return nil, err
+	}
+	return &Bucket{node: n, name: name}, nil
+}
+
+// Name returns the bucket's name
+func (b *Bucket) Name() string {
+	return b.name
+}
+
+// Put stores key in the bucket
+func (b *Bucket) Put(ctx context.Context, key string, value []byte) error {
+	return b.node.StoreValue(ctx, BucketKey(b.name, key), value)
+}
+
+// Get retrieves key from the bucket. It returns ErrKeyNotFound if the
+// bucket has no such key.
+func (b *Bucket) Get(ctx context.Context, key string) ([]byte, error) {
+	return b.node.FetchValue(ctx, BucketKey(b.name, key))
+}
+
+// Keys lists the keys of the bucket across the ring, sorted. It asks every
+// node for the keys it owns, so keys written or moved meanwhile may be
+// missed or listed twice, which Keys removes.
+func (b *Bucket) Keys(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	err := b.node.walkRing(ctx, func(address string) error {
+		after := ""
+		for {
+			keys, more, err := b.node.RemoteBucketKeys(ctx, address, b.name, after, MaxBucketPage)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				seen[key] = true
+			}
+			if !more || len(keys) == 0 {
+				return nil
+			}
+			after = keys[len(keys)-1]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Stats sums the stats of the bucket across the ring
+func (b *Bucket) Stats(ctx context.Context) (BucketStats, error) {
+	total := make(map[string]*BucketStats)
+	err := b.node.walkRing(ctx, func(address string) error {
+		stats, err := b.node.RemoteBucketStats(ctx, address, b.name)
+		if err != nil {
+			return err
+		}
+		MergeBucketStats(total, stats)
+		return nil
+	})
+	if err != nil {
+		return BucketStats{}, err
+	}
+	if stats, ok := total[b.name]; ok {
+		return *stats, nil
+	}
+	return BucketStats{Bucket: b.name}, nil
+}
+
+// ListBucket returns a page of the keys of a bucket owned by this node
+func (n *Node) ListBucket(ctx context.Context, req *pb.ListBucketRequest) (*pb.ListBucketResponse, error) {
+	n.countMessage()
+
+	keys, more, err := n.BucketKeys(req.Bucket, req.After, int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.ListBucketResponse{Keys: keys, More: more}, nil
+}
+
+// GetBucketStats reports the stats of the buckets on this node
+func (n *Node) GetBucketStats(ctx context.Context, req *pb.GetBucketStatsRequest) (*pb.GetBucketStatsResponse, error) {
+	n.countMessage()
+
+	stats, err := n.BucketStats(req.Bucket)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.GetBucketStatsResponse{Buckets: make([]*pb.BucketStats, 0, len(stats))}
+	for _, s := range stats {
+		resp.Buckets = append(resp.Buckets, &pb.BucketStats{
+			Bucket:   s.Bucket,
+			Keys:     s.Keys,
+			Bytes:    s.Bytes,
+			Reads:    s.Reads,
+			Writes:   s.Writes,
+			MaxKeys:  s.Quota.MaxKeys,
+			MaxBytes: s.Quota.MaxBytes,
+		})
+	}
+	return resp, nil
+}
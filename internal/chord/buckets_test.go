@@ -0,0 +1,113 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBucketQuota(t *testing.T) {
+	node, err := startRingNode(t, DefaultRing, "localhost:8593", "")
+	if err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	if err := node.SetBucketQuota("photos", BucketQuota{MaxKeys: 2, MaxBytes: 10}); err != nil {
+		t.Fatalf("SetBucketQuota failed: %v", err)
+	}
+	photos, err := node.Bucket("photos")
+	if err != nil {
+		t.Fatalf("Bucket failed: %v", err)
+	}
+	docs, _ := node.Bucket("docs")
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b"} {
+		if err := photos.Put(ctx, key, []byte("1234")); err != nil {
+			t.Fatalf("Put %q failed: %v", key, err)
+		}
+	}
+	if err := photos.Put(ctx, "c", []byte("1")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for a third key, got %v", err)
+	}
+	if err := photos.Put(ctx, "a", []byte("1234567")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for 11 bytes, got %v", err)
+	}
+	if err := photos.Put(ctx, "a", []byte("123456")); err != nil {
+		t.Errorf("Overwrite within the quota failed: %v", err)
+	}
+	if err := docs.Put(ctx, "a", []byte("not limited")); err != nil {
+		t.Errorf("Put to a bucket without quota failed: %v", err)
+	}
+
+	// Keys of different buckets do not collide
+	value, err := photos.Get(ctx, "a")
+	if err != nil || string(value) != "123456" {
+		t.Errorf("Get returned %q, %v", value, err)
+	}
+
+	stats, err := photos.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	want := BucketStats{Bucket: "photos", Keys: 2, Bytes: 10, Reads: 1, Writes: 3, Quota: BucketQuota{MaxKeys: 2, MaxBytes: 10}}
+	if stats != want {
+		t.Errorf("Stats returned %+v, expected %+v", stats, want)
+	}
+}
+
+func TestBucketKeysAcrossRing(t *testing.T) {
+	first, err := startRingNode(t, DefaultRing, "localhost:8594", "")
+	if err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	second, err := startRingNode(t, DefaultRing, "localhost:8595", first.GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	for round := 0; round < 3; round++ {
+		first.stabilize()
+		second.stabilize()
+	}
+
+	ctx := context.Background()
+	logs, _ := first.Bucket("logs")
+	var want []string
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		want = append(want, key)
+		if err := logs.Put(ctx, key, []byte("value")); err != nil {
+			t.Fatalf("Put %q failed: %v", key, err)
+		}
+	}
+	if err := first.StoreValue(ctx, "other/key-00", []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+
+	owned := make([]int, 2)
+	for i, node := range []*Node{first, second} {
+		keys, _, err := node.BucketKeys("logs", "", 0)
+		if err != nil {
+			t.Fatalf("BucketKeys failed: %v", err)
+		}
+		owned[i] = len(keys)
+	}
+	if owned[0]+owned[1] != len(want) {
+		t.Errorf("Nodes own %v keys of the bucket, expected %d in all", owned, len(want))
+	}
+
+	keys, err := logs.Keys(ctx)
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if !slices.Equal(keys, want) {
+		t.Errorf("Keys returned %v, expected %v", keys, want)
+	}
+
+	// Pages of one key
+	page, more, err := first.RemoteBucketKeys(ctx, second.GetAddress(), "logs", "", 1)
+	if err != nil || len(page) != min(owned[1], 1) || more != (owned[1] > 1) {
+		t.Errorf("RemoteBucketKeys returned %v, more %v, %v with %d keys owned", page, more, err, owned[1])
+	}
+}
@@ -0,0 +1,347 @@
+package chord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+const (
+	// storageUsageTTL is how long the measured size of the store is used to
+	// check the storage limits before it is measured again. Writes through
+	// the node are added to it meanwhile.
+	storageUsageTTL = time.Second
+	// evictionSlack is the fraction of a limit freed beyond what a write
+	// needs, so that a full node does not scan its store on every write
+	evictionSlack = 0.05
+)
+
+// Eviction is what a node does with a write that would take its store over
+// the storage limits
+type Eviction string
+
+const (
+	// EvictNone refuses the write with ErrQuotaExceeded
+	EvictNone Eviction = "reject"
+	// EvictExpired drops entries whose TTL has passed to make room, and
+	// refuses the write if that is not enough
+	EvictExpired Eviction = "expired"
+	// EvictLRU drops expired entries, then the least recently read or
+	// written ones, making the node a cache. Entries never read or written
+	// through the node, such as replicas, go first.
+	EvictLRU Eviction = "lru"
+)
+
+// ParseEviction returns the eviction with the given name: reject, expired
+// or lru
+func ParseEviction(name string) (Eviction, error) {
+	switch Eviction(name) {
+	case "", EvictNone:
+		return EvictNone, nil
+	case EvictExpired, EvictLRU:
+		return Eviction(name), nil
+	default:
+		return "", fmt.Errorf("unknown eviction %q", name)
+	}
+}
+
+// StorageLimits bounds what a node stores, replicas and tombstones
+// included. Writes to keys the node owns that would exceed a limit are
+// refused or make room by eviction. Replicas and ranges handed over to the
+// node are never refused, though they evict like writes. Evicted entries
+// are dropped from this node only: other nodes keep their copies, and a
+// read of an evicted key the node owns finds it missing.
+type StorageLimits struct {
+	// MaxKeys and MaxBytes bound the entries stored and the bytes of their
+	// keys and values. Zero fields are unlimited.
+	MaxKeys  int64
+	MaxBytes int64
+	// Eviction is what a write over the limits does, EvictNone if empty
+	Eviction Eviction
+}
+
+// StorageUsage is what a node stores against its storage limits
+type StorageUsage struct {
+	Keys   int64
+	Bytes  int64
+	Limits StorageLimits
+	// Evictions counts the entries the node evicted since it started
+	Evictions int64
+}
+
+// capacity holds the storage limits of a node and the size of its store
+type capacity struct {
+	mu     sync.Mutex
+	limits StorageLimits
+	// usage is the size of the store measured at measuredAt, plus the
+	// writes reserved since
+	usage      bucketUsage
+	measuredAt time.Time
+	// clock and used order the keys by last access for EvictLRU
+	clock     uint64
+	used      map[string]uint64
+	evictions int64
+}
+
+// SetStorageLimits bounds what the node stores. Zero limits remove the
+// bounds.
+func (n *Node) SetStorageLimits(limits StorageLimits) {
+	n.capacity.mu.Lock()
+	defer n.capacity.mu.Unlock()
+
+	if limits.Eviction == "" {
+		limits.Eviction = EvictNone
+	}
+	n.capacity.limits = limits
+	n.capacity.measuredAt = time.Time{}
+	if limits.Eviction != EvictLRU {
+		n.capacity.used = nil
+	}
+}
+
+// StorageLimits returns the limits set with SetStorageLimits
+func (n *Node) StorageLimits() StorageLimits {
+	n.capacity.mu.Lock()
+	defer n.capacity.mu.Unlock()
+
+	return n.capacity.limits
+}
+
+// StorageUsage measures what the node stores
+func (n *Node) StorageUsage() (StorageUsage, error) {
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	n.capacity.mu.Lock()
+	defer n.capacity.mu.Unlock()
+
+	if err := n.measureStorageLocked(time.Now()); err != nil {
+		return StorageUsage{}, err
+	}
+	return n.capacity.usageLocked(), nil
+}
+
+// cachedStorageUsage is StorageUsage for callers too frequent to scan the
+// store every time: the store is only measured again once the last
+// measurement is older than storageUsageTTL
+func (n *Node) cachedStorageUsage() (StorageUsage, error) {
+	c := &n.capacity
+	c.mu.Lock()
+	if !c.measuredAt.IsZero() && time.Since(c.measuredAt) <= storageUsageTTL {
+		defer c.mu.Unlock()
+		return c.usageLocked(), nil
+	}
+	c.mu.Unlock()
+
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another caller may have measured meanwhile
+	now := time.Now()
+	if c.measuredAt.IsZero() || now.Sub(c.measuredAt) > storageUsageTTL {
+		if err := n.measureStorageLocked(now); err != nil {
+			return StorageUsage{}, err
+		}
+	}
+	return c.usageLocked(), nil
+}
+
+// usageLocked returns the usage last measured or reserved. The caller must
+// hold mu.
+func (c *capacity) usageLocked() StorageUsage {
+	return StorageUsage{
+		Keys:      c.usage.keys,
+		Bytes:     c.usage.bytes,
+		Limits:    c.limits,
+		Evictions: c.evictions,
+	}
+}
+
+// limited reports whether the limits bound anything
+func (l StorageLimits) limited() bool {
+	return l.MaxKeys > 0 || l.MaxBytes > 0
+}
+
+// fits reports whether u is within the limits, less a fraction of them
+func (l StorageLimits) fits(u bucketUsage, slack float64) bool {
+	return (l.MaxKeys <= 0 || float64(u.keys) <= float64(l.MaxKeys)*(1-slack)) &&
+		(l.MaxBytes <= 0 || float64(u.bytes) <= float64(l.MaxBytes)*(1-slack))
+}
+
+// entrySize is the bytes an entry counts against the storage limits
+func entrySize(key string, e Entry) int64 {
+	return int64(len(key) + len(e.Value))
+}
+
+// measureStorageLocked sums the entries of the store, forgetting the access
+// times of keys no longer stored. The caller must hold dataMu and
+// capacity.mu.
+func (n *Node) measureStorageLocked(now time.Time) error {
+	c := &n.capacity
+	var usage bucketUsage
+	var used map[string]uint64
+	if c.used != nil {
+		used = make(map[string]uint64, len(c.used))
+	}
+	err := n.storage.Range(func(key string, e Entry) bool {
+		usage.keys++
+		usage.bytes += entrySize(key, e)
+		if seq, ok := c.used[key]; ok {
+			used[key] = seq
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to measure storage: %w", err)
+	}
+	c.usage, c.measuredAt = usage, now
+	if used != nil {
+		c.used = used
+	}
+	instruments := n.instruments.Load()
+	instruments.storageKeys.Set(float64(usage.keys))
+	instruments.storageBytes.Set(float64(usage.bytes))
+	return nil
+}
+
+// reserveStorage checks that writing batch keeps the store within the
+// storage limits, evicting entries other than the batch's to make room if
+// the eviction allows, and counts the batch in the usage. If the batch does
+// not fit, it returns ErrQuotaExceeded when refuse is set and is counted
+// anyway otherwise. The caller must hold dataMu for writing.
+func (n *Node) reserveStorage(batch []*pb.KeyValue, refuse bool) error {
+	c := &n.capacity
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.limits.limited() {
+		return nil
+	}
+
+	var delta bucketUsage
+	keep := make(map[string]bool, len(batch))
+	for _, item := range batch {
+		if keep[item.Key] {
+			continue
+		}
+		keep[item.Key] = true
+		e, exists, err := n.storage.Get(item.Key)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", item.Key, err)
+		}
+		if exists {
+			delta.bytes -= entrySize(item.Key, e)
+		} else {
+			delta.keys++
+		}
+		delta.bytes += int64(len(item.Key) + len(item.Value))
+	}
+
+	now := time.Now()
+	if c.measuredAt.IsZero() || now.Sub(c.measuredAt) > storageUsageTTL {
+		if err := n.measureStorageLocked(now); err != nil {
+			return err
+		}
+	}
+	after := bucketUsage{keys: c.usage.keys + delta.keys, bytes: c.usage.bytes + delta.bytes}
+	// A batch larger than the limits on its own evicts nothing
+	if !c.limits.fits(after, 0) && c.limits.Eviction != EvictNone && c.limits.fits(delta, 0) {
+		if err := n.evictLocked(keep, delta, now); err != nil {
+			return err
+		}
+		after = bucketUsage{keys: c.usage.keys + delta.keys, bytes: c.usage.bytes + delta.bytes}
+	}
+	if refuse && !c.limits.fits(after, 0) && (delta.keys > 0 || delta.bytes > 0) {
+		if c.limits.MaxKeys > 0 && after.keys > c.limits.MaxKeys {
+			return fmt.Errorf("%w: node %s holds %d keys, at most %d", ErrQuotaExceeded, n.address, c.usage.keys, c.limits.MaxKeys)
+		}
+		return fmt.Errorf("%w: node %s holds %d bytes, at most %d", ErrQuotaExceeded, n.address, c.usage.bytes, c.limits.MaxBytes)
+	}
+
+	c.usage = after
+	instruments := n.instruments.Load()
+	instruments.storageKeys.Set(float64(after.keys))
+	instruments.storageBytes.Set(float64(after.bytes))
+	return nil
+}
+
+// evictLocked drops entries not in keep until the store has room for delta
+// and some slack: expired entries first, then, for EvictLRU, live entries
+// from the least recently used. Tombstones are never evicted, since the
+// deletes they record would be undone, nor are locks, whose versions are
+// fencing tokens. The caller must hold dataMu and capacity.mu.
+func (n *Node) evictLocked(keep map[string]bool, delta bucketUsage, now time.Time) error {
+	c := &n.capacity
+	type candidate struct {
+		key     string
+		size    int64
+		expired bool
+		used    uint64
+	}
+	var candidates []candidate
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if keep[key] || e.Tombstone() || strings.HasPrefix(key, LockKeyPrefix) {
+			return true
+		}
+		expired := !e.Live(now)
+		if expired || c.limits.Eviction == EvictLRU {
+			candidates = append(candidates, candidate{key, entrySize(key, e), expired, c.used[key]})
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.expired != b.expired {
+			return a.expired
+		}
+		if a.used != b.used {
+			return a.used < b.used
+		}
+		return a.key < b.key
+	})
+
+	evicted := 0
+	for _, cand := range candidates {
+		after := bucketUsage{keys: c.usage.keys + delta.keys, bytes: c.usage.bytes + delta.bytes}
+		if c.limits.fits(after, evictionSlack) {
+			break
+		}
+		if err := n.storage.Delete(cand.key); err != nil {
+			return fmt.Errorf("failed to evict %q: %w", cand.key, err)
+		}
+		delete(c.used, cand.key)
+		c.usage.keys--
+		c.usage.bytes -= cand.size
+		evicted++
+	}
+	c.evictions += int64(evicted)
+	n.instruments.Load().evictions.Add(int64(evicted))
+	return nil
+}
+
+// touchKeys marks keys as just used, for EvictLRU
+func (n *Node) touchKeys(keys []string) {
+	c := &n.capacity
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limits.Eviction != EvictLRU {
+		return
+	}
+	if c.used == nil {
+		c.used = make(map[string]uint64)
+	}
+	for _, key := range keys {
+		c.clock++
+		c.used[key] = c.clock
+	}
+}
@@ -0,0 +1,106 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"chord-dht/internal/metrics"
+)
+
+func TestStorageLimitsReject(t *testing.T) {
+	node := startTestRing(t, 8627, 1)[0]
+	node.SetStorageLimits(StorageLimits{MaxKeys: 3})
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if err := node.StoreValue(ctx, key, []byte("value")); err != nil {
+			t.Fatalf("StoreValue(%q) failed: %v", key, err)
+		}
+	}
+	if err := node.StoreValue(ctx, "d", []byte("value")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for a fourth key, got %v", err)
+	}
+	if err := node.StoreValue(ctx, "a", []byte("other")); err != nil {
+		t.Errorf("Overwrite within the limits failed: %v", err)
+	}
+
+	usage, err := node.StorageUsage()
+	if err != nil {
+		t.Fatalf("StorageUsage failed: %v", err)
+	}
+	want := StorageUsage{Keys: 3, Bytes: 18, Limits: StorageLimits{MaxKeys: 3, Eviction: EvictNone}}
+	if usage != want {
+		t.Errorf("StorageUsage returned %+v, expected %+v", usage, want)
+	}
+}
+
+func TestStorageLimitsEvictLRU(t *testing.T) {
+	node := startTestRing(t, 8628, 1)[0]
+	sink := metrics.NewMemory()
+	node.SetMetrics(sink)
+	node.SetStorageLimits(StorageLimits{MaxKeys: 4, Eviction: EvictLRU})
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := node.StoreValue(ctx, fmt.Sprintf("k%d", i), []byte("value")); err != nil {
+			t.Fatalf("StoreValue failed: %v", err)
+		}
+	}
+	if _, err := node.FetchValue(ctx, "k0"); err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+
+	// Room is made for the new key and some slack, from the least recently
+	// used keys
+	if err := node.StoreValue(ctx, "k4", []byte("value")); err != nil {
+		t.Fatalf("StoreValue over the limit failed: %v", err)
+	}
+	for _, key := range []string{"k1", "k2"} {
+		if _, ok, _ := node.Storage().Get(key); ok {
+			t.Errorf("Expected %s evicted", key)
+		}
+	}
+	for _, key := range []string{"k0", "k3", "k4"} {
+		if _, ok, _ := node.Storage().Get(key); !ok {
+			t.Errorf("Expected %s kept", key)
+		}
+	}
+	if evicted := sink.CounterValue(metrics.Evictions); evicted != 2 {
+		t.Errorf("Expected 2 evictions, got %d", evicted)
+	}
+	if keys := sink.GaugeValue(metrics.StorageKeys); keys != 3 {
+		t.Errorf("Expected 3 stored keys, got %v", keys)
+	}
+}
+
+func TestStorageLimitsEvictExpired(t *testing.T) {
+	node := startTestRing(t, 8629, 1)[0]
+	node.SetStorageLimits(StorageLimits{MaxKeys: 2, Eviction: EvictExpired})
+
+	ctx := context.Background()
+	result, err := node.CompareAndSwap(ctx, ConditionalWrite{Key: "short", Value: []byte("value"), TTL: time.Millisecond})
+	if err != nil || !result.Applied {
+		t.Fatalf("CompareAndSwap failed: %v", err)
+	}
+	if err := node.StoreValue(ctx, "long", []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if err := node.StoreValue(ctx, "new", []byte("value")); err != nil {
+		t.Fatalf("StoreValue in place of an expired entry failed: %v", err)
+	}
+	if _, ok, _ := node.Storage().Get("short"); ok {
+		t.Error("Expected the expired entry evicted")
+	}
+	// Live entries are never evicted
+	if err := node.StoreValue(ctx, "more", []byte("value")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded with no expired entry left, got %v", err)
+	}
+	if usage, _ := node.StorageUsage(); usage.Evictions != 1 {
+		t.Errorf("Expected 1 eviction, got %d", usage.Evictions)
+	}
+}
@@ -0,0 +1,313 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	pb "chord-dht/api/chord/v1"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Compression is the algorithm compressing the messages of a node's RPCs
+// to peers
+type Compression string
+
+const (
+	// CompressionNone sends messages as they are
+	CompressionNone Compression = "none"
+	// CompressionGzip compresses messages with gzip
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses messages with zstd, which is several times
+	// faster than gzip at a similar ratio
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression returns the compression with the given name: none, gzip
+// or zstd
+func ParseCompression(name string) (Compression, error) {
+	switch Compression(name) {
+	case "", CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip, CompressionZstd:
+		return Compression(name), nil
+	default:
+		return "", fmt.Errorf("unknown compression %q", name)
+	}
+}
+
+// CompressionPolicy configures the compression of a node's RPCs to peers.
+// Every node accepts both algorithms whatever its own policy, and replies
+// compressed with the algorithm of the request.
+type CompressionPolicy struct {
+	// Algorithm compresses the calls below; CompressionNone disables it
+	Algorithm Compression
+	// MinSize is the smallest request, in bytes, compressed for methods
+	// other than hand-offs, replication and batches, which are always
+	// compressed since their replies carry stored values. 0 compresses
+	// those bulk methods only.
+	MinSize int
+}
+
+// CompressionStats counts the traffic of the RPCs a node made to its peers
+// before and after compression
+type CompressionStats struct {
+	Algorithm Compression
+	// Compressed counts the calls sent compressed
+	Compressed int64
+	// PayloadBytes counts the bytes of the messages sent and received over
+	// the node's outgoing connections as serialized, WireBytes as sent, after
+	// compression and with gRPC's message framing
+	PayloadBytes int64
+	WireBytes    int64
+}
+
+// bulkMethods carry stored entries and are compressed whatever their
+// request size
+var bulkMethods = map[string]bool{
+	pb.ChordService_PrepareHandoff_FullMethodName: true,
+	pb.ChordService_StreamHandoff_FullMethodName:  true,
+	pb.ChordService_Replicate_FullMethodName:      true,
+	pb.ChordService_PutBatch_FullMethodName:       true,
+	pb.ChordService_GetBatch_FullMethodName:       true,
+	pb.ChordService_GetSnapshot_FullMethodName:    true,
+	pb.ChordService_ListKeys_FullMethodName:       true,
+}
+
+// compression holds a node's compression policy, the peers that refused
+// it and the counters
+type compression struct {
+	mu     sync.Mutex
+	policy CompressionPolicy
+	// refused holds the addresses of peers that cannot decompress the
+	// policy's algorithm; calls to them are sent uncompressed
+	refused map[string]bool
+
+	compressed   atomic.Int64
+	payloadBytes atomic.Int64
+	wireBytes    atomic.Int64
+}
+
+// SetCompression sets how the node compresses its RPCs to peers. It may be
+// called at any time.
+func (n *Node) SetCompression(policy CompressionPolicy) {
+	n.compression.mu.Lock()
+	defer n.compression.mu.Unlock()
+
+	if policy.Algorithm == "" {
+		policy.Algorithm = CompressionNone
+	}
+	n.compression.policy = policy
+	n.compression.refused = make(map[string]bool)
+}
+
+// CompressionStats returns the traffic counters of the node's outgoing RPCs
+func (n *Node) CompressionStats() CompressionStats {
+	n.compression.mu.Lock()
+	algorithm := n.compression.policy.Algorithm
+	n.compression.mu.Unlock()
+
+	if algorithm == "" {
+		algorithm = CompressionNone
+	}
+	return CompressionStats{
+		Algorithm:    algorithm,
+		Compressed:   n.compression.compressed.Load(),
+		PayloadBytes: n.compression.payloadBytes.Load(),
+		WireBytes:    n.compression.wireBytes.Load(),
+	}
+}
+
+// compressorFor returns the compressor of a call to address, or "" to send
+// it uncompressed
+func (n *Node) compressorFor(address, method string, req any) string {
+	n.compression.mu.Lock()
+	defer n.compression.mu.Unlock()
+
+	policy := n.compression.policy
+	if policy.Algorithm == "" || policy.Algorithm == CompressionNone || n.compression.refused[address] {
+		return ""
+	}
+	if !bulkMethods[method] {
+		message, ok := req.(proto.Message)
+		if policy.MinSize <= 0 || !ok || proto.Size(message) < policy.MinSize {
+			return ""
+		}
+	}
+	return string(policy.Algorithm)
+}
+
+// refuseCompression sends calls to address uncompressed from now on
+func (n *Node) refuseCompression(address string) {
+	n.compression.mu.Lock()
+	defer n.compression.mu.Unlock()
+
+	if n.compression.refused == nil {
+		n.compression.refused = make(map[string]bool)
+	}
+	n.compression.refused[address] = true
+}
+
+// compressionMiddleware compresses outgoing calls by the node's policy. A
+// unary call a peer cannot decompress is retried uncompressed, and the peer
+// is sent uncompressed calls from then on.
+func (n *Node) compressionMiddleware() Middleware {
+	return Middleware{
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			name := n.compressorFor(cc.Target(), method, req)
+			if name == "" {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			err := invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(name))...)
+			if isCompressionRefused(err) {
+				n.refuseCompression(cc.Target())
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			n.compression.compressed.Add(1)
+			return err
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			// The request of a stream is sent after it opens, so only bulk
+			// methods are compressed
+			if name := n.compressorFor(cc.Target(), method, nil); name != "" {
+				n.compression.compressed.Add(1)
+				opts = append(opts, grpc.UseCompressor(name))
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		},
+	}
+}
+
+// isCompressionRefused reports whether err is a peer's refusal of a
+// compressed message
+func isCompressionRefused(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Code() == codes.Unimplemented && strings.Contains(s.Message(), "grpc-encoding")
+}
+
+// compressionCounter counts the payload and wire bytes of a node's
+// outgoing connections
+type compressionCounter struct {
+	node *Node
+}
+
+// TagRPC returns ctx
+func (c compressionCounter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC counts the bytes of every message
+func (c compressionCounter) HandleRPC(_ context.Context, s stats.RPCStats) {
+	var payload, wire int
+	switch s := s.(type) {
+	case *stats.OutPayload:
+		payload, wire = s.Length, s.WireLength
+	case *stats.InPayload:
+		payload, wire = s.Length, s.WireLength
+	default:
+		return
+	}
+	c.node.compression.payloadBytes.Add(int64(payload))
+	c.node.compression.wireBytes.Add(int64(wire))
+	instruments := c.node.instruments.Load()
+	instruments.payloadBytes.Add(int64(payload))
+	instruments.wireBytes.Add(int64(wire))
+}
+
+// TagConn returns ctx
+func (c compressionCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn ignores connection events
+func (c compressionCounter) HandleConn(context.Context, stats.ConnStats) {}
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor is the gRPC compressor of CompressionZstd. Encoders and
+// decoders are pooled; they are costly to create.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+// Name returns the grpc-encoding of zstd
+func (c *zstdCompressor) Name() string {
+	return string(CompressionZstd)
+}
+
+// Compress returns a writer compressing to w
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	encoder, ok := c.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		encoder, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		encoder.Reset(w)
+	}
+	return &zstdWriter{Encoder: encoder, pool: &c.encoders}, nil
+}
+
+// Decompress returns a reader decompressing r
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	decoder, ok := c.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		decoder, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else if err := decoder.Reset(r); err != nil {
+		c.decoders.Put(decoder)
+		return nil, err
+	}
+	return &zstdReader{Decoder: decoder, pool: &c.decoders}, nil
+}
+
+// zstdWriter returns its encoder to the pool once closed
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+// Close flushes the compressed message
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+// zstdReader returns its decoder to the pool once the message is read
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+// Read decompresses from the message
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+	count, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+	return count, err
+}
@@ -0,0 +1,139 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startCompressionPair starts two nodes, each a ring of its own, so the
+// second owns every key the first sends it
+func startCompressionPair(t *testing.T, basePort int, middleware Middleware) (*Node, pb.ChordServiceClient) {
+	nodes := make([]*Node, 2)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", basePort+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		nodes[i].Use(middleware)
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+		if err := nodes[i].Join(""); err != nil {
+			t.Fatalf("Failed to create ring %d: %v", i, err)
+		}
+	}
+
+	conn, err := nodes[0].ClientConn(nodes[1].GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return nodes[0], pb.NewChordServiceClient(conn)
+}
+
+// compressibleBatch returns a batch of items with repetitive values
+func compressibleBatch(count int) *pb.PutBatchRequest {
+	req := &pb.PutBatchRequest{}
+	for i := 0; i < count; i++ {
+		req.Items = append(req.Items, &pb.KeyValue{
+			Key:   fmt.Sprintf("key-%d", i),
+			Value: []byte(strings.Repeat("chord ", 100)),
+		})
+	}
+	return req
+}
+
+func TestCompression(t *testing.T) {
+	for i, algorithm := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			node, client := startCompressionPair(t, 8510+2*i, Middleware{})
+			sink := metrics.NewMemory()
+			node.SetMetrics(sink)
+			node.SetCompression(CompressionPolicy{Algorithm: algorithm})
+
+			if _, err := client.PutBatch(context.Background(), compressibleBatch(50)); err != nil {
+				t.Fatalf("Compressed batch put failed: %v", err)
+			}
+			resp, err := client.GetBatch(context.Background(), &pb.GetBatchRequest{Keys: []string{"key-1"}})
+			if err != nil || len(resp.Items) != 1 || string(resp.Items[0].Value) != strings.Repeat("chord ", 100) {
+				t.Fatalf("Compressed batch get returned %v, %v", resp, err)
+			}
+
+			stats := node.CompressionStats()
+			if stats.Algorithm != algorithm || stats.Compressed != 2 {
+				t.Errorf("Expected two calls compressed with %s: %+v", algorithm, stats)
+			}
+			if stats.WireBytes*4 > stats.PayloadBytes {
+				t.Errorf("Expected repetitive values to shrink on the wire: %+v", stats)
+			}
+			if sink.CounterValue(metrics.PayloadBytes) == 0 || sink.CounterValue(metrics.WireBytes) == 0 {
+				t.Error("Expected the byte counts in the metrics sink")
+			}
+		})
+	}
+}
+
+func TestCompressionMinSize(t *testing.T) {
+	node, client := startCompressionPair(t, 8514, Middleware{})
+	node.SetCompression(CompressionPolicy{Algorithm: CompressionZstd, MinSize: 1024})
+
+	if _, err := client.Put(context.Background(), &pb.PutRequest{Key: "small", Value: []byte("v")}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got := node.CompressionStats().Compressed; got != 0 {
+		t.Errorf("Expected a small put to be sent uncompressed, %d calls were compressed", got)
+	}
+	if _, err := client.Put(context.Background(), &pb.PutRequest{Key: "large", Value: []byte(strings.Repeat("v", 2048))}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got := node.CompressionStats().Compressed; got != 1 {
+		t.Errorf("Expected a large put to be compressed, %d calls were compressed", got)
+	}
+}
+
+func TestCompressionRefused(t *testing.T) {
+	// The node called answers the first batch as a node without the
+	// decompressor would
+	var refusals atomic.Int64
+	refuse := Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if info.FullMethod == pb.ChordService_PutBatch_FullMethodName && refusals.Add(1) == 1 {
+				return nil, status.Error(codes.Unimplemented, `grpc: Decompressor is not installed for grpc-encoding "zstd"`)
+			}
+			return handler(ctx, req)
+		},
+	}
+
+	node, client := startCompressionPair(t, 8516, refuse)
+	node.SetCompression(CompressionPolicy{Algorithm: CompressionZstd})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.PutBatch(context.Background(), compressibleBatch(5)); err != nil {
+			t.Fatalf("Batch put %d failed: %v", i, err)
+		}
+	}
+	if got := node.CompressionStats().Compressed; got != 0 {
+		t.Errorf("Expected calls to the refusing node to be sent uncompressed, %d were compressed", got)
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for name, want := range map[string]Compression{"": CompressionNone, "none": CompressionNone, "gzip": CompressionGzip, "zstd": CompressionZstd} {
+		got, err := ParseCompression(name)
+		if err != nil || got != want {
+			t.Errorf("ParseCompression(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseCompression("lz4"); err == nil {
+		t.Error("Expected an unknown compression to be rejected")
+	}
+}
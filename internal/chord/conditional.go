@@ -0,0 +1,209 @@
+package chord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConditionalWrite describes a write that the key's owner applies only if
+// the key's current value matches Expected
+type ConditionalWrite struct {
+	Key   string
+	Value []byte
+	// Expected is the required current value. A nil Expected requires the
+	// key to be absent (never written, deleted or expired).
+	Expected []byte
+	// TTL makes the written value expire; zero means it never expires
+	TTL time.Duration
+	// Delete removes the key instead of writing Value
+	Delete bool
+}
+
+// ConditionalResult is the outcome of a conditional write
+type ConditionalResult struct {
+	Applied bool
+	// Current is the value found when the write was not applied
+	Current []byte
+	// Version increases on every write to the key, including deletes, and
+	// survives expiry, so it can be used as a fencing token
+	Version uint64
+}
+
+// CompareAndSwap applies a conditional write on the node responsible for
+// the key
+func (n *Node) CompareAndSwap(ctx context.Context, w ConditionalWrite) (*ConditionalResult, error) {
+	owner, err := n.findSuccessor(ctx, n.KeyID(w.Key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner of key %q: %w", w.Key, err)
+	}
+
+	result, err := n.conditionalPutAt(ctx, owner.Address, w)
+	if hint := ownerHint(err); hint != nil && hint.Address != owner.Address {
+		// Follow the responsible node hint once
+		result, err = n.conditionalPutAt(ctx, hint.Address, w)
+	}
+	return result, err
+}
+
+// conditionalPutAt applies a conditional write on the node at address,
+// short-circuiting locally
+func (n *Node) conditionalPutAt(ctx context.Context, address string, w ConditionalWrite) (*ConditionalResult, error) {
+	if address == n.address {
+		result, err := n.applyConditional(w)
+		if err == nil && result.Applied {
+			n.replicateKeys(ctx, []string{w.Key})
+			n.invalidateKeys(ctx, []string{w.Key})
+		}
+		return result, err
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.ConditionalPut(ctx, &pb.ConditionalPutRequest{
+		Key:          w.Key,
+		Value:        w.Value,
+		Expected:     w.Expected,
+		ExpectAbsent: w.Expected == nil,
+		TtlMs:        w.TTL.Milliseconds(),
+		Delete:       w.Delete,
+	})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("conditional put to %s failed: %s", address, resp.Error)
+	}
+
+	return &ConditionalResult{
+		Applied: resp.Applied,
+		Current: resp.Current,
+		Version: resp.Version,
+	}, nil
+}
+
+// applyConditional applies a conditional write to the local store
+func (n *Node) applyConditional(w ConditionalWrite) (*ConditionalResult, error) {
+	// A conditional write cannot be buffered: its outcome depends on the
+	// value in the ring
+	if n.Isolated() {
+		return nil, fmt.Errorf("%w: conditional write to %q", ErrIsolated, w.Key)
+	}
+
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	if err := n.checkOwned(w.Key, true); err != nil {
+		return nil, err
+	}
+
+	e, exists, err := n.storage.Get(w.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", w.Key, err)
+	}
+	present := exists && e.Live(time.Now())
+
+	var matches bool
+	if w.Expected == nil {
+		matches = !present
+	} else {
+		matches = present && bytes.Equal(e.Value, w.Expected)
+	}
+
+	if !matches {
+		result := &ConditionalResult{Applied: false}
+		if exists {
+			result.Version = e.Version
+			if present {
+				result.Current = e.Value
+			}
+		}
+		return result, nil
+	}
+
+	if w.Delete {
+		if !exists {
+			return &ConditionalResult{Applied: true}, nil
+		}
+		if present {
+			n.trash.keep(w.Key, e)
+		}
+		// Leave a tombstone rather than removing the entry so that replicas
+		// holding the old value do not bring it back, and the version keeps
+		// growing across delete/write cycles until the tombstone is reaped,
+		// or for good under LockKeyPrefix (see tombstones.go). It also
+		// expires, for nodes that predate tombstones.
+		now := time.Now()
+		e.Value = nil
+		e.Version++
+		e.ExpiresAt = now
+		e.DeletedAt = now
+		if err := n.storage.Put(w.Key, e); err != nil {
+			return nil, fmt.Errorf("failed to write %q: %w", w.Key, err)
+		}
+		return &ConditionalResult{Applied: true, Version: e.Version}, nil
+	}
+
+	batch := []*pb.KeyValue{{Key: w.Key, Value: w.Value}}
+	if err := n.checkBucketQuotas(batch); err != nil {
+		return nil, err
+	}
+	if err := n.reserveStorage(batch, true); err != nil {
+		return nil, err
+	}
+	e, err = n.writeLocked(w.Key, w.Value, w.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionalResult{Applied: true, Version: e.Version}, nil
+}
+
+// ConditionalPut applies a conditional write to a key owned by this node
+func (n *Node) ConditionalPut(ctx context.Context, req *pb.ConditionalPutRequest) (*pb.ConditionalPutResponse, error) {
+	n.countMessage()
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+	if err := n.checkResponsible([]string{req.Key}, true); err != nil {
+		return nil, toStatus(err)
+	}
+
+	w := ConditionalWrite{
+		Key:    req.Key,
+		Value:  req.Value,
+		TTL:    time.Duration(req.TtlMs) * time.Millisecond,
+		Delete: req.Delete,
+	}
+	if !req.ExpectAbsent {
+		// A non-nil slice distinguishes "expect empty value" from "expect absent"
+		w.Expected = append([]byte{}, req.Expected...)
+	}
+
+	result, err := n.applyConditional(w)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if result.Applied {
+		n.replicateKeys(ctx, []string{w.Key})
+		n.invalidateKeys(ctx, []string{w.Key})
+	}
+	return &pb.ConditionalPutResponse{
+		Applied: result.Applied,
+		Current: result.Current,
+		Version: result.Version,
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultConvergedRounds is the number of quiet stabilization rounds in a
+// row after which a ring is taken to have converged
+const DefaultConvergedRounds = 2
+
+// convergencePoll is how often WaitConverged checks the nodes
+const convergencePoll = 100 * time.Millisecond
+
+// Converged reports whether every node has run the given number of
+// stabilization rounds in a row without a change of successor or
+// predecessor. Nil nodes, such as stopped ones, are skipped.
+func Converged(nodes []*Node, rounds int) bool {
+	live := 0
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		live++
+		if node.StabilizationStatus().QuietRounds < int64(rounds) {
+			return false
+		}
+	}
+	return live > 0
+}
+
+// WaitConverged waits until the nodes have converged (see Converged), or
+// until ctx is done. The error names the nodes that had not.
+func WaitConverged(ctx context.Context, nodes []*Node, rounds int) error {
+	ticker := time.NewTicker(convergencePoll)
+	defer ticker.Stop()
+
+	for !Converged(nodes, rounds) {
+		select {
+		case <-ctx.Done():
+			var unsettled []string
+			for _, node := range nodes {
+				if node != nil && node.StabilizationStatus().QuietRounds < int64(rounds) {
+					unsettled = append(unsettled, node.GetID().Short())
+				}
+			}
+			return fmt.Errorf("%w: %d nodes still changing neighbors %v: %v", ErrRingUnstable, len(unsettled), unsettled, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
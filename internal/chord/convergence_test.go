@@ -0,0 +1,67 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// stabilizeRounds runs stabilization rounds on every node as the
+// maintenance loop would
+func stabilizeRounds(nodes []*Node, rounds int) {
+	for round := 0; round < rounds; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+			node.stabilized()
+		}
+	}
+}
+
+func TestConverged(t *testing.T) {
+	nodes := startTestRing(t, 8550, 3)
+	if Converged(nodes, DefaultConvergedRounds) {
+		t.Error("Expected a ring that just formed not to have converged")
+	}
+	stabilizeRounds(nodes, DefaultConvergedRounds+1)
+	if !Converged(nodes, DefaultConvergedRounds) {
+		for _, node := range nodes {
+			t.Logf("%s: %+v", node.GetID().Short(), node.StabilizationStatus())
+		}
+		t.Fatal("Expected the ring to have converged")
+	}
+	if err := WaitConverged(context.Background(), nodes, DefaultConvergedRounds); err != nil {
+		t.Errorf("WaitConverged failed on a converged ring: %v", err)
+	}
+
+	// A join changes the neighbors of some nodes, which start over
+	addr := "localhost:8553"
+	joiner := NewNode(addr, hash.NewHashFromString(addr))
+	if err := joiner.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(joiner.Stop)
+	if err := joiner.Join(nodes[0].GetAddress()); err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	nodes = append(nodes, joiner)
+	stabilizeRounds(nodes, 1)
+	if Converged(nodes, DefaultConvergedRounds) {
+		t.Error("Expected a join to restart convergence")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := WaitConverged(ctx, nodes, DefaultConvergedRounds); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected ErrRingUnstable while nodes change neighbors, got %v", err)
+	}
+
+	stabilizeRounds(nodes, len(nodes)+DefaultConvergedRounds)
+	if !Converged(nodes, DefaultConvergedRounds) {
+		t.Error("Expected the ring to converge again")
+	}
+	if Converged([]*Node{nil}, 1) {
+		t.Error("Expected no live nodes not to count as converged")
+	}
+}
@@ -0,0 +1,285 @@
+package chord
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/crdt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IncrCounter adds delta, which may be negative, to the counter under key
+// and returns the counter's value on the key's owner. Updates are applied
+// by the owner on its own behalf, so no read is needed before writing.
+func (n *Node) IncrCounter(ctx context.Context, key string, delta int64) (int64, error) {
+	v, err := n.updateCRDT(ctx, &pb.UpdateCRDTRequest{Key: key, Type: crdt.TypeCounter, Delta: delta})
+	if err != nil {
+		return 0, err
+	}
+	return v.Counter.Value(), nil
+}
+
+// ReadCounter returns the value of the counter under key, merged from the
+// owner and the replicas that answer. A counter never written is 0.
+func (n *Node) ReadCounter(ctx context.Context, key string) (int64, error) {
+	v, err := n.readCRDT(ctx, key, crdt.TypeCounter)
+	if err != nil {
+		return 0, err
+	}
+	return v.Counter.Value(), nil
+}
+
+// AddToSet adds elements to the set under key. An add concurrent with a
+// remove of the same element wins.
+func (n *Node) AddToSet(ctx context.Context, key string, elements ...string) error {
+	_, err := n.updateCRDT(ctx, &pb.UpdateCRDTRequest{Key: key, Type: crdt.TypeSet, Add: elements})
+	return err
+}
+
+// RemoveFromSet removes elements from the set under key; elements not in
+// the set are ignored
+func (n *Node) RemoveFromSet(ctx context.Context, key string, elements ...string) error {
+	_, err := n.updateCRDT(ctx, &pb.UpdateCRDTRequest{Key: key, Type: crdt.TypeSet, Remove: elements})
+	return err
+}
+
+// ReadSet returns the elements of the set under key, sorted, merged from
+// the owner and the replicas that answer. A set never written is empty.
+func (n *Node) ReadSet(ctx context.Context, key string) ([]string, error) {
+	v, err := n.readCRDT(ctx, key, crdt.TypeSet)
+	if err != nil {
+		return nil, err
+	}
+	return v.Set.Elements(), nil
+}
+
+// updateCRDT applies an update on the node responsible for the key. It is
+// not retried, since an update that reached the owner before failing would
+// be applied twice.
+func (n *Node) updateCRDT(ctx context.Context, req *pb.UpdateCRDTRequest) (*crdt.Value, error) {
+	owner, err := n.findSuccessor(ctx, n.KeyID(req.Key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner of key %q: %w", req.Key, err)
+	}
+
+	state, err := n.updateCRDTAt(ctx, owner.Address, req)
+	if hint := ownerHint(err); hint != nil && hint.Address != owner.Address {
+		// Follow the responsible node hint once
+		state, err = n.updateCRDTAt(ctx, hint.Address, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return crdt.Decode(state)
+}
+
+// updateCRDTAt applies an update on the node at address, short-circuiting
+// locally, and returns the encoded state after it
+func (n *Node) updateCRDTAt(ctx context.Context, address string, req *pb.UpdateCRDTRequest) ([]byte, error) {
+	if address == n.address {
+		state, err := n.applyCRDT(req)
+		if err != nil {
+			return nil, err
+		}
+		n.replicateKeys(ctx, []string{req.Key})
+		n.invalidateKeys(ctx, []string{req.Key})
+		return state, nil
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.UpdateCRDT(ctx, req)
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("update of %q on %s failed: %s", req.Key, address, resp.Error)
+	}
+	return resp.State, nil
+}
+
+// applyCRDT applies an update to the local store and returns the encoded
+// state after it. Elements are added with tags of this node, and counters
+// counted on its behalf.
+func (n *Node) applyCRDT(req *pb.UpdateCRDTRequest) ([]byte, error) {
+	// Like a conditional write, an update is not buffered: its outcome
+	// depends on the state in the ring
+	if n.Isolated() {
+		return nil, fmt.Errorf("%w: update of %q", ErrIsolated, req.Key)
+	}
+
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	if err := n.checkOwned(req.Key, true); err != nil {
+		return nil, err
+	}
+
+	e, exists, err := n.storage.Get(req.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", req.Key, err)
+	}
+	var v *crdt.Value
+	if exists && e.Live(time.Now()) {
+		v, err = crdt.Decode(e.Value)
+	} else {
+		v, err = crdt.New(req.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrTypeMismatch, req.Key, err)
+	}
+	if err := v.Check(req.Type); err != nil {
+		return nil, fmt.Errorf("key %q: %w", req.Key, err)
+	}
+
+	actor := n.id.String()
+	switch req.Type {
+	case crdt.TypeCounter:
+		v.Counter.Add(actor, req.Delta)
+	case crdt.TypeSet:
+		for _, element := range req.Add {
+			v.Set.Add(element, actor+":"+newElementTag())
+		}
+		for _, element := range req.Remove {
+			v.Set.Remove(element)
+		}
+	}
+	if len(req.Merge) > 0 {
+		other, err := crdt.Decode(req.Merge)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.Merge(other); err != nil {
+			return nil, fmt.Errorf("key %q: %w", req.Key, err)
+		}
+	}
+
+	state, err := v.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %q: %w", req.Key, err)
+	}
+	if err := n.reserveStorage([]*pb.KeyValue{{Key: req.Key, Value: state}}, true); err != nil {
+		return nil, err
+	}
+	if _, err := n.writeLocked(req.Key, state, 0); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// newElementTag returns a random tag for an add to a set
+func newElementTag() string {
+	tag := make([]byte, 8)
+	if _, err := rand.Read(tag); err != nil {
+		panic(fmt.Sprintf("chord: failed to generate element tag: %v", err))
+	}
+	return hex.EncodeToString(tag)
+}
+
+// readCRDT reads the value under key from its owner and replicas and merges
+// the copies that answer. With ConsistencyStrong only the owner is read.
+// When the merged state holds updates the owner's copy lacks, such as ones
+// applied by a previous owner, they are merged into it.
+func (n *Node) readCRDT(ctx context.Context, key, typ string) (*crdt.Value, error) {
+	merged, err := crdt.New(typ)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := n.findSuccessor(ctx, n.KeyID(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner of key %q: %w", key, err)
+	}
+	targets := append([]*NodeInfo{owner}, n.replicaCandidates(owner)...)
+	if requestConsistency(ctx) == ConsistencyStrong {
+		targets = targets[:1]
+	}
+
+	var (
+		ownerState []byte
+		found      bool
+		reached    int
+		firstErr   error
+	)
+	for i, target := range targets {
+		isOwner := i == 0
+		value, ok, err := n.getAt(ctx, target.Address, key, !isOwner)
+		if hint := ownerHint(err); isOwner && hint != nil && hint.Address != owner.Address {
+			// Follow the responsible node hint once
+			owner = hint
+			value, ok, err = n.getAt(ctx, hint.Address, key, false)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		reached++
+		if !ok {
+			continue
+		}
+
+		v, err := crdt.Decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrTypeMismatch, key, err)
+		}
+		if err := merged.Merge(v); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		if isOwner {
+			ownerState = value
+		}
+		found = true
+	}
+	if reached == 0 {
+		return nil, firstErr
+	}
+
+	if found {
+		state, err := merged.Encode()
+		if err == nil && !bytes.Equal(state, ownerState) {
+			_, err = n.updateCRDTAt(ctx, owner.Address, &pb.UpdateCRDTRequest{Key: key, Type: typ, Merge: state})
+		}
+		if err != nil {
+			log.Printf("Node %s: failed to repair %q on %s: %v", n.id.Short(), key, owner.Address, err)
+		}
+	}
+	return merged, nil
+}
+
+// UpdateCRDT applies an update to a replicated data type this node owns
+func (n *Node) UpdateCRDT(ctx context.Context, req *pb.UpdateCRDTRequest) (*pb.UpdateCRDTResponse, error) {
+	n.countMessage()
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+	if _, err := crdt.New(req.Type); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := n.checkResponsible([]string{req.Key}, true); err != nil {
+		return nil, toStatus(err)
+	}
+
+	state, err := n.applyCRDT(req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	n.replicateKeys(ctx, []string{req.Key})
+	n.invalidateKeys(ctx, []string{req.Key})
+	return &pb.UpdateCRDTResponse{State: state, Success: true}, nil
+}
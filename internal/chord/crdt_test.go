@@ -0,0 +1,116 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"chord-dht/internal/crdt"
+)
+
+func TestCRDT(t *testing.T) {
+	nodes := startTestRing(t, 8575, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+	ctx := context.Background()
+
+	for i, node := range nodes {
+		if _, err := node.IncrCounter(ctx, "visits", int64(i+1)); err != nil {
+			t.Fatalf("IncrCounter failed: %v", err)
+		}
+	}
+	value, err := nodes[0].IncrCounter(ctx, "visits", -2)
+	if err != nil {
+		t.Fatalf("IncrCounter failed: %v", err)
+	}
+	if value != 4 {
+		t.Errorf("Expected 4, got %d", value)
+	}
+	for _, node := range nodes {
+		if got, err := node.ReadCounter(ctx, "visits"); err != nil || got != 4 {
+			t.Errorf("Expected 4, got %d, %v", got, err)
+		}
+	}
+	if got, err := nodes[0].ReadCounter(ctx, "unset"); err != nil || got != 0 {
+		t.Errorf("Expected an unset counter to be 0, got %d, %v", got, err)
+	}
+
+	if err := nodes[0].AddToSet(ctx, "members", "a", "b"); err != nil {
+		t.Fatalf("AddToSet failed: %v", err)
+	}
+	if err := nodes[1].AddToSet(ctx, "members", "c"); err != nil {
+		t.Fatalf("AddToSet failed: %v", err)
+	}
+	if err := nodes[2].RemoveFromSet(ctx, "members", "a", "missing"); err != nil {
+		t.Fatalf("RemoveFromSet failed: %v", err)
+	}
+	if got, err := nodes[0].ReadSet(ctx, "members"); err != nil || !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("Expected [b c], got %v, %v", got, err)
+	}
+
+	if err := nodes[0].AddToSet(ctx, "visits", "x"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch adding to a counter, got %v", err)
+	}
+	if _, err := nodes[1].ReadSet(ctx, "visits"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch reading a counter as a set, got %v", err)
+	}
+}
+
+func TestCRDTMergeOnRead(t *testing.T) {
+	nodes := startTestRing(t, 8578, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+	ctx := context.Background()
+
+	key := "merged"
+	if err := nodes[0].AddToSet(ctx, key, "a"); err != nil {
+		t.Fatalf("AddToSet failed: %v", err)
+	}
+	info, err := nodes[0].LookupContext(ctx, nodes[0].KeyID(key))
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	var owner, replica *Node
+	for _, node := range nodes {
+		if node.GetAddress() == info.Address {
+			owner = node
+		}
+	}
+	for _, node := range nodes {
+		if node.GetAddress() == owner.GetSuccessor().Address {
+			replica = node
+		}
+	}
+
+	// Give the replica an add the owner never saw, as if applied by a
+	// previous owner
+	e, ok, err := replica.storage.Get(key)
+	if err != nil || !ok {
+		t.Fatalf("Expected the replica to hold %q, got %v", key, err)
+	}
+	diverged, err := crdt.Decode(e.Value)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	diverged.Set.Add("b", "previous-owner")
+	e.Value, _ = diverged.Encode()
+	if err := replica.storage.Put(key, e); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if got, err := nodes[0].ReadSet(ctx, key); err != nil || !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("Expected [a b] merged from the replica, got %v, %v", got, err)
+	}
+	// The read repaired the owner
+	e, _, _ = owner.storage.Get(key)
+	repaired, err := crdt.Decode(e.Value)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !repaired.Set.Contains("b") {
+		t.Error("Expected the owner to be repaired with the replica's add")
+	}
+}
@@ -0,0 +1,157 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// DensityEstimate is a node's estimate of how many keys fall in each unit of
+// keyspace. With uniformly hashed keys every node reports roughly the same
+// density; a skewed application key distribution shows up as outliers.
+type DensityEstimate struct {
+	Node        *NodeInfo
+	Predecessor *NodeInfo
+	// Keys is the number of live keys stored in (predecessor, node]
+	Keys int64
+	// ArcFraction is the share of the keyspace in (predecessor, node]
+	ArcFraction float64
+	// Density is Keys / ArcFraction, i.e. the ring-wide key count this node
+	// would extrapolate from its own arc
+	Density float64
+	// NeighborhoodDensity pools the keys and arcs of this node and its
+	// successor list, smoothing out the noise of a single small arc
+	NeighborhoodDensity float64
+}
+
+// LocalDensity estimates the key density from this node's own data only
+func (n *Node) LocalDensity() *DensityEstimate {
+	n.mu.RLock()
+	self := n.GetNodeInfo()
+	predecessor := n.predecessor
+	alone := n.successor == nil || n.successor.Address == n.address
+	n.mu.RUnlock()
+
+	estimate := &DensityEstimate{Node: self, Predecessor: predecessor}
+	switch {
+	case predecessor == nil && alone, predecessor != nil && predecessor.ID.Equal(n.id):
+		// A node alone in the ring owns all of it
+		estimate.ArcFraction = 1
+	case predecessor == nil:
+		// The arc we own is unknown until stabilization finds a predecessor
+		return estimate
+	default:
+		estimate.ArcFraction = predecessor.ID.RingFraction(n.id)
+	}
+
+	n.dataMu.RLock()
+	now := time.Now()
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if e.Live(now) && (estimate.ArcFraction == 1 || n.KeyID(key).InRange(predecessor.ID, n.id)) {
+			estimate.Keys++
+		}
+		return true
+	})
+	n.dataMu.RUnlock()
+	if err != nil {
+		log.Printf("Node %s: failed to count keys for density: %v", n.id.Short(), err)
+	}
+
+	estimate.Density = float64(estimate.Keys) / estimate.ArcFraction
+	estimate.NeighborhoodDensity = estimate.Density
+	return estimate
+}
+
+// Density estimates the key density from this node's data and the local
+// estimates of its successors. Unreachable successors are left out.
+func (n *Node) Density(ctx context.Context) *DensityEstimate {
+	estimate := n.LocalDensity()
+
+	keys := estimate.Keys
+	arc := estimate.ArcFraction
+	for _, succ := range n.Peers(0).Successors {
+		remote, err := n.RemoteDensity(ctx, succ.Address, true)
+		if err != nil {
+			log.Printf("Node %s: failed to get density from %s: %v", n.id.Short(), succ.Address, err)
+			continue
+		}
+		keys += remote.Keys
+		arc += remote.ArcFraction
+	}
+
+	if arc > 0 {
+		estimate.NeighborhoodDensity = float64(keys) / arc
+	}
+	return estimate
+}
+
+// RemoteDensity asks the node at address for its density estimate. With
+// localOnly set the remote node does not contact its own successors.
+func (n *Node) RemoteDensity(ctx context.Context, address string, localOnly bool) (*DensityEstimate, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetDensity(ctx, &pb.GetDensityRequest{LocalOnly: localOnly})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("get density from %s failed: %s", address, resp.Error)
+	}
+	return DensityFromProto(resp)
+}
+
+// GetDensity reports this node's keyspace density estimate
+func (n *Node) GetDensity(ctx context.Context, req *pb.GetDensityRequest) (*pb.GetDensityResponse, error) {
+	n.countMessage()
+
+	var estimate *DensityEstimate
+	if req.LocalOnly {
+		estimate = n.LocalDensity()
+	} else {
+		estimate = n.Density(ctx)
+	}
+
+	resp := &pb.GetDensityResponse{
+		Node:                toProtoNode(estimate.Node),
+		KeyCount:            estimate.Keys,
+		ArcFraction:         estimate.ArcFraction,
+		Density:             estimate.Density,
+		NeighborhoodDensity: estimate.NeighborhoodDensity,
+		Success:             true,
+	}
+	if estimate.Predecessor != nil {
+		resp.Predecessor = toProtoNode(estimate.Predecessor)
+	}
+	return resp, nil
+}
+
+// DensityFromProto converts a GetDensity response into a DensityEstimate
+func DensityFromProto(resp *pb.GetDensityResponse) (*DensityEstimate, error) {
+	node, err := fromProtoNode(resp.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &DensityEstimate{
+		Node:                node,
+		Keys:                resp.KeyCount,
+		ArcFraction:         resp.ArcFraction,
+		Density:             resp.Density,
+		NeighborhoodDensity: resp.NeighborhoodDensity,
+	}
+	if resp.Predecessor != nil {
+		if estimate.Predecessor, err = fromProtoNode(resp.Predecessor); err != nil {
+			return nil, err
+		}
+	}
+	return estimate, nil
+}
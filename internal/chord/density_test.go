@@ -0,0 +1,65 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestLocalDensity(t *testing.T) {
+	nodes := startTestRing(t, 8320, 1)
+	node := nodes[0]
+
+	items := make(map[string][]byte)
+	for i := 0; i < 50; i++ {
+		items[fmt.Sprintf("key_%d", i)] = []byte("v")
+	}
+	if err := node.StoreBatch(context.Background(), items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	estimate := node.LocalDensity()
+	if estimate.ArcFraction != 1 {
+		t.Errorf("Single node should own the whole ring, got %f", estimate.ArcFraction)
+	}
+	if estimate.Keys != 50 || estimate.Density != 50 {
+		t.Errorf("Expected 50 keys at density 50, got %d at %f", estimate.Keys, estimate.Density)
+	}
+}
+
+func TestNeighborhoodDensity(t *testing.T) {
+	nodes := startTestRing(t, 8325, 3)
+
+	items := make(map[string][]byte)
+	for i := 0; i < 300; i++ {
+		items[fmt.Sprintf("key_%d", i)] = []byte("v")
+	}
+	if err := nodes[0].StoreBatch(context.Background(), items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	var arcs float64
+	var keys int64
+	for _, node := range nodes {
+		local := node.LocalDensity()
+		arcs += local.ArcFraction
+		keys += local.Keys
+	}
+	if math.Abs(arcs-1) > 1e-9 {
+		t.Errorf("Arcs should cover the ring exactly once, got %f", arcs)
+	}
+	if keys != 300 {
+		t.Errorf("Expected 300 keys across the ring, got %d", keys)
+	}
+
+	// The successor list spans the rest of the ring, so the pooled
+	// estimate is the exact total
+	estimate, err := nodes[1].RemoteDensity(context.Background(), nodes[0].GetAddress(), false)
+	if err != nil {
+		t.Fatalf("RemoteDensity failed: %v", err)
+	}
+	if math.Abs(estimate.NeighborhoodDensity-300) > 1e-6 {
+		t.Errorf("Expected neighborhood density 300, got %f", estimate.NeighborhoodDensity)
+	}
+}
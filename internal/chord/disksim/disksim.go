@@ -0,0 +1,150 @@
+// Package disksim wraps a node's Storage in a simulated disk that adds
+// configurable read/write latency and fails a fraction of operations. It is
+// meant for tests and the simulator, to evaluate how replication and quorum
+// features behave on slow or flaky disks without real hardware.
+package disksim
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+// ErrInjected is returned by operations the simulated disk chose to fail
+var ErrInjected = errors.New("simulated disk error")
+
+// Config describes the simulated disk
+type Config struct {
+	// ReadLatency is added to every Get and Range
+	ReadLatency time.Duration
+	// WriteLatency is added to every Put and Delete
+	WriteLatency time.Duration
+	// Jitter adds up to this much uniformly distributed extra latency
+	Jitter time.Duration
+	// ReadErrorRate and WriteErrorRate are the fractions of operations,
+	// between 0 and 1, that fail with ErrInjected
+	ReadErrorRate  float64
+	WriteErrorRate float64
+	// Seed seeds the random source; zero uses the current time
+	Seed int64
+}
+
+// Stats counts the operations seen by a simulated disk
+type Stats struct {
+	Reads       int64
+	Writes      int64
+	ReadErrors  int64
+	WriteErrors int64
+	// Delay is the total latency injected so far
+	Delay time.Duration
+}
+
+// Storage is a chord.Storage with simulated disk behaviour
+type Storage struct {
+	inner chord.Storage
+
+	mu    sync.Mutex
+	cfg   Config
+	rng   *rand.Rand
+	stats Stats
+}
+
+// Wrap puts inner behind a simulated disk configured by cfg
+func Wrap(inner chord.Storage, cfg Config) *Storage {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Storage{
+		inner: inner,
+		cfg:   cfg,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetConfig changes the disk behaviour for subsequent operations, e.g. to
+// simulate a disk that degrades during a run
+func (s *Storage) SetConfig(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = cfg
+}
+
+// Stats returns the operation counters
+func (s *Storage) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
+
+// Get returns the entry for key after the read latency
+func (s *Storage) Get(key string) (chord.Entry, bool, error) {
+	if err := s.access(false); err != nil {
+		return chord.Entry{}, false, err
+	}
+	return s.inner.Get(key)
+}
+
+// Put writes the entry for key after the write latency
+func (s *Storage) Put(key string, e chord.Entry) error {
+	if err := s.access(true); err != nil {
+		return err
+	}
+	return s.inner.Put(key, e)
+}
+
+// Delete removes the entry for key after the write latency
+func (s *Storage) Delete(key string) error {
+	if err := s.access(true); err != nil {
+		return err
+	}
+	return s.inner.Delete(key)
+}
+
+// Range iterates the entries after a single read latency
+func (s *Storage) Range(fn func(key string, e chord.Entry) bool) error {
+	if err := s.access(false); err != nil {
+		return err
+	}
+	return s.inner.Range(fn)
+}
+
+// access waits for the latency of one operation and decides whether it fails
+func (s *Storage) access(write bool) error {
+	s.mu.Lock()
+	latency, rate := s.cfg.ReadLatency, s.cfg.ReadErrorRate
+	if write {
+		latency, rate = s.cfg.WriteLatency, s.cfg.WriteErrorRate
+	}
+	if s.cfg.Jitter > 0 {
+		latency += time.Duration(s.rng.Int63n(int64(s.cfg.Jitter)))
+	}
+	failed := rate > 0 && s.rng.Float64() < rate
+
+	if write {
+		s.stats.Writes++
+		if failed {
+			s.stats.WriteErrors++
+		}
+	} else {
+		s.stats.Reads++
+		if failed {
+			s.stats.ReadErrors++
+		}
+	}
+	s.stats.Delay += latency
+	s.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if failed {
+		return ErrInjected
+	}
+	return nil
+}
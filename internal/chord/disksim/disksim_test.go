@@ -0,0 +1,62 @@
+package disksim
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+func TestLatency(t *testing.T) {
+	disk := Wrap(chord.NewMemoryStorage(), Config{
+		ReadLatency:  20 * time.Millisecond,
+		WriteLatency: 30 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if err := disk.Put("key", chord.Entry{Value: []byte("value"), Version: 1}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	e, ok, err := disk.Get("key")
+	if err != nil || !ok {
+		t.Fatalf("Get failed: %v (found %v)", err, ok)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected at least 50ms of injected latency, took %v", elapsed)
+	}
+	if string(e.Value) != "value" {
+		t.Errorf("Expected value, got %q", e.Value)
+	}
+
+	stats := disk.Stats()
+	if stats.Reads != 1 || stats.Writes != 1 || stats.Delay != 50*time.Millisecond {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	disk := Wrap(chord.NewMemoryStorage(), Config{WriteErrorRate: 1, Seed: 1})
+
+	if err := disk.Put("key", chord.Entry{Value: []byte("value")}); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected ErrInjected, got %v", err)
+	}
+	if _, ok, err := disk.Get("key"); err != nil || ok {
+		t.Errorf("Failed write should not be stored (found %v, err %v)", ok, err)
+	}
+
+	// Degrade reads halfway through
+	disk.SetConfig(Config{ReadErrorRate: 0.5, Seed: 1})
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		if _, _, err := disk.Get("key"); err != nil {
+			failures++
+		}
+	}
+	if failures < 400 || failures > 600 {
+		t.Errorf("Expected about 500 read failures, got %d", failures)
+	}
+	if stats := disk.Stats(); stats.ReadErrors != int64(failures) || stats.WriteErrors != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,335 @@
+// Package envelope encrypts values on the client before they are stored in
+// the Chord DHT, so nodes only ever see ciphertext. Every value is sealed
+// with its own random data key, and the data key is wrapped with a key
+// encryption key from a Keyring. The ID of that key is stored in the sealed
+// value, so keys can be rotated: values sealed with an old key still open,
+// and Rotate re-wraps their data key with the primary key without
+// re-encrypting the value.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"chord-dht/internal/chord"
+)
+
+const (
+	// KeySize is the size of key encryption keys and data keys (AES-256)
+	KeySize = 32
+	// version is the first byte of a sealed value
+	version = 1
+	// maxKeyID bounds the length of key IDs, which are stored with a
+	// one-byte length
+	maxKeyID = 255
+)
+
+var (
+	// ErrUnknownKey is returned when opening a value sealed with a key the
+	// keyring does not hold
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrMalformed is returned for values that were not sealed by this
+	// package, or were truncated
+	ErrMalformed = errors.New("malformed sealed value")
+	// ErrNoPrimaryKey is returned when sealing with an empty keyring
+	ErrNoPrimaryKey = errors.New("keyring has no primary key")
+)
+
+// Keyring holds key encryption keys by ID. New values are sealed with the
+// primary key; any key held opens the values sealed with it.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// NewKeyring creates an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]cipher.AEAD)}
+}
+
+// Add adds a KeySize-byte key under id. The first key added becomes the
+// primary one.
+func (k *Keyring) Add(id string, key []byte) error {
+	if id == "" || len(id) > maxKeyID {
+		return fmt.Errorf("key id must have 1 to %d bytes, got %d", maxKeyID, len(id))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", id, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("key %q already in keyring", id)
+	}
+	k.keys[id] = aead
+	if k.primary == "" {
+		k.primary = id
+	}
+	return nil
+}
+
+// SetPrimary makes the key with id seal new values
+func (k *Keyring) SetPrimary(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	k.primary = id
+	return nil
+}
+
+// Primary returns the ID of the key that seals new values
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.primary
+}
+
+// key returns the key with id, or the primary key for an empty id
+func (k *Keyring) key(id string) (string, cipher.AEAD, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if id == "" {
+		if k.primary == "" {
+			return "", nil, ErrNoPrimaryKey
+		}
+		id = k.primary
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return id, aead, nil
+}
+
+// NewKey returns a random KeySize-byte key
+func NewKey() []byte {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("envelope: failed to generate key: %v", err))
+	}
+	return key
+}
+
+// newAEAD returns AES-GCM with key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must have %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealed is the layout of a sealed value:
+//
+//	version | len(keyID) | keyID | wrapped data key | nonce | ciphertext
+//
+// The wrapped data key is a nonce followed by the data key sealed with the
+// key encryption key. Both seals authenticate the DHT key, so a value
+// copied under another key does not open.
+type sealed struct {
+	keyID      string
+	wrappedKey []byte
+	payload    []byte
+}
+
+// wrappedKeySize is the size of a nonce plus a sealed data key
+const wrappedKeySize = 12 + KeySize + 16
+
+// encode serializes s
+func (s *sealed) encode() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(version)
+	buf.WriteByte(byte(len(s.keyID)))
+	buf.WriteString(s.keyID)
+	buf.Write(s.wrappedKey)
+	buf.Write(s.payload)
+	return buf.Bytes()
+}
+
+// decode parses a sealed value
+func decode(data []byte) (*sealed, error) {
+	if len(data) < 2 || data[0] != version {
+		return nil, ErrMalformed
+	}
+	idLen := int(data[1])
+	data = data[2:]
+	if idLen == 0 || len(data) < idLen+wrappedKeySize {
+		return nil, ErrMalformed
+	}
+	return &sealed{
+		keyID:      string(data[:idLen]),
+		wrappedKey: data[idLen : idLen+wrappedKeySize],
+		payload:    data[idLen+wrappedKeySize:],
+	}, nil
+}
+
+// sealWith encrypts plaintext with aead under a random nonce, which it
+// prepends
+func sealWith(aead cipher.AEAD, plaintext, aad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("envelope: failed to generate nonce: %v", err))
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad)
+}
+
+// openWith decrypts data sealed by sealWith
+func openWith(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], aad)
+}
+
+// Seal encrypts value, stored under the DHT key, with a new data key
+// wrapped by the keyring's primary key
+func Seal(keys *Keyring, key string, value []byte) ([]byte, error) {
+	keyID, kek, err := keys.key("")
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := NewKey()
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	s := &sealed{
+		keyID:      keyID,
+		wrappedKey: sealWith(kek, dataKey, []byte(key)),
+		payload:    sealWith(dek, value, []byte(key)),
+	}
+	return s.encode(), nil
+}
+
+// Open decrypts a value sealed by Seal under the DHT key
+func Open(keys *Keyring, key string, data []byte) ([]byte, error) {
+	s, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := unwrap(keys, key, s)
+	if err != nil {
+		return nil, err
+	}
+	value, err := openWith(dek, s.payload, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// unwrap returns the data key of a sealed value
+func unwrap(keys *Keyring, key string, s *sealed) (cipher.AEAD, error) {
+	_, kek, err := keys.key(s.keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := openWith(kek, s.wrappedKey, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key of %q: %w", key, err)
+	}
+	return newAEAD(dataKey)
+}
+
+// KeyID returns the ID of the key a sealed value was sealed with
+func KeyID(data []byte) (string, error) {
+	s, err := decode(data)
+	if err != nil {
+		return "", err
+	}
+	return s.keyID, nil
+}
+
+// Store reads and writes encrypted values through a Chord node
+type Store struct {
+	node *chord.Node
+	keys *Keyring
+}
+
+// New creates a Store that seals values with keys before they leave the
+// process
+func New(node *chord.Node, keys *Keyring) *Store {
+	return &Store{node: node, keys: keys}
+}
+
+// Put seals value and stores it under key
+func (s *Store) Put(ctx context.Context, key string, value []byte) error {
+	data, err := Seal(s.keys, key, value)
+	if err != nil {
+		return err
+	}
+	return s.node.StoreValue(ctx, key, data)
+}
+
+// Get fetches the value under key and opens it. It returns
+// chord.ErrKeyNotFound if the key is not stored in the ring.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.node.FetchValue(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return Open(s.keys, key, data)
+}
+
+// Rotate re-wraps the data key of the value under key with the primary key,
+// if it was sealed with another one, and reports whether it did. The value
+// itself is not re-encrypted. Once no value uses a key, it can be dropped
+// from the keyring.
+func (s *Store) Rotate(ctx context.Context, key string) (bool, error) {
+	data, err := s.node.FetchValue(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	sv, err := decode(data)
+	if err != nil {
+		return false, err
+	}
+	primary, kek, err := s.keys.key("")
+	if err != nil {
+		return false, err
+	}
+	if sv.keyID == primary {
+		return false, nil
+	}
+
+	_, oldKEK, err := s.keys.key(sv.keyID)
+	if err != nil {
+		return false, err
+	}
+	dataKey, err := openWith(oldKEK, sv.wrappedKey, []byte(key))
+	if err != nil {
+		return false, fmt.Errorf("failed to unwrap data key of %q: %w", key, err)
+	}
+	sv.keyID = primary
+	sv.wrappedKey = sealWith(kek, dataKey, []byte(key))
+
+	// Only replace the value read, so a concurrent Put is not undone
+	result, err := s.node.CompareAndSwap(ctx, chord.ConditionalWrite{
+		Key:      key,
+		Value:    sv.encode(),
+		Expected: data,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.Applied, nil
+}
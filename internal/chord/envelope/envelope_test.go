@@ -0,0 +1,132 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"chord-dht/internal/chord"
+)
+
+func newKeyring(t *testing.T, ids ...string) *Keyring {
+	keys := NewKeyring()
+	for _, id := range ids {
+		if err := keys.Add(id, NewKey()); err != nil {
+			t.Fatalf("Add(%q) failed: %v", id, err)
+		}
+	}
+	return keys
+}
+
+func TestSealOpen(t *testing.T) {
+	keys := newKeyring(t, "k1")
+
+	sealed, err := Seal(keys, "key", []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Error("Expected the sealed value not to contain the plaintext")
+	}
+	if id, err := KeyID(sealed); err != nil || id != "k1" {
+		t.Errorf("Expected key ID k1, got %q, %v", id, err)
+	}
+
+	value, err := Open(keys, "key", sealed)
+	if err != nil || string(value) != "secret" {
+		t.Fatalf("Expected to open the sealed value, got %q, %v", value, err)
+	}
+
+	if _, err := Open(keys, "other", sealed); err == nil {
+		t.Error("Expected a value copied under another key not to open")
+	}
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Open(keys, "key", tampered); err == nil {
+		t.Error("Expected a tampered value not to open")
+	}
+	if _, err := Open(keys, "key", []byte("plain")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected ErrMalformed for an unsealed value, got %v", err)
+	}
+	if _, err := Open(newKeyring(t, "k2"), "key", sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey without the key, got %v", err)
+	}
+	if _, err := Seal(NewKeyring(), "key", nil); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("Expected ErrNoPrimaryKey for an empty keyring, got %v", err)
+	}
+}
+
+func TestKeyringAdd(t *testing.T) {
+	keys := newKeyring(t, "k1")
+	if err := keys.Add("k1", NewKey()); err == nil {
+		t.Error("Expected adding a key ID twice to fail")
+	}
+	if err := keys.Add("short", make([]byte, 16)); err == nil {
+		t.Error("Expected a key of the wrong size to be rejected")
+	}
+	if err := keys.SetPrimary("missing"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestStoreRotate(t *testing.T) {
+	node := chord.NewNode("localhost:8466", nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer node.Stop()
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	ctx := context.Background()
+	keys := newKeyring(t, "k1", "k2")
+	store := New(node, keys)
+	if err := store.Put(ctx, "key", []byte("secret")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// The node only holds ciphertext
+	stored, err := node.FetchValue(ctx, "key")
+	if err != nil || bytes.Contains(stored, []byte("secret")) {
+		t.Fatalf("Expected the node to hold ciphertext, got %q, %v", stored, err)
+	}
+
+	if rotated, err := store.Rotate(ctx, "key"); err != nil || rotated {
+		t.Errorf("Expected no rotation under the primary key, got %v, %v", rotated, err)
+	}
+	if err := keys.SetPrimary("k2"); err != nil {
+		t.Fatalf("SetPrimary failed: %v", err)
+	}
+	if rotated, err := store.Rotate(ctx, "key"); err != nil || !rotated {
+		t.Fatalf("Expected the value to be rotated, got %v, %v", rotated, err)
+	}
+
+	rotated, err := node.FetchValue(ctx, "key")
+	if err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+	if id, _ := KeyID(rotated); id != "k2" {
+		t.Errorf("Expected the value to be sealed with k2, got %q", id)
+	}
+	// Only the data key was re-wrapped
+	if !bytes.HasSuffix(rotated, stored[len(stored)-len("secret")-28:]) {
+		t.Error("Expected the value ciphertext to be unchanged")
+	}
+
+	// The old key is no longer needed
+	onlyNew := NewKeyring()
+	keys.mu.RLock()
+	onlyNew.keys["k2"] = keys.keys["k2"]
+	onlyNew.primary = "k2"
+	keys.mu.RUnlock()
+	value, err := New(node, onlyNew).Get(ctx, "key")
+	if err != nil || string(value) != "secret" {
+		t.Errorf("Expected to read the rotated value with k2 only, got %q, %v", value, err)
+	}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, chord.ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package chord
+
+import (
+	"errors"
+	"fmt"
+
+	"chord-dht/internal/crdt"
+)
+
+// Sentinel errors returned by the node's Go API. RPC handlers report them as
+// gRPC status errors (see status.go) and the calling side maps them back, so
+// callers can branch with errors.Is regardless of where the error originated.
+var (
+	// ErrNotResponsible is returned when a node is asked to serve a key
+	// outside the range it owns
+	ErrNotResponsible = errors.New("node is not responsible for key")
+	// ErrRingUnstable is returned when the node has no usable ring state
+	// (e.g. it has not joined a ring yet) to route a request
+	ErrRingUnstable = errors.New("ring is not stable")
+	// ErrRangeMoving is returned for writes to a key whose range is being
+	// handed off between nodes; retrying shortly succeeds at the new owner
+	ErrRangeMoving = errors.New("key range is being handed off")
+	// ErrPeerUnreachable is returned when a remote node cannot be contacted
+	ErrPeerUnreachable = errors.New("peer unreachable")
+	// ErrKeyNotFound is returned when a key is not stored in the ring
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrQuotaExceeded is returned when a write would exceed a node's
+	// storage quota, such as a bucket's (see SetBucketQuota) or its own
+	// (see SetStorageLimits)
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrJoinThrottled is returned by a bootstrap node admitting joins
+	// faster than its join limit; RetryDelay tells when to try again
+	ErrJoinThrottled = errors.New("join throttled")
+	// ErrPaused is returned for data migrations while the node is paused
+	// for a maintenance window
+	ErrPaused = errors.New("data migrations paused for maintenance")
+	// ErrOverloaded is returned for RPCs beyond the node's rate limits;
+	// RetryDelay tells when to try again
+	ErrOverloaded = errors.New("node overloaded")
+	// ErrIsolated is returned for writes to a node that lost contact with
+	// the whole ring, unless it buffers them (see SetIsolationPolicy)
+	ErrIsolated = errors.New("node is isolated from the ring")
+	// ErrIncompatibleVersion is returned for RPCs between nodes that speak
+	// no common protocol version (see VersionError)
+	ErrIncompatibleVersion = errors.New("incompatible protocol version")
+	// ErrBehindNAT is returned by Join when the bootstrap cannot call the
+	// node back at its advertised address, such as behind NAT without a
+	// relay (see NATPolicy)
+	ErrBehindNAT = errors.New("node cannot be reached by its peers")
+	// ErrIDCollision is returned by Join when another node of the ring
+	// already has the joining node's ID, such as one given the same --id
+	ErrIDCollision = errors.New("node ID already taken")
+	// ErrWrongRing is returned for RPCs naming a ring the node, or the
+	// host serving it, does not belong to (see rings.go)
+	ErrWrongRing = errors.New("node belongs to another ring")
+	// ErrTypeMismatch is returned for an update or read of a replicated
+	// data type under a key holding another type (see crdt.go)
+	ErrTypeMismatch = crdt.ErrTypeMismatch
+)
+
+// PeerError reports a failed attempt to reach a remote node
+type PeerError struct {
+	Address string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *PeerError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrPeerUnreachable, e.Address, e.Err)
+}
+
+// Unwrap returns the underlying transport error
+func (e *PeerError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrPeerUnreachable) match a PeerError
+func (e *PeerError) Is(target error) bool {
+	return target == ErrPeerUnreachable
+}
+
+// NotResponsibleError reports a key sent to the wrong node. Owner, when
+// known, is the node the rejecting node believes is responsible for Key.
+type NotResponsibleError struct {
+	Key   string
+	Owner *NodeInfo
+}
+
+// Error implements the error interface
+func (e *NotResponsibleError) Error() string {
+	if e.Owner != nil {
+		return fmt.Sprintf("%s: %s (owner %s)", ErrNotResponsible, e.Key, e.Owner.Address)
+	}
+	return fmt.Sprintf("%s: %s", ErrNotResponsible, e.Key)
+}
+
+// Is makes errors.Is(err, ErrNotResponsible) match a NotResponsibleError
+func (e *NotResponsibleError) Is(target error) bool {
+	return target == ErrNotResponsible
+}
@@ -0,0 +1,144 @@
+package chord
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// DefaultEventBuffer is how many events a subscriber may fall behind by
+// before further events are dropped for it
+const DefaultEventBuffer = 256
+
+// Types of ring events
+const (
+	// EventTopology is a change of neighbors: a join, leave or failure
+	EventTopology = "topology"
+	// EventLookup is a lookup the node finished
+	EventLookup = "lookup"
+	// EventTransfer is a key range changing owner
+	EventTransfer = "transfer"
+)
+
+// RingEvent is something a node saw happen, as streamed live to the
+// subscribers of SubscribeEvents
+type RingEvent struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Change describes a topology change or transfer as the audit log
+	// records it
+	Change *AuditEvent `json:"change,omitempty"`
+	// Lookup describes a finished lookup
+	Lookup *LookupEvent `json:"lookup,omitempty"`
+}
+
+// LookupEvent is a lookup in the event stream
+type LookupEvent struct {
+	Key     string `json:"key"`
+	Hops    int    `json:"hops"`
+	Latency int64  `json:"latency_us"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EventSubscription receives a node's ring events until closed
+type EventSubscription struct {
+	node    *Node
+	events  chan RingEvent
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// ringEvents holds the subscribers of a node's events
+type ringEvents struct {
+	mu   sync.Mutex
+	subs map[*EventSubscription]struct{}
+	// count mirrors len(subs) so that events are not built for nobody
+	count atomic.Int32
+}
+
+// SubscribeEvents starts streaming this node's topology changes, lookups
+// and transfers. Events are never waited for: once buffer events are
+// pending, newer ones are dropped and counted. A buffer of zero or less is
+// DefaultEventBuffer.
+func (n *Node) SubscribeEvents(buffer int) *EventSubscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	sub := &EventSubscription{node: n, events: make(chan RingEvent, buffer)}
+
+	n.events.mu.Lock()
+	defer n.events.mu.Unlock()
+
+	if n.events.subs == nil {
+		n.events.subs = make(map[*EventSubscription]struct{})
+	}
+	n.events.subs[sub] = struct{}{}
+	n.events.count.Store(int32(len(n.events.subs)))
+	return sub
+}
+
+// Events returns the channel events are delivered on, closed by Close
+func (s *EventSubscription) Events() <-chan RingEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the subscriber fell
+// behind
+func (s *EventSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription
+func (s *EventSubscription) Close() {
+	s.once.Do(func() {
+		e := &s.node.events
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		delete(e.subs, s)
+		e.count.Store(int32(len(e.subs)))
+		close(s.events)
+	})
+}
+
+// publishEvent hands event to every subscriber that has room for it
+func (n *Node) publishEvent(event RingEvent) {
+	e := &n.events
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for sub := range e.subs {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// publishChange publishes a topology change or transfer recorded for the
+// audit log
+func (n *Node) publishChange(change AuditEvent) {
+	if n.events.count.Load() == 0 {
+		return
+	}
+	kind := EventTopology
+	if change.Kind == AuditTransfer {
+		kind = EventTransfer
+	}
+	n.publishEvent(RingEvent{Type: kind, Time: change.Time, Change: &change})
+}
+
+// publishLookup publishes a finished lookup
+func (n *Node) publishLookup(key *hash.Hash, hops int, latency time.Duration, err error) {
+	if n.events.count.Load() == 0 {
+		return
+	}
+	lookup := &LookupEvent{Key: key.String(), Hops: hops, Latency: latency.Microseconds()}
+	if err != nil {
+		lookup.Error = err.Error()
+	}
+	n.publishEvent(RingEvent{Type: EventLookup, Time: time.Now().UTC(), Lookup: lookup})
+}
@@ -0,0 +1,69 @@
+package chord
+
+import (
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestEventStream(t *testing.T) {
+	first := NewNode("localhost:8677", hash.NewHashFromString("localhost:8677"))
+	second := NewNode("localhost:8678", hash.NewHashFromString("localhost:8678"))
+	for _, node := range []*Node{first, second} {
+		if err := node.Start(); err != nil {
+			t.Fatalf("Failed to start node: %v", err)
+		}
+		t.Cleanup(node.Stop)
+	}
+	if err := first.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	sub := second.SubscribeEvents(0)
+	defer sub.Close()
+	if err := second.Join(first.GetAddress()); err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	if _, err := second.Lookup(hash.NewHashFromString("key")); err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+
+	// Joining takes the range over from the successor and routes a lookup
+	seen := make(map[string]bool)
+	timeout := time.After(2 * time.Second)
+	for !seen[EventTopology] || !seen[EventTransfer] || !seen[EventLookup] {
+		select {
+		case event := <-sub.Events():
+			seen[event.Type] = true
+			switch event.Type {
+			case EventTopology, EventTransfer:
+				if event.Change == nil || event.Change.Node.Address != second.GetAddress() {
+					t.Errorf("Unexpected change in %+v", event)
+				}
+			case EventLookup:
+				if event.Lookup == nil || event.Lookup.Key != hash.NewHashFromString("key").String() {
+					t.Errorf("Unexpected lookup in %+v", event)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("Missing events, saw %v", seen)
+		}
+	}
+
+	// A subscriber that falls behind loses events instead of blocking
+	slow := second.SubscribeEvents(1)
+	for i := 0; i < 3; i++ {
+		second.Lookup(hash.NewHashFromString("key"))
+	}
+	if slow.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped events, got %d", slow.Dropped())
+	}
+	slow.Close()
+	if _, open := <-slow.Events(); !open {
+		t.Error("Expected the buffered event before the channel closed")
+	}
+	if _, open := <-slow.Events(); open {
+		t.Error("Expected the channel closed")
+	}
+}
@@ -0,0 +1,143 @@
+package chord
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// FingerCheckPolicy makes a node periodically check its finger table
+// against the true successor of every finger's start, found with a lookup.
+// The fraction of correct fingers shows how far routing state has
+// converged, as after churn.
+type FingerCheckPolicy struct {
+	// Interval is the period of the checks; zero disables them
+	Interval time.Duration
+}
+
+// FingerAccuracy is the result of a finger table check
+type FingerAccuracy struct {
+	// Checked counts the fingers whose start could be looked up, Correct
+	// those pointing at the successor the lookup returned
+	Checked int
+	Correct int
+	// Time is when the check finished, zero if none has
+	Time time.Time
+}
+
+// Fraction returns the fraction of the checked fingers that were correct,
+// 0 if none was checked
+func (a FingerAccuracy) Fraction() float64 {
+	if a.Checked == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Checked)
+}
+
+// fingerCheck is a node's finger check policy and last result
+type fingerCheck struct {
+	mu     sync.Mutex
+	policy FingerCheckPolicy
+	last   FingerAccuracy
+	// changed wakes the check loop when the policy changes
+	changed chan struct{}
+}
+
+// SetFingerCheck sets how often the node checks its finger table
+func (n *Node) SetFingerCheck(policy FingerCheckPolicy) {
+	n.fingerCheck.mu.Lock()
+	n.fingerCheck.policy = policy
+	n.fingerCheck.mu.Unlock()
+
+	select {
+	case n.fingerCheck.changed <- struct{}{}:
+	default:
+	}
+}
+
+// FingerAccuracy returns the result of the last finger table check
+func (n *Node) FingerAccuracy() FingerAccuracy {
+	n.fingerCheck.mu.Lock()
+	defer n.fingerCheck.mu.Unlock()
+
+	return n.fingerCheck.last
+}
+
+// CheckFingers looks up the successor of every finger's start and compares
+// it with the finger. Fingers whose lookup fails are not counted. The result
+// is kept for FingerAccuracy and recorded as metrics.FingerAccuracy.
+func (n *Node) CheckFingers(ctx context.Context) FingerAccuracy {
+	var accuracy FingerAccuracy
+	failed := 0
+	for i := 0; i < FingerTableSize; i++ {
+		successor, err := n.findSuccessor(ctx, hash.FingerStart(n.id, i+1))
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			failed++
+			continue
+		}
+
+		// Compared with the finger as it is now, since fix-fingers may
+		// have updated it during the lookup
+		n.mu.RLock()
+		finger := n.fingers[i]
+		n.mu.RUnlock()
+
+		accuracy.Checked++
+		if finger != nil && finger.ID.Equal(successor.ID) {
+			accuracy.Correct++
+		}
+	}
+	if failed > 0 {
+		log.Printf("Node %s: finger check could not look up %d of %d fingers", n.id.Short(), failed, FingerTableSize)
+	}
+	accuracy.Time = time.Now()
+
+	n.fingerCheck.mu.Lock()
+	n.fingerCheck.last = accuracy
+	n.fingerCheck.mu.Unlock()
+	if accuracy.Checked > 0 {
+		n.instruments.Load().fingerAccuracy.Set(accuracy.Fraction())
+	}
+	return accuracy
+}
+
+// checkFingersLoop runs CheckFingers at the policy's interval until the node
+// stops
+func (n *Node) checkFingersLoop() {
+	defer n.wg.Done()
+
+	for {
+		n.fingerCheck.mu.Lock()
+		interval := n.fingerCheck.policy.Interval
+		n.fingerCheck.mu.Unlock()
+
+		// Disabled checks wait for a policy change only
+		var tick <-chan time.Time
+		var timer *time.Timer
+		if interval > 0 {
+			timer = time.NewTimer(interval)
+			tick = timer.C
+		}
+		select {
+		case <-n.ctx.Done():
+		case <-n.fingerCheck.changed:
+		case <-tick:
+			// Checking is optional work, skipped under pressure
+			if !n.shedding(PressureElevated) {
+				n.CheckFingers(n.ctx)
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if n.ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package chord
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chord-dht/internal/metrics"
+)
+
+func TestCheckFingers(t *testing.T) {
+	nodes := startTestRing(t, 8545, 3)
+	sink := metrics.NewMemory()
+	nodes[0].SetMetrics(sink)
+
+	// Fingers still point at the node itself after joining
+	before := nodes[0].CheckFingers(context.Background())
+	if before.Checked != FingerTableSize || before.Fraction() == 1 {
+		t.Errorf("Expected stale fingers to be found: %+v", before)
+	}
+
+	for i := 0; i < FingerTableSize; i++ {
+		nodes[0].fixFingers()
+	}
+	after := nodes[0].CheckFingers(context.Background())
+	if after.Checked != FingerTableSize || after.Correct != FingerTableSize {
+		t.Errorf("Expected every finger to be correct once fixed: %+v", after)
+	}
+	if got := nodes[0].FingerAccuracy(); got != after {
+		t.Errorf("Expected the last check to be kept, got %+v for %+v", got, after)
+	}
+	if sink.GaugeValue(metrics.FingerAccuracy) != 1 {
+		t.Errorf("Expected the accuracy in the metrics sink, got %v", sink.GaugeValue(metrics.FingerAccuracy))
+	}
+
+	// Enabled checks run periodically
+	nodes[1].SetFingerCheck(FingerCheckPolicy{Interval: 50 * time.Millisecond})
+	deadline := time.Now().Add(5 * time.Second)
+	for nodes[1].FingerAccuracy().Time.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a periodic finger check")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
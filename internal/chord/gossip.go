@@ -0,0 +1,233 @@
+package chord
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+const (
+	// DefaultGossipEntries is how many entries of the membership view ride
+	// on each notification besides the sender's own
+	DefaultGossipEntries = 16
+	// DefaultGossipTTL is how long a member whose heartbeat stops advancing
+	// stays in the membership view
+	DefaultGossipTTL = time.Minute
+	// maxGossipMembers bounds the membership view
+	maxGossipMembers = 4096
+)
+
+// GossipPolicy configures the gossip of node metadata and health. Gossip
+// rides on the Notify RPC of stabilization both ways: the notifier sends
+// its own entry and a random sample of its membership view, and the
+// notified node replies with the same. Each node thereby builds an
+// approximate view of the ring, for dashboards and size estimation, without
+// RPCs of its own. News travels about one ring hop per stabilization round.
+type GossipPolicy struct {
+	// Enabled turns gossip on; it is off by default
+	Enabled bool
+	// Entries bounds the entries sent with each notification besides the
+	// node's own, DefaultGossipEntries if zero
+	Entries int
+	// TTL is how long a member whose heartbeat stops advancing stays in the
+	// view, DefaultGossipTTL if zero
+	TTL time.Duration
+}
+
+// GossipMember is a node of the gossiped membership view
+type GossipMember struct {
+	Node       *NodeInfo
+	Pressure   PressureLevel
+	StoredKeys int64
+	// MaxKeys is the node's storage limit in entries, 0 if unlimited
+	MaxKeys int64
+	// Heartbeat is when the node last described itself, in its own clock
+	Heartbeat uint64
+	// Updated is when the heartbeat was first heard, by this node or by the
+	// peers it was gossiped through
+	Updated time.Time
+}
+
+// gossip is a node's membership view
+type gossip struct {
+	mu     sync.Mutex
+	policy GossipPolicy
+	// members maps addresses to the members other than this node
+	members map[string]*GossipMember
+}
+
+// SetGossip sets whether and how this node gossips node metadata and
+// health. Turning gossip off drops the membership view.
+func (n *Node) SetGossip(policy GossipPolicy) {
+	if policy.Entries <= 0 {
+		policy.Entries = DefaultGossipEntries
+	}
+	if policy.TTL <= 0 {
+		policy.TTL = DefaultGossipTTL
+	}
+
+	n.gossip.mu.Lock()
+	defer n.gossip.mu.Unlock()
+
+	n.gossip.policy = policy
+	if !policy.Enabled {
+		n.gossip.members = nil
+	}
+}
+
+// GossipMembers returns the membership view, this node included, in ID
+// order. It is empty with gossip off.
+func (n *Node) GossipMembers() []GossipMember {
+	n.gossip.mu.Lock()
+	enabled := n.gossip.policy.Enabled
+	n.gossip.mu.Unlock()
+	if !enabled {
+		return nil
+	}
+
+	self := n.gossipSelf()
+	g := &n.gossip
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.expireLocked(time.Now())
+	members := []GossipMember{self}
+	for _, member := range g.members {
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Node.ID.Less(members[j].Node.ID) })
+	return members
+}
+
+// gossipSelf describes this node as it gossips itself. It runs on both
+// sides of every notification, so the store size is the cached one.
+func (n *Node) gossipSelf() GossipMember {
+	self := GossipMember{
+		Node:      n.GetNodeInfo(),
+		Pressure:  n.Pressure().Level,
+		Heartbeat: uint64(time.Now().UnixNano()),
+		Updated:   time.Now(),
+	}
+	if usage, err := n.cachedStorageUsage(); err == nil {
+		self.StoredKeys = usage.Keys
+		self.MaxKeys = usage.Limits.MaxKeys
+	}
+	return self
+}
+
+// outgoingGossip returns the entries to send with a notification: this
+// node's own and a random sample of its view, nil with gossip off
+func (n *Node) outgoingGossip() []*pb.GossipEntry {
+	n.gossip.mu.Lock()
+	enabled := n.gossip.policy.Enabled
+	n.gossip.mu.Unlock()
+	if !enabled {
+		return nil
+	}
+
+	self := n.gossipSelf()
+	g := &n.gossip
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.expireLocked(now)
+	entries := []*pb.GossipEntry{toProtoGossip(&self, now)}
+	for _, member := range g.members {
+		entries = append(entries, toProtoGossip(member, now))
+	}
+	sample := entries[1:]
+	rand.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	if len(sample) > g.policy.Entries {
+		entries = entries[:1+g.policy.Entries]
+	}
+	return entries
+}
+
+// mergeGossip adds the entries received from the node at address to the
+// membership view, keeping the newest heartbeat of every member. Entries
+// keep the age they were gossiped with, so a heartbeat expires on every node
+// about when it does on the first, and a member already expired here is not
+// brought back by a peer still listing it.
+func (n *Node) mergeGossip(address string, entries []*pb.GossipEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	g := &n.gossip
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.policy.Enabled {
+		return
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		member, err := fromProtoGossip(entry, now)
+		if err != nil {
+			log.Printf("Node %s: ignoring gossip from %s: %v", n.id.Short(), address, err)
+			continue
+		}
+		if member.Node.Address == n.address {
+			continue
+		}
+		if now.Sub(member.Updated) > g.policy.TTL {
+			continue
+		}
+		known, ok := g.members[member.Node.Address]
+		// A node restarted under another ID replaces its old entry
+		if ok && known.Node.ID.Equal(member.Node.ID) && known.Heartbeat >= member.Heartbeat {
+			continue
+		}
+		if !ok && len(g.members) >= maxGossipMembers {
+			continue
+		}
+		if g.members == nil {
+			g.members = make(map[string]*GossipMember)
+		}
+		g.members[member.Node.Address] = member
+	}
+}
+
+// expireLocked drops the members whose heartbeat stopped advancing for the
+// TTL. Called with mu held.
+func (g *gossip) expireLocked(now time.Time) {
+	for address, member := range g.members {
+		if now.Sub(member.Updated) > g.policy.TTL {
+			delete(g.members, address)
+		}
+	}
+}
+
+// toProtoGossip converts a member to its wire representation, aged as of
+// now
+func toProtoGossip(member *GossipMember, now time.Time) *pb.GossipEntry {
+	return &pb.GossipEntry{
+		Node:       toProtoNode(member.Node),
+		Heartbeat:  member.Heartbeat,
+		Pressure:   int32(member.Pressure),
+		StoredKeys: member.StoredKeys,
+		MaxKeys:    member.MaxKeys,
+		AgeMs:      now.Sub(member.Updated).Milliseconds(),
+	}
+}
+
+// fromProtoGossip converts a gossip entry received now, dating it back by
+// its age
+func fromProtoGossip(entry *pb.GossipEntry, now time.Time) (*GossipMember, error) {
+	node, err := fromProtoNode(entry.Node)
+	if err != nil {
+		return nil, err
+	}
+	return &GossipMember{
+		Node:       node,
+		Heartbeat:  entry.Heartbeat,
+		Pressure:   PressureLevel(entry.Pressure),
+		StoredKeys: entry.StoredKeys,
+		MaxKeys:    entry.MaxKeys,
+		Updated:    now.Add(-time.Duration(max(entry.AgeMs, 0)) * time.Millisecond),
+	}, nil
+}
@@ -0,0 +1,102 @@
+package chord
+
+import (
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+func TestGossipSpreadsMetadata(t *testing.T) {
+	nodes := startTestRing(t, 8673, 4)
+	if members := nodes[0].GossipMembers(); members != nil {
+		t.Fatalf("Expected no view with gossip off, got %d members", len(members))
+	}
+	for _, node := range nodes {
+		node.SetGossip(GossipPolicy{Enabled: true, TTL: 300 * time.Millisecond})
+	}
+	nodes[2].SetNodeMetadata(NodeMetadata{Zone: "zone-b"})
+	if err := nodes[2].Storage().Put("gossiped", Entry{Value: []byte("value"), Version: 1}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	// Setting limits drops the cached store size, so the key is counted
+	nodes[2].SetStorageLimits(StorageLimits{MaxKeys: 100})
+
+	// Every round moves news about one hop around the ring
+	stabilizeAll := func(nodes []*Node, rounds int) {
+		for round := 0; round < rounds; round++ {
+			for _, node := range nodes {
+				node.stabilize()
+			}
+		}
+	}
+	stabilizeAll(nodes, len(nodes))
+	for _, node := range nodes {
+		members := node.GossipMembers()
+		if len(members) != len(nodes) {
+			t.Fatalf("Node %s sees %d members, expected %d", node.GetID().Short(), len(members), len(nodes))
+		}
+		for _, member := range members {
+			if member.Node.Address != nodes[2].GetAddress() {
+				continue
+			}
+			if member.Node.Zone != "zone-b" || member.StoredKeys != 1 || member.MaxKeys != 100 {
+				t.Errorf("Node %s sees %+v", node.GetID().Short(), member)
+			}
+		}
+		if estimate := node.EstimateRingSize(); estimate.Gossiped != len(nodes) {
+			t.Errorf("Expected %d gossiped nodes, got %+v", len(nodes), estimate)
+		}
+	}
+
+	// A stopped node's heartbeat stops advancing and it drops out of the view
+	nodes[3].Stop()
+	time.Sleep(400 * time.Millisecond)
+	stabilizeAll(nodes[:3], 2)
+	for _, node := range nodes[:3] {
+		for _, member := range node.GossipMembers() {
+			if member.Node.Address == nodes[3].GetAddress() {
+				t.Errorf("Node %s still sees the stopped node", node.GetID().Short())
+			}
+		}
+	}
+}
+
+func TestGossipDoesNotReadmitExpiredMembers(t *testing.T) {
+	node := NewNode("localhost:8679", hash.NewHashFromString("localhost:8679"))
+	node.SetGossip(GossipPolicy{Enabled: true, TTL: 100 * time.Millisecond})
+	dead := &NodeInfo{ID: hash.NewHashFromString("localhost:8680"), Address: "localhost:8680"}
+	heard := time.Now()
+	// A peer relays the member's last heartbeat as it first heard it
+	relay := func(heartbeat uint64, heard time.Time) []*pb.GossipEntry {
+		return []*pb.GossipEntry{toProtoGossip(&GossipMember{Node: dead, Heartbeat: heartbeat, Updated: heard}, time.Now())}
+	}
+	sees := func() bool {
+		for _, member := range node.GossipMembers() {
+			if member.Node.Address == dead.Address {
+				return true
+			}
+		}
+		return false
+	}
+
+	node.mergeGossip("peer", relay(1, heard))
+	if !sees() {
+		t.Fatal("Expected the gossiped member in the view")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if sees() {
+		t.Fatal("Expected the member expired")
+	}
+
+	// A peer that has not expired it yet gossips the same heartbeat
+	node.mergeGossip("peer", relay(1, heard))
+	if sees() {
+		t.Error("Expected the expired member kept out of the view")
+	}
+	node.mergeGossip("peer", relay(2, time.Now()))
+	if !sees() {
+		t.Error("Expected a newer heartbeat admitted")
+	}
+}
@@ -0,0 +1,541 @@
+package chord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// handoffTimeout is how long the old owner keeps a range frozen waiting
+	// for the new owner to commit before it aborts the hand-off
+	handoffTimeout = 2 * RPCTimeout
+	// handoffRetryDelay is how long writers are asked to wait before retrying
+	// a write to a range that is being handed off
+	handoffRetryDelay = 200 * time.Millisecond
+)
+
+// errHandoffAborted is returned when committing a hand-off the old owner no
+// longer holds open
+var errHandoffAborted = errors.New("hand-off aborted")
+
+// ownership tracks the key range a node answers for authoritatively.
+//
+// Ranges move with an explicit two-phase hand-off pulled by the joining node:
+// the old owner freezes the range for writes and returns its entries
+// (PrepareHandoff), the new owner installs them and takes ownership while
+// still frozen, then the old owner commits the switch and drops the entries
+// (CommitHandoff) and the new owner unfreezes. At every point exactly one of
+// the two may accept writes for the range, and reads see the same data on
+// both sides while it is frozen.
+type ownership struct {
+	mu sync.Mutex
+	// start is the exclusive start of the owned range (start, self]. It is
+	// the node's own ID when it owns the whole ring and nil while it owns
+	// nothing.
+	start *hash.Hash
+	// outgoing is the hand-off of the lower part of the range to a joining
+	// node that has been prepared but not committed yet
+	outgoing *outgoingHandoff
+	// committed is the ID of the last committed outgoing hand-off, so that
+	// a retried commit is acknowledged
+	committed string
+	// incoming is the ID of a received hand-off the old owner has not
+	// committed yet; the received range stays frozen until it does
+	incoming     string
+	incomingFrom *NodeInfo
+	incomingKeys int
+	// receiving is the progress of a streamed hand-off not fully received
+	// yet (see transfer.go)
+	receiving incomingStream
+	// failedPredecessor is set when the predecessor was found dead, so the
+	// next predecessor extends the owned range over the orphaned keys
+	failedPredecessor *NodeInfo
+}
+
+// outgoingHandoff is a range (start, end] frozen for writes while it moves to
+// the node at to
+type outgoingHandoff struct {
+	id       string
+	to       *NodeInfo
+	start    *hash.Hash
+	end      *hash.Hash
+	deadline time.Time
+	// keys is the number of entries found by the last prepare
+	keys int
+	// streamed is set when the entries are fetched with StreamHandoff,
+	// from streamKeys, the sorted keys in the range
+	streamed   bool
+	streamKeys []string
+}
+
+// ownAll makes the node the owner of the whole ring
+func (n *Node) ownAll() {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	n.own.start = n.id
+	n.own.outgoing = nil
+	n.own.incoming = ""
+	n.own.incomingFrom = nil
+}
+
+// OwnedRange returns the exclusive start of the range (start, self] this
+// node owns, equal to its own ID for the whole ring, and whether it owns
+// any range at all
+func (n *Node) OwnedRange() (*hash.Hash, bool) {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	return n.own.start, n.own.start != nil && n.own.incoming == ""
+}
+
+// ownsLocked reports whether id falls in the owned range. The caller must
+// hold own.mu.
+func (n *Node) ownsLocked(id *hash.Hash) bool {
+	switch {
+	case n.own.start == nil:
+		return false
+	case n.own.start.Equal(n.id):
+		return true
+	default:
+		return id.InRange(n.own.start, n.id)
+	}
+}
+
+// frozenLocked reports whether writes to id are held back by a hand-off in
+// progress, aborting an outgoing hand-off whose commit is overdue. The
+// caller must hold own.mu.
+func (n *Node) frozenLocked(id *hash.Hash) bool {
+	if n.own.incoming != "" {
+		// Everything we own arrived with the pending hand-off
+		return true
+	}
+
+	out := n.expireLocked()
+	return out != nil && id.InRange(out.start, out.end)
+}
+
+// expireLocked aborts an outgoing hand-off whose commit is overdue and
+// returns the one still open, if any. The caller must hold own.mu.
+func (n *Node) expireLocked() *outgoingHandoff {
+	out := n.own.outgoing
+	if out != nil && time.Now().After(out.deadline) {
+		log.Printf("Node %s: hand-off %s to %s timed out, aborted",
+			n.id.Short(), out.id, out.to.Address)
+		n.own.outgoing = nil
+		return nil
+	}
+	return out
+}
+
+// checkOwned returns a NotResponsibleError if this node does not own key and,
+// for writes, ErrRangeMoving if the key is frozen by a hand-off
+func (n *Node) checkOwned(key string, write bool) error {
+	keyID := n.KeyID(key)
+
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	if !n.ownsLocked(keyID) {
+		return &NotResponsibleError{Key: key}
+	}
+	if write && n.frozenLocked(keyID) {
+		return fmt.Errorf("%w: %s", ErrRangeMoving, key)
+	}
+	return nil
+}
+
+// prepareHandoff freezes the part of the owned range that requester takes
+// over and returns the hand-off together with the entries stored in it, or
+// none if they are to be streamed. A repeated prepare by the same requester
+// reuses the open hand-off.
+func (n *Node) prepareHandoff(requester *NodeInfo, stream bool) (*outgoingHandoff, []*pb.StoredEntry, error) {
+	n.own.mu.Lock()
+	if requester.ID.Equal(n.id) || !n.ownsLocked(requester.ID) {
+		n.own.mu.Unlock()
+		return nil, nil, &NotResponsibleError{Key: requester.ID.String()}
+	}
+	out := n.expireLocked()
+	if n.own.incoming != "" || out != nil && out.to.Address != requester.Address {
+		n.own.mu.Unlock()
+		return nil, nil, fmt.Errorf("%w: hand-off already in progress", ErrRangeMoving)
+	}
+	if out == nil {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			n.own.mu.Unlock()
+			return nil, nil, fmt.Errorf("failed to generate transfer id: %w", err)
+		}
+		out = &outgoingHandoff{
+			id:    hex.EncodeToString(id),
+			to:    requester,
+			start: n.own.start,
+			end:   requester.ID,
+		}
+		n.own.outgoing = out
+	}
+	out.deadline = time.Now().Add(handoffTimeout)
+	n.own.mu.Unlock()
+
+	// Writes that passed the ownership check before the freeze re-check it
+	// under dataMu, so every write to the range is either collected here or
+	// rejected
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	var entries []*pb.StoredEntry
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if !n.KeyID(key).InRange(out.start, out.end) {
+			return true
+		}
+		if stream {
+			keys = append(keys, key)
+		} else {
+			entries = append(entries, toProtoEntry(key, e))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to collect hand-off entries: %w", err)
+	}
+	sort.Strings(keys)
+
+	n.own.mu.Lock()
+	out.keys = len(entries) + len(keys)
+	out.streamed = stream
+	out.streamKeys = keys
+	n.own.mu.Unlock()
+	return out, entries, nil
+}
+
+// commitHandoff switches ownership of a prepared range to its new owner and
+// drops the moved entries
+func (n *Node) commitHandoff(id string) error {
+	n.own.mu.Lock()
+	if id == n.own.committed {
+		n.own.mu.Unlock()
+		return nil
+	}
+	out := n.expireLocked()
+	if out == nil || out.id != id {
+		n.own.mu.Unlock()
+		return errHandoffAborted
+	}
+	n.own.start = out.end
+	n.own.outgoing = nil
+	n.own.committed = id
+	n.own.mu.Unlock()
+	n.auditTransfer(TransferSent, out.to, out.start, out.end, out.keys, out.id)
+
+	if n.Replication() > 1 {
+		// The new owner is our predecessor, so we keep the entries as its
+		// replica
+		log.Printf("Node %s: handed off range to %s, keeping replicas",
+			n.id.Short(), out.to.Address)
+		return nil
+	}
+
+	moved, err := n.dropRange(out.start, out.end)
+	if err != nil {
+		return err
+	}
+	log.Printf("Node %s: handed off %d keys to %s",
+		n.id.Short(), moved, out.to.Address)
+	return nil
+}
+
+// dropRange deletes the local entries in (start, end] and returns how many
+// were deleted
+func (n *Node) dropRange(start, end *hash.Hash) (int, error) {
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if n.KeyID(key).InRange(start, end) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entries: %w", err)
+	}
+	for _, key := range keys {
+		if err := n.storage.Delete(key); err != nil {
+			return 0, fmt.Errorf("failed to delete %q: %w", key, err)
+		}
+	}
+	return len(keys), nil
+}
+
+// pullHandoff takes over the range (start, self] from the successor, or
+// finishes a hand-off whose commit is still pending. It does nothing once the
+// node owns its range, and returns ErrPaused instead of starting a hand-off
+// while the node is paused.
+func (n *Node) pullHandoff() error {
+	n.own.mu.Lock()
+	owned := n.own.start != nil
+	pending, from := n.own.incoming, n.own.incomingFrom
+	n.own.mu.Unlock()
+
+	if pending != "" {
+		return n.finishHandoff(from, pending)
+	}
+	if owned {
+		return nil
+	}
+	if n.paused() {
+		return ErrPaused
+	}
+
+	successor := n.GetSuccessor()
+	if successor == nil || successor.Address == n.address {
+		return fmt.Errorf("%w: no successor to take over from", ErrRingUnstable)
+	}
+
+	client, err := n.getClient(successor.Address)
+	if err != nil {
+		return err
+	}
+
+	var resp *pb.PrepareHandoffResponse
+	err = n.RetryPolicies().Transfer.Do(n.ctx, func(int) error {
+		ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+		defer cancel()
+
+		var callErr error
+		resp, callErr = client.PrepareHandoff(ctx, &pb.PrepareHandoffRequest{
+			Requester: toProtoNode(n.GetNodeInfo()),
+			Stream:    true,
+		})
+		return fromStatus(successor.Address, callErr)
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("prepare hand-off at %s failed: %s", successor.Address, resp.Error)
+	}
+	start, err := hash.NewHashFromHex(resp.Start)
+	if err != nil {
+		return fmt.Errorf("invalid hand-off start: %w", err)
+	}
+
+	// Nodes that do not stream hand-offs return the entries inline. If
+	// installing them fails, the old owner aborts the unused hand-off after
+	// handoffTimeout.
+	received := len(resp.Entries)
+	if resp.Streamed {
+		if received, err = n.receiveHandoff(client, successor, resp); err != nil {
+			return err
+		}
+	} else if err := n.installEntries(resp.Entries); err != nil {
+		return err
+	}
+
+	n.own.mu.Lock()
+	n.own.start = start
+	n.own.incoming = resp.TransferId
+	n.own.incomingFrom = successor
+	n.own.incomingKeys = received
+	n.own.mu.Unlock()
+
+	log.Printf("Node %s: received %d keys from %s, committing",
+		n.id.Short(), received, successor.Address)
+	return n.finishHandoff(successor, resp.TransferId)
+}
+
+// installEntries stores entries received in a hand-off, keeping versions
+func (n *Node) installEntries(entries []*pb.StoredEntry) error {
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	batch := make([]*pb.KeyValue, len(entries))
+	for i, entry := range entries {
+		batch[i] = &pb.KeyValue{Key: entry.Key, Value: entry.Value}
+	}
+	if err := n.reserveStorage(batch, false); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := n.storage.Put(entry.Key, fromProtoEntry(entry)); err != nil {
+			return fmt.Errorf("failed to install %q: %w", entry.Key, err)
+		}
+	}
+	return nil
+}
+
+// finishHandoff asks the old owner to commit a received hand-off and
+// unfreezes the range once it has. An aborted hand-off is rolled back; if the
+// old owner failed and has been replaced as our successor nobody else can
+// answer for the range, so it is kept.
+func (n *Node) finishHandoff(from *NodeInfo, id string) error {
+	err := n.remoteCommitHandoff(from.Address, id)
+	switch {
+	case err == nil:
+	case status.Code(err) == codes.Aborted:
+		n.rollbackHandoff(id)
+		return fmt.Errorf("hand-off from %s: %w", from.Address, errHandoffAborted)
+	case errors.Is(err, ErrPeerUnreachable) && n.GetSuccessor().Address != from.Address:
+		log.Printf("Node %s: old owner %s failed before committing, keeping range",
+			n.id.Short(), from.Address)
+	default:
+		return err
+	}
+
+	n.own.mu.Lock()
+	finished := n.own.incoming == id
+	start, keys := n.own.start, n.own.incomingKeys
+	if finished {
+		n.own.incoming = ""
+		n.own.incomingFrom = nil
+		n.own.incomingKeys = 0
+	}
+	n.own.mu.Unlock()
+
+	if finished {
+		n.auditTransfer(TransferReceived, from, start, n.id, keys, id)
+	}
+	return nil
+}
+
+// rollbackHandoff gives up a received range after the old owner aborted
+func (n *Node) rollbackHandoff(id string) {
+	n.own.mu.Lock()
+	if n.own.incoming != id {
+		n.own.mu.Unlock()
+		return
+	}
+	start := n.own.start
+	n.own.start = nil
+	n.own.incoming = ""
+	n.own.incomingFrom = nil
+	n.own.incomingKeys = 0
+	n.own.mu.Unlock()
+
+	if _, err := n.dropRange(start, n.id); err != nil {
+		log.Printf("Node %s: failed to drop aborted hand-off: %v", n.id.Short(), err)
+	}
+}
+
+// maintainOwnership retries a missing or unfinished hand-off. It is called
+// from stabilize.
+func (n *Node) maintainOwnership() {
+	if err := n.pullHandoff(); err != nil && !errors.Is(err, ErrPaused) {
+		log.Printf("Node %s: hand-off not completed, will retry: %v", n.id.Short(), err)
+	}
+}
+
+// predecessorFailed records that the predecessor died, leaving its range
+// without an owner until the next predecessor shows up
+func (n *Node) predecessorFailed(predecessor *NodeInfo) {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	n.own.failedPredecessor = predecessor
+}
+
+// adoptPredecessor extends the owned range down to a new predecessor that
+// follows a failed one, taking over the keys the failed node owned
+func (n *Node) adoptPredecessor(predecessor *NodeInfo) {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	failed := n.own.failedPredecessor
+	n.own.failedPredecessor = nil
+	if failed == nil || n.own.start == nil || n.own.start.Equal(n.id) || n.ownsLocked(predecessor.ID) {
+		return
+	}
+	log.Printf("Node %s: taking over range of failed predecessor down to %s",
+		n.id.Short(), predecessor.ID.Short())
+	n.auditTransfer(TransferAdopted, failed, predecessor.ID, n.own.start, 0, "")
+	n.own.start = predecessor.ID
+	n.adoptedRange()
+}
+
+// remoteCommitHandoff calls CommitHandoff on the old owner at address. An
+// aborted hand-off is reported with codes.Aborted.
+func (n *Node) remoteCommitHandoff(address, id string) error {
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.CommitHandoff(ctx, &pb.CommitHandoffRequest{
+		TransferId: id,
+		Requester:  toProtoNode(n.GetNodeInfo()),
+	})
+	if err != nil {
+		if status.Code(err) == codes.Aborted {
+			return err
+		}
+		return fromStatus(address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("commit hand-off at %s failed: %s", address, resp.Error)
+	}
+	return nil
+}
+
+// PrepareHandoff freezes the part of this node's range a joining node takes
+// over and returns the entries stored in it, or leaves them to
+// StreamHandoff if asked to
+func (n *Node) PrepareHandoff(ctx context.Context, req *pb.PrepareHandoffRequest) (*pb.PrepareHandoffResponse, error) {
+	n.countMessage()
+
+	requester, err := fromProtoNode(req.Requester)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid requester: %v", err)
+	}
+	if n.paused() {
+		return nil, toStatus(ErrPaused)
+	}
+
+	out, entries, err := n.prepareHandoff(requester, req.Stream)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.PrepareHandoffResponse{
+		TransferId:   out.id,
+		Start:        out.start.String(),
+		Entries:      entries,
+		Success:      true,
+		Streamed:     req.Stream,
+		TotalEntries: uint64(out.keys),
+	}, nil
+}
+
+// CommitHandoff completes a prepared hand-off, moving ownership of the range
+// to the requester
+func (n *Node) CommitHandoff(ctx context.Context, req *pb.CommitHandoffRequest) (*pb.CommitHandoffResponse, error) {
+	n.countMessage()
+
+	if req.TransferId == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing transfer id")
+	}
+
+	if err := n.commitHandoff(req.TransferId); err != nil {
+		if errors.Is(err, errHandoffAborted) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, toStatus(err)
+	}
+	return &pb.CommitHandoffResponse{Success: true}, nil
+}
@@ -0,0 +1,146 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+func TestHandoffOnJoin(t *testing.T) {
+	bootstrap := NewNode("localhost:8390", hash.NewHashFromString("handoff-bootstrap"))
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start bootstrap: %v", err)
+	}
+	defer bootstrap.Stop()
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 50; i++ {
+		items[fmt.Sprintf("handoff-%d", i)] = []byte(fmt.Sprintf("value-%d", i))
+	}
+	if err := bootstrap.StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	node := NewNode("localhost:8391", hash.NewHashFromString("handoff-joiner"))
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer node.Stop()
+	if err := node.Join(bootstrap.GetAddress()); err != nil {
+		t.Fatalf("Failed to join ring: %v", err)
+	}
+
+	// The hand-off completes during Join
+	start, owned := node.OwnedRange()
+	if !owned || !start.Equal(bootstrap.GetID()) {
+		t.Fatalf("Expected joiner to own (bootstrap, joiner], got start %v owned %v", start, owned)
+	}
+	if start, _ := bootstrap.OwnedRange(); !start.Equal(node.GetID()) {
+		t.Fatalf("Expected bootstrap to own (joiner, bootstrap], got start %v", start)
+	}
+
+	for key := range items {
+		moved := hash.NewHashFromString(key).InRange(bootstrap.GetID(), node.GetID())
+		onNode, _ := node.loadLocal([]string{key})
+		onBootstrap, _ := bootstrap.loadLocal([]string{key})
+		if moved && (len(onNode) != 1 || len(onBootstrap) != 0) {
+			t.Errorf("Key %s should have moved to the joiner", key)
+		}
+		if !moved && (len(onNode) != 0 || len(onBootstrap) != 1) {
+			t.Errorf("Key %s should have stayed on the bootstrap", key)
+		}
+	}
+
+	node.stabilize()
+	bootstrap.stabilize()
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	values, err := node.FetchBatch(ctx, keys)
+	if err != nil {
+		t.Fatalf("FetchBatch failed: %v", err)
+	}
+	if len(values) != len(items) {
+		t.Errorf("Expected %d values after hand-off, got %d", len(items), len(values))
+	}
+}
+
+func TestHandoffFreezesWrites(t *testing.T) {
+	owner := NewNode("localhost:8392", hash.NewHashFromString("handoff-owner"))
+	if err := owner.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer owner.Stop()
+	if err := owner.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	// Find one key the joiner takes over and one that stays
+	joiner := &NodeInfo{ID: hash.NewHashFromString("handoff-newcomer"), Address: "localhost:1"}
+	var moving, staying string
+	for i := 0; moving == "" || staying == ""; i++ {
+		key := fmt.Sprintf("freeze-%d", i)
+		if hash.NewHashFromString(key).InRange(owner.GetID(), joiner.ID) {
+			moving = key
+		} else {
+			staying = key
+		}
+	}
+
+	ctx := context.Background()
+	out, _, err := owner.prepareHandoff(joiner, false)
+	if err != nil {
+		t.Fatalf("prepareHandoff failed: %v", err)
+	}
+
+	// Writes to the frozen range are rejected with a retry hint, reads and
+	// writes elsewhere go through
+	_, err = owner.Put(ctx, &pb.PutRequest{Key: moving, Value: []byte("v")})
+	remoteErr := fromStatus(owner.GetAddress(), err)
+	if !errors.Is(remoteErr, ErrRangeMoving) {
+		t.Fatalf("Expected ErrRangeMoving, got %v", err)
+	}
+	if delay, ok := RetryDelay(remoteErr); !ok || delay != handoffRetryDelay {
+		t.Errorf("Expected retry delay %v, got %v", handoffRetryDelay, delay)
+	}
+	if _, err := owner.Get(ctx, &pb.GetRequest{Key: moving}); err != nil {
+		t.Errorf("Reads of a frozen range should be served: %v", err)
+	}
+	if err := owner.StoreValue(ctx, staying, []byte("v")); err != nil {
+		t.Errorf("Write outside the frozen range failed: %v", err)
+	}
+
+	// A second joiner has to wait for the first hand-off
+	other := &NodeInfo{ID: hash.NewHashFromString("handoff-other"), Address: "localhost:2"}
+	if _, _, err := owner.prepareHandoff(other, false); !errors.Is(err, ErrRangeMoving) {
+		t.Errorf("Expected concurrent hand-off to be refused, got %v", err)
+	}
+
+	if err := owner.commitHandoff("unknown"); !errors.Is(err, errHandoffAborted) {
+		t.Errorf("Expected unknown transfer to be aborted, got %v", err)
+	}
+	if err := owner.commitHandoff(out.id); err != nil {
+		t.Fatalf("commitHandoff failed: %v", err)
+	}
+	if err := owner.commitHandoff(out.id); err != nil {
+		t.Errorf("Retried commit should succeed, got %v", err)
+	}
+
+	// The range now belongs to the joiner
+	if err := owner.checkOwned(moving, false); !errors.Is(err, ErrNotResponsible) {
+		t.Errorf("Expected ErrNotResponsible after commit, got %v", err)
+	}
+	if err := owner.checkOwned(staying, true); err != nil {
+		t.Errorf("Remaining range should be writable, got %v", err)
+	}
+}
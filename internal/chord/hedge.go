@@ -0,0 +1,301 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+const (
+	// hedgeWindowSize is the number of recent read latencies the hedge delay
+	// is computed from
+	hedgeWindowSize = 256
+	// hedgeMinSamples is the number of samples needed before the percentile
+	// is trusted; until then MaxDelay is used
+	hedgeMinSamples = 20
+	// defaultHedgeDelay replaces an unset MaxDelay until enough samples are
+	// collected
+	defaultHedgeDelay = 100 * time.Millisecond
+)
+
+// HedgePolicy configures hedged reads. When the copy of a key chosen by the
+// replica selector has not answered a read within the given percentile of
+// recent read latencies, the read is also sent to the next copy and the first
+// answer wins. Hedging needs a replication factor above 1. The same policy
+// configures hedged lookup hops (see SetLookupHedging).
+type HedgePolicy struct {
+	// Percentile of recent latencies after which a hedge is sent,
+	// e.g. 0.95. Zero disables hedging.
+	Percentile float64
+	// MinDelay and MaxDelay bound the hedge delay, zero meaning unbounded.
+	// MaxDelay (or 100ms if unset) is also used until enough latencies have
+	// been observed.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+}
+
+// HedgeStats counts hedged reads, or hedged lookup hops
+type HedgeStats struct {
+	// Reads is the number of reads, or lookup hops, that could have been
+	// hedged
+	Reads int64
+	// Hedged is the number of reads that sent a hedge
+	Hedged int64
+	// HedgeWins is the number of hedged reads answered by a hedge first
+	HedgeWins int64
+	// Delay is the current hedge delay
+	Delay time.Duration
+}
+
+// hedger holds the hedge policy and the latency window it is applied to
+type hedger struct {
+	mu        sync.Mutex
+	policy    HedgePolicy
+	latencies []time.Duration
+	next      int
+	stats     HedgeStats
+}
+
+// SetHedging sets the policy for hedged reads in FetchValue
+func (n *Node) SetHedging(policy HedgePolicy) {
+	n.hedge.mu.Lock()
+	defer n.hedge.mu.Unlock()
+
+	n.hedge.policy = policy
+}
+
+// HedgeStats returns counters for hedged reads
+func (n *Node) HedgeStats() HedgeStats {
+	n.hedge.mu.Lock()
+	defer n.hedge.mu.Unlock()
+
+	stats := n.hedge.stats
+	stats.Delay = n.hedge.delayLocked()
+	return stats
+}
+
+// enabled reports whether reads are hedged
+func (h *hedger) enabled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.policy.Percentile > 0
+}
+
+// observe records the latency of a successful read
+func (h *hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeWindowSize {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeWindowSize
+}
+
+// delay returns how long to wait for the owner before hedging
+func (h *hedger) delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.delayLocked()
+}
+
+// delayLocked computes the hedge delay. The caller must hold mu.
+func (h *hedger) delayLocked() time.Duration {
+	if len(h.latencies) < hedgeMinSamples {
+		if h.policy.MaxDelay > 0 {
+			return h.policy.MaxDelay
+		}
+		return defaultHedgeDelay
+	}
+
+	sorted := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(h.policy.Percentile * float64(len(sorted)))
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	delay := sorted[index]
+	if delay < h.policy.MinDelay {
+		delay = h.policy.MinDelay
+	}
+	if h.policy.MaxDelay > 0 && delay > h.policy.MaxDelay {
+		delay = h.policy.MaxDelay
+	}
+	return delay
+}
+
+// record counts a finished read
+func (h *hedger) record(hedged, replicaWon bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stats.Reads++
+	if hedged {
+		h.stats.Hedged++
+	}
+	if replicaWon {
+		h.stats.HedgeWins++
+	}
+}
+
+// readAnswer is the outcome of one leg of a replicated read
+type readAnswer struct {
+	value []byte
+	found bool
+	err   error
+	owner bool
+	hedge bool
+}
+
+// replicatedFetch reads key from the copies ordered by the replica selector,
+// one at a time. The next copy is tried when one fails or does not have the
+// key and, with hedging enabled, when one is slower than the hedge delay. The
+// owner's answer is authoritative; a replica's answer is only taken if it
+// found the key.
+func (n *Node) replicatedFetch(ctx context.Context, key string) ([]byte, error) {
+	owner, err := n.findSuccessor(ctx, n.KeyID(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner of key %q: %w", key, err)
+	}
+	targets := n.readOrder(owner)
+	if requestConsistency(ctx) == ConsistencyStrong {
+		targets = []*NodeInfo{owner}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make(chan readAnswer, len(targets))
+	var (
+		pending  int
+		hedged   bool
+		firstErr error
+	)
+	// send reads from the next copy, reporting false if none is left
+	send := func(hedge bool) bool {
+		if len(targets) == 0 {
+			return false
+		}
+		target := targets[0]
+		targets = targets[1:]
+		isOwner := target.Address == owner.Address
+		pending++
+		go func() {
+			start := time.Now()
+			value, found, err := n.getAt(ctx, target.Address, key, !isOwner)
+			if ctx.Err() == nil {
+				n.health.observe(target.Address, time.Since(start), err)
+			}
+			if hint := ownerHint(err); isOwner && hint != nil && hint.Address != owner.Address {
+				// Follow the responsible node hint once
+				value, found, err = n.getAt(ctx, hint.Address, key, false)
+			}
+			if err == nil {
+				n.hedge.observe(time.Since(start))
+			}
+			answers <- readAnswer{value: value, found: found, err: err, owner: isOwner, hedge: hedge}
+		}()
+		return true
+	}
+	send(false)
+
+	// Hedges are optional work, skipped under pressure
+	hedging := n.hedge.enabled() && !n.shedding(PressureElevated)
+	var (
+		timer  *time.Timer
+		hedgeC <-chan time.Time
+	)
+	if hedging {
+		timer = time.NewTimer(n.hedge.delay())
+		defer timer.Stop()
+		hedgeC = timer.C
+	}
+
+	for pending > 0 {
+		select {
+		case <-hedgeC:
+			if send(true) {
+				hedged = true
+				timer.Reset(n.hedge.delay())
+			}
+		case answer := <-answers:
+			pending--
+			switch {
+			case answer.err != nil:
+				if firstErr == nil || answer.owner {
+					firstErr = answer.err
+				}
+				// Fall back to the next copy right away
+				send(false)
+			case !answer.owner && !answer.found:
+				// The replica may have missed the write; ask the next copy
+				send(false)
+			default:
+				if hedging {
+					n.hedge.record(hedged, answer.hedge)
+				}
+				if !answer.found {
+					return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+				}
+				return answer.value, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if hedging {
+		n.hedge.record(hedged, false)
+	}
+	if firstErr == nil {
+		firstErr = fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return nil, firstErr
+}
+
+// getAt reads a single key from the node at address, short-circuiting
+// locally. With replica set a node that does not own the key answers from
+// its replica copy.
+func (n *Node) getAt(ctx context.Context, address, key string, replica bool) ([]byte, bool, error) {
+	if address == n.address {
+		if !replica {
+			if err := n.checkOwned(key, false); err != nil {
+				return nil, false, err
+			}
+		}
+		items, err := n.loadLocal([]string{key})
+		if err == nil && !replica {
+			n.hotKeys.record([]string{key})
+		}
+		if err != nil || len(items) == 0 {
+			return nil, false, err
+		}
+		return items[0].Value, true, nil
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.Get(ctx, &pb.GetRequest{Key: key, Replica: replica})
+	if err != nil {
+		return nil, false, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, false, fmt.Errorf("get from %s failed: %s", address, resp.Error)
+	}
+	return resp.Value, resp.Found, nil
+}
@@ -0,0 +1,86 @@
+package chord
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// slowStorage delays every read of the wrapped store
+type slowStorage struct {
+	Storage
+	delay time.Duration
+}
+
+// Get returns the entry for key after the configured delay
+func (s *slowStorage) Get(key string) (Entry, bool, error) {
+	time.Sleep(s.delay)
+	return s.Storage.Get(key)
+}
+
+func TestHedgeDelay(t *testing.T) {
+	h := &hedger{policy: HedgePolicy{Percentile: 0.9, MinDelay: 5 * time.Millisecond, MaxDelay: 50 * time.Millisecond}}
+	if got := h.delay(); got != 50*time.Millisecond {
+		t.Errorf("Expected MaxDelay before enough samples, got %v", got)
+	}
+
+	for i := 1; i <= 100; i++ {
+		h.observe(time.Duration(i) * 100 * time.Microsecond)
+	}
+	if got := h.delay(); got != 9100*time.Microsecond {
+		t.Errorf("Expected the 90th percentile of 9.1ms, got %v", got)
+	}
+
+	h.policy.MinDelay = 20 * time.Millisecond
+	if got := h.delay(); got != 20*time.Millisecond {
+		t.Errorf("Expected delay clamped to MinDelay, got %v", got)
+	}
+}
+
+func TestHedgedFetch(t *testing.T) {
+	nodes := startTestRing(t, 8393, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+
+	ctx := context.Background()
+	key := "hedged-key"
+	owner, err := nodes[0].Lookup(hash.NewHashFromString(key))
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	var ownerNode, reader *Node
+	for _, node := range nodes {
+		if node.GetAddress() == owner.Address {
+			ownerNode = node
+		} else {
+			reader = node
+		}
+	}
+
+	if err := reader.StoreValue(ctx, key, []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+
+	// Make the owner slow and let the reader hedge after 50ms
+	ownerNode.SetStorage(&slowStorage{Storage: ownerNode.Storage(), delay: time.Second})
+	reader.SetHedging(HedgePolicy{Percentile: 0.95, MaxDelay: 50 * time.Millisecond})
+
+	start := time.Now()
+	value, err := reader.FetchValue(ctx, key)
+	if err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+	if !bytes.Equal(value, []byte("value")) {
+		t.Errorf("Expected value, got %q", value)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Hedged read took %v, expected the replica to answer first", elapsed)
+	}
+	if stats := reader.HedgeStats(); stats.Hedged != 1 || stats.HedgeWins != 1 {
+		t.Errorf("Expected one hedge won by a replica, got %+v", stats)
+	}
+}
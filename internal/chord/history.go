@@ -0,0 +1,171 @@
+package chord
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// HistorySize is the number of membership events a node keeps
+const HistorySize = 256
+
+// MembershipChange is the kind of a membership event
+type MembershipChange string
+
+const (
+	// MemberJoined is recorded when a node becomes this node's predecessor
+	// or successor, or when this node enters a ring
+	MemberJoined MembershipChange = "joined"
+	// MemberLeft is recorded when the predecessor or successor stops
+	// answering
+	MemberLeft MembershipChange = "left"
+)
+
+// Roles a node plays in a membership event, relative to the observer
+const (
+	RoleSelf        = "self"
+	RolePredecessor = "predecessor"
+	RoleSuccessor   = "successor"
+)
+
+// MembershipEvent is a change of neighbors as observed by one node
+type MembershipEvent struct {
+	// Seq numbers the node's events from 1, without gaps
+	Seq  uint64
+	Time time.Time
+	Kind MembershipChange
+	Role string
+	Node *NodeInfo
+	// Previous is the node Node replaced in its role, if any
+	Previous *NodeInfo
+}
+
+// membershipLog is a bounded ring buffer of membership events
+type membershipLog struct {
+	mu     sync.Mutex
+	events []MembershipEvent
+	next   uint64
+}
+
+// record appends an event, dropping the oldest one when full. It takes its
+// own lock, so callers may hold the node's mu.
+func (l *membershipLog) record(kind MembershipChange, role string, node, previous *NodeInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.next++
+	event := MembershipEvent{Seq: l.next, Time: time.Now(), Kind: kind, Role: role, Node: node, Previous: previous}
+	if len(l.events) == HistorySize {
+		copy(l.events, l.events[1:])
+		l.events = l.events[:HistorySize-1]
+	}
+	l.events = append(l.events, event)
+}
+
+// recordMembership records a membership event in the history and the audit
+// log (see audit.go)
+func (n *Node) recordMembership(kind MembershipChange, role string, node, previous *NodeInfo) {
+	n.history.record(kind, role, node, previous)
+	n.auditMembership(kind, role, node, previous)
+}
+
+// seq returns the sequence number of the last event recorded
+func (l *membershipLog) seq() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.next
+}
+
+// MembershipHistory returns the kept events after sequence number since,
+// oldest first, and the sequence number of the oldest event kept. A gap
+// between since and it means events were dropped.
+func (n *Node) MembershipHistory(since uint64) ([]MembershipEvent, uint64) {
+	n.history.mu.Lock()
+	defer n.history.mu.Unlock()
+
+	first := n.history.next + 1
+	if len(n.history.events) > 0 {
+		first = n.history.events[0].Seq
+	}
+	var events []MembershipEvent
+	for _, event := range n.history.events {
+		if event.Seq > since {
+			events = append(events, event)
+		}
+	}
+	return events, first
+}
+
+// RemoteMembershipHistory returns the membership events of the node at
+// address after sequence number since, and the sequence number of the
+// oldest event it kept
+func (n *Node) RemoteMembershipHistory(ctx context.Context, address string, since uint64) ([]MembershipEvent, uint64, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetMembershipHistory(ctx, &pb.GetMembershipHistoryRequest{SinceSeq: since})
+	if err != nil {
+		return nil, 0, fromStatus(address, err)
+	}
+	events, err := MembershipFromProto(resp.Events)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, resp.FirstSeq, nil
+}
+
+// GetMembershipHistory returns this node's membership events
+func (n *Node) GetMembershipHistory(ctx context.Context, req *pb.GetMembershipHistoryRequest) (*pb.GetMembershipHistoryResponse, error) {
+	n.countMessage()
+
+	events, first := n.MembershipHistory(req.SinceSeq)
+	resp := &pb.GetMembershipHistoryResponse{Node: toProtoNode(n.GetNodeInfo()), FirstSeq: first}
+	for _, event := range events {
+		pe := &pb.MembershipEvent{
+			Seq:    event.Seq,
+			TimeMs: event.Time.UnixMilli(),
+			Kind:   string(event.Kind),
+			Role:   event.Role,
+			Node:   toProtoNode(event.Node),
+		}
+		if event.Previous != nil {
+			pe.Previous = toProtoNode(event.Previous)
+		}
+		resp.Events = append(resp.Events, pe)
+	}
+	return resp, nil
+}
+
+// MembershipFromProto converts protobuf membership events
+func MembershipFromProto(events []*pb.MembershipEvent) ([]MembershipEvent, error) {
+	result := make([]MembershipEvent, 0, len(events))
+	for _, event := range events {
+		node, err := fromProtoNode(event.Node)
+		if err != nil {
+			return nil, err
+		}
+		var previous *NodeInfo
+		if event.Previous != nil {
+			if previous, err = fromProtoNode(event.Previous); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, MembershipEvent{
+			Seq:      event.Seq,
+			Time:     time.UnixMilli(event.TimeMs),
+			Kind:     MembershipChange(event.Kind),
+			Role:     event.Role,
+			Node:     node,
+			Previous: previous,
+		})
+	}
+	return result, nil
+}
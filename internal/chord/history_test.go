@@ -0,0 +1,59 @@
+package chord
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMembershipHistory(t *testing.T) {
+	nodes := startTestRing(t, 8451, 3)
+
+	node := nodes[0]
+	events, first := node.MembershipHistory(0)
+	if first != 1 || len(events) == 0 {
+		t.Fatalf("Expected events from 1, got %d events from %d", len(events), first)
+	}
+	if events[0].Kind != MemberJoined || events[0].Role != RoleSelf {
+		t.Errorf("Expected the node's own join first, got %+v", events[0])
+	}
+	since := events[len(events)-1].Seq
+
+	failed := node.GetSuccessor()
+	for _, n := range nodes {
+		if n.GetAddress() == failed.Address {
+			n.Stop()
+		}
+	}
+	node.stabilize()
+
+	events, _, err := nodes[2].RemoteMembershipHistory(context.Background(), node.GetAddress(), since)
+	if err != nil {
+		t.Fatalf("RemoteMembershipHistory failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected the failover to add 2 events, got %+v", events)
+	}
+	if left := events[0]; left.Kind != MemberLeft || left.Role != RoleSuccessor || left.Node.Address != failed.Address {
+		t.Errorf("Expected the successor to leave, got %+v", left)
+	}
+	joined := events[1]
+	if joined.Kind != MemberJoined || joined.Previous == nil || joined.Previous.Address != failed.Address {
+		t.Errorf("Expected a new successor replacing %s, got %+v", failed.Address, joined)
+	}
+}
+
+func TestMembershipHistoryBounded(t *testing.T) {
+	node := NewNode("localhost:0", nil)
+	self := node.GetNodeInfo()
+	for i := 0; i < HistorySize+10; i++ {
+		node.history.record(MemberJoined, RolePredecessor, self, nil)
+	}
+
+	events, first := node.MembershipHistory(0)
+	if len(events) != HistorySize || first != 11 {
+		t.Errorf("Expected %d events from 11, got %d from %d", HistorySize, len(events), first)
+	}
+	if last := events[len(events)-1].Seq; last != HistorySize+10 {
+		t.Errorf("Expected the newest event last, got %d", last)
+	}
+}
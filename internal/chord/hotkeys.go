@@ -0,0 +1,597 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// hotKeyWindow is the period over which the read rates of keys are
+	// measured, and at which hot keys are copied
+	hotKeyWindow = 10 * time.Second
+	// maxTrackedKeys bounds the keys a node tracks rates of, and the hot
+	// copies it holds; the coldest tracked key makes room for a new one
+	maxTrackedKeys = 1024
+	// DefaultHotKeys is the number of keys returned by GetHotKeys when the
+	// request sets no limit
+	DefaultHotKeys = 10
+	// DefaultHotKeyTTL is how long a hot copy is served unless its owner
+	// refreshes it
+	DefaultHotKeyTTL = 3 * hotKeyWindow
+)
+
+// HotKeyPolicy makes a node copy the keys it owns that are read at a high
+// rate to the nodes preceding it on the ring. Lookups for a key end at its
+// owner's predecessor, so those nodes lie on the lookup paths of most
+// readers: they report their copies in lookup answers, and readers spread
+// later reads of the key over the owner and the copies. Writes to a copied
+// key refresh its copies; reads with ConsistencyStrong always go to the
+// owner.
+type HotKeyPolicy struct {
+	// Threshold is the read rate per second at which a key is copied; zero
+	// disables copying
+	Threshold float64
+	// Copies is the number of predecessors holding copies; zero means one
+	Copies int
+	// TTL is how long a copy is served unless refreshed; zero means
+	// DefaultHotKeyTTL
+	TTL time.Duration
+}
+
+// copies returns the number of predecessors to hold copies
+func (p HotKeyPolicy) copies() int {
+	if p.Copies <= 0 {
+		return 1
+	}
+	return p.Copies
+}
+
+// ttl returns how long copies are served
+func (p HotKeyPolicy) ttl() time.Duration {
+	if p.TTL <= 0 {
+		return DefaultHotKeyTTL
+	}
+	return p.TTL
+}
+
+// HotKey is the read rate of a key owned by a node
+type HotKey struct {
+	Key string
+	// Rate is the reads per second the owner served over the last window,
+	// not counting reads answered by copies
+	Rate float64
+	// Copies is the number of nodes holding a hot copy of the key
+	Copies int
+}
+
+// HotKeyStats counts the hot copies of a node
+type HotKeyStats struct {
+	// Copied is the number of keys owned here that have live copies
+	Copied int
+	// Held is the number of copies held here for other owners
+	Held int
+	// Served is the number of reads answered from copies held here
+	Served int64
+	// Offloaded is the number of reads this node sent to a copy instead of
+	// the owner
+	Offloaded int64
+}
+
+// keyRate counts the reads of a key in the current and previous windows
+type keyRate struct {
+	current, previous int64
+}
+
+// hotCopy is a copy of a hot key held for its owner
+type hotCopy struct {
+	value   []byte
+	expires time.Time
+}
+
+// copyHolders is a set of nodes holding copies of a key
+type copyHolders struct {
+	nodes   []*NodeInfo
+	expires time.Time
+}
+
+// hotKeys holds a node's read rates of owned keys and the hot copies it
+// holds or has seen
+type hotKeys struct {
+	mu     sync.Mutex
+	policy HotKeyPolicy
+	// rates counts the reads of owned keys in the window started at start
+	rates map[string]*keyRate
+	start time.Time
+	// holders maps owned keys to the nodes holding copies of them
+	holders map[string]copyHolders
+	// copies holds the copies kept for other owners, and byID maps their
+	// ring positions to their keys
+	copies map[string]hotCopy
+	byID   map[string]string
+	// learned maps key positions to the copies reported on lookup paths
+	learned map[string]copyHolders
+	stats   HotKeyStats
+}
+
+// SetHotKeys sets when this node copies the hot keys it owns
+func (n *Node) SetHotKeys(policy HotKeyPolicy) {
+	n.hotKeys.mu.Lock()
+	defer n.hotKeys.mu.Unlock()
+
+	n.hotKeys.policy = policy
+}
+
+// HotKeys returns up to limit of the keys owned by this node with the
+// highest read rates, hottest first
+func (n *Node) HotKeys(limit int) []HotKey {
+	n.hotKeys.mu.Lock()
+	defer n.hotKeys.mu.Unlock()
+
+	return n.hotKeys.topLocked(time.Now(), limit)
+}
+
+// HotKeyStats returns counters for hot copies
+func (n *Node) HotKeyStats() HotKeyStats {
+	n.hotKeys.mu.Lock()
+	defer n.hotKeys.mu.Unlock()
+
+	now := time.Now()
+	n.hotKeys.expireLocked(now)
+	stats := n.hotKeys.stats
+	stats.Copied = len(n.hotKeys.holders)
+	stats.Held = len(n.hotKeys.copies)
+	return stats
+}
+
+// record counts reads of owned keys
+func (h *hotKeys) record(keys []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.rotateLocked(now)
+	for _, key := range keys {
+		if rate, ok := h.rates[key]; ok {
+			rate.current++
+			continue
+		}
+		if len(h.rates) >= maxTrackedKeys {
+			h.evictLocked(now)
+		}
+		h.rates[key] = &keyRate{current: 1}
+	}
+}
+
+// rotateLocked starts a new window once the current one is over, dropping
+// keys not read in the last two
+func (h *hotKeys) rotateLocked(now time.Time) {
+	if h.rates == nil {
+		h.rates = make(map[string]*keyRate)
+		h.start = now
+	}
+	elapsed := now.Sub(h.start)
+	if elapsed < hotKeyWindow {
+		return
+	}
+	for key, rate := range h.rates {
+		rate.previous = rate.current
+		if elapsed >= 2*hotKeyWindow {
+			rate.previous = 0
+		}
+		rate.current = 0
+		if rate.previous == 0 {
+			delete(h.rates, key)
+		}
+	}
+	h.start = now
+}
+
+// rateLocked returns the reads per second of a key over the last window,
+// weighing the previous window by how much of it is still in range
+func (h *hotKeys) rateLocked(now time.Time, rate *keyRate) float64 {
+	left := 1 - float64(now.Sub(h.start))/float64(hotKeyWindow)
+	if left < 0 {
+		left = 0
+	}
+	return (float64(rate.previous)*left + float64(rate.current)) / hotKeyWindow.Seconds()
+}
+
+// evictLocked drops the tracked key with the lowest rate
+func (h *hotKeys) evictLocked(now time.Time) {
+	var (
+		coldest string
+		lowest  float64
+		found   bool
+	)
+	for key, rate := range h.rates {
+		if r := h.rateLocked(now, rate); !found || r < lowest {
+			coldest, lowest, found = key, r, true
+		}
+	}
+	delete(h.rates, coldest)
+}
+
+// topLocked returns up to limit of the tracked keys with the highest rates
+func (h *hotKeys) topLocked(now time.Time, limit int) []HotKey {
+	h.rotateLocked(now)
+	h.expireLocked(now)
+	keys := make([]HotKey, 0, len(h.rates))
+	for key, rate := range h.rates {
+		if r := h.rateLocked(now, rate); r > 0 {
+			keys = append(keys, HotKey{Key: key, Rate: r, Copies: len(h.holders[key].nodes)})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Rate != keys[j].Rate {
+			return keys[i].Rate > keys[j].Rate
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	if limit >= 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+// expireLocked drops copies, holders and learned copies past their lifetime
+func (h *hotKeys) expireLocked(now time.Time) {
+	for key, c := range h.copies {
+		if !now.Before(c.expires) {
+			delete(h.copies, key)
+		}
+	}
+	for id, key := range h.byID {
+		if _, ok := h.copies[key]; !ok {
+			delete(h.byID, id)
+		}
+	}
+	for key, holders := range h.holders {
+		if !now.Before(holders.expires) {
+			delete(h.holders, key)
+		}
+	}
+	for id, holders := range h.learned {
+		if !now.Before(holders.expires) {
+			delete(h.learned, id)
+		}
+	}
+}
+
+// hold stores copies for their owner, and drops the copies of dropped keys
+func (h *hotKeys) hold(items []*pb.KeyValue, dropped []string, ttl time.Duration, keyID func(string) *hash.Hash) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.copies == nil {
+		h.copies = make(map[string]hotCopy)
+		h.byID = make(map[string]string)
+	}
+	now := time.Now()
+	h.expireLocked(now)
+	for _, key := range dropped {
+		delete(h.copies, key)
+		delete(h.byID, keyID(key).String())
+	}
+	for _, item := range items {
+		if _, ok := h.copies[item.Key]; !ok && len(h.copies) >= maxTrackedKeys {
+			continue
+		}
+		h.copies[item.Key] = hotCopy{value: item.Value, expires: now.Add(ttl)}
+		h.byID[keyID(item.Key).String()] = item.Key
+	}
+}
+
+// copyOf returns the live copy held of key, counting it as served
+func (h *hotKeys) copyOf(key string) ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c, ok := h.copies[key]
+	if !ok || !time.Now().Before(c.expires) {
+		return nil, false
+	}
+	h.stats.Served++
+	return c.value, true
+}
+
+// holds reports whether a live copy of the key at id is held here
+func (h *hotKeys) holds(id *hash.Hash) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key, ok := h.byID[id.String()]
+	if !ok {
+		return false
+	}
+	c, ok := h.copies[key]
+	return ok && time.Now().Before(c.expires)
+}
+
+// learn records the copies of the key at id reported on a lookup path; an
+// answer without copies forgets those seen before
+func (h *hotKeys) learn(id *hash.Hash, copies []*NodeInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(copies) == 0 {
+		delete(h.learned, id.String())
+		return
+	}
+	if h.learned == nil {
+		h.learned = make(map[string]copyHolders)
+	}
+	if _, ok := h.learned[id.String()]; !ok && len(h.learned) >= maxTrackedKeys {
+		h.expireLocked(time.Now())
+		if len(h.learned) >= maxTrackedKeys {
+			return
+		}
+	}
+	h.learned[id.String()] = copyHolders{nodes: copies, expires: time.Now().Add(hotKeyWindow)}
+}
+
+// learnedFor returns the copies of the key at id seen on lookup paths
+func (h *hotKeys) learnedFor(id *hash.Hash) []*NodeInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	holders, ok := h.learned[id.String()]
+	if !ok || !time.Now().Before(holders.expires) {
+		return nil
+	}
+	return holders.nodes
+}
+
+// copied records the nodes now holding copies of keys until ttl elapses
+func (h *hotKeys) copied(keys []string, nodes []*NodeInfo, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.holders == nil {
+		h.holders = make(map[string]copyHolders)
+	}
+	expires := time.Now().Add(ttl)
+	for _, key := range keys {
+		h.holders[key] = copyHolders{nodes: nodes, expires: expires}
+	}
+}
+
+// withHotCopy adds this node to a lookup answer for the key at id if it
+// holds a hot copy of it
+func (n *Node) withHotCopy(id *hash.Hash, resp *pb.FindSuccessorResponse) *pb.FindSuccessorResponse {
+	if n.hotKeys.holds(id) {
+		resp.Copies = append(resp.Copies, toProtoNode(n.GetNodeInfo()))
+	}
+	return resp
+}
+
+// learnHotCopies records the copies reported in a lookup answer for the
+// key at id
+func (n *Node) learnHotCopies(id *hash.Hash, resp *pb.FindSuccessorResponse) {
+	copies, err := fromProtoNodes(resp.Copies)
+	if err != nil {
+		log.Printf("Node %s: ignoring invalid hot copies in lookup answer: %v", n.id.Short(), err)
+		return
+	}
+	n.hotKeys.learn(id, copies)
+}
+
+// fetchHotCopy reads key from a hot copy held here or, picked at random
+// with the owner, from one reported on its lookup path. It reports false
+// when the read should go to the owner.
+func (n *Node) fetchHotCopy(ctx context.Context, key string) ([]byte, bool) {
+	if requestConsistency(ctx) == ConsistencyStrong {
+		return nil, false
+	}
+	if value, ok := n.hotKeys.copyOf(key); ok {
+		return value, true
+	}
+
+	copies := n.hotKeys.learnedFor(n.KeyID(key))
+	pick := rand.Intn(len(copies) + 1)
+	if pick == len(copies) {
+		// The owner's share of the reads
+		return nil, false
+	}
+	value, found, err := n.getAt(ctx, copies[pick].Address, key, true)
+	if err != nil || !found {
+		return nil, false
+	}
+
+	n.hotKeys.mu.Lock()
+	n.hotKeys.stats.Offloaded++
+	n.hotKeys.mu.Unlock()
+	return value, true
+}
+
+// copyHotKeys copies the owned keys read at the policy's threshold to this
+// node's predecessors. A copied key stays copied while the reads its owner
+// serves, scaled by the share the copies take, reach the threshold.
+func (n *Node) copyHotKeys(ctx context.Context) {
+	n.hotKeys.mu.Lock()
+	policy := n.hotKeys.policy
+	n.hotKeys.mu.Unlock()
+	if policy.Threshold <= 0 {
+		return
+	}
+
+	var keys []string
+	for _, hot := range n.HotKeys(maxTrackedKeys) {
+		if hot.Rate*float64(hot.Copies+1) >= policy.Threshold && n.checkOwned(hot.Key, false) == nil {
+			keys = append(keys, hot.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	holders := n.hotKeyHolders(ctx, policy.copies())
+	if len(holders) == 0 {
+		return
+	}
+	n.pushHotCopies(ctx, keys, holders, policy.ttl())
+}
+
+// hotKeyHolders returns up to count of the nodes preceding this one,
+// nearest first
+func (n *Node) hotKeyHolders(ctx context.Context, count int) []*NodeInfo {
+	seen := map[string]bool{n.address: true}
+	var holders []*NodeInfo
+	node := n.GetPredecessor()
+	for node != nil && !seen[node.Address] {
+		seen[node.Address] = true
+		holders = append(holders, node)
+		if len(holders) == count {
+			break
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, RPCTimeout)
+		resp := &pb.GetInfoResponse{}
+		err := n.invokeMaintenance(rpcCtx, node.Address, pb.ChordService_GetInfo_FullMethodName, &pb.GetInfoRequest{}, resp)
+		cancel()
+		if err != nil || resp.Predecessor == nil {
+			break
+		}
+		if node, err = fromProtoNode(resp.Predecessor); err != nil {
+			break
+		}
+	}
+	return holders
+}
+
+// pushHotCopies sends the current values of keys to holders, which serve
+// them for ttl; keys no longer stored have their copies dropped
+func (n *Node) pushHotCopies(ctx context.Context, keys []string, holders []*NodeInfo, ttl time.Duration) {
+	items, err := n.loadLocal(keys)
+	if err != nil {
+		log.Printf("Node %s: failed to read hot keys: %v", n.id.Short(), err)
+		return
+	}
+	stored := make(map[string]bool, len(items))
+	for _, item := range items {
+		stored[item.Key] = true
+	}
+	var dropped []string
+	for _, key := range keys {
+		if !stored[key] {
+			dropped = append(dropped, key)
+		}
+	}
+
+	var reached []*NodeInfo
+	for _, holder := range holders {
+		if err := n.remoteCacheHotKeys(ctx, holder.Address, items, dropped, ttl); err != nil {
+			log.Printf("Node %s: failed to copy %d hot keys to %s: %v",
+				n.id.Short(), len(keys), holder.Address, err)
+			continue
+		}
+		reached = append(reached, holder)
+	}
+	if len(reached) > 0 {
+		n.hotKeys.copied(keys, reached, ttl)
+	}
+}
+
+// refreshHotCopies sends the written keys that have live copies to their
+// holders. It is called by the owner after a write is applied.
+func (n *Node) refreshHotCopies(ctx context.Context, keys []string) {
+	n.hotKeys.mu.Lock()
+	now := time.Now()
+	refresh := make(map[string]copyHolders)
+	for _, key := range keys {
+		if holders, ok := n.hotKeys.holders[key]; ok && now.Before(holders.expires) {
+			refresh[key] = holders
+		}
+	}
+	n.hotKeys.mu.Unlock()
+
+	for key, holders := range refresh {
+		n.pushHotCopies(ctx, []string{key}, holders.nodes, holders.expires.Sub(now))
+	}
+}
+
+// remoteCacheHotKeys sends hot copies to the node at address
+func (n *Node) remoteCacheHotKeys(ctx context.Context, address string, items []*pb.KeyValue, dropped []string, ttl time.Duration) error {
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.CacheHotKeys(ctx, &pb.CacheHotKeysRequest{
+		Owner:   toProtoNode(n.GetNodeInfo()),
+		Items:   items,
+		Dropped: dropped,
+		TtlMs:   ttl.Milliseconds(),
+	})
+	if err != nil {
+		return fromStatus(address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("copy hot keys to %s failed: %s", address, resp.Error)
+	}
+	return nil
+}
+
+// copyHotKeysLoop copies hot keys every hotKeyWindow until the node stops
+func (n *Node) copyHotKeysLoop() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(hotKeyWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			// Copying sheds load, so it only stops at critical pressure
+			if !n.shedding(PressureCritical) {
+				n.copyHotKeys(n.ctx)
+			}
+		}
+	}
+}
+
+// CacheHotKeys stores hot copies sent by their owner
+func (n *Node) CacheHotKeys(ctx context.Context, req *pb.CacheHotKeysRequest) (*pb.CacheHotKeysResponse, error) {
+	n.countMessage()
+
+	if req.TtlMs <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl must be positive")
+	}
+	for _, item := range req.Items {
+		if item.Key == "" {
+			return nil, status.Error(codes.InvalidArgument, "empty key in hot copies")
+		}
+	}
+	n.hotKeys.hold(req.Items, req.Dropped, time.Duration(req.TtlMs)*time.Millisecond, n.KeyID)
+	return &pb.CacheHotKeysResponse{Success: true}, nil
+}
+
+// GetHotKeys returns the hottest keys owned by this node
+func (n *Node) GetHotKeys(ctx context.Context, req *pb.GetHotKeysRequest) (*pb.GetHotKeysResponse, error) {
+	n.countMessage()
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultHotKeys
+	}
+	resp := &pb.GetHotKeysResponse{}
+	for _, hot := range n.HotKeys(limit) {
+		resp.Keys = append(resp.Keys, &pb.HotKey{Key: hot.Key, Rate: hot.Rate, Copies: int32(hot.Copies)})
+	}
+	return resp, nil
+}
@@ -0,0 +1,107 @@
+package chord
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHotKeys(t *testing.T) {
+	nodes := startTestRing(t, 8555, 3)
+	for _, node := range nodes {
+		for i := 0; i < FingerTableSize; i++ {
+			node.fixFingers()
+		}
+	}
+	byAddress := make(map[string]*Node)
+	for _, node := range nodes {
+		byAddress[node.GetAddress()] = node
+	}
+
+	ctx := context.Background()
+	if err := nodes[0].StoreBatch(ctx, map[string][]byte{"hot": []byte("v1"), "cold": []byte("v1")}); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+	info, err := nodes[0].LookupContext(ctx, nodes[0].KeyID("hot"))
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	owner := byAddress[info.Address]
+	holder := byAddress[owner.GetPredecessor().Address]
+	var reader *Node
+	for _, node := range nodes {
+		if node != owner && node != holder {
+			reader = node
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		if _, err := reader.FetchValue(ctx, "hot"); err != nil {
+			t.Fatalf("FetchValue failed: %v", err)
+		}
+	}
+	if _, err := reader.FetchValue(ctx, "cold"); err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+	top := owner.HotKeys(1)
+	if len(top) != 1 || top[0].Key != "hot" || top[0].Rate <= 0 {
+		t.Fatalf("Expected hot to be the hottest key, got %+v", top)
+	}
+
+	// Without a policy nothing is copied
+	owner.copyHotKeys(ctx)
+	if held := holder.HotKeyStats().Held; held != 0 {
+		t.Fatalf("Expected no copies without a policy, %d held", held)
+	}
+
+	owner.SetHotKeys(HotKeyPolicy{Threshold: 1})
+	owner.copyHotKeys(ctx)
+	if stats := owner.HotKeyStats(); stats.Copied != 1 {
+		t.Fatalf("Expected 1 key copied, got %+v", stats)
+	}
+	if stats := holder.HotKeyStats(); stats.Held != 1 {
+		t.Fatalf("Expected the predecessor to hold 1 copy, got %+v", stats)
+	}
+	if top := owner.HotKeys(1); top[0].Copies != 1 {
+		t.Errorf("Expected hot to have 1 copy, got %+v", top[0])
+	}
+
+	// The reader learns the copy on the lookup path and spreads its reads
+	for i := 0; i < 40; i++ {
+		value, err := reader.FetchValue(ctx, "hot")
+		if err != nil {
+			t.Fatalf("FetchValue failed: %v", err)
+		}
+		if string(value) != "v1" {
+			t.Fatalf("Expected v1, got %q", value)
+		}
+	}
+	if offloaded := reader.HotKeyStats().Offloaded; offloaded == 0 {
+		t.Error("Expected some reads to be served by the copy")
+	}
+	if served := holder.HotKeyStats().Served; served == 0 {
+		t.Error("Expected the predecessor to serve reads from its copy")
+	}
+
+	// Writes refresh the copy
+	if err := reader.StoreValue(ctx, "hot", []byte("v2")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		value, err := reader.FetchValue(ctx, "hot")
+		if err != nil {
+			t.Fatalf("FetchValue failed: %v", err)
+		}
+		if string(value) != "v2" {
+			t.Fatalf("Expected v2 after the write, got %q", value)
+		}
+	}
+
+	// The holder reads its own copy
+	before := holder.HotKeyStats().Served
+	if value, err := holder.FetchValue(ctx, "hot"); err != nil || string(value) != "v2" {
+		t.Fatalf("Expected v2 from the holder, got %q, %v", value, err)
+	}
+	if holder.HotKeyStats().Served != before+1 {
+		t.Error("Expected the holder to answer from its copy")
+	}
+}
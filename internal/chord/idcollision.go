@@ -0,0 +1,77 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+// CheckID asks the node at bootstrapAddr which node holds this node's ID,
+// failing with ErrIDCollision if another node does. It may be called before
+// the node starts, to pick another ID with SetID.
+func (n *Node) CheckID(bootstrapAddr string) error {
+	bootstrapAddr, err := NormalizeAddress(bootstrapAddr)
+	if err != nil {
+		return fmt.Errorf("invalid bootstrap address: %w", err)
+	}
+	client, err := n.getClient(bootstrapAddr)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.FindSuccessor(ctx, &pb.FindSuccessorRequest{
+		Key:       n.id.String(),
+		Requester: toProtoNode(n.GetNodeInfo()),
+	})
+	if err != nil {
+		return fromStatus(bootstrapAddr, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("lookup of %s failed: %s", n.id.Short(), resp.Error)
+	}
+	holder, err := fromProtoNode(resp.Successor)
+	if err != nil {
+		return fmt.Errorf("invalid successor: %w", err)
+	}
+	return n.idCollision(holder)
+}
+
+// idCollision fails with ErrIDCollision if successor, the node holding this
+// node's ID, is another node with the same ID. One at our own address is
+// this node before a restart, which the ring has not noticed yet.
+func (n *Node) idCollision(successor *NodeInfo) error {
+	if !successor.ID.Equal(n.id) || successor.Address == n.address {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is held by %s", ErrIDCollision, n.id, successor.Address)
+}
+
+// SetID replaces the ID of a node that has neither started nor joined a
+// ring, such as with one from PerturbID after CheckID found it taken
+func (n *Node) SetID(id *hash.Hash) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.listener != nil || n.successor != nil {
+		return fmt.Errorf("cannot change the ID of a started node")
+	}
+	n.id = id
+	selfInfo := n.GetNodeInfo()
+	for i := range n.fingers {
+		n.fingers[i] = selfInfo
+	}
+	return nil
+}
+
+// PerturbID returns an ID a random distance of at most 2^32 past id, close
+// enough to keep a chosen ID's place in the ring
+func PerturbID(id *hash.Hash) *hash.Hash {
+	return id.Add(big.NewInt(rand.Int63n(1<<32) + 1))
+}
@@ -0,0 +1,61 @@
+package chord
+
+import (
+	"errors"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestJoinRefusesIDCollision(t *testing.T) {
+	id := hash.NewHashFromString("taken")
+	first := NewNode("localhost:8660", id)
+	if err := first.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(first.Stop)
+	if err := first.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	second := NewNode("localhost:8661", id)
+	if err := second.CheckID(first.GetAddress()); !errors.Is(err, ErrIDCollision) {
+		t.Fatalf("Expected CheckID to find the ID taken, got %v", err)
+	}
+	if err := second.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(second.Stop)
+	if err := second.Join(first.GetAddress()); !errors.Is(err, ErrIDCollision) {
+		t.Fatalf("Expected ErrIDCollision joining with a taken ID, got %v", err)
+	}
+	if second.GetSuccessor() != nil {
+		t.Errorf("Refused node has successor %v", second.GetSuccessor())
+	}
+	if err := second.SetID(PerturbID(id)); err == nil {
+		t.Error("Expected SetID to refuse a started node")
+	}
+
+	// A node that has not started takes a perturbed ID and joins with it
+	third := NewNode("localhost:8662", id)
+	perturbed := PerturbID(id)
+	if perturbed.Equal(id) {
+		t.Fatal("PerturbID returned the same ID")
+	}
+	if err := third.SetID(perturbed); err != nil {
+		t.Fatalf("SetID failed: %v", err)
+	}
+	if err := third.CheckID(first.GetAddress()); err != nil {
+		t.Fatalf("CheckID of the perturbed ID failed: %v", err)
+	}
+	if err := third.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(third.Stop)
+	if err := third.Join(first.GetAddress()); err != nil {
+		t.Fatalf("Join with the perturbed ID failed: %v", err)
+	}
+	if !third.GetID().Equal(perturbed) {
+		t.Errorf("Node joined as %s, expected %s", third.GetID().Short(), perturbed.Short())
+	}
+}
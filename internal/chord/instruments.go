@@ -0,0 +1,98 @@
+package chord
+
+import (
+	"time"
+
+	"chord-dht/internal/metrics"
+)
+
+// instruments are the metrics a node records into its sink
+type instruments struct {
+	lookups       metrics.Counter
+	lookupErrors  metrics.Counter
+	lookupHops    metrics.Counter
+	lookupLatency metrics.Timer
+	messages      metrics.Counter
+	payloadBytes  metrics.Counter
+	wireBytes     metrics.Counter
+	// bytesSent and bytesReceived are by bandwidth category
+	bytesSent     map[string]metrics.Counter
+	bytesReceived map[string]metrics.Counter
+	// fingerAccuracy is set by every finger table check
+	fingerAccuracy metrics.Gauge
+	// lookupSaturation is the fraction of the lookup limit in use
+	lookupSaturation metrics.Gauge
+	lookupsRejected  metrics.Counter
+	// Rounds restoring the replication factor (see rereplication.go)
+	reReplications       metrics.Counter
+	reReplicatedKeys     metrics.Counter
+	reReplicationErrors  metrics.Counter
+	reReplicationPending metrics.Gauge
+	// tombstonesReaped counts tombstones removed (see tombstones.go)
+	tombstonesReaped metrics.Counter
+	// Size of the store and entries evicted (see capacity.go)
+	storageKeys  metrics.Gauge
+	storageBytes metrics.Gauge
+	evictions    metrics.Counter
+}
+
+// newInstruments registers a node's instruments with sink
+func newInstruments(sink metrics.Sink) *instruments {
+	i := &instruments{
+		lookups:       sink.Counter(metrics.Lookups),
+		lookupErrors:  sink.Counter(metrics.LookupErrors),
+		lookupHops:    sink.Counter(metrics.LookupHops),
+		lookupLatency: sink.Timer(metrics.LookupLatency),
+		messages:      sink.Counter(metrics.Messages),
+		payloadBytes:  sink.Counter(metrics.PayloadBytes),
+		wireBytes:     sink.Counter(metrics.WireBytes),
+		bytesSent:     make(map[string]metrics.Counter),
+		bytesReceived: make(map[string]metrics.Counter),
+
+		fingerAccuracy:   sink.Gauge(metrics.FingerAccuracy),
+		lookupSaturation: sink.Gauge(metrics.LookupSaturation),
+		lookupsRejected:  sink.Counter(metrics.LookupsRejected),
+
+		reReplications:       sink.Counter(metrics.ReReplications),
+		reReplicatedKeys:     sink.Counter(metrics.ReReplicatedKeys),
+		reReplicationErrors:  sink.Counter(metrics.ReReplicationErrors),
+		reReplicationPending: sink.Gauge(metrics.ReReplicationPending),
+
+		tombstonesReaped: sink.Counter(metrics.TombstonesReaped),
+		storageKeys:      sink.Gauge(metrics.StorageKeys),
+		storageBytes:     sink.Gauge(metrics.StorageBytes),
+		evictions:        sink.Counter(metrics.Evictions),
+	}
+	for _, category := range metrics.Categories {
+		i.bytesSent[category] = sink.Counter(metrics.BytesSent(category))
+		i.bytesReceived[category] = sink.Counter(metrics.BytesReceived(category))
+	}
+	return i
+}
+
+// SetMetrics sets the sink the node records its lookups and the RPCs it
+// serves into (see the names in package metrics). It may be called at any
+// time; metrics.Discard, the default, drops them.
+func (n *Node) SetMetrics(sink metrics.Sink) {
+	if sink == nil {
+		sink = metrics.Discard
+	}
+	n.instruments.Store(newInstruments(sink))
+}
+
+// countMessage counts an RPC served by the node
+func (n *Node) countMessage() {
+	n.MessageCount.Add(1)
+	n.instruments.Load().messages.Add(1)
+}
+
+// recordLookup records a finished lookup
+func (i *instruments) recordLookup(hops int, latency time.Duration, err error) {
+	if err != nil {
+		i.lookupErrors.Add(1)
+		return
+	}
+	i.lookups.Add(1)
+	i.lookupHops.Add(int64(hops))
+	i.lookupLatency.Observe(latency)
+}
@@ -0,0 +1,41 @@
+package chord
+
+import (
+	"testing"
+
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+)
+
+func TestSetMetrics(t *testing.T) {
+	nodes := startTestRing(t, 8493, 2)
+	sinks := []*metrics.Memory{metrics.NewMemory(), metrics.NewMemory()}
+	for i, node := range nodes {
+		node.SetMetrics(sinks[i])
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := nodes[0].LookupHops(hash.NewHashFromString(string(rune('a' + i)))); err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+	}
+	if lookups := sinks[0].CounterValue(metrics.Lookups); lookups != 10 {
+		t.Errorf("Expected 10 lookups, got %d", lookups)
+	}
+	if observed := len(sinks[0].Observations(metrics.LookupLatency)); observed != 10 {
+		t.Errorf("Expected 10 lookup latencies, got %d", observed)
+	}
+
+	// Stabilization asks the successor for its predecessor
+	nodes[0].stabilize()
+	if messages := sinks[1].CounterValue(metrics.Messages); messages == 0 {
+		t.Error("Expected the RPCs served by the successor to be counted")
+	}
+
+	// A nil sink drops everything again
+	nodes[0].SetMetrics(nil)
+	nodes[0].LookupHops(hash.NewHashFromString("z"))
+	if lookups := sinks[0].CounterValue(metrics.Lookups); lookups != 10 {
+		t.Errorf("Expected no lookups recorded after SetMetrics(nil), got %d", lookups)
+	}
+}
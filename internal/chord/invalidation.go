@@ -0,0 +1,256 @@
+package chord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// invalidateBroadcast is the broadcast kind of cache invalidations
+	invalidateBroadcast = "chord.invalidate"
+	// DefaultCacheLease is how long an owner sends invalidations for a key
+	// after it was advertised as cached
+	DefaultCacheLease = time.Minute
+)
+
+// InvalidationPolicy configures cache invalidation broadcasts. When a key
+// that some node advertised caching (see AdvertiseCached) is overwritten or
+// deleted, its owner broadcasts an invalidation for it through the ring.
+// Keys nobody advertised are written without a broadcast.
+type InvalidationPolicy struct {
+	// Broadcast enables invalidation broadcasts on this node as an owner
+	Broadcast bool
+}
+
+// InvalidationHandler is called with every key invalidated in the ring
+type InvalidationHandler func(key string)
+
+// InvalidationStats counts the invalidations sent and received by a node
+type InvalidationStats struct {
+	// Advertised is the number of keys owned here with a live cache lease
+	Advertised int
+	// Sent is the number of keys this node broadcast invalidations for
+	Sent int64
+	// Received is the number of invalidated keys delivered to this node
+	Received int64
+}
+
+// invalidation holds the keys advertised as cached and the handlers of
+// invalidations
+type invalidation struct {
+	mu       sync.Mutex
+	policy   InvalidationPolicy
+	leases   map[string]time.Time // cached key -> end of its lease
+	handlers []InvalidationHandler
+	stats    InvalidationStats
+}
+
+// SetInvalidation sets whether this node broadcasts invalidations for the
+// cached keys it owns
+func (n *Node) SetInvalidation(policy InvalidationPolicy) {
+	n.invalidation.mu.Lock()
+	defer n.invalidation.mu.Unlock()
+
+	n.invalidation.policy = policy
+}
+
+// OnInvalidate registers a handler called on this node with every key
+// invalidated anywhere in the ring, such as to drop a cached copy
+func (n *Node) OnInvalidate(handler InvalidationHandler) {
+	n.invalidation.mu.Lock()
+	defer n.invalidation.mu.Unlock()
+
+	n.invalidation.handlers = append(n.invalidation.handlers, handler)
+}
+
+// InvalidationStats returns counters for cache invalidations
+func (n *Node) InvalidationStats() InvalidationStats {
+	n.invalidation.mu.Lock()
+	defer n.invalidation.mu.Unlock()
+
+	n.invalidation.expireLocked(time.Now())
+	stats := n.invalidation.stats
+	stats.Advertised = len(n.invalidation.leases)
+	return stats
+}
+
+// AdvertiseCached tells the owners of keys that this node caches copies of
+// them, so that overwriting or deleting one broadcasts an invalidation for
+// the next lease (DefaultCacheLease if zero). Advertisements are not handed
+// off with the keys: a cache re-advertises the keys it holds before their
+// lease ends.
+func (n *Node) AdvertiseCached(ctx context.Context, keys []string, lease time.Duration) error {
+	if lease <= 0 {
+		lease = DefaultCacheLease
+	}
+	groups, err := n.groupByOwner(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for address, groupKeys := range groups {
+		wg.Add(1)
+		go func(address string, keys []string) {
+			defer wg.Done()
+			if err := n.advertiseCacheAt(ctx, address, keys, lease); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+		}(address, groupKeys)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// advertiseCacheAt advertises cached keys to the node at address,
+// short-circuiting locally
+func (n *Node) advertiseCacheAt(ctx context.Context, address string, keys []string, lease time.Duration) error {
+	if address == n.address {
+		n.invalidation.lease(keys, lease)
+		return nil
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.AdvertiseCache(ctx, &pb.AdvertiseCacheRequest{
+		Keys:    keys,
+		LeaseMs: lease.Milliseconds(),
+	})
+	if err != nil {
+		return fromStatus(address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("advertise cache to %s failed: %s", address, resp.Error)
+	}
+	return nil
+}
+
+// lease records keys as cached until lease from now
+func (v *invalidation) lease(keys []string, lease time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.leases == nil {
+		v.leases = make(map[string]time.Time)
+	}
+	until := time.Now().Add(lease)
+	for _, key := range keys {
+		if until.After(v.leases[key]) {
+			v.leases[key] = until
+		}
+	}
+}
+
+// expireLocked forgets the leases that ended before now. The caller must
+// hold mu.
+func (v *invalidation) expireLocked(now time.Time) {
+	for key, until := range v.leases {
+		if now.After(until) {
+			delete(v.leases, key)
+		}
+	}
+}
+
+// claim returns the keys among the written ones that are advertised as
+// cached and ends their leases: a cache that drops its copy re-advertises
+// the key when it caches it again
+func (v *invalidation) claim(keys []string) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.policy.Broadcast || len(v.leases) == 0 {
+		return nil
+	}
+	v.expireLocked(time.Now())
+
+	var cached []string
+	for _, key := range keys {
+		if _, ok := v.leases[key]; ok {
+			delete(v.leases, key)
+			cached = append(cached, key)
+		}
+	}
+	v.stats.Sent += int64(len(cached))
+	return cached
+}
+
+// invalidateKeys broadcasts an invalidation for the written keys that are
+// advertised as cached, and refreshes their hot copies (see hotkeys.go). It
+// is called by the owner after a write is applied; failures are logged.
+func (n *Node) invalidateKeys(ctx context.Context, keys []string) {
+	n.refreshHotCopies(ctx, keys)
+
+	cached := n.invalidation.claim(keys)
+	if len(cached) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(cached)
+	if err != nil {
+		log.Printf("Node %s: failed to encode invalidation: %v", n.id.Short(), err)
+		return
+	}
+	if _, err := n.Broadcast(ctx, invalidateBroadcast, payload); err != nil {
+		log.Printf("Node %s: failed to broadcast invalidation of %d keys: %v",
+			n.id.Short(), len(cached), err)
+	}
+}
+
+// handleInvalidationBroadcasts registers the handler delivering
+// invalidations to the handlers set with OnInvalidate
+func (n *Node) handleInvalidationBroadcasts() {
+	n.HandleBroadcast(invalidateBroadcast, func(msg *BroadcastMessage) {
+		var keys []string
+		if err := json.Unmarshal(msg.Payload, &keys); err != nil {
+			log.Printf("Node %s: dropping malformed invalidation from %s: %v",
+				n.id.Short(), msg.Origin.Address, err)
+			return
+		}
+
+		n.invalidation.mu.Lock()
+		n.invalidation.stats.Received += int64(len(keys))
+		handlers := append([]InvalidationHandler(nil), n.invalidation.handlers...)
+		n.invalidation.mu.Unlock()
+
+		for _, key := range keys {
+			for _, handler := range handlers {
+				handler(key)
+			}
+		}
+	})
+}
+
+// AdvertiseCache records keys cached by the caller
+func (n *Node) AdvertiseCache(ctx context.Context, req *pb.AdvertiseCacheRequest) (*pb.AdvertiseCacheResponse, error) {
+	n.countMessage()
+
+	if req.LeaseMs <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "lease must be positive")
+	}
+	n.invalidation.lease(req.Keys, time.Duration(req.LeaseMs)*time.Millisecond)
+	return &pb.AdvertiseCacheResponse{Success: true}, nil
+}
@@ -0,0 +1,83 @@
+package chord
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheInvalidation(t *testing.T) {
+	nodes := startTestRing(t, 8472, 3)
+
+	var mu sync.Mutex
+	invalidated := make(map[string][]string)
+	for _, node := range nodes {
+		node.SetInvalidation(InvalidationPolicy{Broadcast: true})
+		address := node.GetAddress()
+		node.OnInvalidate(func(key string) {
+			mu.Lock()
+			invalidated[address] = append(invalidated[address], key)
+			mu.Unlock()
+		})
+	}
+	received := func(node *Node) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return invalidated[node.GetAddress()]
+	}
+
+	ctx := context.Background()
+	if err := nodes[0].StoreBatch(ctx, map[string][]byte{"cached": []byte("v1"), "plain": []byte("v1")}); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+	if err := nodes[1].AdvertiseCached(ctx, []string{"cached"}, time.Minute); err != nil {
+		t.Fatalf("AdvertiseCached failed: %v", err)
+	}
+
+	// Writes to keys nobody caches are not broadcast
+	if err := nodes[2].StoreValue(ctx, "plain", []byte("v2")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	for _, node := range nodes {
+		if got := received(node); len(got) != 0 {
+			t.Errorf("Node %s received invalidations %v for an uncached key", node.GetAddress(), got)
+		}
+	}
+
+	if err := nodes[2].StoreValue(ctx, "cached", []byte("v2")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	for _, node := range nodes {
+		if got := received(node); len(got) != 1 || got[0] != "cached" {
+			t.Errorf("Node %s received invalidations %v, expected [cached]", node.GetAddress(), got)
+		}
+	}
+
+	// The lease ends with the invalidation until the key is cached again
+	if err := nodes[2].StoreValue(ctx, "cached", []byte("v3")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	if got := received(nodes[1]); len(got) != 1 {
+		t.Errorf("Expected no invalidation without a new advertisement, got %v", got)
+	}
+
+	if err := nodes[1].AdvertiseCached(ctx, []string{"cached"}, time.Minute); err != nil {
+		t.Fatalf("AdvertiseCached failed: %v", err)
+	}
+	result, err := nodes[0].CompareAndSwap(ctx, ConditionalWrite{Key: "cached", Expected: []byte("v3"), Delete: true})
+	if err != nil || !result.Applied {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got := received(nodes[1]); len(got) != 2 {
+		t.Errorf("Expected a delete to invalidate the key, got %v", got)
+	}
+
+	var sent int64
+	for _, node := range nodes {
+		sent += node.InvalidationStats().Sent
+	}
+	if sent != 2 {
+		t.Errorf("Expected 2 invalidations sent, got %d", sent)
+	}
+}
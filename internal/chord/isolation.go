@@ -0,0 +1,328 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// IsolationPolicy configures a node that lost contact with the whole ring
+type IsolationPolicy struct {
+	// BufferWrites is the number of keys whose writes are held while
+	// isolated and stored in the ring once the node rejoins; 0 rejects
+	// writes with ErrIsolated
+	BufferWrites int
+}
+
+// IsolationStatus is a node's isolation state
+type IsolationStatus struct {
+	Isolated bool
+	Since    time.Time
+	// Buffered is the number of keys written while isolated and not yet
+	// stored in the ring
+	Buffered int
+	// RejoinAttempts counts the attempts to rejoin since the node was
+	// isolated
+	RejoinAttempts int
+	// Peers is the number of last known peers every attempt goes through
+	Peers int
+}
+
+// isolation is the state of a node whose successors, fingers and
+// predecessor all stopped answering
+type isolation struct {
+	mu       sync.Mutex
+	policy   IsolationPolicy
+	isolated bool
+	since    time.Time
+	peers    []*NodeInfo
+	buffer   map[string][]byte
+	attempts int
+}
+
+// SetIsolationPolicy configures how the node behaves while isolated
+func (n *Node) SetIsolationPolicy(policy IsolationPolicy) {
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	n.isolation.policy = policy
+}
+
+// Isolated reports whether the node lost contact with the whole ring and
+// has not rejoined it yet
+func (n *Node) Isolated() bool {
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	return n.isolation.isolated
+}
+
+// IsolationStatus returns the node's isolation state
+func (n *Node) IsolationStatus() IsolationStatus {
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	return IsolationStatus{
+		Isolated:       n.isolation.isolated,
+		Since:          n.isolation.since,
+		Buffered:       len(n.isolation.buffer),
+		RejoinAttempts: n.isolation.attempts,
+		Peers:          len(n.isolation.peers),
+	}
+}
+
+// checkIsolation looks for a live peer once the successor list ran out. The
+// closest live finger or predecessor becomes the successor; with none left
+// the node is isolated. A node alone in the ring it created has no peers to
+// lose and is not isolated.
+func (n *Node) checkIsolation() {
+	n.mu.RLock()
+	alone := n.successor != nil && n.successor.Address == n.address
+	seen := map[string]bool{n.address: true}
+	var peers []*NodeInfo
+	for _, node := range append([]*NodeInfo{n.predecessor}, n.fingers...) {
+		if node != nil && !seen[node.Address] {
+			seen[node.Address] = true
+			peers = append(peers, node)
+		}
+	}
+	n.mu.RUnlock()
+
+	if !alone || len(peers) == 0 || n.Isolated() {
+		return
+	}
+
+	// Closest clockwise first, so the first live peer is the best successor
+	sort.Slice(peers, func(i, j int) bool {
+		return n.id.Distance(peers[i].ID).Cmp(n.id.Distance(peers[j].ID)) < 0
+	})
+	live := make([]bool, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			live[i] = n.pingWithTimeout(peer.Address) == nil
+		}()
+	}
+	wg.Wait()
+
+	for i, peer := range peers {
+		if !live[i] {
+			continue
+		}
+		n.mu.Lock()
+		if n.successor != nil && n.successor.Address == n.address {
+			n.successor = peer
+			n.successorList = []*NodeInfo{peer}
+			n.recordMembership(MemberJoined, RoleSuccessor, peer, nil)
+			log.Printf("Node %s: successor list exhausted, falling back to %s",
+				n.id.Short(), peer.ID.Short())
+		}
+		n.mu.Unlock()
+		return
+	}
+	n.isolate(peers)
+}
+
+// isolate enters the isolated state and starts rejoining through peers
+func (n *Node) isolate(peers []*NodeInfo) {
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	if n.isolation.isolated {
+		return
+	}
+	n.isolation.isolated = true
+	n.isolation.since = time.Now()
+	n.isolation.peers = peers
+	n.isolation.attempts = 0
+
+	writes := "rejecting writes"
+	if n.isolation.policy.BufferWrites > 0 {
+		writes = fmt.Sprintf("buffering up to %d written keys", n.isolation.policy.BufferWrites)
+	}
+	log.Printf("Node %s: isolated, none of %d known peers answers; serving local reads as stale and %s until it rejoins",
+		n.id.Short(), len(peers), writes)
+
+	// Called from stabilize, whose goroutine keeps the wait group above zero
+	n.wg.Add(1)
+	go n.rejoinIsolated()
+}
+
+// rejoinIsolated rejoins the ring through the last known peers, unless a
+// peer found us first, and then stores the buffered writes in the ring
+func (n *Node) rejoinIsolated() {
+	defer n.wg.Done()
+
+	err := n.RetryPolicies().Rejoin.Do(n.ctx, func(int) error {
+		n.isolation.mu.Lock()
+		n.isolation.attempts++
+		peers := n.isolation.peers
+		n.isolation.mu.Unlock()
+
+		if successor := n.GetSuccessor(); successor == nil || successor.Address == n.address {
+			if err := n.rejoinThrough(peers); err != nil {
+				return err
+			}
+		}
+		return n.flushIsolated()
+	})
+	if err != nil {
+		// Only a stopped node gives up
+		return
+	}
+
+	status := n.IsolationStatus()
+	log.Printf("Node %s: rejoined the ring after %v isolated (%d attempts)",
+		n.id.Short(), time.Since(status.Since).Truncate(time.Second), status.RejoinAttempts)
+}
+
+// rejoinThrough joins the ring through the first peer that answers
+func (n *Node) rejoinThrough(peers []*NodeInfo) error {
+	for _, peer := range peers {
+		successor, err := n.askSuccessor(peer.Address)
+		if err == nil && successor.ID.Equal(n.id) {
+			// The peer still routes our ID to us; start from it and let
+			// stabilization walk back to our successor
+			successor = peer
+		}
+		if err == nil {
+			err = n.joinSuccessor(peer.Address, successor)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: none of %d known peers answers", ErrPeerUnreachable, len(peers))
+}
+
+// flushIsolated stores the writes buffered while isolated in the ring and
+// leaves the isolated state once none is left. Writes keep being buffered
+// until then, so none is overwritten by an older buffered one.
+func (n *Node) flushIsolated() error {
+	for {
+		n.isolation.mu.Lock()
+		buffer := n.isolation.buffer
+		n.isolation.buffer = nil
+		if len(buffer) == 0 {
+			n.isolation.isolated = false
+			n.isolation.mu.Unlock()
+			return nil
+		}
+		n.isolation.mu.Unlock()
+
+		if err := n.storeBuffered(buffer); err != nil {
+			n.isolation.mu.Lock()
+			for key, value := range buffer {
+				// Writes buffered meanwhile are newer
+				if _, ok := n.isolation.buffer[key]; !ok {
+					if n.isolation.buffer == nil {
+						n.isolation.buffer = make(map[string][]byte)
+					}
+					n.isolation.buffer[key] = value
+				}
+			}
+			n.isolation.mu.Unlock()
+			return fmt.Errorf("failed to store writes buffered while isolated: %w", err)
+		}
+	}
+}
+
+// storeBuffered stores buffered writes at their owners. Unlike StoreBatch it
+// does not buffer them again.
+func (n *Node) storeBuffered(buffer map[string][]byte) error {
+	keys := make([]string, 0, len(buffer))
+	for key := range buffer {
+		keys = append(keys, key)
+	}
+	groups, err := n.groupByOwner(n.ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	for address, groupKeys := range groups {
+		batch := make([]*pb.KeyValue, 0, len(groupKeys))
+		for _, key := range groupKeys {
+			batch = append(batch, &pb.KeyValue{Key: key, Value: buffer[key]})
+		}
+		if err := n.putBatchAt(ctx, address, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bufferIsolated holds a batch of writes while the node is isolated. It
+// reports false if the node is not isolated, and returns ErrIsolated if
+// buffering is off or the batch does not fit.
+func (n *Node) bufferIsolated(batch []*pb.KeyValue) (bool, error) {
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	if !n.isolation.isolated {
+		return false, nil
+	}
+	added := 0
+	for _, item := range batch {
+		if _, ok := n.isolation.buffer[item.Key]; !ok {
+			added++
+		}
+	}
+	if len(n.isolation.buffer)+added > n.isolation.policy.BufferWrites {
+		return false, fmt.Errorf("%w: %d writes buffered, limit %d",
+			ErrIsolated, len(n.isolation.buffer), n.isolation.policy.BufferWrites)
+	}
+	if n.isolation.buffer == nil {
+		n.isolation.buffer = make(map[string][]byte)
+	}
+	for _, item := range batch {
+		n.isolation.buffer[item.Key] = item.Value
+	}
+	return true, nil
+}
+
+// loadServed reads keys from the local store for a client. While isolated
+// the writes buffered since are read back too, and the result is stale;
+// requests asking for ConsistencyStrong get ErrIsolated instead.
+func (n *Node) loadServed(ctx context.Context, keys []string) ([]*pb.KeyValue, bool, error) {
+	items, err := n.loadLocal(keys)
+	if err != nil {
+		return nil, false, err
+	}
+
+	n.isolation.mu.Lock()
+	defer n.isolation.mu.Unlock()
+
+	if !n.isolation.isolated {
+		return items, false, nil
+	}
+	if requestConsistency(ctx) == ConsistencyStrong {
+		return nil, false, fmt.Errorf("%w: strongly consistent read", ErrIsolated)
+	}
+	if len(n.isolation.buffer) == 0 {
+		return items, true, nil
+	}
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		if value, ok := n.isolation.buffer[item.Key]; ok {
+			item.Value = value
+		}
+		found[item.Key] = true
+	}
+	for _, key := range keys {
+		if value, ok := n.isolation.buffer[key]; ok && !found[key] {
+			items = append(items, &pb.KeyValue{Key: key, Value: value})
+			found[key] = true
+		}
+	}
+	return items, true, nil
+}
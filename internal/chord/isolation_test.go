@@ -0,0 +1,89 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+func TestIsolation(t *testing.T) {
+	nodes := startTestRing(t, 8461, 2)
+	node, peer := nodes[0], nodes[1]
+
+	policies := node.RetryPolicies()
+	policies.Rejoin.InitialDelay = 50 * time.Millisecond
+	policies.Rejoin.MaxDelay = 200 * time.Millisecond
+	node.SetRetryPolicies(policies)
+	node.SetIsolationPolicy(IsolationPolicy{BufferWrites: 2})
+
+	peer.Stop()
+	node.stabilize()
+	if !node.Isolated() {
+		t.Fatal("Expected the node to be isolated once its only peer failed")
+	}
+	if err := node.Ready(); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected an isolated node not to be ready, got %v", err)
+	}
+
+	ctx := context.Background()
+	if err := node.StoreValue(ctx, "buffered", []byte("v1")); err != nil {
+		t.Fatalf("Expected the write to be buffered, got %v", err)
+	}
+	resp, err := node.Get(ctx, &pb.GetRequest{Key: "buffered"})
+	if err != nil || !resp.Found || !resp.Stale || string(resp.Value) != "v1" {
+		t.Errorf("Expected a stale read of the buffered write, got %+v, %v", resp, err)
+	}
+	if err := node.StoreBatch(ctx, map[string][]byte{"a": nil, "b": nil}); !errors.Is(err, ErrIsolated) {
+		t.Errorf("Expected ErrIsolated beyond the buffer, got %v", err)
+	}
+	if _, err := node.CompareAndSwap(ctx, ConditionalWrite{Key: "buffered"}); !errors.Is(err, ErrIsolated) {
+		t.Errorf("Expected ErrIsolated for a conditional write, got %v", err)
+	}
+
+	// The peer comes back as a ring of its own; the node rejoins through it
+	restarted := NewNode(peer.GetAddress(), hash.NewHashFromString(peer.GetAddress()))
+	if err := restarted.Start(); err != nil {
+		t.Fatalf("Failed to restart peer: %v", err)
+	}
+	t.Cleanup(restarted.Stop)
+	if err := restarted.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for node.Isolated() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	status := node.IsolationStatus()
+	if status.Isolated || status.Buffered != 0 {
+		t.Fatalf("Expected the node to rejoin and store its buffered writes, got %+v", status)
+	}
+
+	value, err := restarted.FetchValue(ctx, "buffered")
+	if err != nil || string(value) != "v1" {
+		t.Errorf("Expected the buffered write in the ring, got %q, %v", value, err)
+	}
+}
+
+func TestSingleNodeRingNotIsolated(t *testing.T) {
+	node := NewNode("localhost:8463", nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	node.checkIsolation()
+	if node.Isolated() {
+		t.Error("A node alone in the ring it created should not be isolated")
+	}
+	if err := node.StoreValue(context.Background(), "key", []byte("value")); err != nil {
+		t.Errorf("Expected writes to a single node ring to succeed, got %v", err)
+	}
+}
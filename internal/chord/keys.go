@@ -0,0 +1,116 @@
+package chord
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultKeyPage is the number of keys a ListKeys call returns when it
+	// asks for none
+	DefaultKeyPage = 1000
+	// MaxKeyPage bounds the keys returned by one ListKeys call
+	MaxKeyPage = 10000
+)
+
+// ErrInvalidCursor is returned for a ListKeys cursor this node did not
+// hand out
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor and decodeCursor convert the last key of a page to the
+// opaque cursor of the next one
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return string(key), nil
+}
+
+// ListLocalKeys returns up to limit live keys starting with prefix that
+// this node owns, sorted, from cursor on, and the cursor of the next page,
+// empty after the last. The node's internal keys, such as tag indexes, are
+// left out. An empty cursor starts at the first key; cursors stay valid
+// across writes, so keys written meanwhile may or may not be listed.
+func (n *Node) ListLocalKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	items, next, err := n.listLocal(prefix, cursor, limit, false)
+	if err != nil {
+		return nil, "", err
+	}
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.Key
+	}
+	return keys, next, nil
+}
+
+// listLocal returns a page of ListLocalKeys, with the values if asked
+func (n *Node) listLocal(prefix, cursor string, limit int, values bool) ([]*pb.KeyValue, string, error) {
+	after := ""
+	if cursor != "" {
+		var err error
+		if after, err = decodeCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultKeyPage
+	}
+	limit = min(limit, MaxKeyPage)
+
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	now := time.Now()
+	var items []*pb.KeyValue
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if strings.HasPrefix(key, prefix) && !strings.HasPrefix(key, "\x00") &&
+			(cursor == "" || key > after) && e.Live(now) && n.ownsLocked(n.KeyID(key)) {
+			item := &pb.KeyValue{Key: key}
+			if values {
+				item.Value = e.Value
+			}
+			items = append(items, item)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list keys: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	if len(items) <= limit {
+		return items, "", nil
+	}
+	items = items[:limit]
+	return items, encodeCursor(items[limit-1].Key), nil
+}
+
+// ListKeys returns a page of the keys owned by this node
+func (n *Node) ListKeys(ctx context.Context, req *pb.ListKeysRequest) (*pb.ListKeysResponse, error) {
+	n.countMessage()
+
+	items, next, err := n.listLocal(req.Prefix, req.Cursor, int(req.Limit), req.Values)
+	if errors.Is(err, ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ListKeysResponse{Items: items, NextCursor: next}, nil
+}
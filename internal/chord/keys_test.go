@@ -0,0 +1,67 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestListLocalKeys(t *testing.T) {
+	nodes := startTestRing(t, 8632, 2)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		if err := nodes[0].StoreValue(ctx, fmt.Sprintf("scan/%02d", i), []byte("value")); err != nil {
+			t.Fatalf("StoreValue failed: %v", err)
+		}
+	}
+	if err := nodes[0].StoreTagged(ctx, "other", []byte("value"), []string{"tag"}); err != nil {
+		t.Fatalf("StoreTagged failed: %v", err)
+	}
+
+	// Every key is listed once, by its owner, and replicas are left out
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		last := ""
+		for cursor, pages := "", 0; pages == 0 || cursor != ""; pages++ {
+			keys, next, err := node.ListLocalKeys("scan/", cursor, 3)
+			if err != nil {
+				t.Fatalf("ListLocalKeys failed: %v", err)
+			}
+			if len(keys) > 3 {
+				t.Errorf("Expected at most 3 keys a page, got %d", len(keys))
+			}
+			for _, key := range keys {
+				if key <= last || seen[key] {
+					t.Errorf("Key %q listed out of order or twice", key)
+				}
+				last, seen[key] = key, true
+			}
+			cursor = next
+		}
+	}
+	if len(seen) != 20 {
+		t.Errorf("Expected 20 keys, got %d", len(seen))
+	}
+
+	// Internal keys, such as the tag index, are never listed
+	var all int
+	for _, node := range nodes {
+		keys, _, err := node.ListLocalKeys("", "", MaxKeyPage)
+		if err != nil {
+			t.Fatalf("ListLocalKeys failed: %v", err)
+		}
+		all += len(keys)
+	}
+	if all != 21 {
+		t.Errorf("Expected 21 keys without a prefix, got %d", all)
+	}
+
+	if _, _, err := nodes[0].ListLocalKeys("", "!", 0); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLivenessTTL is how long the outcome of contacting a peer
+	// stands before probing it pings the peer again
+	DefaultLivenessTTL = 2 * time.Second
+	// livenessRTTWeight is the weight of a new ping in the smoothed RTT
+	livenessRTTWeight = 0.2
+	// maxLivenessPeers bounds the peers remembered; beyond it, peers not
+	// heard from within the TTL are forgotten
+	maxLivenessPeers = 1024
+)
+
+// PeerLiveness is what this node knows about whether a peer is up. Pings
+// and the node's other RPCs to the peer both update it.
+type PeerLiveness struct {
+	Alive bool
+	// RTT is the smoothed round trip time of pings, zero if unmeasured,
+	// and LastRTT the latest one
+	RTT     time.Duration
+	LastRTT time.Duration
+	// Checked is when the peer last answered or failed to
+	Checked time.Time
+	// Failures is the number of consecutive failed contacts
+	Failures int
+	// Probes is the number of pings sent to the peer
+	Probes int64
+}
+
+// peerLiveness is a peer's entry in the liveness cache
+type peerLiveness struct {
+	PeerLiveness
+	// err is why the peer was last found unreachable
+	err error
+	// probe is closed when the ping in flight, if any, is answered
+	probe chan struct{}
+}
+
+// liveness is the liveness and RTT of the peers this node talks to, shared
+// by stabilization, finger fixing and routing so that a dead peer is only
+// probed once
+type liveness struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	peers map[string]*peerLiveness
+}
+
+// SetLivenessTTL sets how long the outcome of contacting a peer stands
+// before ProbePeer pings it again. Zero pings on every probe, though probes
+// of a peer already being pinged still wait for that ping.
+func (n *Node) SetLivenessTTL(ttl time.Duration) {
+	n.liveness.mu.Lock()
+	defer n.liveness.mu.Unlock()
+
+	n.liveness.ttl = ttl
+}
+
+// PeerLiveness returns what this node knows about whether the peer at
+// address is up, if it has contacted it
+func (n *Node) PeerLiveness(address string) (PeerLiveness, bool) {
+	n.liveness.mu.Lock()
+	defer n.liveness.mu.Unlock()
+
+	peer, ok := n.liveness.peers[address]
+	if !ok {
+		return PeerLiveness{}, false
+	}
+	return peer.PeerLiveness, true
+}
+
+// ProbePeer reports whether the peer at address is up and its smoothed RTT.
+// Within the liveness TTL of the last contact the known outcome is
+// returned; otherwise the peer is pinged, once for all concurrent probes.
+// A peer that is down yields an error matching ErrPeerUnreachable.
+func (n *Node) ProbePeer(ctx context.Context, address string) (time.Duration, error) {
+	l := &n.liveness
+	waited := false
+	for {
+		l.mu.Lock()
+		peer := l.peer(address)
+		if probe := peer.probe; probe != nil {
+			l.mu.Unlock()
+			select {
+			case <-probe:
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+			waited = true
+			continue
+		}
+		if !peer.Checked.IsZero() && (waited || time.Since(peer.Checked) < l.ttl) {
+			defer l.mu.Unlock()
+			return peer.outcome()
+		}
+		probe := make(chan struct{})
+		peer.probe = probe
+		peer.Probes++
+		l.mu.Unlock()
+
+		start := time.Now()
+		err := n.remotePing(ctx, address)
+		rtt := time.Since(start)
+		if ctx.Err() == nil {
+			n.observeReputation(address, err)
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		peer.probe = nil
+		close(probe)
+		// A probe given up by its caller says nothing about the peer
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		peer.observe(rtt, err)
+		return peer.outcome()
+	}
+}
+
+// peerAlive probes the peer at address and reports whether it is up
+func (n *Node) peerAlive(address string) bool {
+	_, err := n.ProbePeer(n.ctx, address)
+	return err == nil
+}
+
+// observeContact records the outcome of an RPC to the peer at address other
+// than a ping, as seen by a caller whose ctx was not done. Only transport
+// failures count against a peer, here and in its reputation (see
+// reputation.go); any answer shows it is up.
+func (n *Node) observeContact(address string, err error) {
+	n.observeReputation(address, err)
+
+	n.liveness.mu.Lock()
+	defer n.liveness.mu.Unlock()
+
+	n.liveness.peer(address).observe(0, err)
+}
+
+// peer returns the entry of the peer at address, adding it if needed.
+// Called with mu held.
+func (l *liveness) peer(address string) *peerLiveness {
+	if peer, ok := l.peers[address]; ok {
+		return peer
+	}
+	if l.peers == nil {
+		l.peers = make(map[string]*peerLiveness)
+	}
+	if len(l.peers) >= maxLivenessPeers {
+		for other, peer := range l.peers {
+			if peer.probe == nil && time.Since(peer.Checked) >= l.ttl {
+				delete(l.peers, other)
+			}
+		}
+	}
+	peer := &peerLiveness{}
+	l.peers[address] = peer
+	return peer
+}
+
+// observe records a contact with the peer; rtt is that of a ping, zero for
+// other RPCs
+func (p *peerLiveness) observe(rtt time.Duration, err error) {
+	p.Checked = time.Now()
+	if errors.Is(err, ErrPeerUnreachable) {
+		p.Alive = false
+		p.Failures++
+		p.err = err
+		return
+	}
+	p.Alive = true
+	p.Failures = 0
+	p.err = nil
+	if rtt > 0 {
+		p.LastRTT = rtt
+		if p.RTT == 0 {
+			p.RTT = rtt
+		} else {
+			p.RTT += time.Duration(livenessRTTWeight * float64(rtt-p.RTT))
+		}
+	}
+}
+
+// outcome returns the RTT of a peer found up, or why it is down
+func (p *peerLiveness) outcome() (time.Duration, error) {
+	if !p.Alive {
+		if p.err == nil {
+			return 0, ErrPeerUnreachable
+		}
+		return 0, p.err
+	}
+	return p.RTT, nil
+}
@@ -0,0 +1,51 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProbePeer(t *testing.T) {
+	nodes := startTestRing(t, 8651, 2)
+	prober, peer := nodes[0], nodes[1]
+
+	// Stabilization already found the peer up, but measured no RTT
+	ctx := context.Background()
+	if known, ok := prober.PeerLiveness(peer.GetAddress()); !ok || !known.Alive {
+		t.Fatalf("Expected stabilization to find the peer up, got %+v", known)
+	}
+	prober.SetLivenessTTL(0)
+	rtt, err := prober.ProbePeer(ctx, peer.GetAddress())
+	if err != nil || rtt <= 0 {
+		t.Fatalf("Expected the peer up with an RTT, got %v, %v", rtt, err)
+	}
+	prober.SetLivenessTTL(time.Minute)
+	before, _ := prober.PeerLiveness(peer.GetAddress())
+
+	// Probes within the TTL reuse the last contact
+	for i := 0; i < 3; i++ {
+		if _, err := prober.ProbePeer(ctx, peer.GetAddress()); err != nil {
+			t.Fatalf("ProbePeer failed: %v", err)
+		}
+	}
+	if after, _ := prober.PeerLiveness(peer.GetAddress()); !after.Alive || after.Probes != before.Probes {
+		t.Errorf("Expected no pings within the TTL, got %+v after %+v", after, before)
+	}
+
+	// A dead peer is pinged once, and its failure shared until the TTL ends
+	peer.Stop()
+	prober.SetLivenessTTL(0)
+	if _, err := prober.ProbePeer(ctx, peer.GetAddress()); !errors.Is(err, ErrPeerUnreachable) {
+		t.Fatalf("Expected the stopped peer unreachable, got %v", err)
+	}
+	prober.SetLivenessTTL(time.Minute)
+	dead, _ := prober.PeerLiveness(peer.GetAddress())
+	if prober.peerAlive(peer.GetAddress()) {
+		t.Error("Expected the stopped peer down")
+	}
+	if after, _ := prober.PeerLiveness(peer.GetAddress()); after.Alive || after.Probes != dead.Probes || after.Failures != 1 {
+		t.Errorf("Expected one failed ping, got %+v", after)
+	}
+}
@@ -0,0 +1,155 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+func startRing(t *testing.T, address string) *chord.Node {
+	node := chord.NewNode(address, nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	if err := node.Join(""); err != nil {
+		node.Stop()
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	return node
+}
+
+func TestAcquireRelease(t *testing.T) {
+	node := startRing(t, "localhost:8200")
+	defer node.Stop()
+
+	ctx := context.Background()
+	first := New(node)
+	second := New(node)
+
+	lock, err := first.Acquire(ctx, "resource", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if _, err := second.TryAcquire(ctx, "resource", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld on double release, got %v", err)
+	}
+
+	next, err := second.TryAcquire(ctx, "resource", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire after release failed: %v", err)
+	}
+	if next.Token <= lock.Token {
+		t.Errorf("Fencing token should increase: %d then %d", lock.Token, next.Token)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	node := startRing(t, "localhost:8201")
+	defer node.Stop()
+
+	ctx := context.Background()
+	first := New(node)
+	second := New(node)
+
+	lock, err := first.TryAcquire(ctx, "expiring", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+
+	// Acquire blocks until the first lease runs out
+	acquireCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	taken, err := second.Acquire(acquireCtx, "expiring", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire after expiry failed: %v", err)
+	}
+	if taken.Token <= lock.Token {
+		t.Errorf("Fencing token should increase: %d then %d", lock.Token, taken.Token)
+	}
+
+	// The original holder lost its lease
+	if err := lock.Refresh(ctx, time.Minute); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Expected ErrNotHeld on refresh of lost lease, got %v", err)
+	}
+}
+
+func TestTokenSurvivesTombstoneReaping(t *testing.T) {
+	node := startRing(t, "localhost:8202")
+	defer node.Stop()
+	node.SetTombstones(chord.TombstonePolicy{Horizon: time.Millisecond})
+
+	ctx := context.Background()
+	locker := New(node)
+	lock, err := locker.TryAcquire(ctx, "reaped", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	// The release left a tombstone long past the horizon
+	time.Sleep(10 * time.Millisecond)
+	if _, err := node.ReapTombstones(); err != nil {
+		t.Fatalf("ReapTombstones failed: %v", err)
+	}
+	next, err := locker.TryAcquire(ctx, "reaped", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire after reaping failed: %v", err)
+	}
+	if next.Token <= lock.Token {
+		t.Errorf("Fencing token should increase across reaping: %d then %d", lock.Token, next.Token)
+	}
+}
+
+func TestLocksSurviveEviction(t *testing.T) {
+	node := startRing(t, "localhost:8203")
+	defer node.Stop()
+	node.SetStorageLimits(chord.StorageLimits{MaxKeys: 4, Eviction: chord.EvictLRU})
+
+	ctx := context.Background()
+	first := New(node)
+	second := New(node)
+	held, err := first.TryAcquire(ctx, "held", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	expired, err := first.TryAcquire(ctx, "expired", time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// The locks are the least recently used, and the expired one goes first
+	for i := 0; i < 10; i++ {
+		if err := node.StoreValue(ctx, fmt.Sprintf("k%d", i), []byte("value")); err != nil {
+			t.Fatalf("StoreValue failed: %v", err)
+		}
+	}
+
+	if _, err := second.TryAcquire(ctx, "held", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked on a held lock after eviction, got %v", err)
+	}
+	taken, err := second.TryAcquire(ctx, "expired", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire after expiry failed: %v", err)
+	}
+	if taken.Token <= expired.Token {
+		t.Errorf("Fencing token should increase across eviction: %d then %d", expired.Token, taken.Token)
+	}
+	if err := held.Release(ctx); err != nil {
+		t.Errorf("Release after eviction failed: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+// lookupAlternates is the number of other preceding nodes a lookup hop is
+// retried at when the closest preceding finger is unreachable
+const lookupAlternates = 3
+
+// LookupContext resolves the node responsible for key like Lookup, giving
+// up when ctx is done. A hop that fails or does not answer within
+// RPCTimeout is routed around through the next best preceding finger or
+// successor list entry, and failed routes are retried with the backoff of
+// the Lookup retry policy.
+func (n *Node) LookupContext(ctx context.Context, key *hash.Hash) (*NodeInfo, error) {
+	return n.findSuccessor(ctx, key)
+}
+
+// SetLookupHedging sets the policy for hedged lookup hops. When the
+// closest preceding finger has not answered a lookup within the given
+// percentile of recent hop latencies, the lookup is also sent to the next
+// best preceding node; the first answer wins and the other is cancelled.
+func (n *Node) SetLookupHedging(policy HedgePolicy) {
+	n.lookupHedge.mu.Lock()
+	defer n.lookupHedge.mu.Unlock()
+
+	n.lookupHedge.policy = policy
+}
+
+// LookupHedgeStats returns counters for hedged lookup hops
+func (n *Node) LookupHedgeStats() HedgeStats {
+	n.lookupHedge.mu.Lock()
+	defer n.lookupHedge.mu.Unlock()
+
+	stats := n.lookupHedge.stats
+	stats.Delay = n.lookupHedge.delayLocked()
+	return stats
+}
+
+// forwardLookup sends a lookup for key to first and, while the nodes tried
+// are unreachable, to up to lookupAlternates other nodes preceding key
+func (n *Node) forwardLookup(ctx context.Context, key *hash.Hash, first *NodeInfo, req *pb.FindSuccessorRequest) (*pb.FindSuccessorResponse, error) {
+	hops := append([]*NodeInfo{first}, n.alternateHops(key, first)...)
+	// Hedges are optional work, skipped under pressure
+	if len(hops) > 1 && n.lookupHedge.enabled() && !n.shedding(PressureElevated) {
+		return n.hedgedLookup(ctx, hops, req)
+	}
+
+	var err error
+	for i, hop := range hops {
+		var resp *pb.FindSuccessorResponse
+		resp, err = n.askHop(ctx, hop.Address, req)
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, ErrPeerUnreachable) || ctx.Err() != nil {
+			return nil, err
+		}
+		if i+1 < len(hops) {
+			log.Printf("Node %s: lookup hop %s unreachable, trying %s: %v",
+				n.id.Short(), hop.Address, hops[i+1].Address, err)
+		}
+	}
+	return nil, err
+}
+
+// hopAnswer is the outcome of one leg of a hedged lookup hop
+type hopAnswer struct {
+	resp  *pb.FindSuccessorResponse
+	err   error
+	hedge bool
+}
+
+// hedgedLookup sends a lookup to hops[0] and, if it has not answered within
+// the hedge delay, to hops[1] as well. The first answer wins and cancels the
+// other leg; a leg whose node is unreachable is replaced by the next hop.
+func (n *Node) hedgedLookup(ctx context.Context, hops []*NodeInfo, req *pb.FindSuccessorRequest) (*pb.FindSuccessorResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make(chan hopAnswer, len(hops))
+	var (
+		pending int
+		hedged  bool
+		lastErr error
+	)
+	// send asks the next hop, reporting false if none is left
+	send := func(hedge bool) bool {
+		if len(hops) == 0 {
+			return false
+		}
+		hop := hops[0]
+		hops = hops[1:]
+		pending++
+		go func() {
+			start := time.Now()
+			resp, err := n.askHop(ctx, hop.Address, req)
+			if err == nil {
+				n.lookupHedge.observe(time.Since(start))
+			}
+			answers <- hopAnswer{resp: resp, err: err, hedge: hedge}
+		}()
+		return true
+	}
+	send(false)
+
+	timer := time.NewTimer(n.lookupHedge.delay())
+	defer timer.Stop()
+	hedgeC := timer.C
+
+	for pending > 0 {
+		select {
+		case <-hedgeC:
+			// At most two legs race; later hops only replace failed ones
+			hedgeC = nil
+			hedged = send(true)
+		case answer := <-answers:
+			pending--
+			if answer.err == nil {
+				n.lookupHedge.record(hedged, answer.hedge)
+				return answer.resp, nil
+			}
+			lastErr = answer.err
+			if errors.Is(answer.err, ErrPeerUnreachable) && ctx.Err() == nil {
+				send(false)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	n.lookupHedge.record(hedged, false)
+	return nil, lastErr
+}
+
+// alternateHops returns the distinct fingers and successor list entries
+// other than first that precede key, closest to key first, leaving out
+// blacklisted peers
+func (n *Node) alternateHops(key *hash.Hash, first *NodeInfo) []*NodeInfo {
+	n.mu.RLock()
+	candidates := make([]*NodeInfo, 0, len(n.fingers)+len(n.successorList))
+	candidates = append(candidates, n.fingers...)
+	candidates = append(candidates, n.successorList...)
+	n.mu.RUnlock()
+
+	seen := map[string]bool{n.address: true, first.Address: true}
+	var hops []*NodeInfo
+	for _, candidate := range candidates {
+		if candidate == nil || seen[candidate.Address] || !candidate.ID.InRangeExclusive(n.id, key) ||
+			n.blacklisted(candidate.Address) {
+			continue
+		}
+		seen[candidate.Address] = true
+		hops = append(hops, candidate)
+	}
+	sort.Slice(hops, func(i, j int) bool {
+		return n.id.Distance(hops[i].ID).Cmp(n.id.Distance(hops[j].ID)) > 0
+	})
+	if len(hops) > lookupAlternates {
+		hops = hops[:lookupAlternates]
+	}
+	return hops
+}
+
+// askHop sends a FindSuccessor request to the node at address, waiting at
+// most RPCTimeout for the lookup to complete from there
+func (n *Node) askHop(ctx context.Context, address string, req *pb.FindSuccessorRequest) (*pb.FindSuccessorResponse, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.FindSuccessor(ctx, req)
+	if ctx.Err() == nil {
+		n.observeContact(address, fromStatus(address, err))
+	}
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("remote error: %s", resp.Error)
+	}
+	if _, err := fromProtoNode(resp.Successor); err != nil {
+		n.reportMalformed(address, err)
+		return nil, fmt.Errorf("malformed lookup answer from %s: %w", address, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+func TestLookupRoutesAroundDeadHop(t *testing.T) {
+	nodes := startTestRing(t, 8477, 4)
+	for _, node := range nodes {
+		for i := 0; i < FingerTableSize; i++ {
+			node.fixFingers()
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].id.Less(nodes[j].id)
+	})
+	origin, target := nodes[0], nodes[3]
+
+	// The first hop is dead; one of the origin's other fingers takes over
+	dead := &NodeInfo{ID: nodes[2].id, Address: "localhost:1"}
+	resp, err := origin.forwardLookup(context.Background(), target.id, dead, &pb.FindSuccessorRequest{Key: target.id.String()})
+	if err != nil {
+		t.Fatalf("forwardLookup failed: %v", err)
+	}
+	if resp.Successor.Address != target.address {
+		t.Errorf("Expected owner %s, got %s", target.address, resp.Successor.Address)
+	}
+
+	// The caller's deadline bounds the whole lookup
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if _, err := origin.forwardLookup(ctx, target.id, dead, &pb.FindSuccessorRequest{Key: target.id.String()}); err == nil {
+		t.Error("Expected a cancelled lookup to fail")
+	}
+	if _, err := origin.LookupContext(ctx, target.id); !errors.Is(err, context.Canceled) && !errors.Is(err, ErrPeerUnreachable) {
+		t.Errorf("Expected a cancelled lookup error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Cancelled lookups took %v", elapsed)
+	}
+}
+
+func TestHedgedLookup(t *testing.T) {
+	nodes := startTestRing(t, 8485, 4)
+	for _, node := range nodes {
+		for i := 0; i < FingerTableSize; i++ {
+			node.fixFingers()
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].id.Less(nodes[j].id)
+	})
+	origin, target := nodes[0], nodes[3]
+
+	// A hedge delay this short always sends the second leg
+	origin.SetLookupHedging(HedgePolicy{Percentile: 0.95, MaxDelay: time.Nanosecond})
+	first := &NodeInfo{ID: nodes[2].id, Address: nodes[2].address}
+	resp, err := origin.forwardLookup(context.Background(), target.id, first, &pb.FindSuccessorRequest{Key: target.id.String()})
+	if err != nil {
+		t.Fatalf("forwardLookup failed: %v", err)
+	}
+	if resp.Successor.Address != target.address {
+		t.Errorf("Expected owner %s, got %s", target.address, resp.Successor.Address)
+	}
+	if stats := origin.LookupHedgeStats(); stats.Reads != 1 || stats.Hedged != 1 {
+		t.Errorf("Expected one hedged hop, got %+v", stats)
+	}
+
+	// A dead leg is replaced by the next best hop
+	dead := &NodeInfo{ID: nodes[2].id, Address: "localhost:1"}
+	resp, err = origin.forwardLookup(context.Background(), target.id, dead, &pb.FindSuccessorRequest{Key: target.id.String()})
+	if err != nil {
+		t.Fatalf("forwardLookup with a dead hop failed: %v", err)
+	}
+	if resp.Successor.Address != target.address {
+		t.Errorf("Expected owner %s, got %s", target.address, resp.Successor.Address)
+	}
+}
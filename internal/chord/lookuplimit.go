@@ -0,0 +1,175 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// lookupQueueTimeout bounds how long a lookup waits for a slot; it must
+	// leave the forwarding node time within its RPC timeout
+	lookupQueueTimeout = RPCTimeout / 2
+	// lookupRetryDelay is the delay sent with a rejected lookup
+	lookupRetryDelay = 100 * time.Millisecond
+)
+
+// LookupLimit bounds the lookups a node forwards at once on behalf of other
+// nodes and clients, each holding a goroutine and a connection until the
+// next hop answers. Lookups beyond it wait in a bounded queue; once it is
+// full they are rejected with ErrOverloaded and a delay after which to
+// retry. Lookups the node answers from its own state are never limited.
+type LookupLimit struct {
+	// MaxInFlight is the number of lookups forwarded at once. Zero
+	// disables the limit.
+	MaxInFlight int
+	// MaxQueue is the number of lookups held waiting for a slot
+	MaxQueue int
+}
+
+// LookupLimitStats counts the forwarded lookups handled by a node
+type LookupLimitStats struct {
+	Admitted int64
+	// Queued is the number of admitted lookups that had to wait
+	Queued   int64
+	Rejected int64
+	// InFlight and Waiting are the lookups being forwarded and queued now
+	InFlight int
+	Waiting  int
+}
+
+// Saturation returns the fraction of the limit in use, 0 without a limit
+func (s LookupLimitStats) Saturation(limit LookupLimit) float64 {
+	if limit.MaxInFlight <= 0 {
+		return 0
+	}
+	return float64(s.InFlight) / float64(limit.MaxInFlight)
+}
+
+// lookupLimiter is a semaphore with a FIFO queue of waiting lookups
+type lookupLimiter struct {
+	mu       sync.Mutex
+	limit    LookupLimit
+	inFlight int
+	// waiting are the queued lookups, woken by closing their channel
+	// when handed a slot
+	waiting []chan struct{}
+	stats   LookupLimitStats
+}
+
+// SetLookupLimit sets how many lookups this node forwards at once
+func (n *Node) SetLookupLimit(limit LookupLimit) {
+	if limit.MaxQueue < 0 {
+		limit.MaxQueue = 0
+	}
+
+	n.lookups.mu.Lock()
+	defer n.lookups.mu.Unlock()
+
+	n.lookups.limit = limit
+	n.lookups.saturatedLocked(n)
+}
+
+// LookupLimitStats returns counters for the lookups this node forwarded or
+// rejected, and its current load
+func (n *Node) LookupLimitStats() LookupLimitStats {
+	n.lookups.mu.Lock()
+	defer n.lookups.mu.Unlock()
+
+	stats := n.lookups.stats
+	stats.InFlight = n.lookups.inFlight
+	stats.Waiting = len(n.lookups.waiting)
+	return stats
+}
+
+// acquireLookup returns once the node may forward a lookup, with the
+// function releasing its slot, or an error carrying a retry delay if the
+// queue is full or the wait too long
+func (n *Node) acquireLookup(ctx context.Context) (func(), error) {
+	l := &n.lookups
+	l.mu.Lock()
+	if l.limit.MaxInFlight <= 0 || l.inFlight < l.limit.MaxInFlight {
+		l.inFlight++
+		l.stats.Admitted++
+		l.saturatedLocked(n)
+		l.mu.Unlock()
+		return func() { l.release(n) }, nil
+	}
+	if len(l.waiting) >= l.limit.MaxQueue {
+		l.stats.Rejected++
+		l.mu.Unlock()
+		n.instruments.Load().lookupsRejected.Add(1)
+		return nil, &retryableError{
+			err:   fmt.Errorf("%w: %d lookups in flight", ErrOverloaded, l.limit.MaxInFlight),
+			after: lookupRetryDelay,
+		}
+	}
+	ready := make(chan struct{})
+	l.waiting = append(l.waiting, ready)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(lookupQueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-ready:
+		l.mu.Lock()
+		l.stats.Admitted++
+		l.stats.Queued++
+		l.mu.Unlock()
+		return func() { l.release(n) }, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C:
+		err = &retryableError{
+			err:   fmt.Errorf("%w: lookup queued for %v", ErrOverloaded, lookupQueueTimeout),
+			after: lookupRetryDelay,
+		}
+	}
+
+	// Leave the queue, or pass on the slot if handed one meanwhile
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, w := range l.waiting {
+		if w == ready {
+			l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+			l.stats.Rejected++
+			n.instruments.Load().lookupsRejected.Add(1)
+			return nil, err
+		}
+	}
+	l.releaseLocked(n)
+	return nil, err
+}
+
+// release frees a lookup's slot
+func (l *lookupLimiter) release(n *Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.releaseLocked(n)
+}
+
+// releaseLocked hands a slot to the first waiting lookup, or frees it. The
+// caller must hold mu.
+func (l *lookupLimiter) releaseLocked(n *Node) {
+	// A lowered limit is reached by freeing slots rather than handing them
+	// on
+	if len(l.waiting) > 0 && (l.limit.MaxInFlight <= 0 || l.inFlight <= l.limit.MaxInFlight) {
+		close(l.waiting[0])
+		l.waiting = l.waiting[1:]
+		return
+	}
+	l.inFlight--
+	l.saturatedLocked(n)
+}
+
+// saturatedLocked records the fraction of the limit in use. The caller must
+// hold mu.
+func (l *lookupLimiter) saturatedLocked(n *Node) {
+	if l.limit.MaxInFlight > 0 {
+		n.instruments.Load().lookupSaturation.Set(float64(l.inFlight) / float64(l.limit.MaxInFlight))
+	}
+}
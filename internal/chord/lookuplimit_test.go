@@ -0,0 +1,75 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chord-dht/internal/metrics"
+)
+
+func TestLookupLimit(t *testing.T) {
+	node := NewNode("localhost:0", nil)
+	sink := metrics.NewMemory()
+	node.SetMetrics(sink)
+	node.SetLookupLimit(LookupLimit{MaxInFlight: 1, MaxQueue: 1})
+
+	release, err := node.acquireLookup(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the first lookup to be admitted: %v", err)
+	}
+	if sink.GaugeValue(metrics.LookupSaturation) != 1 {
+		t.Errorf("Expected the limit to be saturated, got %v", sink.GaugeValue(metrics.LookupSaturation))
+	}
+
+	// The second lookup waits for the slot
+	queued := make(chan error, 1)
+	go func() {
+		release, err := node.acquireLookup(context.Background())
+		if err == nil {
+			release()
+		}
+		queued <- err
+	}()
+	for node.LookupLimitStats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The third finds the queue full
+	if _, err := node.acquireLookup(context.Background()); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("Expected ErrOverloaded with a full queue, got %v", err)
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Errorf("Expected the queued lookup to get the slot: %v", err)
+	}
+	stats := node.LookupLimitStats()
+	if stats.Admitted != 2 || stats.Queued != 1 || stats.Rejected != 1 || stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if sink.CounterValue(metrics.LookupsRejected) != 1 || sink.GaugeValue(metrics.LookupSaturation) != 0 {
+		t.Errorf("Expected one rejection and no saturation in the metrics sink")
+	}
+
+	// A queued lookup whose caller gives up leaves the queue
+	release, _ = node.acquireLookup(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := node.acquireLookup(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the queued lookup to time out with its caller, got %v", err)
+	}
+	release()
+	if stats := node.LookupLimitStats(); stats.InFlight != 0 || stats.Waiting != 0 {
+		t.Errorf("Expected no lookup left in flight or queued: %+v", stats)
+	}
+
+	// Without a limit every lookup is admitted
+	node.SetLookupLimit(LookupLimit{})
+	for i := 0; i < 3; i++ {
+		if _, err := node.acquireLookup(context.Background()); err != nil {
+			t.Errorf("Expected no limit: %v", err)
+		}
+	}
+}
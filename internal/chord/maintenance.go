@@ -0,0 +1,243 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// Broadcast kinds pausing and resuming maintenance work on every node
+const (
+	pauseBroadcast  = "chord.maintenance.pause"
+	resumeBroadcast = "chord.maintenance.resume"
+)
+
+// MaintenanceStatus is a node's maintenance window state and the work it
+// deferred while paused
+type MaintenanceStatus struct {
+	Node   *NodeInfo
+	Paused bool
+	Reason string
+	Since  time.Time
+	// PendingHandoff is set while the node waits to pull its key range
+	PendingHandoff bool
+	// PendingReplication is the number of keys whose copies to replicas
+	// were deferred
+	PendingReplication int
+}
+
+// maintenance is the pause state of a node and its deferred replication
+type maintenance struct {
+	mu      sync.Mutex
+	paused  bool
+	reason  string
+	since   time.Time
+	backlog map[string]bool
+}
+
+// Pause stops data migrations (key range hand-offs) and copies to replicas
+// on this node until Resume. Lookups, reads and writes keep working; writes
+// are replicated once the node resumes.
+func (n *Node) Pause(reason string) {
+	n.maint.mu.Lock()
+	defer n.maint.mu.Unlock()
+
+	if n.maint.paused {
+		return
+	}
+	n.maint.paused = true
+	n.maint.reason = reason
+	n.maint.since = time.Now()
+	log.Printf("Node %s: paused for maintenance: %s", n.id.Short(), reason)
+}
+
+// Resume ends a pause and catches up on the deferred work. It returns the
+// status as it was before resuming, with the backlog that is now drained.
+func (n *Node) Resume() MaintenanceStatus {
+	status := n.MaintenanceStatus()
+
+	n.maint.mu.Lock()
+	if !n.maint.paused {
+		n.maint.mu.Unlock()
+		return status
+	}
+	backlog := make([]string, 0, len(n.maint.backlog))
+	for key := range n.maint.backlog {
+		backlog = append(backlog, key)
+	}
+	n.maint.paused = false
+	n.maint.reason = ""
+	n.maint.backlog = nil
+	n.maint.mu.Unlock()
+
+	log.Printf("Node %s: resumed after %v, replicating %d deferred keys",
+		n.id.Short(), time.Since(status.Since).Truncate(time.Second), len(backlog))
+
+	// Catch up in the background so a ring-wide resume is not held up
+	go func() {
+		if len(backlog) > 0 {
+			ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+			defer cancel()
+			n.replicateKeys(ctx, backlog)
+		}
+		n.maintainOwnership()
+	}()
+	return status
+}
+
+// MaintenanceStatus returns the node's pause state and deferred work
+func (n *Node) MaintenanceStatus() MaintenanceStatus {
+	n.own.mu.Lock()
+	pendingHandoff := n.own.start == nil
+	n.own.mu.Unlock()
+
+	n.maint.mu.Lock()
+	defer n.maint.mu.Unlock()
+
+	return MaintenanceStatus{
+		Node:               n.GetNodeInfo(),
+		Paused:             n.maint.paused,
+		Reason:             n.maint.reason,
+		Since:              n.maint.since,
+		PendingHandoff:     pendingHandoff,
+		PendingReplication: len(n.maint.backlog),
+	}
+}
+
+// PauseRing pauses maintenance work on every node of the ring
+func (n *Node) PauseRing(ctx context.Context, reason string) (*BroadcastResult, error) {
+	return n.Broadcast(ctx, pauseBroadcast, []byte(reason))
+}
+
+// ResumeRing resumes maintenance work on every node of the ring
+func (n *Node) ResumeRing(ctx context.Context) (*BroadcastResult, error) {
+	return n.Broadcast(ctx, resumeBroadcast, nil)
+}
+
+// paused reports whether maintenance work is paused
+func (n *Node) paused() bool {
+	n.maint.mu.Lock()
+	defer n.maint.mu.Unlock()
+
+	return n.maint.paused
+}
+
+// deferReplication queues keys for replication on resume, reporting false
+// if the node is not paused
+func (n *Node) deferReplication(keys []string) bool {
+	n.maint.mu.Lock()
+	defer n.maint.mu.Unlock()
+
+	if !n.maint.paused {
+		return false
+	}
+	if n.maint.backlog == nil {
+		n.maint.backlog = make(map[string]bool)
+	}
+	for _, key := range keys {
+		n.maint.backlog[key] = true
+	}
+	return true
+}
+
+// handleMaintenanceBroadcasts registers the handlers for ring-wide pauses
+func (n *Node) handleMaintenanceBroadcasts() {
+	n.HandleBroadcast(pauseBroadcast, func(msg *BroadcastMessage) {
+		n.Pause(string(msg.Payload))
+	})
+	n.HandleBroadcast(resumeBroadcast, func(msg *BroadcastMessage) {
+		n.Resume()
+	})
+}
+
+// toProtoMaintenance converts a maintenance status to its protobuf form
+func toProtoMaintenance(status MaintenanceStatus) *pb.MaintenanceStatus {
+	result := &pb.MaintenanceStatus{
+		Node:               toProtoNode(status.Node),
+		Paused:             status.Paused,
+		Reason:             status.Reason,
+		PendingHandoff:     status.PendingHandoff,
+		PendingReplication: int64(status.PendingReplication),
+	}
+	if status.Paused {
+		result.SinceMs = status.Since.UnixMilli()
+	}
+	return result
+}
+
+// MaintenanceFromProto converts a protobuf maintenance status
+func MaintenanceFromProto(status *pb.MaintenanceStatus) (MaintenanceStatus, error) {
+	node, err := fromProtoNode(status.GetNode())
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+	result := MaintenanceStatus{
+		Node:               node,
+		Paused:             status.Paused,
+		Reason:             status.Reason,
+		PendingHandoff:     status.PendingHandoff,
+		PendingReplication: int(status.PendingReplication),
+	}
+	if status.SinceMs != 0 {
+		result.Since = time.UnixMilli(status.SinceMs)
+	}
+	return result, nil
+}
+
+// RemoteMaintenance returns the maintenance status of the node at address
+func (n *Node) RemoteMaintenance(ctx context.Context, address string) (MaintenanceStatus, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return MaintenanceStatus{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetMaintenance(ctx, &pb.GetMaintenanceRequest{})
+	if err != nil {
+		return MaintenanceStatus{}, fromStatus(address, err)
+	}
+	return MaintenanceFromProto(resp.Status)
+}
+
+// SetMaintenance pauses or resumes this node, or the whole ring
+func (n *Node) SetMaintenance(ctx context.Context, req *pb.SetMaintenanceRequest) (*pb.SetMaintenanceResponse, error) {
+	n.countMessage()
+
+	status := n.MaintenanceStatus()
+	resp := &pb.SetMaintenanceResponse{Status: toProtoMaintenance(status), Reached: 1, Success: true}
+
+	switch {
+	case req.Ring:
+		var (
+			result *BroadcastResult
+			err    error
+		)
+		if req.Paused {
+			result, err = n.PauseRing(ctx, req.Reason)
+		} else {
+			result, err = n.ResumeRing(ctx)
+		}
+		if err != nil {
+			return nil, toStatus(fmt.Errorf("maintenance broadcast failed: %w", err))
+		}
+		resp.Reached = int32(result.Reached)
+	case req.Paused:
+		n.Pause(req.Reason)
+	default:
+		n.Resume()
+	}
+	return resp, nil
+}
+
+// GetMaintenance returns this node's maintenance status
+func (n *Node) GetMaintenance(ctx context.Context, req *pb.GetMaintenanceRequest) (*pb.GetMaintenanceResponse, error) {
+	n.countMessage()
+
+	return &pb.GetMaintenanceResponse{Status: toProtoMaintenance(n.MaintenanceStatus())}, nil
+}
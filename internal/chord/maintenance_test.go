@@ -0,0 +1,96 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+func TestMaintenancePause(t *testing.T) {
+	nodes := startTestRing(t, 8420, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+
+	ctx := context.Background()
+	result, err := nodes[0].PauseRing(ctx, "upgrade")
+	if err != nil {
+		t.Fatalf("PauseRing failed: %v", err)
+	}
+	if result.Reached != len(nodes) {
+		t.Errorf("Expected %d nodes paused, got %d", len(nodes), result.Reached)
+	}
+	for _, node := range nodes {
+		status, err := nodes[0].RemoteMaintenance(ctx, node.GetAddress())
+		if err != nil {
+			t.Fatalf("RemoteMaintenance failed: %v", err)
+		}
+		if !status.Paused || status.Reason != "upgrade" {
+			t.Errorf("Expected %s paused for upgrade, got %+v", node.GetAddress(), status)
+		}
+	}
+
+	// Lookups and writes keep working; copies to the replica wait
+	key := "paused-key"
+	owner, err := nodes[1].Lookup(hash.NewHashFromString(key))
+	if err != nil {
+		t.Fatalf("Lookup failed while paused: %v", err)
+	}
+	if err := nodes[1].StoreValue(ctx, key, []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed while paused: %v", err)
+	}
+	var ownerNode *Node
+	for _, node := range nodes {
+		if node.GetAddress() == owner.Address {
+			ownerNode = node
+		}
+	}
+	if pending := ownerNode.MaintenanceStatus().PendingReplication; pending != 1 {
+		t.Errorf("Expected one deferred key on the owner, got %d", pending)
+	}
+	replicas := ownerNode.replicaTargets()
+	if len(replicas) != 1 {
+		t.Fatalf("Expected one replica, got %v", addresses(replicas))
+	}
+	var replica *Node
+	for _, node := range nodes {
+		if node.GetAddress() == replicas[0].Address {
+			replica = node
+		}
+	}
+	if _, ok, _ := replica.Storage().Get(key); ok {
+		t.Error("Expected the replica not to hold the key while paused")
+	}
+
+	// Hand-offs are refused while paused
+	_, err = replica.PrepareHandoff(ctx, &pb.PrepareHandoffRequest{
+		Requester: toProtoNode(ownerNode.GetNodeInfo()),
+	})
+	if err := fromStatus(replica.GetAddress(), err); !errors.Is(err, ErrPaused) {
+		t.Errorf("Expected ErrPaused for a hand-off, got %v", err)
+	}
+
+	if _, err := nodes[2].ResumeRing(ctx); err != nil {
+		t.Fatalf("ResumeRing failed: %v", err)
+	}
+	for _, node := range nodes {
+		if status := node.MaintenanceStatus(); status.Paused || status.PendingReplication != 0 {
+			t.Errorf("Expected %s resumed with no backlog, got %+v", node.GetAddress(), status)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok, _ := replica.Storage().Get(key); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Deferred key was not replicated after resume")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,516 @@
+// Package memcache serves the memcached text protocol on top of the Chord
+// DHT, so that applications with a memcached client can use a ring as their
+// cache tier. get, set, add, delete and flush_all map to DHT operations.
+// Items are stored under keys namespaced by a flush generation, a counter
+// kept in the ring: flush_all moves every frontend to a new generation
+// instead of deleting keys one by one.
+package memcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+const (
+	// MaxKeyLength is the longest key accepted, as in memcached
+	MaxKeyLength = 250
+	// MaxItemSize is the largest value accepted, memcached's default
+	MaxItemSize = 1 << 20
+
+	// keyPrefix namespaces memcached items in the DHT
+	keyPrefix = "memcache/"
+	// generationKey holds the flush generation, a counter
+	generationKey = keyPrefix + "generation"
+	// generationRefresh is how long a frontend uses the generation it last
+	// read, and so how long a flush by another frontend takes to apply
+	generationRefresh = time.Second
+	// relativeExpiryLimit is the largest expiry time taken as seconds from
+	// now; larger ones are Unix times
+	relativeExpiryLimit = 30 * 24 * 60 * 60
+	// requestTimeout bounds the DHT operations of one command
+	requestTimeout = 5 * time.Second
+	// maxLineLength bounds a command line
+	maxLineLength = 64 << 10
+	// maxSwapAttempts bounds the conditional writes of one command while
+	// other writers keep changing the item
+	maxSwapAttempts = 8
+	// version is the answer to the version command
+	version = "chord-dht"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("memcache: server closed")
+
+// errContended is returned when an item kept changing under a write
+var errContended = errors.New("item modified concurrently")
+
+// Server answers memcached clients through a Chord node
+type Server struct {
+	node *chord.Node
+
+	mu         sync.Mutex
+	generation int64
+	refreshed  time.Time
+	listeners  map[net.Listener]bool
+	conns      map[net.Conn]bool
+	closed     bool
+}
+
+// New creates a server that issues requests through node
+func New(node *chord.Node) *Server {
+	return &Server{
+		node:      node,
+		listeners: make(map[net.Listener]bool),
+		conns:     make(map[net.Conn]bool),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and serves clients
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts clients on listener until Close, then returns
+// ErrServerClosed
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = true
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, listener)
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the listeners and closes every client connection
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// serveConn answers the commands of one client until it quits or breaks
+// the protocol
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReaderSize(conn, maxLineLength)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			fmt.Fprint(w, "CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(string(line))
+		keep := s.handle(fields, r, w)
+		if err := w.Flush(); err != nil || !keep {
+			return
+		}
+	}
+}
+
+// handle runs one command and reports whether to keep the connection
+func (s *Server) handle(fields []string, r *bufio.Reader, w *bufio.Writer) bool {
+	if len(fields) == 0 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	switch fields[0] {
+	case "get":
+		s.get(ctx, fields[1:], w)
+	case "set", "add":
+		return s.store(ctx, fields, r, w)
+	case "delete":
+		s.delete(ctx, fields[1:], w)
+	case "flush_all":
+		s.flushAll(ctx, fields[1:], w)
+	case "version":
+		fmt.Fprintf(w, "VERSION %s\r\n", version)
+	case "quit":
+		return false
+	default:
+		fmt.Fprint(w, "ERROR\r\n")
+	}
+	return true
+}
+
+// get answers "get <key>*" with the items found
+func (s *Server) get(ctx context.Context, keys []string, w *bufio.Writer) {
+	if len(keys) == 0 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+	for _, key := range keys {
+		if !validKey(key) {
+			fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+			return
+		}
+	}
+
+	generation, err := s.currentGeneration(ctx)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	dhtKeys := make([]string, len(keys))
+	for i, key := range keys {
+		dhtKeys[i] = itemKey(generation, key)
+	}
+	values, err := s.node.FetchBatch(ctx, dhtKeys)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	for i, key := range keys {
+		flags, data, ok := decodeItem(values[dhtKeys[i]])
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, flags, len(data))
+		w.Write(data)
+		w.WriteString("\r\n")
+	}
+	fmt.Fprint(w, "END\r\n")
+}
+
+// store answers "set|add <key> <flags> <exptime> <bytes> [noreply]" followed
+// by the data block, and reports whether the connection is still in sync
+func (s *Server) store(ctx context.Context, fields []string, r *bufio.Reader, w *bufio.Writer) bool {
+	if len(fields) != 5 && len(fields) != 6 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return true
+	}
+	key := fields[1]
+	flags, errFlags := strconv.ParseUint(fields[2], 10, 32)
+	exptime, errExptime := strconv.ParseInt(fields[3], 10, 64)
+	size, errSize := strconv.Atoi(fields[4])
+	if errSize != nil || size < 0 {
+		// Without the length of the data block the stream cannot be
+		// followed any further
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return false
+	}
+	if errFlags != nil || errExptime != nil {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return discard(r, size+2)
+	}
+	noreply := len(fields) == 6 && fields[5] == "noreply"
+
+	if size > MaxItemSize {
+		if !discard(r, size+2) {
+			return false
+		}
+		fmt.Fprint(w, "SERVER_ERROR object too large for cache\r\n")
+		return true
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return false
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		// Skip the rest of the line the block ran into
+		fmt.Fprint(w, "CLIENT_ERROR bad data chunk\r\n")
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			_, err := r.ReadSlice('\n')
+			return err == nil
+		}
+		return true
+	}
+	if !validKey(key) {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return true
+	}
+
+	generation, err := s.currentGeneration(ctx)
+	if err != nil {
+		serverError(w, err)
+		return true
+	}
+	write := chord.ConditionalWrite{Key: itemKey(generation, key), Value: encodeItem(uint32(flags), data[:size])}
+	ttl, expired := expiry(exptime, time.Now())
+	write.TTL = ttl
+	write.Delete = expired
+
+	stored := true
+	switch {
+	case fields[0] == "add":
+		// An add that expires at once stores nothing, but still fails if
+		// the item exists
+		var result *chord.ConditionalResult
+		result, err = s.node.CompareAndSwap(ctx, write)
+		stored = err == nil && result.Applied
+	case !expired && ttl == 0:
+		err = s.node.StoreValue(ctx, write.Key, write.Value)
+	default:
+		_, err = s.swap(ctx, write)
+	}
+
+	switch {
+	case err != nil:
+		serverError(w, err)
+	case noreply:
+	case stored:
+		fmt.Fprint(w, "STORED\r\n")
+	default:
+		fmt.Fprint(w, "NOT_STORED\r\n")
+	}
+	return true
+}
+
+// delete answers "delete <key> [noreply]"
+func (s *Server) delete(ctx context.Context, args []string, w *bufio.Writer) {
+	if len(args) != 1 && len(args) != 2 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+	if !validKey(args[0]) {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return
+	}
+	noreply := len(args) == 2 && args[1] == "noreply"
+
+	generation, err := s.currentGeneration(ctx)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	existed, err := s.swap(ctx, chord.ConditionalWrite{Key: itemKey(generation, args[0]), Delete: true})
+	switch {
+	case err != nil:
+		serverError(w, err)
+	case noreply:
+	case existed:
+		fmt.Fprint(w, "DELETED\r\n")
+	default:
+		fmt.Fprint(w, "NOT_FOUND\r\n")
+	}
+}
+
+// flushAll answers "flush_all [delay] [noreply]"
+func (s *Server) flushAll(ctx context.Context, args []string, w *bufio.Writer) {
+	noreply := len(args) > 0 && args[len(args)-1] == "noreply"
+	if noreply {
+		args = args[:len(args)-1]
+	}
+	var delay int64
+	if len(args) > 1 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+	if len(args) == 1 {
+		var err error
+		if delay, err = strconv.ParseInt(args[0], 10, 64); err != nil || delay < 0 {
+			fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+			return
+		}
+	}
+
+	if delay > 0 {
+		time.AfterFunc(time.Duration(delay)*time.Second, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+			if err := s.flush(ctx); err != nil {
+				log.Printf("memcache: delayed flush failed: %v", err)
+			}
+		})
+	} else if err := s.flush(ctx); err != nil {
+		serverError(w, err)
+		return
+	}
+	if !noreply {
+		fmt.Fprint(w, "OK\r\n")
+	}
+}
+
+// flush starts a new generation, hiding every item stored so far. The
+// items of old generations stay in the ring until they expire.
+func (s *Server) flush(ctx context.Context) error {
+	generation, err := s.node.IncrCounter(ctx, generationKey, 1)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.generation = max(s.generation, generation)
+	s.refreshed = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// currentGeneration returns the flush generation, read again from the ring
+// once the last read is older than generationRefresh
+func (s *Server) currentGeneration(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	if time.Since(s.refreshed) < generationRefresh {
+		generation := s.generation
+		s.mu.Unlock()
+		return generation, nil
+	}
+	s.mu.Unlock()
+
+	generation, err := s.node.ReadCounter(ctx, generationKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read flush generation: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation = max(s.generation, generation)
+	s.refreshed = time.Now()
+	return s.generation, nil
+}
+
+// swap applies a write or delete whatever the item's current value, and
+// reports whether the item existed. The DHT has no unconditional delete or
+// expiring write, so the conditional write is retried with the value found
+// until it applies.
+func (s *Server) swap(ctx context.Context, write chord.ConditionalWrite) (bool, error) {
+	current, err := s.node.FetchValue(ctx, write.Key)
+	if err != nil && !errors.Is(err, chord.ErrKeyNotFound) {
+		return false, err
+	}
+	write.Expected = current
+	for attempt := 0; attempt < maxSwapAttempts; attempt++ {
+		result, err := s.node.CompareAndSwap(ctx, write)
+		if err != nil {
+			return false, err
+		}
+		if result.Applied {
+			return write.Expected != nil, nil
+		}
+		write.Expected = result.Current
+	}
+	return false, errContended
+}
+
+// expiry converts a memcached expiry time, zero for none, seconds from now
+// up to 30 days or a Unix time otherwise, into a TTL. It reports whether the
+// item expires at once.
+func expiry(exptime int64, now time.Time) (time.Duration, bool) {
+	switch {
+	case exptime < 0:
+		return 0, true
+	case exptime == 0:
+		return 0, false
+	case exptime <= relativeExpiryLimit:
+		return time.Duration(exptime) * time.Second, false
+	}
+	at := time.Unix(exptime, 0)
+	if !at.After(now) {
+		return 0, true
+	}
+	return at.Sub(now), false
+}
+
+// itemKey returns the DHT key of an item in a flush generation
+func itemKey(generation int64, key string) string {
+	return keyPrefix + strconv.FormatInt(generation, 10) + "/" + key
+}
+
+// encodeItem prefixes an item's data with its client flags
+func encodeItem(flags uint32, data []byte) []byte {
+	value := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(value, flags)
+	copy(value[4:], data)
+	return value
+}
+
+// decodeItem splits a value written by encodeItem
+func decodeItem(value []byte) (uint32, []byte, bool) {
+	if len(value) < 4 {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint32(value), value[4:], true
+}
+
+// validKey reports whether key is a valid memcached key: at most
+// MaxKeyLength bytes and no control characters
+func validKey(key string) bool {
+	if key == "" || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// discard skips n bytes of input and reports whether they were read
+func discard(r *bufio.Reader, n int) bool {
+	_, err := r.Discard(n)
+	return err == nil
+}
+
+// serverError reports a failed DHT operation
+func serverError(w *bufio.Writer, err error) {
+	msg := strings.NewReplacer("\r", " ", "\n", " ").Replace(err.Error())
+	fmt.Fprintf(w, "SERVER_ERROR %s\r\n", msg)
+}
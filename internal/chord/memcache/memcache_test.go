@@ -0,0 +1,171 @@
+package memcache
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+// startServer starts a single node ring with a server in front of it and
+// returns a connected client
+func startServer(t *testing.T, address string) (*Server, *client) {
+	node := chord.NewNode(address, nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	server := New(node)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return server, &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// do sends a command and returns the reply up to and including its last
+// line, which starts with last
+func (c *client) do(command, last string) string {
+	c.t.Helper()
+	c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := fmt.Fprint(c.conn, command); err != nil {
+		c.t.Fatalf("Write failed: %v", err)
+	}
+	var reply strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("Read after %q failed: %v", command, err)
+		}
+		reply.WriteString(line)
+		if strings.HasPrefix(line, last) || strings.HasPrefix(line, "SERVER_ERROR") || strings.HasPrefix(line, "CLIENT_ERROR") || line == "ERROR\r\n" {
+			return reply.String()
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	_, c := startServer(t, "localhost:8581")
+
+	if got := c.do("set a 5 0 3\r\nabc\r\n", ""); got != "STORED\r\n" {
+		t.Fatalf("Expected STORED, got %q", got)
+	}
+	if got := c.do("get a missing\r\n", "END"); got != "VALUE a 5 3\r\nabc\r\nEND\r\n" {
+		t.Errorf("Unexpected get reply %q", got)
+	}
+	if got := c.do("add a 0 0 1\r\nx\r\n", ""); got != "NOT_STORED\r\n" {
+		t.Errorf("Expected NOT_STORED adding an existing item, got %q", got)
+	}
+	if got := c.do("add b 0 0 1\r\nx\r\n", ""); got != "STORED\r\n" {
+		t.Errorf("Expected STORED, got %q", got)
+	}
+	if got := c.do("set a 1 0 0\r\n\r\n", ""); got != "STORED\r\n" {
+		t.Errorf("Expected STORED, got %q", got)
+	}
+	if got := c.do("get a b\r\n", "END"); got != "VALUE a 1 0\r\n\r\nVALUE b 0 1\r\nx\r\nEND\r\n" {
+		t.Errorf("Unexpected get reply %q", got)
+	}
+
+	if got := c.do("delete a\r\n", ""); got != "DELETED\r\n" {
+		t.Errorf("Expected DELETED, got %q", got)
+	}
+	if got := c.do("delete a\r\n", ""); got != "NOT_FOUND\r\n" {
+		t.Errorf("Expected NOT_FOUND, got %q", got)
+	}
+	if got := c.do("get a\r\n", "END"); got != "END\r\n" {
+		t.Errorf("Expected a deleted item to be missing, got %q", got)
+	}
+
+	// Expiring and expired items
+	if got := c.do("set short 0 1 1\r\ns\r\n", ""); got != "STORED\r\n" {
+		t.Errorf("Expected STORED, got %q", got)
+	}
+	if got := c.do("set b 0 -1 1\r\ny\r\n", ""); got != "STORED\r\n" {
+		t.Errorf("Expected STORED, got %q", got)
+	}
+	if got := c.do("get short b\r\n", "END"); got != "VALUE short 0 1\r\ns\r\nEND\r\n" {
+		t.Errorf("Unexpected get reply %q", got)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if got := c.do("get short\r\n", "END"); got != "END\r\n" {
+		t.Errorf("Expected short to expire, got %q", got)
+	}
+
+	if got := c.do("set noreply 0 0 1 noreply\r\nz\r\nget noreply\r\n", "END"); got != "VALUE noreply 0 1\r\nz\r\nEND\r\n" {
+		t.Errorf("Unexpected reply after noreply %q", got)
+	}
+	if got := c.do("set bad 0 0 1\r\nzz\r\n", ""); got != "CLIENT_ERROR bad data chunk\r\n" {
+		t.Errorf("Expected a bad data chunk, got %q", got)
+	}
+	if got := c.do("bogus\r\n", ""); got != "ERROR\r\n" {
+		t.Errorf("Expected ERROR, got %q", got)
+	}
+	if got := c.do("version\r\n", "VERSION"); !strings.HasPrefix(got, "VERSION ") {
+		t.Errorf("Expected a version, got %q", got)
+	}
+}
+
+func TestFlushAll(t *testing.T) {
+	server, c := startServer(t, "localhost:8582")
+
+	c.do("set a 0 0 1\r\n1\r\n", "")
+	if got := c.do("flush_all\r\n", ""); got != "OK\r\n" {
+		t.Fatalf("Expected OK, got %q", got)
+	}
+	if got := c.do("get a\r\n", "END"); got != "END\r\n" {
+		t.Errorf("Expected a flushed item to be missing, got %q", got)
+	}
+	c.do("set a 0 0 1\r\n2\r\n", "")
+	if got := c.do("get a\r\n", "END"); got != "VALUE a 0 1\r\n2\r\nEND\r\n" {
+		t.Errorf("Unexpected get reply after flushing %q", got)
+	}
+
+	// Another frontend sees the flush once it reads the generation again
+	other := New(server.node)
+	if generation, err := other.currentGeneration(t.Context()); err != nil || generation != 1 {
+		t.Errorf("Expected generation 1, got %d, %v", generation, err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(2_000_000_000, 0)
+	for _, tc := range []struct {
+		exptime int64
+		ttl     time.Duration
+		expired bool
+	}{
+		{0, 0, false},
+		{-1, 0, true},
+		{60, time.Minute, false},
+		{relativeExpiryLimit, relativeExpiryLimit * time.Second, false},
+		{now.Unix() + 10, 10 * time.Second, false},
+		{now.Unix() - 10, 0, true},
+	} {
+		ttl, expired := expiry(tc.exptime, now)
+		if ttl != tc.ttl || expired != tc.expired {
+			t.Errorf("expiry(%d) = %v, %v, want %v, %v", tc.exptime, ttl, expired, tc.ttl, tc.expired)
+		}
+	}
+}
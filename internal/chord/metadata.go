@@ -0,0 +1,60 @@
+package chord
+
+import (
+	"sync"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// ProtocolVersion is the newest version of the Chord protocol this node
+// speaks, the newest the API package describes. It is advertised to peers
+// in NodeInfo and negotiated on every RPC between nodes (see version.go).
+const ProtocolVersion = uint32(pb.ProtocolVersion_PROTOCOL_VERSION_CURRENT)
+
+// NodeMetadata is what a node advertises about itself besides its ID and
+// address
+type NodeMetadata struct {
+	// Zone labels the datacenter or availability zone the node runs in.
+	// Replica selection and routing can prefer nodes in their own zone.
+	Zone string
+	// Weight is the node's capacity relative to other nodes; 0 means the
+	// default of 1
+	Weight uint32
+}
+
+// nodeMetadata holds the NodeMetadata this node advertises
+type nodeMetadata struct {
+	mu   sync.RWMutex
+	meta NodeMetadata
+}
+
+// SetNodeMetadata sets the zone and weight this node advertises. Peers pick
+// up changes as they stabilize; set it before Join so the ring learns it
+// from the start.
+func (n *Node) SetNodeMetadata(meta NodeMetadata) {
+	n.metadata.mu.Lock()
+	defer n.metadata.mu.Unlock()
+
+	n.metadata.meta = meta
+}
+
+// Metadata returns the NodeMetadata this node advertises
+func (n *Node) Metadata() NodeMetadata {
+	n.metadata.mu.RLock()
+	defer n.metadata.mu.RUnlock()
+
+	return n.metadata.meta
+}
+
+// EffectiveWeight returns the node's capacity weight, 1 if unset
+func (info *NodeInfo) EffectiveWeight() uint32 {
+	if info.Weight == 0 {
+		return 1
+	}
+	return info.Weight
+}
+
+// sameZone reports whether info is known to run in zone
+func (info *NodeInfo) sameZone(zone string) bool {
+	return zone != "" && info.Zone == zone
+}
@@ -0,0 +1,36 @@
+package chord
+
+import (
+	"testing"
+)
+
+func TestNodeMetadataPropagation(t *testing.T) {
+	nodes := startTestRing(t, 8467, 2)
+	nodes[0].SetNodeMetadata(NodeMetadata{Zone: "zone-a", Weight: 3})
+	nodes[1].SetNodeMetadata(NodeMetadata{Zone: "zone-b"})
+
+	// Neighbors pick up what a node advertises as they stabilize
+	for round := 0; round < 2; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+
+	successor := nodes[0].GetSuccessor()
+	if successor.Zone != "zone-b" || successor.EffectiveWeight() != 1 || successor.Version != ProtocolVersion {
+		t.Errorf("Expected the successor to advertise zone-b, weight 1 and version %d, got %+v",
+			ProtocolVersion, successor)
+	}
+	predecessor := nodes[1].GetPredecessor()
+	if predecessor == nil || predecessor.Zone != "zone-a" || predecessor.Weight != 3 {
+		t.Errorf("Expected the predecessor to advertise zone-a and weight 3, got %+v", predecessor)
+	}
+
+	peers, err := nodes[1].RemotePeers(nodes[1].ctx, nodes[0].GetAddress(), 1)
+	if err != nil {
+		t.Fatalf("RemotePeers failed: %v", err)
+	}
+	if peers.Self.Zone != "zone-a" || peers.Self.Version != ProtocolVersion {
+		t.Errorf("Expected the peer to describe itself, got %+v", peers.Self)
+	}
+}
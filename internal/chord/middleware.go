@@ -0,0 +1,116 @@
+package chord
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Middleware is a cross-cutting concern wrapped around the node's RPCs, such
+// as logging, authentication or metrics. Each interceptor is optional; the
+// server interceptors wrap incoming calls and the client interceptors wrap
+// calls the node makes to its peers.
+type Middleware struct {
+	UnaryServer  grpc.UnaryServerInterceptor
+	StreamServer grpc.StreamServerInterceptor
+	UnaryClient  grpc.UnaryClientInterceptor
+	StreamClient grpc.StreamClientInterceptor
+}
+
+// Use appends middleware to the node's chains. The first middleware added
+// is the outermost one. It must be called before Start and before the node
+// dials any peer.
+func (n *Node) Use(middleware ...Middleware) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.middleware = append(n.middleware, middleware...)
+}
+
+// serverOptions builds the interceptor chains for the gRPC server. The
+// caller must hold mu.
+func (n *Node) serverOptions() []grpc.ServerOption {
+	unary, stream := n.serverInterceptors()
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.StatsHandler(bandwidthCounter{node: n}),
+	}
+}
+
+// serverInterceptors returns the interceptors wrapping incoming calls,
+// outermost first. The caller must hold mu.
+func (n *Node) serverInterceptors() ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
+	// Rate limits run first, so rejected RPCs cost no other work, then
+	// relaying, since relayed nodes check calls themselves, then the count
+	// of calls served, the protocol version and ring checks and the
+	// request descriptor, so every middleware sees it
+	versions, ring := n.versionMiddleware(), n.ringMiddleware()
+	unary := []grpc.UnaryServerInterceptor{n.limiter.unaryServer, n.relayUnaryServer, n.statsUnaryServer, versions.UnaryServer, ring.UnaryServer, requestMiddleware.UnaryServer}
+	stream := []grpc.StreamServerInterceptor{n.limiter.streamServer, n.relayStreamServer, n.statsStreamServer, versions.StreamServer, ring.StreamServer, requestMiddleware.StreamServer}
+	for _, mw := range n.middleware {
+		if mw.UnaryServer != nil {
+			unary = append(unary, mw.UnaryServer)
+		}
+		if mw.StreamServer != nil {
+			stream = append(stream, mw.StreamServer)
+		}
+	}
+	return unary, stream
+}
+
+// dialOptions builds the interceptor chains for outgoing connections
+func (n *Node) dialOptions() []grpc.DialOption {
+	unary, stream := n.clientInterceptors()
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	}
+}
+
+// clientInterceptors returns the interceptors wrapping outgoing calls,
+// outermost first
+func (n *Node) clientInterceptors() ([]grpc.UnaryClientInterceptor, []grpc.StreamClientInterceptor) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	versions, ring := n.versionMiddleware(), n.ringMiddleware()
+	unary := []grpc.UnaryClientInterceptor{versions.UnaryClient, ring.UnaryClient, requestMiddleware.UnaryClient}
+	stream := []grpc.StreamClientInterceptor{versions.StreamClient, ring.StreamClient, requestMiddleware.StreamClient}
+	for _, mw := range n.middleware {
+		if mw.UnaryClient != nil {
+			unary = append(unary, mw.UnaryClient)
+		}
+		if mw.StreamClient != nil {
+			stream = append(stream, mw.StreamClient)
+		}
+	}
+	compression := n.compressionMiddleware()
+	unary = append(unary, compression.UnaryClient)
+	stream = append(stream, compression.StreamClient)
+	return unary, stream
+}
+
+// chainUnaryServer wraps handler in interceptors, the first outermost, for
+// calls that do not arrive through the gRPC server
+func chainUnaryServer(interceptors []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+// chainUnaryClient wraps invoker in interceptors, the first outermost, for
+// calls that do not go through a gRPC connection
+func chainUnaryClient(interceptors []grpc.UnaryClientInterceptor, invoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	return invoker
+}
@@ -0,0 +1,327 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AccessTokenMetadataKey carries a client's access token on every RPC
+	AccessTokenMetadataKey = "chord-access-token"
+	// AnyBucket is the bucket of a scope that covers every bucket, and the
+	// keys outside any bucket
+	AnyBucket = "*"
+	// chordService is the prefix of the methods Access checks
+	chordService = "/chord.v1.ChordService/"
+	// minAccessSecret is the shortest secret LoadAccessSecret accepts
+	minAccessSecret = 16
+)
+
+// accessOps maps the client-facing methods to whether they write. Other
+// methods of the Chord service need full access, except openMethods.
+var accessOps = map[string]bool{
+	"Get":            false,
+	"GetBatch":       false,
+	"QueryTag":       false,
+	"ListBucket":     false,
+	"GetBucketStats": false,
+	"ListKeys":       false,
+	"Put":            true,
+	"PutBatch":       true,
+	"ConditionalPut": true,
+	"Undelete":       true,
+	"UpdateCRDT":     true,
+}
+
+// openMethods are served without a token: the lookups and neighborhood
+// views clients route their calls with
+var openMethods = map[string]bool{
+	"FindSuccessor":          true,
+	"ClosestPrecedingFinger": true,
+	"GetInfo":                true,
+	"Ping":                   true,
+	"GetPeers":               true,
+	"GetSuccessorList":       true,
+	"GetRoutingTable":        true,
+}
+
+// AccessClaims are what an access token grants
+type AccessClaims struct {
+	// Subject names the holder, such as a user or service
+	Subject string
+	// Scopes are BUCKET:read or BUCKET:write, where BUCKET is a bucket
+	// name or AnyBucket. Writing does not imply reading.
+	Scopes []string
+	// ExpiresAt is when the token stops being accepted, never if zero
+	ExpiresAt time.Time
+}
+
+// Allows reports whether the claims permit reading, or writing, bucket
+func (c *AccessClaims) Allows(bucket string, write bool) bool {
+	action := "read"
+	if write {
+		action = "write"
+	}
+	for _, scope := range c.Scopes {
+		if scope == bucket+":"+action || scope == AnyBucket+":"+action {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtHeader is the header of every token, HMAC-SHA256 signed
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// jwtClaims is the payload of a token
+type jwtClaims struct {
+	Subject   string `json:"sub,omitempty"`
+	Scope     string `json:"scope"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// IssueToken signs claims with secret into a JWT that Access accepts. The
+// scopes are space separated in its scope claim.
+func IssueToken(secret []byte, claims AccessClaims) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("empty access secret")
+	}
+	for _, scope := range claims.Scopes {
+		bucket, action, _ := strings.Cut(scope, ":")
+		if bucket == "" || action != "read" && action != "write" {
+			return "", fmt.Errorf("invalid scope %q, expected BUCKET:read or BUCKET:write", scope)
+		}
+		if bucket != AnyBucket {
+			if err := chord.ValidBucket(bucket); err != nil {
+				return "", fmt.Errorf("invalid scope %q: %w", scope, err)
+			}
+		}
+	}
+
+	payload := jwtClaims{Subject: claims.Subject, Scope: strings.Join(claims.Scopes, " ")}
+	if !claims.ExpiresAt.IsZero() {
+		payload.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(data)
+	return unsigned + "." + sign(secret, unsigned), nil
+}
+
+// VerifyToken checks the signature and expiry of a token issued with
+// secret and returns its claims
+func VerifyToken(secret []byte, token string, now time.Time) (*AccessClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, errors.New("malformed token")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(secret, parts[0]+"."+parts[1]))) {
+		return nil, errors.New("invalid token signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	var payload jwtClaims
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+
+	claims := &AccessClaims{Subject: payload.Subject, Scopes: strings.Fields(payload.Scope)}
+	if payload.ExpiresAt != 0 {
+		claims.ExpiresAt = time.Unix(payload.ExpiresAt, 0)
+		if !now.Before(claims.ExpiresAt) {
+			return nil, fmt.Errorf("token expired at %v", claims.ExpiresAt)
+		}
+	}
+	return claims, nil
+}
+
+// sign returns the encoded HMAC-SHA256 of data
+func sign(secret []byte, data string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// LoadAccessSecret reads the secret tokens are signed with from a file,
+// ignoring surrounding whitespace
+func LoadAccessSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < minAccessSecret {
+		return nil, fmt.Errorf("%s: secret shorter than %d bytes", path, minAccessSecret)
+	}
+	return secret, nil
+}
+
+// WithAccessToken returns a context whose outgoing RPCs carry token
+func WithAccessToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AccessTokenMetadataKey, token)
+}
+
+// accessClaimsKey is the context key of the verified claims of a call
+type accessClaimsKey struct{}
+
+// AccessClaimsFrom returns the verified claims of the token an incoming
+// RPC carried, for services added with RegisterService to check themselves
+func AccessClaimsFrom(ctx context.Context) (*AccessClaims, bool) {
+	claims, ok := ctx.Value(accessClaimsKey{}).(*AccessClaims)
+	return claims, ok
+}
+
+// Access requires the key-value RPCs of the Chord service to carry a token
+// issued with secret whose scopes cover the bucket of every key they touch
+// (see chord.SplitBucketKey), so a ring shared by several applications is
+// not open to anyone who can reach it. Keys outside any bucket need
+// AnyBucket scopes. Lookups and GetPeers stay open for clients to route
+// with; every other RPC of the service needs AnyBucket read and write
+// scopes. The node calls its peers with such a token, so every node of the
+// ring must use the same secret, unless the context of a call through the
+// node's API carries another (see WithAccessToken). Other services are not
+// checked, but see the claims of a valid token through AccessClaimsFrom.
+func Access(secret []byte) (chord.Middleware, error) {
+	nodeToken, err := IssueToken(secret, AccessClaims{Subject: "chord-node", Scopes: []string{AnyBucket + ":read", AnyBucket + ":write"}})
+	if err != nil {
+		return chord.Middleware{}, err
+	}
+
+	check := func(ctx context.Context, method string, req any) (context.Context, error) {
+		var claims *AccessClaims
+		md, _ := metadata.FromIncomingContext(ctx)
+		if tokens := md.Get(AccessTokenMetadataKey); len(tokens) > 0 {
+			var err error
+			if claims, err = VerifyToken(secret, tokens[0], time.Now()); err != nil {
+				return ctx, status.Error(codes.Unauthenticated, err.Error())
+			}
+			ctx = context.WithValue(ctx, accessClaimsKey{}, claims)
+		}
+
+		name := path.Base(method)
+		if !strings.HasPrefix(method, chordService) || openMethods[name] {
+			return ctx, nil
+		}
+		if claims == nil {
+			return ctx, status.Error(codes.Unauthenticated, "missing access token")
+		}
+		write, ok := accessOps[name]
+		if !ok {
+			if !claims.Allows(AnyBucket, false) || !claims.Allows(AnyBucket, true) {
+				return ctx, status.Errorf(codes.PermissionDenied, "%s needs full access", name)
+			}
+			return ctx, nil
+		}
+		for bucket := range requestBuckets(req) {
+			if !claims.Allows(bucket, write) {
+				action := "read"
+				if write {
+					action = "write"
+				}
+				return ctx, status.Errorf(codes.PermissionDenied, "token of %q may not %s bucket %q", claims.Subject, action, bucket)
+			}
+		}
+		return ctx, nil
+	}
+	attach := func(ctx context.Context) context.Context {
+		if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get(AccessTokenMetadataKey)) > 0 {
+			return ctx
+		}
+		return WithAccessToken(ctx, nodeToken)
+	}
+
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := check(ctx, info.FullMethod, req)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := check(ss.Context(), info.FullMethod, nil)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &claimsStream{ServerStream: ss, ctx: ctx})
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(attach(ctx), method, req, reply, cc, opts...)
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(attach(ctx), desc, cc, method, opts...)
+		},
+	}, nil
+}
+
+// claimsStream is a server stream whose context carries verified claims
+type claimsStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *claimsStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestBuckets returns the buckets of the keys a request touches, with
+// AnyBucket for keys outside any bucket and for requests that do not name
+// their keys
+func requestBuckets(req any) map[string]bool {
+	buckets := make(map[string]bool)
+	add := func(key string) {
+		if bucket, _, ok := chord.SplitBucketKey(key); ok {
+			buckets[bucket] = true
+		} else {
+			buckets[AnyBucket] = true
+		}
+	}
+	switch r := req.(type) {
+	case interface{ GetKey() string }:
+		add(r.GetKey())
+	case *pb.GetBatchRequest:
+		for _, key := range r.Keys {
+			add(key)
+		}
+	case *pb.PutBatchRequest:
+		for _, item := range r.Items {
+			add(item.Key)
+		}
+	case *pb.ListBucketRequest:
+		buckets[r.Bucket] = true
+	case *pb.ListKeysRequest:
+		// A prefix within one bucket needs access to that bucket only
+		add(r.Prefix)
+	case *pb.GetBucketStatsRequest:
+		if r.Bucket == "" {
+			buckets[AnyBucket] = true
+		} else {
+			buckets[r.Bucket] = true
+		}
+	default:
+		buckets[AnyBucket] = true
+	}
+	return buckets
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authMetadataKey carries the shared token on every RPC
+const authMetadataKey = "authorization"
+
+// Auth requires every incoming RPC to carry the shared token and attaches it
+// to every outgoing one, so only nodes configured with the same token can
+// talk to each other
+func Auth(token string) chord.Middleware {
+	expected := []byte("Bearer " + token)
+
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get(authMetadataKey) {
+			if subtle.ConstantTimeCompare([]byte(value), expected) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	attach := func(ctx context.Context) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, authMetadataKey, string(expected))
+	}
+
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(attach(ctx), method, req, reply, cc, opts...)
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(attach(ctx), desc, cc, method, opts...)
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Faults describes the faults injected into the RPCs a node serves
+type Faults struct {
+	// ErrorRate is the fraction of calls, between 0 and 1, that fail with
+	// codes.Unavailable before reaching the handler
+	ErrorRate float64
+	// Delay is added to every call before it is handled
+	Delay time.Duration
+	// Methods restricts the faults to methods whose full name ends with one
+	// of these suffixes, e.g. "/Notify". Empty means all methods.
+	Methods []string
+}
+
+// FaultInjection makes the node's server delay and fail calls as described
+// by faults, to exercise the ring's failure handling
+func FaultInjection(faults Faults) chord.Middleware {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	inject := func(ctx context.Context, method string) error {
+		if !faults.applies(method) {
+			return nil
+		}
+		if faults.Delay > 0 {
+			select {
+			case <-time.After(faults.Delay):
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			}
+		}
+		mu.Lock()
+		fail := rng.Float64() < faults.ErrorRate
+		mu.Unlock()
+		if fail {
+			return status.Errorf(codes.Unavailable, "injected fault in %s", method)
+		}
+		return nil
+	}
+
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := inject(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := inject(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	}
+}
+
+// applies reports whether faults are injected into method
+func (f Faults) applies(method string) bool {
+	if len(f.Methods) == 0 {
+		return true
+	}
+	for _, suffix := range f.Methods {
+		if strings.HasSuffix(method, suffix) {
+			return true
+		}
+	}
+	return false
+}
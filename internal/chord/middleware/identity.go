@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Metadata keys carrying the signer's public key, the signature and the time
+// it was made
+const (
+	publicKeyMetadataKey = "chord-public-key-bin"
+	signatureMetadataKey = "chord-signature-bin"
+	signedAtMetadataKey  = "chord-signed-at"
+)
+
+// MaxClockSkew bounds how old or how far in the future a signature may be
+const MaxClockSkew = time.Minute
+
+// pemKeyType is the PEM block type of node key files
+const pemKeyType = "PRIVATE KEY"
+
+// KeyID returns the ring position of the node owning the public key, the
+// SHA-1 of the key
+func KeyID(public ed25519.PublicKey) *hash.Hash {
+	return hash.NewHashFromString(string(public))
+}
+
+// GenerateKey creates a new node key and writes it to path. It refuses to
+// overwrite an existing file.
+func GenerateKey(path string) (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := pem.Encode(file, &pem.Block{Type: pemKeyType, Bytes: der}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return key, file.Close()
+}
+
+// LoadKey reads a node key written by GenerateKey
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemKeyType {
+		return nil, fmt.Errorf("%s: no %s PEM block", path, pemKeyType)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// Identity signs every outgoing RPC with key and verifies the signature of
+// incoming ones. RPCs through which a node claims a ring position (Notify
+// and the ownership hand-off) must be signed by the key the claimed ID is
+// derived from, so a peer cannot take over arbitrary ring positions. Other
+// RPCs may be unsigned, which keeps plain clients such as chordctl working.
+// The node itself must use KeyID of the key's public half as its ID.
+func Identity(key ed25519.PrivateKey) chord.Middleware {
+	public := key.Public().(ed25519.PublicKey)
+
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := verifyIdentity(ctx, info.FullMethod, req); err != nil {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return handler(ctx, req)
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			msg, ok := req.(proto.Message)
+			if !ok {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+			signedAt := strconv.FormatInt(time.Now().UnixNano(), 10)
+			payload, err := signedPayload(method, signedAt, msg)
+			if err != nil {
+				return err
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				publicKeyMetadataKey, string(public),
+				signatureMetadataKey, string(ed25519.Sign(key, payload)),
+				signedAtMetadataKey, signedAt)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		},
+	}
+}
+
+// verifyIdentity checks the signature of an incoming RPC, if any, and that a
+// claimed ring position belongs to the signer
+func verifyIdentity(ctx context.Context, method string, req any) error {
+	claimed := claimedNode(req)
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(publicKeyMetadataKey)
+	if len(keys) == 0 {
+		if claimed != nil {
+			return errors.New("unsigned request claims a ring position")
+		}
+		return nil
+	}
+
+	public := ed25519.PublicKey(keys[0])
+	signatures := md.Get(signatureMetadataKey)
+	stamps := md.Get(signedAtMetadataKey)
+	if len(public) != ed25519.PublicKeySize || len(signatures) == 0 || len(stamps) == 0 {
+		return errors.New("malformed signature")
+	}
+	nanos, err := strconv.ParseInt(stamps[0], 10, 64)
+	if err != nil {
+		return errors.New("malformed signature time")
+	}
+	if skew := time.Since(time.Unix(0, nanos)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return fmt.Errorf("signature time off by %v", skew.Truncate(time.Second))
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return errors.New("cannot verify a non-protobuf request")
+	}
+	payload, err := signedPayload(method, stamps[0], msg)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(public, payload, []byte(signatures[0])) {
+		return errors.New("invalid signature")
+	}
+
+	if claimed != nil {
+		id, err := hash.NewHashFromHex(claimed.Id)
+		if err != nil || !id.Equal(KeyID(public)) {
+			return fmt.Errorf("node %s is not owned by the signing key", claimed.Id)
+		}
+	}
+	return nil
+}
+
+// claimedNode returns the node a request claims to come from, for the RPCs
+// that change ring state on its behalf
+func claimedNode(req any) *pb.Node {
+	switch r := req.(type) {
+	case *pb.NotifyRequest:
+		return r.GetNode()
+	case *pb.PrepareHandoffRequest:
+		return r.GetRequester()
+	case *pb.CommitHandoffRequest:
+		return r.GetRequester()
+	}
+	return nil
+}
+
+// signedPayload is what a signature covers: the method, the signing time and
+// the deterministically encoded request
+func signedPayload(method, signedAt string, req proto.Message) ([]byte, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, 0, len(method)+len(signedAt)+len(body)+2)
+	payload = append(payload, method...)
+	payload = append(payload, '\n')
+	payload = append(payload, signedAt...)
+	payload = append(payload, '\n')
+	return append(payload, body...), nil
+}
@@ -0,0 +1,57 @@
+// Package middleware provides reusable chord.Middleware for a node's RPC
+// server and client: request logging, shared-token authentication, per-method
+// and per-tenant metrics and fault injection. Install them with Node.Use before starting
+// the node.
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Logging logs every incoming and outgoing RPC with its duration, status
+// code and, when it has one, the client and trace of its chord.Request. A
+// nil logger uses the standard logger.
+func Logging(logger *log.Logger) chord.Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	logCall := func(ctx context.Context, side, method string, start time.Time, err error) {
+		var request string
+		if req, ok := chord.RequestFrom(ctx); ok {
+			request = " trace=" + req.TraceID
+			if req.Client != "" {
+				request += " client=" + req.Client
+			}
+		}
+		logger.Printf("rpc %s %s %s in %v%s", side, method, status.Code(err), time.Since(start), request)
+	}
+
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			logCall(ctx, "server", info.FullMethod, start, err)
+			return resp, err
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			logCall(ss.Context(), "server", info.FullMethod, start, err)
+			return err
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			logCall(ctx, "client", method, start, err)
+			return err
+		},
+	}
+}
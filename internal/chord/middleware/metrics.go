@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+)
+
+// MethodStats are the counters of one RPC method
+type MethodStats struct {
+	Calls   int64
+	Errors  int64
+	Latency time.Duration // Total time spent in calls
+}
+
+// Metrics counts calls, errors and latency per RPC method, separately for
+// the calls a node serves and the calls it makes
+type Metrics struct {
+	mu     sync.Mutex
+	server map[string]*MethodStats
+	client map[string]*MethodStats
+}
+
+// NewMetrics creates empty RPC metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		server: make(map[string]*MethodStats),
+		client: make(map[string]*MethodStats),
+	}
+}
+
+// Middleware returns the middleware that records into m
+func (m *Metrics) Middleware() chord.Middleware {
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			m.record(m.server, info.FullMethod, start, err)
+			return resp, err
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			m.record(m.server, info.FullMethod, start, err)
+			return err
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			m.record(m.client, method, start, err)
+			return err
+		},
+	}
+}
+
+// Server returns a copy of the counters of served calls by method
+func (m *Metrics) Server() map[string]MethodStats {
+	return m.snapshot(m.server)
+}
+
+// Client returns a copy of the counters of outgoing calls by method
+func (m *Metrics) Client() map[string]MethodStats {
+	return m.snapshot(m.client)
+}
+
+func (m *Metrics) record(stats map[string]*MethodStats, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := stats[method]
+	if !ok {
+		s = &MethodStats{}
+		stats[method] = s
+	}
+	s.Calls++
+	s.Latency += elapsed
+	if err != nil {
+		s.Errors++
+	}
+}
+
+func (m *Metrics) snapshot(stats map[string]*MethodStats) map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(stats))
+	for method, s := range stats {
+		snapshot[method] = *s
+	}
+	return snapshot
+}
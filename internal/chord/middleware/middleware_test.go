@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func startNode(t *testing.T, address string, middleware ...chord.Middleware) *chord.Node {
+	node := chord.NewNode(address, nil)
+	node.Use(middleware...)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	return node
+}
+
+func TestAuth(t *testing.T) {
+	server := startNode(t, "localhost:8380", Auth("secret"))
+	trusted := startNode(t, "localhost:8381", Auth("secret"))
+	stranger := startNode(t, "localhost:8382", Auth("wrong"))
+
+	ctx := context.Background()
+	if _, err := trusted.RemotePeers(ctx, server.GetAddress(), 1); err != nil {
+		t.Errorf("Call with the right token failed: %v", err)
+	}
+	_, err := stranger.RemotePeers(ctx, server.GetAddress(), 1)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestMetricsAndFaults(t *testing.T) {
+	metrics := NewMetrics()
+	server := startNode(t, "localhost:8383", metrics.Middleware(),
+		FaultInjection(Faults{ErrorRate: 1, Methods: []string{"/GetDensity"}}))
+	client := startNode(t, "localhost:8384")
+
+	ctx := context.Background()
+	if _, err := client.RemotePeers(ctx, server.GetAddress(), 1); err != nil {
+		t.Fatalf("RemotePeers failed: %v", err)
+	}
+	if _, err := client.RemoteDensity(ctx, server.GetAddress(), true); err == nil {
+		t.Error("Expected the injected fault to fail GetDensity")
+	}
+
+	stats := metrics.Server()
+	peers := stats["/chord.v1.ChordService/GetPeers"]
+	if peers.Calls != 1 || peers.Errors != 0 {
+		t.Errorf("Unexpected GetPeers stats: %+v", peers)
+	}
+	// Metrics sits outside fault injection, so it sees the failed call
+	density := stats["/chord.v1.ChordService/GetDensity"]
+	if density.Calls != 1 || density.Errors != 1 {
+		t.Errorf("Unexpected GetDensity stats: %+v", density)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	partition := NewPartition()
+	server := startNode(t, "localhost:8385")
+	client := startNode(t, "localhost:8386", partition.Middleware())
+
+	ctx := context.Background()
+	partition.Block(server.GetAddress())
+	if _, err := client.RemotePeers(ctx, server.GetAddress(), 1); !errors.Is(err, chord.ErrPeerUnreachable) {
+		t.Errorf("Expected a partitioned peer to be unreachable, got %v", err)
+	}
+
+	partition.Heal()
+	if _, err := client.RemotePeers(ctx, server.GetAddress(), 1); err != nil {
+		t.Errorf("RemotePeers failed after healing: %v", err)
+	}
+}
+
+func TestTenants(t *testing.T) {
+	m, err := metrics.NewMetrics("0123456789abcdef", t.TempDir(), "test")
+	if err != nil {
+		t.Fatalf("NewMetrics failed: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	server := startNode(t, "localhost:8387", Tenants(m))
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewChordServiceClient(conn)
+
+	ctx := context.Background()
+	for _, key := range []string{"acme/a", "acme/b", "plain"} {
+		if _, err := client.Put(ctx, &pb.PutRequest{Key: key, Value: []byte("value")}); err != nil {
+			t.Fatalf("Put %s failed: %v", key, err)
+		}
+	}
+	if _, err := client.GetBatch(ctx, &pb.GetBatchRequest{Keys: []string{"acme/a", "plain"}}); err != nil {
+		t.Fatalf("GetBatch failed: %v", err)
+	}
+	// Routing RPCs are not accounted to tenants
+	if _, err := client.Ping(ctx, &pb.PingRequest{}); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	stats := m.TenantStats()
+	if len(stats) != 2 {
+		t.Fatalf("Expected two tenants, got %+v", stats)
+	}
+	if s := stats["acme"]; s.Writes != 2 || s.Reads != 1 {
+		t.Errorf("Unexpected acme stats: %+v", s)
+	}
+	if s := stats[metrics.DefaultTenant]; s.Writes != 1 || s.Reads != 1 {
+		t.Errorf("Unexpected default tenant stats: %+v", s)
+	}
+
+	m.UpdateTenantUsage(map[string]metrics.TenantUsage{"acme": {Keys: 2, Bytes: 10}})
+	var out strings.Builder
+	if err := m.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	for _, line := range []string{
+		`chord_tenant_writes_total{tenant="acme"} 2`,
+		`chord_tenant_reads_total{tenant="default"} 1`,
+		`chord_tenant_keys{tenant="acme"} 2`,
+		`chord_tenant_bytes{tenant="default"} 0`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	dir := t.TempDir()
+	serverKey, err := GenerateKey(dir + "/server.key")
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	if _, err := GenerateKey(dir + "/server.key"); err == nil {
+		t.Error("Expected GenerateKey to refuse overwriting a key")
+	}
+	loaded, err := LoadKey(dir + "/server.key")
+	if err != nil || !loaded.Equal(serverKey) {
+		t.Fatalf("LoadKey returned %v, %v", loaded, err)
+	}
+	clientKey, err := GenerateKey(dir + "/client.key")
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	server := chord.NewNode("localhost:8440", KeyID(serverKey.Public().(ed25519.PublicKey)))
+	server.Use(Identity(serverKey))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	if err := server.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	dial := func(opts ...grpc.DialOption) pb.ChordServiceClient {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient(server.GetAddress(), opts...)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewChordServiceClient(conn)
+	}
+	signed := dial(grpc.WithUnaryInterceptor(Identity(clientKey).UnaryClient))
+	unsigned := dial()
+
+	own := &pb.Node{Id: KeyID(clientKey.Public().(ed25519.PublicKey)).String(), Address: "localhost:8441"}
+	forged := &pb.Node{Id: hash.GenerateID("localhost:8442").String(), Address: "localhost:8442"}
+
+	ctx := context.Background()
+	if _, err := signed.Notify(ctx, &pb.NotifyRequest{Node: own}); err != nil {
+		t.Errorf("Notify claiming the signer's own ID failed: %v", err)
+	}
+	if _, err := signed.Notify(ctx, &pb.NotifyRequest{Node: forged}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected a forged ID to be Unauthenticated, got %v", err)
+	}
+	if _, err := unsigned.Notify(ctx, &pb.NotifyRequest{Node: own}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected an unsigned claim to be Unauthenticated, got %v", err)
+	}
+	// RPCs that claim no ring position may be unsigned
+	if _, err := unsigned.GetInfo(ctx, &pb.GetInfoRequest{}); err != nil {
+		t.Errorf("Unsigned GetInfo failed: %v", err)
+	}
+}
+
+func TestAccess(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	access, err := Access(secret)
+	if err != nil {
+		t.Fatalf("Access failed: %v", err)
+	}
+	server := startNode(t, "localhost:8596", access)
+	peer := startNode(t, "localhost:8597", access)
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewChordServiceClient(conn)
+
+	issue := func(claims AccessClaims) context.Context {
+		token, err := IssueToken(secret, claims)
+		if err != nil {
+			t.Fatalf("IssueToken failed: %v", err)
+		}
+		return WithAccessToken(context.Background(), token)
+	}
+	writer := issue(AccessClaims{Subject: "writer", Scopes: []string{"photos:write"}})
+	expired := issue(AccessClaims{Scopes: []string{"photos:write"}, ExpiresAt: time.Now().Add(-time.Minute)})
+	forged, _ := IssueToken([]byte("another secret!!"), AccessClaims{Scopes: []string{"*:write"}})
+
+	put := &pb.PutRequest{Key: "photos/a", Value: []byte("value")}
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{"no token", context.Background(), func(ctx context.Context) error { _, err := client.Put(ctx, put); return err }, codes.Unauthenticated},
+		{"expired", expired, func(ctx context.Context) error { _, err := client.Put(ctx, put); return err }, codes.Unauthenticated},
+		{"forged", WithAccessToken(context.Background(), forged), func(ctx context.Context) error { _, err := client.Put(ctx, put); return err }, codes.Unauthenticated},
+		{"write", writer, func(ctx context.Context) error { _, err := client.Put(ctx, put); return err }, codes.OK},
+		{"read without scope", writer, func(ctx context.Context) error {
+			_, err := client.Get(ctx, &pb.GetRequest{Key: "photos/a"})
+			return err
+		}, codes.PermissionDenied},
+		{"other bucket", writer, func(ctx context.Context) error {
+			_, err := client.PutBatch(ctx, &pb.PutBatchRequest{Items: []*pb.KeyValue{{Key: "photos/b"}, {Key: "docs/b"}}})
+			return err
+		}, codes.PermissionDenied},
+		{"admin RPC", writer, func(ctx context.Context) error {
+			_, err := client.GetHotKeys(ctx, &pb.GetHotKeysRequest{})
+			return err
+		}, codes.PermissionDenied},
+		{"lookup", context.Background(), func(ctx context.Context) error {
+			_, err := client.GetPeers(ctx, &pb.GetPeersRequest{})
+			return err
+		}, codes.OK},
+	} {
+		if err := tc.call(tc.ctx); status.Code(err) != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	// Nodes sharing the secret call each other with full access
+	stats, err := peer.RemoteBucketStats(context.Background(), server.GetAddress(), "")
+	if err != nil || len(stats) != 1 || stats[0].Bucket != "photos" {
+		t.Errorf("RemoteBucketStats between nodes returned %v, %v", stats, err)
+	}
+}
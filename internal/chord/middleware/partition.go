@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Partition cuts a node off from a set of peers by failing the calls it
+// makes to them with codes.Unavailable, as if they were down. Installing one
+// on every node on both sides of a split simulates a network partition.
+type Partition struct {
+	mu      sync.RWMutex
+	blocked map[string]bool
+}
+
+// NewPartition creates a partition that blocks nothing
+func NewPartition() *Partition {
+	return &Partition{blocked: make(map[string]bool)}
+}
+
+// Block fails every later call to the peers at addresses
+func (p *Partition) Block(addresses ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, address := range addresses {
+		p.blocked[address] = true
+	}
+}
+
+// Heal unblocks all peers
+func (p *Partition) Heal() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.blocked = make(map[string]bool)
+}
+
+// Blocked reports whether calls to the peer at address are failed
+func (p *Partition) Blocked(address string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.blocked[address]
+}
+
+// Middleware returns the middleware that applies p to outgoing calls
+func (p *Partition) Middleware() chord.Middleware {
+	return chord.Middleware{
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if p.Blocked(cc.Target()) {
+				return status.Errorf(codes.Unavailable, "%s is partitioned away", cc.Target())
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			if p.Blocked(cc.Target()) {
+				return nil, status.Errorf(codes.Unavailable, "%s is partitioned away", cc.Target())
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"path"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/internal/metrics"
+
+	"google.golang.org/grpc"
+)
+
+// tenantOps maps the served key-value methods to whether they write
+var tenantOps = map[string]bool{
+	"Get":            false,
+	"GetBatch":       false,
+	"Put":            true,
+	"PutBatch":       true,
+	"ConditionalPut": true,
+}
+
+// Tenants records the key-value reads and writes a node serves into m,
+// broken down by the tenant of each key (see metrics.TenantOf). A batch
+// counts once for every tenant it touches.
+func Tenants(m *metrics.Metrics) chord.Middleware {
+	return chord.Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			write, ok := tenantOps[path.Base(info.FullMethod)]
+			if !ok {
+				return handler(ctx, req)
+			}
+
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			elapsed := time.Since(start)
+			for tenant := range requestTenants(req) {
+				m.RecordTenantOp(tenant, write, elapsed, err)
+			}
+			return resp, err
+		},
+	}
+}
+
+// requestTenants returns the tenants of the keys a request carries
+func requestTenants(req any) map[string]bool {
+	tenants := make(map[string]bool)
+	switch r := req.(type) {
+	case interface{ GetKey() string }:
+		tenants[metrics.TenantOf(r.GetKey())] = true
+	case *pb.GetBatchRequest:
+		for _, key := range r.Keys {
+			tenants[metrics.TenantOf(key)] = true
+		}
+	case *pb.PutBatchRequest:
+		for _, item := range r.Items {
+			tenants[metrics.TenantOf(item.Key)] = true
+		}
+	}
+	return tenants
+}
@@ -0,0 +1,746 @@
+package chord
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// A node behind NAT can call its peers but cannot be called by them, so
+// its predecessor's stabilization and every lookup routed to it fail. Such
+// a node registers with a relay, a public node that keeps a Relay stream
+// open to it, and advertises an address on the relay:
+//
+//	relay-host:port/relay/<node ID>
+//
+// Peers dial the relay for these addresses and name the node in the
+// chord-relay-to header; the relay forwards each unary call down the stream
+// and the node answers it through its own server. With hole punching, the
+// relay also serves as rendezvous: it tells the node to send probes to the
+// caller's QUIC address, opening the node's NAT to it, and gives the caller
+// the node's QUIC address to dial directly.
+const (
+	// relayHeader names the node behind NAT a call through its relay is for
+	relayHeader = "chord-relay-to"
+	// relayPath separates the relay from the node ID in relayed addresses
+	relayPath = "/relay/"
+	// reachabilityTimeout bounds the call back of a reachability check
+	reachabilityTimeout = 3 * time.Second
+	// relayRetry is how long a node waits to register again after its Relay
+	// stream broke
+	relayRetry = time.Second
+	// punchProbes datagrams are sent punchInterval apart to open a NAT to a
+	// caller; punchTimeout bounds the whole hole punching attempt
+	punchProbes   = 3
+	punchInterval = 50 * time.Millisecond
+	punchTimeout  = 2 * time.Second
+)
+
+// NATPolicy configures how a node deals with NAT, its own and its peers'
+type NATPolicy struct {
+	// Relay is the address of a public node serving relays. At Start, the
+	// node asks it to call the node back; if it cannot, the node registers
+	// with it and advertises an address on it.
+	Relay string
+	// ServeRelay lets nodes behind NAT register with this node, and makes it
+	// the rendezvous of their hole punching
+	ServeRelay bool
+	// HolePunch makes this node ask the relay of a node behind NAT for a
+	// direct connection before relaying calls through it. Both nodes must
+	// use NetworkQUIC.
+	HolePunch bool
+}
+
+// NATStatus describes a node's reachability and the relaying it does
+type NATStatus struct {
+	// Checked reports whether another node tried to call this node back,
+	// at Start with a relay or when joining
+	Checked   bool
+	Reachable bool
+	// Observed is the address this node's requests came from, as seen by
+	// the node that checked it
+	Observed string
+	// Relay is the node peers reach this node through, "" if they call it
+	// directly
+	Relay string
+	// Registered is the number of nodes behind NAT using this node as their
+	// relay, Forwarded the calls relayed to them
+	Registered int
+	Forwarded  int64
+	// Punched counts direct connections to nodes behind NAT opened by hole
+	// punching, PunchFailures the attempts that fell back to their relay
+	Punched       int64
+	PunchFailures int64
+}
+
+// natTraversal holds a node's NAT policy and state
+type natTraversal struct {
+	mu     sync.Mutex
+	policy NATPolicy
+	status NATStatus
+	// registered holds the nodes relayed through this node, by ID
+	registered map[string]*relayedNode
+	// loopback is the connection a node behind NAT serves relayed calls
+	// through
+	loopback *grpc.ClientConn
+
+	forwarded     atomic.Int64
+	punched       atomic.Int64
+	punchFailures atomic.Int64
+}
+
+// SetNAT sets how the node deals with NAT. It must be called before Start.
+func (n *Node) SetNAT(policy NATPolicy) {
+	n.nat.mu.Lock()
+	defer n.nat.mu.Unlock()
+
+	n.nat.policy = policy
+}
+
+// NATStatus returns the node's reachability and relaying counters
+func (n *Node) NATStatus() NATStatus {
+	n.nat.mu.Lock()
+	defer n.nat.mu.Unlock()
+
+	status := n.nat.status
+	status.Registered = len(n.nat.registered)
+	status.Forwarded = n.nat.forwarded.Load()
+	status.Punched = n.nat.punched.Load()
+	status.PunchFailures = n.nat.punchFailures.Load()
+	return status
+}
+
+// natPolicy returns the policy set with SetNAT
+func (n *Node) natPolicy() NATPolicy {
+	n.nat.mu.Lock()
+	defer n.nat.mu.Unlock()
+
+	return n.nat.policy
+}
+
+// relayAddress returns the address of the node with id on relay
+func relayAddress(relay string, id *hash.Hash) string {
+	return relay + relayPath + id.String()
+}
+
+// parseRelayAddress splits a relayed address into its relay and node ID.
+// The ID follows the last relayPath, so a relay on a Unix domain socket
+// whose path holds one is parsed right.
+func parseRelayAddress(address string) (relay, id string, ok bool) {
+	i := strings.LastIndex(address, relayPath)
+	if i <= 0 {
+		return "", "", false
+	}
+	relay, id = address[:i], address[i+len(relayPath):]
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return "", "", false
+	}
+	return relay, id, true
+}
+
+// traverseNAT asks the node's relay, if it has one, to call it back, and
+// registers with it if it cannot. Called by Start before maintenance runs.
+func (n *Node) traverseNAT() error {
+	policy := n.natPolicy()
+	if policy.Relay == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := n.checkReachability(ctx, policy.Relay)
+	if err != nil {
+		return fmt.Errorf("failed to check reachability with relay %s: %w", policy.Relay, err)
+	}
+	if resp.Reachable {
+		return nil
+	}
+	if err := n.openRelay(policy.Relay); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.address = relayAddress(policy.Relay, n.id)
+	self := n.GetNodeInfo()
+	for i := range n.fingers {
+		n.fingers[i] = self
+	}
+	n.mu.Unlock()
+
+	n.nat.mu.Lock()
+	n.nat.status.Relay = policy.Relay
+	n.nat.mu.Unlock()
+	log.Printf("Node %s is behind NAT (seen as %s), reachable through relay %s",
+		n.id.Short(), resp.Observed, policy.Relay)
+	return nil
+}
+
+// checkJoinReachability fails with ErrBehindNAT if the bootstrap cannot
+// call this node back. Bootstraps that predate the check are trusted.
+func (n *Node) checkJoinReachability(bootstrap string) error {
+	if n.NATStatus().Relay != "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := n.checkReachability(ctx, bootstrap)
+	if err != nil {
+		// Joining reports an unreachable bootstrap
+		return nil
+	}
+	if !resp.Reachable {
+		return fmt.Errorf("%w: %s cannot call back %s (requests come from %s): %s; use a relay",
+			ErrBehindNAT, bootstrap, n.GetAddress(), resp.Observed, resp.Error)
+	}
+	return nil
+}
+
+// checkReachability asks the node at address to call this node back, and
+// records the result
+func (n *Node) checkReachability(ctx context.Context, address string) (*pb.CheckReachabilityResponse, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.CheckReachability(ctx, &pb.CheckReachabilityRequest{Address: n.GetAddress()})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+
+	n.nat.mu.Lock()
+	n.nat.status.Checked = true
+	n.nat.status.Reachable = resp.Reachable
+	n.nat.status.Observed = resp.Observed
+	n.nat.mu.Unlock()
+	return resp, nil
+}
+
+// CheckReachability calls the caller back at the address it advertises
+func (n *Node) CheckReachability(ctx context.Context, req *pb.CheckReachabilityRequest) (*pb.CheckReachabilityResponse, error) {
+	n.countMessage()
+
+	resp := &pb.CheckReachabilityResponse{}
+	if p, ok := peer.FromContext(ctx); ok {
+		resp.Observed = p.Addr.String()
+	}
+	if err := n.callBack(ctx, req.Address); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Reachable = true
+	}
+	return resp, nil
+}
+
+// callBack pings address over a new connection. Any answer, even a
+// refusal by the peer's middleware, shows it can be called.
+func (n *Node) callBack(ctx context.Context, address string) error {
+	if _, _, ok := parseRelayAddress(address); ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, reachabilityTimeout)
+	defer cancel()
+
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(n.dialCounted),
+	}, n.dialOptions()...)
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = pb.NewChordServiceClient(conn).Ping(ctx, &pb.PingRequest{Requester: toProtoNode(n.GetNodeInfo())})
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return err
+	}
+	return nil
+}
+
+// relayedNode is a node behind NAT registered with this node
+type relayedNode struct {
+	stream   pb.ChordService_RelayServer
+	observed net.Addr
+	done     chan struct{}
+
+	sendMu sync.Mutex
+	nextID atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[uint64]chan *pb.RelayFrame
+}
+
+// send sends a frame down the node's stream
+func (r *relayedNode) send(frame *pb.RelayFrame) error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	return r.stream.Send(frame)
+}
+
+// call forwards a call to the node and waits for its reply
+func (r *relayedNode) call(ctx context.Context, frame *pb.RelayFrame) (*pb.RelayFrame, error) {
+	frame.CallId = r.nextID.Add(1)
+	replies := make(chan *pb.RelayFrame, 1)
+	r.mu.Lock()
+	r.pending[frame.CallId] = replies
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, frame.CallId)
+		r.mu.Unlock()
+	}()
+
+	if err := r.send(frame); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "relay stream broken: %v", err)
+	}
+	select {
+	case reply := <-replies:
+		return reply, nil
+	case <-r.done:
+		return nil, status.Error(codes.Unavailable, "relayed node disconnected")
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// deliver hands a reply to the call waiting for it
+func (r *relayedNode) deliver(frame *pb.RelayFrame) {
+	r.mu.Lock()
+	replies, ok := r.pending[frame.CallId]
+	r.mu.Unlock()
+	if ok {
+		replies <- frame
+	}
+}
+
+// Relay registers the calling node behind NAT and forwards calls to it
+// until it disconnects
+func (n *Node) Relay(stream pb.ChordService_RelayServer) error {
+	if !n.natPolicy().ServeRelay {
+		return status.Error(codes.FailedPrecondition, "this node does not serve relays")
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	info, err := fromProtoNode(first.Node)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid node: %v", err)
+	}
+
+	relayed := &relayedNode{
+		stream:  stream,
+		done:    make(chan struct{}),
+		pending: make(map[uint64]chan *pb.RelayFrame),
+	}
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		relayed.observed = p.Addr
+	}
+	id := info.ID.String()
+	n.nat.mu.Lock()
+	if n.nat.registered == nil {
+		n.nat.registered = make(map[string]*relayedNode)
+	}
+	n.nat.registered[id] = relayed
+	n.nat.mu.Unlock()
+	defer func() {
+		close(relayed.done)
+		n.nat.mu.Lock()
+		if n.nat.registered[id] == relayed {
+			delete(n.nat.registered, id)
+		}
+		n.nat.mu.Unlock()
+	}()
+
+	if err := relayed.send(&pb.RelayFrame{}); err != nil {
+		return err
+	}
+	log.Printf("Node %s: relaying for %s (%s)", n.id.Short(), info.ID.Short(), relayed.observed)
+
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			frame, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			relayed.deliver(frame)
+		}
+	}()
+	select {
+	case err := <-errs:
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	case <-n.Done():
+		return nil
+	}
+}
+
+// relayedTo returns the node behind NAT registered with id
+func (n *Node) relayedTo(id string) *relayedNode {
+	n.nat.mu.Lock()
+	defer n.nat.mu.Unlock()
+
+	return n.nat.registered[id]
+}
+
+// relayUnaryServer forwards calls naming a node behind NAT in relayHeader
+// to it. The node applies its own middleware to them.
+func (n *Node) relayUnaryServer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	targets := md.Get(relayHeader)
+	if len(targets) == 0 {
+		return handler(ctx, req)
+	}
+	relayed := n.relayedTo(targets[0])
+	if relayed == nil {
+		return nil, status.Errorf(codes.Unavailable, "node %s is not registered with this relay", targets[0])
+	}
+
+	payload, err := proto.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode relayed request: %v", err)
+	}
+	frame := &pb.RelayFrame{Method: info.FullMethod, Payload: payload}
+	for key, values := range md {
+		if !relayedHeader(key) {
+			continue
+		}
+		for _, value := range values {
+			frame.Headers = append(frame.Headers, &pb.RelayHeader{Key: key, Value: value})
+		}
+	}
+	reply, err := relayed.call(ctx, frame)
+	if err != nil {
+		return nil, err
+	}
+	n.nat.forwarded.Add(1)
+
+	if len(reply.Status) > 0 {
+		var st spb.Status
+		if err := proto.Unmarshal(reply.Status, &st); err != nil {
+			return nil, status.Error(codes.Internal, "malformed relayed error")
+		}
+		return nil, status.ErrorProto(&st)
+	}
+	_, resp, err := methodMessages(info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(reply.Payload, resp); err != nil {
+		return nil, status.Errorf(codes.Internal, "malformed relayed reply: %v", err)
+	}
+	return resp, nil
+}
+
+// relayStreamServer refuses streams for nodes behind NAT; only unary calls
+// are relayed
+func (n *Node) relayStreamServer(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	if len(md.Get(relayHeader)) > 0 {
+		return status.Errorf(codes.Unimplemented, "%s is not relayed", info.FullMethod)
+	}
+	return handler(srv, ss)
+}
+
+// relayedHeader reports whether the header key of a relayed call is passed
+// on to the node; gRPC's own headers are not
+func relayedHeader(key string) bool {
+	switch {
+	case key == relayHeader, key == "content-type", key == "user-agent":
+		return false
+	case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "grpc-"):
+		return false
+	}
+	return true
+}
+
+// methodMessages returns new request and reply messages of a gRPC method
+func methodMessages(method string) (proto.Message, proto.Message, error) {
+	name := protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", "."))
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	in, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown request of %s", method)
+	}
+	out, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown reply of %s", method)
+	}
+	return in.New().Interface(), out.New().Interface(), nil
+}
+
+// relayDialOptions name the node behind NAT with id on every call over a
+// connection to its relay
+func relayDialOptions(id string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, relayHeader, id), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, relayHeader, id), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// openRelay registers the node with relay and serves the calls it forwards
+// until the node stops, registering again whenever the stream breaks
+func (n *Node) openRelay(relay string) error {
+	stream, err := n.registerRelay(relay)
+	if err != nil {
+		return err
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		for {
+			n.serveRelay(stream)
+			for {
+				select {
+				case <-n.ctx.Done():
+					return
+				case <-time.After(relayRetry):
+				}
+				if stream, err = n.registerRelay(relay); err == nil {
+					break
+				}
+				log.Printf("Node %s: failed to register with relay %s: %v", n.id.Short(), relay, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// registerRelay opens a Relay stream to relay and waits for it to
+// acknowledge the node
+func (n *Node) registerRelay(relay string) (pb.ChordService_RelayClient, error) {
+	client, err := n.getClient(relay)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.Relay(n.ctx)
+	if err != nil {
+		return nil, fromStatus(relay, err)
+	}
+	if err := stream.Send(&pb.RelayFrame{Node: toProtoNode(n.GetNodeInfo())}); err != nil {
+		return nil, fromStatus(relay, err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		return nil, fmt.Errorf("relay %s refused the node: %w", relay, fromStatus(relay, err))
+	}
+	return stream, nil
+}
+
+// serveRelay answers the calls forwarded by the relay until the stream
+// breaks
+func (n *Node) serveRelay(stream pb.ChordService_RelayClient) {
+	var sendMu sync.Mutex
+	for {
+		frame, err := stream.Recv()
+		if err != nil {
+			if n.ctx.Err() == nil {
+				log.Printf("Node %s: relay stream broken: %v", n.id.Short(), err)
+			}
+			return
+		}
+		switch {
+		case frame.PunchTo != "":
+			go n.punch(frame.PunchTo)
+		case frame.Method != "":
+			go func() {
+				reply := n.serveRelayedCall(frame)
+				sendMu.Lock()
+				defer sendMu.Unlock()
+				stream.Send(reply)
+			}()
+		}
+	}
+}
+
+// serveRelayedCall runs a forwarded call through the node's own server
+func (n *Node) serveRelayedCall(frame *pb.RelayFrame) *pb.RelayFrame {
+	reply := &pb.RelayFrame{CallId: frame.CallId}
+	resp, err := n.invokeLoopback(frame)
+	if err == nil {
+		reply.Payload, err = proto.Marshal(resp)
+	}
+	if err != nil {
+		reply.Payload = nil
+		reply.Status, _ = proto.Marshal(status.Convert(err).Proto())
+	}
+	return reply
+}
+
+// invokeLoopback calls the method of a forwarded call on the node's own
+// listener, with the caller's headers, so the node's middleware applies
+func (n *Node) invokeLoopback(frame *pb.RelayFrame) (proto.Message, error) {
+	req, resp, err := methodMessages(frame.Method)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(frame.Payload, req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid relayed request: %v", err)
+	}
+	conn, err := n.loopbackConn()
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata.MD{}
+	for _, header := range frame.Headers {
+		md.Append(header.Key, header.Value)
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+	if err := conn.Invoke(metadata.NewOutgoingContext(ctx, md), frame.Method, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// loopbackConn returns the connection to the node's own listener
+func (n *Node) loopbackConn() (*grpc.ClientConn, error) {
+	n.nat.mu.Lock()
+	defer n.nat.mu.Unlock()
+
+	if n.nat.loopback != nil {
+		return n.nat.loopback, nil
+	}
+	n.mu.RLock()
+	addr := n.listener.Addr()
+	n.mu.RUnlock()
+	target := addr.String()
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		if addr.IP.IsUnspecified() {
+			target = net.JoinHostPort("localhost", fmt.Sprint(addr.Port))
+		}
+	case *net.UnixAddr:
+		target = unixScheme + addr.Name
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	n.nat.loopback = conn
+	return conn, nil
+}
+
+// close closes the loopback connection
+func (nt *natTraversal) close() {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+
+	if nt.loopback != nil {
+		nt.loopback.Close()
+		nt.loopback = nil
+	}
+}
+
+// Rendezvous tells a node behind NAT registered here to open its NAT to
+// the caller, and returns the address the caller can reach it at
+func (n *Node) Rendezvous(ctx context.Context, req *pb.RendezvousRequest) (*pb.RendezvousResponse, error) {
+	n.countMessage()
+
+	relayed := n.relayedTo(req.Target)
+	if relayed == nil {
+		return &pb.RendezvousResponse{Error: "node is not registered with this relay"}, nil
+	}
+	caller, ok := peer.FromContext(ctx)
+	if !ok || caller.Addr.Network() != "udp" || relayed.observed == nil || relayed.observed.Network() != "udp" {
+		return &pb.RendezvousResponse{Error: "hole punching needs both nodes on QUIC"}, nil
+	}
+	if err := relayed.send(&pb.RelayFrame{PunchTo: caller.Addr.String()}); err != nil {
+		return &pb.RendezvousResponse{Error: err.Error()}, nil
+	}
+	return &pb.RendezvousResponse{Success: true, Address: relayed.observed.String()}, nil
+}
+
+// punch sends probes to address from the node's QUIC socket, so its NAT
+// lets that address's QUIC traffic in
+func (n *Node) punch(address string) {
+	n.mu.RLock()
+	transport := n.quicTransport
+	n.mu.RUnlock()
+	to, err := net.ResolveUDPAddr("udp", address)
+	if transport == nil || err != nil {
+		return
+	}
+	for i := 0; i < punchProbes; i++ {
+		// A zero first byte is not QUIC, so the peer's transport drops it
+		transport.WriteTo([]byte{0}, to)
+		time.Sleep(punchInterval)
+	}
+}
+
+// punchHole asks relay to open the NAT of the node with id to this node, and
+// returns the address to dial it at directly, or "" to call it through the
+// relay
+func (n *Node) punchHole(relay, id string) string {
+	n.mu.RLock()
+	network, transport := n.network, n.quicTransport
+	n.mu.RUnlock()
+	if !n.natPolicy().HolePunch || network != NetworkQUIC || transport == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, punchTimeout)
+	defer cancel()
+
+	client, err := n.getClient(relay)
+	if err != nil {
+		return ""
+	}
+	resp, err := client.Rendezvous(ctx, &pb.RendezvousRequest{Target: id})
+	if err == nil && !resp.Success {
+		err = errors.New(resp.Error)
+	}
+	if err == nil {
+		var conn net.Conn
+		if conn, err = dialQUIC(ctx, transport, resp.Address); err == nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		n.nat.punchFailures.Add(1)
+		log.Printf("Node %s: hole punching to %s failed, relaying: %v", n.id.Short(), id, err)
+		return ""
+	}
+	n.nat.punched.Add(1)
+	return resp.Address
+}
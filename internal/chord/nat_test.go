@@ -0,0 +1,132 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startNATNode starts a node that advertises an address nobody listens on,
+// as a node behind NAT advertises its private address
+func startNATNode(t *testing.T, port int, network Network, policy NATPolicy) *Node {
+	addr := fmt.Sprintf("localhost:%d", port)
+	node := NewNodeWithAdvertise(addr, fmt.Sprintf("localhost:%d", port+50), hash.NewHashFromString(addr))
+	node.SetNetwork(network)
+	node.SetNAT(policy)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node behind NAT: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	return node
+}
+
+func TestJoinBehindNAT(t *testing.T) {
+	nodes := startTestRing(t, 8520, 1)
+	natted := startNATNode(t, 8521, NetworkTCP, NATPolicy{})
+
+	err := natted.Join(nodes[0].GetAddress())
+	if !errors.Is(err, ErrBehindNAT) {
+		t.Fatalf("Expected joining from behind NAT without a relay to fail, got %v", err)
+	}
+	if nattedStatus := natted.NATStatus(); !nattedStatus.Checked || nattedStatus.Reachable || nattedStatus.Observed == "" {
+		t.Errorf("Expected the node to know it is unreachable: %+v", nattedStatus)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	relay := NewNode("localhost:8522", hash.NewHashFromString("localhost:8522"))
+	relay.SetNAT(NATPolicy{ServeRelay: true})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+	t.Cleanup(relay.Stop)
+	if err := relay.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	public := NewNode("localhost:8523", hash.NewHashFromString("localhost:8523"))
+	if err := public.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(public.Stop)
+	if err := public.Join(relay.GetAddress()); err != nil {
+		t.Fatalf("Failed to join public node: %v", err)
+	}
+
+	natted := startNATNode(t, 8524, NetworkTCP, NATPolicy{Relay: relay.GetAddress()})
+	if !strings.HasPrefix(natted.GetAddress(), relay.GetAddress()+relayPath) {
+		t.Fatalf("Expected the node to advertise an address on its relay, got %s", natted.GetAddress())
+	}
+	if err := natted.Join(relay.GetAddress()); err != nil {
+		t.Fatalf("Failed to join through the relay: %v", err)
+	}
+
+	nodes := []*Node{relay, public, natted}
+	for round := 0; round < len(nodes)+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	checkRing(t, nodes)
+
+	// Peers call the node behind NAT through the relay
+	if err := public.remotePing(context.Background(), natted.GetAddress()); err != nil {
+		t.Fatalf("Ping through the relay failed: %v", err)
+	}
+	client, err := public.getClient(natted.GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	stream, err := client.GetSnapshot(context.Background(), &pb.GetSnapshotRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected streams not to be relayed, got %v", err)
+	}
+
+	relayStatus := relay.NATStatus()
+	if relayStatus.Registered != 1 || relayStatus.Forwarded == 0 {
+		t.Errorf("Expected the relay to forward calls to one node: %+v", relayStatus)
+	}
+	if nattedStatus := natted.NATStatus(); nattedStatus.Reachable || nattedStatus.Relay != relay.GetAddress() {
+		t.Errorf("Expected the node to be reached through its relay: %+v", nattedStatus)
+	}
+}
+
+func TestHolePunching(t *testing.T) {
+	relay := NewNode("localhost:8525", nil)
+	relay.SetNetwork(NetworkQUIC)
+	relay.SetNAT(NATPolicy{ServeRelay: true})
+	if err := relay.Start(); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+	t.Cleanup(relay.Stop)
+
+	natted := startNATNode(t, 8526, NetworkQUIC, NATPolicy{Relay: relay.GetAddress()})
+
+	caller := NewNode("localhost:8527", nil)
+	caller.SetNetwork(NetworkQUIC)
+	caller.SetNAT(NATPolicy{HolePunch: true})
+	if err := caller.Start(); err != nil {
+		t.Fatalf("Failed to start caller: %v", err)
+	}
+	t.Cleanup(caller.Stop)
+
+	if err := caller.remotePing(context.Background(), natted.GetAddress()); err != nil {
+		t.Fatalf("Ping of the node behind NAT failed: %v", err)
+	}
+	if callerStatus := caller.NATStatus(); callerStatus.Punched != 1 || callerStatus.PunchFailures != 0 {
+		t.Errorf("Expected a direct connection by hole punching: %+v", callerStatus)
+	}
+	if forwarded := relay.NATStatus().Forwarded; forwarded != 0 {
+		t.Errorf("Expected no calls through the relay, %d were forwarded", forwarded)
+	}
+}
@@ -6,14 +6,19 @@ import (
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
 	"chord-dht/pkg/hash"
-	pb "chord-dht/proto"
 	
+	"github.com/quic-go/quic-go"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -27,6 +32,8 @@ const (
 	CheckPredecessorInterval = 15 * time.Second
 	// RPCTimeout is the timeout for RPC calls
 	RPCTimeout = 10 * time.Second
+	// SuccessorListSize is how many successors each node tracks for failover
+	SuccessorListSize = 4
 )
 
 // Node represents a Chord DHT node
@@ -38,12 +45,14 @@ type Node struct {
 	id         *hash.Hash
 	address    string // Address advertised to other nodes
 	listenAddr string // Address to bind/listen on
+	hasher     hash.Provider // Places keys on the ring
 	
 	// Chord state
-	predecessor *NodeInfo
-	successor   *NodeInfo
-	fingers     []*NodeInfo
-	next        int // next finger to fix
+	predecessor   *NodeInfo
+	successor     *NodeInfo
+	successorList []*NodeInfo // successorList[0] is the successor
+	fingers       []*NodeInfo
+	next          int // next finger to fix
 	
 	// Network
 	server      *grpc.Server
@@ -60,17 +69,176 @@ type Node struct {
 	wg     sync.WaitGroup
 	
 	// Metrics (will be used by metrics module)
-	MessageCount int64
-	LookupCount  int64
+	MessageCount atomic.Int64
+	LookupCount  atomic.Int64
 	
-	// Storage (simple key-value store)
-	data map[string][]byte
+	// Storage (local key-value store)
+	storage Storage
+	dataMu  sync.RWMutex
+	
+	// Key range this node is authoritative for (see handoff.go)
+	own ownership
+	
+	// Chunking and pace of streamed hand-offs (see transfer.go)
+	transfers transfers
+	
+	// Replica set and pending rounds restoring the replication factor
+	// (see rereplication.go)
+	reReplication reReplication
+	
+	// How long tombstones of deleted keys are kept (see tombstones.go)
+	tombstones tombstones
+	
+	// Storage limits and eviction (see capacity.go)
+	capacity capacity
+	
+	// Number of nodes holding each key (see replication.go), hedged
+	// read and lookup state (see hedge.go, lookup.go) and replica
+	// selection (see selection.go)
+	replication int
+	hedge       hedger
+	lookupHedge hedger
+	selector    ReplicaSelector
+	health      ReplicaHealth
+	
+	// Admission of joining nodes when used as a bootstrap (see admission.go)
+	joins joinLimiter
+	
+	// Limits on incoming RPCs per peer and overall (see ratelimit.go)
+	limiter rateLimiter
+	
+	// Limit on the lookups forwarded at once (see lookuplimit.go)
+	lookups lookupLimiter
+	
+	// How maintenance RPCs are carried, and traffic counters (see
+	// transport.go, udp.go)
+	transport transport
+	
+	// Compression of the RPCs to peers (see compression.go)
+	compression compression
+	
+	// Reachability behind NAT, relays and hole punching (see nat.go)
+	nat natTraversal
+	
+	// What connections between nodes run over, and the QUIC listener
+	// serving peers next to the TCP one and its socket (see quic.go)
+	network       Network
+	quicListener  net.Listener
+	quicTransport *quic.Transport
+	
+	// Order of the IPv4 and IPv6 addresses of peers to dial (see address.go)
+	addressPreference AddressPreference
+	
+	// Resource usage and the pressure levels of peers (see pressure.go)
+	pressure pressureMonitor
+	
+	// Maintenance window state and deferred work (see maintenance.go)
+	maint maintenance
+	
+	// Additional gRPC services served next to ChordService
+	services []registeredService
+	
+	// Standard gRPC health service (see services.go)
+	healthServer *health.Server
+	
+	// Interceptor chains for the server and outgoing connections
+	middleware []Middleware
+	
+	// Called after every lookup this node starts
+	lookupObserver LookupObserver
+	
+	// Metrics recorded into the sink set with SetMetrics (see
+	// instruments.go)
+	instruments atomic.Pointer[instruments]
+	
+	// Changes of neighbors seen by this node (see history.go)
+	history membershipLog
+	
+	// Append-only record of membership events and transfers (see audit.go)
+	audit atomic.Pointer[AuditLog]
+	
+	// Pace of stabilization and fix-fingers (see stabilization.go)
+	stabilization stabilization
+	
+	// State while cut off from the whole ring (see isolation.go)
+	isolation isolation
+	
+	// Zone and weight advertised to peers (see metadata.go)
+	metadata nodeMetadata
+	
+	// Protocol versions spoken with peers (see version.go)
+	versions VersionRange
+	
+	// Logical ring the node belongs to, named on every RPC to peers (see
+	// rings.go)
+	ring string
+	
+	// Keys advertised as cached and invalidation handlers
+	// (see invalidation.go)
+	invalidation invalidation
+	
+	// Read rates of owned keys and hot copies held for other owners
+	// (see hotkeys.go)
+	hotKeys hotKeys
+	
+	// Deleted values that can still be restored (see trash.go)
+	trash trash
+	
+	// Quotas and counters of the buckets of keys (see buckets.go)
+	buckets buckets
+	
+	// Counters sampled at recent stats epochs (see statsepoch.go)
+	statsSamples statsSamples
+	
+	// Pieces of recent ring snapshots (see ringsnapshot.go)
+	ringSnapshots ringSnapshots
+	
+	// Counters of Stats not kept elsewhere (see stats.go)
+	stats nodeStats
+	
+	// Bytes of RPCs by category (see bandwidth.go)
+	bandwidth bandwidth
+	
+	// Periodic finger table checks (see fingercheck.go)
+	fingerCheck fingerCheck
+	
+	// Liveness and RTT of peers, shared by maintenance and routing
+	// (see liveness.go)
+	liveness liveness
+	
+	// Peers blacklisted from routing after repeated failures (see
+	// reputation.go)
+	reputation reputation
+	
+	// Membership view spread by gossip on notifications (see gossip.go)
+	gossip gossip
+	
+	// Subscribers of the live event stream (see events.go)
+	events ringEvents
+	
+	// Set once the routing state was computed from a static member list,
+	// which stops its maintenance (see static.go)
+	static atomic.Bool
+	
+	// Retry policies of joins, lookups, transfers and client requests
+	// (see retries.go)
+	retries RetryPolicies
+	
+	// Broadcast handlers by kind, and recently seen broadcast IDs
+	broadcastHandlers map[string]BroadcastHandler
+	seenBroadcasts    map[string]time.Time
+	broadcastMu       sync.Mutex
 }
 
 // NodeInfo represents information about a Chord node
 type NodeInfo struct {
 	ID      *hash.Hash
 	Address string
+	// What the node advertises about itself (see metadata.go); zero
+	// when unknown, as for nodes known only by address
+	Zone    string
+	Weight  uint32
+	Version uint32
 }
 
 // NewNode creates a new Chord node
@@ -94,23 +262,64 @@ func NewNodeWithAdvertise(listenAddr, advertiseAddr string, id *hash.Hash) *Node
 		connections: make(map[string]*grpc.ClientConn),
 		ctx:         ctx,
 		cancel:      cancel,
-		data:        make(map[string][]byte),
+		storage:     NewMemoryStorage(),
+		replication: 1,
+		selector:    PrimaryFirst{},
+		hasher:      hash.SHA1,
+		retries:     DefaultRetryPolicies(),
+		versions:    VersionRange{Min: MinProtocolVersion, Max: ProtocolVersion},
+		
+		healthServer:      health.NewServer(),
+		broadcastHandlers: make(map[string]BroadcastHandler),
+		seenBroadcasts:    make(map[string]time.Time),
 	}
+	node.reReplication.policy.Delay = DefaultReReplicationDelay
+	node.tombstones.policy.Horizon = DefaultTombstoneHorizon
+	node.tombstones.changed = make(chan struct{}, 1)
+	node.stabilization.changed = make(chan struct{}, 1)
+	node.fingerCheck.changed = make(chan struct{}, 1)
+	node.liveness.ttl = DefaultLivenessTTL
+	node.instruments.Store(newInstruments(metrics.Discard))
 	
 	// Store listen address separately for binding
 	node.listenAddr = listenAddr
 	
 	// Initialize finger table with advertise address
-	selfInfo := &NodeInfo{ID: id, Address: advertiseAddr}
+	selfInfo := node.GetNodeInfo()
 	for i := 0; i < FingerTableSize; i++ {
 		node.fingers[i] = selfInfo
 	}
 	
+	node.handleMaintenanceBroadcasts()
+	node.handleInvalidationBroadcasts()
+	node.handleStatsBroadcasts()
+	node.handleRingSnapshotBroadcasts()
+	
 	return node
 }
 
 // Start starts the Chord node
 func (n *Node) Start() error {
+	if err := n.listen(); err != nil {
+		return err
+	}
+	
+	// Behind NAT, peers reach the node through its relay from the start
+	// (see nat.go)
+	if err := n.traverseNAT(); err != nil {
+		n.Stop()
+		return err
+	}
+	
+	// Start maintenance routines
+	n.startMaintenance()
+	
+	log.Printf("Node %s listening on %s, advertising %s", n.id.Short(), n.listener.Addr(), n.GetAddress())
+	return nil
+}
+
+// listen starts serving gRPC on the node's listeners
+func (n *Node) listen() error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	
@@ -121,14 +330,47 @@ func (n *Node) Start() error {
 	}
 	
 	// Start gRPC server
-	listener, err := net.Listen("tcp", bindAddr)
+	if _, ok := unixPath(bindAddr); ok && (n.network == NetworkQUIC || n.transport.kind == TransportUDP) {
+		return fmt.Errorf("%s is a Unix domain socket, which cannot carry QUIC or the udp maintenance transport", bindAddr)
+	}
+	listener, err := listenAll(bindAddr)
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", bindAddr, err)
 	}
 	
+	listener = &countingListener{Listener: &meteredListener{Listener: listener, stats: &n.stats}, open: &n.pressure.inbound}
 	n.listener = listener
-	n.server = grpc.NewServer()
+	n.stats.started = time.Now()
+	n.server = grpc.NewServer(n.serverOptions()...)
 	pb.RegisterChordServiceServer(n.server, n)
+	for _, svc := range n.services {
+		n.server.RegisterService(svc.desc, svc.impl)
+	}
+	
+	n.registerHealth()
+	
+	if n.network == NetworkQUIC {
+		quicListener, err := n.listenQUIC(listener.Addr().String())
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		n.quicListener = &countingListener{Listener: &meteredListener{Listener: quicListener, stats: &n.stats}, open: &n.pressure.inbound}
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			if err := n.server.Serve(n.quicListener); err != nil && err != grpc.ErrServerStopped {
+				log.Printf("gRPC server error on QUIC: %v", err)
+			}
+		}()
+	}
+	
+	if n.transport.kind == TransportUDP {
+		if err := n.listenUDP(listener.Addr().String()); err != nil {
+			listener.Close()
+			return err
+		}
+	}
 	
 	// Enable reflection for grpcurl compatibility
 	reflection.Register(n.server)
@@ -140,17 +382,13 @@ func (n *Node) Start() error {
 			log.Printf("gRPC server error: %v", err)
 		}
 	}()
-	
-	// Start maintenance routines
-	n.startMaintenance()
-	
-	log.Printf("Node %s listening on %s, advertising %s", n.id.String()[:8], bindAddr, n.address)
 	return nil
 }
 
 // Stop stops the Chord node
 func (n *Node) Stop() {
 	n.cancel()
+	n.healthServer.Shutdown()
 	
 	if n.server != nil {
 		n.server.GracefulStop()
@@ -159,9 +397,18 @@ func (n *Node) Stop() {
 	if n.listener != nil {
 		n.listener.Close()
 	}
+	if n.quicListener != nil {
+		n.quicListener.Close()
+	}
+	
+	if udp := n.transport.udp.Load(); udp != nil {
+		udp.close()
+	}
+	n.nat.close()
 	
 	n.wg.Wait()
-	log.Printf("Node %s stopped", n.id.String()[:8])
+	n.recordAudit(AuditEvent{Kind: AuditLeave, Role: RoleSelf, Peer: auditPeer(n.GetNodeInfo())})
+	log.Printf("Node %s stopped", n.id.Short())
 }
 
 // Join joins the Chord ring via a bootstrap node
@@ -170,76 +417,137 @@ func (n *Node) Join(bootstrapAddr string) error {
 		// This is the first node, create ring
 		n.mu.Lock()
 		defer n.mu.Unlock()
-		selfInfo := &NodeInfo{ID: n.id, Address: n.address}
+		selfInfo := n.GetNodeInfo()
 		n.successor = selfInfo
 		n.predecessor = nil
-		log.Printf("Node %s created ring", n.id.String()[:8])
+		n.ownAll()
+		n.setServing(true)
+		n.recordMembership(MemberJoined, RoleSelf, selfInfo, nil)
+		log.Printf("Node %s created ring", n.id.Short())
 		return nil
 	}
 	
-	// Join existing ring
-	client, err := n.getClient(bootstrapAddr)
+	bootstrapAddr, err := NormalizeAddress(bootstrapAddr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to bootstrap node: %w", err)
+		return fmt.Errorf("invalid bootstrap address: %w", err)
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
-	defer cancel()
-	
-	// Find our successor
-	resp, err := client.FindSuccessor(ctx, &pb.FindSuccessorRequest{
-		Key: n.id.String(),
-		Requester: &pb.Node{
-			Id:      n.id.String(),
-			Address: n.address,
-		},
-	})
+	// A node its peers cannot call back would break their stabilization;
+	// refuse to join rather than fail silently (see nat.go)
+	if err := n.checkJoinReachability(bootstrapAddr); err != nil {
+		return err
+	}
 	
+	// Join existing ring, find our successor
+	successor, err := n.askSuccessor(bootstrapAddr)
 	if err != nil {
-		return fmt.Errorf("failed to find successor: %w", err)
+		return err
 	}
 	
-	if !resp.Success {
-		return fmt.Errorf("join failed: %s", resp.Error)
+	// A node with our ID would share every key range with us; refuse to
+	// join rather than misroute silently (see idcollision.go)
+	if err := n.idCollision(successor); err != nil {
+		// Let the joins the bootstrap holds behind ours proceed
+		n.remoteJoinSettled(bootstrapAddr)
+		return err
 	}
-	
-	n.mu.Lock()
-	defer n.mu.Unlock()
-	
-	successorID, err := hash.NewHashFromHex(resp.Successor.Id)
+	return n.joinSuccessor(bootstrapAddr, successor)
+}
+
+// askSuccessor asks the node at address for this node's successor
+func (n *Node) askSuccessor(address string) (*NodeInfo, error) {
+	resp, err := n.findJoinSuccessor(address)
 	if err != nil {
-		return fmt.Errorf("invalid successor ID: %w", err)
+		return nil, err
 	}
 	
-	n.successor = &NodeInfo{
-		ID:      successorID,
-		Address: resp.Successor.Address,
+	if !resp.Success {
+		return nil, fmt.Errorf("join failed: %s", resp.Error)
 	}
 	
+	successor, err := fromProtoNode(resp.Successor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid successor: %w", err)
+	}
+	return successor, nil
+}
+
+// joinSuccessor enters the ring in front of successor, as told by the
+// bootstrap at bootstrapAddr, and takes over our key range from it
+func (n *Node) joinSuccessor(bootstrapAddr string, successor *NodeInfo) error {
+	n.mu.Lock()
+	n.successor = successor
+	n.successorList = []*NodeInfo{successor}
 	// Initialize predecessor as nil (will be set by stabilization)
 	n.predecessor = nil
+	n.mu.Unlock()
+	n.recordMembership(MemberJoined, RoleSelf, n.GetNodeInfo(), nil)
+	n.recordMembership(MemberJoined, RoleSuccessor, successor, nil)
 
 	log.Printf("Node %s joined ring, successor: %s", 
-		n.id.String()[:8], n.successor.ID.String()[:8])
+		n.id.Short(), successor.ID.Short())
 	
-	// Notify successor about us immediately after join
-	if err := n.remoteNotify(n.successor.Address); err != nil {
-		log.Printf("Node %s: failed to notify successor after join: %v", n.id.String()[:8], err)
-	}
+	// Notify successor about us immediately after join and take over our
+	// range from it, moving in front of nodes that joined next to us
+	// meanwhile (see admission.go). The lock must not be held here:
+	// getClient acquires it.
+	n.settleJoin(bootstrapAddr, successor)
 	
+	n.setServing(true)
 	return nil
 }
 
 // findSuccessor finds the successor of a given key
-func (n *Node) findSuccessor(key *hash.Hash) (*NodeInfo, error) {
-	n.LookupCount++
+func (n *Node) findSuccessor(ctx context.Context, key *hash.Hash) (*NodeInfo, error) {
+	successor, _, err := n.lookup(ctx, key)
+	return successor, err
+}
+
+// lookup finds the successor of key and counts the RPC hops it took, zero
+// if this node knew the answer. Failed routes are retried with backoff
+// until ctx is done.
+func (n *Node) lookup(ctx context.Context, key *hash.Hash) (*NodeInfo, int, error) {
+	start := time.Now()
+	var (
+		owner *NodeInfo
+		hops  int
+	)
+	err := n.RetryPolicies().Lookup.Do(ctx, func(int) error {
+		var err error
+		owner, hops, err = n.route(ctx, key)
+		return err
+	})
 	
 	n.mu.RLock()
+	observer := n.lookupObserver
+	n.mu.RUnlock()
+	latency := time.Since(start)
+	if observer != nil {
+		observer(key, hops, latency, err)
+	}
+	n.publishLookup(key, hops, latency, err)
+	n.instruments.Load().recordLookup(hops, latency, err)
+	return owner, hops, err
+}
+
+// route resolves key with the local routing state, asking the closest
+// preceding finger when this node does not know the answer, or the next
+// best preceding nodes if it is unreachable
+func (n *Node) route(ctx context.Context, key *hash.Hash) (*NodeInfo, int, error) {
+	n.LookupCount.Add(1)
+	
+	n.mu.RLock()
+	// Keys between our predecessor and us belong to us
+	if n.predecessor != nil && key.InRange(n.predecessor.ID, n.id) {
+		n.mu.RUnlock()
+		return n.GetNodeInfo(), 0, nil
+	}
+	
 	// Check if key is between us and our successor
 	if n.successor != nil && key.InRange(n.id, n.successor.ID) {
 		successor := n.successor
 		n.mu.RUnlock()
-		return successor, nil
+		return successor, 0, nil
 	}
 	n.mu.RUnlock()
 	
@@ -251,33 +559,123 @@ func (n *Node) findSuccessor(key *hash.Hash) (*NodeInfo, error) {
 		n.mu.RLock()
 		successor := n.successor
 		n.mu.RUnlock()
-		return successor, nil
+		if successor == nil {
+			return nil, 0, ErrRingUnstable
+		}
+		return successor, 0, nil
 	}
 	
 	// Ask the closest preceding finger
-	return n.remoteFindSuccessor(preceding.Address, key)
+	resp, err := n.forwardLookup(ctx, key, preceding, &pb.FindSuccessorRequest{
+		Key: key.String(),
+		Requester: toProtoNode(n.GetNodeInfo()),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	successor, err := fromProtoNode(resp.Successor)
+	if err != nil {
+		return nil, 0, err
+	}
+	n.learnHotCopies(key, resp)
+	return successor, int(resp.Hops) + 1, nil
+}
+
+// Lookup resolves the node responsible for key
+func (n *Node) Lookup(key *hash.Hash) (*NodeInfo, error) {
+	return n.findSuccessor(n.ctx, key)
+}
+
+// LookupHops resolves the node responsible for key through the ring's
+// routing and returns how many RPC hops the lookup took
+func (n *Node) LookupHops(key *hash.Hash) (*NodeInfo, int, error) {
+	return n.lookup(n.ctx, key)
+}
+
+// LookupObserver is called after every lookup the node starts, with the
+// number of RPC hops and the time it took
+type LookupObserver func(key *hash.Hash, hops int, latency time.Duration, err error)
+
+// ObserveLookups sets a function called after every lookup this node
+// starts, for its own requests and for ring maintenance. It must not block.
+func (n *Node) ObserveLookups(observer LookupObserver) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	
+	n.lookupObserver = observer
+}
+
+// NextHop returns the node a lookup for key is forwarded to from here: the
+// successor if the key lies between us and it, otherwise the closest
+// preceding finger. It returns this node when the key is ours.
+func (n *Node) NextHop(key *hash.Hash) *NodeInfo {
+	n.mu.RLock()
+	self := n.GetNodeInfo()
+	predecessor := n.predecessor
+	successor := n.successor
+	n.mu.RUnlock()
+	
+	if predecessor != nil && key.InRange(predecessor.ID, n.id) {
+		return self
+	}
+	if successor == nil || successor.Address == n.address {
+		return self
+	}
+	if key.InRange(n.id, successor.ID) {
+		return successor
+	}
+	if hop := n.closestPrecedingFinger(key); hop.Address != n.address {
+		return hop
+	}
+	return successor
 }
 
 // closestPrecedingFinger finds the closest preceding finger for a key
 func (n *Node) closestPrecedingFinger(key *hash.Hash) *NodeInfo {
 	n.mu.RLock()
-	var candidate *NodeInfo
+	var candidate, alternate *NodeInfo
 
 	//tomamos el primer candidato mas cercano
 	for i := FingerTableSize - 1; i >= 0; i-- {
 		finger := n.fingers[i]
-		if finger != nil && finger.ID.InRangeExclusive(n.id, key) {
+		if finger == nil || !finger.ID.InRangeExclusive(n.id, key) || n.blacklisted(finger.Address) {
+			continue
+		}
+		if candidate == nil {
 			candidate = finger
+		} else if finger.Address != candidate.Address {
+			alternate = finger
 			break
 		}
+	}
+	// The successor is a valid finger even before fixFingers has run
+	if candidate == nil && n.successor != nil && n.successor.ID.InRangeExclusive(n.id, key) {
+		candidate = n.successor
+	}
 	n.mu.RUnlock()
 
-	if candidate != nil {
-		if n.remotePing(candidate.Address) == nil {
-			return candidate
+	// Both candidates precede the key; prefer the one under less pressure,
+	// then the one in our zone
+	if alternate != nil && n.preferHop(alternate, candidate) {
+		candidate, alternate = alternate, candidate
+	}
+	for _, node := range []*NodeInfo{candidate, alternate} {
+		if node != nil && n.peerAlive(node.Address) {
+			return node
 		}
 	}
-	return &NodeInfo{ID: n.id, Address: n.address}
+	return n.GetNodeInfo()
+}
+
+// preferHop reports whether a lookup should go to alternate rather than to
+// candidate, which is closer to the key
+func (n *Node) preferHop(alternate, candidate *NodeInfo) bool {
+	alternatePressure, candidatePressure := n.PeerPressure(alternate.Address), n.PeerPressure(candidate.Address)
+	if alternatePressure != candidatePressure {
+		return alternatePressure < candidatePressure
+	}
+	zone := n.Metadata().Zone
+	return alternate.sameZone(zone) && !candidate.sameZone(zone)
 }
 
 // notify is called when a node thinks it might be our predecessor
@@ -287,13 +685,19 @@ func (n *Node) notify(node *NodeInfo) {
 	
 	// If we have no predecessor, or the new node is between our predecessor and us
 	if n.predecessor == nil || node.ID.InRangeExclusive(n.predecessor.ID, n.id) {
+		n.recordMembership(MemberJoined, RolePredecessor, node, n.predecessor)
 		n.predecessor = node
-		log.Printf("Node %s: new predecessor %s", n.id.String()[:8], node.ID.String()[:8])
+		n.adoptPredecessor(node)
+		log.Printf("Node %s: new predecessor %s", n.id.Short(), node.ID.Short())
 	}
 }
 
 // stabilize is called periodically to verify and update successor and predecessor
 func (n *Node) stabilize() {
+	if n.Static() {
+		return
+	}
+	
 	n.mu.RLock()
 	successor := n.successor
 	n.mu.RUnlock()
@@ -303,60 +707,67 @@ func (n *Node) stabilize() {
 	}
 	
 	// Get predecessor of our successor
-	client, err := n.getClient(successor.Address)
-	if err != nil {
-		log.Printf("Node %s: failed to connect to successor %s: %v", 
-			n.id.String()[:8], successor.Address, err)
-		return
-	}
-	
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
 	
-	resp, err := client.GetInfo(ctx, &pb.GetInfoRequest{})
+	resp := &pb.GetInfoResponse{}
+	err := n.invokeMaintenance(ctx, successor.Address, pb.ChordService_GetInfo_FullMethodName, &pb.GetInfoRequest{}, resp)
+	n.observeContact(successor.Address, fromStatus(successor.Address, err))
 	if err != nil {
-		log.Printf("Node %s: failed to get info from successor: %v", n.id.String()[:8], err)
+		log.Printf("Node %s: failed to get info from successor: %v", n.id.Short(), err)
+		n.replaceFailedSuccessor(successor)
+		n.checkIsolation()
 		return
 	}
 	
 	if !resp.Success {
 		return
 	}
+	n.recordPeerPressure(successor.Address, resp.Pressure)
 	
 	// If successor has a predecessor, check if we should update our successor
 	if resp.Predecessor != nil {
-		predID, err := hash.NewHashFromHex(resp.Predecessor.Id)
+		pred, err := fromProtoNode(resp.Predecessor)
 		if err != nil {
-			log.Printf("Node %s: invalid predecessor ID from successor: %v", n.id.String()[:8], err)
+			n.reportMalformed(successor.Address, err)
 			return
 		}
 		
-		// If successor's predecessor is between us and our successor, update successor
-		if predID.InRangeExclusive(n.id, successor.ID) {
+		// If successor's predecessor is between us and our successor, update successor.
+		// A node whose successor is itself adopts any other node it learns about.
+		if pred.ID.InRangeExclusive(n.id, successor.ID) ||
+			(successor.ID.Equal(n.id) && !pred.ID.Equal(n.id)) {
 			n.mu.Lock()
-			n.successor = &NodeInfo{
-				ID:      predID,
-				Address: resp.Predecessor.Address,
-			}
+			n.successor = pred
+			n.recordMembership(MemberJoined, RoleSuccessor, n.successor, successor)
 			n.mu.Unlock()
 		}
 	}
 	
 	// Notify our successor about us
-	n.remoteNotify(n.successor.Address)
+	n.remoteNotify(n.GetSuccessor().Address)
+	
+	n.refreshSuccessorList()
+	n.watchReplicas()
+	n.maintainOwnership()
 }
 
 // fixFingers is called periodically to update finger table entries
 func (n *Node) fixFingers() {
+	// Refreshing fingers is optional work; stabilize keeps lookups correct
+	if n.shedding(PressureCritical) || n.Static() {
+		return
+	}
+	
 	n.mu.Lock()
 	n.next = (n.next + 1) % FingerTableSize
 	fingerStart := hash.FingerStart(n.id, n.next+1)
 	n.mu.Unlock()
 	
 	// Find successor of finger start
-	successor, err := n.findSuccessor(fingerStart)
+	successor, err := n.findSuccessor(n.ctx, fingerStart)
 	if err != nil {
-		log.Printf("Node %s: failed to fix finger %d: %v", n.id.String()[:8], n.next, err)
+		log.Printf("Node %s: failed to fix finger %d: %v", n.id.Short(), n.next, err)
 		return
 	}
 	
@@ -367,6 +778,10 @@ func (n *Node) fixFingers() {
 
 // checkPredecessor is called periodically to check if predecessor is alive
 func (n *Node) checkPredecessor() {
+	if n.Static() {
+		return
+	}
+	
 	n.mu.RLock()
 	predecessor := n.predecessor
 	n.mu.RUnlock()
@@ -375,52 +790,70 @@ func (n *Node) checkPredecessor() {
 		return
 	}
 	
-	// Ping predecessor
-	if n.remotePing(predecessor.Address) != nil {
+	// Ping predecessor, unless another contact just told whether it is up
+	if !n.peerAlive(predecessor.Address) {
 		n.mu.Lock()
 		n.predecessor = nil
 		n.mu.Unlock()
+		n.recordMembership(MemberLeft, RolePredecessor, predecessor, nil)
+		n.predecessorFailed(predecessor)
 		log.Printf("Node %s: predecessor %s failed, cleared", 
-			n.id.String()[:8], predecessor.ID.String()[:8])
+			n.id.Short(), predecessor.ID.Short())
 	}
 }
 
 // startMaintenance starts the periodic maintenance routines
 func (n *Node) startMaintenance() {
-	// Stabilization
+	// Stabilization, at a pace adapting to churn (see stabilization.go)
 	n.wg.Add(1)
 	go func() {
 		defer n.wg.Done()
-		ticker := time.NewTicker(StabilizeInterval)
-		defer ticker.Stop()
+		timer := time.NewTimer(n.StabilizationStatus().Interval)
+		defer timer.Stop()
 		
 		for {
 			select {
 			case <-n.ctx.Done():
 				return
-			case <-ticker.C:
+			case <-timer.C:
 				n.stabilize()
+				timer.Reset(n.stabilized())
 			}
 		}
 	}()
 	
-	// Fix fingers
+	// Fix fingers, sped up with stabilization
 	n.wg.Add(1)
 	go func() {
 		defer n.wg.Done()
-		ticker := time.NewTicker(FixFingersInterval)
-		defer ticker.Stop()
+		timer := time.NewTimer(n.StabilizationStatus().FixFingersInterval)
+		defer timer.Stop()
 		
 		for {
 			select {
 			case <-n.ctx.Done():
 				return
-			case <-ticker.C:
+			case <-n.stabilization.changed:
+				timer.Reset(n.StabilizationStatus().FixFingersInterval)
+			case <-timer.C:
 				n.fixFingers()
+				timer.Reset(n.fingersFixed())
 			}
 		}
 	}()
 	
+	// Check the finger table, if enabled with SetFingerCheck
+	n.wg.Add(1)
+	go n.checkFingersLoop()
+	
+	// Copy hot keys, if enabled with SetHotKeys
+	n.wg.Add(1)
+	go n.copyHotKeysLoop()
+	
+	// Reap tombstones past the horizon set with SetTombstones
+	n.wg.Add(1)
+	go n.reapTombstonesLoop()
+	
 	// Check predecessor
 	n.wg.Add(1)
 	go func() {
@@ -437,6 +870,23 @@ func (n *Node) startMaintenance() {
 			}
 		}
 	}()
+	
+	// Sample resource usage
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(PressureInterval)
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-ticker.C:
+				n.samplePressure()
+			}
+		}
+	}()
 }
 
 // GetID returns the node's ID
@@ -449,6 +899,18 @@ func (n *Node) GetAddress() string {
 	return n.address
 }
 
+// GetNodeInfo returns the NodeInfo describing this node
+func (n *Node) GetNodeInfo() *NodeInfo {
+	meta := n.Metadata()
+	return &NodeInfo{
+		ID:      n.id,
+		Address: n.address,
+		Zone:    meta.Zone,
+		Weight:  meta.Weight,
+		Version: n.versions.Max,
+	}
+}
+
 // GetSuccessor returns the node's successor
 func (n *Node) GetSuccessor() *NodeInfo {
 	n.mu.RLock()
@@ -473,86 +935,138 @@ func (n *Node) GetFingers() []*NodeInfo {
 	return fingers
 }
 
-// GetStats returns basic statistics about the node
+// GetStats returns the number of messages and lookups served by the node;
+// Stats returns the rest of its counters
 func (n *Node) GetStats() (int64, int64) {
-	return n.MessageCount, n.LookupCount
+	return n.MessageCount.Load(), n.LookupCount.Load()
 }
 
 // FindSuccessor finds the successor of the given ID
 func (n *Node) FindSuccessor(ctx context.Context, req *pb.FindSuccessorRequest) (*pb.FindSuccessorResponse, error) {
-	n.mu.RLock()
-	defer n.mu.RUnlock()
+	n.countMessage()
+	n.LookupCount.Add(1)
 	
-	n.MessageCount++
-	n.LookupCount++
+	// Snapshot state under the lock; it must not be held while forwarding
+	n.mu.RLock()
+	successor := n.successor
+	predecessor := n.predecessor
+	n.mu.RUnlock()
 	
 	targetID, err := hash.NewHashFromHex(req.Key)
 	if err != nil {
-		return &pb.FindSuccessorResponse{
-			Success: false,
-			Error:   "invalid key format",
-		}, nil
+		return nil, status.Error(codes.InvalidArgument, "invalid key format")
+	}
+	
+	if successor == nil {
+		return nil, toStatus(fmt.Errorf("%w: node has not joined a ring", ErrRingUnstable))
 	}
 	
-	// If target is between us and our successor, return successor
-	if n.successor != nil && targetID.InRange(n.id, n.successor.ID) {
+	// Joining nodes are admitted at the configured rate and, if they ask,
+	// one at a time (see admission.go)
+	if req.Join {
+		started := time.Now()
+		if err := n.joins.admit(ctx); err != nil {
+			return nil, toStatus(err)
+		}
+		if req.Serialize && req.Requester != nil {
+			wait := joinQueueTimeout - time.Since(started)
+			if err := n.joins.serialize(ctx, req.Requester.Address, wait); err != nil {
+				return nil, toStatus(err)
+			}
+		}
+	}
+	
+	// Keys between our predecessor and us belong to us
+	if predecessor != nil && targetID.InRange(predecessor.ID, n.id) {
 		return &pb.FindSuccessorResponse{
-			Successor: &pb.Node{
-				Id:      n.successor.ID.String(),
-				Address: n.successor.Address,
-			},
+			Successor: toProtoNode(n.GetNodeInfo()),
 			Success: true,
 		}, nil
 	}
 	
+	// If target is between us and our successor, return successor; the
+	// answer reports a hot copy of the key held here (see hotkeys.go)
+	if targetID.InRange(n.id, successor.ID) {
+		return n.withHotCopy(targetID, &pb.FindSuccessorResponse{
+			Successor: toProtoNode(successor),
+			Success: true,
+		}), nil
+	}
+	
 	// Find closest preceding node and ask it
 	precedingNode := n.closestPrecedingFinger(targetID)
 	if precedingNode.Address == n.address {
 		// We are the closest, return our successor
-		return &pb.FindSuccessorResponse{
-			Successor: &pb.Node{
-				Id:      n.successor.ID.String(),
-				Address: n.successor.Address,
-			},
+		return n.withHotCopy(targetID, &pb.FindSuccessorResponse{
+			Successor: toProtoNode(successor),
 			Success: true,
-		}, nil
+		}), nil
 	}
 	
-	// Forward request to closest preceding node
-	client, err := n.getClient(precedingNode.Address)
+	// Forward request to closest preceding node, within the limit on
+	// lookups in flight (see lookuplimit.go); only the bootstrap node
+	// applies its join limit
+	release, err := n.acquireLookup(ctx)
 	if err != nil {
-		return &pb.FindSuccessorResponse{
-			Success: false,
-			Error:   fmt.Sprintf("failed to connect to node: %v", err),
-		}, nil
+		return nil, toStatus(err)
 	}
-	
-	return client.FindSuccessor(ctx, req)
+	defer release()
+	resp, err := n.forwardLookup(ctx, targetID, precedingNode, &pb.FindSuccessorRequest{Key: req.Key, Requester: req.Requester})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp.Hops++
+	return n.withHotCopy(targetID, resp), nil
 }
 
 // Notify is called by another node that thinks it might be our predecessor
 func (n *Node) Notify(ctx context.Context, req *pb.NotifyRequest) (*pb.NotifyResponse, error) {
+	// Gossip rides on the notification both ways (see gossip.go). It is
+	// exchanged before taking the lock: describing this node reads the store.
+	n.mergeGossip(req.Node.GetAddress(), req.Gossip)
+	gossip := n.outgoingGossip()
+	
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	
-	n.MessageCount++
+	n.countMessage()
 	
-	notifierID, err := hash.NewHashFromHex(req.Node.Id)
+	notifier, err := fromProtoNode(req.Node)
 	if err != nil {
-		return &pb.NotifyResponse{Success: false, Error: "invalid node ID"}, nil
+		return nil, status.Error(codes.InvalidArgument, "invalid node ID")
+	}
+	
+	// A node alone in the ring notifies itself; that is not a predecessor
+	if notifier.ID.Equal(n.id) {
+		return &pb.NotifyResponse{Success: true, Gossip: gossip}, nil
+	}
+	
+	// Our predecessor notifies us on every stabilization; keep what it
+	// advertises about itself current
+	if n.predecessor != nil && n.predecessor.ID.Equal(notifier.ID) &&
+		n.predecessor.Address == notifier.Address {
+		n.predecessor = notifier
+		return &pb.NotifyResponse{Success: true, Predecessor: req.Node, Gossip: gossip}, nil
 	}
 	
 	// If we don't have a predecessor or the notifier is between our predecessor and us
-	if n.predecessor == nil || notifierID.InRangeExclusive(n.predecessor.ID, n.id) {
-		n.predecessor = &NodeInfo{
-			ID:      notifierID,
-			Address: req.Node.Address,
-		}
+	resp := &pb.NotifyResponse{Success: true, Gossip: gossip}
+	if n.predecessor == nil || notifier.ID.InRangeExclusive(n.predecessor.ID, n.id) {
+		previous := n.predecessor
+		n.predecessor = notifier
+		n.recordMembership(MemberJoined, RolePredecessor, n.predecessor, previous)
+		n.adoptPredecessor(n.predecessor)
 		log.Printf("Node %s updated predecessor to %s", 
-			n.id.String()[:8], n.predecessor.ID.String()[:8])
+			n.id.Short(), n.predecessor.ID.Short())
+		if previous != nil {
+			resp.Replaced = toProtoNode(previous)
+		}
 	}
 	
-	return &pb.NotifyResponse{Success: true}, nil
+	// A joining notifier learns whether a closer node beat it to us, or
+	// else the predecessor it comes after
+	resp.Predecessor = toProtoNode(n.predecessor)
+	return resp, nil
 }
 
 // GetInfo returns information about this node
@@ -560,37 +1074,26 @@ func (n *Node) GetInfo(ctx context.Context, req *pb.GetInfoRequest) (*pb.GetInfo
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 	
-	n.MessageCount++
+	n.countMessage()
 	
 	response := &pb.GetInfoResponse{
-		Node: &pb.Node{
-			Id:      n.id.String(),
-			Address: n.address,
-		},
-		Success: true,
+		Node:     toProtoNode(n.GetNodeInfo()),
+		Success:  true,
+		Pressure: int32(n.Pressure().Level),
 	}
 	
 	if n.predecessor != nil {
-		response.Predecessor = &pb.Node{
-			Id:      n.predecessor.ID.String(),
-			Address: n.predecessor.Address,
-		}
+		response.Predecessor = toProtoNode(n.predecessor)
 	}
 	
 	if n.successor != nil {
-		response.Successor = &pb.Node{
-			Id:      n.successor.ID.String(),
-			Address: n.successor.Address,
-		}
+		response.Successor = toProtoNode(n.successor)
 	}
 	
 	// Add finger table entries
 	for _, finger := range n.fingers {
 		if finger != nil {
-			response.Fingers = append(response.Fingers, &pb.Node{
-				Id:      finger.ID.String(),
-				Address: finger.Address,
-			})
+			response.Fingers = append(response.Fingers, toProtoNode(finger))
 		}
 	}
 	
@@ -602,42 +1105,31 @@ func (n *Node) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse,
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 	
-	n.MessageCount++
+	n.countMessage()
 	
 	return &pb.PingResponse{
 		Alive:     true,
 		Timestamp: time.Now().UnixNano(),
+		Pressure:  int32(n.Pressure().Level),
 	}, nil
 }
 
 // ClosestPrecedingFinger finds the closest preceding finger for a key
 func (n *Node) ClosestPrecedingFinger(ctx context.Context, req *pb.ClosestPrecedingFingerRequest) (*pb.ClosestPrecedingFingerResponse, error) {
-	n.mu.RLock()
-	defer n.mu.RUnlock()
-	
-	n.MessageCount++
+	n.countMessage()
 	
 	key, err := hash.NewHashFromHex(req.Key)
 	if err != nil {
-		return &pb.ClosestPrecedingFingerResponse{
-			Success: false,
-			Error:   "invalid key format",
-		}, nil
+		return nil, status.Error(codes.InvalidArgument, "invalid key format")
 	}
 	
 	closest := n.closestPrecedingFinger(key)
 	if closest == nil {
-		closest = &NodeInfo{
-			ID:      n.id,
-			Address: n.address,
-		}
+		closest = n.GetNodeInfo()
 	}
 	
 	return &pb.ClosestPrecedingFingerResponse{
-		Node: &pb.Node{
-			Id:      closest.ID.String(),
-			Address: closest.Address,
-		},
+		Node:    toProtoNode(closest),
 		Success: true,
 	}, nil
 }
@@ -652,17 +1144,15 @@ func (n *Node) getClient(address string) (pb.ChordServiceClient, error) {
 		return client, nil
 	}
 	
-	// Create new connection
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := n.ClientConn(address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		return nil, err
 	}
 	
 	client = pb.NewChordServiceClient(conn)
 	
 	n.mu.Lock()
 	n.clients[address] = client
-	n.connections[address] = conn
 	n.mu.Unlock()
 	
 	return client, nil
@@ -671,73 +1161,45 @@ func (n *Node) getClient(address string) (pb.ChordServiceClient, error) {
 // MustEmbedUnimplementedChordServiceServer is required by the generated gRPC code
 func (n *Node) mustEmbedUnimplementedChordServiceServer() {}
 
-// remoteFindSuccessor calls FindSuccessor on a remote node
-func (n *Node) remoteFindSuccessor(address string, key *hash.Hash) (*NodeInfo, error) {
-	client, err := n.getClient(address)
-	if err != nil {
-		return nil, err
-	}
-	
-	req := &pb.FindSuccessorRequest{
-		Key: key.String(),
-		Requester: &pb.Node{
-			Id:      n.id.String(),
-			Address: n.address,
-		},
-	}
-	
-	resp, err := client.FindSuccessor(context.Background(), req)
-	if err != nil {
-		return nil, err
+// remotePing calls Ping on a remote node
+func (n *Node) remotePing(ctx context.Context, address string) error {
+	req := &pb.PingRequest{
+		Requester: toProtoNode(n.GetNodeInfo()),
 	}
 	
-	if !resp.Success {
-		return nil, fmt.Errorf("remote error: %s", resp.Error)
-	}
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
 	
-	successorID, err := hash.NewHashFromHex(resp.Successor.Id)
-	if err != nil {
-		return nil, err
+	resp := &pb.PingResponse{}
+	if err := n.invokeMaintenance(ctx, address, pb.ChordService_Ping_FullMethodName, req, resp); err != nil {
+		return fromStatus(address, err)
 	}
-	
-	return &NodeInfo{
-		ID:      successorID,
-		Address: resp.Successor.Address,
-	}, nil
+	n.recordPeerPressure(address, resp.Pressure)
+	return nil
 }
 
-// remotePing calls Ping on a remote node
-func (n *Node) remotePing(address string) error {
-	client, err := n.getClient(address)
-	if err != nil {
-		return err
+// remoteNotify calls Notify on a remote node and returns the predecessor it
+// reports afterwards and the one we replaced, nil if none
+func (n *Node) remoteNotify(address string) (predecessor, replaced *NodeInfo, err error) {
+	req := &pb.NotifyRequest{
+		Node:   toProtoNode(n.GetNodeInfo()),
+		Gossip: n.outgoingGossip(),
 	}
 	
-	req := &pb.PingRequest{
-		Requester: &pb.Node{
-			Id:      n.id.String(),
-			Address: n.address,
-		},
+	resp := &pb.NotifyResponse{}
+	if err := n.invokeMaintenance(context.Background(), address, pb.ChordService_Notify_FullMethodName, req, resp); err != nil {
+		return nil, nil, err
 	}
-	
-	_, err = client.Ping(context.Background(), req)
-	return err
-}
-
-// remoteNotify calls Notify on a remote node
-func (n *Node) remoteNotify(address string) error {
-	client, err := n.getClient(address)
-	if err != nil {
-		return err
+	n.mergeGossip(address, resp.Gossip)
+	if resp.Predecessor != nil {
+		if predecessor, err = fromProtoNode(resp.Predecessor); err != nil {
+			return nil, nil, err
+		}
 	}
-	
-	req := &pb.NotifyRequest{
-		Node: &pb.Node{
-			Id:      n.id.String(),
-			Address: n.address,
-		},
+	if resp.Replaced != nil {
+		if replaced, err = fromProtoNode(resp.Replaced); err != nil {
+			return nil, nil, err
+		}
 	}
-	
-	_, err = client.Notify(context.Background(), req)
-	return err
+	return predecessor, replaced, nil
 }
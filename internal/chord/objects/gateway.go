@@ -0,0 +1,162 @@
+package objects
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// readTimeout bounds reading the headers of a gateway request; bodies are
+// streamed for as long as they take
+const readTimeout = 10 * time.Second
+
+// Handler serves the store over HTTP, in the style of S3:
+//
+//	PUT    /buckets/{bucket}/{object}                       store an object
+//	GET    /buckets/{bucket}/{object}                       read an object (HEAD for its headers)
+//	DELETE /buckets/{bucket}/{object}                       delete an object
+//	POST   /buckets/{bucket}/{object}?uploads               start a multipart upload
+//	PUT    /buckets/{bucket}/{object}?uploadId=&partNumber= upload a part
+//	POST   /buckets/{bucket}/{object}?uploadId=             complete an upload
+//	DELETE /buckets/{bucket}/{object}?uploadId=             abort an upload
+//
+// Bodies are streamed a chunk at a time in both directions.
+func (s *Store) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /buckets/{bucket}/{object...}", s.handlePut)
+	mux.HandleFunc("GET /buckets/{bucket}/{object...}", s.handleGet)
+	mux.HandleFunc("DELETE /buckets/{bucket}/{object...}", s.handleDelete)
+	mux.HandleFunc("POST /buckets/{bucket}/{object...}", s.handlePost)
+	return mux
+}
+
+// jsonUpload is the answer to starting an upload
+type jsonUpload struct {
+	UploadID string `json:"upload_id"`
+	Bucket   string `json:"bucket"`
+	Name     string `json:"name"`
+}
+
+// jsonObject is the answer to completing an upload
+type jsonObject struct {
+	Bucket string `json:"bucket"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+}
+
+func (s *Store) handlePut(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if id := query.Get("uploadId"); id != "" {
+		number, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			http.Error(w, "invalid partNumber", http.StatusBadRequest)
+			return
+		}
+		part, err := s.UploadPart(r.Context(), id, number, r.Body)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("ETag", strconv.Quote(part.ETag))
+		return
+	}
+
+	obj, err := s.Put(r.Context(), r.PathValue("bucket"), r.PathValue("object"), r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", strconv.Quote(obj.ETag))
+}
+
+func (s *Store) handleGet(w http.ResponseWriter, r *http.Request) {
+	obj, content, err := s.Get(r.Context(), r.PathValue("bucket"), r.PathValue("object"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	header.Set("ETag", strconv.Quote(obj.ETag))
+	header.Set("Last-Modified", obj.Modified.Format(http.TimeFormat))
+	if obj.ContentType != "" {
+		header.Set("Content-Type", obj.ContentType)
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	// Once streaming has started a failure can only cut the body short
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("objects: failed to send %s/%s: %v", obj.Bucket, obj.Name, err)
+	}
+}
+
+func (s *Store) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var err error
+	if id := r.URL.Query().Get("uploadId"); id != "" {
+		err = s.AbortUpload(r.Context(), id)
+	} else {
+		err = s.Delete(r.Context(), r.PathValue("bucket"), r.PathValue("object"))
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Store) handlePost(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case query.Has("uploads"):
+		upload, err := s.CreateUpload(r.Context(), r.PathValue("bucket"), r.PathValue("object"), r.Header.Get("Content-Type"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, jsonUpload{UploadID: upload.ID, Bucket: upload.Bucket, Name: upload.Name})
+	case query.Get("uploadId") != "":
+		obj, err := s.CompleteUpload(r.Context(), query.Get("uploadId"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("ETag", strconv.Quote(obj.ETag))
+		writeJSON(w, jsonObject{Bucket: obj.Bucket, Name: obj.Name, Size: obj.Size, ETag: obj.ETag})
+	default:
+		http.Error(w, "expected ?uploads or ?uploadId=", http.StatusBadRequest)
+	}
+}
+
+// writeJSON writes v as the JSON body of the response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("objects: failed to write response: %v", err)
+	}
+}
+
+// writeError answers with the status of err
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrNoSuchUpload):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidPart), errors.Is(err, ErrNoParts):
+		status = http.StatusBadRequest
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// NewServer returns an HTTP server of the gateway on addr
+func (s *Store) NewServer(addr string) *http.Server {
+	return &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: readTimeout}
+}
@@ -0,0 +1,512 @@
+// Package objects stores objects of any size in the Chord DHT as chunks
+// spread across the ring plus a manifest listing them, and serves them over
+// an S3-style HTTP API (see gateway.go). Objects are written and read as
+// streams, one chunk at a time, and large ones can be uploaded in parts.
+package objects
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+const (
+	// ChunkSize is the size of the chunks objects are split into, well
+	// under the gRPC message limit
+	ChunkSize = 1 << 20
+	// MaxPartNumber is the highest part number of a multipart upload
+	MaxPartNumber = 10000
+
+	// Key prefixes of manifests, chunks and multipart uploads in the DHT
+	manifestPrefix = "objects/manifest/"
+	chunkPrefix    = "objects/chunk/"
+	uploadPrefix   = "objects/upload/"
+	// maxSwapAttempts bounds the conditional writes replacing a key while
+	// other writers keep changing it
+	maxSwapAttempts = 8
+)
+
+var (
+	// ErrNotFound is returned for an object that does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrNoSuchUpload is returned for a multipart upload that does not
+	// exist, or was completed or aborted
+	ErrNoSuchUpload = errors.New("no such upload")
+	// ErrInvalidName is returned for an empty bucket or object name, or a
+	// bucket name containing a slash
+	ErrInvalidName = errors.New("invalid bucket or object name")
+	// ErrInvalidPart is returned for a part number out of range
+	ErrInvalidPart = errors.New("invalid part number")
+	// ErrNoParts is returned when completing an upload with no parts
+	ErrNoParts = errors.New("upload has no parts")
+	// errContended is returned when a key kept changing under a write
+	errContended = errors.New("modified concurrently")
+)
+
+// Object describes a stored object; it is the manifest kept in the DHT
+type Object struct {
+	Bucket      string    `json:"bucket"`
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ETag        string    `json:"etag"`
+	ContentType string    `json:"content_type,omitempty"`
+	Modified    time.Time `json:"modified"`
+	// Chunks holds the keys of the object's chunks in order
+	Chunks []string `json:"chunks"`
+}
+
+// Upload is a multipart upload in progress
+type Upload struct {
+	ID          string    `json:"id"`
+	Bucket      string    `json:"bucket"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type,omitempty"`
+	Created     time.Time `json:"created"`
+}
+
+// Part is an uploaded part of a multipart upload
+type Part struct {
+	Number int      `json:"number"`
+	Size   int64    `json:"size"`
+	ETag   string   `json:"etag"`
+	Chunks []string `json:"chunks"`
+}
+
+// Store stores objects through a Chord node
+type Store struct {
+	node      *chord.Node
+	chunkSize int
+}
+
+// New creates a store that issues requests through node
+func New(node *chord.Node) *Store {
+	return &Store{node: node, chunkSize: ChunkSize}
+}
+
+// Put stores the object read from r, replacing any object of the same name
+// once all of it is written. The chunks of the replaced object are deleted.
+func (s *Store) Put(ctx context.Context, bucket, name string, r io.Reader, contentType string) (*Object, error) {
+	if err := checkName(bucket, name); err != nil {
+		return nil, err
+	}
+	chunks, size, sum, err := s.writeChunks(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	obj := &Object{
+		Bucket:      bucket,
+		Name:        name,
+		Size:        size,
+		ETag:        hex.EncodeToString(sum),
+		ContentType: contentType,
+		Modified:    time.Now().UTC(),
+		Chunks:      chunks,
+	}
+	if err := s.commit(ctx, obj); err != nil {
+		s.deleteChunks(ctx, chunks)
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Head returns the manifest of an object
+func (s *Store) Head(ctx context.Context, bucket, name string) (*Object, error) {
+	if err := checkName(bucket, name); err != nil {
+		return nil, err
+	}
+	data, err := s.node.FetchValue(ctx, manifestKey(bucket, name))
+	if errors.Is(err, chord.ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, bucket, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var obj Object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s/%s: %w", bucket, name, err)
+	}
+	return &obj, nil
+}
+
+// Get returns the manifest of an object and a reader of its content, which
+// fetches one chunk at a time. Reading fails if the object is replaced or
+// deleted meanwhile.
+func (s *Store) Get(ctx context.Context, bucket, name string) (*Object, io.Reader, error) {
+	obj, err := s.Head(ctx, bucket, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, &objectReader{ctx: ctx, node: s.node, chunks: obj.Chunks}, nil
+}
+
+// Delete deletes an object and its chunks; deleting a missing object is not
+// an error
+func (s *Store) Delete(ctx context.Context, bucket, name string) error {
+	if err := checkName(bucket, name); err != nil {
+		return err
+	}
+	previous, err := s.swap(ctx, chord.ConditionalWrite{Key: manifestKey(bucket, name), Delete: true})
+	if err != nil {
+		return err
+	}
+	s.dropManifest(ctx, previous)
+	return nil
+}
+
+// CreateUpload starts a multipart upload of an object
+func (s *Store) CreateUpload(ctx context.Context, bucket, name, contentType string) (*Upload, error) {
+	if err := checkName(bucket, name); err != nil {
+		return nil, err
+	}
+	upload := &Upload{ID: newID(), Bucket: bucket, Name: name, ContentType: contentType, Created: time.Now().UTC()}
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.node.StoreValue(ctx, uploadKey(upload.ID), data); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// UploadPart stores part number of an upload from r, replacing a part
+// uploaded before with that number. Parts may be uploaded concurrently.
+func (s *Store) UploadPart(ctx context.Context, id string, number int, r io.Reader) (*Part, error) {
+	if number < 1 || number > MaxPartNumber {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidPart, number)
+	}
+	if _, err := s.upload(ctx, id); err != nil {
+		return nil, err
+	}
+	chunks, size, sum, err := s.writeChunks(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	part := &Part{Number: number, Size: size, ETag: hex.EncodeToString(sum), Chunks: chunks}
+	data, err := json.Marshal(part)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.swap(ctx, chord.ConditionalWrite{Key: partKey(id, number), Value: data})
+	if err == nil {
+		err = s.node.AddToSet(ctx, partsKey(id), strconv.Itoa(number))
+	}
+	if err != nil {
+		s.deleteChunks(ctx, chunks)
+		return nil, err
+	}
+	if previous != nil {
+		var replaced Part
+		if json.Unmarshal(previous, &replaced) == nil {
+			s.deleteChunks(ctx, replaced.Chunks)
+		}
+	}
+	return part, nil
+}
+
+// CompleteUpload assembles the uploaded parts, in order of part number, into
+// the object and ends the upload. The ETag of the object is that of S3: the
+// MD5 of the parts' MD5s followed by the number of parts.
+func (s *Store) CompleteUpload(ctx context.Context, id string) (*Object, error) {
+	upload, err := s.upload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := s.parts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoParts, id)
+	}
+
+	obj := &Object{
+		Bucket:      upload.Bucket,
+		Name:        upload.Name,
+		ContentType: upload.ContentType,
+		Modified:    time.Now().UTC(),
+	}
+	sums := md5.New()
+	for _, part := range parts {
+		sum, err := hex.DecodeString(part.ETag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ETag of part %d: %w", part.Number, err)
+		}
+		sums.Write(sum)
+		obj.Size += part.Size
+		obj.Chunks = append(obj.Chunks, part.Chunks...)
+	}
+	obj.ETag = fmt.Sprintf("%s-%d", hex.EncodeToString(sums.Sum(nil)), len(parts))
+
+	if err := s.commit(ctx, obj); err != nil {
+		return nil, err
+	}
+	// The chunks now belong to the object
+	s.dropUpload(ctx, id, parts, false)
+	return obj, nil
+}
+
+// AbortUpload ends an upload and deletes its parts
+func (s *Store) AbortUpload(ctx context.Context, id string) error {
+	if _, err := s.upload(ctx, id); err != nil {
+		return err
+	}
+	parts, err := s.parts(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.dropUpload(ctx, id, parts, true)
+	return nil
+}
+
+// upload returns an upload in progress
+func (s *Store) upload(ctx context.Context, id string) (*Upload, error) {
+	if id == "" || strings.Contains(id, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrNoSuchUpload, id)
+	}
+	data, err := s.node.FetchValue(ctx, uploadKey(id))
+	if errors.Is(err, chord.ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchUpload, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("invalid upload %s: %w", id, err)
+	}
+	return &upload, nil
+}
+
+// parts returns the uploaded parts of an upload, in order of part number
+func (s *Store) parts(ctx context.Context, id string) ([]*Part, error) {
+	members, err := s.node.ReadSet(ctx, partsKey(id))
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]int, 0, len(members))
+	for _, member := range members {
+		number, err := strconv.Atoi(member)
+		if err != nil {
+			return nil, fmt.Errorf("invalid part number %q of upload %s", member, id)
+		}
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+
+	keys := make([]string, len(numbers))
+	for i, number := range numbers {
+		keys[i] = partKey(id, number)
+	}
+	values, err := s.node.FetchBatch(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]*Part, 0, len(numbers))
+	for i, number := range numbers {
+		data, ok := values[keys[i]]
+		if !ok {
+			return nil, fmt.Errorf("part %d of upload %s is missing", number, id)
+		}
+		var part Part
+		if err := json.Unmarshal(data, &part); err != nil {
+			return nil, fmt.Errorf("invalid part %d of upload %s: %w", number, id, err)
+		}
+		parts = append(parts, &part)
+	}
+	return parts, nil
+}
+
+// dropUpload deletes the records of an upload, and the chunks of its parts
+// if chunks is set
+func (s *Store) dropUpload(ctx context.Context, id string, parts []*Part, chunks bool) {
+	keys := []string{uploadKey(id), partsKey(id)}
+	for _, part := range parts {
+		keys = append(keys, partKey(id, part.Number))
+		if chunks {
+			s.deleteChunks(ctx, part.Chunks)
+		}
+	}
+	for _, key := range keys {
+		if _, err := s.swap(ctx, chord.ConditionalWrite{Key: key, Delete: true}); err != nil {
+			log.Printf("objects: failed to delete %q of upload %s: %v", key, id, err)
+		}
+	}
+}
+
+// writeChunks stores the content of r as chunks under a new prefix and
+// returns their keys, the size and the MD5 of the content. On failure the
+// chunks written are deleted.
+func (s *Store) writeChunks(ctx context.Context, r io.Reader) ([]string, int64, []byte, error) {
+	prefix := chunkPrefix + newID() + "/"
+	sum := md5.New()
+	buf := make([]byte, s.chunkSize)
+
+	var (
+		chunks []string
+		size   int64
+	)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			key := prefix + strconv.Itoa(len(chunks))
+			if err := s.node.StoreValue(ctx, key, bytes.Clone(buf[:n])); err != nil {
+				s.deleteChunks(ctx, chunks)
+				return nil, 0, nil, fmt.Errorf("failed to store chunk %d: %w", len(chunks), err)
+			}
+			chunks = append(chunks, key)
+			sum.Write(buf[:n])
+			size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return chunks, size, sum.Sum(nil), nil
+		}
+		if err != nil {
+			s.deleteChunks(ctx, chunks)
+			return nil, 0, nil, fmt.Errorf("failed to read content: %w", err)
+		}
+	}
+}
+
+// commit writes the manifest of an object, replacing the object of the
+// same name and deleting its chunks
+func (s *Store) commit(ctx context.Context, obj *Object) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	previous, err := s.swap(ctx, chord.ConditionalWrite{Key: manifestKey(obj.Bucket, obj.Name), Value: data})
+	if err != nil {
+		return err
+	}
+	s.dropManifest(ctx, previous)
+	return nil
+}
+
+// dropManifest deletes the chunks of a replaced or deleted manifest, if any
+func (s *Store) dropManifest(ctx context.Context, manifest []byte) {
+	if manifest == nil {
+		return
+	}
+	var obj Object
+	if err := json.Unmarshal(manifest, &obj); err != nil {
+		log.Printf("objects: not deleting the chunks of an invalid manifest: %v", err)
+		return
+	}
+	s.deleteChunks(ctx, obj.Chunks)
+}
+
+// deleteChunks deletes chunks, logging failures: a chunk left behind only
+// takes space
+func (s *Store) deleteChunks(ctx context.Context, chunks []string) {
+	for _, key := range chunks {
+		if _, err := s.swap(ctx, chord.ConditionalWrite{Key: key, Delete: true}); err != nil {
+			log.Printf("objects: failed to delete chunk %q: %v", key, err)
+		}
+	}
+}
+
+// swap applies a write or delete whatever the key's current value, and
+// returns the value it replaced, nil if none. The DHT has no unconditional
+// delete, so the conditional write is retried with the value found until
+// it applies.
+func (s *Store) swap(ctx context.Context, write chord.ConditionalWrite) ([]byte, error) {
+	current, err := s.node.FetchValue(ctx, write.Key)
+	if err != nil && !errors.Is(err, chord.ErrKeyNotFound) {
+		return nil, err
+	}
+	write.Expected = current
+	for attempt := 0; attempt < maxSwapAttempts; attempt++ {
+		result, err := s.node.CompareAndSwap(ctx, write)
+		if err != nil {
+			return nil, err
+		}
+		if result.Applied {
+			return write.Expected, nil
+		}
+		write.Expected = result.Current
+	}
+	return nil, fmt.Errorf("%w: %q", errContended, write.Key)
+}
+
+// objectReader reads the chunks of an object in order
+type objectReader struct {
+	ctx    context.Context
+	node   *chord.Node
+	chunks []string
+	next   int
+	buf    []byte
+}
+
+// Read reads from the current chunk, fetching the next one once it is used
+// up
+func (r *objectReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next == len(r.chunks) {
+			return 0, io.EOF
+		}
+		chunk, err := r.node.FetchValue(r.ctx, r.chunks[r.next])
+		if errors.Is(err, chord.ErrKeyNotFound) {
+			return 0, fmt.Errorf("chunk %d is gone, the object changed while read: %w", r.next, err)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch chunk %d: %w", r.next, err)
+		}
+		r.buf = chunk
+		r.next++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// checkName checks a bucket and object name
+func checkName(bucket, name string) error {
+	if bucket == "" || name == "" || strings.Contains(bucket, "/") {
+		return fmt.Errorf("%w: %q/%q", ErrInvalidName, bucket, name)
+	}
+	return nil
+}
+
+// manifestKey returns the key of an object's manifest
+func manifestKey(bucket, name string) string {
+	return manifestPrefix + bucket + "/" + name
+}
+
+// uploadKey returns the key of an upload's record
+func uploadKey(id string) string {
+	return uploadPrefix + id
+}
+
+// partsKey returns the key of the set of an upload's part numbers
+func partsKey(id string) string {
+	return uploadPrefix + id + "/parts"
+}
+
+// partKey returns the key of an uploaded part's record
+func partKey(id string, number int) string {
+	return uploadPrefix + id + "/part/" + strconv.Itoa(number)
+}
+
+// newID returns a random identifier for a set of chunks or an upload
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("objects: failed to generate id: %v", err))
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,176 @@
+package objects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+// startStore starts a single node ring and a store with 4 byte chunks
+func startStore(t *testing.T, address string) (*chord.Node, *Store) {
+	node := chord.NewNode(address, nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	store := New(node)
+	store.chunkSize = 4
+	return node, store
+}
+
+// liveKeys returns the live keys stored on node that start with prefix
+func liveKeys(t *testing.T, node *chord.Node, prefix string) []string {
+	var keys []string
+	now := time.Now()
+	err := node.Storage().Range(func(key string, e chord.Entry) bool {
+		if strings.HasPrefix(key, prefix) && e.Live(now) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	return keys
+}
+
+func TestPutGet(t *testing.T) {
+	node, store := startStore(t, "localhost:8583")
+	ctx := context.Background()
+
+	obj, err := store.Put(ctx, "b", "dir/file.txt", strings.NewReader("hello world"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if obj.Size != 11 || len(obj.Chunks) != 3 {
+		t.Errorf("Expected 11 bytes in 3 chunks, got %d in %d", obj.Size, len(obj.Chunks))
+	}
+	if obj.ETag != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Errorf("Expected the MD5 of the content as ETag, got %s", obj.ETag)
+	}
+
+	got, content, err := store.Get(ctx, "b", "dir/file.txt")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, err := io.ReadAll(content)
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("Expected hello world, got %q, %v", data, err)
+	}
+	if got.ContentType != "text/plain" {
+		t.Errorf("Expected text/plain, got %q", got.ContentType)
+	}
+
+	// Replacing an object deletes its chunks
+	if _, err := store.Put(ctx, "b", "dir/file.txt", strings.NewReader("bye"), ""); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if chunks := liveKeys(t, node, chunkPrefix); len(chunks) != 1 {
+		t.Errorf("Expected 1 chunk after replacing, got %v", chunks)
+	}
+
+	if err := store.Delete(ctx, "b", "dir/file.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, _, err := store.Get(ctx, "b", "dir/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after deleting, got %v", err)
+	}
+	if chunks := liveKeys(t, node, chunkPrefix); len(chunks) != 0 {
+		t.Errorf("Expected no chunks after deleting, got %v", chunks)
+	}
+
+	if _, err := store.Put(ctx, "b/c", "x", strings.NewReader(""), ""); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+}
+
+func TestGateway(t *testing.T) {
+	node, store := startStore(t, "localhost:8584")
+	server := httptest.NewServer(store.Handler())
+	defer server.Close()
+	url := server.URL + "/buckets/photos/2024/cat.jpg"
+
+	do := func(method, url string, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest failed: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, url, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	if resp := do(http.MethodGet, url, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing object, got %d", resp.StatusCode)
+	}
+
+	// Multipart upload, parts sent out of order
+	resp := do(http.MethodPost, url+"?uploads", "")
+	var upload jsonUpload
+	if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil || upload.UploadID == "" {
+		t.Fatalf("Expected an upload, got %d, %v", resp.StatusCode, err)
+	}
+	for number, body := range map[string]string{"2": "second part", "1": "first part, ", "3": "!"} {
+		resp := do(http.MethodPut, url+"?uploadId="+upload.UploadID+"&partNumber="+number, body)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+			t.Fatalf("Expected part %s to be uploaded, got %d", number, resp.StatusCode)
+		}
+	}
+	// Uploading a part again replaces it
+	do(http.MethodPut, url+"?uploadId="+upload.UploadID+"&partNumber=3", "!!")
+
+	resp = do(http.MethodPost, url+"?uploadId="+upload.UploadID, "")
+	var obj jsonObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		t.Fatalf("Expected the completed object, got %d, %v", resp.StatusCode, err)
+	}
+	if obj.Size != 25 || !strings.HasSuffix(obj.ETag, "-3") {
+		t.Errorf("Unexpected completed object %+v", obj)
+	}
+
+	resp = do(http.MethodGet, url, "")
+	data, _ := io.ReadAll(resp.Body)
+	if string(data) != "first part, second part!!" {
+		t.Errorf("Unexpected content %q", data)
+	}
+	if resp.Header.Get("Content-Length") != "25" {
+		t.Errorf("Expected Content-Length 25, got %q", resp.Header.Get("Content-Length"))
+	}
+	resp = do(http.MethodHead, url, "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != `"`+obj.ETag+`"` {
+		t.Errorf("Unexpected HEAD answer %d %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	if resp := do(http.MethodPost, url+"?uploadId="+upload.UploadID, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 completing a completed upload, got %d", resp.StatusCode)
+	}
+
+	// An aborted upload leaves nothing behind
+	resp = do(http.MethodPost, url+"?uploads", "")
+	json.NewDecoder(resp.Body).Decode(&upload)
+	do(http.MethodPut, url+"?uploadId="+upload.UploadID+"&partNumber=1", "discarded")
+	if resp := do(http.MethodDelete, url+"?uploadId="+upload.UploadID, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 aborting, got %d", resp.StatusCode)
+	}
+
+	if resp := do(http.MethodDelete, url, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 deleting, got %d", resp.StatusCode)
+	}
+	if keys := liveKeys(t, node, "objects/"); len(keys) != 0 {
+		t.Errorf("Expected no object keys left, found %v", keys)
+	}
+}
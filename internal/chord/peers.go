@@ -0,0 +1,256 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPeerFingers is the number of sampled fingers returned when a
+// GetPeers request does not ask for a specific number
+const DefaultPeerFingers = 8
+
+// PeerSet is a node's view of its neighborhood in the ring
+type PeerSet struct {
+	Self        *NodeInfo
+	Predecessor *NodeInfo
+	// Successors is the successor list, closest first
+	Successors []*NodeInfo
+	// Fingers is a random sample of distinct finger table entries
+	Fingers []*NodeInfo
+}
+
+// Peers returns this node's known peers with at most maxFingers sampled
+// finger entries
+func (n *Node) Peers(maxFingers int) *PeerSet {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	peers := &PeerSet{
+		Self:        n.GetNodeInfo(),
+		Predecessor: n.predecessor,
+		Successors:  append([]*NodeInfo(nil), n.successorList...),
+	}
+
+	// Collect distinct fingers other than ourselves, then sample them
+	seen := make(map[string]bool)
+	var fingers []*NodeInfo
+	for _, finger := range n.fingers {
+		if finger == nil || finger.Address == n.address || seen[finger.Address] {
+			continue
+		}
+		seen[finger.Address] = true
+		fingers = append(fingers, finger)
+	}
+	rand.Shuffle(len(fingers), func(i, j int) {
+		fingers[i], fingers[j] = fingers[j], fingers[i]
+	})
+	if len(fingers) > maxFingers {
+		fingers = fingers[:maxFingers]
+	}
+	peers.Fingers = fingers
+
+	return peers
+}
+
+// RemotePeers asks the node at address for its known peers
+func (n *Node) RemotePeers(ctx context.Context, address string, maxFingers int) (*PeerSet, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetPeers(ctx, &pb.GetPeersRequest{
+		Requester:  toProtoNode(n.GetNodeInfo()),
+		MaxFingers: int32(maxFingers),
+	})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("get peers from %s failed: %s", address, resp.Error)
+	}
+
+	peers := &PeerSet{}
+	if peers.Self, err = fromProtoNode(resp.Node); err != nil {
+		return nil, err
+	}
+	if resp.Predecessor != nil {
+		if peers.Predecessor, err = fromProtoNode(resp.Predecessor); err != nil {
+			return nil, err
+		}
+	}
+	if peers.Successors, err = fromProtoNodes(resp.Successors); err != nil {
+		return nil, err
+	}
+	if peers.Fingers, err = fromProtoNodes(resp.Fingers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// GetPeers returns this node's successor list, predecessor and a sample of
+// its fingers so callers can expand their view of the ring
+func (n *Node) GetPeers(ctx context.Context, req *pb.GetPeersRequest) (*pb.GetPeersResponse, error) {
+	n.countMessage()
+
+	maxFingers := int(req.MaxFingers)
+	if maxFingers < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_fingers must not be negative")
+	}
+	if maxFingers == 0 {
+		maxFingers = DefaultPeerFingers
+	}
+
+	peers := n.Peers(maxFingers)
+	resp := &pb.GetPeersResponse{
+		Node:       toProtoNode(peers.Self),
+		Successors: toProtoNodes(peers.Successors),
+		Fingers:    toProtoNodes(peers.Fingers),
+		Success:    true,
+	}
+	if peers.Predecessor != nil {
+		resp.Predecessor = toProtoNode(peers.Predecessor)
+	}
+	return resp, nil
+}
+
+// replaceFailedSuccessor drops a failed successor and promotes the next live
+// entry of the successor list
+func (n *Node) replaceFailedSuccessor(failed *NodeInfo) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.successor == nil || n.successor.Address != failed.Address {
+		return
+	}
+
+	remaining := n.successorList[:0]
+	for _, succ := range n.successorList {
+		if succ.Address != failed.Address {
+			remaining = append(remaining, succ)
+		}
+	}
+	n.successorList = remaining
+
+	if len(n.successorList) > 0 {
+		n.successor = n.successorList[0]
+	} else {
+		// Nobody left to fall back on; stabilization will find new peers
+		// through whoever notifies us
+		n.successor = n.GetNodeInfo()
+	}
+	n.recordMembership(MemberLeft, RoleSuccessor, failed, nil)
+	if n.successor.Address != n.address {
+		n.recordMembership(MemberJoined, RoleSuccessor, n.successor, failed)
+	}
+	log.Printf("Node %s: successor %s failed, now %s",
+		n.id.Short(), failed.ID.Short(), n.successor.ID.Short())
+}
+
+// refreshSuccessorList rebuilds the successor list from our successor's list
+func (n *Node) refreshSuccessorList() {
+	successor := n.GetSuccessor()
+	if successor == nil {
+		return
+	}
+
+	if successor.Address == n.address {
+		n.mu.Lock()
+		n.successorList = nil
+		n.mu.Unlock()
+		return
+	}
+
+	peers, err := n.RemotePeers(n.ctx, successor.Address, 1)
+	if err != nil {
+		log.Printf("Node %s: failed to refresh successor list: %v", n.id.Short(), err)
+		return
+	}
+
+	// The successor describes itself best; keep what it advertises current
+	if peers.Self.Address == successor.Address && peers.Self.ID.Equal(successor.ID) {
+		successor = peers.Self
+	}
+	list := []*NodeInfo{successor}
+	seen := map[string]bool{successor.Address: true, n.address: true}
+	for _, succ := range peers.Successors {
+		if len(list) >= SuccessorListSize {
+			break
+		}
+		if seen[succ.Address] {
+			continue
+		}
+		seen[succ.Address] = true
+		list = append(list, succ)
+	}
+
+	n.mu.Lock()
+	// Only install the list if the successor did not change meanwhile
+	if n.successor != nil && n.successor.Address == successor.Address {
+		n.successor = successor
+		n.successorList = list
+	}
+	n.mu.Unlock()
+}
+
+// toProtoNode converts a NodeInfo into its wire representation
+func toProtoNode(info *NodeInfo) *pb.Node {
+	return &pb.Node{
+		Id:              info.ID.String(),
+		Address:         info.Address,
+		Zone:            info.Zone,
+		Weight:          info.Weight,
+		ProtocolVersion: info.Version,
+	}
+}
+
+// toProtoNodes converts a list of NodeInfo into their wire representation
+func toProtoNodes(infos []*NodeInfo) []*pb.Node {
+	nodes := make([]*pb.Node, 0, len(infos))
+	for _, info := range infos {
+		nodes = append(nodes, toProtoNode(info))
+	}
+	return nodes
+}
+
+// fromProtoNode converts a wire node into a NodeInfo
+func fromProtoNode(node *pb.Node) (*NodeInfo, error) {
+	if node == nil {
+		return nil, fmt.Errorf("missing node")
+	}
+	id, err := hash.NewHashFromHex(node.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node ID: %w", err)
+	}
+	return &NodeInfo{
+		ID:      id,
+		Address: node.Address,
+		Zone:    node.Zone,
+		Weight:  node.Weight,
+		Version: node.ProtocolVersion,
+	}, nil
+}
+
+// fromProtoNodes converts a list of wire nodes into NodeInfo
+func fromProtoNodes(nodes []*pb.Node) ([]*NodeInfo, error) {
+	infos := make([]*NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		info, err := fromProtoNode(node)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,84 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+// startTestRing starts count nodes on consecutive ports and runs a few
+// stabilization rounds by hand so their pointers settle
+func startTestRing(t *testing.T, basePort, count int) []*Node {
+	nodes := make([]*Node, count)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", basePort+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d: %v", i, err)
+		}
+	}
+
+	for round := 0; round < count+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	return nodes
+}
+
+func TestGetPeers(t *testing.T) {
+	nodes := startTestRing(t, 8300, 3)
+
+	peers, err := nodes[1].RemotePeers(context.Background(), nodes[0].GetAddress(), 4)
+	if err != nil {
+		t.Fatalf("RemotePeers failed: %v", err)
+	}
+
+	if peers.Self.Address != nodes[0].GetAddress() {
+		t.Errorf("Expected self %s, got %s", nodes[0].GetAddress(), peers.Self.Address)
+	}
+	if peers.Predecessor == nil {
+		t.Error("Expected a predecessor in a settled ring")
+	}
+	if len(peers.Successors) != 2 {
+		t.Fatalf("Expected 2 successors, got %d", len(peers.Successors))
+	}
+	if peers.Successors[0].Address != nodes[0].GetSuccessor().Address {
+		t.Errorf("First successor should be the node's successor")
+	}
+	for _, succ := range peers.Successors {
+		if succ.Address == nodes[0].GetAddress() {
+			t.Error("Successor list should not contain the node itself")
+		}
+	}
+}
+
+func TestSuccessorFailover(t *testing.T) {
+	nodes := startTestRing(t, 8310, 3)
+
+	node := nodes[0]
+	failed := node.GetSuccessor()
+	backup := node.Peers(0).Successors[1]
+	for _, n := range nodes {
+		if n.GetAddress() == failed.Address {
+			n.Stop()
+		}
+	}
+
+	node.stabilize()
+
+	if got := node.GetSuccessor(); got.Address != backup.Address {
+		t.Errorf("Expected failover to %s, got %s", backup.Address, got.Address)
+	}
+}
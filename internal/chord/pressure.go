@@ -0,0 +1,213 @@
+package chord
+
+import (
+	"net"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// PressureInterval is how often a node samples its resource usage
+	PressureInterval = time.Second
+	// pressureElevatedRatio is the fraction of a limit at which pressure
+	// becomes elevated
+	pressureElevatedRatio = 0.75
+	// peerPressureTTL is how long a pressure level reported by a peer is
+	// trusted
+	peerPressureTTL = 2 * StabilizeInterval
+)
+
+// PressureLevel is how loaded a node is. Under pressure a node sheds
+// optional work, and peers prefer other nodes when routing.
+type PressureLevel int32
+
+const (
+	PressureNormal PressureLevel = iota
+	PressureElevated
+	PressureCritical
+)
+
+// String returns the level's name
+func (l PressureLevel) String() string {
+	switch l {
+	case PressureNormal:
+		return "normal"
+	case PressureElevated:
+		return "elevated"
+	case PressureCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// PressureLimits are the resource usages at which a node is under critical
+// pressure. Pressure is elevated from 75% of any limit. Zero limits are not
+// monitored.
+type PressureLimits struct {
+	// CPU is the fraction of the GOMAXPROCS CPUs the process may use
+	CPU float64
+	// Memory is the number of bytes the Go runtime may map
+	Memory uint64
+	// Connections is the number of open client and server connections
+	Connections int
+}
+
+// PressureStats are the latest resource usage sample and the level derived
+// from it
+type PressureStats struct {
+	Level       PressureLevel
+	CPU         float64
+	Memory      uint64
+	Connections int
+}
+
+// pressureMonitor samples the node's resource usage
+type pressureMonitor struct {
+	mu      sync.Mutex
+	limits  PressureLimits
+	stats   PressureStats
+	cpuUsed float64
+	cpuAll  float64
+	// inbound counts the connections accepted by the server
+	inbound atomic.Int64
+
+	peersMu sync.Mutex
+	peers   map[string]peerPressure
+}
+
+// peerPressure is a pressure level reported by a peer
+type peerPressure struct {
+	level PressureLevel
+	at    time.Time
+}
+
+// SetPressureLimits sets the resource usage limits the node's pressure level
+// is computed from
+func (n *Node) SetPressureLimits(limits PressureLimits) {
+	n.pressure.mu.Lock()
+	defer n.pressure.mu.Unlock()
+
+	n.pressure.limits = limits
+}
+
+// Pressure returns the node's latest resource usage sample
+func (n *Node) Pressure() PressureStats {
+	n.pressure.mu.Lock()
+	defer n.pressure.mu.Unlock()
+
+	return n.pressure.stats
+}
+
+// PeerPressure returns the pressure level last reported by the node at
+// address, PressureNormal if unknown
+func (n *Node) PeerPressure(address string) PressureLevel {
+	n.pressure.peersMu.Lock()
+	defer n.pressure.peersMu.Unlock()
+
+	peer, ok := n.pressure.peers[address]
+	if !ok || time.Since(peer.at) > peerPressureTTL {
+		return PressureNormal
+	}
+	return peer.level
+}
+
+// shedding reports whether the node is under enough pressure to skip
+// optional work
+func (n *Node) shedding(level PressureLevel) bool {
+	return n.Pressure().Level >= level
+}
+
+// samplePressure measures resource usage and updates the pressure level
+func (n *Node) samplePressure() {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+		{Name: "/memory/classes/total:bytes"},
+	}
+	metrics.Read(samples)
+
+	n.mu.RLock()
+	outbound := len(n.connections)
+	n.mu.RUnlock()
+
+	n.pressure.mu.Lock()
+	defer n.pressure.mu.Unlock()
+
+	p := &n.pressure
+	var cpuAll, cpuUsed float64
+	if samples[0].Value.Kind() == metrics.KindFloat64 {
+		cpuAll = samples[0].Value.Float64()
+		cpuUsed = cpuAll - samples[1].Value.Float64()
+	}
+	if delta := cpuAll - p.cpuAll; delta > 0 {
+		p.stats.CPU = (cpuUsed - p.cpuUsed) / delta
+	}
+	p.cpuAll, p.cpuUsed = cpuAll, cpuUsed
+	if samples[2].Value.Kind() == metrics.KindUint64 {
+		p.stats.Memory = samples[2].Value.Uint64()
+	}
+	p.stats.Connections = outbound + int(p.inbound.Load())
+
+	ratio := 0.0
+	if p.limits.CPU > 0 {
+		ratio = max(ratio, p.stats.CPU/p.limits.CPU)
+	}
+	if p.limits.Memory > 0 {
+		ratio = max(ratio, float64(p.stats.Memory)/float64(p.limits.Memory))
+	}
+	if p.limits.Connections > 0 {
+		ratio = max(ratio, float64(p.stats.Connections)/float64(p.limits.Connections))
+	}
+
+	switch {
+	case ratio >= 1:
+		p.stats.Level = PressureCritical
+	case ratio >= pressureElevatedRatio:
+		p.stats.Level = PressureElevated
+	default:
+		p.stats.Level = PressureNormal
+	}
+}
+
+// recordPeerPressure remembers the pressure level reported by a peer
+func (n *Node) recordPeerPressure(address string, level int32) {
+	n.pressure.peersMu.Lock()
+	defer n.pressure.peersMu.Unlock()
+
+	if n.pressure.peers == nil {
+		n.pressure.peers = make(map[string]peerPressure)
+	}
+	n.pressure.peers[address] = peerPressure{level: PressureLevel(level), at: time.Now()}
+}
+
+// countingListener counts the connections accepted and not yet closed
+type countingListener struct {
+	net.Listener
+	open *atomic.Int64
+}
+
+// Accept waits for and returns the next counted connection
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.open.Add(1)
+	return &countedConn{Conn: conn, open: l.open}, nil
+}
+
+// countedConn decrements its listener's count when closed
+type countedConn struct {
+	net.Conn
+	open   *atomic.Int64
+	closed sync.Once
+}
+
+// Close closes the connection
+func (c *countedConn) Close() error {
+	c.closed.Do(func() { c.open.Add(-1) })
+	return c.Conn.Close()
+}
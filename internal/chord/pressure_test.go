@@ -0,0 +1,63 @@
+package chord
+
+import (
+	"context"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestPressureLevel(t *testing.T) {
+	nodes := startTestRing(t, 8410, 3)
+	loaded, peer := nodes[0], nodes[1]
+
+	loaded.SetPressureLimits(PressureLimits{Connections: 100})
+	loaded.samplePressure()
+	stats := loaded.Pressure()
+	if stats.Level != PressureNormal {
+		t.Errorf("Expected normal pressure, got %+v", stats)
+	}
+	if stats.Connections < 2 {
+		t.Errorf("Expected connections to both peers to be counted, got %+v", stats)
+	}
+
+	loaded.SetPressureLimits(PressureLimits{Connections: 1})
+	loaded.samplePressure()
+	if level := loaded.Pressure().Level; level != PressureCritical {
+		t.Fatalf("Expected critical pressure over the connection limit, got %v", level)
+	}
+
+	// Peers learn the level from pings
+	if err := peer.remotePing(context.Background(), loaded.GetAddress()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if level := peer.PeerPressure(loaded.GetAddress()); level != PressureCritical {
+		t.Errorf("Expected the peer to see critical pressure, got %v", level)
+	}
+}
+
+func TestRoutingAvoidsPressure(t *testing.T) {
+	nodes := startTestRing(t, 8415, 3)
+	reader := nodes[0]
+
+	// near and far both precede a key just after far
+	near, far := nodes[1].GetNodeInfo(), nodes[2].GetNodeInfo()
+	if reader.id.Distance(near.ID).Cmp(reader.id.Distance(far.ID)) > 0 {
+		near, far = far, near
+	}
+	key := hash.FingerStart(far.ID, 1)
+
+	reader.mu.Lock()
+	reader.fingers[FingerTableSize-1] = far
+	reader.fingers[FingerTableSize-2] = near
+	reader.mu.Unlock()
+
+	if got := reader.closestPrecedingFinger(key); got.Address != far.Address {
+		t.Errorf("Expected the closest finger %s, got %s", far.Address, got.Address)
+	}
+
+	reader.recordPeerPressure(far.Address, int32(PressureCritical))
+	if got := reader.closestPrecedingFinger(key); got.Address != near.Address {
+		t.Errorf("Expected %s under less pressure, got %s", near.Address, got.Address)
+	}
+}
@@ -0,0 +1,443 @@
+// Package pubsub provides publish/subscribe topics on top of the Chord DHT.
+// Every topic hashes to an owner node, which numbers published messages and
+// fans them out to the nodes that have subscribers. Nodes either join the
+// owner directly or, in Tree mode, join towards it along the lookup path so
+// that the nodes on the path form a rendezvous multicast tree and the
+// owner's fan-out stays bounded by its fingers.
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+)
+
+// Mode selects how messages travel from a topic's owner to its subscribers
+type Mode int
+
+const (
+	// Direct makes every node with subscribers join the topic owner, which
+	// sends each message to all of them
+	Direct Mode = iota
+	// Tree makes nodes join the next hop towards the owner, building a
+	// multicast tree across fingers; inner nodes forward to their children
+	Tree
+)
+
+const (
+	// keyPrefix namespaces topic keys in the identifier space
+	keyPrefix = "topic/"
+	// SubscriberBuffer is how many undelivered messages a subscription
+	// queues before new messages are dropped
+	SubscriberBuffer = 64
+)
+
+// Message is a message published to a topic
+type Message struct {
+	Topic   string
+	Payload []byte
+	// Sequence is assigned by the topic owner and increases per topic
+	Sequence uint64
+	// Publisher is the address of the node the message was published through
+	Publisher string
+}
+
+// Broker serves topics on a Chord node
+type Broker struct {
+	node *chord.Node
+	mode Mode
+
+	mu        sync.Mutex
+	topics    map[string]*topicState
+	sequences map[string]uint64 // last sequence of topics we own
+}
+
+// topicState is a topic this node takes part in
+type topicState struct {
+	subscribers map[*Subscription]struct{}
+	// children are nodes that receive the topic through us
+	children map[string]struct{}
+	// parent is the node we joined, empty while we are the root
+	parent string
+}
+
+// New creates a Broker on node and registers its gRPC service. It must be
+// called before the node is started.
+func New(node *chord.Node, mode Mode) *Broker {
+	b := &Broker{
+		node:      node,
+		mode:      mode,
+		topics:    make(map[string]*topicState),
+		sequences: make(map[string]uint64),
+	}
+	node.RegisterService(&pb.PubSubService_ServiceDesc, &server{broker: b})
+	go b.maintain()
+	return b
+}
+
+// Subscribe starts receiving the messages published to topic from now on
+func (b *Broker) Subscribe(ctx context.Context, topic string) (*Subscription, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("empty topic")
+	}
+
+	sub := &Subscription{
+		Topic:    topic,
+		messages: make(chan *Message, SubscriberBuffer),
+		broker:   b,
+	}
+
+	b.mu.Lock()
+	b.topicLocked(topic).subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	if err := b.attach(ctx, topic); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %q: %w", topic, err)
+	}
+	return sub, nil
+}
+
+// Publish sends payload to every subscriber of topic and returns the
+// sequence number the owner assigned to it
+func (b *Broker) Publish(ctx context.Context, topic string, payload []byte) (uint64, error) {
+	if topic == "" {
+		return 0, fmt.Errorf("empty topic")
+	}
+	return b.publish(ctx, &pb.PublishRequest{
+		Topic:     topic,
+		Payload:   payload,
+		Publisher: b.node.GetAddress(),
+	})
+}
+
+// publish numbers and disseminates a message if we own the topic, or hands
+// it to the owner otherwise
+func (b *Broker) publish(ctx context.Context, req *pb.PublishRequest) (uint64, error) {
+	owner, err := b.node.Lookup(b.topicKey(req.Topic))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find owner of %q: %w", req.Topic, err)
+	}
+
+	if owner.Address != b.node.GetAddress() {
+		client, err := b.client(owner.Address)
+		if err != nil {
+			return 0, err
+		}
+		ctx, cancel := context.WithTimeout(ctx, chord.RPCTimeout)
+		defer cancel()
+
+		resp, err := client.Publish(ctx, req)
+		if err != nil {
+			return 0, fmt.Errorf("publish to %s failed: %w", owner.Address, err)
+		}
+		if !resp.Success {
+			return 0, fmt.Errorf("publish to %s failed: %s", owner.Address, resp.Error)
+		}
+		return resp.Sequence, nil
+	}
+
+	b.mu.Lock()
+	b.sequences[req.Topic]++
+	sequence := b.sequences[req.Topic]
+	b.mu.Unlock()
+
+	b.disseminate(&pb.TopicMessage{
+		Topic:     req.Topic,
+		Payload:   req.Payload,
+		Sequence:  sequence,
+		Publisher: req.Publisher,
+	})
+	return sequence, nil
+}
+
+// disseminate delivers a message to local subscribers and forwards it to
+// the children of the topic
+func (b *Broker) disseminate(msg *pb.TopicMessage) {
+	b.mu.Lock()
+	t, ok := b.topics[msg.Topic]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	subscribers := make([]*Subscription, 0, len(t.subscribers))
+	for sub := range t.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	children := make([]string, 0, len(t.children))
+	for child := range t.children {
+		children = append(children, child)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.deliver(&Message{
+			Topic:     msg.Topic,
+			Payload:   msg.Payload,
+			Sequence:  msg.Sequence,
+			Publisher: msg.Publisher,
+		})
+	}
+	for _, child := range children {
+		go b.forward(child, msg)
+	}
+}
+
+// forward sends a message to a child, dropping the child if it is gone
+func (b *Broker) forward(child string, msg *pb.TopicMessage) {
+	client, err := b.client(child)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), chord.RPCTimeout)
+		defer cancel()
+		_, err = client.Deliver(ctx, &pb.DeliverRequest{Message: msg})
+	}
+	if err != nil {
+		log.Printf("pubsub: dropping child %s of %q: %v", child, msg.Topic, err)
+		b.removeChild(msg.Topic, child)
+	}
+}
+
+// attach joins the topic at the node we should receive it from, and leaves
+// the previous parent if that changed. Joining an unchanged parent again is
+// harmless and refreshes our membership there.
+func (b *Broker) attach(ctx context.Context, topic string) error {
+	parent, err := b.parentFor(topic)
+	if err != nil {
+		return err
+	}
+
+	if parent != "" {
+		if err := b.joinAt(ctx, parent, topic); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	t, ok := b.topics[topic]
+	previous := ""
+	if ok {
+		previous = t.parent
+		t.parent = parent
+	}
+	b.mu.Unlock()
+
+	if !ok && parent != "" {
+		// The topic was dropped while we were joining
+		b.leaveAt(ctx, parent, topic)
+	}
+	if previous != "" && previous != parent {
+		b.leaveAt(ctx, previous, topic)
+	}
+	return nil
+}
+
+// parentFor returns the node to join for topic, or an empty string if we
+// own it
+func (b *Broker) parentFor(topic string) (string, error) {
+	key := b.topicKey(topic)
+	owner, err := b.node.Lookup(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to find owner of %q: %w", topic, err)
+	}
+
+	self := b.node.GetAddress()
+	if owner.Address == self {
+		return "", nil
+	}
+	if b.mode == Tree {
+		if hop := b.node.NextHop(key); hop.Address != self {
+			return hop.Address, nil
+		}
+	}
+	return owner.Address, nil
+}
+
+// joinAt asks parent to forward the topic to us
+func (b *Broker) joinAt(ctx context.Context, parent, topic string) error {
+	client, err := b.client(parent)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, chord.RPCTimeout)
+	defer cancel()
+
+	resp, err := client.JoinTopic(ctx, &pb.JoinTopicRequest{Topic: topic, Child: b.node.GetAddress()})
+	if err != nil {
+		return fmt.Errorf("join %q at %s failed: %w", topic, parent, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("join %q at %s failed: %s", topic, parent, resp.Error)
+	}
+	return nil
+}
+
+// leaveAt tells parent to stop forwarding the topic to us
+func (b *Broker) leaveAt(ctx context.Context, parent, topic string) {
+	client, err := b.client(parent)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(ctx, chord.RPCTimeout)
+		defer cancel()
+		_, err = client.LeaveTopic(ctx, &pb.LeaveTopicRequest{Topic: topic, Child: b.node.GetAddress()})
+	}
+	if err != nil {
+		log.Printf("pubsub: failed to leave %q at %s: %v", topic, parent, err)
+	}
+}
+
+// addChild records a node that receives the topic through us. It reports
+// whether we had to start taking part in the topic for it.
+func (b *Broker) addChild(topic, child string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, existed := b.topics[topic]
+	b.topicLocked(topic).children[child] = struct{}{}
+	return !existed
+}
+
+// removeChild stops forwarding the topic to child
+func (b *Broker) removeChild(topic, child string) {
+	b.mu.Lock()
+	if t, ok := b.topics[topic]; ok {
+		delete(t.children, child)
+	}
+	b.mu.Unlock()
+
+	b.prune(topic)
+}
+
+// unsubscribe removes a local subscription
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	if t, ok := b.topics[sub.Topic]; ok {
+		delete(t.subscribers, sub)
+	}
+	b.mu.Unlock()
+
+	b.prune(sub.Topic)
+}
+
+// prune drops a topic nobody needs from us anymore and leaves its parent
+func (b *Broker) prune(topic string) {
+	b.mu.Lock()
+	t, ok := b.topics[topic]
+	if !ok || len(t.subscribers) > 0 || len(t.children) > 0 {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.topics, topic)
+	parent := t.parent
+	b.mu.Unlock()
+
+	if parent != "" {
+		b.leaveAt(context.Background(), parent, topic)
+	}
+}
+
+// topicLocked returns the state of a topic, creating it if needed. The
+// caller must hold mu.
+func (b *Broker) topicLocked(topic string) *topicState {
+	t, ok := b.topics[topic]
+	if !ok {
+		t = &topicState{
+			subscribers: make(map[*Subscription]struct{}),
+			children:    make(map[string]struct{}),
+		}
+		b.topics[topic] = t
+	}
+	return t
+}
+
+// maintain periodically re-attaches every topic so the dissemination paths
+// follow ownership and finger changes
+func (b *Broker) maintain() {
+	ticker := time.NewTicker(chord.StabilizeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.node.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		topics := make([]string, 0, len(b.topics))
+		for topic := range b.topics {
+			topics = append(topics, topic)
+		}
+		b.mu.Unlock()
+
+		for _, topic := range topics {
+			if err := b.attach(context.Background(), topic); err != nil {
+				log.Printf("pubsub: failed to refresh %q: %v", topic, err)
+			}
+		}
+	}
+}
+
+// client returns a PubSubService client sharing the node's connection
+func (b *Broker) client(address string) (pb.PubSubServiceClient, error) {
+	conn, err := b.node.ClientConn(address)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewPubSubServiceClient(conn), nil
+}
+
+// topicKey returns the identifier a topic hashes to
+func (b *Broker) topicKey(topic string) *hash.Hash {
+	return b.node.KeyID(keyPrefix + topic)
+}
+
+// Subscription receives the messages of a topic
+type Subscription struct {
+	Topic string
+
+	mu       sync.Mutex
+	closed   bool
+	messages chan *Message
+	broker   *Broker
+}
+
+// Messages returns the channel messages are delivered on. It is closed
+// when the subscription is closed.
+func (s *Subscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.messages)
+	s.mu.Unlock()
+
+	s.broker.unsubscribe(s)
+}
+
+// deliver queues a message, dropping it if the subscriber is too far behind
+func (s *Subscription) deliver(msg *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.messages <- msg:
+	default:
+		log.Printf("pubsub: subscriber of %q is behind, dropping message %d", s.Topic, msg.Sequence)
+	}
+}
@@ -0,0 +1,143 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// startRing starts count nodes with a broker each and waits until every
+// node has a predecessor
+func startRing(t *testing.T, basePort, count int, mode Mode) ([]*chord.Node, []*Broker) {
+	nodes := make([]*chord.Node, count)
+	brokers := make([]*Broker, count)
+	for i := range nodes {
+		nodes[i] = chord.NewNode(fmt.Sprintf("localhost:%d", basePort+i), nil)
+		brokers[i] = New(nodes[i], mode)
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d: %v", i, err)
+		}
+	}
+
+	for attempt := 0; count > 1 && attempt < 150; attempt++ {
+		settled := true
+		for _, node := range nodes {
+			if node.GetPredecessor() == nil {
+				settled = false
+			}
+		}
+		if settled {
+			return nodes, brokers
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if count > 1 {
+		t.Fatal("Ring did not stabilize")
+	}
+	return nodes, brokers
+}
+
+func receive(t *testing.T, sub *Subscription) *Message {
+	select {
+	case msg := <-sub.Messages():
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for a message on %q", sub.Topic)
+		return nil
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	for _, mode := range []Mode{Direct, Tree} {
+		t.Run(fmt.Sprintf("mode=%d", mode), func(t *testing.T) {
+			_, brokers := startRing(t, 8340+int(mode)*10, 3, mode)
+			ctx := context.Background()
+
+			var subs []*Subscription
+			for _, broker := range brokers {
+				sub, err := broker.Subscribe(ctx, "news")
+				if err != nil {
+					t.Fatalf("Subscribe failed: %v", err)
+				}
+				defer sub.Close()
+				subs = append(subs, sub)
+			}
+
+			for i := 1; i <= 3; i++ {
+				seq, err := brokers[i%len(brokers)].Publish(ctx, "news", []byte(fmt.Sprintf("item %d", i)))
+				if err != nil {
+					t.Fatalf("Publish failed: %v", err)
+				}
+				if seq != uint64(i) {
+					t.Errorf("Expected sequence %d, got %d", i, seq)
+				}
+			}
+
+			for _, sub := range subs {
+				for i := 1; i <= 3; i++ {
+					msg := receive(t, sub)
+					if string(msg.Payload) != fmt.Sprintf("item %d", msg.Sequence) {
+						t.Errorf("Payload %q does not match sequence %d", msg.Payload, msg.Sequence)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSubscribeStream(t *testing.T) {
+	nodes, brokers := startRing(t, 8360, 1, Direct)
+
+	conn, err := grpc.NewClient(nodes[0].GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewPubSubServiceClient(conn).Subscribe(ctx, &pb.SubscribeRequest{Topic: "events"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// The subscription is registered asynchronously; publish until it lands
+	received := make(chan *pb.TopicMessage, 1)
+	go func() {
+		if msg, err := stream.Recv(); err == nil {
+			received <- msg
+		}
+	}()
+	for {
+		if _, err := brokers[0].Publish(ctx, "events", []byte("hello")); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		select {
+		case msg := <-received:
+			if string(msg.Payload) != "hello" {
+				t.Errorf("Expected hello, got %q", msg.Payload)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the stream")
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package pubsub
+
+import (
+	"context"
+	"log"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// server implements the PubSubService RPCs for a Broker
+type server struct {
+	pb.UnimplementedPubSubServiceServer
+
+	broker *Broker
+}
+
+// Subscribe streams the messages of a topic until the client goes away or
+// the node stops
+func (s *server) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStreamingServer[pb.TopicMessage]) error {
+	if req.Topic == "" {
+		return status.Error(codes.InvalidArgument, "empty topic")
+	}
+
+	sub, err := s.broker.Subscribe(stream.Context(), req.Topic)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.broker.node.Done():
+			return status.Error(codes.Unavailable, "node is shutting down")
+		case msg := <-sub.Messages():
+			err := stream.Send(&pb.TopicMessage{
+				Topic:     msg.Topic,
+				Payload:   msg.Payload,
+				Sequence:  msg.Sequence,
+				Publisher: msg.Publisher,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Publish publishes a message, forwarding it to the topic owner if needed
+func (s *server) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	if req.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty topic")
+	}
+
+	sequence, err := s.broker.publish(ctx, req)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.PublishResponse{Sequence: sequence, Success: true}, nil
+}
+
+// JoinTopic makes the caller a child of this node for a topic
+func (s *server) JoinTopic(ctx context.Context, req *pb.JoinTopicRequest) (*pb.JoinTopicResponse, error) {
+	if req.Topic == "" || req.Child == "" {
+		return nil, status.Error(codes.InvalidArgument, "topic and child are required")
+	}
+
+	if s.broker.addChild(req.Topic, req.Child) {
+		// We just became part of the topic's tree; hook ourselves up too.
+		// Periodic maintenance retries if this fails.
+		if err := s.broker.attach(ctx, req.Topic); err != nil {
+			log.Printf("pubsub: failed to attach %q: %v", req.Topic, err)
+		}
+	}
+	return &pb.JoinTopicResponse{Success: true}, nil
+}
+
+// LeaveTopic stops forwarding a topic to the caller
+func (s *server) LeaveTopic(ctx context.Context, req *pb.LeaveTopicRequest) (*pb.LeaveTopicResponse, error) {
+	if req.Topic == "" || req.Child == "" {
+		return nil, status.Error(codes.InvalidArgument, "topic and child are required")
+	}
+
+	s.broker.removeChild(req.Topic, req.Child)
+	return &pb.LeaveTopicResponse{Success: true}, nil
+}
+
+// Deliver receives a message from our parent in the topic's tree
+func (s *server) Deliver(ctx context.Context, req *pb.DeliverRequest) (*pb.DeliverResponse, error) {
+	if req.Message == nil || req.Message.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing message")
+	}
+
+	s.broker.disseminate(req.Message)
+	return &pb.DeliverResponse{Success: true}, nil
+}
@@ -0,0 +1,238 @@
+package chord
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Network is what a node's gRPC server and its connections to peers run
+// over
+type Network string
+
+const (
+	// NetworkTCP runs gRPC over TCP
+	NetworkTCP Network = "tcp"
+	// NetworkQUIC runs gRPC between nodes over QUIC, on the UDP port
+	// numbered like the node's TCP port. QUIC sets up transport and
+	// encryption in one round trip, recovers from loss without stalling on
+	// TCP's retransmission timers, and identifies connections by ID rather
+	// than address, so they survive a peer's NAT rebinding. Every node of a
+	// ring must use it; clients such as chordctl keep using TCP, which the
+	// node still serves.
+	NetworkQUIC Network = "quic"
+)
+
+// quicALPN is the application protocol negotiated by QUIC connections
+// between nodes
+const quicALPN = "chord-grpc"
+
+// quicConfig keeps idle connections to peers open between maintenance
+// rounds
+var quicConfig = &quic.Config{
+	KeepAlivePeriod: 10 * time.Second,
+	MaxIdleTimeout:  time.Minute,
+}
+
+// ParseNetwork returns the network with the given name: tcp or quic
+func ParseNetwork(name string) (Network, error) {
+	switch Network(name) {
+	case "", NetworkTCP:
+		return NetworkTCP, nil
+	case NetworkQUIC:
+		return NetworkQUIC, nil
+	default:
+		return "", fmt.Errorf("unknown network %q", name)
+	}
+}
+
+// SetNetwork sets what the node's connections to peers run over. It must
+// be called before Start.
+func (n *Node) SetNetwork(network Network) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.network = network
+}
+
+// listenQUIC serves gRPC over QUIC on the UDP port of the node's TCP
+// listener. The node dials peers from the same socket, so a peer behind NAT
+// is seen at one address by every node, which hole punching relies on (see
+// nat.go). The caller must hold mu.
+func (n *Node) listenQUIC(bindAddr string) (net.Listener, error) {
+	if n.transport.kind == TransportUDP {
+		return nil, fmt.Errorf("the udp maintenance transport and the quic network both need the UDP port of %s", bindAddr)
+	}
+	certificate, err := selfSignedCertificate()
+	if err != nil {
+		return nil, err
+	}
+	addr, err := net.ResolveUDPAddr("udp", bindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", bindAddr, err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on quic %s: %w", bindAddr, err)
+	}
+	transport := &quic.Transport{Conn: conn}
+	listener, err := transport.Listen(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		NextProtos:   []string{quicALPN},
+	}, quicConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to listen on quic %s: %w", bindAddr, err)
+	}
+	n.quicTransport = transport
+
+	ctx, cancel := context.WithCancel(n.ctx)
+	l := &quicListener{transport: transport, listener: listener, conns: make(chan net.Conn), ctx: ctx, cancel: cancel}
+	go l.run()
+	return l, nil
+}
+
+// dialQUIC opens a QUIC connection to address carrying one gRPC connection,
+// from transport if the node has one
+func dialQUIC(ctx context.Context, transport *quic.Transport, address string) (net.Conn, error) {
+	// Nodes present throwaway certificates; QUIC's encryption is kept, but
+	// peers are authenticated by the identity middleware, as over TCP
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{quicALPN},
+	}
+	var conn *quic.Conn
+	var err error
+	if transport != nil {
+		var addr *net.UDPAddr
+		if addr, err = net.ResolveUDPAddr("udp", address); err != nil {
+			return nil, err
+		}
+		conn, err = transport.Dial(ctx, addr, tlsConfig, quicConfig)
+	} else {
+		conn, err = quic.DialAddr(ctx, address, tlsConfig, quicConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(0, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+// quicListener accepts the QUIC connections of peers as net.Conns for the
+// gRPC server, one per connection's first stream
+type quicListener struct {
+	transport *quic.Transport
+	listener  *quic.Listener
+	conns     chan net.Conn
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// run accepts connections until the listener is closed
+func (l *quicListener) run() {
+	for {
+		conn, err := l.listener.Accept(l.ctx)
+		if err != nil {
+			return
+		}
+		go l.acceptStream(conn)
+	}
+}
+
+// acceptStream hands the first stream of conn to Accept
+func (l *quicListener) acceptStream(conn *quic.Conn) {
+	ctx, cancel := context.WithTimeout(l.ctx, RPCTimeout)
+	defer cancel()
+
+	stream, err := conn.AcceptStream(ctx)
+	if err != nil {
+		conn.CloseWithError(0, "")
+		return
+	}
+	select {
+	case l.conns <- &quicConn{Stream: stream, conn: conn}:
+	case <-l.ctx.Done():
+		conn.CloseWithError(0, "")
+	}
+}
+
+// Accept waits for and returns the next connection
+func (l *quicListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.ctx.Done():
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections and closes the node's UDP socket
+func (l *quicListener) Close() error {
+	l.cancel()
+	err := l.listener.Close()
+	l.transport.Close()
+	l.transport.Conn.Close()
+	return err
+}
+
+// Addr returns the listener's UDP address
+func (l *quicListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// quicConn is a QUIC connection used through its one stream
+type quicConn struct {
+	*quic.Stream
+	conn *quic.Conn
+}
+
+// LocalAddr returns the local UDP address
+func (c *quicConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the peer's UDP address
+func (c *quicConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Close closes the stream and its connection
+func (c *quicConn) Close() error {
+	c.Stream.CancelRead(0)
+	c.Stream.Close()
+	return c.conn.CloseWithError(0, "")
+}
+
+// selfSignedCertificate creates a certificate for the node's QUIC listener
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "chord node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
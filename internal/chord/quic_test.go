@@ -0,0 +1,85 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+func TestQUICNetwork(t *testing.T) {
+	// Record the network every RPC between nodes arrived over
+	var mu sync.Mutex
+	networks := make(map[string]bool)
+	recordNetwork := Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if p, ok := peer.FromContext(ctx); ok && info.FullMethod == pb.ChordService_Notify_FullMethodName {
+				mu.Lock()
+				networks[p.Addr.Network()] = true
+				mu.Unlock()
+			}
+			return handler(ctx, req)
+		},
+	}
+
+	nodes := make([]*Node, 3)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", 8506+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		nodes[i].SetNetwork(NetworkQUIC)
+		nodes[i].Use(recordNetwork)
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d over QUIC: %v", i, err)
+		}
+	}
+	for round := 0; round < len(nodes)+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	checkRing(t, nodes)
+
+	mu.Lock()
+	if !networks["udp"] || networks["tcp"] {
+		t.Errorf("Expected nodes to notify each other over QUIC only, saw %v", networks)
+	}
+	mu.Unlock()
+
+	// Clients still reach the nodes over TCP
+	conn, err := grpc.NewClient(nodes[0].GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+	resp, err := pb.NewChordServiceClient(conn).FindSuccessor(context.Background(),
+		&pb.FindSuccessorRequest{Key: hash.NewHashFromString("key").String()})
+	if err != nil || !resp.Success {
+		t.Fatalf("Lookup over TCP failed: %v", err)
+	}
+}
+
+func TestQUICNetworkNeedsUDPPort(t *testing.T) {
+	node := NewNode("localhost:8509", nil)
+	node.SetNetwork(NetworkQUIC)
+	node.SetMaintenanceTransport(TransportUDP)
+	if err := node.Start(); err == nil {
+		node.Stop()
+		t.Fatal("Expected QUIC with the UDP maintenance transport to be refused")
+	}
+}
@@ -0,0 +1,206 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// idlePeerTimeout is how long a peer's bucket is kept after its last RPC
+const idlePeerTimeout = time.Minute
+
+// maintenanceMethods are the RPCs that keep the ring and its data in shape.
+// They are exempt from the overall limit so client traffic cannot starve
+// them; the per-peer limit still applies.
+var maintenanceMethods = map[string]bool{
+	pb.ChordService_FindSuccessor_FullMethodName:          true,
+	pb.ChordService_Notify_FullMethodName:                 true,
+	pb.ChordService_JoinSettled_FullMethodName:            true,
+	pb.ChordService_GetInfo_FullMethodName:                true,
+	pb.ChordService_Ping_FullMethodName:                   true,
+	pb.ChordService_ClosestPrecedingFinger_FullMethodName: true,
+	pb.ChordService_GetPeers_FullMethodName:               true,
+	pb.ChordService_GetSuccessorList_FullMethodName:       true,
+	pb.ChordService_GetRoutingTable_FullMethodName:        true,
+	pb.ChordService_RelayBroadcast_FullMethodName:         true,
+	pb.ChordService_PrepareHandoff_FullMethodName:         true,
+	pb.ChordService_CommitHandoff_FullMethodName:          true,
+	pb.ChordService_StreamHandoff_FullMethodName:          true,
+	pb.ChordService_Replicate_FullMethodName:              true,
+	pb.ChordService_CheckReachability_FullMethodName:      true,
+	pb.ChordService_Relay_FullMethodName:                  true,
+	pb.ChordService_Rendezvous_FullMethodName:             true,
+}
+
+// RateLimits configures the token buckets admitting incoming RPCs. RPCs
+// beyond a limit are rejected with ErrOverloaded and a retry delay.
+type RateLimits struct {
+	// PeerRate is the number of RPCs per second admitted from each peer
+	// host. Zero disables the per-peer limit.
+	PeerRate float64
+	// PeerBurst is the number of RPCs a peer may send back to back (at
+	// least 1)
+	PeerBurst int
+	// Rate is the number of RPCs per second admitted overall, not counting
+	// ring maintenance. Zero disables the overall limit.
+	Rate float64
+	// Burst is the overall burst (at least 1)
+	Burst int
+}
+
+// RateLimitStats counts the RPCs handled by the rate limiter
+type RateLimitStats struct {
+	Admitted int64
+	// PeerRejected counts RPCs rejected by a per-peer limit
+	PeerRejected int64
+	// Rejected counts RPCs rejected by the overall limit
+	Rejected int64
+}
+
+// tokenBucket holds the tokens of one limit
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and takes a token. Otherwise it returns how long
+// until the next token is available.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) (bool, time.Duration) {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// rateLimiter admits incoming RPCs per peer and overall
+type rateLimiter struct {
+	mu        sync.Mutex
+	limits    RateLimits
+	total     tokenBucket
+	peers     map[string]*tokenBucket
+	lastSweep time.Time
+	stats     RateLimitStats
+}
+
+// SetRateLimits sets the limits on incoming RPCs
+func (n *Node) SetRateLimits(limits RateLimits) {
+	if limits.PeerBurst < 1 {
+		limits.PeerBurst = 1
+	}
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+
+	n.limiter.mu.Lock()
+	defer n.limiter.mu.Unlock()
+
+	now := time.Now()
+	n.limiter.limits = limits
+	n.limiter.total = tokenBucket{tokens: float64(limits.Burst), last: now}
+	n.limiter.peers = make(map[string]*tokenBucket)
+	n.limiter.lastSweep = now
+}
+
+// RateLimitStats returns counters for the RPCs this node admitted or
+// rejected
+func (n *Node) RateLimitStats() RateLimitStats {
+	n.limiter.mu.Lock()
+	defer n.limiter.mu.Unlock()
+
+	return n.limiter.stats
+}
+
+// admit takes a token for an RPC to method from the peer in ctx
+func (l *rateLimiter) admit(ctx context.Context, method string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.limits.PeerRate > 0 {
+		host := peerHost(ctx)
+		bucket, ok := l.peers[host]
+		if !ok {
+			bucket = &tokenBucket{tokens: float64(l.limits.PeerBurst), last: now}
+			l.peers[host] = bucket
+		}
+		if ok, wait := bucket.take(now, l.limits.PeerRate, l.limits.PeerBurst); !ok {
+			l.stats.PeerRejected++
+			return &retryableError{
+				err:   fmt.Errorf("%w: peer %s above %.0f RPC/s", ErrOverloaded, host, l.limits.PeerRate),
+				after: wait,
+			}
+		}
+		l.sweep(now)
+	}
+
+	if l.limits.Rate > 0 && !maintenanceMethods[method] {
+		if ok, wait := l.total.take(now, l.limits.Rate, l.limits.Burst); !ok {
+			l.stats.Rejected++
+			return &retryableError{
+				err:   fmt.Errorf("%w: above %.0f RPC/s", ErrOverloaded, l.limits.Rate),
+				after: wait,
+			}
+		}
+	}
+
+	l.stats.Admitted++
+	return nil
+}
+
+// sweep drops the buckets of peers idle for long enough to have refilled.
+// The caller must hold mu.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idlePeerTimeout {
+		return
+	}
+	l.lastSweep = now
+	for host, bucket := range l.peers {
+		if now.Sub(bucket.last) > idlePeerTimeout {
+			delete(l.peers, host)
+		}
+	}
+}
+
+// unaryServer rejects unary RPCs beyond the limits
+func (l *rateLimiter) unaryServer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := l.admit(ctx, info.FullMethod); err != nil {
+		return nil, toStatus(err)
+	}
+	return handler(ctx, req)
+}
+
+// streamServer rejects streams beyond the limits
+func (l *rateLimiter) streamServer(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.admit(ss.Context(), info.FullMethod); err != nil {
+		return toStatus(err)
+	}
+	return handler(srv, ss)
+}
+
+// peerHost returns the host an RPC came from, without its port, so every
+// connection of a peer shares one bucket
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
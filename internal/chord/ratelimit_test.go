@@ -0,0 +1,64 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+func TestRateLimits(t *testing.T) {
+	server := NewNode("localhost:8442", nil)
+	server.SetRateLimits(RateLimits{Rate: 0.5, Burst: 2})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(server.Stop)
+	if err := server.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	caller := NewNode("localhost:8443", nil)
+	t.Cleanup(caller.Stop)
+
+	client, err := caller.getClient(server.GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	ctx := context.Background()
+	put := func() error {
+		_, err := client.Put(ctx, &pb.PutRequest{Key: "key", Value: []byte("value")})
+		return fromStatus(server.GetAddress(), err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := put(); err != nil {
+			t.Fatalf("Put %d within the burst failed: %v", i, err)
+		}
+	}
+	err = put()
+	if !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("Expected ErrOverloaded beyond the overall limit, got %v", err)
+	}
+	if delay, ok := RetryDelay(err); !ok || delay <= 0 {
+		t.Errorf("Expected a retry delay, got %v (%v)", delay, ok)
+	}
+	// Ring maintenance is exempt from the overall limit
+	if _, err := caller.RemotePeers(ctx, server.GetAddress(), 1); err != nil {
+		t.Errorf("Maintenance RPC was throttled: %v", err)
+	}
+
+	// The per-peer limit applies to maintenance too
+	server.SetRateLimits(RateLimits{PeerRate: 0.5, PeerBurst: 1})
+	if _, err := caller.RemotePeers(ctx, server.GetAddress(), 1); err != nil {
+		t.Fatalf("First RPC within the peer burst failed: %v", err)
+	}
+	if _, err := caller.RemotePeers(ctx, server.GetAddress(), 1); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("Expected ErrOverloaded beyond the peer limit, got %v", err)
+	}
+
+	stats := server.RateLimitStats()
+	if stats.Admitted != 4 || stats.Rejected != 1 || stats.PeerRejected != 1 {
+		t.Errorf("Unexpected rate limit stats: %+v", stats)
+	}
+}
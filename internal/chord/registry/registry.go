@@ -0,0 +1,306 @@
+// Package registry provides service discovery on top of the Chord DHT.
+// Services register name → address endpoints under leases that expire
+// unless refreshed by a heartbeat; lookups return the live endpoints of a
+// name and watches report when they change. All endpoints of a name are
+// kept in one key, updated with conditional writes on the key's owner.
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/internal/retry"
+)
+
+const (
+	// keyPrefix namespaces service keys in the DHT
+	keyPrefix = "registry/"
+	// DefaultWatchInterval is how often a watch reads a name by default
+	DefaultWatchInterval = time.Second
+	// maxUpdateAttempts bounds the conditional writes of one update while
+	// other services of the name keep updating it
+	maxUpdateAttempts = 16
+)
+
+// updatePolicy retries an update while the name's key is moving
+var updatePolicy = retry.Policy{
+	MaxAttempts:  5,
+	InitialDelay: 50 * time.Millisecond,
+	Multiplier:   2,
+	Retryable: retry.On(chord.ErrNotResponsible,
+		chord.ErrRingUnstable, chord.ErrRangeMoving),
+}
+
+var (
+	// ErrInvalidName is returned for an empty service name
+	ErrInvalidName = errors.New("invalid service name")
+	// errContended is returned when a name kept changing under an update
+	errContended = errors.New("service modified concurrently")
+)
+
+// Endpoint is a registered instance of a service
+type Endpoint struct {
+	// ID identifies the registration, so one address can be registered
+	// more than once
+	ID      string    `json:"id"`
+	Address string    `json:"address"`
+	Expires time.Time `json:"expires"`
+}
+
+// Registry registers and looks up services through a Chord node
+type Registry struct {
+	node *chord.Node
+}
+
+// Lease is a registration, live until it expires or is deregistered
+type Lease struct {
+	Name     string
+	Endpoint Endpoint
+	TTL      time.Duration
+
+	registry *Registry
+}
+
+// New creates a Registry that issues requests through node
+func New(node *chord.Node) *Registry {
+	return &Registry{node: node}
+}
+
+// Register registers address under name for ttl. Expiry is judged by the
+// clocks of the registering and looking up processes, which must roughly
+// agree.
+func (r *Registry) Register(ctx context.Context, name, address string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("registry ttl must be positive, got %v", ttl)
+	}
+	lease := &Lease{
+		Name:     name,
+		Endpoint: Endpoint{ID: newID(), Address: address},
+		TTL:      ttl,
+		registry: r,
+	}
+	if err := lease.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// Refresh extends the lease by its TTL from now. A lease that expired is
+// registered again.
+func (l *Lease) Refresh(ctx context.Context) error {
+	endpoint := l.Endpoint
+	endpoint.Expires = time.Now().Add(l.TTL)
+	err := l.registry.update(ctx, l.Name, func(endpoints map[string]Endpoint) {
+		endpoints[endpoint.ID] = endpoint
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register %q: %w", l.Name, err)
+	}
+	l.Endpoint = endpoint
+	return nil
+}
+
+// KeepAlive refreshes the lease every third of its TTL until ctx is done,
+// logging failed heartbeats
+func (l *Lease) KeepAlive(ctx context.Context) {
+	ticker := time.NewTicker(l.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("registry: heartbeat of %q failed: %v", l.Name, err)
+			}
+		}
+	}
+}
+
+// Deregister removes the endpoint; stop KeepAlive first
+func (l *Lease) Deregister(ctx context.Context) error {
+	err := l.registry.update(ctx, l.Name, func(endpoints map[string]Endpoint) {
+		delete(endpoints, l.Endpoint.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deregister %q: %w", l.Name, err)
+	}
+	return nil
+}
+
+// Lookup returns the live endpoints of name, sorted by address
+func (r *Registry) Lookup(ctx context.Context, name string) ([]Endpoint, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	value, err := r.node.FetchValue(ctx, keyPrefix+name)
+	if errors.Is(err, chord.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %q: %w", name, err)
+	}
+	endpoints, err := decode(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return live(endpoints, time.Now()), nil
+}
+
+// Watch reads the endpoints of name every interval (DefaultWatchInterval if
+// zero) and sends them when they change, registrations and expiries
+// alike, starting with the current ones. The channel is closed once ctx is
+// done. Failed reads are logged and retried on the next tick.
+func (r *Registry) Watch(ctx context.Context, name string, interval time.Duration) <-chan []Endpoint {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	changes := make(chan []Endpoint, 1)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last []Endpoint
+		first := true
+		for {
+			endpoints, err := r.Lookup(ctx, name)
+			switch {
+			case err != nil:
+				if ctx.Err() == nil {
+					log.Printf("registry: watch of %q failed: %v", name, err)
+				}
+			case first || !sameEndpoints(endpoints, last):
+				select {
+				case changes <- endpoints:
+				case <-ctx.Done():
+					return
+				}
+				last = endpoints
+				first = false
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return changes
+}
+
+// update applies fn to the live endpoints of name and writes them back with
+// a conditional write, trying again if another update came first. The key
+// expires with its last endpoint.
+func (r *Registry) update(ctx context.Context, name string, fn func(map[string]Endpoint)) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	key := keyPrefix + name
+	return updatePolicy.Do(ctx, func(int) error {
+		current, err := r.node.FetchValue(ctx, key)
+		if err != nil && !errors.Is(err, chord.ErrKeyNotFound) {
+			return err
+		}
+
+		for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+			now := time.Now()
+			endpoints := make(map[string]Endpoint)
+			if current != nil {
+				stored, err := decode(name, current)
+				if err != nil {
+					return err
+				}
+				for _, endpoint := range live(stored, now) {
+					endpoints[endpoint.ID] = endpoint
+				}
+			}
+			fn(endpoints)
+
+			write := chord.ConditionalWrite{Key: key, Expected: current, Delete: len(endpoints) == 0}
+			if !write.Delete {
+				var last time.Time
+				for _, endpoint := range endpoints {
+					if endpoint.Expires.After(last) {
+						last = endpoint.Expires
+					}
+				}
+				if write.Value, err = json.Marshal(endpoints); err != nil {
+					return err
+				}
+				write.TTL = last.Sub(now)
+			}
+
+			result, err := r.node.CompareAndSwap(ctx, write)
+			if err != nil {
+				return err
+			}
+			if result.Applied {
+				return nil
+			}
+			current = result.Current
+		}
+		return fmt.Errorf("%w: %s", errContended, name)
+	})
+}
+
+// decode decodes the endpoints stored under name
+func decode(name string, value []byte) (map[string]Endpoint, error) {
+	var endpoints map[string]Endpoint
+	if err := json.Unmarshal(value, &endpoints); err != nil {
+		return nil, fmt.Errorf("invalid endpoints of %q: %w", name, err)
+	}
+	return endpoints, nil
+}
+
+// live returns the endpoints not expired at now, sorted by address
+func live(endpoints map[string]Endpoint, now time.Time) []Endpoint {
+	var result []Endpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Expires.After(now) {
+			result = append(result, endpoint)
+		}
+	}
+	slices.SortFunc(result, func(a, b Endpoint) int {
+		if c := strings.Compare(a.Address, b.Address); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// sameEndpoints reports whether two sorted lists hold the same endpoints,
+// ignoring their expiry
+func sameEndpoints(a, b []Endpoint) bool {
+	return slices.EqualFunc(a, b, func(x, y Endpoint) bool {
+		return x.ID == y.ID && x.Address == y.Address
+	})
+}
+
+// checkName checks a service name
+func checkName(name string) error {
+	if name == "" {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+// newID returns a random registration id
+func newID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("registry: failed to generate id: %v", err))
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+)
+
+func startRing(t *testing.T, address string) *chord.Node {
+	node := chord.NewNode(address, nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	return node
+}
+
+func TestRegisterLookup(t *testing.T) {
+	registry := New(startRing(t, "localhost:8585"))
+	ctx := context.Background()
+
+	first, err := registry.Register(ctx, "api", "10.0.0.2:80", time.Minute)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := registry.Register(ctx, "api", "10.0.0.1:80", time.Minute); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	endpoints, err := registry.Lookup(ctx, "api")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].Address != "10.0.0.1:80" || endpoints[1].Address != "10.0.0.2:80" {
+		t.Fatalf("Expected both endpoints sorted by address, got %+v", endpoints)
+	}
+
+	if err := first.Deregister(ctx); err != nil {
+		t.Fatalf("Deregister failed: %v", err)
+	}
+	endpoints, _ = registry.Lookup(ctx, "api")
+	if len(endpoints) != 1 || endpoints[0].Address != "10.0.0.1:80" {
+		t.Errorf("Expected only 10.0.0.1:80 after deregistering, got %+v", endpoints)
+	}
+
+	if endpoints, err := registry.Lookup(ctx, "unknown"); err != nil || len(endpoints) != 0 {
+		t.Errorf("Expected no endpoints of an unknown service, got %+v, %v", endpoints, err)
+	}
+	if _, err := registry.Register(ctx, "", "x", time.Minute); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	registry := New(startRing(t, "localhost:8586"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kept, err := registry.Register(ctx, "db", "10.0.0.1:5432", 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	go kept.KeepAlive(ctx)
+	if _, err := registry.Register(ctx, "db", "10.0.0.2:5432", 300*time.Millisecond); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	changes := registry.Watch(ctx, "db", 50*time.Millisecond)
+	if initial := <-changes; len(initial) != 2 {
+		t.Fatalf("Expected the watch to start with 2 endpoints, got %+v", initial)
+	}
+	select {
+	case endpoints := <-changes:
+		if len(endpoints) != 1 || endpoints[0].Address != "10.0.0.1:5432" {
+			t.Errorf("Expected only the kept alive endpoint, got %+v", endpoints)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the watch to report the expiry")
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	endpoints, err := registry.Lookup(ctx, "db")
+	if err != nil || len(endpoints) != 1 {
+		t.Errorf("Expected the heartbeat to keep the endpoint alive, got %+v, %v", endpoints, err)
+	}
+
+	cancel()
+	for range changes {
+	}
+}
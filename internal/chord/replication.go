@@ -0,0 +1,209 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetReplication sets how many nodes hold each key: the owner plus its first
+// factor-1 successors, which receive a copy of every write. A factor of 1
+// (the default) disables replication. It must be called before the node
+// starts serving requests.
+func (n *Node) SetReplication(factor int) {
+	if factor < 1 {
+		factor = 1
+	}
+	if factor > SuccessorListSize+1 {
+		factor = SuccessorListSize + 1
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.replication = factor
+}
+
+// Replication returns the replication factor
+func (n *Node) Replication() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.replication
+}
+
+// replicaTargets returns the successors that hold copies of the keys this
+// node owns
+func (n *Node) replicaTargets() []*NodeInfo {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	var targets []*NodeInfo
+	for _, succ := range n.successorList {
+		if len(targets) == n.replication-1 {
+			break
+		}
+		if succ.Address != n.address {
+			targets = append(targets, succ)
+		}
+	}
+	return targets
+}
+
+// replicaCandidates guesses the replicas of the keys owned by owner from
+// this node's own view of the ring: the known nodes that follow owner,
+// closest first
+func (n *Node) replicaCandidates(owner *NodeInfo) []*NodeInfo {
+	factor := n.Replication()
+	if factor <= 1 {
+		return nil
+	}
+
+	peers := n.Peers(FingerTableSize)
+	known := append([]*NodeInfo{peers.Self}, peers.Successors...)
+	known = append(known, peers.Fingers...)
+	if peers.Predecessor != nil {
+		known = append(known, peers.Predecessor)
+	}
+
+	seen := map[string]bool{owner.Address: true}
+	var candidates []*NodeInfo
+	for _, node := range known {
+		if node == nil || seen[node.Address] {
+			continue
+		}
+		seen[node.Address] = true
+		candidates = append(candidates, node)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return owner.ID.Distance(candidates[i].ID).Cmp(owner.ID.Distance(candidates[j].ID)) < 0
+	})
+	if len(candidates) > factor-1 {
+		candidates = candidates[:factor-1]
+	}
+	return candidates
+}
+
+// replicateKeys copies the current entries of keys to this node's replicas.
+// Failures are logged; a replica that misses a write serves it stale until
+// the next write to the key reaches it. While the node is paused the keys
+// are queued and replicated on Resume.
+func (n *Node) replicateKeys(ctx context.Context, keys []string) {
+	if n.deferReplication(keys) {
+		return
+	}
+	targets := n.replicaTargets()
+	if len(targets) == 0 {
+		return
+	}
+
+	n.dataMu.RLock()
+	entries := make([]*pb.StoredEntry, 0, len(keys))
+	for _, key := range keys {
+		e, ok, err := n.storage.Get(key)
+		if err != nil {
+			log.Printf("Node %s: failed to read %q for replication: %v", n.id.Short(), key, err)
+			continue
+		}
+		if ok {
+			entries = append(entries, toProtoEntry(key, e))
+		}
+	}
+	n.dataMu.RUnlock()
+	if len(entries) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target *NodeInfo) {
+			defer wg.Done()
+			err := n.RetryPolicies().Transfer.Do(ctx, func(int) error {
+				return n.remoteReplicate(ctx, target.Address, entries)
+			})
+			if err != nil {
+				log.Printf("Node %s: failed to replicate %d keys to %s: %v",
+					n.id.Short(), len(entries), target.Address, err)
+			}
+		}(target)
+	}
+	wg.Wait()
+}
+
+// storeReplicas applies replicated entries, keeping whichever version of a
+// key is newer. Keys this node owns are skipped: its own copy is the
+// authoritative one. A tombstone past the horizon loses to any entry (see
+// tombstones.go).
+func (n *Node) storeReplicas(entries []*pb.StoredEntry) error {
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	policy, now := n.Tombstones(), time.Now()
+	for _, entry := range entries {
+		if n.checkOwned(entry.Key, false) == nil {
+			continue
+		}
+		current, ok, err := n.storage.Get(entry.Key)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", entry.Key, err)
+		}
+		if ok && current.Version >= entry.Version && !policy.reapable(entry.Key, current, now) {
+			continue
+		}
+		if err := n.reserveStorage([]*pb.KeyValue{{Key: entry.Key, Value: entry.Value}}, false); err != nil {
+			return err
+		}
+		if err := n.storage.Put(entry.Key, fromProtoEntry(entry)); err != nil {
+			return fmt.Errorf("failed to write %q: %w", entry.Key, err)
+		}
+	}
+	return nil
+}
+
+// remoteReplicate sends entries to the replica at address
+func (n *Node) remoteReplicate(ctx context.Context, address string, entries []*pb.StoredEntry) error {
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.Replicate(ctx, &pb.ReplicateRequest{
+		Owner:   toProtoNode(n.GetNodeInfo()),
+		Entries: entries,
+	})
+	if err != nil {
+		return fromStatus(address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("replicate to %s failed: %s", address, resp.Error)
+	}
+	return nil
+}
+
+// Replicate stores copies of entries owned by a predecessor
+func (n *Node) Replicate(ctx context.Context, req *pb.ReplicateRequest) (*pb.ReplicateResponse, error) {
+	n.countMessage()
+
+	for _, entry := range req.Entries {
+		if entry.Key == "" {
+			return nil, status.Error(codes.InvalidArgument, "empty key in replicated entries")
+		}
+	}
+
+	if err := n.storeReplicas(req.Entries); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ReplicateResponse{Success: true}, nil
+}
@@ -0,0 +1,252 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBan is how long a peer is first blacklisted for
+	DefaultBan = 5 * time.Second
+	// DefaultMaxBan caps the ban of a peer blacklisted again and again
+	DefaultMaxBan = 5 * time.Minute
+)
+
+// ReputationPolicy configures when a node blacklists peers from routing.
+// A peer that fails RPCs or answers them with malformed responses Strikes
+// times in a row is left out of the fingers and alternates lookups are sent
+// to for a ban. When the ban ends the peer is pinged: if it answers, it is
+// routed to again, otherwise it is banned for twice as long.
+type ReputationPolicy struct {
+	// Strikes is the number of consecutive failures that blacklists a peer.
+	// Zero disables blacklisting.
+	Strikes int
+	// Ban is how long a first blacklisting lasts; each one that follows
+	// within MaxBan of the last doubles it, up to MaxBan
+	Ban    time.Duration
+	MaxBan time.Duration
+}
+
+// BlacklistedPeer is a peer left out of routing
+type BlacklistedPeer struct {
+	Address string
+	// Until is when the peer is next pinged to see if it recovered
+	Until time.Time
+	// Bans is the number of times in a row the peer was blacklisted
+	Bans int
+	// Reason is the failure that blacklisted the peer
+	Reason string
+}
+
+// ReputationStats counts what blacklisting did
+type ReputationStats struct {
+	// Blacklisted is the number of times peers were blacklisted
+	Blacklisted int64
+	// Skipped is the number of times routing passed over a blacklisted peer
+	Skipped int64
+	// Probes is the number of pings sent to peers whose ban ended, and
+	// Recovered the number of them answered
+	Probes    int64
+	Recovered int64
+}
+
+// reputation is the standing of the peers this node routes through
+type reputation struct {
+	mu     sync.Mutex
+	policy ReputationPolicy
+	peers  map[string]*peerReputation
+	stats  ReputationStats
+}
+
+// peerReputation is a peer's entry in the reputation table
+type peerReputation struct {
+	strikes int
+	bans    int
+	// until is when the ban ends, zero if the peer is not blacklisted
+	until time.Time
+	// lifted is when the last ban ended
+	lifted  time.Time
+	probing bool
+	reason  error
+}
+
+// SetReputation sets when this node blacklists peers from routing. Peers
+// are not blacklisted by default.
+func (n *Node) SetReputation(policy ReputationPolicy) {
+	if policy.Ban <= 0 {
+		policy.Ban = DefaultBan
+	}
+	if policy.MaxBan < policy.Ban {
+		policy.MaxBan = max(DefaultMaxBan, policy.Ban)
+	}
+
+	n.reputation.mu.Lock()
+	defer n.reputation.mu.Unlock()
+
+	n.reputation.policy = policy
+	if policy.Strikes <= 0 {
+		n.reputation.peers = nil
+	}
+}
+
+// ReputationStats returns counters for blacklisted peers
+func (n *Node) ReputationStats() ReputationStats {
+	n.reputation.mu.Lock()
+	defer n.reputation.mu.Unlock()
+
+	return n.reputation.stats
+}
+
+// Blacklist returns the peers currently left out of routing, by address
+func (n *Node) Blacklist() []BlacklistedPeer {
+	n.reputation.mu.Lock()
+	defer n.reputation.mu.Unlock()
+
+	var peers []BlacklistedPeer
+	for address, peer := range n.reputation.peers {
+		if peer.until.IsZero() {
+			continue
+		}
+		entry := BlacklistedPeer{Address: address, Until: peer.until, Bans: peer.bans}
+		if peer.reason != nil {
+			entry.Reason = peer.reason.Error()
+		}
+		peers = append(peers, entry)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Address < peers[j].Address })
+	return peers
+}
+
+// observeReputation records the outcome of a contact with the peer at
+// address: failing to reach it is a strike, any answer clears them
+func (n *Node) observeReputation(address string, err error) {
+	if errors.Is(err, ErrPeerUnreachable) {
+		n.strike(address, err)
+		return
+	}
+
+	r := &n.reputation
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peer, ok := r.peers[address]
+	if !ok || !peer.until.IsZero() {
+		return
+	}
+	if peer.bans == 0 {
+		// Only peers banned before need remembering
+		delete(r.peers, address)
+		return
+	}
+	peer.strikes = 0
+}
+
+// reportMalformed records a response from the peer at address that could
+// not be used
+func (n *Node) reportMalformed(address string, err error) {
+	log.Printf("Node %s: malformed response from %s: %v", n.id.Short(), address, err)
+	n.strike(address, err)
+}
+
+// strike counts a failure of the peer at address, blacklisting it after
+// the policy's number in a row
+func (n *Node) strike(address string, err error) {
+	r := &n.reputation
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.policy.Strikes <= 0 {
+		return
+	}
+	peer, ok := r.peers[address]
+	if !ok {
+		if r.peers == nil {
+			r.peers = make(map[string]*peerReputation)
+		}
+		peer = &peerReputation{}
+		r.peers[address] = peer
+	}
+	peer.strikes++
+	peer.reason = err
+	if peer.until.IsZero() && peer.strikes >= r.policy.Strikes {
+		n.banLocked(address, peer)
+	}
+}
+
+// banLocked blacklists a peer, for twice as long as last time if its last
+// ban ended within MaxBan. Called with reputation.mu held.
+func (n *Node) banLocked(address string, peer *peerReputation) {
+	r := &n.reputation
+	now := time.Now()
+	if !peer.lifted.IsZero() && now.Sub(peer.lifted) > r.policy.MaxBan {
+		peer.bans = 0
+	}
+	peer.bans++
+	ban := r.policy.Ban
+	for i := 1; i < peer.bans && ban < r.policy.MaxBan; i++ {
+		ban *= 2
+	}
+	ban = min(ban, r.policy.MaxBan)
+	peer.until = now.Add(ban)
+	r.stats.Blacklisted++
+	log.Printf("Node %s: blacklisting %s from routing for %v after %d failures: %v",
+		n.id.Short(), address, ban, peer.strikes, peer.reason)
+}
+
+// blacklisted reports whether routing should pass over the peer at
+// address. A peer whose ban ended stays blacklisted until a ping shows it
+// recovered.
+func (n *Node) blacklisted(address string) bool {
+	r := &n.reputation
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peer, ok := r.peers[address]
+	if !ok || peer.until.IsZero() {
+		return false
+	}
+	r.stats.Skipped++
+	if time.Now().Before(peer.until) || peer.probing {
+		return true
+	}
+	peer.probing = true
+	r.stats.Probes++
+	go n.probeBlacklisted(address)
+	return true
+}
+
+// probeBlacklisted pings a peer whose ban ended, routing to it again if it
+// answers and banning it again otherwise
+func (n *Node) probeBlacklisted(address string) {
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+	err := n.remotePing(ctx, address)
+
+	r := &n.reputation
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	peer, ok := r.peers[address]
+	if !ok {
+		return
+	}
+	peer.probing = false
+	if n.ctx.Err() != nil {
+		// The node stopped; the ping says nothing about the peer
+		return
+	}
+	if err != nil {
+		peer.reason = err
+		n.banLocked(address, peer)
+		return
+	}
+	peer.strikes = 0
+	peer.until = time.Time{}
+	peer.lifted = time.Now()
+	r.stats.Recovered++
+	log.Printf("Node %s: %s answers again, routing to it", n.id.Short(), address)
+}
@@ -0,0 +1,104 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestBlacklistRecovers(t *testing.T) {
+	nodes := make([]*Node, 2)
+	for i := range nodes {
+		addr := []string{"localhost:8663", "localhost:8664"}[i]
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+	}
+	node, peer := nodes[0], nodes[1].GetAddress()
+	node.SetReputation(ReputationPolicy{Strikes: 2, Ban: 100 * time.Millisecond, MaxBan: time.Second})
+
+	// Every finger points at the peer, which precedes the key
+	node.mu.Lock()
+	for i := range node.fingers {
+		node.fingers[i] = nodes[1].GetNodeInfo()
+	}
+	node.mu.Unlock()
+	key := nodes[1].GetID().AddPowerOfTwo(0)
+	if hop := node.closestPrecedingFinger(key); hop.Address != peer {
+		t.Fatalf("Expected the lookup to go to %s, got %s", peer, hop.Address)
+	}
+
+	// Malformed responses count as strikes like failed RPCs
+	node.reportMalformed(peer, errors.New("invalid node ID"))
+	if node.blacklisted(peer) {
+		t.Fatal("Peer blacklisted after one strike")
+	}
+	node.reportMalformed(peer, errors.New("invalid node ID"))
+	if !node.blacklisted(peer) {
+		t.Fatal("Peer not blacklisted after two strikes")
+	}
+	if list := node.Blacklist(); len(list) != 1 || list[0].Address != peer || list[0].Bans != 1 {
+		t.Fatalf("Unexpected blacklist: %+v", list)
+	}
+	if hop := node.closestPrecedingFinger(key); hop.Address == peer {
+		t.Error("Lookup routed to a blacklisted peer")
+	}
+
+	// Once the ban ends, a ping shows the peer recovered
+	time.Sleep(150 * time.Millisecond)
+	if !node.blacklisted(peer) {
+		t.Fatal("Peer routed to before the recovery probe answered")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for node.blacklisted(peer) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if node.blacklisted(peer) {
+		t.Fatalf("Peer still blacklisted after answering the probe: %+v", node.Blacklist())
+	}
+	stats := node.ReputationStats()
+	if stats.Blacklisted != 1 || stats.Probes != 1 || stats.Recovered != 1 {
+		t.Errorf("Unexpected reputation stats: %+v", stats)
+	}
+}
+
+func TestBlacklistBacksOff(t *testing.T) {
+	node := NewNode("localhost:8665", hash.NewHashFromString("localhost:8665"))
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	node.SetLivenessTTL(0)
+	node.SetReputation(ReputationPolicy{Strikes: 2, Ban: 100 * time.Millisecond, MaxBan: time.Second})
+
+	// Nothing listens on the peer's port, so every ping fails
+	const dead = "localhost:8666"
+	for i := 0; i < 2; i++ {
+		if _, err := node.ProbePeer(context.Background(), dead); !errors.Is(err, ErrPeerUnreachable) {
+			t.Fatalf("Expected the dead peer to be unreachable, got %v", err)
+		}
+	}
+	if !node.blacklisted(dead) {
+		t.Fatal("Dead peer not blacklisted")
+	}
+
+	// A failed recovery probe bans the peer for twice as long
+	time.Sleep(150 * time.Millisecond)
+	node.blacklisted(dead)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if list := node.Blacklist(); len(list) == 1 && list[0].Bans == 2 {
+			if ban := time.Until(list[0].Until); ban <= 100*time.Millisecond || ban > 200*time.Millisecond {
+				t.Errorf("Expected a second ban of 200ms, %v left", ban)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Dead peer not banned again after its probe: %+v", node.Blacklist())
+}
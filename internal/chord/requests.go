@@ -0,0 +1,169 @@
+package chord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys carrying a Request between nodes
+const (
+	clientMetadataKey      = "chord-client"
+	traceIDMetadataKey     = "chord-trace-id"
+	consistencyMetadataKey = "chord-consistency"
+)
+
+// Consistency is the read consistency a request asks for
+type Consistency string
+
+const (
+	// ConsistencyEventual reads whichever copy the node's replica selector
+	// picks, possibly a stale replica; it is the default
+	ConsistencyEventual Consistency = "eventual"
+	// ConsistencyStrong reads from the key's owner only, and fails instead
+	// of serving stale reads from an isolated node
+	ConsistencyStrong Consistency = "strong"
+)
+
+// Request describes what an RPC or API call is made for: who asked, the
+// trace it belongs to and the consistency asked for. It travels in the
+// context through the node's API and middleware, and as gRPC metadata to
+// the peers a node calls on the request's behalf.
+type Request struct {
+	// Client identifies the caller, such as a service or user name
+	Client string
+	// TraceID correlates the RPCs and logs of one request across nodes
+	TraceID string
+	// Consistency is empty for ConsistencyEventual
+	Consistency Consistency
+}
+
+// requestKey is the context key of a Request
+type requestKey struct{}
+
+// WithRequest returns a context carrying req
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFrom returns the Request ctx carries. Every RPC served by a node
+// carries one, with a trace ID generated if the caller sent none.
+func RequestFrom(ctx context.Context) (Request, bool) {
+	req, ok := ctx.Value(requestKey{}).(Request)
+	return req, ok
+}
+
+// NewTraceID returns a random trace ID
+func NewTraceID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("chord: failed to generate trace id: %v", err))
+	}
+	return hex.EncodeToString(id)
+}
+
+// requestConsistency returns the consistency ctx asks for
+func requestConsistency(ctx context.Context) Consistency {
+	if req, ok := RequestFrom(ctx); ok && req.Consistency != "" {
+		return req.Consistency
+	}
+	return ConsistencyEventual
+}
+
+// incomingRequest builds the Request of an incoming RPC from its metadata
+func incomingRequest(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	req := Request{
+		Client:      first(clientMetadataKey),
+		TraceID:     first(traceIDMetadataKey),
+		Consistency: Consistency(first(consistencyMetadataKey)),
+	}
+	switch req.Consistency {
+	case "", ConsistencyEventual, ConsistencyStrong:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown consistency %q", req.Consistency)
+	}
+	if req.TraceID == "" {
+		req.TraceID = NewTraceID()
+	}
+	return WithRequest(ctx, req), nil
+}
+
+// outgoingRequest adds the Request ctx carries, if any, to the metadata of
+// an outgoing RPC
+func outgoingRequest(ctx context.Context) context.Context {
+	req, ok := RequestFrom(ctx)
+	if !ok {
+		return ctx
+	}
+	var pairs []string
+	if req.Client != "" {
+		pairs = append(pairs, clientMetadataKey, req.Client)
+	}
+	if req.TraceID != "" {
+		pairs = append(pairs, traceIDMetadataKey, req.TraceID)
+	}
+	if req.Consistency != "" {
+		pairs = append(pairs, consistencyMetadataKey, string(req.Consistency))
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// requestStream is a server stream whose context carries the Request
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context with the Request
+func (s *requestStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestMiddleware reads the Request of incoming RPCs into their context
+// and sends the Request of outgoing ones as metadata. The node installs it
+// ahead of the user's middleware, so that every middleware sees it.
+var requestMiddleware = Middleware{
+	UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := incomingRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	},
+	StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := incomingRequest(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &requestStream{ServerStream: ss, ctx: ctx})
+	},
+	UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequest(ctx), method, req, reply, cc, opts...)
+	},
+	StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequest(ctx), desc, cc, method, opts...)
+	},
+}
+
+// RequestDialOptions returns dial options that send the Request in the
+// context of every call as metadata, for clients that dial nodes directly
+func RequestDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(requestMiddleware.UnaryClient),
+		grpc.WithChainStreamInterceptor(requestMiddleware.StreamClient),
+	}
+}
@@ -0,0 +1,82 @@
+package chord
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestRecorder is middleware keeping the Request of the last RPC served
+type requestRecorder struct {
+	mu   sync.Mutex
+	last Request
+}
+
+func (r *requestRecorder) middleware() Middleware {
+	return Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			r.mu.Lock()
+			r.last, _ = RequestFrom(ctx)
+			r.mu.Unlock()
+			return handler(ctx, req)
+		},
+	}
+}
+
+func (r *requestRecorder) request() Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+func TestRequestPropagation(t *testing.T) {
+	recorder := &requestRecorder{}
+	nodes := make([]*Node, 2)
+	for i, addr := range []string{"localhost:8464", "localhost:8465"} {
+		nodes[i] = NewNode(addr, nil)
+		nodes[i].Use(recorder.middleware())
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node: %v", err)
+		}
+		t.Cleanup(nodes[i].Stop)
+	}
+
+	// A node calling a peer on behalf of a request passes it on
+	want := Request{Client: "test", TraceID: "trace-1", Consistency: ConsistencyStrong}
+	ctx := WithRequest(context.Background(), want)
+	if _, _, err := nodes[0].RemoteMembershipHistory(ctx, nodes[1].GetAddress(), 0); err != nil {
+		t.Fatalf("RemoteMembershipHistory failed: %v", err)
+	}
+	if got := recorder.request(); got != want {
+		t.Errorf("Expected the peer to see %+v, got %+v", want, got)
+	}
+
+	conn, err := grpc.NewClient(nodes[1].GetAddress(), append(RequestDialOptions(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewChordServiceClient(conn)
+
+	// A request without a trace gets one
+	if _, err := client.Ping(context.Background(), &pb.PingRequest{}); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if got := recorder.request(); got.TraceID == "" || got.Client != "" {
+		t.Errorf("Expected a generated trace ID only, got %+v", got)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), consistencyMetadataKey, "linearizable")
+	if _, err := client.Ping(ctx, &pb.PingRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown consistency, got %v", err)
+	}
+}
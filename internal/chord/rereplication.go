@@ -0,0 +1,237 @@
+package chord
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultReReplicationDelay is how long a node waits after its replica set
+// changes before copying its keys to the new replicas
+const DefaultReReplicationDelay = 30 * time.Second
+
+// ReReplicationPolicy configures how a node restores the replication
+// factor of the keys it owns. When a replica is declared dead, the next
+// successor takes its place in the replica set without holding any of the
+// owner's keys; likewise, a node that takes over the range of a failed
+// predecessor owns keys its replicas never received. After Delay, the owner
+// copies every key it owns to the replicas that lack them. If the set is
+// back to what it was by then, such as after a node restarted, nothing is
+// copied.
+type ReReplicationPolicy struct {
+	// Delay is how long the replica set must stay changed before keys are
+	// copied, so transient failures are ridden out. Zero disables
+	// re-replication.
+	Delay time.Duration
+}
+
+// reReplication tracks the replica set of a node and the pending round
+type reReplication struct {
+	mu     sync.Mutex
+	policy ReReplicationPolicy
+	// replicas holds the addresses of the replica set last seen, nil until
+	// the node has replicas
+	replicas map[string]bool
+	// before is the replica set when the pending round was scheduled;
+	// replicas not in it get every key
+	before map[string]bool
+	// adopted is set when the node took over a failed predecessor's range,
+	// so every replica gets every key
+	adopted bool
+	timer   *time.Timer
+}
+
+// SetReReplication sets how the node restores the replication factor after
+// its replica set changes
+func (n *Node) SetReReplication(policy ReReplicationPolicy) {
+	n.reReplication.mu.Lock()
+	defer n.reReplication.mu.Unlock()
+
+	n.reReplication.policy = policy
+	if policy.Delay <= 0 && n.reReplication.timer != nil {
+		n.reReplication.timer.Stop()
+		n.reReplication.timer = nil
+		n.instruments.Load().reReplicationPending.Set(0)
+	}
+}
+
+// ReReplication returns the policy set with SetReReplication
+func (n *Node) ReReplication() ReReplicationPolicy {
+	n.reReplication.mu.Lock()
+	defer n.reReplication.mu.Unlock()
+
+	return n.reReplication.policy
+}
+
+// watchReplicas compares the replica set with the one last seen and
+// schedules a round when a node has joined it. It is called after every
+// stabilization round.
+func (n *Node) watchReplicas() {
+	targets := n.replicaTargets()
+	current := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		current[target.Address] = true
+	}
+
+	r := &n.reReplication
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.replicas
+	if len(current) > 0 {
+		r.replicas = current
+	}
+	if previous == nil {
+		// The first replicas of a node hold what they need from the writes
+		// and hand-offs that made them replicas
+		return
+	}
+	for address := range current {
+		if !previous[address] {
+			n.scheduleReReplicationLocked(previous)
+			return
+		}
+	}
+}
+
+// adoptedRange schedules a round copying every owned key, after the node
+// took over the range of a failed predecessor. It does not take the node's
+// mu, which the caller may hold.
+func (n *Node) adoptedRange() {
+	r := &n.reReplication
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replicas == nil {
+		// Without replicas there is nothing to restore
+		return
+	}
+	r.adopted = true
+	n.scheduleReReplicationLocked(r.replicas)
+}
+
+// scheduleReReplicationLocked starts or restarts the delay of a round. The
+// replica set at the first change of a series is kept as its baseline. The
+// caller must hold reReplication.mu.
+func (n *Node) scheduleReReplicationLocked(before map[string]bool) {
+	r := &n.reReplication
+	if r.policy.Delay <= 0 {
+		r.adopted = false
+		return
+	}
+	if r.timer != nil {
+		r.timer.Reset(r.policy.Delay)
+		return
+	}
+	r.before = before
+	r.timer = time.AfterFunc(r.policy.Delay, n.reReplicate)
+	n.instruments.Load().reReplicationPending.Set(1)
+}
+
+// reReplicate copies the owned keys to the replicas that joined the set
+// since the round was scheduled, or to all of them after adopting a range
+func (n *Node) reReplicate() {
+	if n.ctx.Err() != nil {
+		return
+	}
+	if n.paused() {
+		n.reReplication.mu.Lock()
+		if n.reReplication.timer != nil {
+			n.reReplication.timer.Reset(n.reReplication.policy.Delay)
+		}
+		n.reReplication.mu.Unlock()
+		return
+	}
+
+	r := &n.reReplication
+	r.mu.Lock()
+	before, adopted := r.before, r.adopted
+	r.timer, r.before, r.adopted = nil, nil, false
+	r.mu.Unlock()
+	instruments := n.instruments.Load()
+	instruments.reReplicationPending.Set(0)
+
+	var fill []*NodeInfo
+	for _, target := range n.replicaTargets() {
+		if adopted || !before[target.Address] {
+			fill = append(fill, target)
+		}
+	}
+	if len(fill) == 0 {
+		log.Printf("Node %s: replica set restored, nothing to re-replicate", n.id.Short())
+		return
+	}
+
+	keys := n.ownedKeys()
+	instruments.reReplications.Add(1)
+	for _, target := range fill {
+		copied, err := n.copyKeys(target, keys)
+		instruments.reReplicatedKeys.Add(int64(copied))
+		if err != nil {
+			instruments.reReplicationErrors.Add(1)
+			log.Printf("Node %s: re-replication to %s failed after %d of %d keys: %v",
+				n.id.Short(), target.Address, copied, len(keys), err)
+			continue
+		}
+		log.Printf("Node %s: re-replicated %d keys to %s", n.id.Short(), copied, target.Address)
+	}
+}
+
+// ownedKeys returns the sorted keys of the owned range
+func (n *Node) ownedKeys() []string {
+	start, owned := n.OwnedRange()
+	if !owned {
+		return nil
+	}
+
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if start.Equal(n.id) || n.KeyID(key).InRange(start, n.id) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil {
+		log.Printf("Node %s: failed to list owned keys: %v", n.id.Short(), err)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// copyKeys sends the entries of keys to the replica at target in chunks,
+// paced by the transfer policy (see transfer.go), and returns how many it
+// copied
+func (n *Node) copyKeys(target *NodeInfo, keys []string) (int, error) {
+	maxEntries, maxBytes := n.transfers.chunkLimits()
+	copied := 0
+	for pos := 0; pos < len(keys); {
+		chunk, next, size, err := n.nextChunk(keys, pos, maxEntries, maxBytes)
+		if err != nil {
+			return copied, err
+		}
+		pos = next
+		if len(chunk.Entries) == 0 {
+			continue
+		}
+
+		if wait := n.transfers.reserve(size); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-n.ctx.Done():
+				return copied, n.ctx.Err()
+			}
+		}
+		err = n.RetryPolicies().Transfer.Do(n.ctx, func(int) error {
+			return n.remoteReplicate(n.ctx, target.Address, chunk.Entries)
+		})
+		if err != nil {
+			return copied, err
+		}
+		copied += len(chunk.Entries)
+	}
+	return copied, nil
+}
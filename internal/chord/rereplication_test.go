@@ -0,0 +1,97 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+)
+
+func TestReReplicationAfterFailure(t *testing.T) {
+	nodes := startTestRing(t, 8622, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+		node.SetReReplication(ReReplicationPolicy{Delay: 50 * time.Millisecond})
+	}
+	owner := nodes[0]
+	sink := metrics.NewMemory()
+	owner.SetMetrics(sink)
+	for _, node := range nodes {
+		node.stabilize()
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 30; i++ {
+		if err := owner.StoreValue(ctx, fmt.Sprintf("rerep-%d", i), []byte("value")); err != nil {
+			t.Fatalf("StoreValue failed: %v", err)
+		}
+	}
+	keys := owner.ownedKeys()
+	if len(keys) == 0 {
+		t.Fatal("Expected the owner to own some keys")
+	}
+
+	// The replica fails; the next successor takes its place
+	failed := owner.GetSuccessor()
+	var next *Node
+	for _, node := range nodes {
+		switch node.GetAddress() {
+		case failed.Address:
+			node.Stop()
+		case owner.GetAddress():
+		default:
+			next = node
+		}
+	}
+	owner.stabilize()
+	owner.stabilize()
+	if sink.GaugeValue(metrics.ReReplicationPending) != 1 {
+		t.Fatal("Expected a re-replication round to be pending")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sink.CounterValue(metrics.ReReplicatedKeys) < int64(len(keys)) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if rounds, copied := sink.CounterValue(metrics.ReReplications), sink.CounterValue(metrics.ReReplicatedKeys); rounds != 1 || copied != int64(len(keys)) {
+		t.Fatalf("Expected 1 round copying %d keys, got %d rounds copying %d", len(keys), rounds, copied)
+	}
+	for _, key := range keys {
+		if _, ok, _ := next.Storage().Get(key); !ok {
+			t.Errorf("New replica %s misses %q", next.GetAddress(), key)
+		}
+	}
+}
+
+func TestReReplicationRidesOutTransientChange(t *testing.T) {
+	node := NewNode("localhost:0", hash.NewHashFromString("rerep-transient"))
+	node.SetReplication(2)
+	node.SetReReplication(ReReplicationPolicy{Delay: 50 * time.Millisecond})
+	sink := metrics.NewMemory()
+	node.SetMetrics(sink)
+
+	replica := &NodeInfo{ID: hash.NewHashFromString("replica"), Address: "localhost:1"}
+	standIn := &NodeInfo{ID: hash.NewHashFromString("stand-in"), Address: "localhost:2"}
+	setReplica := func(succ *NodeInfo) {
+		node.mu.Lock()
+		node.successorList = []*NodeInfo{succ}
+		node.mu.Unlock()
+		node.watchReplicas()
+	}
+
+	setReplica(replica)
+	setReplica(standIn)
+	if sink.GaugeValue(metrics.ReReplicationPending) != 1 {
+		t.Fatal("Expected a re-replication round to be pending")
+	}
+	// The replica comes back before the delay is over
+	setReplica(replica)
+
+	time.Sleep(200 * time.Millisecond)
+	if sink.GaugeValue(metrics.ReReplicationPending) != 0 || sink.CounterValue(metrics.ReReplications) != 0 {
+		t.Errorf("Expected the round to find the replica set restored, got %d rounds", sink.CounterValue(metrics.ReReplications))
+	}
+}
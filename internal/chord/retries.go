@@ -0,0 +1,110 @@
+package chord
+
+import (
+	"time"
+
+	"chord-dht/internal/retry"
+)
+
+// RetryPolicies are the retry policies of the node's subsystems
+type RetryPolicies struct {
+	// Join asks the bootstrap again while it throttles joins
+	Join retry.Policy
+	// Lookup repeats a lookup whose route failed after routing around
+	// unreachable hops (see lookup.go)
+	Lookup retry.Policy
+	// Transfer repeats hand-off preparation and replica pushes a peer
+	// turned down for now
+	Transfer retry.Policy
+	// Client repeats batched reads and writes turned down by the node they
+	// were sent to, at the owner it pointed to if any
+	Client retry.Policy
+	// Settle repeats a joining node's notification and hand-off while its
+	// successor is still taking over the range itself (see admission.go)
+	Settle retry.Policy
+	// Rejoin tries the last known peers of an isolated node until one lets
+	// it back into the ring (see isolation.go)
+	Rejoin retry.Policy
+}
+
+// DefaultRetryPolicies returns the policies a node starts with. Every policy
+// waits as long as a peer asks with a RetryInfo delay.
+func DefaultRetryPolicies() RetryPolicies {
+	return RetryPolicies{
+		Join: retry.Policy{
+			MaxAttempts:  10,
+			InitialDelay: time.Second,
+			Retryable:    retry.On(ErrJoinThrottled),
+			Hint:         RetryDelay,
+		},
+		Lookup: retry.Policy{
+			MaxAttempts:  3,
+			InitialDelay: 50 * time.Millisecond,
+			Multiplier:   2,
+			MaxDelay:     time.Second,
+			Jitter:       0.2,
+			Retryable:    retry.On(ErrPeerUnreachable, ErrRingUnstable, ErrOverloaded),
+			Hint:         RetryDelay,
+		},
+		Transfer: retry.Policy{
+			MaxAttempts:  3,
+			InitialDelay: handoffRetryDelay,
+			Multiplier:   2,
+			MaxDelay:     2 * time.Second,
+			Jitter:       0.2,
+			Retryable:    retry.On(ErrRangeMoving, ErrOverloaded),
+			Hint:         RetryDelay,
+		},
+		Client: retry.Policy{
+			MaxAttempts:  3,
+			InitialDelay: 50 * time.Millisecond,
+			Multiplier:   2,
+			MaxDelay:     time.Second,
+			Jitter:       0.2,
+			Retryable:    retry.On(ErrNotResponsible, ErrRangeMoving, ErrOverloaded),
+			Hint:         clientRetryDelay,
+		},
+		Settle: retry.Policy{
+			MaxAttempts:  8,
+			InitialDelay: 50 * time.Millisecond,
+			Multiplier:   2,
+			MaxDelay:     time.Second,
+			Jitter:       0.2,
+			Retryable:    retry.On(ErrNotResponsible, ErrRangeMoving),
+			Hint:         RetryDelay,
+		},
+		Rejoin: retry.Policy{
+			MaxAttempts:  retry.Forever,
+			InitialDelay: 500 * time.Millisecond,
+			Multiplier:   2,
+			MaxDelay:     StabilizeInterval,
+			Jitter:       0.2,
+			Hint:         RetryDelay,
+		},
+	}
+}
+
+// SetRetryPolicies replaces the node's retry policies
+func (n *Node) SetRetryPolicies(policies RetryPolicies) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.retries = policies
+}
+
+// RetryPolicies returns the node's retry policies
+func (n *Node) RetryPolicies() RetryPolicies {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.retries
+}
+
+// clientRetryDelay follows an owner hint right away and otherwise waits as
+// long as the peer asked
+func clientRetryDelay(err error) (time.Duration, bool) {
+	if ownerHint(err) != nil {
+		return 0, true
+	}
+	return RetryDelay(err)
+}
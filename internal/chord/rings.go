@@ -0,0 +1,365 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"sync"
+
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultRing is the ring of nodes that name none. Calls that name no ring,
+// such as those of clients unaware of rings, are served by its node.
+const DefaultRing = ""
+
+// ringMetadataKey carries the ring the caller belongs to on every RPC
+// between nodes of a named ring
+const ringMetadataKey = "chord-ring"
+
+// SetRing makes the node a member of the logical ring named ring. The node
+// names its ring on every RPC to peers and refuses RPCs naming another
+// ring, so rings sharing hosts, or bootstraps given by mistake, stay
+// isolated. RPCs naming no ring, such as those of clients, are served. It
+// must be called before Start.
+func (n *Node) SetRing(ring string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.ring = ring
+}
+
+// Ring returns the ring the node belongs to
+func (n *Node) Ring() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.ring
+}
+
+// callerRing returns the ring an incoming RPC names, if any
+func callerRing(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if rings := md.Get(ringMetadataKey); len(rings) > 0 {
+		return rings[0], true
+	}
+	return "", false
+}
+
+// checkRing rejects an incoming RPC naming a ring other than ring
+func (n *Node) checkRing(ctx context.Context, ring string) error {
+	caller, ok := callerRing(ctx)
+	if !ok || caller == ring {
+		return nil
+	}
+	return toStatus(fmt.Errorf("%w: %s is in ring %q, the caller in %q", ErrWrongRing, n.address, ring, caller))
+}
+
+// ringMiddleware names the node's ring on every RPC to a peer and refuses
+// RPCs from peers of other rings. The node installs it ahead of the user's
+// middleware. The caller must hold mu.
+func (n *Node) ringMiddleware() Middleware {
+	ring := n.ring
+	outgoing := func(ctx context.Context) context.Context {
+		if ring == DefaultRing {
+			return ctx
+		}
+		return metadata.AppendToOutgoingContext(ctx, ringMetadataKey, ring)
+	}
+	return Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := n.checkRing(ctx, ring); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := n.checkRing(ss.Context(), ring); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(outgoing(ctx), desc, cc, method, opts...)
+		},
+	}
+}
+
+// Host serves the nodes of several rings on one address, so one process
+// can take part in isolated rings, such as one per tenant. Every ring has
+// its own Node, with its own routing state and storage, listening on a
+// private loopback port and advertising the host's address. The host
+// forwards every RPC it receives to the node of the ring the call names,
+// or of DefaultRing if it names none.
+//
+// Hosted nodes must use NetworkTCP and the grpc maintenance transport, and
+// cannot be behind NAT. Their per-peer rate limits see every call coming
+// from the host.
+type Host struct {
+	listenAddr string
+	address    string
+
+	mu       sync.RWMutex
+	nodes    map[string]*Node
+	backends map[string]*grpc.ClientConn
+	listener net.Listener
+	server   *grpc.Server
+	wg       sync.WaitGroup
+}
+
+// NewHost creates a host listening on listenAddr and advertising
+// advertiseAddr to peers
+func NewHost(listenAddr, advertiseAddr string) *Host {
+	return &Host{
+		listenAddr: listenAddr,
+		address:    advertiseAddr,
+		nodes:      make(map[string]*Node),
+		backends:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+// AddRing creates the host's node of ring with id, or an ID derived from
+// the host's address if nil. The node is configured like any other, then
+// started by Start; it joins its ring with Join once the host is started.
+func (h *Host) AddRing(ring string, id *hash.Hash) (*Node, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.server != nil {
+		return nil, fmt.Errorf("cannot add ring %q to a started host", ring)
+	}
+	if _, ok := h.nodes[ring]; ok {
+		return nil, fmt.Errorf("ring %q is already hosted", ring)
+	}
+	node := NewNodeWithAdvertise("127.0.0.1:0", h.address, id)
+	node.SetRing(ring)
+	h.nodes[ring] = node
+	return node, nil
+}
+
+// Node returns the host's node of ring, or nil
+func (h *Host) Node(ring string) *Node {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.nodes[ring]
+}
+
+// Rings returns the rings the host serves, sorted
+func (h *Host) Rings() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	rings := make([]string, 0, len(h.nodes))
+	for ring := range h.nodes {
+		rings = append(rings, ring)
+	}
+	sort.Strings(rings)
+	return rings
+}
+
+// Start starts every hosted node and serves the host's address
+func (h *Host) Start() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.nodes) == 0 {
+		return fmt.Errorf("host %s serves no ring", h.address)
+	}
+	for ring, node := range h.nodes {
+		if node.network == NetworkQUIC || node.transport.kind == TransportUDP || node.natPolicy() != (NATPolicy{}) {
+			return fmt.Errorf("ring %q: hosted nodes need TCP, the grpc maintenance transport and no NAT traversal", ring)
+		}
+	}
+
+	started := make([]*Node, 0, len(h.nodes))
+	stopAll := func() {
+		for _, node := range started {
+			node.Stop()
+		}
+		h.closeBackends()
+	}
+	for ring, node := range h.nodes {
+		if err := node.Start(); err != nil {
+			stopAll()
+			return fmt.Errorf("ring %q: %w", ring, err)
+		}
+		started = append(started, node)
+		node.mu.RLock()
+		target := node.listener.Addr().String()
+		node.mu.RUnlock()
+		conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			stopAll()
+			return fmt.Errorf("ring %q: %w", ring, err)
+		}
+		h.backends[ring] = conn
+	}
+
+	listener, err := listenAll(h.listenAddr)
+	if err != nil {
+		stopAll()
+		return fmt.Errorf("failed to listen on %s: %w", h.listenAddr, err)
+	}
+	h.listener = listener
+	h.server = grpc.NewServer(grpc.UnknownServiceHandler(h.forward), grpc.ForceServerCodec(rawCodec{}))
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		if err := h.server.Serve(listener); err != nil {
+			log.Printf("Host %s: gRPC server error: %v", h.address, err)
+		}
+	}()
+	log.Printf("Host listening on %s, advertising %s, serving %d rings", listener.Addr(), h.address, len(h.nodes))
+	return nil
+}
+
+// Stop stops serving the host's address and stops every hosted node
+func (h *Host) Stop() {
+	h.mu.Lock()
+	server := h.server
+	h.mu.Unlock()
+	if server != nil {
+		server.Stop()
+		h.wg.Wait()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closeBackends()
+	for _, node := range h.nodes {
+		node.Stop()
+	}
+}
+
+// closeBackends closes the connections to the hosted nodes. The caller
+// must hold mu.
+func (h *Host) closeBackends() {
+	for ring, conn := range h.backends {
+		conn.Close()
+		delete(h.backends, ring)
+	}
+}
+
+// backend returns the connection to the node of ring
+func (h *Host) backend(ring string) (*grpc.ClientConn, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	conn, ok := h.backends[ring]
+	if !ok {
+		return nil, toStatus(fmt.Errorf("%w: %s serves no ring %q", ErrWrongRing, h.address, ring))
+	}
+	return conn, nil
+}
+
+// forward passes a call through to the node of the ring it names, frame by
+// frame, with its headers and the node's reply headers and trailers
+func (h *Host) forward(srv any, ss grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(ss)
+	ring, _ := callerRing(ss.Context())
+	conn, err := h.backend(ring)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+	md, _ := metadata.FromIncomingContext(ctx)
+	headers := metadata.MD{}
+	for key, values := range md {
+		if relayedHeader(key) {
+			headers[key] = values
+		}
+	}
+	ctx = metadata.NewOutgoingContext(ctx, headers)
+	cs, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+
+	// Requests flow to the node until the caller half-closes; a failure
+	// cancels the node's call, which ends the replies below
+	go func() {
+		for {
+			frame := new(rawFrame)
+			if err := ss.RecvMsg(frame); err != nil {
+				if err == io.EOF {
+					cs.CloseSend()
+				} else {
+					cancel()
+				}
+				return
+			}
+			if err := cs.SendMsg(frame); err != nil {
+				return
+			}
+		}
+	}()
+
+	for first := true; ; first = false {
+		frame := new(rawFrame)
+		if err := cs.RecvMsg(frame); err != nil {
+			ss.SetTrailer(cs.Trailer())
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if first {
+			header, err := cs.Header()
+			if err != nil {
+				return err
+			}
+			if err := ss.SendHeader(header); err != nil {
+				return err
+			}
+		}
+		if err := ss.SendMsg(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// rawFrame is an encoded message passed through a host
+type rawFrame struct {
+	payload []byte
+}
+
+// rawCodec passes frames through a host without decoding them. It keeps
+// the name of the proto codec, which encoded them.
+type rawCodec struct{}
+
+// Marshal implements encoding.Codec
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	frame, ok := v.(*rawFrame)
+	if !ok {
+		return nil, fmt.Errorf("cannot pass %T through a host", v)
+	}
+	return frame.payload, nil
+}
+
+// Unmarshal implements encoding.Codec
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	frame, ok := v.(*rawFrame)
+	if !ok {
+		return fmt.Errorf("cannot pass %T through a host", v)
+	}
+	frame.payload = append([]byte(nil), data...)
+	return nil
+}
+
+// Name implements encoding.Codec
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,83 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// startRingNode starts a node of ring on addr and joins it through
+// bootstrap, creating the ring if empty
+func startRingNode(t *testing.T, ring, addr, bootstrap string) (*Node, error) {
+	t.Helper()
+	node := NewNode(addr, nil)
+	node.SetRing(ring)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+	return node, node.Join(bootstrap)
+}
+
+func TestJoinRefusesOtherRing(t *testing.T) {
+	first, err := startRingNode(t, "a", "localhost:8587", "")
+	if err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	if _, err := startRingNode(t, "b", "localhost:8588", first.GetAddress()); !errors.Is(err, ErrWrongRing) {
+		t.Fatalf("Expected ErrWrongRing joining another ring, got %v", err)
+	}
+	if _, err := startRingNode(t, "a", "localhost:8589", first.GetAddress()); err != nil {
+		t.Fatalf("Join of the same ring failed: %v", err)
+	}
+}
+
+func TestHostServesRings(t *testing.T) {
+	hosts := []*Host{NewHost("localhost:8590", "localhost:8590"), NewHost("localhost:8591", "localhost:8591")}
+	rings := []string{DefaultRing, "tenant"}
+	for _, host := range hosts {
+		for _, ring := range rings {
+			if _, err := host.AddRing(ring, nil); err != nil {
+				t.Fatalf("AddRing failed: %v", err)
+			}
+		}
+		if err := host.Start(); err != nil {
+			t.Fatalf("Failed to start host: %v", err)
+		}
+		t.Cleanup(host.Stop)
+	}
+	for _, ring := range rings {
+		if err := hosts[0].Node(ring).Join(""); err != nil {
+			t.Fatalf("Failed to create ring %q: %v", ring, err)
+		}
+		if err := hosts[1].Node(ring).Join("localhost:8590"); err != nil {
+			t.Fatalf("Failed to join ring %q through the host: %v", ring, err)
+		}
+		for round := 0; round < 3; round++ {
+			hosts[0].Node(ring).stabilize()
+			hosts[1].Node(ring).stabilize()
+		}
+		if successor := hosts[0].Node(ring).GetSuccessor(); successor == nil || successor.Address != "localhost:8591" {
+			t.Errorf("Ring %q: expected successor localhost:8591, got %v", ring, successor)
+		}
+	}
+
+	// Each ring stores its own keys
+	ctx := context.Background()
+	if err := hosts[1].Node("tenant").StoreValue(ctx, "key", []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	value, err := hosts[0].Node("tenant").FetchValue(ctx, "key")
+	if err != nil || string(value) != "value" {
+		t.Errorf("FetchValue in the same ring returned %q, %v", value, err)
+	}
+	if _, err := hosts[0].Node(DefaultRing).FetchValue(ctx, "key"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound in another ring, got %v", err)
+	}
+
+	// A ring the host does not serve is refused
+	if _, err := startRingNode(t, "other", "localhost:8592", "localhost:8590"); !errors.Is(err, ErrWrongRing) {
+		t.Errorf("Expected ErrWrongRing joining through a host without the ring, got %v", err)
+	}
+}
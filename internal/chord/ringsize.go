@@ -0,0 +1,70 @@
+package chord
+
+import "math"
+
+// RingSizeEstimate is a node's estimate of how many nodes the ring has,
+// made from its own neighborhood without crawling the ring
+type RingSizeEstimate struct {
+	// Nodes is the estimated number of nodes, at least 1 for this node
+	Nodes int
+	// Exact is set when the successor list wraps around to the
+	// predecessor, so that the node knows every member of the ring
+	Exact bool
+	// Gaps is the number of gaps between consecutive IDs the estimate was
+	// made from; its error shrinks with their square root
+	Gaps int
+	// Gossiped is the number of nodes in the gossiped membership view,
+	// this node included, or 0 with gossip off (see gossip.go). It lags
+	// behind joins and failures but counts nodes rather than estimating.
+	Gossiped int
+}
+
+// EstimateRingSize estimates the number of nodes in the ring from the
+// density of IDs around this node. With uniformly spread IDs, the arc from
+// the predecessor to the last entry of the successor list covers about
+// (gaps / N) of the ring; (gaps - 1) over that fraction estimates N without
+// the upward bias of gaps over it. A ring small enough for the successor
+// list to reach the predecessor is counted exactly. Nothing is sent to
+// other nodes.
+func (n *Node) EstimateRingSize() RingSizeEstimate {
+	estimate := n.estimateFromNeighbors()
+	estimate.Gossiped = len(n.GossipMembers())
+	return estimate
+}
+
+// estimateFromNeighbors estimates the ring size from the IDs of the
+// predecessor and successor list
+func (n *Node) estimateFromNeighbors() RingSizeEstimate {
+	peers := n.Peers(0)
+	successor := n.GetSuccessor()
+	if successor == nil || successor.Address == n.address {
+		// Alone in the ring, or not in one yet
+		return RingSizeEstimate{Nodes: 1, Exact: successor != nil}
+	}
+
+	successors := peers.Successors
+	if len(successors) == 0 {
+		successors = []*NodeInfo{successor}
+	}
+	predecessor := peers.Predecessor
+	if predecessor != nil {
+		for i, succ := range successors {
+			if succ.Address == predecessor.Address {
+				return RingSizeEstimate{Nodes: i + 2, Exact: true, Gaps: i + 2}
+			}
+		}
+	}
+
+	// Without a predecessor the arc starts at this node
+	start, gaps := n.id, len(successors)
+	if predecessor != nil && predecessor.Address != n.address {
+		start, gaps = predecessor.ID, gaps+1
+	}
+	fraction := start.RingFraction(successors[len(successors)-1].ID)
+	if fraction <= 0 {
+		return RingSizeEstimate{Nodes: gaps + 1, Gaps: gaps}
+	}
+	nodes := int(math.Round(float64(max(gaps-1, 1)) / fraction))
+	// The nodes seen are there for certain
+	return RingSizeEstimate{Nodes: max(nodes, gaps+1), Gaps: gaps}
+}
@@ -0,0 +1,62 @@
+package chord
+
+import (
+	"fmt"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+// staticRing returns unstarted nodes at count addresses, each holding the
+// routing state of the static ring of all of them
+func staticRing(t *testing.T, count int) []*Node {
+	nodes := make([]*Node, count)
+	members := make([]*NodeInfo, count)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", 20000+i)
+		nodes[i] = NewNode(addr, hash.GenerateID(addr))
+		members[i] = nodes[i].GetNodeInfo()
+	}
+	for _, node := range nodes {
+		if err := node.JoinStatic(members); err != nil {
+			t.Fatalf("Failed to join static ring: %v", err)
+		}
+	}
+	return nodes
+}
+
+func TestEstimateRingSize(t *testing.T) {
+	alone := NewNode("localhost:20100", nil)
+	if estimate := alone.EstimateRingSize(); estimate.Nodes != 1 || estimate.Exact {
+		t.Errorf("Node outside a ring estimated %+v", estimate)
+	}
+	if err := alone.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	if estimate := alone.EstimateRingSize(); estimate.Nodes != 1 || !estimate.Exact {
+		t.Errorf("Node alone in its ring estimated %+v", estimate)
+	}
+
+	// A successor list reaching the predecessor counts the ring exactly
+	for _, node := range staticRing(t, 3) {
+		if estimate := node.EstimateRingSize(); estimate.Nodes != 3 || !estimate.Exact {
+			t.Errorf("Node %s estimated %+v in a ring of 3", node.GetID().Short(), estimate)
+		}
+	}
+
+	// Larger rings are estimated from the ID density; single estimates are
+	// noisy, their mean close
+	const size = 64
+	nodes := staticRing(t, size)
+	total := 0
+	for _, node := range nodes {
+		estimate := node.EstimateRingSize()
+		if estimate.Exact || estimate.Gaps != SuccessorListSize+1 || estimate.Nodes < estimate.Gaps+1 {
+			t.Fatalf("Unexpected estimate %+v", estimate)
+		}
+		total += estimate.Nodes
+	}
+	if mean := float64(total) / size; mean < size/1.5 || mean > size*1.5 {
+		t.Errorf("Mean estimate %.1f for a ring of %d", mean, size)
+	}
+}
@@ -0,0 +1,298 @@
+package chord
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+const (
+	// ringSnapshotBroadcast is the broadcast kind marking a ring snapshot
+	ringSnapshotBroadcast = "chord.ring-snapshot"
+	// RingSnapshotsKept is the number of recent ring snapshots a node keeps
+	// the pieces of
+	RingSnapshotsKept = 16
+)
+
+// RingSnapshotPiece is a node's part of a ring snapshot: the entries of the
+// key range it owned when the snapshot's marker reached it, written to a
+// file in the node snapshot format
+type RingSnapshotPiece struct {
+	ID   uint64
+	Node *NodeInfo
+	Time time.Time
+	// RangeStart is the exclusive start of the range (RangeStart, Node.ID]
+	// in the piece: the node's own ID if it owned the whole ring, nil if it
+	// owned nothing
+	RangeStart *hash.Hash
+	// File is the path of the piece on the node's host
+	File     string
+	Entries  int
+	Bytes    int64
+	Checksum string
+	// Pending is set while the piece is being written
+	Pending bool
+	// Err is why the piece could not be written
+	Err string
+}
+
+// ringSnapshots holds where a node writes its pieces of ring snapshots and
+// the recent pieces, oldest first
+type ringSnapshots struct {
+	mu     sync.Mutex
+	dir    string
+	pieces []RingSnapshotPiece
+}
+
+// SetRingSnapshotDir sets the directory the node writes its pieces of ring
+// snapshots to, each snapshot in a ring-snapshot-ID subdirectory. The node
+// takes no pieces while it is empty, as it is by default.
+func (n *Node) SetRingSnapshotDir(dir string) {
+	n.ringSnapshots.mu.Lock()
+	defer n.ringSnapshots.mu.Unlock()
+
+	n.ringSnapshots.dir = dir
+}
+
+// RingSnapshotDir returns the directory set with SetRingSnapshotDir
+func (n *Node) RingSnapshotDir() string {
+	n.ringSnapshots.mu.Lock()
+	defer n.ringSnapshots.mu.Unlock()
+
+	return n.ringSnapshots.dir
+}
+
+// RingSnapshotPath returns where the piece of a node goes under dir
+func RingSnapshotPath(dir string, id uint64, node *hash.Hash) string {
+	return filepath.Join(dir, fmt.Sprintf("ring-snapshot-%d", id), node.String()+".snap")
+}
+
+// StartRingSnapshot starts a snapshot of the whole ring: a marker is
+// broadcast through the ring, and every node it reaches copies the entries
+// of the key range it owns as it delivers it, under the data lock, then
+// writes them to its snapshot directory. The pieces are collected with
+// RingSnapshotPiece on every node, such as by the crawler, which ties them
+// together in a manifest. The pieces are taken within the O(log N) rounds of
+// one broadcast, not at one instant; a ring paused for maintenance moves no
+// ranges meanwhile, so its pieces cover the ring exactly once.
+func (n *Node) StartRingSnapshot(ctx context.Context) (uint64, *BroadcastResult, error) {
+	id := uint64(time.Now().UnixNano())
+	payload := binary.BigEndian.AppendUint64(nil, id)
+	result, err := n.Broadcast(ctx, ringSnapshotBroadcast, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, result, nil
+}
+
+// RingSnapshotPiece returns this node's piece of a ring snapshot, if it
+// still keeps it
+func (n *Node) RingSnapshotPiece(id uint64) (RingSnapshotPiece, bool) {
+	n.ringSnapshots.mu.Lock()
+	defer n.ringSnapshots.mu.Unlock()
+
+	for _, piece := range n.ringSnapshots.pieces {
+		if piece.ID == id {
+			return piece, true
+		}
+	}
+	return RingSnapshotPiece{}, false
+}
+
+// takeRingSnapshot copies the entries of the owned range and writes them
+// in the background, so the marker is relayed on without waiting for the
+// disk
+func (n *Node) takeRingSnapshot(id uint64) {
+	dir := n.RingSnapshotDir()
+	if dir == "" {
+		return
+	}
+	piece := RingSnapshotPiece{ID: id, Node: n.GetNodeInfo(), Pending: true}
+	piece.File = RingSnapshotPath(dir, id, piece.Node.ID)
+
+	type item struct {
+		key   string
+		entry Entry
+	}
+	var items []item
+	n.dataMu.RLock()
+	rangeEntries := n.storage.Range
+	sealed, isSealed := n.storage.(SealedStorage)
+	if isSealed {
+		rangeEntries = sealed.RangeSealed
+	}
+	n.own.mu.Lock()
+	if n.own.start != nil {
+		piece.RangeStart = n.own.start.Copy()
+	}
+	err := rangeEntries(func(key string, e Entry) bool {
+		if n.ownsLocked(n.KeyID(key)) {
+			items = append(items, item{key, e})
+		}
+		return true
+	})
+	n.own.mu.Unlock()
+	state := n.RoutingState()
+	n.dataMu.RUnlock()
+	piece.Time = time.Now()
+	if err != nil {
+		piece.Pending, piece.Err = false, fmt.Sprintf("failed to list entries: %v", err)
+	}
+	n.recordRingSnapshotPiece(piece)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		header := snapshotRecord{Format: snapshotFormat, State: state, Sealed: isSealed}
+		err := os.MkdirAll(filepath.Dir(piece.File), 0o755)
+		if err == nil {
+			err = writeFileAtomic(piece.File, func(w io.Writer) error {
+				digest := sha256.New()
+				counted := &countingWriter{w: io.MultiWriter(w, digest)}
+				buffered := bufio.NewWriter(counted)
+				err := writeSnapshot(buffered, header, func(fn func(string, Entry) bool) error {
+					for _, item := range items {
+						if !fn(item.key, item.entry) {
+							break
+						}
+					}
+					return nil
+				})
+				if err == nil {
+					err = buffered.Flush()
+				}
+				piece.Bytes = counted.n
+				piece.Checksum = hex.EncodeToString(digest.Sum(nil))
+				return err
+			})
+		}
+		piece.Pending = false
+		if err != nil {
+			piece.Err = err.Error()
+			log.Printf("Node %s: failed to write ring snapshot %d: %v", n.id.Short(), id, err)
+		} else {
+			piece.Entries = len(items)
+		}
+		n.recordRingSnapshotPiece(piece)
+	}()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write implements io.Writer
+func (c *countingWriter) Write(p []byte) (int, error) {
+	written, err := c.w.Write(p)
+	c.n += int64(written)
+	return written, err
+}
+
+// recordRingSnapshotPiece adds or updates a piece, dropping the oldest
+func (n *Node) recordRingSnapshotPiece(piece RingSnapshotPiece) {
+	n.ringSnapshots.mu.Lock()
+	defer n.ringSnapshots.mu.Unlock()
+
+	for i := range n.ringSnapshots.pieces {
+		if n.ringSnapshots.pieces[i].ID == piece.ID {
+			n.ringSnapshots.pieces[i] = piece
+			return
+		}
+	}
+	if len(n.ringSnapshots.pieces) == RingSnapshotsKept {
+		n.ringSnapshots.pieces = n.ringSnapshots.pieces[1:]
+	}
+	n.ringSnapshots.pieces = append(n.ringSnapshots.pieces, piece)
+}
+
+// handleRingSnapshotBroadcasts registers the handler taking a piece when a
+// ring snapshot's marker arrives
+func (n *Node) handleRingSnapshotBroadcasts() {
+	n.HandleBroadcast(ringSnapshotBroadcast, func(msg *BroadcastMessage) {
+		if len(msg.Payload) != 8 {
+			log.Printf("Node %s: dropping malformed ring snapshot marker from %s", n.id.Short(), msg.Origin.Address)
+			return
+		}
+		n.takeRingSnapshot(binary.BigEndian.Uint64(msg.Payload))
+	})
+}
+
+// GetRingSnapshotPiece returns this node's piece of a ring snapshot,
+// starting the snapshot first if asked to
+func (n *Node) GetRingSnapshotPiece(ctx context.Context, req *pb.GetRingSnapshotPieceRequest) (*pb.GetRingSnapshotPieceResponse, error) {
+	n.countMessage()
+
+	id := req.Id
+	if req.Start {
+		var err error
+		if id, _, err = n.StartRingSnapshot(ctx); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	piece, ok := n.RingSnapshotPiece(id)
+	if !ok {
+		return &pb.GetRingSnapshotPieceResponse{Found: false}, nil
+	}
+	return &pb.GetRingSnapshotPieceResponse{Piece: toProtoRingSnapshotPiece(piece), Found: true}, nil
+}
+
+// toProtoRingSnapshotPiece converts a ring snapshot piece to its protobuf
+// form
+func toProtoRingSnapshotPiece(piece RingSnapshotPiece) *pb.RingSnapshotPiece {
+	result := &pb.RingSnapshotPiece{
+		Node:     toProtoNode(piece.Node),
+		Id:       piece.ID,
+		TimeMs:   piece.Time.UnixMilli(),
+		File:     piece.File,
+		Entries:  int64(piece.Entries),
+		Bytes:    piece.Bytes,
+		Checksum: piece.Checksum,
+		Pending:  piece.Pending,
+		Error:    piece.Err,
+	}
+	if piece.RangeStart != nil {
+		result.RangeStart = piece.RangeStart.String()
+	}
+	return result
+}
+
+// RingSnapshotPieceFromProto converts a ring snapshot piece received from a
+// node
+func RingSnapshotPieceFromProto(piece *pb.RingSnapshotPiece) (RingSnapshotPiece, error) {
+	node, err := fromProtoNode(piece.GetNode())
+	if err != nil {
+		return RingSnapshotPiece{}, fmt.Errorf("invalid ring snapshot piece: %w", err)
+	}
+	result := RingSnapshotPiece{
+		ID:       piece.Id,
+		Node:     node,
+		Time:     time.UnixMilli(piece.TimeMs),
+		File:     piece.File,
+		Entries:  int(piece.Entries),
+		Bytes:    piece.Bytes,
+		Checksum: piece.Checksum,
+		Pending:  piece.Pending,
+		Err:      piece.Error,
+	}
+	if piece.RangeStart != "" {
+		if result.RangeStart, err = hash.NewHashFromHex(piece.RangeStart); err != nil {
+			return RingSnapshotPiece{}, fmt.Errorf("invalid ring snapshot range start: %w", err)
+		}
+	}
+	return result, nil
+}
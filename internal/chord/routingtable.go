@@ -0,0 +1,165 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+// Finger is an entry of a finger table
+type Finger struct {
+	// Index is i for the entry pointing at the successor of node + 2^i
+	Index int
+	Start *hash.Hash
+	// Node is nil if the entry is empty
+	Node *NodeInfo
+}
+
+// RoutingTable is a node's full view of the ring: its neighbors and every
+// entry of its finger table
+type RoutingTable struct {
+	Self        *NodeInfo
+	Predecessor *NodeInfo
+	// Successors is the successor list, closest first, led by the current
+	// successor even before the list is refreshed after it changed
+	Successors []*NodeInfo
+	// Fingers holds every finger table entry, in index order
+	Fingers []Finger
+}
+
+// RoutingTable returns this node's neighbors and finger table
+func (n *Node) RoutingTable() *RoutingTable {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	table := &RoutingTable{
+		Self:        n.GetNodeInfo(),
+		Predecessor: n.predecessor,
+		Fingers:     make([]Finger, len(n.fingers)),
+	}
+	if n.successor != nil {
+		table.Successors = append(table.Successors, n.successor)
+	}
+	for _, succ := range n.successorList {
+		if n.successor == nil || succ.Address != n.successor.Address {
+			table.Successors = append(table.Successors, succ)
+		}
+	}
+	for i, finger := range n.fingers {
+		table.Fingers[i] = Finger{Index: i, Start: hash.FingerStart(n.id, i+1), Node: finger}
+	}
+	return table
+}
+
+// RemoteSuccessorList asks the node at address for its predecessor and
+// successor list; the returned table has no fingers
+func (n *Node) RemoteSuccessorList(ctx context.Context, address string) (*RoutingTable, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetSuccessorList(ctx, &pb.GetSuccessorListRequest{})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	return RoutingTableFromProto(&pb.GetRoutingTableResponse{
+		Node:        resp.Node,
+		Predecessor: resp.Predecessor,
+		Successors:  resp.Successors,
+	})
+}
+
+// RemoteRoutingTable asks the node at address for its routing table
+func (n *Node) RemoteRoutingTable(ctx context.Context, address string) (*RoutingTable, error) {
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetRoutingTable(ctx, &pb.GetRoutingTableRequest{})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	return RoutingTableFromProto(resp)
+}
+
+// GetSuccessorList returns this node's predecessor and successor list
+func (n *Node) GetSuccessorList(ctx context.Context, req *pb.GetSuccessorListRequest) (*pb.GetSuccessorListResponse, error) {
+	n.countMessage()
+
+	table := n.RoutingTable()
+	resp := &pb.GetSuccessorListResponse{
+		Node:       toProtoNode(table.Self),
+		Successors: toProtoNodes(table.Successors),
+	}
+	if table.Predecessor != nil {
+		resp.Predecessor = toProtoNode(table.Predecessor)
+	}
+	return resp, nil
+}
+
+// GetRoutingTable returns this node's predecessor, successor list and every
+// entry of its finger table
+func (n *Node) GetRoutingTable(ctx context.Context, req *pb.GetRoutingTableRequest) (*pb.GetRoutingTableResponse, error) {
+	n.countMessage()
+
+	return toProtoRoutingTable(n.RoutingTable()), nil
+}
+
+// toProtoRoutingTable converts a routing table to its protobuf form
+func toProtoRoutingTable(table *RoutingTable) *pb.GetRoutingTableResponse {
+	resp := &pb.GetRoutingTableResponse{
+		Node:       toProtoNode(table.Self),
+		Successors: toProtoNodes(table.Successors),
+	}
+	if table.Predecessor != nil {
+		resp.Predecessor = toProtoNode(table.Predecessor)
+	}
+	for _, finger := range table.Fingers {
+		entry := &pb.Finger{Index: int32(finger.Index), Start: finger.Start.String()}
+		if finger.Node != nil {
+			entry.Node = toProtoNode(finger.Node)
+		}
+		resp.Fingers = append(resp.Fingers, entry)
+	}
+	return resp
+}
+
+// RoutingTableFromProto converts a routing table received from a node
+func RoutingTableFromProto(resp *pb.GetRoutingTableResponse) (*RoutingTable, error) {
+	self, err := fromProtoNode(resp.Node)
+	if err != nil {
+		return nil, err
+	}
+	table := &RoutingTable{Self: self}
+	if resp.Predecessor != nil {
+		if table.Predecessor, err = fromProtoNode(resp.Predecessor); err != nil {
+			return nil, err
+		}
+	}
+	if table.Successors, err = fromProtoNodes(resp.Successors); err != nil {
+		return nil, err
+	}
+	for _, entry := range resp.Fingers {
+		finger := Finger{Index: int(entry.Index)}
+		if finger.Start, err = hash.NewHashFromHex(entry.Start); err != nil {
+			return nil, fmt.Errorf("invalid start of finger %d: %w", entry.Index, err)
+		}
+		if entry.Node != nil {
+			if finger.Node, err = fromProtoNode(entry.Node); err != nil {
+				return nil, err
+			}
+		}
+		table.Fingers = append(table.Fingers, finger)
+	}
+	return table, nil
+}
@@ -0,0 +1,47 @@
+package chord
+
+import (
+	"context"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestRemoteRoutingTable(t *testing.T) {
+	nodes := startTestRing(t, 8648, 3)
+	for _, node := range nodes {
+		node.fixFingers()
+	}
+
+	ctx := context.Background()
+	table, err := nodes[1].RemoteRoutingTable(ctx, nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("RemoteRoutingTable failed: %v", err)
+	}
+	if table.Self.Address != nodes[0].GetAddress() || table.Predecessor == nil {
+		t.Errorf("Expected node 0 with a predecessor, got %+v", table)
+	}
+	if len(table.Successors) != 2 || table.Successors[0].Address != nodes[0].GetSuccessor().Address {
+		t.Errorf("Expected the successor list led by the successor, got %v", table.Successors)
+	}
+	if len(table.Fingers) != FingerTableSize {
+		t.Fatalf("Expected %d fingers, got %d", FingerTableSize, len(table.Fingers))
+	}
+	fingers := nodes[0].GetFingers()
+	for i, finger := range table.Fingers {
+		if finger.Index != i || !finger.Start.Equal(hash.FingerStart(nodes[0].GetID(), i+1)) {
+			t.Fatalf("Finger %d has index %d and start %s", i, finger.Index, finger.Start)
+		}
+		if finger.Node.Address != fingers[i].Address {
+			t.Errorf("Finger %d is %s, expected %s", i, finger.Node.Address, fingers[i].Address)
+		}
+	}
+
+	list, err := nodes[1].RemoteSuccessorList(ctx, nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("RemoteSuccessorList failed: %v", err)
+	}
+	if len(list.Successors) != 2 || len(list.Fingers) != 0 {
+		t.Errorf("Expected 2 successors and no fingers, got %+v", list)
+	}
+}
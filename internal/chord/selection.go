@@ -0,0 +1,212 @@
+package chord
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// replicaFailureThreshold is the number of consecutive failed reads after
+	// which a replica is considered unhealthy
+	replicaFailureThreshold = 3
+	// replicaProbeInterval is how long an unhealthy replica is avoided before
+	// it is tried again
+	replicaProbeInterval = 10 * time.Second
+	// replicaRTTWeight is the weight of a new sample in the smoothed RTT
+	replicaRTTWeight = 0.2
+)
+
+// ReplicaSelector decides which copies of a key a read goes to, and in which
+// order. Reads go to the first node and move on to the next one if it fails,
+// has not seen the key, or is slower than the hedge delay.
+type ReplicaSelector interface {
+	// Order returns the nodes to read from, most preferred first.
+	// candidates[0] is the key's owner, followed by its replicas in ring
+	// order. Unhealthy nodes are moved to the end by the caller.
+	Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo
+}
+
+// PrimaryFirst reads from the owner and uses the replicas only as fallbacks
+// and hedges. It is the default.
+type PrimaryFirst struct{}
+
+// Order implements ReplicaSelector
+func (PrimaryFirst) Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo {
+	return candidates
+}
+
+// PrimaryOnly reads from the owner only
+type PrimaryOnly struct{}
+
+// Order implements ReplicaSelector
+func (PrimaryOnly) Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo {
+	return candidates[:1]
+}
+
+// RoundRobin spreads reads evenly over the owner and its replicas
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+// Order implements ReplicaSelector
+func (r *RoundRobin) Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo {
+	offset := int(r.next.Add(1) % uint64(len(candidates)))
+	return append(append([]*NodeInfo(nil), candidates[offset:]...), candidates[:offset]...)
+}
+
+// ClosestRTT reads from the copy with the lowest smoothed read latency.
+// Nodes without a measurement yet are tried first so that every copy gets
+// measured.
+type ClosestRTT struct{}
+
+// Order implements ReplicaSelector
+func (ClosestRTT) Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo {
+	ordered := append([]*NodeInfo(nil), candidates...)
+	rtts := make(map[string]time.Duration, len(ordered))
+	for _, node := range ordered {
+		rtts[node.Address] = health.Status(node.Address).RTT
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rtts[ordered[i].Address] < rtts[ordered[j].Address]
+	})
+	return ordered
+}
+
+// LocalZone reads from the copies in Zone first, in ring order, and from
+// the other zones only as fallbacks and hedges. An empty Zone means the zone
+// of the reading node (see SetNodeMetadata). Copies whose zone is unknown
+// count as remote.
+type LocalZone struct {
+	Zone string
+}
+
+// Order implements ReplicaSelector
+func (l LocalZone) Order(candidates []*NodeInfo, health *ReplicaHealth) []*NodeInfo {
+	ordered := append([]*NodeInfo(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].sameZone(l.Zone) && !ordered[j].sameZone(l.Zone)
+	})
+	return ordered
+}
+
+// ParseReplicaSelector returns the selector with the given name:
+// primary-first, primary-only, round-robin, closest-rtt or local-zone
+func ParseReplicaSelector(name string) (ReplicaSelector, error) {
+	switch name {
+	case "", "primary-first":
+		return PrimaryFirst{}, nil
+	case "primary-only":
+		return PrimaryOnly{}, nil
+	case "round-robin":
+		return &RoundRobin{}, nil
+	case "closest-rtt":
+		return ClosestRTT{}, nil
+	case "local-zone":
+		return LocalZone{}, nil
+	default:
+		return nil, fmt.Errorf("unknown replica selection policy %q", name)
+	}
+}
+
+// ReplicaStatus is the read health of one node as seen by this node
+type ReplicaStatus struct {
+	// RTT is the smoothed latency of successful reads, zero if unmeasured
+	RTT time.Duration
+	// Failures is the number of consecutive failed reads
+	Failures    int
+	LastFailure time.Time
+	// Healthy is false while the node is avoided after repeated failures
+	Healthy bool
+}
+
+// ReplicaHealth tracks the read latency and failures of the nodes this node
+// reads from
+type ReplicaHealth struct {
+	mu    sync.Mutex
+	peers map[string]*ReplicaStatus
+}
+
+// Status returns the health of the node at address
+func (h *ReplicaHealth) Status(address string) ReplicaStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status, ok := h.peers[address]
+	if !ok {
+		return ReplicaStatus{Healthy: true}
+	}
+	result := *status
+	result.Healthy = status.Failures < replicaFailureThreshold ||
+		time.Since(status.LastFailure) > replicaProbeInterval
+	return result
+}
+
+// observe records the outcome of a read from the node at address. Only
+// transport failures count against a node's health.
+func (h *ReplicaHealth) observe(address string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.peers == nil {
+		h.peers = make(map[string]*ReplicaStatus)
+	}
+	status, ok := h.peers[address]
+	if !ok {
+		status = &ReplicaStatus{}
+		h.peers[address] = status
+	}
+
+	switch {
+	case errors.Is(err, ErrPeerUnreachable):
+		status.Failures++
+		status.LastFailure = time.Now()
+	case err == nil:
+		status.Failures = 0
+		if status.RTT == 0 {
+			status.RTT = latency
+		} else {
+			status.RTT += time.Duration(replicaRTTWeight * float64(latency-status.RTT))
+		}
+	}
+}
+
+// SetReplicaSelector sets the policy choosing which copies of a key reads go
+// to when the replication factor is above 1. A nil selector restores
+// PrimaryFirst.
+func (n *Node) SetReplicaSelector(selector ReplicaSelector) {
+	if selector == nil {
+		selector = PrimaryFirst{}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.selector = selector
+}
+
+// ReplicaHealth returns the read health tracked for other nodes
+func (n *Node) ReplicaHealth() *ReplicaHealth {
+	return &n.health
+}
+
+// readOrder returns the nodes to read a key owned by owner from, ordered by
+// the replica selector with unhealthy nodes last
+func (n *Node) readOrder(owner *NodeInfo) []*NodeInfo {
+	n.mu.RLock()
+	selector := n.selector
+	n.mu.RUnlock()
+	if local, ok := selector.(LocalZone); ok && local.Zone == "" {
+		selector = LocalZone{Zone: n.Metadata().Zone}
+	}
+
+	candidates := append([]*NodeInfo{owner}, n.replicaCandidates(owner)...)
+	ordered := selector.Order(candidates, &n.health)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return n.health.Status(ordered[i].Address).Healthy && !n.health.Status(ordered[j].Address).Healthy
+	})
+	return ordered
+}
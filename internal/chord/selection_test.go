@@ -0,0 +1,153 @@
+package chord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+func testCandidates(count int) []*NodeInfo {
+	candidates := make([]*NodeInfo, count)
+	for i := range candidates {
+		address := fmt.Sprintf("localhost:%d", 9000+i)
+		candidates[i] = &NodeInfo{ID: hash.GenerateID(address), Address: address}
+	}
+	return candidates
+}
+
+func addresses(nodes []*NodeInfo) []string {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = node.Address
+	}
+	return result
+}
+
+func TestReplicaSelectors(t *testing.T) {
+	candidates := testCandidates(3)
+	health := &ReplicaHealth{}
+
+	if got := (PrimaryOnly{}).Order(candidates, health); len(got) != 1 || got[0] != candidates[0] {
+		t.Errorf("PrimaryOnly: expected only the owner, got %v", addresses(got))
+	}
+
+	rr := &RoundRobin{}
+	firsts := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		got := rr.Order(candidates, health)
+		if len(got) != len(candidates) {
+			t.Fatalf("RoundRobin: expected %d nodes, got %v", len(candidates), addresses(got))
+		}
+		firsts[got[0].Address]++
+	}
+	for _, node := range candidates {
+		if firsts[node.Address] != 2 {
+			t.Errorf("RoundRobin: expected %s first twice, got %v", node.Address, firsts)
+		}
+	}
+
+	health.observe(candidates[0].Address, 30*time.Millisecond, nil)
+	health.observe(candidates[1].Address, 10*time.Millisecond, nil)
+	got := addresses((ClosestRTT{}).Order(candidates, health))
+	want := []string{candidates[2].Address, candidates[1].Address, candidates[0].Address}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ClosestRTT: expected unmeasured then fastest first %v, got %v", want, got)
+	}
+
+	candidates[0].Zone, candidates[1].Zone, candidates[2].Zone = "a", "b", "b"
+	got = addresses((LocalZone{Zone: "b"}).Order(candidates, health))
+	want = []string{candidates[1].Address, candidates[2].Address, candidates[0].Address}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("LocalZone: expected copies in zone b first in ring order %v, got %v", want, got)
+	}
+
+	if _, err := ParseReplicaSelector("fastest"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+func TestReplicaHealth(t *testing.T) {
+	health := &ReplicaHealth{}
+	address := "localhost:9000"
+	unreachable := &PeerError{Address: address, Err: context.DeadlineExceeded}
+
+	// Application errors do not count against a node
+	health.observe(address, time.Millisecond, ErrKeyNotFound)
+	for i := 0; i < replicaFailureThreshold-1; i++ {
+		health.observe(address, time.Millisecond, unreachable)
+	}
+	if status := health.Status(address); !status.Healthy || status.Failures != replicaFailureThreshold-1 {
+		t.Errorf("Expected healthy with %d failures, got %+v", replicaFailureThreshold-1, status)
+	}
+
+	health.observe(address, time.Millisecond, unreachable)
+	if health.Status(address).Healthy {
+		t.Error("Expected unhealthy after repeated failures")
+	}
+
+	health.observe(address, 20*time.Millisecond, nil)
+	if status := health.Status(address); !status.Healthy || status.Failures != 0 || status.RTT != 20*time.Millisecond {
+		t.Errorf("Expected a success to restore health, got %+v", status)
+	}
+}
+
+func TestReplicaSelection(t *testing.T) {
+	nodes := startTestRing(t, 8396, 3)
+	for _, node := range nodes {
+		node.SetReplication(2)
+	}
+
+	ctx := context.Background()
+	key := "selected-key"
+	owner, err := nodes[0].Lookup(hash.NewHashFromString(key))
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	var ownerNode, reader *Node
+	for _, node := range nodes {
+		if node.GetAddress() == owner.Address {
+			ownerNode = node
+		} else {
+			reader = node
+		}
+	}
+
+	if err := reader.StoreValue(ctx, key, []byte("value")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	replicas := reader.replicaCandidates(owner)
+	if len(replicas) != 1 {
+		t.Fatalf("Expected one replica candidate, got %v", addresses(replicas))
+	}
+
+	// Without hedging, a closest-RTT reader that measured the owner as slow
+	// reads from the replica instead of waiting for the owner
+	ownerNode.SetStorage(&slowStorage{Storage: ownerNode.Storage(), delay: time.Second})
+	reader.SetReplicaSelector(ClosestRTT{})
+	reader.health.observe(owner.Address, time.Second, nil)
+	reader.health.observe(replicas[0].Address, time.Millisecond, nil)
+
+	start := time.Now()
+	value, err := reader.FetchValue(ctx, key)
+	if err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+	if !bytes.Equal(value, []byte("value")) {
+		t.Errorf("Expected value, got %q", value)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Read took %v, expected the replica to be chosen", elapsed)
+	}
+
+	// An unhealthy replica is tried last, so the read waits for the owner
+	for i := 0; i < replicaFailureThreshold; i++ {
+		reader.health.observe(replicas[0].Address, time.Millisecond, &PeerError{Address: replicas[0].Address})
+	}
+	if order := reader.readOrder(owner); order[0].Address != owner.Address {
+		t.Errorf("Expected the owner first with an unhealthy replica, got %v", addresses(order))
+	}
+}
@@ -0,0 +1,124 @@
+package chord
+
+import (
+	"fmt"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// registeredService is a gRPC service served by the node's server
+type registeredService struct {
+	desc *grpc.ServiceDesc
+	impl any
+}
+
+// RegisterService adds a gRPC service to be served on the node's listener,
+// letting packages built on the node expose their own RPCs. It must be
+// called before Start.
+func (n *Node) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.services = append(n.services, registeredService{desc: desc, impl: impl})
+}
+
+// ClientConn returns the node's connection to address, dialing it on first
+// use. The connection is shared by all services talking to that peer.
+func (n *Node) ClientConn(address string) (*grpc.ClientConn, error) {
+	n.mu.RLock()
+	conn, exists := n.connections[address]
+	n.mu.RUnlock()
+
+	if exists {
+		return conn, nil
+	}
+
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(n.dialCounted),
+		grpc.WithStatsHandler(compressionCounter{node: n}),
+		grpc.WithStatsHandler(bandwidthCounter{node: n}),
+	}, n.dialOptions()...)
+	target := address
+	if relay, id, ok := parseRelayAddress(address); ok {
+		// A node behind NAT is dialed directly if hole punching opens its
+		// NAT, and through its relay otherwise (see nat.go)
+		if target = n.punchHole(relay, id); target == "" {
+			target = relay
+			opts = append(opts, relayDialOptions(id)...)
+		}
+	}
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return nil, &PeerError{Address: address, Err: err}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	// Keep the first connection if another caller dialed concurrently
+	if existing, ok := n.connections[address]; ok {
+		conn.Close()
+		return existing, nil
+	}
+	n.connections[address] = conn
+	return conn, nil
+}
+
+// Done returns a channel that is closed when the node stops. Long-running
+// handlers such as server streams should return once it is closed.
+func (n *Node) Done() <-chan struct{} {
+	return n.ctx.Done()
+}
+
+// registerHealth serves the standard gRPC health service. The node as a
+// whole (the empty service name) is serving while its server runs;
+// ChordService and the registered services are serving once the node has
+// joined a ring. The caller must hold mu.
+func (n *Node) registerHealth() {
+	healthpb.RegisterHealthServer(n.server, n.healthServer)
+	n.setServing(false)
+}
+
+// setServing sets the health status of ChordService and the registered
+// services
+func (n *Node) setServing(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	n.healthServer.SetServingStatus(pb.ChordService_ServiceDesc.ServiceName, status)
+	for _, svc := range n.services {
+		n.healthServer.SetServingStatus(svc.desc.ServiceName, status)
+	}
+}
+
+// Ready reports whether the node can serve traffic: it has joined a ring
+// and stabilized, knowing its successor and either its predecessor or being
+// alone in the ring it created. It returns an error wrapping
+// ErrRingUnstable saying what is missing otherwise.
+func (n *Node) Ready() error {
+	select {
+	case <-n.ctx.Done():
+		return fmt.Errorf("%w: node stopped", ErrRingUnstable)
+	default:
+	}
+
+	if n.Isolated() {
+		return fmt.Errorf("%w: node is isolated, rejoining", ErrRingUnstable)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	switch {
+	case n.successor == nil:
+		return fmt.Errorf("%w: node has not joined a ring", ErrRingUnstable)
+	case n.predecessor == nil && !n.successor.ID.Equal(n.id):
+		return fmt.Errorf("%w: predecessor not known yet", ErrRingUnstable)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+func TestHealthAndReflection(t *testing.T) {
+	node := NewNode("localhost:8444", nil)
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+
+	conn, err := grpc.NewClient(node.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	health := healthpb.NewHealthClient(conn)
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Health check of %q failed: %v", service, err)
+		}
+		return resp.Status
+	}
+
+	chordService := pb.ChordService_ServiceDesc.ServiceName
+	if status := check(""); status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected the started node to be serving, got %v", status)
+	}
+	if status := check(chordService); status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected ChordService not serving before joining, got %v", status)
+	}
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	if status := check(chordService); status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected ChordService serving after joining, got %v", status)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("Reflection stream failed: %v", err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("ListServices failed: %v", err)
+	}
+	services := make(map[string]bool)
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		services[svc.Name] = true
+	}
+	for _, name := range []string{chordService, healthpb.Health_ServiceDesc.ServiceName} {
+		if !services[name] {
+			t.Errorf("Expected reflection to list %s, got %v", name, services)
+		}
+	}
+}
+
+func TestReady(t *testing.T) {
+	bootstrap := NewNode("localhost:8445", nil)
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(bootstrap.Stop)
+	if err := bootstrap.Ready(); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected a node outside a ring not to be ready, got %v", err)
+	}
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+	if err := bootstrap.Ready(); err != nil {
+		t.Errorf("Expected the lone bootstrap to be ready: %v", err)
+	}
+
+	joiner := NewNode("localhost:8446", nil)
+	if err := joiner.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	if err := joiner.Join(bootstrap.GetAddress()); err != nil {
+		t.Fatalf("Failed to join: %v", err)
+	}
+	if err := joiner.Ready(); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected a joiner without predecessor not to be ready, got %v", err)
+	}
+	bootstrap.stabilize()
+	if err := joiner.Ready(); err != nil {
+		t.Errorf("Expected the joiner to be ready after stabilization: %v", err)
+	}
+
+	joiner.Stop()
+	if err := joiner.Ready(); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected a stopped node not to be ready, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package chord
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+)
+
+// snapshotFormat is the version of the snapshot format written by Snapshot
+const snapshotFormat = 1
+
+// snapshotChunkSize is the size of the chunks GetSnapshot streams
+const snapshotChunkSize = 64 << 10
+
+// A snapshot is a sequence of JSON records, one per line: a header with the
+// routing state, one record per stored entry and a trailer with the entry
+// count, so a truncated snapshot is detected
+type snapshotRecord struct {
+	Format int           `json:"format,omitempty"`
+	State  *RoutingState `json:"state,omitempty"`
+	// Sealed is set in the header of the snapshot of a SealedStorage,
+	// whose entries hold values as stored
+	Sealed bool           `json:"sealed,omitempty"`
+	Entry  *snapshotEntry `json:"entry,omitempty"`
+	// Entries is set in the trailer only
+	Entries *int `json:"entries,omitempty"`
+}
+
+// snapshotEntry is a stored entry in a snapshot
+type snapshotEntry struct {
+	Key       string     `json:"key"`
+	Value     []byte     `json:"value"`
+	Version   uint64     `json:"version"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SnapshotInfo describes a snapshot
+type SnapshotInfo struct {
+	State   *RoutingState
+	Entries int
+	// Sealed is set for the snapshot of a SealedStorage, whose values are
+	// encrypted
+	Sealed bool
+}
+
+// Snapshot writes the node's ID, routing state and stored entries to w.
+// Entries are read under the data lock, so the snapshot is consistent and
+// writes to the node wait until it is written. The values of a
+// SealedStorage are written encrypted.
+func (n *Node) Snapshot(w io.Writer) error {
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	rangeEntries := n.storage.Range
+	sealed, isSealed := n.storage.(SealedStorage)
+	if isSealed {
+		rangeEntries = sealed.RangeSealed
+	}
+	return writeSnapshot(w, snapshotRecord{Format: snapshotFormat, State: n.RoutingState(), Sealed: isSealed}, rangeEntries)
+}
+
+// writeSnapshot writes a snapshot with the given header and the entries
+// listed by rangeEntries
+func writeSnapshot(w io.Writer, header snapshotRecord, rangeEntries func(func(string, Entry) bool) error) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	count := 0
+	var writeErr error
+	err := rangeEntries(func(key string, e Entry) bool {
+		if writeErr = encoder.Encode(snapshotRecord{Entry: toSnapshotEntry(key, e)}); writeErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return encoder.Encode(snapshotRecord{Entries: &count})
+}
+
+// toSnapshotEntry converts a stored entry to its snapshot form
+func toSnapshotEntry(key string, e Entry) *snapshotEntry {
+	entry := &snapshotEntry{Key: key, Value: e.Value, Version: e.Version}
+	if !e.ExpiresAt.IsZero() {
+		expires := e.ExpiresAt.UTC()
+		entry.ExpiresAt = &expires
+	}
+	if e.Tombstone() {
+		deleted := e.DeletedAt.UTC()
+		entry.DeletedAt = &deleted
+	}
+	return entry
+}
+
+// entry converts a snapshot entry back to a stored entry
+func (s *snapshotEntry) entry() Entry {
+	e := Entry{Value: s.Value, Version: s.Version}
+	if s.ExpiresAt != nil {
+		e.ExpiresAt = *s.ExpiresAt
+	}
+	if s.DeletedAt != nil {
+		e.DeletedAt = *s.DeletedAt
+	}
+	return e
+}
+
+// SaveSnapshot writes a snapshot of the node to path, replacing it
+// atomically
+func (n *Node) SaveSnapshot(path string) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		buffered := bufio.NewWriter(w)
+		if err := n.Snapshot(buffered); err != nil {
+			return err
+		}
+		return buffered.Flush()
+	})
+}
+
+// RestoreSnapshot loads the entries of a snapshot written by Snapshot into
+// the node's store, keeping any newer version already stored, and returns
+// the snapshot's routing state for RejoinState. The snapshot must have been
+// taken of a node with the same ID, and a sealed one restores only into a
+// SealedStorage. Nothing is stored unless the whole snapshot reads back.
+func (n *Node) RestoreSnapshot(r io.Reader) (*RoutingState, error) {
+	var entries []*snapshotEntry
+	info, err := readSnapshot(r, func(entry *snapshotEntry) {
+		entries = append(entries, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if id, err := hash.NewHashFromHex(info.State.ID); err != nil || !id.Equal(n.id) {
+		return nil, fmt.Errorf("snapshot of node %s cannot be restored on node %s", info.State.ID, n.id)
+	}
+
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	put := n.storage.Put
+	if info.Sealed {
+		sealed, ok := n.storage.(SealedStorage)
+		if !ok {
+			return nil, errors.New("snapshot holds encrypted values and the node's store does not encrypt")
+		}
+		put = sealed.PutSealed
+	}
+	for _, entry := range entries {
+		current, ok, err := n.storage.Get(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", entry.Key, err)
+		}
+		if ok && current.Version >= entry.Version {
+			continue
+		}
+		if err := put(entry.Key, entry.entry()); err != nil {
+			return nil, fmt.Errorf("failed to restore %q: %w", entry.Key, err)
+		}
+	}
+	return info.State, nil
+}
+
+// ReadSnapshot checks a snapshot written by Snapshot and describes it
+// without loading its entries
+func ReadSnapshot(r io.Reader) (*SnapshotInfo, error) {
+	return readSnapshot(r, func(*snapshotEntry) {})
+}
+
+// ReadSnapshotEntries reads a snapshot written by Snapshot, calling fn for
+// every stored entry, tombstones and expired entries included. The snapshot
+// is only known to be whole once it returns without an error.
+func ReadSnapshotEntries(r io.Reader, fn func(key string, e Entry)) (*SnapshotInfo, error) {
+	return readSnapshot(r, func(entry *snapshotEntry) {
+		fn(entry.Key, entry.entry())
+	})
+}
+
+// readSnapshot decodes a snapshot, calling entry for every stored entry
+func readSnapshot(r io.Reader, entry func(*snapshotEntry)) (*SnapshotInfo, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var header snapshotRecord
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("invalid snapshot header: %w", err)
+	}
+	if header.Format != snapshotFormat || header.State == nil {
+		return nil, fmt.Errorf("unsupported snapshot format %d", header.Format)
+	}
+
+	info := &SnapshotInfo{State: header.State, Sealed: header.Sealed}
+	for {
+		var record snapshotRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("truncated snapshot: no trailer")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot record %d: %w", info.Entries+1, err)
+		}
+
+		switch {
+		case record.Entry != nil:
+			entry(record.Entry)
+			info.Entries++
+		case record.Entries != nil:
+			if *record.Entries != info.Entries {
+				return nil, fmt.Errorf("truncated snapshot: %d of %d entries", info.Entries, *record.Entries)
+			}
+			return info, nil
+		default:
+			return nil, fmt.Errorf("invalid snapshot record %d", info.Entries+1)
+		}
+	}
+}
+
+// chunkWriter streams the bytes written to it as snapshot chunks
+type chunkWriter struct {
+	stream grpc.ServerStreamingServer[pb.SnapshotChunk]
+}
+
+// Write sends p as one chunk
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.SnapshotChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// GetSnapshot streams a snapshot of this node
+func (n *Node) GetSnapshot(req *pb.GetSnapshotRequest, stream grpc.ServerStreamingServer[pb.SnapshotChunk]) error {
+	n.countMessage()
+
+	w := bufio.NewWriterSize(chunkWriter{stream}, snapshotChunkSize)
+	if err := n.Snapshot(w); err != nil {
+		return toStatus(err)
+	}
+	return toStatus(w.Flush())
+}
@@ -0,0 +1,170 @@
+package chord
+
+import (
+	"sync"
+	"time"
+)
+
+// StabilizationPolicy makes the stabilization and fix-fingers periods adapt
+// to churn. A change of successor or predecessor drops the stabilization
+// period to MinInterval; every quiet round after that grows it by Growth, up
+// to MaxInterval. Fix-fingers runs at the same multiple of the stabilization
+// period as FixFingersInterval is of StabilizeInterval.
+type StabilizationPolicy struct {
+	// Adaptive enables adaptation. Without it stabilization runs every
+	// StabilizeInterval and fix-fingers every FixFingersInterval.
+	Adaptive bool
+	// MinInterval is the period after a change, StabilizeInterval/5 if zero
+	MinInterval time.Duration
+	// MaxInterval is the period on a quiet ring, 4*StabilizeInterval if zero
+	MaxInterval time.Duration
+	// Growth multiplies the period after every quiet round, 1.5 if zero
+	Growth float64
+}
+
+// withDefaults fills in the zero fields of p
+func (p StabilizationPolicy) withDefaults() StabilizationPolicy {
+	if p.MinInterval <= 0 {
+		p.MinInterval = StabilizeInterval / 5
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = 4 * StabilizeInterval
+	}
+	p.MaxInterval = max(p.MaxInterval, p.MinInterval)
+	if p.Growth <= 1 {
+		p.Growth = 1.5
+	}
+	return p
+}
+
+// StabilizationStatus is the current pace of a node's ring maintenance
+type StabilizationStatus struct {
+	Interval           time.Duration
+	FixFingersInterval time.Duration
+	// Rounds and FixFingersRounds count the rounds run since Start
+	Rounds           int64
+	FixFingersRounds int64
+	// LastChange is when the last change of successor or predecessor was
+	// seen, zero if none
+	LastChange time.Time
+	// LastRound is when the last stabilization round ran, zero if none
+	LastRound time.Time
+	// QuietRounds counts the stabilization rounds in a row that saw no
+	// change of successor or predecessor (see Converged)
+	QuietRounds int64
+}
+
+// stabilization is the adaptive pace of stabilize and fixFingers
+type stabilization struct {
+	mu         sync.Mutex
+	policy     StabilizationPolicy
+	interval   time.Duration
+	seen       uint64 // membership history sequence at the last round
+	rounds     int64
+	quiet      int64
+	fingers    int64
+	lastChange time.Time
+	lastRound  time.Time
+	// changed wakes the fix-fingers loop when the period drops
+	changed chan struct{}
+}
+
+// SetStabilization sets how the stabilization and fix-fingers periods adapt
+// to churn
+func (n *Node) SetStabilization(policy StabilizationPolicy) {
+	n.stabilization.mu.Lock()
+	defer n.stabilization.mu.Unlock()
+
+	n.stabilization.policy = policy
+	n.stabilization.interval = 0
+}
+
+// StabilizationStatus returns the current stabilization and fix-fingers
+// periods and the rounds run
+func (n *Node) StabilizationStatus() StabilizationStatus {
+	n.stabilization.mu.Lock()
+	defer n.stabilization.mu.Unlock()
+
+	interval := n.stabilization.currentLocked()
+	return StabilizationStatus{
+		Interval:           interval,
+		FixFingersInterval: fixFingersPeriod(interval),
+		Rounds:             n.stabilization.rounds,
+		FixFingersRounds:   n.stabilization.fingers,
+		LastChange:         n.stabilization.lastChange,
+		LastRound:          n.stabilization.lastRound,
+		QuietRounds:        n.stabilization.quiet,
+	}
+}
+
+// currentLocked returns the stabilization period, starting from
+// StabilizeInterval within the policy's bounds
+func (s *stabilization) currentLocked() time.Duration {
+	if !s.policy.Adaptive {
+		return StabilizeInterval
+	}
+	if s.interval == 0 {
+		policy := s.policy.withDefaults()
+		s.interval = min(max(StabilizeInterval, policy.MinInterval), policy.MaxInterval)
+	}
+	return s.interval
+}
+
+// fixFingersPeriod returns the fix-fingers period going with a stabilization
+// period
+func fixFingersPeriod(interval time.Duration) time.Duration {
+	return time.Duration(float64(interval) * float64(FixFingersInterval) / float64(StabilizeInterval))
+}
+
+// stabilized is called after every stabilization round and returns the delay
+// until the next one
+func (n *Node) stabilized() time.Duration {
+	seq := n.history.seq()
+
+	s := &n.stabilization
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rounds++
+	s.lastRound = time.Now()
+	changed := seq != s.seen
+	s.seen = seq
+	if changed {
+		s.lastChange = time.Now()
+		s.quiet = 0
+	} else {
+		s.quiet++
+	}
+	interval := s.currentLocked()
+	if !s.policy.Adaptive {
+		return interval
+	}
+
+	policy := s.policy.withDefaults()
+	if changed {
+		s.interval = policy.MinInterval
+		if s.interval < interval {
+			select {
+			case s.changed <- struct{}{}:
+			default:
+			}
+		}
+	} else {
+		s.interval = min(time.Duration(float64(interval)*policy.Growth), policy.MaxInterval)
+	}
+	return s.interval
+}
+
+// fingersFixed is called after every fix-fingers round and returns the delay
+// until the next one
+func (n *Node) fingersFixed() time.Duration {
+	s := &n.stabilization
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fingers++
+	if !s.policy.Adaptive {
+		return FixFingersInterval
+	}
+	return fixFingersPeriod(s.currentLocked())
+}
@@ -0,0 +1,50 @@
+package chord
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveStabilization(t *testing.T) {
+	node := NewNode("localhost:0", nil)
+	if got := node.stabilized(); got != StabilizeInterval {
+		t.Errorf("Expected the fixed interval without a policy, got %v", got)
+	}
+	if got := node.fingersFixed(); got != FixFingersInterval {
+		t.Errorf("Expected the fixed fix-fingers interval without a policy, got %v", got)
+	}
+
+	node.SetStabilization(StabilizationPolicy{
+		Adaptive:    true,
+		MinInterval: time.Second,
+		MaxInterval: 8 * time.Second,
+		Growth:      2,
+	})
+	// A quiet ring slows down to MaxInterval
+	for _, want := range []time.Duration{8 * time.Second, 8 * time.Second} {
+		if got := node.stabilized(); got != want {
+			t.Errorf("Expected %v on a quiet ring, got %v", want, got)
+		}
+	}
+
+	// A neighbor change speeds it up, and it slows down again
+	node.history.record(MemberJoined, RoleSuccessor, node.GetNodeInfo(), nil)
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if got := node.stabilized(); got != want {
+			t.Errorf("Expected %v after a change, got %v", want, got)
+		}
+	}
+	select {
+	case <-node.stabilization.changed:
+	default:
+		t.Error("Expected the fix-fingers loop to be woken by the change")
+	}
+
+	status := node.StabilizationStatus()
+	if status.Interval != 4*time.Second || status.FixFingersInterval != 8*time.Second {
+		t.Errorf("Expected fix-fingers at twice the stabilization period, got %+v", status)
+	}
+	if status.Rounds != 6 || status.FixFingersRounds != 1 || status.LastChange.IsZero() {
+		t.Errorf("Unexpected round counts or last change, got %+v", status)
+	}
+}
@@ -0,0 +1,144 @@
+package chord
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"chord-dht/pkg/hash"
+)
+
+// LoadMembers reads the member list of a static ring from path: one node
+// per line, as its address or as its ID in hex followed by its address.
+// Nodes given by address alone take the ID derived from it. Blank lines
+// and lines starting with # are skipped.
+func LoadMembers(path string) ([]*NodeInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var members []*NodeInfo
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var id *hash.Hash
+		switch len(fields) {
+		case 1:
+		case 2:
+			if id, err = hash.NewHashFromHex(fields[0]); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid node ID: %w", path, line, err)
+			}
+			fields = fields[1:]
+		default:
+			return nil, fmt.Errorf("%s:%d: expected [id] address", path, line)
+		}
+		address, err := NormalizeAddress(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if id == nil {
+			id = hash.GenerateID(address)
+		}
+		members = append(members, &NodeInfo{ID: id, Address: address})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return members, nil
+}
+
+// JoinStatic enters a ring whose full membership is known up front,
+// computing the successor list, predecessor, owned range and every finger
+// directly from members instead of through a bootstrap and stabilization.
+// members must include this node. Stabilization, finger fixing and
+// predecessor checks stop for good afterwards, so the routing state stays
+// exactly as computed: this is meant for benchmarking the routing layer
+// without convergence noise, and the ring does not repair itself when
+// members fail or new nodes join.
+func (n *Node) JoinStatic(members []*NodeInfo) error {
+	ring := make([]*NodeInfo, 0, len(members))
+	seen := make(map[string]string, len(members))
+	self := -1
+	for _, member := range members {
+		if other, ok := seen[member.ID.String()]; ok {
+			if other == member.Address {
+				continue
+			}
+			return fmt.Errorf("%w: %s is held by both %s and %s",
+				ErrIDCollision, member.ID, other, member.Address)
+		}
+		seen[member.ID.String()] = member.Address
+		if member.Address == n.address {
+			if !member.ID.Equal(n.id) {
+				return fmt.Errorf("member list gives %s ID %s, the node has %s",
+					n.address, member.ID.Short(), n.id.Short())
+			}
+			member = n.GetNodeInfo()
+		}
+		ring = append(ring, member)
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].ID.Less(ring[j].ID) })
+	for i, member := range ring {
+		if member.Address == n.address {
+			self = i
+		}
+	}
+	if self < 0 {
+		return fmt.Errorf("member list does not include %s", n.address)
+	}
+
+	// The successor of a key is the first member at or after it
+	successorOf := func(key *hash.Hash) *NodeInfo {
+		i := sort.Search(len(ring), func(i int) bool { return !ring[i].ID.Less(key) })
+		return ring[i%len(ring)]
+	}
+	successor := ring[(self+1)%len(ring)]
+	var predecessor *NodeInfo
+	var successors []*NodeInfo
+	if len(ring) > 1 {
+		predecessor = ring[(self+len(ring)-1)%len(ring)]
+		for i := 1; i < len(ring) && len(successors) < SuccessorListSize; i++ {
+			successors = append(successors, ring[(self+i)%len(ring)])
+		}
+	}
+
+	n.static.Store(true)
+	n.mu.Lock()
+	n.successor = successor
+	n.successorList = successors
+	n.predecessor = predecessor
+	for i := range n.fingers {
+		n.fingers[i] = successorOf(hash.FingerStart(n.id, i+1))
+	}
+	n.mu.Unlock()
+
+	n.ownAll()
+	if predecessor != nil {
+		n.own.mu.Lock()
+		n.own.start = predecessor.ID
+		n.own.mu.Unlock()
+	}
+	n.setServing(true)
+	n.recordMembership(MemberJoined, RoleSelf, n.GetNodeInfo(), nil)
+	if predecessor != nil {
+		n.recordMembership(MemberJoined, RoleSuccessor, successor, nil)
+		n.recordMembership(MemberJoined, RolePredecessor, predecessor, nil)
+	}
+	log.Printf("Node %s joined static ring of %d members, successor: %s",
+		n.id.Short(), len(ring), successor.ID.Short())
+	return nil
+}
+
+// Static reports whether the node joined a static ring with JoinStatic,
+// so that its routing state is no longer maintained
+func (n *Node) Static() bool {
+	return n.static.Load()
+}
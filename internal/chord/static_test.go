@@ -0,0 +1,132 @@
+package chord
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestLoadMembers(t *testing.T) {
+	id := hash.NewHashFromString("chosen")
+	path := filepath.Join(t.TempDir(), "members")
+	content := "# static ring\nlocalhost:8667\n\n" + id.String() + " localhost:8668\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := LoadMembers(path)
+	if err != nil {
+		t.Fatalf("LoadMembers failed: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("Expected 2 members, got %d", len(members))
+	}
+	if !members[0].ID.Equal(hash.GenerateID("localhost:8667")) {
+		t.Errorf("Member given by address has ID %s", members[0].ID.Short())
+	}
+	if !members[1].ID.Equal(id) || members[1].Address != "localhost:8668" {
+		t.Errorf("Unexpected member %s at %s", members[1].ID.Short(), members[1].Address)
+	}
+
+	if err := os.WriteFile(path, []byte("nothex localhost:8667\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMembers(path); err == nil {
+		t.Error("Expected an invalid ID to fail")
+	}
+}
+
+func TestJoinStatic(t *testing.T) {
+	addrs := []string{"localhost:8667", "localhost:8668", "localhost:8669"}
+	nodes := make([]*Node, len(addrs))
+	members := make([]*NodeInfo, len(addrs))
+	for i, addr := range addrs {
+		nodes[i] = NewNode(addr, hash.GenerateID(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+		members[i] = nodes[i].GetNodeInfo()
+	}
+	for i, node := range nodes {
+		if err := node.JoinStatic(members); err != nil {
+			t.Fatalf("Node %d failed to join the static ring: %v", i, err)
+		}
+	}
+
+	// Every neighbor and finger is the true successor from the start
+	for _, node := range nodes {
+		if owner := node.GetSuccessor(); owner.Address != ringOwner(nodes, node.GetID().AddPowerOfTwo(0)).Address {
+			t.Errorf("Node %s has successor %s", node.GetID().Short(), owner.ID.Short())
+		}
+		if pred := node.GetPredecessor(); pred == nil || pred.Address == node.GetAddress() {
+			t.Errorf("Node %s has predecessor %v", node.GetID().Short(), pred)
+		}
+		for i, finger := range node.GetFingers() {
+			want := ringOwner(nodes, hash.FingerStart(node.GetID(), i+1))
+			if finger.Address != want.Address {
+				t.Errorf("Node %s finger %d is %s, expected %s",
+					node.GetID().Short(), i, finger.ID.Short(), want.ID.Short())
+			}
+		}
+		if _, ok := node.OwnedRange(); !ok {
+			t.Errorf("Node %s owns no range", node.GetID().Short())
+		}
+	}
+
+	key := hash.NewHashFromString("static-key")
+	for _, node := range nodes {
+		owner, err := node.Lookup(key)
+		if err != nil {
+			t.Fatalf("Lookup failed: %v", err)
+		}
+		if owner.Address != ringOwner(nodes, key).Address {
+			t.Errorf("Node %s routed %s to %s", node.GetID().Short(), key.Short(), owner.ID.Short())
+		}
+	}
+	if !nodes[0].Static() {
+		t.Error("Node not reported as static")
+	}
+
+	// Stabilization no longer runs to change what was computed
+	before := nodes[0].GetSuccessor()
+	nodes[0].stabilize()
+	nodes[0].fixFingers()
+	if after := nodes[0].GetSuccessor(); after != before {
+		t.Error("Stabilization changed the successor of a static node")
+	}
+}
+
+func TestJoinStaticRefusesBadMembers(t *testing.T) {
+	node := NewNode("localhost:8670", hash.GenerateID("localhost:8670"))
+	other := &NodeInfo{ID: hash.GenerateID("localhost:8671"), Address: "localhost:8671"}
+	if err := node.JoinStatic([]*NodeInfo{other}); err == nil {
+		t.Error("Expected a member list without the node to fail")
+	}
+	twin := &NodeInfo{ID: other.ID, Address: "localhost:8672"}
+	if err := node.JoinStatic([]*NodeInfo{node.GetNodeInfo(), other, twin}); !errors.Is(err, ErrIDCollision) {
+		t.Errorf("Expected ErrIDCollision for a duplicate ID, got %v", err)
+	}
+	if node.Static() {
+		t.Error("Refused member list left the node static")
+	}
+}
+
+// ringOwner returns the node of nodes whose ID is the first at or after key
+func ringOwner(nodes []*Node, key *hash.Hash) *NodeInfo {
+	ring := make([]*NodeInfo, len(nodes))
+	for i, node := range nodes {
+		ring[i] = node.GetNodeInfo()
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].ID.Less(ring[j].ID) })
+	for _, member := range ring {
+		if !member.ID.Less(key) {
+			return member
+		}
+	}
+	return ring[0]
+}
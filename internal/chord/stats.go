@@ -0,0 +1,163 @@
+package chord
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+)
+
+// Stats is a snapshot of a node's counters
+type Stats struct {
+	// Messages counts the RPCs the node served and Lookups the
+	// FindSuccessor requests among them, as returned by GetStats
+	Messages int64
+	Lookups  int64
+	// RPCs counts the RPCs served by method name, such as "FindSuccessor",
+	// "Notify", "Ping" or "StreamHandoff", which transfers keys
+	RPCs map[string]int64
+	// BytesSent and BytesReceived count the bytes of the node's gRPC
+	// connections, from and to peers and clients
+	BytesSent     int64
+	BytesReceived int64
+	// ActiveConnections counts the connections open from and to the node
+	ActiveConnections int
+	// StoredKeys counts the live keys in the local store
+	StoredKeys int
+	// Uptime is the time since Start, zero before it
+	Uptime time.Duration
+	// LastStabilization is when the last stabilization round ran, zero if
+	// none has
+	LastStabilization time.Time
+}
+
+// nodeStats holds the counters of Stats not kept elsewhere
+type nodeStats struct {
+	// started is when the node started serving; guarded by the node's mu
+	started time.Time
+	// rpcs maps method names to *atomic.Int64
+	rpcs           sync.Map
+	sent, received atomic.Int64
+}
+
+// Stats returns the node's counters
+func (n *Node) Stats() Stats {
+	n.mu.RLock()
+	stats := Stats{
+		Messages:          n.MessageCount.Load(),
+		Lookups:           n.LookupCount.Load(),
+		ActiveConnections: len(n.connections) + int(n.pressure.inbound.Load()),
+	}
+	if !n.stats.started.IsZero() {
+		stats.Uptime = time.Since(n.stats.started)
+	}
+	n.mu.RUnlock()
+
+	stats.RPCs = make(map[string]int64)
+	n.stats.rpcs.Range(func(method, count any) bool {
+		stats.RPCs[method.(string)] = count.(*atomic.Int64).Load()
+		return true
+	})
+	stats.BytesSent = n.stats.sent.Load()
+	stats.BytesReceived = n.stats.received.Load()
+	stats.LastStabilization = n.StabilizationStatus().LastRound
+
+	now := time.Now()
+	n.Storage().Range(func(_ string, e Entry) bool {
+		if e.Live(now) {
+			stats.StoredKeys++
+		}
+		return true
+	})
+	return stats
+}
+
+// countRPC counts a served call of fullMethod by its method name
+func (n *Node) countRPC(fullMethod string) {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	count, ok := n.stats.rpcs.Load(method)
+	if !ok {
+		count, _ = n.stats.rpcs.LoadOrStore(method, new(atomic.Int64))
+	}
+	count.(*atomic.Int64).Add(1)
+}
+
+// statsUnaryServer counts the unary calls the node serves
+func (n *Node) statsUnaryServer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	n.countRPC(info.FullMethod)
+	return handler(ctx, req)
+}
+
+// statsStreamServer counts the streams the node serves
+func (n *Node) statsStreamServer(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	n.countRPC(info.FullMethod)
+	return handler(srv, ss)
+}
+
+// meteredListener counts the bytes of the connections it accepts into the
+// node's stats
+type meteredListener struct {
+	net.Listener
+	stats *nodeStats
+}
+
+// Accept waits for and returns the next metered connection
+func (l *meteredListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &meteredConn{Conn: conn, sent: &l.stats.sent, received: &l.stats.received}, nil
+}
+
+// GetNodeStats returns the node's counters
+func (n *Node) GetNodeStats(ctx context.Context, req *pb.GetNodeStatsRequest) (*pb.GetNodeStatsResponse, error) {
+	n.countMessage()
+
+	return &pb.GetNodeStatsResponse{Stats: toProtoStats(n.Stats())}, nil
+}
+
+// toProtoStats converts a node's counters to their protobuf form
+func toProtoStats(stats Stats) *pb.NodeStats {
+	ps := &pb.NodeStats{
+		Messages:          stats.Messages,
+		Lookups:           stats.Lookups,
+		Rpcs:              stats.RPCs,
+		BytesSent:         stats.BytesSent,
+		BytesReceived:     stats.BytesReceived,
+		ActiveConnections: int32(stats.ActiveConnections),
+		StoredKeys:        int64(stats.StoredKeys),
+		UptimeMs:          stats.Uptime.Milliseconds(),
+	}
+	if !stats.LastStabilization.IsZero() {
+		ps.LastStabilizationMs = stats.LastStabilization.UnixMilli()
+	}
+	return ps
+}
+
+// StatsFromProto converts the counters received from a node
+func StatsFromProto(ps *pb.NodeStats) Stats {
+	stats := Stats{
+		Messages:          ps.GetMessages(),
+		Lookups:           ps.GetLookups(),
+		RPCs:              ps.GetRpcs(),
+		BytesSent:         ps.GetBytesSent(),
+		BytesReceived:     ps.GetBytesReceived(),
+		ActiveConnections: int(ps.GetActiveConnections()),
+		StoredKeys:        int(ps.GetStoredKeys()),
+		Uptime:            time.Duration(ps.GetUptimeMs()) * time.Millisecond,
+	}
+	if stats.RPCs == nil {
+		stats.RPCs = make(map[string]int64)
+	}
+	if ps.GetLastStabilizationMs() != 0 {
+		stats.LastStabilization = time.UnixMilli(ps.GetLastStabilizationMs())
+	}
+	return stats
+}
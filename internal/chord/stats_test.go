@@ -0,0 +1,59 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStats(t *testing.T) {
+	nodes := startTestRing(t, 8535, 2)
+	nodes[0].stabilized()
+
+	conn, err := nodes[1].ClientConn(nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	client := pb.NewChordServiceClient(conn)
+
+	// Store a key the first node owns
+	for i := 0; ; i++ {
+		_, err := client.Put(context.Background(), &pb.PutRequest{Key: fmt.Sprintf("key-%d", i), Value: []byte("value")})
+		if err == nil {
+			break
+		}
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	stats := nodes[0].Stats()
+	for _, method := range []string{"FindSuccessor", "Notify", "GetInfo", "Put"} {
+		if stats.RPCs[method] == 0 {
+			t.Errorf("Expected %s calls to be counted: %v", method, stats.RPCs)
+		}
+	}
+	if stats.Messages == 0 || stats.BytesSent == 0 || stats.BytesReceived == 0 || stats.ActiveConnections == 0 {
+		t.Errorf("Expected messages, traffic and connections to be counted: %+v", stats)
+	}
+	if stats.StoredKeys != 1 || stats.Uptime <= 0 || stats.LastStabilization.IsZero() {
+		t.Errorf("Expected one key, the uptime and the last stabilization round: %+v", stats)
+	}
+
+	// The same counters are read over gRPC
+	resp, err := client.GetNodeStats(context.Background(), &pb.GetNodeStatsRequest{})
+	if err != nil {
+		t.Fatalf("GetNodeStats failed: %v", err)
+	}
+	remote := StatsFromProto(resp.Stats)
+	if remote.StoredKeys != 1 || remote.RPCs["Put"] != stats.RPCs["Put"] || remote.Uptime < stats.Uptime.Truncate(time.Millisecond) ||
+		!remote.LastStabilization.Equal(stats.LastStabilization.Truncate(time.Millisecond)) {
+		t.Errorf("Expected the counters over gRPC, got %+v for %+v", remote, stats)
+	}
+}
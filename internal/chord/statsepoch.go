@@ -0,0 +1,140 @@
+package chord
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+const (
+	// statsEpochBroadcast is the broadcast kind starting a stats epoch
+	statsEpochBroadcast = "chord.stats-epoch"
+	// StatsEpochsKept is the number of recent epochs a node keeps its
+	// samples of
+	StatsEpochsKept = 16
+)
+
+// StatsSample is a node's counters as sampled at the start of a stats epoch
+type StatsSample struct {
+	Epoch    uint64
+	Node     *NodeInfo
+	Time     time.Time
+	Messages int64
+	Lookups  int64
+}
+
+// statsSamples is a bounded set of samples, oldest epoch first
+type statsSamples struct {
+	mu      sync.Mutex
+	samples []StatsSample
+}
+
+// StartStatsEpoch starts a new stats epoch: the epoch is broadcast through
+// the ring and every node it reaches samples its counters as it delivers
+// it, so the samples are taken within the O(log N) rounds of one broadcast
+// rather than one collection RPC after another. The samples are then
+// collected with StatsSample on every node, such as by the crawler.
+func (n *Node) StartStatsEpoch(ctx context.Context) (uint64, *BroadcastResult, error) {
+	epoch := uint64(time.Now().UnixNano())
+	payload := binary.BigEndian.AppendUint64(nil, epoch)
+	result, err := n.Broadcast(ctx, statsEpochBroadcast, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	return epoch, result, nil
+}
+
+// StatsSample returns this node's sample of an epoch, if it still keeps it
+func (n *Node) StatsSample(epoch uint64) (StatsSample, bool) {
+	n.statsSamples.mu.Lock()
+	defer n.statsSamples.mu.Unlock()
+
+	for _, sample := range n.statsSamples.samples {
+		if sample.Epoch == epoch {
+			return sample, true
+		}
+	}
+	return StatsSample{}, false
+}
+
+// sampleStats records this node's counters for an epoch
+func (n *Node) sampleStats(epoch uint64) {
+	messages, lookups := n.GetStats()
+	sample := StatsSample{
+		Epoch:    epoch,
+		Node:     n.GetNodeInfo(),
+		Time:     time.Now(),
+		Messages: messages,
+		Lookups:  lookups,
+	}
+
+	n.statsSamples.mu.Lock()
+	defer n.statsSamples.mu.Unlock()
+
+	if len(n.statsSamples.samples) == StatsEpochsKept {
+		n.statsSamples.samples = n.statsSamples.samples[1:]
+	}
+	n.statsSamples.samples = append(n.statsSamples.samples, sample)
+}
+
+// handleStatsBroadcasts registers the handler sampling counters when a
+// stats epoch starts
+func (n *Node) handleStatsBroadcasts() {
+	n.HandleBroadcast(statsEpochBroadcast, func(msg *BroadcastMessage) {
+		if len(msg.Payload) != 8 {
+			log.Printf("Node %s: dropping malformed stats epoch from %s", n.id.Short(), msg.Origin.Address)
+			return
+		}
+		n.sampleStats(binary.BigEndian.Uint64(msg.Payload))
+	})
+}
+
+// GetStatsSample returns this node's sample of a stats epoch, starting the
+// epoch first if asked to
+func (n *Node) GetStatsSample(ctx context.Context, req *pb.GetStatsSampleRequest) (*pb.GetStatsSampleResponse, error) {
+	n.countMessage()
+
+	epoch := req.Epoch
+	if req.Start {
+		var err error
+		if epoch, _, err = n.StartStatsEpoch(ctx); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	sample, ok := n.StatsSample(epoch)
+	if !ok {
+		return &pb.GetStatsSampleResponse{Found: false}, nil
+	}
+	return &pb.GetStatsSampleResponse{Sample: toProtoStatsSample(sample), Found: true}, nil
+}
+
+// toProtoStatsSample converts a stats sample to its protobuf form
+func toProtoStatsSample(sample StatsSample) *pb.StatsSample {
+	return &pb.StatsSample{
+		Node:     toProtoNode(sample.Node),
+		Epoch:    sample.Epoch,
+		TimeMs:   sample.Time.UnixMilli(),
+		Messages: sample.Messages,
+		Lookups:  sample.Lookups,
+	}
+}
+
+// StatsSampleFromProto converts a stats sample received from a node
+func StatsSampleFromProto(sample *pb.StatsSample) (StatsSample, error) {
+	node, err := fromProtoNode(sample.GetNode())
+	if err != nil {
+		return StatsSample{}, fmt.Errorf("invalid stats sample: %w", err)
+	}
+	return StatsSample{
+		Epoch:    sample.Epoch,
+		Node:     node,
+		Time:     time.UnixMilli(sample.TimeMs),
+		Messages: sample.Messages,
+		Lookups:  sample.Lookups,
+	}, nil
+}
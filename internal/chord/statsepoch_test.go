@@ -0,0 +1,38 @@
+package chord
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStatsEpoch(t *testing.T) {
+	nodes := startTestRing(t, 8481, 3)
+
+	epoch, result, err := nodes[1].StartStatsEpoch(context.Background())
+	if err != nil {
+		t.Fatalf("StartStatsEpoch failed: %v", err)
+	}
+	if result.Reached != len(nodes) {
+		t.Errorf("Expected %d nodes sampled, got %d", len(nodes), result.Reached)
+	}
+
+	for _, node := range nodes {
+		sample, ok := node.StatsSample(epoch)
+		if !ok {
+			t.Fatalf("Node %s has no sample of epoch %d", node.GetAddress(), epoch)
+		}
+		if sample.Node.Address != node.GetAddress() || sample.Messages == 0 {
+			t.Errorf("Unexpected sample %+v", sample)
+		}
+		// Counters move on after the epoch; the sample does not
+		messages, _ := node.GetStats()
+		node.Ping(context.Background(), nil)
+		if again, _ := node.StatsSample(epoch); again.Messages != sample.Messages || again.Messages > messages {
+			t.Errorf("Sample changed after the epoch: %d then %d", sample.Messages, again.Messages)
+		}
+	}
+
+	if _, ok := nodes[0].StatsSample(epoch + 1); ok {
+		t.Error("Expected no sample of an unknown epoch")
+	}
+}
@@ -0,0 +1,273 @@
+package chord
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain identifies the ErrorInfo details produced by chord nodes
+const errorDomain = "chord-dht"
+
+// Reasons carried in the ErrorInfo detail of a chord status error
+const (
+	reasonNotResponsible  = "NOT_RESPONSIBLE"
+	reasonRingUnstable    = "RING_UNSTABLE"
+	reasonRangeMoving     = "RANGE_MOVING"
+	reasonPeerUnreachable = "PEER_UNREACHABLE"
+	reasonKeyNotFound     = "KEY_NOT_FOUND"
+	reasonQuotaExceeded   = "QUOTA_EXCEEDED"
+	reasonJoinThrottled   = "JOIN_THROTTLED"
+	reasonPaused          = "PAUSED"
+	reasonOverloaded      = "OVERLOADED"
+	reasonIsolated        = "ISOLATED"
+	reasonIncompatible    = "INCOMPATIBLE_VERSION"
+	reasonTypeMismatch    = "TYPE_MISMATCH"
+	reasonWrongRing       = "WRONG_RING"
+)
+
+// Metadata keys of the ErrorInfo detail
+const (
+	metadataKey          = "key"
+	metadataOwnerID      = "owner_id"
+	metadataOwnerAddress = "owner_address"
+	// The versions the rejecting node and its caller speak
+	metadataVersion        = "version"
+	metadataMinVersion     = "min_version"
+	metadataPeerVersion    = "peer_version"
+	metadataPeerMinVersion = "peer_min_version"
+)
+
+// remoteError is an error reported by a remote node, matching the sentinel
+// it was derived from
+type remoteError struct {
+	msg  string
+	kind error
+}
+
+// Error implements the error interface
+func (e *remoteError) Error() string {
+	return "remote error: " + e.msg
+}
+
+// Unwrap returns the sentinel the remote error corresponds to
+func (e *remoteError) Unwrap() error {
+	return e.kind
+}
+
+// retryableError carries the delay a remote node asked callers to wait
+// before retrying
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+// Error implements the error interface
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// RetryDelay returns the delay a remote node asked the caller to wait before
+// retrying the failed operation, if it provided one
+func RetryDelay(err error) (time.Duration, bool) {
+	var retryable *retryableError
+	if errors.As(err, &retryable) {
+		return retryable.after, true
+	}
+	return 0, false
+}
+
+// toStatus converts an error returned by the node into a gRPC status error.
+// Typed errors get a well-defined code plus an ErrorInfo detail, and a
+// RetryInfo detail when retrying later is expected to succeed.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		code       codes.Code
+		reason     string
+		metadata   map[string]string
+		retryDelay time.Duration
+		notResp    *NotResponsibleError
+		versionErr *VersionError
+	)
+
+	switch {
+	case errors.As(err, &notResp):
+		code, reason = codes.FailedPrecondition, reasonNotResponsible
+		metadata = map[string]string{metadataKey: notResp.Key}
+		if notResp.Owner != nil {
+			metadata[metadataOwnerID] = notResp.Owner.ID.String()
+			metadata[metadataOwnerAddress] = notResp.Owner.Address
+		}
+	case errors.As(err, &versionErr):
+		code, reason = codes.FailedPrecondition, reasonIncompatible
+		metadata = map[string]string{
+			metadataVersion:        strconv.FormatUint(uint64(versionErr.Local.Max), 10),
+			metadataMinVersion:     strconv.FormatUint(uint64(versionErr.Local.Min), 10),
+			metadataPeerVersion:    strconv.FormatUint(uint64(versionErr.Peer.Max), 10),
+			metadataPeerMinVersion: strconv.FormatUint(uint64(versionErr.Peer.Min), 10),
+		}
+	case errors.Is(err, ErrNotResponsible):
+		code, reason = codes.FailedPrecondition, reasonNotResponsible
+	case errors.Is(err, ErrRingUnstable):
+		code, reason = codes.Unavailable, reasonRingUnstable
+		retryDelay = StabilizeInterval
+	case errors.Is(err, ErrRangeMoving):
+		code, reason = codes.Unavailable, reasonRangeMoving
+		retryDelay = handoffRetryDelay
+	case errors.Is(err, ErrPeerUnreachable):
+		code, reason = codes.Unavailable, reasonPeerUnreachable
+		retryDelay = StabilizeInterval
+	case errors.Is(err, ErrKeyNotFound):
+		code, reason = codes.NotFound, reasonKeyNotFound
+	case errors.Is(err, ErrQuotaExceeded):
+		code, reason = codes.ResourceExhausted, reasonQuotaExceeded
+	case errors.Is(err, ErrJoinThrottled):
+		code, reason = codes.ResourceExhausted, reasonJoinThrottled
+		retryDelay, _ = RetryDelay(err)
+	case errors.Is(err, ErrPaused):
+		code, reason = codes.Unavailable, reasonPaused
+	case errors.Is(err, ErrOverloaded):
+		code, reason = codes.ResourceExhausted, reasonOverloaded
+		retryDelay, _ = RetryDelay(err)
+	case errors.Is(err, ErrIsolated):
+		code, reason = codes.Unavailable, reasonIsolated
+		retryDelay = StabilizeInterval
+	case errors.Is(err, ErrTypeMismatch):
+		code, reason = codes.FailedPrecondition, reasonTypeMismatch
+	case errors.Is(err, ErrWrongRing):
+		code, reason = codes.FailedPrecondition, reasonWrongRing
+	default:
+		if st, ok := status.FromError(err); ok {
+			return st.Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	st := status.New(code, err.Error())
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: metadata,
+	}}
+	if retryDelay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+	if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// fromStatus converts an error returned by an RPC to the node at address back
+// into the typed error the remote handler reported. Failures that did not
+// come from a chord handler are reported as a PeerError.
+func fromStatus(address string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return &PeerError{Address: address, Err: err}
+	}
+
+	var (
+		info       *errdetails.ErrorInfo
+		retryDelay time.Duration
+	)
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Domain == errorDomain {
+				info = detail
+			}
+		case *errdetails.RetryInfo:
+			retryDelay = detail.RetryDelay.AsDuration()
+		}
+	}
+
+	if info == nil {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+			return &PeerError{Address: address, Err: err}
+		default:
+			return err
+		}
+	}
+
+	var result error
+	switch info.Reason {
+	case reasonNotResponsible:
+		notResp := &NotResponsibleError{Key: info.Metadata[metadataKey]}
+		if ownerAddr := info.Metadata[metadataOwnerAddress]; ownerAddr != "" {
+			if ownerID, idErr := hash.NewHashFromHex(info.Metadata[metadataOwnerID]); idErr == nil {
+				notResp.Owner = &NodeInfo{ID: ownerID, Address: ownerAddr}
+			}
+		}
+		result = notResp
+	case reasonIncompatible:
+		// The rejecting node's versions are the peer's from here
+		version := func(key string) uint32 {
+			v, _ := strconv.ParseUint(info.Metadata[key], 10, 32)
+			return uint32(v)
+		}
+		result = &VersionError{
+			Address: address,
+			Peer:    VersionRange{Min: version(metadataMinVersion), Max: version(metadataVersion)},
+			Local:   VersionRange{Min: version(metadataPeerMinVersion), Max: version(metadataPeerVersion)},
+		}
+	case reasonRingUnstable:
+		result = &remoteError{msg: st.Message(), kind: ErrRingUnstable}
+	case reasonRangeMoving:
+		result = &remoteError{msg: st.Message(), kind: ErrRangeMoving}
+	case reasonPeerUnreachable:
+		result = &remoteError{msg: st.Message(), kind: ErrPeerUnreachable}
+	case reasonKeyNotFound:
+		result = &remoteError{msg: st.Message(), kind: ErrKeyNotFound}
+	case reasonQuotaExceeded:
+		result = &remoteError{msg: st.Message(), kind: ErrQuotaExceeded}
+	case reasonJoinThrottled:
+		result = &remoteError{msg: st.Message(), kind: ErrJoinThrottled}
+	case reasonPaused:
+		result = &remoteError{msg: st.Message(), kind: ErrPaused}
+	case reasonOverloaded:
+		result = &remoteError{msg: st.Message(), kind: ErrOverloaded}
+	case reasonIsolated:
+		result = &remoteError{msg: st.Message(), kind: ErrIsolated}
+	case reasonTypeMismatch:
+		result = &remoteError{msg: st.Message(), kind: ErrTypeMismatch}
+	case reasonWrongRing:
+		result = &remoteError{msg: st.Message(), kind: ErrWrongRing}
+	default:
+		result = &remoteError{msg: st.Message()}
+	}
+
+	if retryDelay > 0 {
+		result = &retryableError{err: result, after: retryDelay}
+	}
+	return result
+}
+
+// FromStatus converts an error returned by an RPC to the node at address
+// into the typed error its handler reported, for tools that call nodes
+// directly
+func FromStatus(address string, err error) error {
+	return fromStatus(address, err)
+}
@@ -0,0 +1,68 @@
+package chord
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{fmt.Errorf("%w: node has not joined a ring", ErrRingUnstable), codes.Unavailable},
+		{&PeerError{Address: "localhost:1", Err: errors.New("connection refused")}, codes.Unavailable},
+		{fmt.Errorf("%w: missing", ErrKeyNotFound), codes.NotFound},
+		{ErrQuotaExceeded, codes.ResourceExhausted},
+		{ErrOverloaded, codes.ResourceExhausted},
+		{&NotResponsibleError{Key: "key"}, codes.FailedPrecondition},
+	}
+
+	for _, test := range tests {
+		wire := toStatus(test.err)
+		if status.Code(wire) != test.code {
+			t.Errorf("toStatus(%v) code = %v, expected %v", test.err, status.Code(wire), test.code)
+		}
+
+		back := fromStatus("localhost:1", wire)
+		for _, sentinel := range []error{ErrNotResponsible, ErrRingUnstable, ErrPeerUnreachable, ErrKeyNotFound, ErrQuotaExceeded} {
+			if errors.Is(test.err, sentinel) != errors.Is(back, sentinel) {
+				t.Errorf("Round trip of %v changed errors.Is(%v): got %v", test.err, sentinel, back)
+			}
+		}
+	}
+}
+
+func TestStatusDetails(t *testing.T) {
+	owner := &NodeInfo{ID: hash.NewHashFromString("owner"), Address: "localhost:9999"}
+	back := fromStatus("localhost:1", toStatus(&NotResponsibleError{Key: "key", Owner: owner}))
+
+	hint := ownerHint(back)
+	if hint == nil {
+		t.Fatal("Owner hint lost over the wire")
+	}
+	if hint.Address != owner.Address || !hint.ID.Equal(owner.ID) {
+		t.Errorf("Expected owner %s, got %s", owner.Address, hint.Address)
+	}
+
+	delay, ok := RetryDelay(fromStatus("localhost:1", toStatus(ErrRingUnstable)))
+	if !ok || delay != StabilizeInterval {
+		t.Errorf("Expected retry delay %v, got %v (ok=%v)", StabilizeInterval, delay, ok)
+	}
+
+	if _, ok := RetryDelay(fromStatus("localhost:1", toStatus(ErrKeyNotFound))); ok {
+		t.Error("ErrKeyNotFound should not carry a retry delay")
+	}
+
+	// Errors without chord details are transport failures
+	transport := status.Error(codes.Unavailable, "connection refused")
+	if !errors.Is(fromStatus("localhost:1", transport), ErrPeerUnreachable) {
+		t.Error("Unavailable without details should map to ErrPeerUnreachable")
+	}
+}
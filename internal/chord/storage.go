@@ -0,0 +1,446 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetHashProvider sets how keys are placed on the ring, such as
+// hash.Identity for tests that assert exact placements. A nil provider
+// restores hash.SHA1. It must be called before Start, and every node of the
+// ring must use the same provider.
+func (n *Node) SetHashProvider(provider hash.Provider) {
+	if provider == nil {
+		provider = hash.SHA1
+	}
+	n.hasher = provider
+}
+
+// KeyID returns the position of key on the ring. The entries of the tag
+// index are placed at their tag instead (see tags.go).
+func (n *Node) KeyID(key string) *hash.Hash {
+	return n.hasher.Hash(placement(key))
+}
+
+// StoreValue stores a key/value pair on the node responsible for the key
+func (n *Node) StoreValue(ctx context.Context, key string, value []byte) error {
+	return n.StoreBatch(ctx, map[string][]byte{key: value})
+}
+
+// FetchValue retrieves the value for a key from the node responsible for it.
+// With replication enabled the copy read from is chosen by the replica
+// selector, and the read is hedged if configured with SetHedging. Hot keys
+// are read from the copies seen on their lookup path as well, unless ctx
+// asks for ConsistencyStrong. It returns ErrKeyNotFound if the key is not
+// stored in the ring.
+func (n *Node) FetchValue(ctx context.Context, key string) ([]byte, error) {
+	// Hot keys may be read from a copy on their lookup path (see hotkeys.go)
+	if value, ok := n.fetchHotCopy(ctx, key); ok {
+		return value, nil
+	}
+	if n.Replication() > 1 {
+		return n.replicatedFetch(ctx, key)
+	}
+
+	values, err := n.FetchBatch(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	value, found := values[key]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return value, nil
+}
+
+// StoreBatch stores many key/value pairs, grouping keys by responsible node
+// so that a single PutBatch RPC is issued per destination
+func (n *Node) StoreBatch(ctx context.Context, items map[string][]byte) error {
+	keys := make([]string, 0, len(items))
+	all := make([]*pb.KeyValue, 0, len(items))
+	for key, value := range items {
+		keys = append(keys, key)
+		all = append(all, &pb.KeyValue{Key: key, Value: value})
+	}
+	if buffered, err := n.bufferIsolated(all); buffered || err != nil {
+		return err
+	}
+
+	groups, err := n.groupByOwner(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for address, groupKeys := range groups {
+		batch := make([]*pb.KeyValue, 0, len(groupKeys))
+		for _, key := range groupKeys {
+			batch = append(batch, &pb.KeyValue{Key: key, Value: items[key]})
+		}
+
+		wg.Add(1)
+		go func(address string, batch []*pb.KeyValue) {
+			defer wg.Done()
+			err := n.RetryPolicies().Client.Do(ctx, func(int) error {
+				err := n.putBatchAt(ctx, address, batch)
+				if owner := ownerHint(err); owner != nil {
+					// Follow the responsible node hint
+					address = owner.Address
+				}
+				return err
+			})
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+		}(address, batch)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// FetchBatch retrieves many keys, grouping them by responsible node so that
+// a single GetBatch RPC is issued per destination. Keys that are not stored
+// anywhere are absent from the returned map.
+func (n *Node) FetchBatch(ctx context.Context, keys []string) (map[string][]byte, error) {
+	groups, err := n.groupByOwner(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	result := make(map[string][]byte, len(keys))
+	for address, groupKeys := range groups {
+		wg.Add(1)
+		go func(address string, groupKeys []string) {
+			defer wg.Done()
+			var items []*pb.KeyValue
+			err := n.RetryPolicies().Client.Do(ctx, func(int) error {
+				var err error
+				items, err = n.getBatchAt(ctx, address, groupKeys)
+				if owner := ownerHint(err); owner != nil {
+					// Follow the responsible node hint
+					address = owner.Address
+				}
+				return err
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for _, item := range items {
+				result[item.Key] = item.Value
+			}
+		}(address, groupKeys)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// groupByOwner resolves the responsible node for each key and groups the
+// keys by that node's address
+func (n *Node) groupByOwner(ctx context.Context, keys []string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		owner, err := n.findSuccessor(ctx, n.KeyID(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find owner of key %q: %w", key, err)
+		}
+		groups[owner.Address] = append(groups[owner.Address], key)
+	}
+	return groups, nil
+}
+
+// ownerHint returns the responsible node suggested by a NotResponsibleError
+func ownerHint(err error) *NodeInfo {
+	var notResp *NotResponsibleError
+	if errors.As(err, &notResp) {
+		return notResp.Owner
+	}
+	return nil
+}
+
+// putBatchAt stores a batch on the node at address, short-circuiting locally
+func (n *Node) putBatchAt(ctx context.Context, address string, batch []*pb.KeyValue) error {
+	if address == n.address {
+		if err := n.storeLocal(batch); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(batch))
+		for _, item := range batch {
+			keys = append(keys, item.Key)
+		}
+		n.replicateKeys(ctx, keys)
+		n.invalidateKeys(ctx, keys)
+		return nil
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.PutBatch(ctx, &pb.PutBatchRequest{Items: batch})
+	if err != nil {
+		return fromStatus(address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("put batch to %s failed: %s", address, resp.Error)
+	}
+	return nil
+}
+
+// getBatchAt fetches a batch from the node at address, short-circuiting locally
+func (n *Node) getBatchAt(ctx context.Context, address string, keys []string) ([]*pb.KeyValue, error) {
+	if address == n.address {
+		items, _, err := n.loadServed(ctx, keys)
+		if err == nil {
+			n.hotKeys.record(keys)
+		}
+		return items, err
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.GetBatch(ctx, &pb.GetBatchRequest{Keys: keys})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("get batch from %s failed: %s", address, resp.Error)
+	}
+	return resp.Items, nil
+}
+
+// checkResponsible returns a NotResponsibleError for the first key that falls
+// outside the range this node owns, and for writes ErrRangeMoving for a key
+// frozen by a hand-off. A NotResponsibleError carries the owner this node
+// resolves for the key as a hint, or the successor while the node is still
+// waiting to take over its range.
+func (n *Node) checkResponsible(keys []string, write bool) error {
+	for _, key := range keys {
+		err := n.checkOwned(key, write)
+		var notResp *NotResponsibleError
+		if errors.As(err, &notResp) {
+			if owner, findErr := n.findSuccessor(n.ctx, n.KeyID(key)); findErr == nil && owner.Address != n.address {
+				notResp.Owner = owner
+			} else if successor := n.GetSuccessor(); successor != nil && successor.Address != n.address {
+				notResp.Owner = successor
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeLocal writes a batch into the local store. Ownership is re-checked
+// under dataMu so that no write lands in a range once it is being handed off.
+func (n *Node) storeLocal(batch []*pb.KeyValue) error {
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	for _, item := range batch {
+		if err := n.checkOwned(item.Key, true); err != nil {
+			return err
+		}
+	}
+	if err := n.checkBucketQuotas(batch); err != nil {
+		return err
+	}
+	if err := n.reserveStorage(batch, true); err != nil {
+		return err
+	}
+	for _, item := range batch {
+		if _, err := n.writeLocked(item.Key, item.Value, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLocked writes a key, bumping its version. A positive ttl makes the
+// value expire. The caller must hold dataMu.
+func (n *Node) writeLocked(key string, value []byte, ttl time.Duration) (Entry, error) {
+	e, _, err := n.storage.Get(key)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+	e.Value = value
+	e.Version++
+	e.ExpiresAt = time.Time{}
+	e.DeletedAt = time.Time{}
+	if ttl > 0 {
+		e.ExpiresAt = time.Now().Add(ttl)
+	}
+	if err := n.storage.Put(key, e); err != nil {
+		return Entry{}, fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	n.trash.drop(key)
+	n.buckets.countOps([]string{key}, true)
+	n.touchKeys([]string{key})
+	return e, nil
+}
+
+// loadLocal reads the given keys from the local store, skipping missing keys
+func (n *Node) loadLocal(keys []string) ([]*pb.KeyValue, error) {
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	n.buckets.countOps(keys, false)
+	n.touchKeys(keys)
+	now := time.Now()
+	items := make([]*pb.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		e, ok, err := n.storage.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", key, err)
+		}
+		if ok && e.Live(now) {
+			items = append(items, &pb.KeyValue{Key: key, Value: e.Value})
+		}
+	}
+	return items, nil
+}
+
+// Put stores a key/value pair on this node
+func (n *Node) Put(ctx context.Context, req *pb.PutRequest) (*pb.PutResponse, error) {
+	n.countMessage()
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+	item := &pb.KeyValue{Key: req.Key, Value: req.Value}
+	if buffered, err := n.bufferIsolated([]*pb.KeyValue{item}); err != nil {
+		return nil, toStatus(err)
+	} else if buffered {
+		return &pb.PutResponse{Success: true, Buffered: true}, nil
+	}
+	if err := n.checkResponsible([]string{req.Key}, true); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := n.storeLocal([]*pb.KeyValue{item}); err != nil {
+		return nil, toStatus(err)
+	}
+	n.replicateKeys(ctx, []string{req.Key})
+	n.invalidateKeys(ctx, []string{req.Key})
+	return &pb.PutResponse{Success: true}, nil
+}
+
+// Get retrieves a value stored on this node
+func (n *Node) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	n.countMessage()
+
+	// Replica reads, and every read while isolated, are answered from
+	// whatever copy this node holds
+	if !req.Replica && !n.Isolated() {
+		if err := n.checkResponsible([]string{req.Key}, false); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+
+	items, stale, err := n.loadServed(ctx, []string{req.Key})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if !req.Replica {
+		n.hotKeys.record([]string{req.Key})
+	}
+	if len(items) == 0 {
+		// Replica reads are also answered from hot copies (see hotkeys.go)
+		if req.Replica {
+			if value, ok := n.hotKeys.copyOf(req.Key); ok {
+				return &pb.GetResponse{Value: value, Found: true, Success: true}, nil
+			}
+		}
+		return &pb.GetResponse{Found: false, Success: true, Stale: stale}, nil
+	}
+	return &pb.GetResponse{Value: items[0].Value, Found: true, Success: true, Stale: stale}, nil
+}
+
+// PutBatch stores a batch of key/value pairs on this node
+func (n *Node) PutBatch(ctx context.Context, req *pb.PutBatchRequest) (*pb.PutBatchResponse, error) {
+	n.countMessage()
+
+	keys := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Key == "" {
+			return nil, status.Error(codes.InvalidArgument, "empty key in batch")
+		}
+		keys = append(keys, item.Key)
+	}
+	if buffered, err := n.bufferIsolated(req.Items); err != nil {
+		return nil, toStatus(err)
+	} else if buffered {
+		return &pb.PutBatchResponse{Success: true, Buffered: true}, nil
+	}
+	if err := n.checkResponsible(keys, true); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := n.storeLocal(req.Items); err != nil {
+		return nil, toStatus(err)
+	}
+	n.replicateKeys(ctx, keys)
+	n.invalidateKeys(ctx, keys)
+	return &pb.PutBatchResponse{Success: true}, nil
+}
+
+// GetBatch retrieves a batch of keys stored on this node
+func (n *Node) GetBatch(ctx context.Context, req *pb.GetBatchRequest) (*pb.GetBatchResponse, error) {
+	n.countMessage()
+
+	if !n.Isolated() {
+		if err := n.checkResponsible(req.Keys, false); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+
+	items, stale, err := n.loadServed(ctx, req.Keys)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	n.hotKeys.record(req.Keys)
+	return &pb.GetBatchResponse{Items: items, Success: true, Stale: stale}, nil
+}
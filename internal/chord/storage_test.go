@@ -0,0 +1,205 @@
+package chord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStoreFetchBatchSingleNode(t *testing.T) {
+	node := NewNode("localhost:8100", hash.NewHashFromString("storage-node"))
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer node.Stop()
+
+	if err := node.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 20; i++ {
+		items[fmt.Sprintf("key-%d", i)] = []byte(fmt.Sprintf("value-%d", i))
+	}
+
+	if err := node.StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	keys := []string{"key-0", "key-7", "missing"}
+	values, err := node.FetchBatch(ctx, keys)
+	if err != nil {
+		t.Fatalf("FetchBatch failed: %v", err)
+	}
+
+	if len(values) != 2 {
+		t.Errorf("Expected 2 values, got %d", len(values))
+	}
+	if !bytes.Equal(values["key-7"], []byte("value-7")) {
+		t.Errorf("Unexpected value for key-7: %q", values["key-7"])
+	}
+	if _, found := values["missing"]; found {
+		t.Error("Missing key should not be returned")
+	}
+}
+
+func TestStoreBatchRemoteOwner(t *testing.T) {
+	bootstrap := NewNode("localhost:8101", hash.NewHashFromString("storage-bootstrap"))
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start bootstrap: %v", err)
+	}
+	defer bootstrap.Stop()
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	node := NewNode("localhost:8102", hash.NewHashFromString("storage-joiner"))
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer node.Stop()
+	if err := node.Join(bootstrap.GetAddress()); err != nil {
+		t.Fatalf("Failed to join ring: %v", err)
+	}
+
+	// Settle successor and predecessor pointers on both nodes
+	node.stabilize()
+	bootstrap.stabilize()
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 20; i++ {
+		items[fmt.Sprintf("remote-key-%d", i)] = []byte(fmt.Sprintf("remote-value-%d", i))
+	}
+	if err := node.StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	// Every key must land on the node responsible for it
+	for key := range items {
+		owner := node
+		if !hash.NewHashFromString(key).InRange(bootstrap.GetID(), node.GetID()) {
+			owner = bootstrap
+		}
+		if stored, _ := owner.loadLocal([]string{key}); len(stored) != 1 {
+			t.Errorf("Key %s should be stored on %s", key, owner.GetAddress())
+		}
+	}
+
+	value, err := bootstrap.FetchValue(ctx, "remote-key-3")
+	if err != nil {
+		t.Fatalf("FetchValue failed: %v", err)
+	}
+	if !bytes.Equal(value, []byte("remote-value-3")) {
+		t.Errorf("Expected remote-value-3, got %q", value)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	bootstrap := NewNode("localhost:8103", hash.NewHashFromString("errors-bootstrap"))
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start bootstrap: %v", err)
+	}
+	defer bootstrap.Stop()
+
+	ctx := context.Background()
+
+	// A node that has not joined a ring cannot route
+	if err := bootstrap.StoreValue(ctx, "key", []byte("value")); !errors.Is(err, ErrRingUnstable) {
+		t.Errorf("Expected ErrRingUnstable, got %v", err)
+	}
+
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	if _, err := bootstrap.FetchValue(ctx, "missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+
+	// Pretend the range up to another node was handed off and ask for a key
+	// outside our range
+	other := hash.NewHashFromString("errors-other")
+	bootstrap.own.mu.Lock()
+	bootstrap.own.start = other
+	bootstrap.own.mu.Unlock()
+
+	var foreign string
+	for i := 0; ; i++ {
+		foreign = fmt.Sprintf("foreign-%d", i)
+		if !hash.NewHashFromString(foreign).InRange(other, bootstrap.GetID()) {
+			break
+		}
+	}
+	_, err := bootstrap.Get(ctx, &pb.GetRequest{Key: foreign})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", err)
+	}
+	if !errors.Is(fromStatus(bootstrap.GetAddress(), err), ErrNotResponsible) {
+		t.Errorf("Expected ErrNotResponsible over the wire, got %v", err)
+	}
+
+	// Unreachable peers are reported as such
+	if _, err := bootstrap.askHop(ctx, "localhost:1", &pb.FindSuccessorRequest{Key: other.String()}); !errors.Is(err, ErrPeerUnreachable) {
+		t.Errorf("Expected ErrPeerUnreachable, got %v", err)
+	}
+}
+
+func TestIdentityHashPlacement(t *testing.T) {
+	// Nodes at positions 100, 200 and 300
+	nodes := make([]*Node, 3)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", 8469+i)
+		nodes[i] = NewNode(addr, hash.Identity.Hash(fmt.Sprint(100*(i+1))))
+		nodes[i].SetHashProvider(hash.Identity)
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node: %v", err)
+		}
+		t.Cleanup(nodes[i].Stop)
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join: %v", err)
+		}
+	}
+	for round := 0; round < 4; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+
+	owners := map[string]*Node{
+		"key-150": nodes[1],
+		"key-200": nodes[1],
+		"key-201": nodes[2],
+		"key-350": nodes[0],
+		"key-50":  nodes[0],
+	}
+	ctx := context.Background()
+	for key, owner := range owners {
+		if err := nodes[2].StoreValue(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("StoreValue(%q) failed: %v", key, err)
+		}
+		for _, node := range nodes {
+			_, found, err := node.Storage().Get(key)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if found != (node == owner) {
+				t.Errorf("Key %q on %s: found=%v, expected it only on %s",
+					key, node.GetAddress(), found, owner.GetAddress())
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package chord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// tagIndexPrefix starts the keys of index entries,
+	// tagIndexPrefix+tag+"\x00"+key, which are placed on the ring at the tag
+	// so that a tag's entries are all on one node
+	tagIndexPrefix = "\x00tag\x00"
+	// tagListPrefix starts the key holding the tags of a key,
+	// tagListPrefix+key, which is placed at the key next to its value
+	tagListPrefix = "\x00tags\x00"
+)
+
+// tagMarker is the value of every index entry
+var tagMarker = []byte{1}
+
+// placement returns the string whose hash places key on the ring: the tag
+// of an index entry, the key of a tag list, and key itself otherwise
+func placement(key string) string {
+	if rest, ok := strings.CutPrefix(key, tagIndexPrefix); ok {
+		tag, _, _ := strings.Cut(rest, "\x00")
+		return tag
+	}
+	if rest, ok := strings.CutPrefix(key, tagListPrefix); ok {
+		return rest
+	}
+	return key
+}
+
+// tagEntryKey returns the key of the index entry for key under tag
+func tagEntryKey(tag, key string) string {
+	return tagIndexPrefix + tag + "\x00" + key
+}
+
+// StoreTagged stores a key/value pair like StoreValue and indexes the key
+// under tags, replacing the tags it was stored with before. Each tag's
+// index lives on the node responsible for the tag, where QueryByTag reads
+// it. The value, the index entries of added tags and the removal of those
+// of dropped tags are separate writes: a failure part way leaves stale
+// entries, which queries skip.
+func (n *Node) StoreTagged(ctx context.Context, key string, value []byte, tags []string) error {
+	if key == "" || strings.HasPrefix(key, "\x00") {
+		return fmt.Errorf("invalid key %q for tagging", key)
+	}
+	tags = uniqueTags(tags)
+	for _, tag := range tags {
+		if tag == "" || strings.Contains(tag, "\x00") {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+
+	previous, err := n.FetchTags(ctx, key)
+	if err != nil {
+		return err
+	}
+	list, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode tags of %q: %w", key, err)
+	}
+	// The value and its tag list are placed at the key, on the same node
+	if err := n.StoreBatch(ctx, map[string][]byte{key: value, tagListPrefix + key: list}); err != nil {
+		return err
+	}
+
+	if len(tags) > 0 {
+		entries := make(map[string][]byte, len(tags))
+		for _, tag := range tags {
+			entries[tagEntryKey(tag, key)] = tagMarker
+		}
+		if err := n.StoreBatch(ctx, entries); err != nil {
+			return fmt.Errorf("failed to index %q: %w", key, err)
+		}
+	}
+
+	kept := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		kept[tag] = true
+	}
+	for _, tag := range previous {
+		if kept[tag] {
+			continue
+		}
+		_, err := n.CompareAndSwap(ctx, ConditionalWrite{Key: tagEntryKey(tag, key), Expected: tagMarker, Delete: true})
+		if err != nil {
+			return fmt.Errorf("failed to drop %q from tag %q: %w", key, tag, err)
+		}
+	}
+	return nil
+}
+
+// FetchTags returns the tags key was stored with by StoreTagged, sorted, and
+// none if it was not
+func (n *Node) FetchTags(ctx context.Context, key string) ([]string, error) {
+	list, err := n.FetchValue(ctx, tagListPrefix+key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags of %q: %w", key, err)
+	}
+	return decodeTags(key, list)
+}
+
+// decodeTags decodes the tag list of key
+func decodeTags(key string, list []byte) ([]string, error) {
+	var tags []string
+	if err := json.Unmarshal(list, &tags); err != nil {
+		return nil, fmt.Errorf("invalid tags of %q: %w", key, err)
+	}
+	return tags, nil
+}
+
+// uniqueTags returns tags sorted without duplicates
+func uniqueTags(tags []string) []string {
+	unique := append([]string(nil), tags...)
+	sort.Strings(unique)
+	out := unique[:0]
+	for i, tag := range unique {
+		if i == 0 || tag != unique[i-1] {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// QueryByTag returns the keys stored with tag by StoreTagged and their
+// values. The tag's index is read from the node responsible for the tag;
+// keys since deleted or stored again without the tag are left out.
+func (n *Node) QueryByTag(ctx context.Context, tag string) (map[string][]byte, error) {
+	if tag == "" || strings.Contains(tag, "\x00") {
+		return nil, fmt.Errorf("invalid tag %q", tag)
+	}
+
+	var keys []string
+	err := n.RetryPolicies().Client.Do(ctx, func(int) error {
+		owner, err := n.findSuccessor(ctx, n.KeyID(tagEntryKey(tag, "")))
+		if err != nil {
+			return fmt.Errorf("failed to find owner of tag %q: %w", tag, err)
+		}
+		keys, err = n.queryTagAt(ctx, owner.Address, tag)
+		if hint := ownerHint(err); hint != nil && hint.Address != owner.Address {
+			// Follow the responsible node hint once
+			keys, err = n.queryTagAt(ctx, hint.Address, tag)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	// Values and tag lists share owners, so this is one batch per owner
+	fetch := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		fetch = append(fetch, key, tagListPrefix+key)
+	}
+	values, err := n.FetchBatch(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		tags, err := decodeTags(key, values[tagListPrefix+key])
+		if err != nil {
+			continue
+		}
+		if i := sort.SearchStrings(tags, tag); i < len(tags) && tags[i] == tag {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+// queryTagAt reads the index of tag on the node at address,
+// short-circuiting locally
+func (n *Node) queryTagAt(ctx context.Context, address, tag string) ([]string, error) {
+	if address == n.address {
+		if err := n.checkOwned(tagEntryKey(tag, ""), false); err != nil {
+			return nil, err
+		}
+		return n.localTagIndex(tag)
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.QueryTag(ctx, &pb.QueryTagRequest{Tag: tag})
+	if err != nil {
+		return nil, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("query of tag %q on %s failed: %s", tag, address, resp.Error)
+	}
+	return resp.Keys, nil
+}
+
+// localTagIndex returns the keys indexed under tag in the local store
+func (n *Node) localTagIndex(tag string) ([]string, error) {
+	prefix := tagEntryKey(tag, "")
+
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	now := time.Now()
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if indexed, ok := strings.CutPrefix(key, prefix); ok && e.Live(now) {
+			keys = append(keys, indexed)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index of tag %q: %w", tag, err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// QueryTag returns the keys indexed under a tag this node owns
+func (n *Node) QueryTag(ctx context.Context, req *pb.QueryTagRequest) (*pb.QueryTagResponse, error) {
+	n.countMessage()
+
+	if req.Tag == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty tag")
+	}
+	if !n.Isolated() {
+		if err := n.checkResponsible([]string{tagEntryKey(req.Tag, "")}, false); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	keys, err := n.localTagIndex(req.Tag)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.QueryTagResponse{Keys: keys, Success: true}, nil
+}
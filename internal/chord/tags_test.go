@@ -0,0 +1,91 @@
+package chord
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestQueryByTag(t *testing.T) {
+	nodes := startTestRing(t, 8570, 3)
+	ctx := context.Background()
+
+	if err := nodes[0].StoreTagged(ctx, "alice", []byte("a"), []string{"admin", "staff", "admin"}); err != nil {
+		t.Fatalf("StoreTagged failed: %v", err)
+	}
+	if err := nodes[1].StoreTagged(ctx, "bob", []byte("b"), []string{"staff"}); err != nil {
+		t.Fatalf("StoreTagged failed: %v", err)
+	}
+	if err := nodes[2].StoreValue(ctx, "carol", []byte("c")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+
+	// Index entries are placed at their tag, tag lists at their key
+	if !nodes[0].KeyID(tagEntryKey("staff", "alice")).Equal(nodes[0].KeyID("staff")) {
+		t.Error("Expected index entries to be placed at their tag")
+	}
+	if !nodes[0].KeyID(tagListPrefix + "alice").Equal(nodes[0].KeyID("alice")) {
+		t.Error("Expected tag lists to be placed at their key")
+	}
+
+	for _, node := range nodes {
+		staff, err := node.QueryByTag(ctx, "staff")
+		if err != nil {
+			t.Fatalf("QueryByTag failed: %v", err)
+		}
+		if want := map[string][]byte{"alice": []byte("a"), "bob": []byte("b")}; !reflect.DeepEqual(staff, want) {
+			t.Errorf("Expected staff %v, got %v", want, staff)
+		}
+	}
+	tags, err := nodes[2].FetchTags(ctx, "alice")
+	if err != nil || !reflect.DeepEqual(tags, []string{"admin", "staff"}) {
+		t.Errorf("Expected alice's tags [admin staff], got %v, %v", tags, err)
+	}
+
+	// Retagging drops the key from the tags it lost
+	if err := nodes[2].StoreTagged(ctx, "alice", []byte("a2"), []string{"admin"}); err != nil {
+		t.Fatalf("StoreTagged failed: %v", err)
+	}
+	staff, err := nodes[0].QueryByTag(ctx, "staff")
+	if err != nil {
+		t.Fatalf("QueryByTag failed: %v", err)
+	}
+	if want := map[string][]byte{"bob": []byte("b")}; !reflect.DeepEqual(staff, want) {
+		t.Errorf("Expected staff %v after retagging, got %v", want, staff)
+	}
+	owner, err := nodes[0].LookupContext(ctx, nodes[0].KeyID("staff"))
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	for _, node := range nodes {
+		if node.GetAddress() != owner.Address {
+			continue
+		}
+		if keys, err := node.localTagIndex("staff"); err != nil || !reflect.DeepEqual(keys, []string{"bob"}) {
+			t.Errorf("Expected the staff index to hold [bob], got %v, %v", keys, err)
+		}
+	}
+	admin, err := nodes[1].QueryByTag(ctx, "admin")
+	if err != nil {
+		t.Fatalf("QueryByTag failed: %v", err)
+	}
+	if want := map[string][]byte{"alice": []byte("a2")}; !reflect.DeepEqual(admin, want) {
+		t.Errorf("Expected admin %v, got %v", want, admin)
+	}
+
+	// Deleted keys are left out even while indexed
+	if _, err := nodes[0].CompareAndSwap(ctx, ConditionalWrite{Key: "bob", Expected: []byte("b"), Delete: true}); err != nil {
+		t.Fatalf("CompareAndSwap failed: %v", err)
+	}
+	staff, err = nodes[0].QueryByTag(ctx, "staff")
+	if err != nil {
+		t.Fatalf("QueryByTag failed: %v", err)
+	}
+	if len(staff) != 0 {
+		t.Errorf("Expected no staff after deleting bob, got %v", staff)
+	}
+
+	if err := nodes[0].StoreTagged(ctx, "dave", nil, []string{"bad\x00tag"}); err == nil {
+		t.Error("Expected a tag with a NUL byte to be rejected")
+	}
+}
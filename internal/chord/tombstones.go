@@ -0,0 +1,148 @@
+package chord
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTombstoneHorizon is how long a deleted key's tombstone is kept
+	// unless set with SetTombstones
+	DefaultTombstoneHorizon = 24 * time.Hour
+	// DefaultTombstoneReapInterval is how often tombstones past the horizon
+	// are looked for when the policy leaves the interval zero
+	DefaultTombstoneReapInterval = time.Minute
+	// LockKeyPrefix namespaces the keys of the lock package. Their
+	// tombstones are never reaped: a lock's version is its fencing token,
+	// which must keep growing across a release and the next acquisition
+	// however long the lock sat free.
+	LockKeyPrefix = "lock/"
+)
+
+// TombstonePolicy configures how long deletes are remembered. A delete
+// leaves a tombstone: an entry without a value whose version is newer than
+// the deleted one. Tombstones are replicated and handed off like values, so
+// a replica still holding the old value is overwritten rather than serving
+// it, or copying it back, after a re-replication or a range move. Once a
+// tombstone is older than the horizon, a background reaper removes it.
+// A replica that missed the delete and stayed away for longer than the
+// horizon can bring the value back, so the horizon should outlast the
+// longest outage a node is expected to recover from. Tombstones under
+// LockKeyPrefix are kept whatever the horizon.
+type TombstonePolicy struct {
+	// Horizon is how long a tombstone is kept after the delete, zero
+	// keeping tombstones forever
+	Horizon time.Duration
+	// Interval is how often the reaper runs, DefaultTombstoneReapInterval
+	// if zero
+	Interval time.Duration
+}
+
+// tombstones holds the node's tombstone policy
+type tombstones struct {
+	mu      sync.Mutex
+	policy  TombstonePolicy
+	changed chan struct{}
+}
+
+// SetTombstones sets how long the node keeps the tombstones of deleted keys
+func (n *Node) SetTombstones(policy TombstonePolicy) {
+	n.tombstones.mu.Lock()
+	n.tombstones.policy = policy
+	n.tombstones.mu.Unlock()
+
+	select {
+	case n.tombstones.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Tombstones returns the policy set with SetTombstones
+func (n *Node) Tombstones() TombstonePolicy {
+	n.tombstones.mu.Lock()
+	defer n.tombstones.mu.Unlock()
+
+	return n.tombstones.policy
+}
+
+// reapable reports whether e, stored under key, is a tombstone past the
+// horizon at now. Such a tombstone counts as absent even before it is
+// reaped, so a key written again after its owner reaped the tombstone is not
+// refused by a replica that has yet to reap its own.
+func (p TombstonePolicy) reapable(key string, e Entry, now time.Time) bool {
+	if strings.HasPrefix(key, LockKeyPrefix) {
+		return false
+	}
+	return e.Tombstone() && p.Horizon > 0 && !now.Before(e.DeletedAt.Add(p.Horizon))
+}
+
+// ReapTombstones removes the tombstones past the horizon from the local
+// store and returns how many it removed
+func (n *Node) ReapTombstones() (int, error) {
+	policy := n.Tombstones()
+	if policy.Horizon <= 0 {
+		return 0, nil
+	}
+
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	now := time.Now()
+	var keys []string
+	err := n.storage.Range(func(key string, e Entry) bool {
+		if policy.reapable(key, e, now) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		if err := n.storage.Delete(key); err != nil {
+			n.instruments.Load().tombstonesReaped.Add(int64(i))
+			return i, err
+		}
+	}
+	n.instruments.Load().tombstonesReaped.Add(int64(len(keys)))
+	return len(keys), nil
+}
+
+// reapTombstonesLoop runs ReapTombstones at the policy's interval
+func (n *Node) reapTombstonesLoop() {
+	defer n.wg.Done()
+
+	for {
+		policy := n.Tombstones()
+		interval := policy.Interval
+		if interval <= 0 {
+			interval = DefaultTombstoneReapInterval
+		}
+
+		// Without a horizon, wait for a policy change only
+		var tick <-chan time.Time
+		var timer *time.Timer
+		if policy.Horizon > 0 {
+			timer = time.NewTimer(interval)
+			tick = timer.C
+		}
+		select {
+		case <-n.ctx.Done():
+		case <-n.tombstones.changed:
+		case <-tick:
+			if reaped, err := n.ReapTombstones(); err != nil {
+				log.Printf("Node %s: failed to reap tombstones: %v", n.id.Short(), err)
+			} else if reaped > 0 {
+				log.Printf("Node %s: reaped %d tombstones", n.id.Short(), reaped)
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if n.ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package chord
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
+	"chord-dht/pkg/hash"
+)
+
+func TestTombstonesOutliveStaleReplicas(t *testing.T) {
+	nodes := startTestRing(t, 8625, 2)
+	for _, node := range nodes {
+		node.SetReplication(2)
+		node.stabilize()
+	}
+
+	ctx := context.Background()
+	if err := nodes[0].StoreValue(ctx, "doc", []byte("v1")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	owner, replica := nodes[0], nodes[1]
+	if owner.checkOwned("doc", false) != nil {
+		owner, replica = replica, owner
+	}
+	stale, ok, _ := replica.Storage().Get("doc")
+	if !ok || !stale.Live(time.Now()) {
+		t.Fatal("Expected the replica to hold doc")
+	}
+
+	result, err := owner.CompareAndSwap(ctx, ConditionalWrite{Key: "doc", Expected: []byte("v1"), Delete: true})
+	if err != nil || !result.Applied {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	for _, node := range nodes {
+		if e, ok, _ := node.Storage().Get("doc"); !ok || !e.Tombstone() || e.Version != result.Version {
+			t.Errorf("Expected a tombstone of version %d on %s, got %+v", result.Version, node.GetAddress(), e)
+		}
+	}
+
+	// The old value sent back to the replica does not bring the key back
+	if err := replica.storeReplicas([]*pb.StoredEntry{toProtoEntry("doc", stale)}); err != nil {
+		t.Fatalf("storeReplicas failed: %v", err)
+	}
+	if e, _, _ := replica.Storage().Get("doc"); !e.Tombstone() {
+		t.Errorf("Expected the tombstone to win over the stale value, got %+v", e)
+	}
+	if _, err := owner.FetchValue(ctx, "doc"); err == nil {
+		t.Error("Expected the deleted key to stay deleted")
+	}
+
+	// Once past the horizon, the reaper removes the tombstones
+	sink := metrics.NewMemory()
+	for _, node := range nodes {
+		node.SetMetrics(sink)
+		node.SetTombstones(TombstonePolicy{Horizon: time.Millisecond})
+	}
+	time.Sleep(5 * time.Millisecond)
+	for _, node := range nodes {
+		if reaped, err := node.ReapTombstones(); err != nil || reaped != 1 {
+			t.Errorf("Expected 1 tombstone reaped on %s, got %d (%v)", node.GetAddress(), reaped, err)
+		}
+		if _, ok, _ := node.Storage().Get("doc"); ok {
+			t.Errorf("Expected no entry left on %s", node.GetAddress())
+		}
+	}
+	if reaped := sink.CounterValue(metrics.TombstonesReaped); reaped != 2 {
+		t.Errorf("Expected 2 tombstones reaped, got %d", reaped)
+	}
+}
+
+func TestReapableTombstoneLosesToAnyVersion(t *testing.T) {
+	node := NewNode("localhost:0", hash.NewHashFromString("tombstone-horizon"))
+	node.SetTombstones(TombstonePolicy{Horizon: time.Hour})
+	deleted := Entry{Version: 5, ExpiresAt: time.Now(), DeletedAt: time.Now()}
+
+	// Within the horizon the tombstone is kept over an older version
+	node.Storage().Put("key", deleted)
+	if err := node.storeReplicas([]*pb.StoredEntry{{Key: "key", Value: []byte("old"), Version: 4}}); err != nil {
+		t.Fatalf("storeReplicas failed: %v", err)
+	}
+	if e, _, _ := node.Storage().Get("key"); !e.Tombstone() {
+		t.Errorf("Expected the tombstone kept, got %+v", e)
+	}
+
+	// Past it, a key written again after its owner reaped the tombstone is
+	// taken even though its version started over
+	deleted.DeletedAt = time.Now().Add(-2 * time.Hour)
+	node.Storage().Put("key", deleted)
+	if err := node.storeReplicas([]*pb.StoredEntry{{Key: "key", Value: []byte("new"), Version: 1}}); err != nil {
+		t.Fatalf("storeReplicas failed: %v", err)
+	}
+	if e, _, _ := node.Storage().Get("key"); string(e.Value) != "new" {
+		t.Errorf("Expected the new value, got %+v", e)
+	}
+}
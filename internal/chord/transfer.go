@@ -0,0 +1,312 @@
+package chord
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultTransferChunkEntries and DefaultTransferChunkBytes bound the
+	// chunks of a streamed hand-off unless set with SetTransferPolicy
+	DefaultTransferChunkEntries = 500
+	DefaultTransferChunkBytes   = 256 << 10
+	// transferIdleTimeout is how long a receiving node waits for the next
+	// chunk of a hand-off before giving up on the stream
+	transferIdleTimeout = RPCTimeout
+)
+
+// TransferPolicy configures how this node streams key ranges to the nodes
+// that take them over. A range is sent in chunks, one at a time, as the
+// receiver's flow control window allows, so neither side holds the whole
+// range in memory; an interrupted stream resumes after the last chunk the
+// receiver installed.
+type TransferPolicy struct {
+	// ChunkEntries and ChunkBytes bound the entries of one chunk, with
+	// DefaultTransferChunkEntries and DefaultTransferChunkBytes if zero. A
+	// chunk holds at least one entry, however large.
+	ChunkEntries int
+	ChunkBytes   int
+	// Rate caps the bytes per second the node sends across all its
+	// transfers; zero leaves them unlimited. Chunks are kept small enough
+	// to go out at least every few seconds at this rate.
+	Rate float64
+}
+
+// transfers holds the node's transfer policy and paces the chunks it sends
+type transfers struct {
+	mu     sync.Mutex
+	policy TransferPolicy
+	// next is when the rate limit lets the next chunk go out
+	next time.Time
+}
+
+// chunkLimits returns the bounds of a chunk under the policy
+func (t *transfers) chunkLimits() (entries, bytes int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, bytes = t.policy.ChunkEntries, t.policy.ChunkBytes
+	if entries <= 0 {
+		entries = DefaultTransferChunkEntries
+	}
+	if bytes <= 0 {
+		bytes = DefaultTransferChunkBytes
+	}
+	if t.policy.Rate > 0 {
+		// The receiver gives up after transferIdleTimeout without a chunk
+		bytes = min(bytes, max(1, int(t.policy.Rate*transferIdleTimeout.Seconds()/2)))
+	}
+	return entries, bytes
+}
+
+// reserve books size bytes under the rate limit and returns how long to
+// wait before sending them
+func (t *transfers) reserve(size int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.policy.Rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(time.Duration(float64(size) / t.policy.Rate * float64(time.Second)))
+	return start.Sub(now)
+}
+
+// SetTransferPolicy sets how the node streams key ranges it hands off
+func (n *Node) SetTransferPolicy(policy TransferPolicy) {
+	n.transfers.mu.Lock()
+	defer n.transfers.mu.Unlock()
+
+	n.transfers.policy = policy
+}
+
+// TransferPolicy returns the policy set with SetTransferPolicy
+func (n *Node) TransferPolicy() TransferPolicy {
+	n.transfers.mu.Lock()
+	defer n.transfers.mu.Unlock()
+
+	return n.transfers.policy
+}
+
+// encodeResumeToken and decodeResumeToken convert the last key of a chunk
+// to the opaque token the receiver resumes with
+func encodeResumeToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeResumeToken(token string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid resume token: %w", err)
+	}
+	return string(key), nil
+}
+
+// streamedKeys returns the sorted keys of an open hand-off prepared for
+// streaming to requester, and extends its deadline
+func (n *Node) streamedKeys(id string, requester *NodeInfo) ([]string, error) {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	out := n.expireLocked()
+	if out == nil || out.id != id || out.to.Address != requester.Address {
+		return nil, errHandoffAborted
+	}
+	if !out.streamed {
+		return nil, fmt.Errorf("hand-off %s was not prepared for streaming", id)
+	}
+	out.deadline = time.Now().Add(handoffTimeout)
+	return out.streamKeys, nil
+}
+
+// extendHandoff keeps an open outgoing hand-off from timing out for
+// another wait plus handoffTimeout, reporting false if it is no longer open
+func (n *Node) extendHandoff(id string, wait time.Duration) bool {
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	out := n.expireLocked()
+	if out == nil || out.id != id {
+		return false
+	}
+	out.deadline = time.Now().Add(wait + handoffTimeout)
+	return true
+}
+
+// nextChunk reads the entries of keys from pos on, up to the chunk limits,
+// and returns the chunk and the position after it. Keys deleted since the
+// hand-off was prepared, such as by expiry, are skipped.
+func (n *Node) nextChunk(keys []string, pos, maxEntries, maxBytes int) (*pb.HandoffChunk, int, int, error) {
+	n.dataMu.RLock()
+	defer n.dataMu.RUnlock()
+
+	chunk := &pb.HandoffChunk{}
+	size := 0
+	for ; pos < len(keys) && len(chunk.Entries) < maxEntries && (size < maxBytes || len(chunk.Entries) == 0); pos++ {
+		e, ok, err := n.storage.Get(keys[pos])
+		if err != nil {
+			return nil, pos, 0, fmt.Errorf("failed to read %q: %w", keys[pos], err)
+		}
+		if !ok {
+			continue
+		}
+		entry := toProtoEntry(keys[pos], e)
+		chunk.Entries = append(chunk.Entries, entry)
+		size += proto.Size(entry)
+	}
+	if pos > 0 {
+		chunk.ResumeToken = encodeResumeToken(keys[pos-1])
+	}
+	return chunk, pos, size, nil
+}
+
+// StreamHandoff sends the entries of a hand-off prepared for streaming, in
+// key order from after the resume token
+func (n *Node) StreamHandoff(req *pb.StreamHandoffRequest, stream grpc.ServerStreamingServer[pb.HandoffChunk]) error {
+	n.countMessage()
+
+	requester, err := fromProtoNode(req.Requester)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid requester: %v", err)
+	}
+	keys, err := n.streamedKeys(req.TransferId, requester)
+	if errors.Is(err, errHandoffAborted) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	pos := 0
+	if req.ResumeToken != "" {
+		after, err := decodeResumeToken(req.ResumeToken)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		pos = sort.Search(len(keys), func(i int) bool { return keys[i] > after })
+	}
+
+	maxEntries, maxBytes := n.transfers.chunkLimits()
+	for pos < len(keys) {
+		var chunk *pb.HandoffChunk
+		var size int
+		if chunk, pos, size, err = n.nextChunk(keys, pos, maxEntries, maxBytes); err != nil {
+			return toStatus(err)
+		}
+
+		wait := n.transfers.reserve(size)
+		if !n.extendHandoff(req.TransferId, wait) {
+			return status.Error(codes.Aborted, errHandoffAborted.Error())
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stream.Context().Done():
+				timer.Stop()
+				return stream.Context().Err()
+			}
+		}
+		// Send blocks while the receiver's flow control window is full
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// incomingStream is the progress of a streamed hand-off being received,
+// kept so that a later attempt resumes it
+type incomingStream struct {
+	id       string
+	token    string
+	received int
+}
+
+// receiveHandoff fetches the entries of a hand-off prepared for streaming
+// from the node at from and installs them chunk by chunk. An interrupted
+// stream is reopened after the last chunk installed for as long as every
+// attempt makes progress; otherwise the progress is kept for the next call
+// with the same transfer. It returns the number of entries received.
+func (n *Node) receiveHandoff(client pb.ChordServiceClient, from *NodeInfo, resp *pb.PrepareHandoffResponse) (int, error) {
+	n.own.mu.Lock()
+	progress := n.own.receiving
+	if progress.id != resp.TransferId {
+		progress = incomingStream{id: resp.TransferId}
+	}
+	n.own.mu.Unlock()
+
+	for {
+		before := progress.received
+		err := n.receiveChunks(client, from, &progress)
+
+		n.own.mu.Lock()
+		n.own.receiving = progress
+		if err == nil {
+			n.own.receiving = incomingStream{}
+		}
+		n.own.mu.Unlock()
+
+		if err == nil || progress.received == before {
+			return progress.received, err
+		}
+		log.Printf("Node %s: hand-off stream from %s interrupted after %d of %d keys, resuming: %v",
+			n.id.Short(), from.Address, progress.received, resp.TotalEntries, err)
+	}
+}
+
+// receiveChunks reads one stream of a hand-off from the resume token of
+// progress on, installing every chunk and advancing progress past it
+func (n *Node) receiveChunks(client pb.ChordServiceClient, from *NodeInfo, progress *incomingStream) error {
+	ctx, cancel := context.WithCancel(n.ctx)
+	defer cancel()
+	idle := time.AfterFunc(transferIdleTimeout, cancel)
+	defer idle.Stop()
+
+	stream, err := client.StreamHandoff(ctx, &pb.StreamHandoffRequest{
+		TransferId:  progress.id,
+		Requester:   toProtoNode(n.GetNodeInfo()),
+		ResumeToken: progress.token,
+	})
+	if err != nil {
+		return fromStatus(from.Address, err)
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if status.Code(err) == codes.Aborted {
+				return fmt.Errorf("hand-off from %s: %w", from.Address, errHandoffAborted)
+			}
+			return fromStatus(from.Address, err)
+		}
+		idle.Reset(transferIdleTimeout)
+
+		if err := n.installEntries(chunk.Entries); err != nil {
+			return err
+		}
+		progress.token = chunk.ResumeToken
+		progress.received += len(chunk.Entries)
+	}
+}
@@ -0,0 +1,105 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+func TestStreamedHandoff(t *testing.T) {
+	bootstrap := NewNode("localhost:8620", hash.NewHashFromString("stream-bootstrap"))
+	bootstrap.SetTransferPolicy(TransferPolicy{ChunkEntries: 7, Rate: 1 << 20})
+	if err := bootstrap.Start(); err != nil {
+		t.Fatalf("Failed to start bootstrap: %v", err)
+	}
+	defer bootstrap.Stop()
+	if err := bootstrap.Join(""); err != nil {
+		t.Fatalf("Failed to create ring: %v", err)
+	}
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 100; i++ {
+		items[fmt.Sprintf("stream-%d", i)] = []byte(fmt.Sprintf("value-%d", i))
+	}
+	if err := bootstrap.StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	node := NewNode("localhost:8621", hash.NewHashFromString("stream-joiner"))
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	defer node.Stop()
+
+	// A stream resumed with a token starts after the key it names
+	out, entries, err := bootstrap.prepareHandoff(node.GetNodeInfo(), true)
+	if err != nil || len(entries) != 0 || len(out.streamKeys) < 3 {
+		t.Fatalf("Expected a streamed hand-off of several keys, got %d keys, %d entries, %v", len(out.streamKeys), len(entries), err)
+	}
+	client, err := node.getClient(bootstrap.GetAddress())
+	if err != nil {
+		t.Fatalf("getClient failed: %v", err)
+	}
+	stream, err := client.StreamHandoff(ctx, &pb.StreamHandoffRequest{
+		TransferId:  out.id,
+		Requester:   toProtoNode(node.GetNodeInfo()),
+		ResumeToken: encodeResumeToken(out.streamKeys[1]),
+	})
+	if err != nil {
+		t.Fatalf("StreamHandoff failed: %v", err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil || len(chunk.Entries) == 0 || chunk.Entries[0].Key != out.streamKeys[2] {
+		t.Fatalf("Expected the stream to resume at %q, got %v, %v", out.streamKeys[2], chunk, err)
+	}
+
+	// The joiner streams the whole range, in chunks of 7 keys
+	if err := node.Join(bootstrap.GetAddress()); err != nil {
+		t.Fatalf("Failed to join ring: %v", err)
+	}
+	if start, owned := node.OwnedRange(); !owned || !start.Equal(bootstrap.GetID()) {
+		t.Fatalf("Expected joiner to own (bootstrap, joiner], got start %v owned %v", start, owned)
+	}
+	moved := 0
+	for key := range items {
+		if hash.NewHashFromString(key).InRange(bootstrap.GetID(), node.GetID()) {
+			moved++
+			if onNode, _ := node.loadLocal([]string{key}); len(onNode) != 1 {
+				t.Errorf("Key %s should have moved to the joiner", key)
+			}
+		}
+	}
+	if moved != len(out.streamKeys) {
+		t.Errorf("Expected %d keys to move, %d did", len(out.streamKeys), moved)
+	}
+	if calls := bootstrap.Stats().RPCs["StreamHandoff"]; calls < 2 {
+		t.Errorf("Expected the joiner to stream the range, got %d StreamHandoff calls", calls)
+	}
+}
+
+func TestTransferPacing(t *testing.T) {
+	var tr transfers
+	tr.policy = TransferPolicy{Rate: 1000}
+	if entries, bytes := tr.chunkLimits(); entries != DefaultTransferChunkEntries || bytes != 5000 {
+		t.Errorf("Expected chunks of %d entries and 5000 bytes at 1000 B/s, got %d and %d", DefaultTransferChunkEntries, entries, bytes)
+	}
+	if wait := tr.reserve(500); wait != 0 {
+		t.Errorf("Expected the first chunk to go out at once, got %v", wait)
+	}
+	if wait := tr.reserve(500); wait < 450*time.Millisecond || wait > 500*time.Millisecond {
+		t.Errorf("Expected the second chunk to wait about 500ms, got %v", wait)
+	}
+
+	tr.policy = TransferPolicy{}
+	if entries, bytes := tr.chunkLimits(); entries != DefaultTransferChunkEntries || bytes != DefaultTransferChunkBytes {
+		t.Errorf("Expected the default chunk limits, got %d and %d", entries, bytes)
+	}
+	if wait := tr.reserve(1 << 30); wait != 0 {
+		t.Errorf("Expected no wait without a rate, got %v", wait)
+	}
+}
@@ -0,0 +1,191 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Transport is how a node carries the Ping, Notify and GetInfo RPCs of ring
+// maintenance. These are tiny and sent to the same few neighbors every few
+// seconds, so connection and framing overhead dominates them. Lookups,
+// transfers and client requests always use gRPC.
+type Transport string
+
+const (
+	// TransportGRPC sends maintenance RPCs over the node's gRPC connections
+	TransportGRPC Transport = "grpc"
+	// TransportUDP sends every maintenance RPC as one datagram and its reply
+	// as another (see udp.go). Calls to peers that do not answer over UDP,
+	// such as nodes using TransportGRPC, fall back to gRPC.
+	TransportUDP Transport = "udp"
+)
+
+// ParseTransport returns the transport with the given name: grpc or udp
+func ParseTransport(name string) (Transport, error) {
+	switch Transport(name) {
+	case "", TransportGRPC:
+		return TransportGRPC, nil
+	case TransportUDP:
+		return TransportUDP, nil
+	default:
+		return "", fmt.Errorf("unknown transport %q", name)
+	}
+}
+
+// TransportStats counts the traffic of the RPCs a node made to its peers
+type TransportStats struct {
+	Maintenance Transport
+	// GRPCBytes counts the bytes sent and received over the node's outgoing
+	// gRPC connections, HTTP/2 framing included
+	GRPCBytes int64
+	// UDPBytes counts the bytes of the maintenance datagrams the node sent
+	// and of the replies it received
+	UDPBytes int64
+	// MaintenanceCalls counts maintenance RPCs, UDPCalls those answered over
+	// UDP and Fallbacks those retried over gRPC after a peer did not answer
+	// over UDP
+	MaintenanceCalls int64
+	UDPCalls         int64
+	Fallbacks        int64
+}
+
+// transport is the maintenance transport of a node and its counters
+type transport struct {
+	kind Transport
+	// udp is set while a node using TransportUDP runs
+	udp       atomic.Pointer[udpEndpoint]
+	grpcBytes atomic.Int64
+	calls     atomic.Int64
+	udpCalls  atomic.Int64
+	fallbacks atomic.Int64
+}
+
+// SetMaintenanceTransport sets how the node carries its maintenance RPCs.
+// It must be called before Start. Nodes using different transports
+// interoperate: every node serves maintenance RPCs over gRPC.
+func (n *Node) SetMaintenanceTransport(t Transport) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.transport.kind = t
+}
+
+// TransportStats returns the traffic counters of the node's outgoing RPCs
+func (n *Node) TransportStats() TransportStats {
+	stats := TransportStats{
+		Maintenance:      n.maintenanceTransport(),
+		GRPCBytes:        n.transport.grpcBytes.Load(),
+		MaintenanceCalls: n.transport.calls.Load(),
+		UDPCalls:         n.transport.udpCalls.Load(),
+		Fallbacks:        n.transport.fallbacks.Load(),
+	}
+	if udp := n.transport.udp.Load(); udp != nil {
+		stats.UDPBytes = udp.bytes.Load()
+	}
+	return stats
+}
+
+// maintenanceTransport returns the transport set with SetMaintenanceTransport
+func (n *Node) maintenanceTransport() Transport {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.transport.kind == "" {
+		return TransportGRPC
+	}
+	return n.transport.kind
+}
+
+// invokeMaintenance calls a maintenance RPC on the node at address over the
+// node's maintenance transport
+func (n *Node) invokeMaintenance(ctx context.Context, address, method string, req, reply proto.Message) error {
+	n.transport.calls.Add(1)
+
+	udp := n.transport.udp.Load()
+	_, _, relayed := parseRelayAddress(address)
+	fellBack := false
+	if udp != nil && !relayed && !udp.avoided(address) {
+		err := udp.invoke(ctx, address, method, req, reply)
+		if !errors.Is(err, errNoUDP) {
+			if err == nil {
+				n.transport.udpCalls.Add(1)
+			}
+			return err
+		}
+		fellBack = true
+	}
+
+	conn, err := n.ClientConn(address)
+	if err != nil {
+		return err
+	}
+	if err := conn.Invoke(ctx, method, req, reply); err != nil {
+		return err
+	}
+	if fellBack {
+		// The peer answers over gRPC but not over UDP
+		n.transport.fallbacks.Add(1)
+		udp.avoid(address)
+	}
+	return nil
+}
+
+// dialCounted dials a peer for gRPC over the node's network, counting the
+// bytes of the connection
+func (n *Node) dialCounted(ctx context.Context, address string) (net.Conn, error) {
+	n.mu.RLock()
+	network, quicTransport := n.network, n.quicTransport
+	n.mu.RUnlock()
+
+	var conn net.Conn
+	var err error
+	if path, ok := unixPath(address); ok {
+		// Peers on Unix domain sockets are dialed over them whatever the
+		// node's network
+		conn, err = (&net.Dialer{}).DialContext(ctx, "unix", path)
+	} else if network == NetworkQUIC {
+		var addresses []string
+		if addresses, err = n.resolveAddress(ctx, address); err == nil {
+			conn, err = dialQUIC(ctx, quicTransport, addresses[0])
+		}
+	} else {
+		conn, err = n.dialTCP(ctx, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &meteredConn{Conn: conn, bytes: &n.transport.grpcBytes, sent: &n.stats.sent, received: &n.stats.received}, nil
+}
+
+// meteredConn adds the bytes read and written to the node's stats, and
+// their sum to a counter if set
+type meteredConn struct {
+	net.Conn
+	bytes          *atomic.Int64
+	sent, received *atomic.Int64
+}
+
+// Read reads from the connection
+func (c *meteredConn) Read(b []byte) (int, error) {
+	count, err := c.Conn.Read(b)
+	c.received.Add(int64(count))
+	if c.bytes != nil {
+		c.bytes.Add(int64(count))
+	}
+	return count, err
+}
+
+// Write writes to the connection
+func (c *meteredConn) Write(b []byte) (int, error) {
+	count, err := c.Conn.Write(b)
+	c.sent.Add(int64(count))
+	if c.bytes != nil {
+		c.bytes.Add(int64(count))
+	}
+	return count, err
+}
@@ -0,0 +1,166 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// startTransportRing starts a ring whose nodes use the given maintenance
+// transports, in order
+func startTransportRing(t *testing.T, basePort int, transports []Transport, middleware Middleware) []*Node {
+	nodes := make([]*Node, len(transports))
+	for i, transport := range transports {
+		addr := fmt.Sprintf("localhost:%d", basePort+i)
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		nodes[i].SetMaintenanceTransport(transport)
+		nodes[i].Use(middleware)
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d: %v", i, err)
+		}
+	}
+
+	for round := 0; round < len(nodes)+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	return nodes
+}
+
+// checkRing fails the test unless every node's successor and predecessor
+// are its neighbors on the ring
+func checkRing(t *testing.T, nodes []*Node) {
+	t.Helper()
+
+	for _, node := range nodes {
+		successor := node.GetSuccessor()
+		predecessor := node.GetPredecessor()
+		if successor == nil || predecessor == nil {
+			t.Fatalf("Node %s has successor %v and predecessor %v", node.GetAddress(), successor, predecessor)
+		}
+		for _, other := range nodes {
+			if other.GetID().InRangeExclusive(node.GetID(), successor.ID) {
+				t.Errorf("Node %s skips %s on its way to successor %s",
+					node.GetAddress(), other.GetAddress(), successor.Address)
+			}
+		}
+		if !predecessor.ID.Equal(nodeBefore(nodes, node).GetID()) {
+			t.Errorf("Node %s has predecessor %s", node.GetAddress(), predecessor.Address)
+		}
+	}
+}
+
+// nodeBefore returns the node preceding node on the ring
+func nodeBefore(nodes []*Node, node *Node) *Node {
+	for _, other := range nodes {
+		if other.GetSuccessor() != nil && other.GetSuccessor().ID.Equal(node.GetID()) {
+			return other
+		}
+	}
+	return nil
+}
+
+// tagMiddleware sends a metadata tag with every outgoing RPC and records the
+// tag of every incoming one, by method
+type tagMiddleware struct {
+	mu   sync.Mutex
+	seen map[string]string
+}
+
+func (m *tagMiddleware) middleware() Middleware {
+	return Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if tags := md.Get("x-test-tag"); len(tags) > 0 {
+				m.mu.Lock()
+				m.seen[info.FullMethod] = tags[0]
+				m.mu.Unlock()
+			}
+			return handler(ctx, req)
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, "x-test-tag", "tagged"), method, req, reply, cc, opts...)
+		},
+	}
+}
+
+func TestUDPTransport(t *testing.T) {
+	tags := &tagMiddleware{seen: make(map[string]string)}
+	nodes := startTransportRing(t, 8500, []Transport{TransportUDP, TransportUDP, TransportUDP}, tags.middleware())
+	checkRing(t, nodes)
+
+	for _, node := range nodes {
+		stats := node.TransportStats()
+		if stats.Maintenance != TransportUDP {
+			t.Errorf("Node %s uses %s", node.GetAddress(), stats.Maintenance)
+		}
+		if stats.UDPCalls == 0 || stats.UDPBytes == 0 {
+			t.Errorf("Node %s made no maintenance calls over UDP: %+v", node.GetAddress(), stats)
+		}
+		if stats.Fallbacks != 0 {
+			t.Errorf("Node %s fell back to gRPC %d times", node.GetAddress(), stats.Fallbacks)
+		}
+	}
+
+	// Middleware runs on both ends of calls over UDP, with the metadata
+	// carried in the datagrams
+	tags.mu.Lock()
+	defer tags.mu.Unlock()
+	for _, method := range []string{"/chord.v1.ChordService/GetInfo", "/chord.v1.ChordService/Notify"} {
+		if tags.seen[method] != "tagged" {
+			t.Errorf("Expected %s to arrive tagged, got %q", method, tags.seen[method])
+		}
+	}
+}
+
+func TestUDPTransportFallsBackToGRPC(t *testing.T) {
+	tags := &tagMiddleware{seen: make(map[string]string)}
+	nodes := startTransportRing(t, 8503, []Transport{TransportUDP, TransportGRPC, TransportUDP}, tags.middleware())
+	checkRing(t, nodes)
+
+	if err := nodes[0].remotePing(context.Background(), nodes[1].GetAddress()); err != nil {
+		t.Fatalf("Ping of the gRPC node failed: %v", err)
+	}
+	if err := nodes[0].remotePing(context.Background(), nodes[2].GetAddress()); err != nil {
+		t.Fatalf("Ping of the UDP node failed: %v", err)
+	}
+
+	stats := nodes[0].TransportStats()
+	if stats.Fallbacks != 1 {
+		t.Errorf("Expected one fallback, on the first call to the gRPC node, got %d", stats.Fallbacks)
+	}
+	if stats.UDPCalls == 0 {
+		t.Error("Expected calls to the other UDP node to use UDP")
+	}
+	if grpcStats := nodes[1].TransportStats(); grpcStats.UDPCalls != 0 || grpcStats.GRPCBytes == 0 {
+		t.Errorf("Expected the gRPC node to use gRPC only: %+v", grpcStats)
+	}
+}
+
+func TestParseTransport(t *testing.T) {
+	for name, want := range map[string]Transport{"": TransportGRPC, "grpc": TransportGRPC, "udp": TransportUDP} {
+		got, err := ParseTransport(name)
+		if err != nil || got != want {
+			t.Errorf("ParseTransport(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseTransport("carrier-pigeon"); err == nil {
+		t.Error("Expected an unknown transport to be rejected")
+	}
+}
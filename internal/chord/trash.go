@@ -0,0 +1,248 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TrashPolicy keeps deleted values recoverable with RestoreValue for a while
+// instead of dropping them on delete. Deleted values stay on the key's owner
+// only: they are neither replicated nor handed off with the key.
+type TrashPolicy struct {
+	// Retention is how long a deleted value can be restored, zero
+	// disabling the trash
+	Retention time.Duration
+	// Prefixes overrides Retention for the keys starting with a prefix,
+	// such as the keys of one bucket. The longest matching prefix wins; a
+	// zero retention disables the trash for its keys.
+	Prefixes map[string]time.Duration
+}
+
+// retention returns how long a deleted value of key is kept
+func (p TrashPolicy) retention(key string) time.Duration {
+	retention, matched := p.Retention, -1
+	for prefix, prefixRetention := range p.Prefixes {
+		if strings.HasPrefix(key, prefix) && len(prefix) > matched {
+			retention, matched = prefixRetention, len(prefix)
+		}
+	}
+	return retention
+}
+
+// TrashedKey is a deleted key that can still be restored
+type TrashedKey struct {
+	Key       string
+	Size      int
+	DeletedAt time.Time
+	// PurgeAt is when the value is dropped for good
+	PurgeAt time.Time
+}
+
+// trashedEntry is a deleted entry held for RestoreValue
+type trashedEntry struct {
+	entry     Entry
+	deletedAt time.Time
+	purgeAt   time.Time
+}
+
+// trash holds the values deleted on this node that can still be restored
+type trash struct {
+	mu      sync.Mutex
+	policy  TrashPolicy
+	entries map[string]trashedEntry
+}
+
+// SetTrash sets how long the values deleted on this node can be restored
+func (n *Node) SetTrash(policy TrashPolicy) {
+	n.trash.mu.Lock()
+	defer n.trash.mu.Unlock()
+
+	n.trash.policy = policy
+}
+
+// Trash lists the keys deleted on this node that can still be restored,
+// oldest deletion first
+func (n *Node) Trash() []TrashedKey {
+	n.trash.mu.Lock()
+	defer n.trash.mu.Unlock()
+
+	n.trash.purgeLocked(time.Now())
+	keys := make([]TrashedKey, 0, len(n.trash.entries))
+	for key, trashed := range n.trash.entries {
+		keys = append(keys, TrashedKey{
+			Key:       key,
+			Size:      len(trashed.entry.Value),
+			DeletedAt: trashed.deletedAt,
+			PurgeAt:   trashed.purgeAt,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].DeletedAt.Equal(keys[j].DeletedAt) {
+			return keys[i].DeletedAt.Before(keys[j].DeletedAt)
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+// purgeLocked drops the values whose retention ended before now. The caller
+// must hold mu.
+func (t *trash) purgeLocked(now time.Time) {
+	for key, trashed := range t.entries {
+		if !now.Before(trashed.purgeAt) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// keep holds the live entry of a key being deleted, if the policy keeps it
+func (t *trash) keep(key string, e Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	retention := t.policy.retention(key)
+	if retention <= 0 {
+		return
+	}
+	if t.entries == nil {
+		t.entries = make(map[string]trashedEntry)
+	}
+	now := time.Now()
+	t.purgeLocked(now)
+	t.entries[key] = trashedEntry{entry: e, deletedAt: now, purgeAt: now.Add(retention)}
+}
+
+// drop forgets the deleted value of a key written again
+func (t *trash) drop(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, key)
+}
+
+// take removes and returns the deleted entry of key, if still kept
+func (t *trash) take(key string) (Entry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.purgeLocked(time.Now())
+	trashed, ok := t.entries[key]
+	if ok {
+		delete(t.entries, key)
+	}
+	return trashed.entry, ok
+}
+
+// RestoreValue restores the last deleted value of a key on the node
+// responsible for it, returning the key's new version. It returns
+// ErrKeyNotFound if the key holds no restorable value: it was never deleted,
+// its retention or its TTL ended, or it was written again since.
+func (n *Node) RestoreValue(ctx context.Context, key string) (uint64, error) {
+	owner, err := n.findSuccessor(ctx, n.KeyID(key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find owner of key %q: %w", key, err)
+	}
+
+	version, err := n.undeleteAt(ctx, owner.Address, key)
+	if hint := ownerHint(err); hint != nil && hint.Address != owner.Address {
+		// Follow the responsible node hint once
+		version, err = n.undeleteAt(ctx, hint.Address, key)
+	}
+	return version, err
+}
+
+// undeleteAt restores a key on the node at address, short-circuiting locally
+func (n *Node) undeleteAt(ctx context.Context, address, key string) (uint64, error) {
+	if address == n.address {
+		version, err := n.restoreTrashed(key)
+		if err != nil {
+			return 0, err
+		}
+		n.replicateKeys(ctx, []string{key})
+		n.invalidateKeys(ctx, []string{key})
+		return version, nil
+	}
+
+	client, err := n.getClient(address)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RPCTimeout)
+	defer cancel()
+
+	resp, err := client.Undelete(ctx, &pb.UndeleteRequest{Key: key})
+	if err != nil {
+		return 0, fromStatus(address, err)
+	}
+	if !resp.Success {
+		return 0, fmt.Errorf("undelete at %s failed: %s", address, resp.Error)
+	}
+	return resp.Version, nil
+}
+
+// restoreTrashed writes the deleted value of a key owned by this node back
+func (n *Node) restoreTrashed(key string) (uint64, error) {
+	if n.Isolated() {
+		return 0, fmt.Errorf("%w: undelete of %q", ErrIsolated, key)
+	}
+
+	n.dataMu.Lock()
+	defer n.dataMu.Unlock()
+
+	if err := n.checkOwned(key, true); err != nil {
+		return 0, err
+	}
+	trashed, ok := n.trash.take(key)
+	if !ok || !trashed.Live(time.Now()) {
+		return 0, fmt.Errorf("%w: no deleted value of %s", ErrKeyNotFound, key)
+	}
+
+	e, _, err := n.storage.Get(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %q: %w", key, err)
+	}
+	e.Value = trashed.Value
+	e.Version++
+	e.ExpiresAt = trashed.ExpiresAt
+	e.DeletedAt = time.Time{}
+	if err := n.storage.Put(key, e); err != nil {
+		return 0, fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	return e.Version, nil
+}
+
+// Undelete restores the last deleted value of a key
+func (n *Node) Undelete(ctx context.Context, req *pb.UndeleteRequest) (*pb.UndeleteResponse, error) {
+	n.countMessage()
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+
+	var (
+		version uint64
+		err     error
+	)
+	if req.Route {
+		version, err = n.RestoreValue(ctx, req.Key)
+	} else {
+		if err := n.checkResponsible([]string{req.Key}, true); err != nil {
+			return nil, toStatus(err)
+		}
+		version, err = n.undeleteAt(ctx, n.address, req.Key)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UndeleteResponse{Version: version, Success: true}, nil
+}
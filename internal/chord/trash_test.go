@@ -0,0 +1,69 @@
+package chord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrashRestore(t *testing.T) {
+	nodes := startTestRing(t, 8475, 2)
+	for _, node := range nodes {
+		node.SetTrash(TrashPolicy{
+			Retention: time.Hour,
+			Prefixes:  map[string]time.Duration{"tmp/": 0},
+		})
+	}
+
+	ctx := context.Background()
+	remove := func(key string) {
+		t.Helper()
+		value, err := nodes[0].FetchValue(ctx, key)
+		if err != nil {
+			t.Fatalf("FetchValue(%q) failed: %v", key, err)
+		}
+		result, err := nodes[0].CompareAndSwap(ctx, ConditionalWrite{Key: key, Expected: value, Delete: true})
+		if err != nil || !result.Applied {
+			t.Fatalf("Delete of %q failed: %v", key, err)
+		}
+	}
+
+	if err := nodes[0].StoreBatch(ctx, map[string][]byte{"doc": []byte("v1"), "tmp/scratch": []byte("v1")}); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+	remove("doc")
+	remove("tmp/scratch")
+
+	var trashed []string
+	for _, node := range nodes {
+		for _, key := range node.Trash() {
+			trashed = append(trashed, key.Key)
+		}
+	}
+	if len(trashed) != 1 || trashed[0] != "doc" {
+		t.Errorf("Expected only doc in the trash, got %v", trashed)
+	}
+
+	if _, err := nodes[1].RestoreValue(ctx, "doc"); err != nil {
+		t.Fatalf("RestoreValue failed: %v", err)
+	}
+	if value, err := nodes[0].FetchValue(ctx, "doc"); err != nil || string(value) != "v1" {
+		t.Errorf("Expected restored value v1, got %q (%v)", value, err)
+	}
+	if _, err := nodes[1].RestoreValue(ctx, "doc"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound restoring a live key, got %v", err)
+	}
+	if _, err := nodes[1].RestoreValue(ctx, "tmp/scratch"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound for a prefix without retention, got %v", err)
+	}
+
+	// Writing a deleted key again drops its deleted value
+	remove("doc")
+	if err := nodes[0].StoreValue(ctx, "doc", []byte("v2")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	if _, err := nodes[1].RestoreValue(ctx, "doc"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound after a new write, got %v", err)
+	}
+}
@@ -0,0 +1,403 @@
+package chord
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/metrics"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Maintenance RPCs over UDP use one datagram per request and one per reply,
+// on the UDP port numbered like the node's gRPC port. A request is
+//
+//	'Q' | id (8 bytes) | method | metadata | protobuf request
+//
+// and its reply
+//
+//	'R' | id (8 bytes) | code (4 bytes) | protobuf reply, or status if code != 0
+//
+// where integers are big-endian, strings are uvarint-length prefixed and
+// metadata is a uvarint count of key and value strings. Metadata carries what
+// the middleware would send as gRPC headers, so protocol versions, auth
+// tokens and signatures apply as they do over gRPC.
+const (
+	udpRequest = 'Q'
+	udpReply   = 'R'
+	// udpMaxDatagram is the largest UDP payload over IPv4
+	udpMaxDatagram = 65507
+	// udpRetransmit is how long a request waits for its reply before it is
+	// sent again; maintenance RPCs are idempotent
+	udpRetransmit = 250 * time.Millisecond
+	// udpAttempts is how many times a request is sent before the call falls
+	// back to gRPC
+	udpAttempts = 3
+	// udpAvoidFor is how long a peer that answered over gRPC but not UDP is
+	// only called over gRPC
+	udpAvoidFor = time.Minute
+)
+
+// errNoUDP reports a peer that did not answer a maintenance RPC over UDP
+var errNoUDP = errors.New("no reply over UDP")
+
+// udpMethod is a maintenance RPC served over UDP
+type udpMethod struct {
+	newRequest func() proto.Message
+	handle     func(n *Node, ctx context.Context, req proto.Message) (proto.Message, error)
+}
+
+// udpMethods are the RPCs served over UDP, by full method name
+var udpMethods = map[string]udpMethod{
+	pb.ChordService_Ping_FullMethodName: {
+		newRequest: func() proto.Message { return &pb.PingRequest{} },
+		handle: func(n *Node, ctx context.Context, req proto.Message) (proto.Message, error) {
+			return n.Ping(ctx, req.(*pb.PingRequest))
+		},
+	},
+	pb.ChordService_Notify_FullMethodName: {
+		newRequest: func() proto.Message { return &pb.NotifyRequest{} },
+		handle: func(n *Node, ctx context.Context, req proto.Message) (proto.Message, error) {
+			return n.Notify(ctx, req.(*pb.NotifyRequest))
+		},
+	},
+	pb.ChordService_GetInfo_FullMethodName: {
+		newRequest: func() proto.Message { return &pb.GetInfoRequest{} },
+		handle: func(n *Node, ctx context.Context, req proto.Message) (proto.Message, error) {
+			resp, err := n.GetInfo(ctx, req.(*pb.GetInfoRequest))
+			if resp != nil {
+				// Stabilization never reads the fingers, and without them
+				// the reply fits an unfragmented datagram
+				resp.Fingers = nil
+			}
+			return resp, err
+		},
+	},
+}
+
+// udpEndpoint sends and serves maintenance RPCs over one UDP socket
+type udpEndpoint struct {
+	node *Node
+	conn *net.UDPConn
+	// server wraps incoming requests, as the gRPC server's chain does
+	server []grpc.UnaryServerInterceptor
+
+	nextID atomic.Uint64
+	bytes  atomic.Int64
+
+	mu       sync.Mutex
+	pending  map[uint64]chan []byte
+	avoiding map[string]time.Time
+}
+
+// listenUDP opens the node's UDP socket on the address and port of its gRPC
+// listener and serves maintenance RPCs on it. The caller must hold mu.
+func (n *Node) listenUDP(bindAddr string) error {
+	addr, err := net.ResolveUDPAddr("udp", bindAddr)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", bindAddr, err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", bindAddr, err)
+	}
+
+	unary, _ := n.serverInterceptors()
+	e := &udpEndpoint{
+		node:     n,
+		conn:     conn,
+		server:   unary,
+		pending:  make(map[uint64]chan []byte),
+		avoiding: make(map[string]time.Time),
+	}
+	e.nextID.Store(uint64(time.Now().UnixNano()))
+	n.transport.udp.Store(e)
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		e.serve()
+	}()
+	return nil
+}
+
+// close closes the socket, ending serve
+func (e *udpEndpoint) close() {
+	e.conn.Close()
+}
+
+// serve reads datagrams until the socket is closed, serving requests and
+// handing replies to the calls waiting for them
+func (e *udpEndpoint) serve() {
+	buf := make([]byte, udpMaxDatagram)
+	for {
+		size, from, err := e.conn.ReadFromUDP(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Node %s: UDP transport stopped: %v", e.node.id.Short(), err)
+			}
+			return
+		}
+		if size < 9 {
+			continue
+		}
+		datagram := append([]byte(nil), buf[:size]...)
+		id := binary.BigEndian.Uint64(datagram[1:9])
+
+		switch datagram[0] {
+		case udpRequest:
+			// Every method served over UDP is a maintenance RPC
+			e.node.countBandwidth(metrics.CategoryMaintenance, 0, size)
+			go e.serveRequest(from, id, datagram[9:])
+		case udpReply:
+			e.mu.Lock()
+			reply, ok := e.pending[id]
+			delete(e.pending, id)
+			e.mu.Unlock()
+			if ok {
+				e.bytes.Add(int64(size))
+				e.node.countBandwidth(metrics.CategoryMaintenance, 0, size)
+				reply <- datagram[9:]
+			}
+		}
+	}
+}
+
+// serveRequest runs a request through the server interceptors and its
+// handler, and sends the reply to from
+func (e *udpEndpoint) serveRequest(from *net.UDPAddr, id uint64, body []byte) {
+	reply, err := e.handle(from, body)
+
+	datagram := binary.BigEndian.AppendUint64([]byte{udpReply}, id)
+	var payload []byte
+	if err == nil {
+		payload, err = proto.Marshal(reply)
+	}
+	if err == nil && len(datagram)+4+len(payload) > udpMaxDatagram {
+		err = status.Error(codes.ResourceExhausted, "reply does not fit a datagram")
+	}
+	code := codes.OK
+	if err != nil {
+		st := status.Convert(err)
+		code = st.Code()
+		payload, _ = proto.Marshal(st.Proto())
+	}
+	datagram = binary.BigEndian.AppendUint32(datagram, uint32(code))
+	datagram = append(datagram, payload...)
+	if _, err := e.conn.WriteToUDP(datagram, from); err == nil {
+		e.node.countBandwidth(metrics.CategoryMaintenance, len(datagram), 0)
+	}
+}
+
+// handle decodes and serves a request
+func (e *udpEndpoint) handle(from *net.UDPAddr, body []byte) (proto.Message, error) {
+	method, md, payload, err := decodeUDPRequest(body)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	m, ok := udpMethods[method]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "method %s is not served over UDP", method)
+	}
+	req := m.newRequest()
+	if err := proto.Unmarshal(payload, req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(e.node.ctx, RPCTimeout)
+	defer cancel()
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: from})
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	info := &grpc.UnaryServerInfo{Server: e.node, FullMethod: method}
+	handler := chainUnaryServer(e.server, info, func(ctx context.Context, req any) (any, error) {
+		return m.handle(e.node, ctx, req.(proto.Message))
+	})
+	reply, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(proto.Message), nil
+}
+
+// invoke calls method on the node at address through the client
+// interceptors, returning errNoUDP if it never answers
+func (e *udpEndpoint) invoke(ctx context.Context, address, method string, req, reply proto.Message) error {
+	// Middleware such as fault injection looks at the connection's target;
+	// the connection is only dialed if gRPC is used
+	cc, err := e.node.ClientConn(address)
+	if err != nil {
+		return err
+	}
+	unary, _ := e.node.clientInterceptors()
+	invoker := chainUnaryClient(unary, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return e.roundTrip(ctx, address, method, req.(proto.Message), reply.(proto.Message))
+	})
+	return invoker(ctx, method, req, reply, cc)
+}
+
+// roundTrip sends a request until its reply arrives
+func (e *udpEndpoint) roundTrip(ctx context.Context, address, method string, req, reply proto.Message) error {
+	to, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return &PeerError{Address: address, Err: err}
+	}
+	id := e.nextID.Add(1)
+	datagram, err := encodeUDPRequest(id, method, ctx, req)
+	if err != nil {
+		return err
+	}
+
+	replies := make(chan []byte, 1)
+	e.mu.Lock()
+	e.pending[id] = replies
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.pending, id)
+		e.mu.Unlock()
+	}()
+
+	e.node.countCall(metrics.CategoryMaintenance)
+	timer := time.NewTimer(udpRetransmit)
+	defer timer.Stop()
+	for attempt := 0; attempt < udpAttempts; attempt++ {
+		if _, err := e.conn.WriteToUDP(datagram, to); err != nil {
+			return &PeerError{Address: address, Err: err}
+		}
+		e.bytes.Add(int64(len(datagram)))
+		e.node.countBandwidth(metrics.CategoryMaintenance, len(datagram), 0)
+		timer.Reset(udpRetransmit)
+
+		select {
+		case body := <-replies:
+			return decodeUDPReply(body, reply)
+		case <-timer.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return errNoUDP
+}
+
+// avoided reports whether address is only called over gRPC for now
+func (e *udpEndpoint) avoided(address string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	until, ok := e.avoiding[address]
+	if ok && time.Now().After(until) {
+		delete(e.avoiding, address)
+		return false
+	}
+	return ok
+}
+
+// avoid calls address only over gRPC for udpAvoidFor
+func (e *udpEndpoint) avoid(address string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.avoiding[address] = time.Now().Add(udpAvoidFor)
+}
+
+// encodeUDPRequest encodes a request datagram, with the outgoing metadata
+// of ctx
+func encodeUDPRequest(id uint64, method string, ctx context.Context, req proto.Message) ([]byte, error) {
+	datagram := binary.BigEndian.AppendUint64([]byte{udpRequest}, id)
+	datagram = appendString(datagram, method)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var pairs []string
+	for key, values := range md {
+		for _, value := range values {
+			pairs = append(pairs, key, value)
+		}
+	}
+	datagram = binary.AppendUvarint(datagram, uint64(len(pairs)))
+	for _, s := range pairs {
+		datagram = appendString(datagram, s)
+	}
+
+	datagram, err := proto.MarshalOptions{}.MarshalAppend(datagram, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(datagram) > udpMaxDatagram {
+		return nil, status.Error(codes.ResourceExhausted, "request does not fit a datagram")
+	}
+	return datagram, nil
+}
+
+// decodeUDPRequest splits the body of a request datagram, after its ID
+func decodeUDPRequest(body []byte) (method string, md metadata.MD, payload []byte, err error) {
+	method, body, err = readString(body)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	count, size := binary.Uvarint(body)
+	if size <= 0 || count%2 != 0 || count > uint64(len(body)) {
+		return "", nil, nil, errors.New("malformed metadata")
+	}
+	body = body[size:]
+
+	md = metadata.MD{}
+	for i := uint64(0); i < count; i += 2 {
+		var key, value string
+		if key, body, err = readString(body); err != nil {
+			return "", nil, nil, err
+		}
+		if value, body, err = readString(body); err != nil {
+			return "", nil, nil, err
+		}
+		md.Append(key, value)
+	}
+	return method, md, body, nil
+}
+
+// decodeUDPReply decodes the body of a reply datagram, after its ID, into
+// reply or the error it carries
+func decodeUDPReply(body []byte, reply proto.Message) error {
+	if len(body) < 4 {
+		return status.Error(codes.Internal, "malformed UDP reply")
+	}
+	code, payload := binary.BigEndian.Uint32(body), body[4:]
+	if codes.Code(code) == codes.OK {
+		return proto.Unmarshal(payload, reply)
+	}
+	var st spb.Status
+	if err := proto.Unmarshal(payload, &st); err != nil {
+		return status.Error(codes.Code(code), "malformed UDP error reply")
+	}
+	return status.ErrorProto(&st)
+}
+
+// appendString appends a uvarint-length prefixed string
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// readString reads a uvarint-length prefixed string
+func readString(b []byte) (string, []byte, error) {
+	length, size := binary.Uvarint(b)
+	if size <= 0 || length > uint64(len(b)-size) {
+		return "", nil, errors.New("malformed string")
+	}
+	end := size + int(length)
+	return string(b[size:end]), b[end:], nil
+}
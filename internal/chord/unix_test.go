@@ -0,0 +1,65 @@
+package chord
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"chord-dht/pkg/hash"
+)
+
+func TestUnixSocketRing(t *testing.T) {
+	dir := t.TempDir()
+
+	// A socket left behind by a node that did not stop cleanly
+	stale, err := net.Listen("unix", filepath.Join(dir, "chord-0.sock"))
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	nodes := make([]*Node, 3)
+	for i := range nodes {
+		addr := unixScheme + filepath.Join(dir, fmt.Sprintf("chord-%d.sock", i))
+		nodes[i] = NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d on a Unix socket: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d over a Unix socket: %v", i, err)
+		}
+	}
+	for round := 0; round < len(nodes)+1; round++ {
+		for _, node := range nodes {
+			node.stabilize()
+		}
+	}
+	checkRing(t, nodes)
+
+	// Clients dial the same address
+	checkPing(t, nodes[1].GetAddress())
+
+	// A socket a live node accepts on is not taken over
+	taken := NewNode(nodes[2].GetAddress(), nil)
+	if err := taken.Start(); err == nil {
+		taken.Stop()
+		t.Error("Expected a socket in use to be refused")
+	}
+}
+
+func TestUnixSocketNeedsIP(t *testing.T) {
+	node := NewNode(unixScheme+filepath.Join(t.TempDir(), "chord.sock"), nil)
+	node.SetNetwork(NetworkQUIC)
+	if err := node.Start(); err == nil {
+		node.Stop()
+		t.Fatal("Expected QUIC on a Unix socket to be refused")
+	}
+}
@@ -0,0 +1,158 @@
+package chord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MinProtocolVersion is the oldest version of the Chord protocol this node
+// still speaks. Nodes speaking overlapping ranges of versions interoperate,
+// so a ring can be upgraded one node at a time. Version 2 moved the API to
+// the chord.v1 package, whose RPCs nodes of version 1 cannot call.
+const MinProtocolVersion = uint32(pb.ProtocolVersion_PROTOCOL_VERSION_MIN)
+
+// Metadata keys carrying the protocol versions the caller speaks on every
+// RPC between nodes
+const (
+	versionMetadataKey    = "chord-protocol-version"
+	minVersionMetadataKey = "chord-protocol-min-version"
+)
+
+// VersionRange is a range of protocol versions a node speaks
+type VersionRange struct {
+	Min uint32
+	Max uint32
+}
+
+// String describes the range
+func (r VersionRange) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprintf("protocol version %d", r.Max)
+	}
+	return fmt.Sprintf("protocol versions %d to %d", r.Min, r.Max)
+}
+
+// Negotiate returns the newest version both ranges speak, or false if they
+// do not overlap
+func (r VersionRange) Negotiate(other VersionRange) (uint32, bool) {
+	version := min(r.Max, other.Max)
+	if version < r.Min || version < other.Min {
+		return 0, false
+	}
+	return version, true
+}
+
+// SupportedVersions returns the range of protocol versions the node speaks
+func (n *Node) SupportedVersions() VersionRange {
+	return n.versions
+}
+
+// VersionError reports a peer that speaks no protocol version this node
+// speaks
+type VersionError struct {
+	// Address of the peer, empty for an incoming RPC from an unknown address
+	Address string
+	Peer    VersionRange
+	Local   VersionRange
+}
+
+// Error implements the error interface
+func (e *VersionError) Error() string {
+	peer := "peer"
+	if e.Address != "" {
+		peer = e.Address
+	}
+	return fmt.Sprintf("%s: %s speaks %s but this node speaks %s",
+		ErrIncompatibleVersion, peer, e.Peer, e.Local)
+}
+
+// Is makes errors.Is(err, ErrIncompatibleVersion) match a VersionError
+func (e *VersionError) Is(target error) bool {
+	return target == ErrIncompatibleVersion
+}
+
+// callerVersions returns the protocol versions the caller of an incoming
+// RPC speaks. ok is false for callers that do not send them, such as
+// clients dialing the node directly, which are served as before.
+func callerVersions(ctx context.Context) (r VersionRange, ok bool, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	maxes, mins := md.Get(versionMetadataKey), md.Get(minVersionMetadataKey)
+	if len(maxes) == 0 {
+		return VersionRange{}, false, nil
+	}
+	r.Max, err = parseVersion(maxes[0])
+	if err != nil {
+		return VersionRange{}, false, err
+	}
+	r.Min = r.Max
+	if len(mins) > 0 {
+		if r.Min, err = parseVersion(mins[0]); err != nil {
+			return VersionRange{}, false, err
+		}
+	}
+	return r, true, nil
+}
+
+// parseVersion parses a protocol version sent as metadata
+func parseVersion(s string) (uint32, error) {
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid protocol version %q", s)
+	}
+	return uint32(v), nil
+}
+
+// checkCaller rejects an incoming RPC from a node that speaks no version of
+// the protocol this node speaks
+func (n *Node) checkCaller(ctx context.Context) error {
+	caller, ok, err := callerVersions(ctx)
+	if err != nil || !ok {
+		return err
+	}
+	if _, ok := n.versions.Negotiate(caller); !ok {
+		return toStatus(&VersionError{Address: peerHost(ctx), Peer: caller, Local: n.versions})
+	}
+	return nil
+}
+
+// outgoingVersions adds the protocol versions this node speaks to the
+// metadata of an outgoing RPC
+func (n *Node) outgoingVersions(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		versionMetadataKey, strconv.FormatUint(uint64(n.versions.Max), 10),
+		minVersionMetadataKey, strconv.FormatUint(uint64(n.versions.Min), 10))
+}
+
+// versionMiddleware sends the protocol versions this node speaks with every
+// RPC to a peer and refuses RPCs from peers speaking none of them. The
+// node installs it ahead of the user's middleware.
+func (n *Node) versionMiddleware() Middleware {
+	return Middleware{
+		UnaryServer: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := n.checkCaller(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		StreamServer: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := n.checkCaller(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+		UnaryClient: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(n.outgoingVersions(ctx), method, req, reply, cc, opts...)
+		},
+		StreamClient: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(n.outgoingVersions(ctx), desc, cc, method, opts...)
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package chord
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVersionRangeNegotiate(t *testing.T) {
+	tests := []struct {
+		a, b    VersionRange
+		version uint32
+		ok      bool
+	}{
+		{VersionRange{1, 1}, VersionRange{1, 1}, 1, true},
+		{VersionRange{1, 2}, VersionRange{1, 1}, 1, true},
+		{VersionRange{1, 3}, VersionRange{2, 4}, 3, true},
+		{VersionRange{2, 2}, VersionRange{1, 1}, 0, false},
+		{VersionRange{1, 1}, VersionRange{3, 4}, 0, false},
+	}
+	for _, tt := range tests {
+		version, ok := tt.a.Negotiate(tt.b)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("%v.Negotiate(%v) = %d, %v; want %d, %v", tt.a, tt.b, version, ok, tt.version, tt.ok)
+		}
+		if back, _ := tt.b.Negotiate(tt.a); back != version {
+			t.Errorf("Negotiate is not symmetric for %v and %v", tt.a, tt.b)
+		}
+	}
+}
+
+func TestJoinRefusesIncompatibleVersion(t *testing.T) {
+	nodes := startTestRing(t, 8496, 1)
+
+	// A node that dropped the ring's version cannot join it
+	newer := NewNode("localhost:8497", nil)
+	newer.versions = VersionRange{Min: ProtocolVersion + 1, Max: ProtocolVersion + 1}
+	if err := newer.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(newer.Stop)
+
+	err := newer.Join(nodes[0].GetAddress())
+	if !errors.Is(err, ErrIncompatibleVersion) {
+		t.Fatalf("Expected ErrIncompatibleVersion, got %v", err)
+	}
+	var versionErr *VersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("Expected a VersionError, got %T", err)
+	}
+	if versionErr.Peer != nodes[0].SupportedVersions() || versionErr.Local != newer.SupportedVersions() {
+		t.Errorf("Expected peer %v and local %v, got %+v", nodes[0].SupportedVersions(), newer.SupportedVersions(), versionErr)
+	}
+	if !strings.Contains(err.Error(), nodes[0].GetAddress()) {
+		t.Errorf("Expected the error to name the bootstrap, got %q", err)
+	}
+
+	// One that still speaks it joins
+	upgraded := NewNode("localhost:8498", nil)
+	upgraded.versions = VersionRange{Min: ProtocolVersion, Max: ProtocolVersion + 1}
+	if err := upgraded.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(upgraded.Stop)
+	if err := upgraded.Join(nodes[0].GetAddress()); err != nil {
+		t.Fatalf("Join of an upgraded node failed: %v", err)
+	}
+}
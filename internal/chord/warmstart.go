@@ -0,0 +1,284 @@
+package chord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+)
+
+// warmStartPeers bounds how many saved peers a restarting node tries to
+// join through before falling back to its bootstrap
+const warmStartPeers = 8
+
+// savedNode is a peer in a saved routing state
+type savedNode struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// RoutingState is the routing table a node saves on shutdown and seeds
+// itself from when it restarts with the same ID
+type RoutingState struct {
+	ID         string      `json:"id"`
+	Address    string      `json:"address"`
+	SavedAt    time.Time   `json:"saved_at"`
+	Successors []savedNode `json:"successors"`
+	// Fingers has one entry per finger index, nil where unset
+	Fingers []*savedNode `json:"fingers"`
+	// OwnedFrom is the exclusive start of the range the node owned, empty
+	// if it owned none
+	OwnedFrom string `json:"owned_from,omitempty"`
+}
+
+// RoutingState returns the node's current successor list and finger table
+func (n *Node) RoutingState() *RoutingState {
+	n.own.mu.Lock()
+	ownedFrom := ""
+	if n.own.start != nil {
+		ownedFrom = n.own.start.String()
+	}
+	n.own.mu.Unlock()
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	state := &RoutingState{
+		OwnedFrom: ownedFrom,
+		ID:        n.id.String(),
+		Address:   n.address,
+		SavedAt:   time.Now().UTC(),
+		Fingers:   make([]*savedNode, len(n.fingers)),
+	}
+	for _, succ := range n.successorList {
+		state.Successors = append(state.Successors, savedNode{ID: succ.ID.String(), Address: succ.Address})
+	}
+	for i, finger := range n.fingers {
+		if finger != nil {
+			state.Fingers[i] = &savedNode{ID: finger.ID.String(), Address: finger.Address}
+		}
+	}
+	return state
+}
+
+// SaveRoutingState writes the node's routing state to path, replacing it
+// atomically
+func (n *Node) SaveRoutingState(path string) error {
+	data, err := json.MarshalIndent(n.RoutingState(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomic writes a temporary file next to path with write and
+// renames it over path, so readers never see a partial file
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadRoutingState reads a routing state written by SaveRoutingState
+func LoadRoutingState(path string) (*RoutingState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state RoutingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Rejoin joins the ring through the peers saved at path, successors first,
+// and seeds the finger table with the saved fingers that still answer. It
+// falls back to Join(bootstrapAddr) if there is no usable state, it was
+// saved under another ID or no saved peer could be joined through.
+func (n *Node) Rejoin(path, bootstrapAddr string) error {
+	state, err := LoadRoutingState(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return n.Join(bootstrapAddr)
+	case err != nil:
+		log.Printf("Node %s: ignoring routing state: %v", n.id.Short(), err)
+		return n.Join(bootstrapAddr)
+	}
+	return n.RejoinState(state, bootstrapAddr)
+}
+
+// RejoinState is Rejoin with a routing state already loaded, such as the
+// one of a restored snapshot
+func (n *Node) RejoinState(state *RoutingState, bootstrapAddr string) error {
+	if id, err := hash.NewHashFromHex(state.ID); err != nil || !id.Equal(n.id) {
+		log.Printf("Node %s: routing state belongs to node %s, ignoring it",
+			n.id.Short(), state.ID)
+		return n.Join(bootstrapAddr)
+	}
+
+	for _, peer := range state.peers(n.address) {
+		successor, err := n.askSuccessor(peer)
+		if err == nil && successor.ID.Equal(n.id) {
+			// The ring has not noticed our restart yet and still routes our
+			// ID to us, possibly at our old address; our successor is the
+			// one we saved
+			successor, err = n.liveSavedSuccessor(state)
+		}
+		if err == nil {
+			err = n.joinSuccessor(peer, successor)
+		}
+		if err != nil {
+			log.Printf("Node %s: rejoin through saved peer %s failed: %v", n.id.Short(), peer, err)
+			continue
+		}
+		n.resumeRange(state)
+		seeded := n.seedFingers(state.Fingers)
+		log.Printf("Node %s: rejoined through saved peer %s, %d fingers warm (state saved %v ago)",
+			n.id.Short(), peer, seeded, time.Since(state.SavedAt).Truncate(time.Second))
+		return nil
+	}
+
+	log.Printf("Node %s: no saved peer reachable, joining through the bootstrap", n.id.Short())
+	return n.Join(bootstrapAddr)
+}
+
+// WarmFingers seeds the finger table of a node that has already joined
+// with the fingers of a routing state saved under its ID, such as the one
+// of a restored snapshot, so lookups take the shortest routes without
+// waiting for fixFingers to reach every entry. It returns the number of
+// entries set.
+func (n *Node) WarmFingers(state *RoutingState) int {
+	if id, err := hash.NewHashFromHex(state.ID); err != nil || !id.Equal(n.id) {
+		return 0
+	}
+	return n.seedFingers(state.Fingers)
+}
+
+// resumeRange takes back the range the node owned when the state was saved
+// if its successor turned down the hand-off because it does not own our ID
+// either: the ring has not noticed the restart, so nobody took the range
+// over
+func (n *Node) resumeRange(state *RoutingState) {
+	start, err := hash.NewHashFromHex(state.OwnedFrom)
+	if err != nil || start.Equal(n.id) {
+		return
+	}
+	var notResp *NotResponsibleError
+	if err := n.pullHandoff(); !errors.As(err, &notResp) {
+		return
+	}
+
+	n.own.mu.Lock()
+	defer n.own.mu.Unlock()
+
+	if n.own.start == nil {
+		n.own.start = start
+		log.Printf("Node %s: resumed ownership of its saved range from %s",
+			n.id.Short(), start.Short())
+	}
+}
+
+// peers returns the distinct saved addresses to rejoin through, other than
+// self, successors before fingers
+func (s *RoutingState) peers(self string) []string {
+	seen := map[string]bool{self: true, "": true}
+	var peers []string
+	add := func(address string) {
+		if !seen[address] && len(peers) < warmStartPeers {
+			seen[address] = true
+			peers = append(peers, address)
+		}
+	}
+	for _, succ := range s.Successors {
+		add(succ.Address)
+	}
+	for _, finger := range s.Fingers {
+		if finger != nil {
+			add(finger.Address)
+		}
+	}
+	return peers
+}
+
+// liveSavedSuccessor returns the first saved successor that answers a ping
+func (n *Node) liveSavedSuccessor(state *RoutingState) (*NodeInfo, error) {
+	for _, succ := range state.Successors {
+		id, err := hash.NewHashFromHex(succ.ID)
+		if err != nil || id.Equal(n.id) {
+			continue
+		}
+		if n.pingWithTimeout(succ.Address) == nil {
+			return &NodeInfo{ID: id, Address: succ.Address}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no saved successor answers", ErrPeerUnreachable)
+}
+
+// seedFingers installs the saved fingers whose node still answers a ping,
+// leaving the rest for fixFingers. It returns the number of entries set.
+func (n *Node) seedFingers(saved []*savedNode) int {
+	alive := make(map[string]bool)
+	fingers := make([]*NodeInfo, FingerTableSize)
+	seeded := 0
+	for i, finger := range saved {
+		if i >= FingerTableSize || finger == nil || finger.Address == n.address {
+			continue
+		}
+		id, err := hash.NewHashFromHex(finger.ID)
+		if err != nil {
+			continue
+		}
+		up, checked := alive[finger.Address]
+		if !checked {
+			up = n.pingWithTimeout(finger.Address) == nil
+			alive[finger.Address] = up
+		}
+		if up {
+			fingers[i] = &NodeInfo{ID: id, Address: finger.Address}
+			seeded++
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// Entries fixFingers has not reached yet still point at ourselves
+	for i, finger := range fingers {
+		if finger != nil && (n.fingers[i] == nil || n.fingers[i].Address == n.address) {
+			n.fingers[i] = finger
+		}
+	}
+	return seeded
+}
+
+// pingWithTimeout checks that the node at address answers within the RPC
+// timeout
+func (n *Node) pingWithTimeout(address string) error {
+	ctx, cancel := context.WithTimeout(n.ctx, RPCTimeout)
+	defer cancel()
+
+	req := &pb.PingRequest{Requester: toProtoNode(n.GetNodeInfo())}
+	return n.invokeMaintenance(ctx, address, pb.ChordService_Ping_FullMethodName, req, &pb.PingResponse{})
+}
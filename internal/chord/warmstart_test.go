@@ -0,0 +1,102 @@
+package chord
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRejoinFromSavedState(t *testing.T) {
+	nodes := startTestRing(t, 8447, 3)
+	restarted := nodes[0]
+	for i := 0; i < FingerTableSize; i++ {
+		restarted.fixFingers()
+	}
+
+	path := filepath.Join(t.TempDir(), "routing.json")
+	if err := restarted.SaveRoutingState(path); err != nil {
+		t.Fatalf("SaveRoutingState failed: %v", err)
+	}
+	restarted.Stop()
+
+	node := NewNode(restarted.GetAddress(), restarted.GetID())
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to restart node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+
+	// Without a bootstrap the node would create a ring of its own
+	if err := node.Rejoin(path, ""); err != nil {
+		t.Fatalf("Rejoin failed: %v", err)
+	}
+	if succ := node.GetSuccessor(); succ == nil || succ.Address == node.GetAddress() {
+		t.Fatalf("Expected to rejoin the saved ring, successor is %v", succ)
+	}
+	warm := 0
+	for _, finger := range node.GetFingers() {
+		if finger != nil && finger.Address != node.GetAddress() {
+			warm++
+		}
+	}
+	if warm == 0 {
+		t.Error("Expected the finger table to be seeded from the saved state")
+	}
+
+	// State saved under another ID is ignored
+	other := NewNode("localhost:8450", nil)
+	if err := other.Start(); err != nil {
+		t.Fatalf("Failed to start node: %v", err)
+	}
+	t.Cleanup(other.Stop)
+	if err := other.Rejoin(path, ""); err != nil {
+		t.Fatalf("Rejoin failed: %v", err)
+	}
+	if succ := other.GetSuccessor(); succ.Address != other.GetAddress() {
+		t.Errorf("Expected a node with foreign state to create its own ring, successor is %s", succ.Address)
+	}
+}
+
+func TestWarmFingersFromSnapshot(t *testing.T) {
+	nodes := startTestRing(t, 8489, 3)
+	previous := nodes[0]
+	for i := 0; i < FingerTableSize; i++ {
+		previous.fixFingers()
+	}
+	previous.Storage().Put("warm", Entry{Value: []byte("value"), Version: 1})
+
+	path := filepath.Join(t.TempDir(), "node.snapshot")
+	if err := previous.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	previous.Stop()
+
+	node := NewNode(previous.GetAddress(), previous.GetID())
+	if err := node.Start(); err != nil {
+		t.Fatalf("Failed to restart node: %v", err)
+	}
+	t.Cleanup(node.Stop)
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer file.Close()
+	state, err := node.RestoreSnapshot(file)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	if _, ok, _ := node.Storage().Get("warm"); !ok {
+		t.Error("Expected the snapshot's key to be restored")
+	}
+
+	// A fresh join leaves the fingers pointing at the node itself
+	if err := node.Join(nodes[1].GetAddress()); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	if seeded := node.WarmFingers(state); seeded == 0 {
+		t.Error("Expected the finger table to be seeded from the snapshot")
+	}
+	if seeded := nodes[1].WarmFingers(state); seeded != 0 {
+		t.Errorf("Expected state of another node to be ignored, %d fingers seeded", seeded)
+	}
+}
@@ -0,0 +1,80 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+)
+
+// Buckets walks the ring from start and sums the stats of bucket on every
+// node, or of every bucket if empty, sorted by name
+func (c *Crawler) Buckets(ctx context.Context, start, bucket string) ([]chord.BucketStats, error) {
+	total := make(map[string]*chord.BucketStats)
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.GetBucketStats(rpcCtx, &pb.GetBucketStatsRequest{Bucket: bucket})
+		if err != nil {
+			return fmt.Errorf("get bucket stats from %s failed: %w", address, err)
+		}
+		chord.MergeBucketStats(total, chord.BucketStatsFromProto(resp.Buckets))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]chord.BucketStats, 0, len(total))
+	for _, s := range total {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Bucket < stats[j].Bucket })
+	return stats, nil
+}
+
+// BucketKeys walks the ring from start and lists the keys of bucket that
+// every node owns, sorted and without the bucket's name
+func (c *Crawler) BucketKeys(ctx context.Context, start, bucket string) ([]string, error) {
+	seen := make(map[string]bool)
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		for after := ""; ; {
+			rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+			resp, err := client.ListBucket(rpcCtx, &pb.ListBucketRequest{Bucket: bucket, After: after, Limit: chord.MaxBucketPage})
+			cancel()
+			if err != nil {
+				return fmt.Errorf("list bucket on %s failed: %w", address, err)
+			}
+			for _, key := range resp.Keys {
+				seen[key] = true
+			}
+			if !resp.More || len(resp.Keys) == 0 {
+				return nil
+			}
+			after = resp.Keys[len(resp.Keys)-1]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,376 @@
+// Package crawl walks a Chord ring through successor pointers and collects
+// ring-wide views from the individual nodes, such as the keyspace density
+// map used to spot skewed application key distributions.
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// DefaultMaxNodes bounds a walk whose successor pointers never lead back
+	// to the start node
+	DefaultMaxNodes = 10000
+	// DefaultTimeout is the per-RPC timeout
+	DefaultTimeout = 5 * time.Second
+)
+
+// Crawler visits every node of a ring
+type Crawler struct {
+	MaxNodes int
+	Timeout  time.Duration
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// New creates a Crawler with default limits
+func New() *Crawler {
+	return &Crawler{
+		MaxNodes: DefaultMaxNodes,
+		Timeout:  DefaultTimeout,
+		conns:    make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Close closes all connections opened by the crawler
+func (c *Crawler) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for address, conn := range c.conns {
+		conn.Close()
+		delete(c.conns, address)
+	}
+}
+
+// client returns a cached client for address
+func (c *Crawler) client(address string) (pb.ChordServiceClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[address]; ok {
+		return pb.NewChordServiceClient(conn), nil
+	}
+
+	conn, err := grpc.NewClient(address, append(chord.RequestDialOptions(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	c.conns[address] = conn
+	return pb.NewChordServiceClient(conn), nil
+}
+
+// Walk calls visit for every node reachable from start by following
+// successor pointers, in ring order, and stops once it is back at the first
+// node it visited
+func (c *Crawler) Walk(ctx context.Context, start string, visit func(address string) error) error {
+	visited := make(map[string]bool)
+	address := start
+	for len(visited) < c.MaxNodes {
+		if visited[address] {
+			return nil
+		}
+		visited[address] = true
+
+		if err := visit(address); err != nil {
+			return err
+		}
+
+		next, err := c.successor(ctx, address)
+		if err != nil {
+			return err
+		}
+		address = next
+	}
+	return fmt.Errorf("walk from %s did not close after %d nodes", start, c.MaxNodes)
+}
+
+// info asks the node at address for its routing state
+func (c *Crawler) info(ctx context.Context, address string) (*pb.GetInfoResponse, error) {
+	client, err := c.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	resp, err := client.GetInfo(ctx, &pb.GetInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get info from %s failed: %w", address, err)
+	}
+	return resp, nil
+}
+
+// routingTable asks the node at address for its full routing table
+func (c *Crawler) routingTable(ctx context.Context, address string) (*chord.RoutingTable, error) {
+	client, err := c.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	resp, err := client.GetRoutingTable(ctx, &pb.GetRoutingTableRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get routing table from %s failed: %w", address, err)
+	}
+	table, err := chord.RoutingTableFromProto(resp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid routing table from %s: %w", address, err)
+	}
+	return table, nil
+}
+
+// successor asks the node at address for its immediate successor
+func (c *Crawler) successor(ctx context.Context, address string) (string, error) {
+	resp, err := c.info(ctx, address)
+	if err != nil {
+		return "", err
+	}
+	if resp.Successor == nil {
+		return "", fmt.Errorf("node %s has no successor", address)
+	}
+	return resp.Successor.Address, nil
+}
+
+// DensityMap is the keyspace density of every node in a ring
+type DensityMap struct {
+	// Estimates holds one entry per node in ring order
+	Estimates []*chord.DensityEstimate
+	// Keys is the total number of keys counted across the ring
+	Keys int64
+	// Mean, Min and Max summarize the per-node densities of nodes that know
+	// their arc
+	Mean float64
+	Min  float64
+	Max  float64
+}
+
+// Skew returns how far the densest arc is above the ring average. It is
+// close to 1 for uniformly hashed keys and grows with hot spots.
+func (m *DensityMap) Skew() float64 {
+	if m.Mean == 0 {
+		return 0
+	}
+	return m.Max / m.Mean
+}
+
+// DensityMap walks the ring from start and collects the local density
+// estimate of every node
+func (c *Crawler) DensityMap(ctx context.Context, start string) (*DensityMap, error) {
+	densities := &DensityMap{Min: math.Inf(1)}
+	var arcs float64
+
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.GetDensity(rpcCtx, &pb.GetDensityRequest{LocalOnly: true})
+		if err != nil {
+			return fmt.Errorf("get density from %s failed: %w", address, err)
+		}
+		estimate, err := chord.DensityFromProto(resp)
+		if err != nil {
+			return fmt.Errorf("invalid density from %s: %w", address, err)
+		}
+
+		densities.Estimates = append(densities.Estimates, estimate)
+		densities.Keys += estimate.Keys
+		if estimate.ArcFraction > 0 {
+			arcs += estimate.ArcFraction
+			densities.Min = math.Min(densities.Min, estimate.Density)
+			densities.Max = math.Max(densities.Max, estimate.Density)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if arcs > 0 {
+		densities.Mean = float64(densities.Keys) / arcs
+	} else {
+		densities.Min = 0
+	}
+	return densities, nil
+}
+
+// Maintenance walks the ring from start and collects the maintenance window
+// status of every node, in ring order
+func (c *Crawler) Maintenance(ctx context.Context, start string) ([]chord.MaintenanceStatus, error) {
+	var statuses []chord.MaintenanceStatus
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.GetMaintenance(rpcCtx, &pb.GetMaintenanceRequest{})
+		if err != nil {
+			return fmt.Errorf("get maintenance from %s failed: %w", address, err)
+		}
+		status, err := chord.MaintenanceFromProto(resp.Status)
+		if err != nil {
+			return fmt.Errorf("invalid maintenance status from %s: %w", address, err)
+		}
+		statuses = append(statuses, status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// ObservedEvent is a membership event and the node that recorded it
+type ObservedEvent struct {
+	Observer *chord.NodeInfo
+	chord.MembershipEvent
+}
+
+// History walks the ring from start and merges the membership history kept
+// by every node into one timeline, oldest first
+func (c *Crawler) History(ctx context.Context, start string) ([]ObservedEvent, error) {
+	var events []ObservedEvent
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.GetMembershipHistory(rpcCtx, &pb.GetMembershipHistoryRequest{})
+		if err != nil {
+			return fmt.Errorf("get membership history from %s failed: %w", address, err)
+		}
+		observer, err := nodeFromProto(resp.Node)
+		if err != nil {
+			return fmt.Errorf("invalid membership history from %s: %w", address, err)
+		}
+		history, err := chord.MembershipFromProto(resp.Events)
+		if err != nil {
+			return fmt.Errorf("invalid membership history from %s: %w", address, err)
+		}
+		for _, event := range history {
+			events = append(events, ObservedEvent{Observer: observer, MembershipEvent: event})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
+// EpochStats is the counters of every node of a ring sampled at the start
+// of one stats epoch
+type EpochStats struct {
+	Epoch uint64
+	// Samples holds one entry per node that sampled the epoch, in ring order
+	Samples []chord.StatsSample
+	// Missing lists the nodes the epoch did not reach, or that no longer
+	// keep their sample of it
+	Missing  []string
+	Messages int64
+	Lookups  int64
+	// Spread is the time between the first and the last sample
+	Spread time.Duration
+}
+
+// MessagesPerLookup returns the ring-wide messages per lookup at the epoch
+func (s *EpochStats) MessagesPerLookup() float64 {
+	if s.Lookups == 0 {
+		return 0
+	}
+	return float64(s.Messages) / float64(s.Lookups)
+}
+
+// StatsEpoch starts a stats epoch at start, then walks the ring and
+// collects the sample every node took of it
+func (c *Crawler) StatsEpoch(ctx context.Context, start string) (*EpochStats, error) {
+	first, err := c.statsSample(ctx, start, &pb.GetStatsSampleRequest{Start: true})
+	if err != nil {
+		return nil, err
+	}
+	if !first.Found {
+		return nil, fmt.Errorf("node %s did not sample the epoch it started", start)
+	}
+	stats := &EpochStats{Epoch: first.Sample.Epoch}
+
+	var earliest, latest time.Time
+	err = c.Walk(ctx, start, func(address string) error {
+		resp, err := c.statsSample(ctx, address, &pb.GetStatsSampleRequest{Epoch: stats.Epoch})
+		if err != nil {
+			return err
+		}
+		if !resp.Found {
+			stats.Missing = append(stats.Missing, address)
+			return nil
+		}
+		sample, err := chord.StatsSampleFromProto(resp.Sample)
+		if err != nil {
+			return fmt.Errorf("invalid stats sample from %s: %w", address, err)
+		}
+		stats.Samples = append(stats.Samples, sample)
+		stats.Messages += sample.Messages
+		stats.Lookups += sample.Lookups
+		if earliest.IsZero() || sample.Time.Before(earliest) {
+			earliest = sample.Time
+		}
+		if sample.Time.After(latest) {
+			latest = sample.Time
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.Spread = latest.Sub(earliest)
+	return stats, nil
+}
+
+// statsSample sends a GetStatsSample request to the node at address
+func (c *Crawler) statsSample(ctx context.Context, address string, req *pb.GetStatsSampleRequest) (*pb.GetStatsSampleResponse, error) {
+	client, err := c.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	resp, err := client.GetStatsSample(rpcCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("get stats sample from %s failed: %w", address, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,400 @@
+package crawl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+)
+
+func TestDensityMap(t *testing.T) {
+	nodes := startRing(t, 8330, 3)
+
+	items := make(map[string][]byte)
+	for i := 0; i < 200; i++ {
+		items[fmt.Sprintf("key_%d", i)] = []byte("v")
+	}
+	if err := nodes[0].StoreBatch(context.Background(), items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	crawler := New()
+	defer crawler.Close()
+
+	densities, err := crawler.DensityMap(context.Background(), nodes[1].GetAddress())
+	if err != nil {
+		t.Fatalf("DensityMap failed: %v", err)
+	}
+	if len(densities.Estimates) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(densities.Estimates))
+	}
+	if densities.Keys != 200 {
+		t.Errorf("Expected 200 keys, got %d", densities.Keys)
+	}
+	if densities.Skew() < 1 {
+		t.Errorf("Skew should be at least 1, got %f", densities.Skew())
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	nodes := startRing(t, 8425, 3)
+	nodes[1].Pause("disk swap")
+
+	crawler := New()
+	defer crawler.Close()
+
+	statuses, err := crawler.Maintenance(context.Background(), nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("Maintenance failed: %v", err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(statuses))
+	}
+	for _, status := range statuses {
+		paused := status.Node.Address == nodes[1].GetAddress()
+		if status.Paused != paused {
+			t.Errorf("Node %s: expected paused=%v, got %+v", status.Node.Address, paused, status)
+		}
+		if paused && (status.Reason != "disk swap" || status.Since.IsZero()) {
+			t.Errorf("Expected the pause reason and start, got %+v", status)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	nodes := startRing(t, 8454, 3)
+
+	crawler := New()
+	defer crawler.Close()
+
+	events, err := crawler.History(context.Background(), nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	observers := make(map[string]bool)
+	for i, event := range events {
+		observers[event.Observer.Address] = true
+		if i > 0 && event.Time.Before(events[i-1].Time) {
+			t.Errorf("Event %d is out of order: %v before %v", i, event.Time, events[i-1].Time)
+		}
+	}
+	if len(observers) != 3 {
+		t.Errorf("Expected events from all 3 nodes, got %v", observers)
+	}
+}
+
+func TestTopology(t *testing.T) {
+	nodes := startRing(t, 8335, 3)
+
+	crawler := New()
+	defer crawler.Close()
+
+	topology, err := crawler.Topology(context.Background(), nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("Topology failed: %v", err)
+	}
+	if len(topology.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(topology.Nodes))
+	}
+	for _, issue := range topology.Issues {
+		if issue.Kind == IssueUnreachable || issue.Kind == IssueLoop || issue.Kind == IssueSkippedNode {
+			t.Errorf("Unexpected issue on a settled ring: %s at %s: %s", issue.Kind, issue.Node.Address, issue.Detail)
+		}
+	}
+
+	// A stopped node breaks the walk
+	nodes[2].Stop()
+	topology, err = crawler.Topology(context.Background(), nodes[0].GetAddress())
+	if err != nil {
+		t.Fatalf("Topology failed: %v", err)
+	}
+	if !hasIssue(topology, IssueUnreachable, nodes[2].GetAddress()) {
+		t.Errorf("Expected the stopped node to be reported unreachable, got %+v", topology.Issues)
+	}
+}
+
+func TestTopologyCheck(t *testing.T) {
+	a := &chord.NodeInfo{ID: hash.NewHashFromString("a"), Address: "a"}
+	b := &chord.NodeInfo{ID: hash.NewHashFromString("b"), Address: "b"}
+
+	// b does not name a as its predecessor
+	topology := &Topology{Nodes: []*NodeState{
+		{Node: a, Successor: b, Predecessor: b},
+		{Node: b, Successor: a},
+	}}
+	topology.check()
+	if !hasIssue(topology, IssueNonMutual, "a") {
+		t.Errorf("Expected non-mutual pair at a, got %+v", topology.Issues)
+	}
+	if hasIssue(topology, IssueNonMutual, "b") {
+		t.Errorf("Unexpected non-mutual pair at b")
+	}
+}
+
+// hasIssue reports whether the topology has an issue of kind at address
+func hasIssue(topology *Topology, kind IssueKind, address string) bool {
+	for _, issue := range topology.Issues {
+		if issue.Kind == kind && issue.Node.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// startRing starts count nodes on consecutive ports, joins them into one ring
+// and waits for it to settle
+func startRing(t *testing.T, basePort, count int) []*chord.Node {
+	nodes := make([]*chord.Node, count)
+	for i := range nodes {
+		addr := fmt.Sprintf("localhost:%d", basePort+i)
+		nodes[i] = chord.NewNode(addr, hash.NewHashFromString(addr))
+		if err := nodes[i].Start(); err != nil {
+			t.Fatalf("Failed to start node %d: %v", i, err)
+		}
+		t.Cleanup(nodes[i].Stop)
+
+		bootstrap := ""
+		if i > 0 {
+			bootstrap = nodes[0].GetAddress()
+		}
+		if err := nodes[i].Join(bootstrap); err != nil {
+			t.Fatalf("Failed to join node %d: %v", i, err)
+		}
+	}
+	waitForRing(t, nodes)
+	return nodes
+}
+
+// waitForRing waits until every node has a predecessor
+func waitForRing(t *testing.T, nodes []*chord.Node) {
+	for attempt := 0; attempt < 150; attempt++ {
+		settled := true
+		for _, node := range nodes {
+			if node.GetPredecessor() == nil {
+				settled = false
+			}
+		}
+		if settled {
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Fatal("Ring did not stabilize")
+}
+
+func TestLoad(t *testing.T) {
+	nodes := startRing(t, 8565, 3)
+
+	items := make(map[string][]byte)
+	for i := 0; i < 300; i++ {
+		items[fmt.Sprintf("key_%d", i)] = []byte("v")
+	}
+	if err := nodes[0].StoreBatch(context.Background(), items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	crawler := New()
+	defer crawler.Close()
+
+	report, err := crawler.Load(context.Background(), nodes[2].GetAddress())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(report.Nodes) != 3 || report.Keys != 300 {
+		t.Fatalf("Expected 3 nodes and 300 keys, got %d nodes and %d keys", len(report.Nodes), report.Keys)
+	}
+	var arcs float64
+	for _, load := range report.Nodes {
+		arcs += load.ArcFraction
+		if load.Share != 1.0/3 {
+			t.Errorf("Expected node %s to have a third of the weight, got %f", load.Node.Address, load.Share)
+		}
+	}
+	if arcs < 0.999 || arcs > 1.001 {
+		t.Errorf("Expected the arcs to cover the ring, got %f", arcs)
+	}
+	if report.ArcImbalance < 1 || report.KeyImbalance < 1 {
+		t.Errorf("Imbalances should be at least 1, got arcs %f, keys %f", report.ArcImbalance, report.KeyImbalance)
+	}
+}
+
+func TestVirtualNodesFor(t *testing.T) {
+	if got := ExpectedImbalance(1, 8); got != 1 {
+		t.Errorf("Expected a single node to be balanced, got %f", got)
+	}
+	few, many := ExpectedImbalance(16, 1), ExpectedImbalance(16, 64)
+	if few <= many || many < 1 {
+		t.Errorf("Expected virtual nodes to reduce the imbalance, got %f with 1 and %f with 64", few, many)
+	}
+
+	vnodes, ok := VirtualNodesFor(16, 1.25)
+	if !ok || ExpectedImbalance(16, vnodes) > 1.25 {
+		t.Errorf("Expected a virtual node count reaching 1.25, got %d (%v)", vnodes, ok)
+	}
+	if vnodes > 1 && ExpectedImbalance(16, vnodes/2) <= 1.25 {
+		t.Errorf("Expected %d to be the fewest virtual nodes reaching 1.25", vnodes)
+	}
+	if _, ok := VirtualNodesFor(16, 1); ok {
+		t.Error("Expected a perfect balance to be out of reach")
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source := startRing(t, 8634, 3)
+	target := startRing(t, 8637, 2)
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 120; i++ {
+		items[fmt.Sprintf("key_%d", i)] = []byte(fmt.Sprintf("value_%d", i))
+	}
+	if err := source[0].StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	crawler := New()
+	defer crawler.Close()
+
+	var dataset bytes.Buffer
+	var reports int
+	exported, err := crawler.Export(ctx, source[1].GetAddress(), &dataset, DatasetOptions{
+		BatchSize: 25,
+		Progress:  func(DatasetProgress) { reports++ },
+	})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if exported.Keys != 120 || bytes.Count(dataset.Bytes(), []byte("\n")) != 120 {
+		t.Fatalf("Expected 120 records, got %+v and %d lines", exported, bytes.Count(dataset.Bytes(), []byte("\n")))
+	}
+	if reports < 120/25 {
+		t.Errorf("Expected progress after every page, got %d reports", reports)
+	}
+
+	imported, err := crawler.Import(ctx, target[0].GetAddress(), &dataset, DatasetOptions{BatchSize: 10, Parallelism: 2})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported != exported {
+		t.Errorf("Imported %+v, exported %+v", imported, exported)
+	}
+	for key, want := range items {
+		value, err := target[1].FetchValue(ctx, key)
+		if err != nil || !bytes.Equal(value, want) {
+			t.Errorf("FetchValue(%q) returned %q, %v; expected %q", key, value, err, want)
+		}
+	}
+
+	_, err = crawler.Import(ctx, target[0].GetAddress(), bytes.NewBufferString("{\"key\": \"\"}\n"), DatasetOptions{})
+	if err == nil {
+		t.Error("Expected an import of an empty key to fail")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	source := startRing(t, 8639, 2)
+	dest := startRing(t, 8641, 2)
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 80; i++ {
+		items[fmt.Sprintf("users/%d", i)] = []byte(fmt.Sprintf("user %d", i))
+		items[fmt.Sprintf("logs/%d", i)] = []byte("line")
+	}
+	if err := source[0].StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	crawler := New()
+	defer crawler.Close()
+
+	report, err := crawler.Migrate(ctx, source[0].GetAddress(), dest[1].GetAddress(),
+		DatasetOptions{Prefix: "users/", BatchSize: 16}, true)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if !report.OK() || report.Keys != 80 || report.Found != 80 || report.FoundChecksum != report.Checksum {
+		t.Errorf("Expected 80 keys copied and verified, got %+v", report)
+	}
+	if _, err := dest[0].FetchValue(ctx, "logs/1"); err == nil {
+		t.Error("Expected keys outside the prefix left behind")
+	}
+
+	// A key changed on the destination since fails the verification
+	if err := dest[0].StoreValue(ctx, "users/7", []byte("changed")); err != nil {
+		t.Fatalf("StoreValue failed: %v", err)
+	}
+	digests := map[string]uint64{
+		"users/7": keyDigest("users/7", items["users/7"]),
+		"users/8": keyDigest("users/8", items["users/8"]),
+		"gone":    keyDigest("gone", nil),
+	}
+	report = &MigrationReport{}
+	if err := crawler.verifyMigration(ctx, dest[0].GetAddress(), DatasetOptions{}, digests, report); err != nil {
+		t.Fatalf("verifyMigration failed: %v", err)
+	}
+	if report.OK() || report.Found != 1 || report.Missing != 1 || report.MismatchedKeys[0] != "users/7" {
+		t.Errorf("Expected users/7 mismatched and gone missing, got %+v", report)
+	}
+}
+
+func TestRingSnapshot(t *testing.T) {
+	source := startRing(t, 8643, 3)
+	target := startRing(t, 8646, 2)
+	dir := t.TempDir()
+	for _, node := range source {
+		node.SetRingSnapshotDir(dir)
+	}
+
+	ctx := context.Background()
+	items := make(map[string][]byte)
+	for i := 0; i < 60; i++ {
+		items[fmt.Sprintf("key-%d", i)] = []byte(fmt.Sprintf("value %d", i))
+	}
+	if err := source[0].StoreBatch(ctx, items); err != nil {
+		t.Fatalf("StoreBatch failed: %v", err)
+	}
+
+	crawler := New()
+	defer crawler.Close()
+
+	manifest, err := crawler.RingSnapshot(ctx, source[1].GetAddress())
+	if err != nil {
+		t.Fatalf("RingSnapshot failed: %v", err)
+	}
+	entries := 0
+	for _, piece := range manifest.Pieces {
+		entries += piece.Entries
+	}
+	if !manifest.Complete || len(manifest.Pieces) != 3 || entries != 60 {
+		t.Fatalf("Expected a complete snapshot of 60 entries in 3 pieces, got %+v", manifest)
+	}
+
+	path := dir + "/manifest.json"
+	if err := SaveManifest(path, manifest); err != nil {
+		t.Fatalf("SaveManifest failed: %v", err)
+	}
+	totals, err := crawler.RestoreRingSnapshot(ctx, target[0].GetAddress(), path, DatasetOptions{BatchSize: 8})
+	if err != nil {
+		t.Fatalf("RestoreRingSnapshot failed: %v", err)
+	}
+	if totals.Keys != 60 {
+		t.Errorf("Expected 60 keys restored, got %d", totals.Keys)
+	}
+	if value, err := target[1].FetchValue(ctx, "key-42"); err != nil || string(value) != "value 42" {
+		t.Errorf("Expected key-42 restored, got %q, %v", value, err)
+	}
+
+	// A piece changed since the snapshot is refused
+	if err := os.WriteFile(manifest.Pieces[0].File, []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crawler.RestoreRingSnapshot(ctx, target[0].GetAddress(), path, DatasetOptions{}); err == nil {
+		t.Error("Expected a corrupt piece refused")
+	}
+}
@@ -0,0 +1,406 @@
+package crawl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+)
+
+const (
+	// DefaultBatchSize is the number of keys written or listed per RPC
+	DefaultBatchSize = 500
+	// DefaultParallelism is the number of nodes written or listed at once
+	DefaultParallelism = 4
+)
+
+// Record is one key of a dataset file, which holds one JSON record per
+// line. Value is base64 encoded in JSON.
+type Record struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// DatasetOptions configures Import, Export and Migrate
+type DatasetOptions struct {
+	// BatchSize is the number of keys per PutBatch or ListKeys call;
+	// DefaultBatchSize if zero
+	BatchSize int
+	// Parallelism is the number of RPCs in flight at once;
+	// DefaultParallelism if zero
+	Parallelism int
+	// Prefix limits an export to the keys starting with it
+	Prefix string
+	// Rate caps the keys per second an import writes; zero leaves it
+	// unlimited
+	Rate float64
+	// Placement is how the ring an import writes to places keys, hash.SHA1
+	// if nil
+	Placement hash.Provider
+	// Progress, if set, is called with the totals so far after every batch,
+	// from one goroutine at a time
+	Progress func(DatasetProgress)
+}
+
+// DatasetProgress counts the keys and value bytes moved by Import or Export
+type DatasetProgress struct {
+	Keys  int64
+	Bytes int64
+}
+
+// withDefaults fills in the zero fields of opts
+func (opts DatasetOptions) withDefaults() DatasetOptions {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = DefaultParallelism
+	}
+	if opts.Placement == nil {
+		opts.Placement = hash.SHA1
+	}
+	return opts
+}
+
+// pacer spaces batches out under a rate in keys per second
+type pacer struct {
+	mu   sync.Mutex
+	rate float64
+	// next is when the rate lets the next batch go out
+	next time.Time
+}
+
+// wait books keys under the rate and sleeps until they may be sent
+func (p *pacer) wait(ctx context.Context, keys int) error {
+	if p.rate <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(time.Duration(float64(keys) / p.rate * float64(time.Second)))
+	p.mu.Unlock()
+
+	timer := time.NewTimer(start.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// progress adds up the keys moved by concurrent batches and reports them
+type progress struct {
+	mu     sync.Mutex
+	totals DatasetProgress
+	report func(DatasetProgress)
+}
+
+// add counts items and reports the new totals
+func (p *progress) add(items []*pb.KeyValue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, item := range items {
+		p.totals.Keys++
+		p.totals.Bytes += int64(len(item.Value))
+	}
+	if p.report != nil {
+		p.report(p.totals)
+	}
+}
+
+// Export walks the ring from start and writes every live key and its value
+// to w, one Record per line. Nodes are listed several at once, each in key
+// order. The export is no snapshot: keys written meanwhile may or may not
+// be included, and keys whose range moves between nodes may be missed or
+// written twice.
+func (c *Crawler) Export(ctx context.Context, start string, w io.Writer, opts DatasetOptions) (DatasetProgress, error) {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	totals, err := c.exportItems(ctx, start, opts, func(items []*pb.KeyValue) error {
+		for _, item := range items {
+			if err := encoder.Encode(Record{Key: item.Key, Value: item.Value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if flushErr := buffered.Flush(); err == nil {
+		err = flushErr
+	}
+	return totals, err
+}
+
+// exportItems walks the ring from start and passes every page of keys and
+// values to write, from one goroutine at a time
+func (c *Crawler) exportItems(ctx context.Context, start string, opts DatasetOptions, write func([]*pb.KeyValue) error) (DatasetProgress, error) {
+	opts = opts.withDefaults()
+	var addresses []string
+	err := c.Walk(ctx, start, func(address string) error {
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		return DatasetProgress{}, err
+	}
+
+	var writeMu sync.Mutex
+	done := &progress{report: opts.Progress}
+	err = parallel(ctx, opts.Parallelism, addresses, func(ctx context.Context, address string) error {
+		return c.exportNode(ctx, address, opts, func(items []*pb.KeyValue) error {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if err := write(items); err != nil {
+				return err
+			}
+			done.add(items)
+			return nil
+		})
+	})
+	return done.totals, err
+}
+
+// exportNode pages through the keys the node at address owns
+func (c *Crawler) exportNode(ctx context.Context, address string, opts DatasetOptions, write func([]*pb.KeyValue) error) error {
+	client, err := c.client(address)
+	if err != nil {
+		return err
+	}
+	for cursor := ""; ; {
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		resp, err := client.ListKeys(rpcCtx, &pb.ListKeysRequest{
+			Prefix: opts.Prefix,
+			Cursor: cursor,
+			Limit:  int32(min(opts.BatchSize, chord.MaxKeyPage)),
+			Values: true,
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("list keys on %s failed: %w", address, err)
+		}
+		if err := write(resp.Items); err != nil {
+			return err
+		}
+		if resp.NextCursor == "" {
+			return nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// importBatch is a batch of keys owned by the node at address
+type importBatch struct {
+	address string
+	items   []*pb.KeyValue
+}
+
+// Import reads Records from r, one per line, and writes them to the ring
+// reached from start in batches of keys sent straight to their owners.
+// Owners are found from the node IDs of a ring walk and the Placement of
+// opts; a batch turned down by a node that is no longer the owner is sent
+// again where the node points. Import stops at the first record that cannot
+// be read or batch that cannot be written, so a failed import may have
+// written part of r; running it again is safe.
+func (c *Crawler) Import(ctx context.Context, start string, r io.Reader, opts DatasetOptions) (DatasetProgress, error) {
+	decoder := json.NewDecoder(r)
+	line := 0
+	return c.importItems(ctx, start, opts, func() (*pb.KeyValue, error) {
+		line++
+		var record Record
+		if err := decoder.Decode(&record); err == io.EOF {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		if record.Key == "" {
+			return nil, fmt.Errorf("record %d: empty key", line)
+		}
+		return &pb.KeyValue{Key: record.Key, Value: record.Value}, nil
+	})
+}
+
+// importItems writes the items returned by next, until it returns io.EOF,
+// to the ring reached from start
+func (c *Crawler) importItems(ctx context.Context, start string, opts DatasetOptions, next func() (*pb.KeyValue, error)) (DatasetProgress, error) {
+	opts = opts.withDefaults()
+	var nodes []*chord.NodeInfo
+	err := c.Walk(ctx, start, func(address string) error {
+		resp, err := c.info(ctx, address)
+		if err != nil {
+			return err
+		}
+		node, err := nodeFromProto(resp.Node)
+		if err != nil {
+			return fmt.Errorf("invalid node at %s: %w", address, err)
+		}
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return DatasetProgress{}, err
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID.Less(nodes[j].ID) })
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errMu    sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	done := &progress{report: opts.Progress}
+	pace := &pacer{rate: opts.Rate}
+	batches := make(chan importBatch)
+	for i := 0; i < opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := pace.wait(ctx, len(batch.items)); err != nil {
+					continue
+				}
+				if err := c.putBatch(ctx, batch.address, batch.items); err != nil {
+					fail(err)
+					continue
+				}
+				done.add(batch.items)
+			}
+		}()
+	}
+	send := func(batch importBatch) bool {
+		select {
+		case batches <- batch:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	pending := make(map[string][]*pb.KeyValue)
+	for ctx.Err() == nil {
+		item, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fail(err)
+			break
+		}
+
+		address := successorOf(nodes, opts.Placement.Hash(item.Key)).Address
+		pending[address] = append(pending[address], item)
+		if len(pending[address]) >= opts.BatchSize {
+			if !send(importBatch{address, pending[address]}) {
+				break
+			}
+			delete(pending, address)
+		}
+	}
+	for address, items := range pending {
+		if !send(importBatch{address, items}) {
+			break
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	errMu.Lock()
+	defer errMu.Unlock()
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return done.totals, firstErr
+}
+
+// putBatch writes items to the node at address, following the owner a node
+// that is not responsible points to
+func (c *Crawler) putBatch(ctx context.Context, address string, items []*pb.KeyValue) error {
+	return chord.DefaultRetryPolicies().Client.Do(ctx, func(int) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.PutBatch(rpcCtx, &pb.PutBatchRequest{Items: items})
+		if err != nil {
+			err = fmt.Errorf("put batch to %s failed: %w", address, chord.FromStatus(address, err))
+			var notResp *chord.NotResponsibleError
+			if errors.As(err, &notResp) && notResp.Owner != nil {
+				address = notResp.Owner.Address
+			}
+			return err
+		}
+		if !resp.Success {
+			return fmt.Errorf("put batch to %s failed: %s", address, resp.Error)
+		}
+		return nil
+	})
+}
+
+// parallel calls fn for every address with at most limit calls at once,
+// and returns the first error after canceling the calls still running
+func parallel(ctx context.Context, limit int, addresses []string, fn func(ctx context.Context, address string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, limit)
+	for _, address := range addresses {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := fn(ctx, address); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(address)
+	}
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,55 @@
+package crawl
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteDOT writes the topology as a Graphviz digraph. Successor edges are
+// solid, predecessor edges dashed and, if withFingers is set, finger edges
+// dotted; nodes with issues are drawn in red, and nodes are labeled with
+// their zone when they advertise one.
+func (t *Topology) WriteDOT(w io.Writer, withFingers bool) error {
+	flagged := make(map[string]bool)
+	for _, issue := range t.Issues {
+		flagged[issue.Node.Address] = true
+	}
+
+	fmt.Fprintln(w, "digraph chord {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, state := range t.Nodes {
+		color := "black"
+		if flagged[state.Node.Address] {
+			color = "red"
+		}
+		label := state.Node.Address + "\\n" + state.Node.ID.Short()
+		if state.Node.Zone != "" {
+			label += "\\n" + state.Node.Zone
+		}
+		fmt.Fprintf(w, "  %q [label=\"%s\", color=%s];\n", state.Node.Address, label, color)
+	}
+	for _, state := range t.Nodes {
+		if state.Successor != nil {
+			fmt.Fprintf(w, "  %q -> %q;\n", state.Node.Address, state.Successor.Address)
+		}
+		if state.Predecessor != nil {
+			fmt.Fprintf(w, "  %q -> %q [style=dashed, color=gray];\n", state.Node.Address, state.Predecessor.Address)
+		}
+		if !withFingers {
+			continue
+		}
+		seen := map[string]bool{state.Node.Address: true}
+		if state.Successor != nil {
+			seen[state.Successor.Address] = true
+		}
+		for _, finger := range state.Fingers {
+			if finger == nil || seen[finger.Address] {
+				continue
+			}
+			seen[finger.Address] = true
+			fmt.Fprintf(w, "  %q -> %q [style=dotted, color=blue];\n", state.Node.Address, finger.Address)
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,151 @@
+package crawl
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+)
+
+const (
+	// DefaultTargetImbalance is the load imbalance a rebalancing report
+	// suggests virtual nodes for when none is given
+	DefaultTargetImbalance = 1.25
+	// MaxVirtualNodes bounds the virtual nodes per node a report suggests
+	MaxVirtualNodes = 1024
+	// imbalancePoints is roughly how many ring positions are placed across
+	// the trials of one imbalance estimate
+	imbalancePoints = 1 << 18
+)
+
+// NodeLoad is one node's share of the keyspace and of the stored keys
+type NodeLoad struct {
+	Node *chord.NodeInfo
+	// ArcFraction is the share of the keyspace in (predecessor, node], zero
+	// if the node does not know its predecessor
+	ArcFraction float64
+	// Keys is the number of live keys stored in the arc
+	Keys int64
+	// Share is the node's fraction of the ring's capacity weight, which is
+	// what its arc and keys are compared with
+	Share float64
+}
+
+// LoadReport is how evenly the keyspace and the stored keys are spread over
+// a ring's nodes
+type LoadReport struct {
+	// Nodes holds one entry per node in ring order
+	Nodes []NodeLoad
+	Keys  int64
+	// ArcImbalance and KeyImbalance are the largest ratio of a node's arc,
+	// or stored keys, to its share of the ring's weight. They are 1 for a
+	// perfectly even ring.
+	ArcImbalance float64
+	KeyImbalance float64
+}
+
+// Load walks the ring from start and collects the arc and stored keys of
+// every node
+func (c *Crawler) Load(ctx context.Context, start string) (*LoadReport, error) {
+	report := &LoadReport{}
+	var weight uint32
+	err := c.Walk(ctx, start, func(address string) error {
+		client, err := c.client(address)
+		if err != nil {
+			return err
+		}
+
+		rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		resp, err := client.GetDensity(rpcCtx, &pb.GetDensityRequest{LocalOnly: true})
+		if err != nil {
+			return fmt.Errorf("get density from %s failed: %w", address, err)
+		}
+		estimate, err := chord.DensityFromProto(resp)
+		if err != nil {
+			return fmt.Errorf("invalid density from %s: %w", address, err)
+		}
+
+		report.Nodes = append(report.Nodes, NodeLoad{
+			Node:        estimate.Node,
+			ArcFraction: estimate.ArcFraction,
+			Keys:        estimate.Keys,
+		})
+		report.Keys += estimate.Keys
+		weight += estimate.Node.EffectiveWeight()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report.Nodes {
+		load := &report.Nodes[i]
+		load.Share = float64(load.Node.EffectiveWeight()) / float64(weight)
+		report.ArcImbalance = max(report.ArcImbalance, load.ArcFraction/load.Share)
+		if report.Keys > 0 {
+			report.KeyImbalance = max(report.KeyImbalance, float64(load.Keys)/float64(report.Keys)/load.Share)
+		}
+	}
+	return report, nil
+}
+
+// ExpectedImbalance estimates the arc imbalance of a ring of nodes equally
+// weighted nodes that each take vnodes random positions, the ratio of the
+// largest total arc of a node to the mean, by placing rings at random. It is
+// deterministic for given arguments.
+func ExpectedImbalance(nodes, vnodes int) float64 {
+	if nodes <= 1 {
+		return 1
+	}
+	vnodes = max(vnodes, 1)
+	trials := max(imbalancePoints/(nodes*vnodes), 3)
+	rng := rand.New(rand.NewSource(int64(nodes)<<32 | int64(vnodes)))
+
+	type position struct {
+		at   float64
+		node int
+	}
+	positions := make([]position, nodes*vnodes)
+	arcs := make([]float64, nodes)
+	var total float64
+	for trial := 0; trial < trials; trial++ {
+		for i := range positions {
+			positions[i] = position{at: rng.Float64(), node: i / vnodes}
+		}
+		slices.SortFunc(positions, func(a, b position) int {
+			return cmp.Compare(a.at, b.at)
+		})
+		clear(arcs)
+		// Each position owns the arc from the one before it, wrapping around
+		previous := positions[len(positions)-1].at - 1
+		for _, p := range positions {
+			arcs[p.node] += p.at - previous
+			previous = p.at
+		}
+		largest := 0.0
+		for _, arc := range arcs {
+			largest = max(largest, arc)
+		}
+		total += largest * float64(nodes)
+	}
+	return total / float64(trials)
+}
+
+// VirtualNodesFor returns the fewest virtual nodes per node, a power of
+// two up to MaxVirtualNodes, with which a ring of nodes equally weighted
+// nodes is expected to have an arc imbalance of at most target, and false
+// if even MaxVirtualNodes is not enough
+func VirtualNodesFor(nodes int, target float64) (int, bool) {
+	for vnodes := 1; vnodes <= MaxVirtualNodes; vnodes *= 2 {
+		if ExpectedImbalance(nodes, vnodes) <= target {
+			return vnodes, true
+		}
+	}
+	return MaxVirtualNodes, false
+}
@@ -0,0 +1,172 @@
+package crawl
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+// maxReportedKeys bounds the missing and mismatched keys a migration report
+// names
+const maxReportedKeys = 10
+
+// MigrationReport is the outcome of Migrate
+type MigrationReport struct {
+	// Copied counts the keys and value bytes read from the source and
+	// written to the destination
+	Copied DatasetProgress
+	// Keys is the number of distinct keys copied, and Checksum an order
+	// independent digest of them and their values
+	Keys     int64
+	Checksum uint64
+	// Verified is set once the destination was read back. Found and
+	// FoundChecksum then count and digest the copied keys found there with
+	// the value copied, so they match Keys and Checksum if nothing is
+	// missing.
+	Verified      bool
+	Found         int64
+	FoundChecksum uint64
+	// Missing and Mismatched count the copied keys the destination lacks or
+	// holds another value for, and name up to 10 of each, sorted
+	Missing        int64
+	Mismatched     int64
+	MissingKeys    []string
+	MismatchedKeys []string
+}
+
+// OK reports whether the migration was verified with every key found
+func (r *MigrationReport) OK() bool {
+	return r.Verified && r.Missing == 0 && r.Mismatched == 0
+}
+
+// keyDigest digests a key and its value for the migration checksums
+func keyDigest(key string, value []byte) uint64 {
+	h := fnv.New64a()
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(key)))
+	h.Write(length[:])
+	h.Write([]byte(key))
+	h.Write(value)
+	return h.Sum64()
+}
+
+// Migrate copies every key of the ring reached from source, or those with
+// the Prefix of opts, to the ring reached from dest, placing them with the
+// destination's Placement, so the rings may hash keys differently. Keys are
+// streamed from the source's nodes straight into batches for the
+// destination's, at the Rate of opts. With verify, the destination is read
+// back afterwards and compared with what was copied. Keys written to the
+// source during the migration may or may not be copied, and the copy is
+// only verified against what was read.
+func (c *Crawler) Migrate(ctx context.Context, source, dest string, opts DatasetOptions, verify bool) (*MigrationReport, error) {
+	copyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The destination's batches take the source's pages as they arrive
+	items := make(chan *pb.KeyValue, opts.withDefaults().BatchSize)
+	digests := make(map[string]uint64)
+	var exportErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(items)
+
+		exportOpts := opts
+		exportOpts.Progress = nil
+		_, exportErr = c.exportItems(copyCtx, source, exportOpts, func(page []*pb.KeyValue) error {
+			for _, item := range page {
+				select {
+				case items <- item:
+				case <-copyCtx.Done():
+					return copyCtx.Err()
+				}
+			}
+			return nil
+		})
+		if exportErr != nil {
+			cancel()
+		}
+	}()
+
+	copied, err := c.importItems(copyCtx, dest, opts, func() (*pb.KeyValue, error) {
+		item, ok := <-items
+		if !ok {
+			return nil, io.EOF
+		}
+		digests[item.Key] = keyDigest(item.Key, item.Value)
+		return item, nil
+	})
+	cancel()
+	wg.Wait()
+	// A failed side cancels the other
+	if exportErr != nil && (err == nil || errors.Is(err, context.Canceled)) {
+		return nil, fmt.Errorf("reading %s failed: %w", source, exportErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing %s failed: %w", dest, err)
+	}
+
+	report := &MigrationReport{Copied: copied, Keys: int64(len(digests))}
+	for _, digest := range digests {
+		report.Checksum += digest
+	}
+	if !verify {
+		return report, nil
+	}
+	if err := c.verifyMigration(ctx, dest, opts, digests, report); err != nil {
+		return report, fmt.Errorf("verifying %s failed: %w", dest, err)
+	}
+	return report, nil
+}
+
+// verifyMigration reads the destination back and compares it with the
+// digests of the keys copied
+func (c *Crawler) verifyMigration(ctx context.Context, dest string, opts DatasetOptions, digests map[string]uint64, report *MigrationReport) error {
+	opts.Progress = nil
+	found := make(map[string]bool, len(digests))
+	var mismatched []string
+	_, err := c.exportItems(ctx, dest, opts, func(page []*pb.KeyValue) error {
+		for _, item := range page {
+			want, copied := digests[item.Key]
+			if !copied || found[item.Key] {
+				continue
+			}
+			found[item.Key] = true
+			if digest := keyDigest(item.Key, item.Value); digest == want {
+				report.Found++
+				report.FoundChecksum += digest
+			} else {
+				mismatched = append(mismatched, item.Key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for key := range digests {
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+	report.Verified = true
+	report.Missing, report.MissingKeys = int64(len(missing)), firstSorted(missing)
+	report.Mismatched, report.MismatchedKeys = int64(len(mismatched)), firstSorted(mismatched)
+	return nil
+}
+
+// firstSorted returns the first maxReportedKeys of keys in order
+func firstSorted(keys []string) []string {
+	sort.Strings(keys)
+	return keys[:min(len(keys), maxReportedKeys)]
+}
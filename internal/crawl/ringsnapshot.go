@@ -0,0 +1,259 @@
+package crawl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+)
+
+// piecePollInterval is how often a piece still being written is asked for
+// again
+const piecePollInterval = 100 * time.Millisecond
+
+// ManifestPiece is a node's piece in a ring snapshot manifest
+type ManifestPiece struct {
+	Node    string `json:"node"`
+	Address string `json:"address"`
+	// RangeStart is the exclusive start of the key range in the piece, up
+	// to Node; empty if the node owned nothing
+	RangeStart string    `json:"range_start,omitempty"`
+	Time       time.Time `json:"time"`
+	// File is the path of the piece on the node's host
+	File     string `json:"file"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	Checksum string `json:"checksum"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RingManifest ties the pieces of a ring snapshot together
+type RingManifest struct {
+	ID      uint64          `json:"id"`
+	Started time.Time       `json:"started"`
+	Pieces  []ManifestPiece `json:"pieces"`
+	// Missing lists the nodes that took no piece, such as nodes without a
+	// snapshot directory
+	Missing []string `json:"missing,omitempty"`
+	// Complete is set if every piece was written and their ranges cover
+	// the ring once
+	Complete bool `json:"complete"`
+}
+
+// RingSnapshot starts a ring snapshot at start, then walks the ring and
+// collects every node's piece of it, waiting for those still being written
+func (c *Crawler) RingSnapshot(ctx context.Context, start string) (*RingManifest, error) {
+	first, err := c.ringSnapshotPiece(ctx, start, &pb.GetRingSnapshotPieceRequest{Start: true})
+	if err != nil {
+		return nil, err
+	}
+	if !first.Found {
+		return nil, fmt.Errorf("node %s took no piece of the snapshot it started; is its snapshot directory set?", start)
+	}
+	manifest := &RingManifest{ID: first.Piece.Id, Started: time.Unix(0, int64(first.Piece.Id)).UTC()}
+
+	err = c.Walk(ctx, start, func(address string) error {
+		for {
+			resp, err := c.ringSnapshotPiece(ctx, address, &pb.GetRingSnapshotPieceRequest{Id: manifest.ID})
+			if err != nil {
+				return err
+			}
+			if !resp.Found {
+				manifest.Missing = append(manifest.Missing, address)
+				return nil
+			}
+			piece, err := chord.RingSnapshotPieceFromProto(resp.Piece)
+			if err != nil {
+				return fmt.Errorf("invalid piece from %s: %w", address, err)
+			}
+			if !piece.Pending {
+				manifest.Pieces = append(manifest.Pieces, toManifestPiece(piece))
+				return nil
+			}
+			select {
+			case <-time.After(piecePollInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(manifest.Pieces, func(i, j int) bool { return manifest.Pieces[i].Node < manifest.Pieces[j].Node })
+	manifest.Complete = len(manifest.Missing) == 0 && piecesCoverRing(manifest.Pieces)
+	return manifest, nil
+}
+
+// toManifestPiece converts a piece reported by a node
+func toManifestPiece(piece chord.RingSnapshotPiece) ManifestPiece {
+	result := ManifestPiece{
+		Node:     piece.Node.ID.String(),
+		Address:  piece.Node.Address,
+		Time:     piece.Time.UTC(),
+		File:     piece.File,
+		Entries:  piece.Entries,
+		Bytes:    piece.Bytes,
+		Checksum: piece.Checksum,
+		Error:    piece.Err,
+	}
+	if piece.RangeStart != nil {
+		result.RangeStart = piece.RangeStart.String()
+	}
+	return result
+}
+
+// piecesCoverRing reports whether pieces sorted by node were all written
+// and each range starts where the previous piece's ends, around the ring
+func piecesCoverRing(pieces []ManifestPiece) bool {
+	if len(pieces) == 0 {
+		return false
+	}
+	for i, piece := range pieces {
+		previous := pieces[(i+len(pieces)-1)%len(pieces)]
+		if piece.Error != "" || piece.RangeStart != previous.Node {
+			return false
+		}
+	}
+	return true
+}
+
+// ringSnapshotPiece sends a GetRingSnapshotPiece request to the node at
+// address
+func (c *Crawler) ringSnapshotPiece(ctx context.Context, address string, req *pb.GetRingSnapshotPieceRequest) (*pb.GetRingSnapshotPieceResponse, error) {
+	client, err := c.client(address)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	resp, err := client.GetRingSnapshotPiece(rpcCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("get ring snapshot piece from %s failed: %w", address, err)
+	}
+	return resp, nil
+}
+
+// SaveManifest writes a ring snapshot manifest to path as indented JSON
+func SaveManifest(path string, manifest *RingManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadManifest reads a manifest written by SaveManifest
+func LoadManifest(path string) (*RingManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest RingManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// PiecePath returns where the piece is read from on restore: its File if
+// that exists on this host, or else a file of the same name next to the
+// manifest at manifestPath, for pieces gathered from several hosts
+func PiecePath(manifestPath string, piece ManifestPiece) string {
+	if _, err := os.Stat(piece.File); err == nil {
+		return piece.File
+	}
+	return filepath.Join(filepath.Dir(manifestPath), filepath.Base(piece.File))
+}
+
+// RestoreRingSnapshot writes the live entries of every piece of the
+// manifest at manifestPath to the ring reached from start, as Import does,
+// after checking each piece's checksum. The ring may differ from the one
+// snapshotted. Versions, TTLs and tombstones are not carried over, and
+// pieces holding encrypted values are refused.
+func (c *Crawler) RestoreRingSnapshot(ctx context.Context, start, manifestPath string, opts DatasetOptions) (DatasetProgress, error) {
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return DatasetProgress{}, err
+	}
+	if !manifest.Complete {
+		return DatasetProgress{}, fmt.Errorf("ring snapshot %d is incomplete", manifest.ID)
+	}
+
+	// Pieces are read one at a time, as the keys before are written
+	pieces := manifest.Pieces
+	var items []*pb.KeyValue
+	return c.importItems(ctx, start, opts, func() (*pb.KeyValue, error) {
+		for len(items) == 0 {
+			if len(pieces) == 0 {
+				return nil, io.EOF
+			}
+			var err error
+			if items, err = readPiece(PiecePath(manifestPath, pieces[0]), pieces[0].Checksum); err != nil {
+				return nil, err
+			}
+			pieces = pieces[1:]
+		}
+		item := items[0]
+		items = items[1:]
+		return item, nil
+	})
+}
+
+// readPiece checks the SHA-256 of the piece at path and returns its live
+// entries, leaving out the nodes' internal keys
+func readPiece(path, checksum string) ([]*pb.KeyValue, error) {
+	if err := checkPiece(path, checksum); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var items []*pb.KeyValue
+	now := time.Now()
+	info, err := chord.ReadSnapshotEntries(file, func(key string, e chord.Entry) {
+		if e.Live(now) && !strings.HasPrefix(key, "\x00") {
+			items = append(items, &pb.KeyValue{Key: key, Value: e.Value})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if info.Sealed {
+		return nil, fmt.Errorf("%s holds encrypted values", path)
+	}
+	return items, nil
+}
+
+// checkPiece checks the SHA-256 of the file at path
+func checkPiece(path, checksum string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if hex.EncodeToString(digest.Sum(nil)) != checksum {
+		return fmt.Errorf("%s does not match its checksum", path)
+	}
+	return nil
+}
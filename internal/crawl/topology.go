@@ -0,0 +1,220 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/internal/chord"
+	"chord-dht/pkg/hash"
+)
+
+// IssueKind classifies an inconsistency found in a ring's routing state
+type IssueKind string
+
+const (
+	// IssueUnreachable is a node that could not be queried; the walk stops
+	// there
+	IssueUnreachable IssueKind = "unreachable"
+	// IssueNoSuccessor is a node without a successor; the walk stops there
+	IssueNoSuccessor IssueKind = "no-successor"
+	// IssueLoop is a walk that ran into a cycle not passing through the start
+	// node
+	IssueLoop IssueKind = "loop"
+	// IssueNonMutual is a node whose successor does not name it as its
+	// predecessor
+	IssueNonMutual IssueKind = "non-mutual"
+	// IssueSkippedNode is a successor pointer that jumps over a node found on
+	// the walk
+	IssueSkippedNode IssueKind = "skipped-node"
+	// IssueUnknownFinger is a finger pointing at a node not found on the walk
+	IssueUnknownFinger IssueKind = "unknown-finger"
+	// IssueWrongFinger is a finger that is not the successor of its start
+	IssueWrongFinger IssueKind = "wrong-finger"
+)
+
+// Issue is an inconsistency found at a node
+type Issue struct {
+	Kind   IssueKind
+	Node   *chord.NodeInfo
+	Detail string
+}
+
+// NodeState is a node's routing state as reported by the node itself
+type NodeState struct {
+	Node        *chord.NodeInfo
+	Predecessor *chord.NodeInfo
+	Successor   *chord.NodeInfo
+	// Successors is the successor list, closest first
+	Successors []*chord.NodeInfo
+	// Fingers holds the finger table, entry i pointing at the successor of
+	// node + 2^i, nil where unset
+	Fingers []*chord.NodeInfo
+}
+
+// Topology is the routing state of every node reached by a ring walk
+type Topology struct {
+	// Nodes holds the nodes in walk order, starting at the start node
+	Nodes  []*NodeState
+	Issues []Issue
+}
+
+// Topology walks the ring from start, collects every node's routing state
+// and cross-checks successors, predecessors and fingers against the ring the
+// walk found. A broken walk is reported as an issue, not an error; an error
+// is only returned if ctx is done.
+func (c *Crawler) Topology(ctx context.Context, start string) (*Topology, error) {
+	topology := &Topology{}
+	visited := make(map[string]bool)
+	address := start
+	for {
+		if visited[address] {
+			if address != topology.Nodes[0].Node.Address {
+				last := topology.Nodes[len(topology.Nodes)-1]
+				topology.addIssue(IssueLoop, last.Node, "successor %s leads back into the walk instead of to %s",
+					address, start)
+			}
+			break
+		}
+		if len(topology.Nodes) == c.MaxNodes {
+			topology.addIssue(IssueLoop, topology.Nodes[0].Node, "walk did not close after %d nodes", c.MaxNodes)
+			break
+		}
+		visited[address] = true
+
+		table, err := c.routingTable(ctx, address)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			topology.addIssue(IssueUnreachable, &chord.NodeInfo{Address: address}, "%v", err)
+			break
+		}
+		state := nodeStateOf(table)
+		topology.Nodes = append(topology.Nodes, state)
+
+		if state.Successor == nil {
+			topology.addIssue(IssueNoSuccessor, state.Node, "node has no successor")
+			break
+		}
+		address = state.Successor.Address
+	}
+
+	topology.check()
+	return topology, nil
+}
+
+// addIssue records an issue at node
+func (t *Topology) addIssue(kind IssueKind, node *chord.NodeInfo, format string, args ...interface{}) {
+	t.Issues = append(t.Issues, Issue{Kind: kind, Node: node, Detail: fmt.Sprintf(format, args...)})
+}
+
+// check cross-checks every node's pointers against the walked ring
+func (t *Topology) check() {
+	byAddress := make(map[string]*NodeState, len(t.Nodes))
+	sorted := make([]*chord.NodeInfo, 0, len(t.Nodes))
+	for _, state := range t.Nodes {
+		byAddress[state.Node.Address] = state
+		sorted = append(sorted, state.Node)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID.Less(sorted[j].ID)
+	})
+
+	for _, state := range t.Nodes {
+		if succ := state.Successor; succ != nil {
+			if next, ok := byAddress[succ.Address]; ok {
+				if next.Predecessor == nil || next.Predecessor.Address != state.Node.Address {
+					t.addIssue(IssueNonMutual, state.Node, "successor %s has predecessor %s",
+						succ.Address, describe(next.Predecessor))
+				}
+			}
+			if expected := successorOf(sorted, state.Node.ID.AddPowerOfTwo(0)); expected != nil && expected.Address != succ.Address {
+				t.addIssue(IssueSkippedNode, state.Node, "successor is %s but %s lies in between",
+					succ.Address, expected.Address)
+			}
+		}
+
+		var unknown, wrong int
+		var firstWrong string
+		for i, finger := range state.Fingers {
+			if finger == nil {
+				continue
+			}
+			if _, ok := byAddress[finger.Address]; !ok {
+				unknown++
+				continue
+			}
+			expected := successorOf(sorted, hash.FingerStart(state.Node.ID, i+1))
+			if expected != nil && expected.Address != finger.Address {
+				if wrong == 0 {
+					firstWrong = fmt.Sprintf("finger %d is %s, expected %s", i, finger.Address, expected.Address)
+				}
+				wrong++
+			}
+		}
+		if unknown > 0 {
+			t.addIssue(IssueUnknownFinger, state.Node, "%d of %d fingers point at nodes not on the ring",
+				unknown, len(state.Fingers))
+		}
+		if wrong > 0 {
+			t.addIssue(IssueWrongFinger, state.Node, "%d of %d fingers are stale; %s",
+				wrong, len(state.Fingers), firstWrong)
+		}
+	}
+}
+
+// successorOf returns the first node in sorted at or after key, wrapping
+// around the ring
+func successorOf(sorted []*chord.NodeInfo, key *hash.Hash) *chord.NodeInfo {
+	if len(sorted) == 0 {
+		return nil
+	}
+	i := sort.Search(len(sorted), func(i int) bool {
+		return !sorted[i].ID.Less(key)
+	})
+	return sorted[i%len(sorted)]
+}
+
+// describe formats an optional node for issue details
+func describe(node *chord.NodeInfo) string {
+	if node == nil {
+		return "none"
+	}
+	return node.Address
+}
+
+// nodeStateOf converts a node's routing table into a NodeState
+func nodeStateOf(table *chord.RoutingTable) *NodeState {
+	state := &NodeState{
+		Node:        table.Self,
+		Predecessor: table.Predecessor,
+		Successors:  table.Successors,
+	}
+	if len(table.Successors) > 0 {
+		state.Successor = table.Successors[0]
+	}
+	for _, finger := range table.Fingers {
+		state.Fingers = append(state.Fingers, finger.Node)
+	}
+	return state
+}
+
+// nodeFromProto converts a protobuf node
+func nodeFromProto(node *pb.Node) (*chord.NodeInfo, error) {
+	if node == nil {
+		return nil, fmt.Errorf("missing node")
+	}
+	id, err := hash.NewHashFromHex(node.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node ID %q: %w", node.Id, err)
+	}
+	return &chord.NodeInfo{
+		ID:      id,
+		Address: node.Address,
+		Zone:    node.Zone,
+		Weight:  node.Weight,
+		Version: node.ProtocolVersion,
+	}, nil
+}
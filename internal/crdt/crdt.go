@@ -0,0 +1,220 @@
+// Package crdt implements state-based conflict-free replicated data types:
+// a counter that can be incremented and decremented (a PN-counter) and a
+// set that elements can be added to and removed from (an observed-remove
+// set). Copies of a value updated independently always merge into the same
+// result whatever the order they are merged in, so replicas that diverged
+// need no conflict handling.
+package crdt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Types of Value
+const (
+	TypeCounter = "counter"
+	TypeSet     = "set"
+)
+
+// ErrTypeMismatch is returned when merging or updating a value of another
+// type
+var ErrTypeMismatch = errors.New("data type mismatch")
+
+// Counter is a PN-counter: the increments and decrements made by each actor
+// are counted apart, and merging keeps the larger count of every actor
+type Counter struct {
+	P map[string]uint64 `json:"p,omitempty"`
+	N map[string]uint64 `json:"n,omitempty"`
+}
+
+// Add adds delta, which may be negative, on behalf of actor
+func (c *Counter) Add(actor string, delta int64) {
+	switch {
+	case delta > 0:
+		if c.P == nil {
+			c.P = make(map[string]uint64)
+		}
+		c.P[actor] += uint64(delta)
+	case delta < 0:
+		if c.N == nil {
+			c.N = make(map[string]uint64)
+		}
+		c.N[actor] += uint64(-delta)
+	}
+}
+
+// Value returns the sum of all increments and decrements
+func (c *Counter) Value() int64 {
+	var value int64
+	for _, p := range c.P {
+		value += int64(p)
+	}
+	for _, n := range c.N {
+		value -= int64(n)
+	}
+	return value
+}
+
+// Merge merges other into c
+func (c *Counter) Merge(other *Counter) {
+	c.P = mergeMax(c.P, other.P)
+	c.N = mergeMax(c.N, other.N)
+}
+
+// mergeMax merges counts, keeping the larger of each
+func mergeMax(into, from map[string]uint64) map[string]uint64 {
+	if len(from) > 0 && into == nil {
+		into = make(map[string]uint64, len(from))
+	}
+	for actor, count := range from {
+		into[actor] = max(into[actor], count)
+	}
+	return into
+}
+
+// Set is an observed-remove set. Every add tags the element with a unique
+// tag; a remove tombstones the tags it observed, so an add concurrent with
+// a remove wins. Tombstones are kept for good.
+type Set struct {
+	// Adds maps elements to the tags of their adds
+	Adds map[string][]string `json:"adds,omitempty"`
+	// Removed holds the tags of removed adds
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Add adds element with a tag unique to this add
+func (s *Set) Add(element, tag string) {
+	if s.Adds == nil {
+		s.Adds = make(map[string][]string)
+	}
+	if !slices.Contains(s.Adds[element], tag) {
+		s.Adds[element] = append(s.Adds[element], tag)
+		slices.Sort(s.Adds[element])
+	}
+}
+
+// Remove removes element by tombstoning the tags of the adds of it seen so
+// far, and reports whether it was in the set
+func (s *Set) Remove(element string) bool {
+	live := s.liveTags(element)
+	for _, tag := range live {
+		s.Removed = insertSorted(s.Removed, tag)
+	}
+	return len(live) > 0
+}
+
+// Contains reports whether element is in the set
+func (s *Set) Contains(element string) bool {
+	return len(s.liveTags(element)) > 0
+}
+
+// Elements returns the elements in the set, sorted
+func (s *Set) Elements() []string {
+	var elements []string
+	for element := range s.Adds {
+		if s.Contains(element) {
+			elements = append(elements, element)
+		}
+	}
+	slices.Sort(elements)
+	return elements
+}
+
+// Merge merges other into s
+func (s *Set) Merge(other *Set) {
+	for element, tags := range other.Adds {
+		for _, tag := range tags {
+			s.Add(element, tag)
+		}
+	}
+	for _, tag := range other.Removed {
+		s.Removed = insertSorted(s.Removed, tag)
+	}
+}
+
+// liveTags returns the tags of the adds of element not removed
+func (s *Set) liveTags(element string) []string {
+	var live []string
+	for _, tag := range s.Adds[element] {
+		if _, removed := slices.BinarySearch(s.Removed, tag); !removed {
+			live = append(live, tag)
+		}
+	}
+	return live
+}
+
+// insertSorted inserts tag into the sorted tags unless present
+func insertSorted(tags []string, tag string) []string {
+	i, found := slices.BinarySearch(tags, tag)
+	if found {
+		return tags
+	}
+	return slices.Insert(tags, i, tag)
+}
+
+// Value is a data type as stored in the DHT
+type Value struct {
+	Type    string   `json:"type"`
+	Counter *Counter `json:"counter,omitempty"`
+	Set     *Set     `json:"set,omitempty"`
+}
+
+// New returns an empty value of a type
+func New(typ string) (*Value, error) {
+	switch typ {
+	case TypeCounter:
+		return &Value{Type: typ, Counter: &Counter{}}, nil
+	case TypeSet:
+		return &Value{Type: typ, Set: &Set{}}, nil
+	}
+	return nil, fmt.Errorf("unknown data type %q", typ)
+}
+
+// Decode decodes a value encoded with Encode
+func Decode(data []byte) (*Value, error) {
+	var v Value
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid data type value: %w", err)
+	}
+	empty, err := New(v.Type)
+	if err != nil {
+		return nil, err
+	}
+	if v.Counter == nil {
+		v.Counter = empty.Counter
+	}
+	if v.Set == nil {
+		v.Set = empty.Set
+	}
+	return &v, nil
+}
+
+// Encode encodes the value. Equal states encode to the same bytes.
+func (v *Value) Encode() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Check returns ErrTypeMismatch unless the value is of type typ
+func (v *Value) Check(typ string) error {
+	if v.Type != typ {
+		return fmt.Errorf("%w: %s is not a %s", ErrTypeMismatch, v.Type, typ)
+	}
+	return nil
+}
+
+// Merge merges other, which must be of the same type, into v
+func (v *Value) Merge(other *Value) error {
+	if err := v.Check(other.Type); err != nil {
+		return err
+	}
+	switch v.Type {
+	case TypeCounter:
+		v.Counter.Merge(other.Counter)
+	case TypeSet:
+		v.Set.Merge(other.Set)
+	}
+	return nil
+}
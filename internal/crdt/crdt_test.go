@@ -0,0 +1,99 @@
+package crdt
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	var a, b Counter
+	a.Add("a", 5)
+	a.Add("a", -2)
+	b.Add("b", 4)
+	b.Add("b", -1)
+	if got := a.Value(); got != 3 {
+		t.Errorf("Expected 3, got %d", got)
+	}
+
+	a.Merge(&b)
+	if got := a.Value(); got != 6 {
+		t.Errorf("Expected 6 after merging, got %d", got)
+	}
+	// Merging is idempotent
+	a.Merge(&b)
+	if got := a.Value(); got != 6 {
+		t.Errorf("Expected 6 after merging again, got %d", got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	var s Set
+	s.Add("x", "1")
+	s.Add("y", "2")
+	if !s.Remove("x") {
+		t.Error("Expected x to be removed")
+	}
+	if s.Remove("z") {
+		t.Error("Expected z not to be in the set")
+	}
+	if got := s.Elements(); !slices.Equal(got, []string{"y"}) {
+		t.Errorf("Expected [y], got %v", got)
+	}
+
+	// An add concurrent with a remove wins
+	var other Set
+	other.Add("y", "3")
+	s.Remove("y")
+	s.Merge(&other)
+	if !s.Contains("y") {
+		t.Error("Expected the concurrent add of y to win")
+	}
+	// A remove that observed the add wins
+	other.Merge(&s)
+	other.Remove("y")
+	s.Merge(&other)
+	if s.Contains("y") {
+		t.Error("Expected y to be removed after observing every add")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, _ := New(TypeSet)
+	a.Set.Add("x", "1")
+	a.Set.Add("y", "2")
+	b, _ := New(TypeSet)
+	b.Set.Add("y", "3")
+	b.Set.Merge(a.Set)
+	b.Set.Remove("x")
+	b.Set.Add("z", "4")
+
+	ab, _ := New(TypeSet)
+	ab.Merge(a)
+	ab.Merge(b)
+	ba, _ := New(TypeSet)
+	ba.Merge(b)
+	ba.Merge(a)
+	encodedAB, _ := ab.Encode()
+	encodedBA, _ := ba.Encode()
+	if !bytes.Equal(encodedAB, encodedBA) {
+		t.Errorf("Expected merges in either order to encode equally, got %s and %s", encodedAB, encodedBA)
+	}
+
+	decoded, err := Decode(encodedAB)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got := decoded.Set.Elements(); !slices.Equal(got, []string{"y", "z"}) {
+		t.Errorf("Expected [y z], got %v", got)
+	}
+
+	counter, _ := New(TypeCounter)
+	if err := counter.Merge(a); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+	if _, err := Decode([]byte(`{"type":"map"}`)); err == nil {
+		t.Error("Expected an unknown type to fail to decode")
+	}
+}
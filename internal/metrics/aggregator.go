@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot is the state of one node pushed to an Aggregator
+type Snapshot struct {
+	NodeID  string    `json:"node_id"`
+	Address string    `json:"address"`
+	Time    time.Time `json:"time"`
+	// Messages and Lookups are cumulative, as in the node's CSV
+	Messages          int64     `json:"messages"`
+	Lookups           int64     `json:"lookups"`
+	AvgLookupMs       float64   `json:"avg_lookup_ms"`
+	AvgHops           float64   `json:"avg_hops"`
+	StoredKeys        int       `json:"stored_keys"`
+	ActiveConnections int       `json:"active_connections"`
+	UptimeSeconds     float64   `json:"uptime_seconds"`
+	Bandwidth         Bandwidth `json:"bandwidth"`
+	// FingerAccuracy is the fraction of correct fingers found by the
+	// node's last finger table check, nil if it does not check
+	FingerAccuracy *float64 `json:"finger_accuracy,omitempty"`
+}
+
+// RingStats is the ring-wide view an Aggregator keeps from the latest
+// snapshot of every live node
+type RingStats struct {
+	Nodes    int   `json:"nodes"`
+	Messages int64 `json:"messages"`
+	Lookups  int64 `json:"lookups"`
+	// AvgLookupMs and AvgHops are the nodes' averages weighted by their
+	// lookups
+	AvgLookupMs float64 `json:"avg_lookup_ms"`
+	AvgHops     float64 `json:"avg_hops"`
+	// FingerAccuracy is the average of the nodes checking their fingers,
+	// nil if none does
+	FingerAccuracy      *float64   `json:"finger_accuracy,omitempty"`
+	StoredKeys          int        `json:"stored_keys"`
+	ActiveConnections   int        `json:"active_connections"`
+	Bandwidth           Bandwidth  `json:"bandwidth"`
+	MaintenanceOverhead float64    `json:"maintenance_overhead"`
+	Snapshots           []Snapshot `json:"snapshots"`
+}
+
+// DefaultSnapshotTTL is how long an Aggregator counts a node that stopped
+// pushing, three missed pushes at the default interval
+const DefaultSnapshotTTL = 30 * time.Second
+
+// Aggregator maintains live ring-wide stats from the snapshots nodes push
+// to it. It serves POST /push for the nodes, GET /ring as JSON and GET
+// /metrics for Prometheus.
+type Aggregator struct {
+	mu sync.Mutex
+	// ttl is how long a node's last snapshot counts
+	ttl       time.Duration
+	snapshots map[string]Snapshot
+	mux       *http.ServeMux
+}
+
+// NewAggregator creates an aggregator forgetting nodes that have not pushed
+// for ttl (DefaultSnapshotTTL if 0)
+func NewAggregator(ttl time.Duration) *Aggregator {
+	if ttl <= 0 {
+		ttl = DefaultSnapshotTTL
+	}
+	a := &Aggregator{ttl: ttl, snapshots: make(map[string]Snapshot)}
+	a.mux = http.NewServeMux()
+	a.mux.HandleFunc("POST /push", a.servePush)
+	a.mux.HandleFunc("GET /ring", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a.Ring())
+	})
+	a.mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := a.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	return a
+}
+
+// Update records the latest snapshot of a node, by address
+func (a *Aggregator) Update(s Snapshot) {
+	if s.Time.IsZero() {
+		s.Time = time.Now()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if old, ok := a.snapshots[s.Address]; ok && old.Time.After(s.Time) {
+		return
+	}
+	a.snapshots[s.Address] = s
+}
+
+// Ring returns the ring-wide stats of the nodes that pushed within the TTL,
+// with their snapshots in address order
+func (a *Aggregator) Ring() RingStats {
+	a.mu.Lock()
+	cutoff := time.Now().Add(-a.ttl)
+	ring := RingStats{Bandwidth: make(Bandwidth, len(Categories)), Snapshots: []Snapshot{}}
+	for address, s := range a.snapshots {
+		if s.Time.Before(cutoff) {
+			delete(a.snapshots, address)
+			continue
+		}
+		ring.Snapshots = append(ring.Snapshots, s)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(ring.Snapshots, func(i, j int) bool { return ring.Snapshots[i].Address < ring.Snapshots[j].Address })
+	for _, category := range Categories {
+		ring.Bandwidth[category] = Traffic{}
+	}
+	var accuracy float64
+	checking := 0
+	for _, s := range ring.Snapshots {
+		if s.FingerAccuracy != nil {
+			accuracy += *s.FingerAccuracy
+			checking++
+		}
+		ring.Nodes++
+		ring.Messages += s.Messages
+		ring.Lookups += s.Lookups
+		ring.AvgLookupMs += s.AvgLookupMs * float64(s.Lookups)
+		ring.AvgHops += s.AvgHops * float64(s.Lookups)
+		ring.StoredKeys += s.StoredKeys
+		ring.ActiveConnections += s.ActiveConnections
+		ring.Bandwidth.Add(s.Bandwidth)
+	}
+	if ring.Lookups > 0 {
+		ring.AvgLookupMs /= float64(ring.Lookups)
+		ring.AvgHops /= float64(ring.Lookups)
+	}
+	if checking > 0 {
+		accuracy /= float64(checking)
+		ring.FingerAccuracy = &accuracy
+	}
+	ring.MaintenanceOverhead = ring.Bandwidth.MaintenanceOverhead()
+	return ring
+}
+
+// servePush records a snapshot posted as JSON
+func (a *Aggregator) servePush(w http.ResponseWriter, r *http.Request) {
+	var s Snapshot
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&s); err != nil {
+		http.Error(w, "invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.Address == "" {
+		http.Error(w, "snapshot without address", http.StatusBadRequest)
+		return
+	}
+	a.Update(s)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeHTTP serves the aggregator's endpoints
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+// WritePrometheus writes the ring-wide stats in the Prometheus text
+// exposition format, followed by the per-node values labelled by address
+func (a *Aggregator) WritePrometheus(w io.Writer) error {
+	ring := a.Ring()
+
+	b := bufio.NewWriter(w)
+	gauge := func(name, help string, value any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	counter := func(name, help string, value any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %v\n", name, help, name, name, value)
+	}
+	gauge("chord_ring_nodes", "Nodes pushing snapshots to the aggregator.", ring.Nodes)
+	counter("chord_ring_messages_total", "Messages handled by the ring.", ring.Messages)
+	counter("chord_ring_lookups_total", "Lookups performed by the ring.", ring.Lookups)
+	gauge("chord_ring_lookup_latency_avg_ms", "Average lookup latency, weighted by each node's lookups.", ring.AvgLookupMs)
+	gauge("chord_ring_lookup_hops_avg", "Average RPC hops per lookup, weighted by each node's lookups.", ring.AvgHops)
+	gauge("chord_ring_stored_keys", "Live keys stored in the ring, replicas included.", ring.StoredKeys)
+	gauge("chord_ring_active_connections", "Connections open from and to the ring's nodes.", ring.ActiveConnections)
+	gauge("chord_ring_maintenance_overhead", "Fraction of the ring's bytes spent on maintenance.", ring.MaintenanceOverhead)
+	if ring.FingerAccuracy != nil {
+		gauge("chord_ring_finger_accuracy", "Average fraction of correct fingers of the nodes checking them.", *ring.FingerAccuracy)
+	}
+	fmt.Fprintf(b, "# HELP chord_ring_bytes_sent_total Bytes sent by the ring's nodes by category.\n# TYPE chord_ring_bytes_sent_total counter\n")
+	for _, category := range Categories {
+		fmt.Fprintf(b, "chord_ring_bytes_sent_total{category=\"%s\"} %d\n", category, ring.Bandwidth[category].Sent)
+	}
+	fmt.Fprintf(b, "# HELP chord_ring_bytes_received_total Bytes received by the ring's nodes by category.\n# TYPE chord_ring_bytes_received_total counter\n")
+	for _, category := range Categories {
+		fmt.Fprintf(b, "chord_ring_bytes_received_total{category=\"%s\"} %d\n", category, ring.Bandwidth[category].Received)
+	}
+
+	perNode := func(name, kind, help string, value func(Snapshot) any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range ring.Snapshots {
+			fmt.Fprintf(b, "%s{node=\"%s\"} %v\n", name, labelEscaper.Replace(s.Address), value(s))
+		}
+	}
+	perNode("chord_node_messages_total", "counter", "Messages handled per node.",
+		func(s Snapshot) any { return s.Messages })
+	perNode("chord_node_lookups_total", "counter", "Lookups performed per node.",
+		func(s Snapshot) any { return s.Lookups })
+	perNode("chord_node_lookup_latency_avg_ms", "gauge", "Average lookup latency per node.",
+		func(s Snapshot) any { return s.AvgLookupMs })
+	perNode("chord_node_stored_keys", "gauge", "Live keys stored per node.",
+		func(s Snapshot) any { return s.StoredKeys })
+	fmt.Fprintf(b, "# HELP chord_node_finger_accuracy Fraction of correct fingers per node, at its last check.\n# TYPE chord_node_finger_accuracy gauge\n")
+	for _, s := range ring.Snapshots {
+		if s.FingerAccuracy != nil {
+			fmt.Fprintf(b, "chord_node_finger_accuracy{node=\"%s\"} %v\n", labelEscaper.Replace(s.Address), *s.FingerAccuracy)
+		}
+	}
+	perNode("chord_node_uptime_seconds", "gauge", "Time since each node started.",
+		func(s Snapshot) any { return s.UptimeSeconds })
+	perNode("chord_node_last_push_timestamp_seconds", "gauge", "Unix time of each node's last snapshot.",
+		func(s Snapshot) any { return float64(s.Time.UnixMilli()) / 1000 })
+
+	return b.Flush()
+}
+
+// PushSnapshot posts a node's snapshot as JSON to the /push endpoint of the
+// aggregator at url
+func PushSnapshot(ctx context.Context, client *http.Client, url string, s Snapshot) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/push", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("aggregator refused snapshot: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
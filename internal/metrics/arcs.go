@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"chord-dht/pkg/hash"
+)
+
+// ArcCount is the number of equal arcs the keyspace is split into for the
+// lookup latency heatmap. Arc i holds the keys whose ID starts with hex
+// digit i.
+const ArcCount = 16
+
+// arcShift selects the leading hex digit of a 160-bit ID
+const arcShift = 160 - 4
+
+// LatencyBuckets are the upper bounds of the heatmap's latency buckets.
+// Slower lookups fall into a final overflow bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// ArcLatency is the latency histogram of the lookups targeting one arc
+type ArcLatency struct {
+	// Counts has one entry per latency bucket plus the overflow bucket
+	Counts  []int64
+	Lookups int64
+	Errors  int64
+	Total   time.Duration
+}
+
+// Average returns the mean latency of the arc's successful lookups
+func (a ArcLatency) Average() time.Duration {
+	if a.Lookups == 0 {
+		return 0
+	}
+	return a.Total / time.Duration(a.Lookups)
+}
+
+// ArcHeatmap is lookup latency by target arc and latency bucket
+type ArcHeatmap [ArcCount]ArcLatency
+
+// ArcOf returns the arc of the keyspace key falls in
+func ArcOf(key *hash.Hash) int {
+	return int(new(big.Int).Rsh(key.BigInt(), arcShift).Int64()) % ArcCount
+}
+
+// ArcLabel names an arc by the leading hex digit of its keys
+func ArcLabel(arc int) string {
+	return fmt.Sprintf("%x", arc)
+}
+
+// RecordArcLookup records the latency of a lookup for key in the heatmap
+func (m *Metrics) RecordArcLookup(key *hash.Hash, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a := &m.arcs[ArcOf(key)]
+	if err != nil {
+		a.Errors++
+		return
+	}
+	if a.Counts == nil {
+		a.Counts = make([]int64, len(LatencyBuckets)+1)
+	}
+	bucket := len(LatencyBuckets)
+	for i, bound := range LatencyBuckets {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	a.Counts[bucket]++
+	a.Lookups++
+	a.Total += latency
+}
+
+// ArcHeatmap returns a copy of the lookup latency heatmap
+func (m *Metrics) ArcHeatmap() ArcHeatmap {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	heatmap := m.arcs
+	for i := range heatmap {
+		heatmap[i].Counts = append([]int64(nil), m.arcs[i].Counts...)
+	}
+	return heatmap
+}
+
+// Merge adds the lookups of other to the heatmap
+func (h *ArcHeatmap) Merge(other ArcHeatmap) {
+	for i := range h {
+		a, o := &h[i], other[i]
+		if a.Counts == nil && o.Counts != nil {
+			a.Counts = make([]int64, len(LatencyBuckets)+1)
+		}
+		for j, count := range o.Counts {
+			a.Counts[j] += count
+		}
+		a.Lookups += o.Lookups
+		a.Errors += o.Errors
+		a.Total += o.Total
+	}
+}
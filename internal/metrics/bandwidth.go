@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Categories of the RPCs a node's bandwidth is accounted to
+const (
+	// CategoryLookup is the routing of lookups: FindSuccessor and
+	// ClosestPrecedingFinger
+	CategoryLookup = "lookup"
+	// CategoryMaintenance is keeping the ring together: stabilization,
+	// notify, pings, successor lists and reachability checks
+	CategoryMaintenance = "maintenance"
+	// CategoryReplication is copying keys to replicas
+	CategoryReplication = "replication"
+	// CategoryTransfer is moving keys between owners: hand-offs and
+	// snapshots
+	CategoryTransfer = "transfer"
+	// CategoryStorage is the puts and gets of clients
+	CategoryStorage = "storage"
+	// CategoryOther is every other RPC, such as broadcasts and admin calls
+	CategoryOther = "other"
+)
+
+// Categories lists the bandwidth categories in report order
+var Categories = []string{CategoryLookup, CategoryMaintenance, CategoryReplication, CategoryTransfer, CategoryStorage, CategoryOther}
+
+// BytesSent returns the name of the counter a node adds the bytes of the
+// RPC messages of a category it sends to, as sized on the wire
+func BytesSent(category string) string {
+	return "bytes_sent_" + category
+}
+
+// BytesReceived returns the name of the counter of the bytes received in a
+// category
+func BytesReceived(category string) string {
+	return "bytes_received_" + category
+}
+
+// Traffic is the bytes sent and received in one category
+type Traffic struct {
+	Sent     int64 `json:"sent"`
+	Received int64 `json:"received"`
+	// Calls is the number of RPCs of the category the node made
+	Calls int64 `json:"calls,omitempty"`
+}
+
+// Total returns the bytes sent and received
+func (t Traffic) Total() int64 {
+	return t.Sent + t.Received
+}
+
+// Bandwidth is the traffic of a node, or of a ring, by category
+type Bandwidth map[string]Traffic
+
+// Add adds the traffic of other to b
+func (b Bandwidth) Add(other Bandwidth) {
+	for category, t := range other {
+		sum := b[category]
+		sum.Sent += t.Sent
+		sum.Received += t.Received
+		sum.Calls += t.Calls
+		b[category] = sum
+	}
+}
+
+// Total returns the bytes sent and received in every category
+func (b Bandwidth) Total() int64 {
+	var total int64
+	for _, t := range b {
+		total += t.Total()
+	}
+	return total
+}
+
+// MaintenanceOverhead returns the fraction of all bytes spent on ring
+// maintenance, the overhead a DHT pays to keep routing correct under churn,
+// or 0 if nothing was sent
+func (b Bandwidth) MaintenanceOverhead() float64 {
+	total := b.Total()
+	if total == 0 {
+		return 0
+	}
+	return float64(b[CategoryMaintenance].Total()) / float64(total)
+}
+
+// WriteBandwidth writes the bandwidth of every node, by node address, to
+// bandwidth_<experiment>.csv with one row per node and category
+func (gm *GlobalMetrics) WriteBandwidth(nodes map[string]Bandwidth) error {
+	if err := os.MkdirAll(gm.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	path := filepath.Join(gm.OutputDir, fmt.Sprintf("bandwidth_%s.csv", gm.ExperimentID))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bandwidth CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"node", "category", "bytes_sent", "bytes_received"})
+	addresses := make([]string, 0, len(nodes))
+	for address := range nodes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		for _, category := range Categories {
+			t := nodes[address][category]
+			writer.Write([]string{address, category, strconv.FormatInt(t.Sent, 10), strconv.FormatInt(t.Received, 10)})
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write bandwidth CSV file: %w", err)
+	}
+	return file.Close()
+}
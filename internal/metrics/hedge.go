@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"time"
+)
+
+// HedgeStats counts the lookup hops a node hedged
+type HedgeStats struct {
+	// Hops is the number of lookup hops that could have been hedged
+	Hops int64
+	// Hedged is the number of hops sent to a second node
+	Hedged int64
+	// Wins is the number of hedged hops answered by the second node first
+	Wins int64
+	// Delay is the current hedge delay
+	Delay time.Duration
+}
+
+// UpdateLookupHedging replaces the lookup hedging counters of the node
+func (m *Metrics) UpdateLookupHedging(stats HedgeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lookupHedge = stats
+}
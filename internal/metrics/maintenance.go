@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"time"
+)
+
+// MaintenanceStats is the pace of a node's ring maintenance
+type MaintenanceStats struct {
+	StabilizeInterval  time.Duration
+	FixFingersInterval time.Duration
+	// StabilizeRounds and FixFingersRounds count the rounds run, so the
+	// maintenance messages an adaptive pace saves show up as a lower rate
+	StabilizeRounds  int64
+	FixFingersRounds int64
+}
+
+// UpdateMaintenance replaces the maintenance pace of the node
+func (m *Metrics) UpdateMaintenance(stats MaintenanceStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.maintenance = stats
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory is a sink that keeps what is recorded into it, for tests
+type Memory struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	timers   map[string][]time.Duration
+	gauges   map[string]float64
+}
+
+// NewMemory creates an empty in-memory sink
+func NewMemory() *Memory {
+	return &Memory{
+		counters: make(map[string]int64),
+		timers:   make(map[string][]time.Duration),
+		gauges:   make(map[string]float64),
+	}
+}
+
+// Counter returns the counter called name
+func (m *Memory) Counter(name string) Counter {
+	return CounterFunc(func(delta int64) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.counters[name] += delta
+	})
+}
+
+// Timer returns the timer called name
+func (m *Memory) Timer(name string) Timer {
+	return TimerFunc(func(d time.Duration) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.timers[name] = append(m.timers[name], d)
+	})
+}
+
+// Gauge returns the gauge called name
+func (m *Memory) Gauge(name string) Gauge {
+	return GaugeFunc(func(value float64) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.gauges[name] = value
+	})
+}
+
+// CounterValue returns the value of the counter called name
+func (m *Memory) CounterValue(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counters[name]
+}
+
+// Observations returns the durations observed by the timer called name
+func (m *Memory) Observations(name string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]time.Duration(nil), m.timers[name]...)
+}
+
+// GaugeValue returns the last value set on the gauge called name
+func (m *Memory) GaugeValue(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.gauges[name]
+}
@@ -23,11 +23,39 @@ type Metrics struct {
 	messageCount   int64
 	lookupCount    int64
 	lookupLatency  []time.Duration
+	lookupHops     []int
+	
+	// Per-tenant counters (see tenant.go) and their values at the last
+	// snapshot
+	tenants     map[string]*TenantStats
+	lastTenants map[string]TenantStats
+	
+	// Lookup latency by target arc of the keyspace (see arcs.go)
+	arcs ArcHeatmap
+	
+	// Pace of stabilization and fix-fingers (see maintenance.go)
+	maintenance MaintenanceStats
+	
+	// Hedged lookup hops (see hedge.go)
+	lookupHedge HedgeStats
+	
+	// RPC, traffic and storage counters (see nodestats.go)
+	nodeStats NodeStats
+	
+	// Instruments registered through Sink with no CSV column, served to
+	// Prometheus only
+	counters map[string]int64
+	timers   map[string]*timerTotals
+	gauges   map[string]float64
 	
 	// CSV writer
 	csvFile   *os.File
 	csvWriter *csv.Writer
 	
+	// Per-tenant CSV writer, created with the first tenant
+	tenantFile   *os.File
+	tenantWriter *csv.Writer
+	
 	// Background writer
 	stopChan chan struct{}
 	wg       sync.WaitGroup
@@ -50,7 +78,7 @@ func NewMetrics(nodeID, outputDir, experimentID string) (*Metrics, error) {
 	writer := csv.NewWriter(file)
 	
 	// Write CSV header
-	header := []string{"timestamp", "nodes", "messages", "lookups", "avg_lookup_ms"}
+	header := []string{"timestamp", "nodes", "messages", "lookups", "avg_lookup_ms", "avg_hops", "finger_accuracy"}
 	if err := writer.Write(header); err != nil {
 		file.Close()
 		return nil, fmt.Errorf("failed to write CSV header: %w", err)
@@ -64,6 +92,9 @@ func NewMetrics(nodeID, outputDir, experimentID string) (*Metrics, error) {
 		timestamp:    time.Now(),
 		csvFile:      file,
 		csvWriter:    writer,
+		counters:     make(map[string]int64),
+		timers:       make(map[string]*timerTotals),
+		gauges:       make(map[string]float64),
 		stopChan:     make(chan struct{}),
 	}
 	
@@ -83,6 +114,14 @@ func (m *Metrics) RecordLookup(latency time.Duration) {
 	m.lookupLatency = append(m.lookupLatency, latency)
 }
 
+// RecordHops records the number of RPC hops a lookup took
+func (m *Metrics) RecordHops(hops int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.lookupHops = append(m.lookupHops, hops)
+}
+
 // RecordMessage records a message sent or received
 func (m *Metrics) RecordMessage() {
 	m.mu.Lock()
@@ -99,6 +138,68 @@ func (m *Metrics) UpdateNodeCount(count int) {
 	m.nodeCount = count
 }
 
+// timerTotals sums the durations a timer observed
+type timerTotals struct {
+	count int64
+	total time.Duration
+}
+
+// Counter returns the counter called name. The lookup and message
+// counters a node registers feed the CSV columns.
+func (m *Metrics) Counter(name string) Counter {
+	return CounterFunc(func(delta int64) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		
+		switch name {
+		case Lookups:
+			m.lookupCount += delta
+		case LookupHops:
+			m.lookupHops = append(m.lookupHops, int(delta))
+		case Messages:
+			m.messageCount += delta
+		default:
+			m.counters[name] += delta
+		}
+	})
+}
+
+// Timer returns the timer called name. The lookup latency a node registers
+// feeds the CSV columns.
+func (m *Metrics) Timer(name string) Timer {
+	return TimerFunc(func(d time.Duration) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		
+		if name == LookupLatency {
+			m.lookupLatency = append(m.lookupLatency, d)
+			return
+		}
+		t := m.timers[name]
+		if t == nil {
+			t = &timerTotals{}
+			m.timers[name] = t
+		}
+		t.count++
+		t.total += d
+	})
+}
+
+// Gauge returns the gauge called name. The node count and the finger
+// accuracy feed the CSV columns.
+func (m *Metrics) Gauge(name string) Gauge {
+	return GaugeFunc(func(value float64) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		
+		if name == Nodes {
+			m.nodeCount = int(value)
+			return
+		}
+		m.gauges[name] = value
+	})
+}
+
 // WriteSnapshot writes current metrics to CSV
 func (m *Metrics) WriteSnapshot() error {
 	m.mu.Lock()
@@ -119,6 +220,12 @@ func (m *Metrics) WriteSnapshot() error {
 		fmt.Sprintf("%d", m.messageCount),
 		fmt.Sprintf("%d", m.lookupCount),
 		fmt.Sprintf("%.2f", avgLatency),
+		fmt.Sprintf("%.2f", m.averageHopsLocked()),
+		"",
+	}
+	// Empty until the node checks its fingers
+	if accuracy, ok := m.gauges[FingerAccuracy]; ok {
+		record[6] = fmt.Sprintf("%.3f", accuracy)
 	}
 	
 	if err := m.csvWriter.Write(record); err != nil {
@@ -129,8 +236,30 @@ func (m *Metrics) WriteSnapshot() error {
 	
 	// Reset lookup latency for next snapshot but keep counters
 	m.lookupLatency = m.lookupLatency[:0]
+	m.lookupHops = m.lookupHops[:0]
 	
-	return nil
+	return m.writeTenantSnapshotLocked(time.Now())
+}
+
+// AverageHops returns the average hops of the lookups recorded since the
+// last snapshot
+func (m *Metrics) AverageHops() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	return m.averageHopsLocked()
+}
+
+// averageHopsLocked computes AverageHops. The caller must hold mu.
+func (m *Metrics) averageHopsLocked() float64 {
+	if len(m.lookupHops) == 0 {
+		return 0
+	}
+	total := 0
+	for _, hops := range m.lookupHops {
+		total += hops
+	}
+	return float64(total) / float64(len(m.lookupHops))
 }
 
 // startPeriodicWriter starts a goroutine that writes metrics every 30 seconds
@@ -171,6 +300,10 @@ func (m *Metrics) Close() error {
 		m.csvWriter.Flush()
 	}
 	
+	if m.tenantFile != nil {
+		m.tenantFile.Close()
+	}
+	
 	if m.csvFile != nil {
 		return m.csvFile.Close()
 	}
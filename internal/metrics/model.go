@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ModelComparison is a quantity measured in a run next to what the
+// theoretical model of Chord predicts for it
+type ModelComparison struct {
+	Name     string
+	Measured float64
+	Expected float64
+}
+
+// Ratio returns the measured value over the expected one, 0 if nothing
+// was expected
+func (c ModelComparison) Ratio() float64 {
+	if c.Expected == 0 {
+		return 0
+	}
+	return c.Measured / c.Expected
+}
+
+// WriteModel writes the comparisons to model_<experiment>.csv with one row
+// per quantity
+func (gm *GlobalMetrics) WriteModel(comparisons []ModelComparison) error {
+	if err := os.MkdirAll(gm.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	path := filepath.Join(gm.OutputDir, fmt.Sprintf("model_%s.csv", gm.ExperimentID))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create model CSV file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"quantity", "measured", "expected", "ratio"})
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	for _, c := range comparisons {
+		writer.Write([]string{c.Name, format(c.Measured), format(c.Expected), format(c.Ratio())})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write model CSV file: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"time"
+)
+
+// NodeStats is a node's RPC, traffic and storage counters
+type NodeStats struct {
+	// RPCs counts the RPCs served by method name
+	RPCs              map[string]int64
+	BytesSent         int64
+	BytesReceived     int64
+	ActiveConnections int
+	StoredKeys        int
+	Uptime            time.Duration
+	// LastStabilization is when the last stabilization round ran, zero if
+	// none has
+	LastStabilization time.Time
+}
+
+// UpdateNodeStats replaces the RPC, traffic and storage counters of the
+// node
+func (m *Metrics) UpdateNodeStats(stats NodeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nodeStats = stats
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// labelEscaper escapes label values in the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the current metrics in the Prometheus text
+// exposition format, with the per-tenant metrics labelled by tenant
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	nodeCount, messages, lookups, avgLatency := m.GetCurrentStats()
+	avgHops := m.AverageHops()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b := bufio.NewWriter(w)
+	gauge := func(name, help string, value any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	counter := func(name, help string, value any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %v\n", name, help, name, name, value)
+	}
+	gauge("chord_nodes", "Number of nodes in the ring as seen by this node.", nodeCount)
+	counter("chord_messages_total", "Messages handled by this node.", messages)
+	counter("chord_lookups_total", "Lookups performed by this node.", lookups)
+	gauge("chord_lookup_latency_avg_ms", "Average lookup latency since the last snapshot.", avgLatency)
+	gauge("chord_lookup_hops_avg", "Average RPC hops per lookup since the last snapshot.", avgHops)
+	gauge("chord_stabilize_interval_seconds", "Current period of stabilization rounds.", m.maintenance.StabilizeInterval.Seconds())
+	gauge("chord_fix_fingers_interval_seconds", "Current period of fix-fingers rounds.", m.maintenance.FixFingersInterval.Seconds())
+	counter("chord_stabilize_rounds_total", "Stabilization rounds run by this node.", m.maintenance.StabilizeRounds)
+	counter("chord_fix_fingers_rounds_total", "Fix-fingers rounds run by this node.", m.maintenance.FixFingersRounds)
+	counter("chord_lookup_hedgeable_hops_total", "Lookup hops that could have been hedged.", m.lookupHedge.Hops)
+	counter("chord_lookup_hedged_hops_total", "Lookup hops also sent to a second node.", m.lookupHedge.Hedged)
+	counter("chord_lookup_hedge_wins_total", "Hedged lookup hops answered by the second node first.", m.lookupHedge.Wins)
+	gauge("chord_lookup_hedge_delay_seconds", "Current delay before a lookup hop is hedged.", m.lookupHedge.Delay.Seconds())
+	counter("chord_bytes_sent_total", "Bytes sent over this node's gRPC connections.", m.nodeStats.BytesSent)
+	counter("chord_bytes_received_total", "Bytes received over this node's gRPC connections.", m.nodeStats.BytesReceived)
+	gauge("chord_active_connections", "Connections open from and to this node.", m.nodeStats.ActiveConnections)
+	gauge("chord_stored_keys", "Live keys in this node's local store.", m.nodeStats.StoredKeys)
+	gauge("chord_uptime_seconds", "Time since this node started.", m.nodeStats.Uptime.Seconds())
+	if !m.nodeStats.LastStabilization.IsZero() {
+		gauge("chord_last_stabilization_timestamp_seconds", "Unix time of this node's last stabilization round.",
+			float64(m.nodeStats.LastStabilization.UnixMilli())/1000)
+	}
+	fmt.Fprintf(b, "# HELP chord_rpcs_total RPCs served by this node by method.\n# TYPE chord_rpcs_total counter\n")
+	for _, method := range sortedKeys(m.nodeStats.RPCs) {
+		fmt.Fprintf(b, "chord_rpcs_total{method=\"%s\"} %d\n", labelEscaper.Replace(method), m.nodeStats.RPCs[method])
+	}
+
+	for _, name := range sortedKeys(m.counters) {
+		counter("chord_"+name+"_total", "Recorded by the node as "+name+".", m.counters[name])
+	}
+	for _, name := range sortedKeys(m.gauges) {
+		gauge("chord_"+name, "Recorded by the node as "+name+".", m.gauges[name])
+	}
+	for _, name := range sortedKeys(m.timers) {
+		summary := "chord_" + name + "_seconds"
+		t := m.timers[name]
+		fmt.Fprintf(b, "# HELP %s Recorded by the node as %s.\n# TYPE %s summary\n%s_sum %v\n%s_count %d\n",
+			summary, name, summary, summary, t.total.Seconds(), summary, t.count)
+	}
+
+	tenants := m.sortedTenantsLocked()
+	perTenant := func(name, kind, help string, value func(*TenantStats) any) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, tenant := range tenants {
+			fmt.Fprintf(b, "%s{tenant=\"%s\"} %v\n", name, labelEscaper.Replace(tenant), value(m.tenants[tenant]))
+		}
+	}
+	perTenant("chord_tenant_reads_total", "counter", "Reads served per tenant.",
+		func(s *TenantStats) any { return s.Reads })
+	perTenant("chord_tenant_writes_total", "counter", "Writes served per tenant.",
+		func(s *TenantStats) any { return s.Writes })
+	perTenant("chord_tenant_errors_total", "counter", "Failed reads and writes per tenant.",
+		func(s *TenantStats) any { return s.Errors })
+	perTenant("chord_tenant_latency_seconds_total", "counter", "Time spent serving each tenant's reads and writes.",
+		func(s *TenantStats) any { return s.Latency.Seconds() })
+	perTenant("chord_tenant_keys", "gauge", "Keys stored per tenant.",
+		func(s *TenantStats) any { return s.Keys })
+	perTenant("chord_tenant_bytes", "gauge", "Value bytes stored per tenant.",
+		func(s *TenantStats) any { return s.Bytes })
+
+	const arcHistogram = "chord_lookup_arc_latency_seconds"
+	fmt.Fprintf(b, "# HELP %s Lookup latency by target arc of the keyspace (leading hex digit of the key).\n# TYPE %s histogram\n",
+		arcHistogram, arcHistogram)
+	for arc, a := range m.arcs {
+		if a.Lookups == 0 {
+			continue
+		}
+		label := ArcLabel(arc)
+		var cumulative int64
+		for i, bound := range LatencyBuckets {
+			cumulative += a.Counts[i]
+			fmt.Fprintf(b, "%s_bucket{arc=\"%s\",le=\"%v\"} %d\n", arcHistogram, label, bound.Seconds(), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket{arc=\"%s\",le=\"+Inf\"} %d\n", arcHistogram, label, a.Lookups)
+		fmt.Fprintf(b, "%s_sum{arc=\"%s\"} %v\n", arcHistogram, label, a.Total.Seconds())
+		fmt.Fprintf(b, "%s_count{arc=\"%s\"} %d\n", arcHistogram, label, a.Lookups)
+	}
+	fmt.Fprintf(b, "# HELP chord_lookup_arc_errors_total Failed lookups by target arc of the keyspace.\n# TYPE chord_lookup_arc_errors_total counter\n")
+	for arc, a := range m.arcs {
+		if a.Errors > 0 {
+			fmt.Fprintf(b, "chord_lookup_arc_errors_total{arc=\"%s\"} %d\n", ArcLabel(arc), a.Errors)
+		}
+	}
+
+	return b.Flush()
+}
+
+// sortedKeys returns the names of an instrument map in order
+func sortedKeys[V any](instruments map[string]V) []string {
+	names := make([]string, 0, len(instruments))
+	for name := range instruments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP serves the metrics to a Prometheus scraper
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := m.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
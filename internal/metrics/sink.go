@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"time"
+)
+
+// Names of the instruments a chord.Node registers (see chord.Node.SetMetrics)
+const (
+	// Lookups counts the lookups a node started that succeeded,
+	// LookupErrors those that failed
+	Lookups      = "lookups"
+	LookupErrors = "lookup_errors"
+	// LookupHops is added the RPC hops of every successful lookup
+	LookupHops = "lookup_hops"
+	// LookupLatency observes the time every successful lookup took
+	LookupLatency = "lookup_latency"
+	// Messages counts the RPCs a node serves
+	Messages = "messages"
+	// PayloadBytes is added the size of every message of the RPCs a node
+	// makes to peers, WireBytes its size on the wire after compression
+	PayloadBytes = "payload_bytes"
+	WireBytes    = "wire_bytes"
+	// Nodes is the number of nodes in the ring as seen by a node
+	Nodes = "nodes"
+	// FingerAccuracy is the fraction of correct fingers found by a node's
+	// last finger table check
+	FingerAccuracy = "finger_accuracy"
+	// LookupSaturation is the fraction of a node's limit on lookups in
+	// flight in use, LookupsRejected counts the lookups it turned away
+	LookupSaturation = "lookup_saturation"
+	LookupsRejected  = "lookups_rejected"
+	// ReReplications counts the rounds a node ran to restore the
+	// replication factor of its keys, ReReplicatedKeys the entries they
+	// copied and ReReplicationErrors the copies to a replica that failed
+	ReReplications      = "rereplications"
+	ReReplicatedKeys    = "rereplicated_keys"
+	ReReplicationErrors = "rereplication_errors"
+	// ReReplicationPending is 1 while a round waits out its delay
+	ReReplicationPending = "rereplication_pending"
+	// TombstonesReaped counts the tombstones of deleted keys a node removed
+	// once past the horizon
+	TombstonesReaped = "tombstones_reaped"
+	// StorageKeys and StorageBytes are the entries a node with storage
+	// limits stores and the bytes of their keys and values, Evictions
+	// counts the entries it dropped to make room
+	StorageKeys  = "storage_keys"
+	StorageBytes = "storage_bytes"
+	Evictions    = "evictions"
+)
+
+// Sink is where a node records its metrics. Instruments are registered once
+// by name and may be used from any goroutine; they must not block.
+// Metrics, which writes CSV files and serves Prometheus, and Memory, which
+// keeps the values for tests, are the implementations in this package.
+type Sink interface {
+	Counter(name string) Counter
+	Timer(name string) Timer
+	Gauge(name string) Gauge
+}
+
+// Counter is a value that only grows
+type Counter interface {
+	Add(delta int64)
+}
+
+// Timer records durations
+type Timer interface {
+	Observe(d time.Duration)
+}
+
+// Gauge is a value that is set
+type Gauge interface {
+	Set(value float64)
+}
+
+// CounterFunc, TimerFunc and GaugeFunc adapt functions to instruments
+type (
+	CounterFunc func(delta int64)
+	TimerFunc   func(d time.Duration)
+	GaugeFunc   func(value float64)
+)
+
+// Add calls f(delta)
+func (f CounterFunc) Add(delta int64) { f(delta) }
+
+// Observe calls f(d)
+func (f TimerFunc) Observe(d time.Duration) { f(d) }
+
+// Set calls f(value)
+func (f GaugeFunc) Set(value float64) { f(value) }
+
+// Discard is a sink that drops everything recorded into it
+var Discard Sink = discard{}
+
+// discard is the Discard sink
+type discard struct{}
+
+func (discard) Counter(string) Counter { return CounterFunc(func(int64) {}) }
+func (discard) Timer(string) Timer     { return TimerFunc(func(time.Duration) {}) }
+func (discard) Gauge(string) Gauge     { return GaugeFunc(func(float64) {}) }
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTenant is the tenant of keys without a namespace prefix
+const DefaultTenant = "default"
+
+// TenantOf returns the tenant a key is accounted to: its namespace, the part
+// before the first "/", or DefaultTenant for keys without one
+func TenantOf(key string) string {
+	if i := strings.IndexByte(key, '/'); i > 0 {
+		return key[:i]
+	}
+	return DefaultTenant
+}
+
+// TenantStats are the counters and storage usage of one tenant on a node
+type TenantStats struct {
+	Reads   int64
+	Writes  int64
+	Errors  int64
+	Latency time.Duration // Total time spent serving the tenant's operations
+	Keys    int64
+	Bytes   int64
+}
+
+// TenantUsage is the storage a tenant uses on a node
+type TenantUsage struct {
+	Keys  int64
+	Bytes int64
+}
+
+// RecordTenantOp records a read or write served for tenant
+func (m *Metrics) RecordTenantOp(tenant string, write bool, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.tenantLocked(tenant)
+	if write {
+		s.Writes++
+	} else {
+		s.Reads++
+	}
+	if err != nil {
+		s.Errors++
+	}
+	s.Latency += latency
+}
+
+// UpdateTenantUsage replaces the storage usage of every tenant. Tenants
+// missing from usage no longer store anything.
+func (m *Metrics) UpdateTenantUsage(usage map[string]TenantUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.tenants {
+		s.Keys, s.Bytes = 0, 0
+	}
+	for tenant, u := range usage {
+		s := m.tenantLocked(tenant)
+		s.Keys, s.Bytes = u.Keys, u.Bytes
+	}
+}
+
+// TenantStats returns a copy of the per-tenant counters
+func (m *Metrics) TenantStats() map[string]TenantStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]TenantStats, len(m.tenants))
+	for tenant, s := range m.tenants {
+		stats[tenant] = *s
+	}
+	return stats
+}
+
+// tenantLocked returns the counters of tenant, creating them if needed. The
+// caller must hold mu.
+func (m *Metrics) tenantLocked(tenant string) *TenantStats {
+	if m.tenants == nil {
+		m.tenants = make(map[string]*TenantStats)
+	}
+	s, ok := m.tenants[tenant]
+	if !ok {
+		s = &TenantStats{}
+		m.tenants[tenant] = s
+	}
+	return s
+}
+
+// sortedTenantsLocked returns the known tenants in name order. The caller
+// must hold mu.
+func (m *Metrics) sortedTenantsLocked() []string {
+	tenants := make([]string, 0, len(m.tenants))
+	for tenant := range m.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// writeTenantSnapshotLocked appends one row per tenant to the tenant CSV,
+// creating it on first use. The average latency covers the operations since
+// the previous snapshot. The caller must hold mu.
+func (m *Metrics) writeTenantSnapshotLocked(now time.Time) error {
+	if len(m.tenants) == 0 {
+		return nil
+	}
+
+	if m.tenantWriter == nil {
+		filename := fmt.Sprintf("node_%s_%s_tenants.csv", m.nodeID[:8], m.experimentID)
+		file, err := os.Create(filepath.Join(m.outputDir, filename))
+		if err != nil {
+			return fmt.Errorf("failed to create tenant CSV file: %w", err)
+		}
+		m.tenantFile = file
+		m.tenantWriter = csv.NewWriter(file)
+		header := []string{"timestamp", "tenant", "reads", "writes", "errors", "avg_latency_ms", "keys", "bytes"}
+		if err := m.tenantWriter.Write(header); err != nil {
+			return fmt.Errorf("failed to write tenant CSV header: %w", err)
+		}
+	}
+	if m.lastTenants == nil {
+		m.lastTenants = make(map[string]TenantStats)
+	}
+
+	for _, tenant := range m.sortedTenantsLocked() {
+		s := m.tenants[tenant]
+		last := m.lastTenants[tenant]
+
+		avgLatency := 0.0
+		if ops := s.Reads + s.Writes - last.Reads - last.Writes; ops > 0 {
+			avgLatency = float64((s.Latency - last.Latency).Nanoseconds()) / float64(ops) / 1e6
+		}
+		record := []string{
+			fmt.Sprintf("%d", now.Unix()),
+			tenant,
+			fmt.Sprintf("%d", s.Reads),
+			fmt.Sprintf("%d", s.Writes),
+			fmt.Sprintf("%d", s.Errors),
+			fmt.Sprintf("%.2f", avgLatency),
+			fmt.Sprintf("%d", s.Keys),
+			fmt.Sprintf("%d", s.Bytes),
+		}
+		if err := m.tenantWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write tenant CSV record: %w", err)
+		}
+		m.lastTenants[tenant] = *s
+	}
+	m.tenantWriter.Flush()
+	return m.tenantWriter.Error()
+}
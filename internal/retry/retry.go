@@ -0,0 +1,117 @@
+// Package retry runs operations with bounded retries and backoff. Its
+// Policy is shared by the subsystems that retry remote calls, so attempts,
+// delays and which errors are worth another try are configured in one
+// place instead of in per-call-site constants.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Forever makes a policy retry until its context is done
+const Forever = math.MaxInt
+
+// Policy configures how an operation is retried. The zero Policy makes a
+// single attempt.
+type Policy struct {
+	// MaxAttempts is the number of attempts including the first (at least
+	// 1), or Forever
+	MaxAttempts int
+	// InitialDelay is the wait before the first retry
+	InitialDelay time.Duration
+	// Multiplier grows the delay after every retry; at most 1 keeps it
+	// constant
+	Multiplier float64
+	// MaxDelay caps the delay (0 for no cap)
+	MaxDelay time.Duration
+	// Jitter randomizes every delay by up to this fraction either way, so
+	// that callers failing together do not retry together
+	Jitter float64
+	// Retryable reports whether an error is worth another attempt; nil
+	// retries every error
+	Retryable func(err error) bool
+	// Hint returns the delay an error asks for, such as one sent by an
+	// overloaded server, which replaces the backoff for that retry
+	Hint func(err error) (time.Duration, bool)
+}
+
+// On returns a Retryable classifier matching any of targets with errors.Is
+func On(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Delay returns the backoff before the given retry, 1 being the first,
+// without jitter
+func (p Policy) Delay(retry int) time.Duration {
+	delay := float64(p.InitialDelay)
+	if p.Multiplier > 1 {
+		delay *= math.Pow(p.Multiplier, float64(retry-1))
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// Do calls op until it succeeds, fails with an error that is not
+// retryable, runs out of attempts or ctx is done. It returns the last error
+// of op, or the context's error if ctx ended a wait.
+func (p Policy) Do(ctx context.Context, op func(attempt int) error) error {
+	return p.DoNotify(ctx, op, nil)
+}
+
+// DoNotify is Do calling notify, if not nil, with every error about to be
+// retried and the delay before the retry
+func (p Policy) DoNotify(ctx context.Context, op func(attempt int) error, notify func(err error, delay time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := op(attempt)
+		if err == nil || attempt >= p.MaxAttempts || (p.Retryable != nil && !p.Retryable(err)) {
+			return err
+		}
+
+		delay := p.jitter(p.Delay(attempt))
+		if p.Hint != nil {
+			if hinted, ok := p.Hint(err); ok {
+				delay = hinted
+			}
+		}
+		if notify != nil {
+			notify(err, delay)
+		}
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// jitter spreads delay by up to Jitter either way
+func (p Policy) jitter(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	spread := (rand.Float64()*2 - 1) * p.Jitter * float64(delay)
+	return delay + time.Duration(spread)
+}
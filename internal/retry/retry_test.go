@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errFatal     = errors.New("fatal")
+)
+
+func TestDelay(t *testing.T) {
+	policy := Policy{InitialDelay: 10 * time.Millisecond, Multiplier: 2, MaxDelay: 50 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 100: 50 * time.Millisecond} {
+		if got := policy.Delay(retry); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+	if got := (Policy{InitialDelay: time.Second}).Delay(5); got != time.Second {
+		t.Errorf("Expected a constant delay without a multiplier, got %v", got)
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{MaxAttempts: 3, InitialDelay: time.Millisecond, Retryable: On(errTransient)}
+
+	attempts := 0
+	err := policy.Do(ctx, func(attempt int) error {
+		attempts = attempt
+		if attempt < 2 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Expected success on attempt 2, got %v after %d", err, attempts)
+	}
+
+	attempts = 0
+	err = policy.Do(ctx, func(attempt int) error {
+		attempts = attempt
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || attempts != 3 {
+		t.Errorf("Expected the last error after 3 attempts, got %v after %d", err, attempts)
+	}
+
+	attempts = 0
+	err = policy.Do(ctx, func(attempt int) error {
+		attempts = attempt
+		return errFatal
+	})
+	if !errors.Is(err, errFatal) || attempts != 1 {
+		t.Errorf("Expected no retry of a fatal error, got %v after %d", err, attempts)
+	}
+
+	if err := (Policy{}).Do(ctx, func(int) error { return errTransient }); !errors.Is(err, errTransient) {
+		t.Errorf("Expected the zero policy to make one attempt, got %v", err)
+	}
+}
+
+func TestDoHintAndContext(t *testing.T) {
+	var delays []time.Duration
+	policy := Policy{
+		MaxAttempts:  2,
+		InitialDelay: time.Hour,
+		Hint:         func(err error) (time.Duration, bool) { return time.Millisecond, true },
+	}
+	policy.DoNotify(context.Background(), func(int) error { return errTransient }, func(err error, delay time.Duration) {
+		delays = append(delays, delay)
+	})
+	if len(delays) != 1 || delays[0] != time.Millisecond {
+		t.Errorf("Expected one retry after the hinted delay, got %v", delays)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Policy{MaxAttempts: Forever, InitialDelay: time.Millisecond}.Do(ctx, func(int) error { return errTransient })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected retrying forever to stop with the context, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package client is a Go client of a Chord DHT ring. It looks up the node
+// responsible for a key through any node of the ring and reads and writes
+// the key on that node, retrying through the other nodes it was given when
+// one fails. Scan lists the keys of the whole ring, node by node. It depends
+// on the hash and proto modules only, so programs using it do not link the
+// server.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+	"chord-dht/pkg/hash"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// DefaultTimeout bounds each RPC of a call by default
+	DefaultTimeout = 5 * time.Second
+	// DefaultMaxAttempts is how many times a call is tried by default
+	DefaultMaxAttempts = 4
+	// DefaultRetryDelay is the wait before the second attempt of a call by
+	// default; it doubles on every further attempt
+	DefaultRetryDelay = 100 * time.Millisecond
+	// maxRetryDelay caps the wait between attempts
+	maxRetryDelay = 2 * time.Second
+	// Metadata keys nodes read the client's name, ring, token and protocol
+	// versions from
+	clientMetadataKey     = "chord-client"
+	ringMetadataKey       = "chord-ring"
+	tokenMetadataKey      = "chord-access-token"
+	versionMetadataKey    = "chord-protocol-version"
+	minVersionMetadataKey = "chord-protocol-min-version"
+)
+
+// Protocol versions the client speaks, sent with every call so that nodes
+// sharing none of them refuse it instead of misreading it
+var (
+	protocolVersion    = strconv.Itoa(int(pb.ProtocolVersion_PROTOCOL_VERSION_CURRENT))
+	minProtocolVersion = strconv.Itoa(int(pb.ProtocolVersion_PROTOCOL_VERSION_MIN))
+)
+
+var (
+	// ErrNotFound is returned by Get for a key not stored in the ring
+	ErrNotFound = errors.New("key not found")
+	// ErrNoNodes is returned by Dial without node addresses
+	ErrNoNodes = errors.New("no node addresses given")
+	// ErrUnavailable is returned by Dial when no node answers
+	ErrUnavailable = errors.New("no node of the ring answered")
+	// ErrClosed is returned for calls on a closed Client
+	ErrClosed = errors.New("client closed")
+)
+
+// Config configures a Client. Only Addrs is required.
+type Config struct {
+	// Addrs are nodes of the ring to send lookups through; the client
+	// moves on to the next one when a node fails
+	Addrs []string
+	// Timeout bounds each RPC; DefaultTimeout if zero
+	Timeout time.Duration
+	// MaxAttempts is how many times a call is tried; DefaultMaxAttempts
+	// if zero
+	MaxAttempts int
+	// RetryDelay is the wait before the second attempt; DefaultRetryDelay
+	// if zero. A node asking callers to wait longer is obeyed.
+	RetryDelay time.Duration
+	// ClientName identifies the program to the nodes, which use it for
+	// per-client limits and metrics
+	ClientName string
+	// Ring names the logical ring to use when nodes take part in several;
+	// empty for the default ring
+	Ring string
+	// Token is the access token sent with every call, for rings whose
+	// nodes require one with scopes for the buckets used
+	Token string
+	// Hash places keys on the ring; hash.SHA1 if nil. It must be the
+	// provider the ring's nodes use.
+	Hash hash.Provider
+	// DialOptions are added to the options of every connection. Without
+	// transport credentials among them connections are insecure.
+	DialOptions []grpc.DialOption
+}
+
+// Node is a node of the ring
+type Node struct {
+	// ID is the node's position on the ring in hex
+	ID      string
+	Address string
+}
+
+// Client reads and writes keys of a ring. It is safe for concurrent use.
+type Client struct {
+	config Config
+
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	entry  int // Index in config.Addrs of the node lookups go through
+	closed bool
+}
+
+// Connect connects to the ring the nodes at addrs belong to, with the
+// default configuration
+func Connect(addrs ...string) (*Client, error) {
+	return Dial(context.Background(), Config{Addrs: addrs})
+}
+
+// Dial connects to the ring the nodes of config.Addrs belong to. It returns
+// once one of them answered, and sends lookups through that node first.
+func Dial(ctx context.Context, config Config) (*Client, error) {
+	if len(config.Addrs) == 0 {
+		return nil, ErrNoNodes
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+	if config.Hash == nil {
+		config.Hash = hash.SHA1
+	}
+	config.Addrs = append([]string(nil), config.Addrs...)
+
+	c := &Client{config: config, conns: make(map[string]*grpc.ClientConn)}
+	var errs []error
+	for i, addr := range config.Addrs {
+		err := c.ping(ctx, addr)
+		if err == nil {
+			c.entry = i
+			return c, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	c.Close()
+	return nil, fmt.Errorf("%w: %w", ErrUnavailable, errors.Join(errs...))
+}
+
+// Lookup returns the node responsible for key
+func (c *Client) Lookup(ctx context.Context, key string) (Node, error) {
+	var node Node
+	err := c.retry(ctx, func(ctx context.Context, hint string) error {
+		var err error
+		node, err = c.lookup(ctx, key)
+		return err
+	})
+	return node, err
+}
+
+// Put stores value under key
+func (c *Client) Put(ctx context.Context, key string, value []byte) error {
+	return c.call(ctx, key, func(ctx context.Context, owner pb.ChordServiceClient) error {
+		resp, err := owner.Put(ctx, &pb.PutRequest{Key: key, Value: value})
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		return nil
+	})
+}
+
+// Get returns the value of key, or ErrNotFound
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := c.call(ctx, key, func(ctx context.Context, owner pb.ChordServiceClient) error {
+		resp, err := owner.Get(ctx, &pb.GetRequest{Key: key})
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		if !resp.Found {
+			return ErrNotFound
+		}
+		value = resp.Value
+		return nil
+	})
+	return value, err
+}
+
+// Close closes the connections to nodes
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	var errs []error
+	for addr, conn := range c.conns {
+		errs = append(errs, conn.Close())
+		delete(c.conns, addr)
+	}
+	return errors.Join(errs...)
+}
+
+// call runs op on the node responsible for key, with retries
+func (c *Client) call(ctx context.Context, key string, op func(context.Context, pb.ChordServiceClient) error) error {
+	err := c.retry(ctx, func(ctx context.Context, hint string) error {
+		address := hint
+		if address == "" {
+			owner, err := c.lookup(ctx, key)
+			if err != nil {
+				return err
+			}
+			address = owner.Address
+		}
+		owner, err := c.client(address)
+		if err != nil {
+			return err
+		}
+		rpcCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+		if err := op(rpcCtx, owner); err != nil {
+			return &nodeError{address: address, err: err}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrNotFound
+	case err != nil:
+		return fmt.Errorf("request for %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// lookup asks the entry node for the owner of key. A failed entry node is
+// left for the next one.
+func (c *Client) lookup(ctx context.Context, key string) (Node, error) {
+	entry := c.entryAddr()
+	client, err := c.client(entry)
+	if err != nil {
+		return Node{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := client.FindSuccessor(ctx, &pb.FindSuccessorRequest{Key: c.config.Hash.Hash(key).String()})
+	if err == nil && (!resp.Success || resp.Successor == nil) {
+		err = fmt.Errorf("lookup failed: %s", resp.Error)
+	}
+	if err != nil {
+		c.skipEntry(entry)
+		return Node{}, &nodeError{address: entry, err: err}
+	}
+	return Node{ID: resp.Successor.Id, Address: resp.Successor.Address}, nil
+}
+
+// ping checks that the node at addr answers
+func (c *Client) ping(ctx context.Context, addr string) error {
+	client, err := c.client(addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	if _, err := client.Ping(ctx, &pb.PingRequest{}); err != nil {
+		return &nodeError{address: addr, err: err}
+	}
+	return nil
+}
+
+// entryAddr returns the address of the node lookups go through
+func (c *Client) entryAddr() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.Addrs[c.entry]
+}
+
+// skipEntry moves lookups on to the next node after addr failed, unless
+// another call already did
+func (c *Client) skipEntry(addr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config.Addrs[c.entry] == addr {
+		c.entry = (c.entry + 1) % len(c.config.Addrs)
+	}
+}
+
+// client returns a client of the node at addr, reusing its connection
+func (c *Client) client(addr string) (pb.ChordServiceClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrClosed
+	}
+	conn, ok := c.conns[addr]
+	if !ok {
+		options := []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(c.withMetadata),
+		}
+		var err error
+		conn, err = grpc.NewClient(addr, append(options, c.config.DialOptions...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		c.conns[addr] = conn
+	}
+	return pb.NewChordServiceClient(conn), nil
+}
+
+// withMetadata sends the protocol versions, Config.ClientName, Config.Ring
+// and Config.Token with every call
+func (c *Client) withMetadata(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	pairs := []string{versionMetadataKey, protocolVersion, minVersionMetadataKey, minProtocolVersion}
+	if c.config.ClientName != "" {
+		pairs = append(pairs, clientMetadataKey, c.config.ClientName)
+	}
+	if c.config.Ring != "" {
+		pairs = append(pairs, ringMetadataKey, c.config.Ring)
+	}
+	if c.config.Token != "" {
+		pairs = append(pairs, tokenMetadataKey, c.config.Token)
+	}
+	return invoker(metadata.AppendToOutgoingContext(ctx, pairs...), method, req, reply, cc, opts...)
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "chord-dht/api/chord/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeNode is a node that routes every key to owner and stores what it is
+// sent, or redirects to redirect if set. Its successor is itself unless
+// successor is set.
+type fakeNode struct {
+	pb.UnimplementedChordServiceServer
+
+	addr   string
+	server *grpc.Server
+
+	mu       sync.Mutex
+	owner    string
+	redirect  string
+	successor string
+	data      map[string][]byte
+}
+
+func startFake(t *testing.T) *fakeNode {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	node := &fakeNode{addr: listener.Addr().String(), server: grpc.NewServer(), data: make(map[string][]byte)}
+	node.owner = node.addr
+	pb.RegisterChordServiceServer(node.server, node)
+	go node.server.Serve(listener)
+	t.Cleanup(node.server.Stop)
+	return node
+}
+
+func (f *fakeNode) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{Alive: true}, nil
+}
+
+func (f *fakeNode) FindSuccessor(ctx context.Context, req *pb.FindSuccessorRequest) (*pb.FindSuccessorResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &pb.FindSuccessorResponse{Success: true, Successor: &pb.Node{Id: "01", Address: f.owner}}, nil
+}
+
+func (f *fakeNode) Put(ctx context.Context, req *pb.PutRequest) (*pb.PutResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.redirected(req.Key); err != nil {
+		return nil, err
+	}
+	f.data[req.Key] = req.Value
+	return &pb.PutResponse{Success: true}, nil
+}
+
+func (f *fakeNode) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.redirected(req.Key); err != nil {
+		return nil, err
+	}
+	value, ok := f.data[req.Key]
+	return &pb.GetResponse{Value: value, Found: ok, Success: true}, nil
+}
+
+// redirected returns the error of a node not responsible for key
+func (f *fakeNode) redirected(key string) error {
+	if f.redirect == "" {
+		return nil
+	}
+	st, _ := status.New(codes.FailedPrecondition, "node is not responsible for key").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "NOT_RESPONSIBLE",
+		Domain:   errorDomain,
+		Metadata: map[string]string{"key": key, metadataOwnerAddress: f.redirect},
+	})
+	return st.Err()
+}
+
+// deadAddr returns an address nothing listens on
+func deadAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func dial(t *testing.T, addrs ...string) *Client {
+	t.Helper()
+	c, err := Dial(context.Background(), Config{Addrs: addrs, Timeout: time.Second, RetryDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestPutGet(t *testing.T) {
+	entry, owner := startFake(t), startFake(t)
+	entry.owner = owner.addr
+	c := dial(t, entry.addr)
+	ctx := context.Background()
+
+	node, err := c.Lookup(ctx, "key")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if node.Address != owner.addr {
+		t.Errorf("Lookup returned %s, expected %s", node.Address, owner.addr)
+	}
+
+	if err := c.Put(ctx, "key", []byte("value")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := owner.data["key"]; !ok {
+		t.Error("Put did not reach the owner")
+	}
+	value, err := c.Get(ctx, "key")
+	if err != nil || !bytes.Equal(value, []byte("value")) {
+		t.Errorf("Get returned %q, %v", value, err)
+	}
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing key returned %v, expected ErrNotFound", err)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	first, second := startFake(t), startFake(t)
+	c := dial(t, deadAddr(t), first.addr, second.addr)
+	ctx := context.Background()
+
+	first.server.Stop()
+	if err := c.Put(ctx, "key", []byte("value")); err != nil {
+		t.Fatalf("Put failed with one node left: %v", err)
+	}
+	if _, ok := second.data["key"]; !ok {
+		t.Error("Put did not go through the remaining node")
+	}
+
+	second.server.Stop()
+	if err := c.Put(ctx, "key", []byte("value")); err == nil {
+		t.Error("Put succeeded with no node left")
+	}
+}
+
+func TestOwnerRedirect(t *testing.T) {
+	stale, owner := startFake(t), startFake(t)
+	stale.redirect = owner.addr
+	c := dial(t, stale.addr)
+
+	if err := c.Put(context.Background(), "key", []byte("value")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := owner.data["key"]; !ok {
+		t.Error("Put did not follow the redirect to the owner")
+	}
+}
+
+func TestDialUnavailable(t *testing.T) {
+	_, err := Dial(context.Background(), Config{Addrs: []string{deadAddr(t)}, Timeout: time.Second})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Dial returned %v, expected ErrUnavailable", err)
+	}
+	if _, err := Connect(); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("Connect returned %v, expected ErrNoNodes", err)
+	}
+}
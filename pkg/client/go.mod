@@ -0,0 +1,23 @@
+module chord-dht/pkg/client
+
+go 1.24.0
+
+require (
+	chord-dht/api v0.0.0-00010101000000-000000000000
+	chord-dht/pkg/hash v0.0.0-00010101000000-000000000000
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251111163417-95abcf5c77ba
+	google.golang.org/grpc v1.77.0
+)
+
+require (
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+)
+
+// The repository's other client modules, as in the main module
+replace (
+	chord-dht/api => ../../api
+	chord-dht/pkg/hash => ../hash
+)
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain and the reasons below are those of the ErrorInfo details
+// nodes attach to their errors
+const (
+	errorDomain          = "chord-dht"
+	metadataOwnerAddress = "owner_address"
+)
+
+// retryableReasons are the failures a later attempt, possibly through
+// another node, is expected to get past
+var retryableReasons = map[string]bool{
+	"NOT_RESPONSIBLE":  true,
+	"RING_UNSTABLE":    true,
+	"RANGE_MOVING":     true,
+	"PEER_UNREACHABLE": true,
+	"PAUSED":           true,
+	"OVERLOADED":       true,
+	"ISOLATED":         true,
+}
+
+// nodeError is a failed RPC to a node
+type nodeError struct {
+	address string
+	err     error
+}
+
+// Error implements the error interface
+func (e *nodeError) Error() string {
+	return e.address + ": " + e.err.Error()
+}
+
+// Unwrap returns the error of the RPC
+func (e *nodeError) Unwrap() error {
+	return e.err
+}
+
+// verdict is what a failed attempt means for the next one
+type verdict struct {
+	retry bool
+	// owner is the node the failed node named responsible for the key,
+	// tried next without waiting
+	owner string
+	// after is how long the failed node asked callers to wait
+	after time.Duration
+}
+
+// retry runs op up to MaxAttempts times until it succeeds, waiting longer
+// after every failure. op is passed the owner a node named in the previous
+// failure, if any, to call without a lookup.
+func (c *Client) retry(ctx context.Context, op func(ctx context.Context, hint string) error) error {
+	delay := c.config.RetryDelay
+	hint := ""
+	for attempt := 1; ; attempt++ {
+		err := op(ctx, hint)
+		if err == nil {
+			return nil
+		}
+		v := judge(err)
+		if !v.retry || attempt >= c.config.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		hint = v.owner
+		wait := max(delay, v.after)
+		delay = min(delay*2, maxRetryDelay)
+		if hint != "" {
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// judge decides whether a failed attempt is worth repeating
+func judge(err error) verdict {
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrClosed) {
+		return verdict{}
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		// A node answering without success, such as a lookup that found
+		// no successor
+		return verdict{retry: true}
+	}
+
+	var (
+		v    verdict
+		info *errdetails.ErrorInfo
+	)
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.Domain == errorDomain {
+				info = detail
+			}
+		case *errdetails.RetryInfo:
+			v.after = detail.RetryDelay.AsDuration()
+		}
+	}
+	if info != nil {
+		v.retry = retryableReasons[info.Reason]
+		v.owner = info.Metadata[metadataOwnerAddress]
+		return v
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		v.retry = true
+	}
+	return v
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+const (
+	// DefaultScanParallelism is how many nodes Scan lists at once by
+	// default
+	DefaultScanParallelism = 4
+	// maxRingNodes bounds the nodes Nodes visits before giving up on a
+	// ring whose successor pointers do not close
+	maxRingNodes = 4096
+)
+
+// ScanOptions configures Scan
+type ScanOptions struct {
+	// Prefix limits the scan to the keys starting with it
+	Prefix string
+	// Values fetches every key's value along with it
+	Values bool
+	// PageSize is how many keys are asked of a node at once; the node's
+	// default if zero
+	PageSize int
+	// Parallelism is how many nodes are listed at once;
+	// DefaultScanParallelism if zero
+	Parallelism int
+}
+
+// Item is a key found by Scan
+type Item struct {
+	Key string
+	// Value is set with ScanOptions.Values
+	Value []byte
+	// Node is the node that owns the key
+	Node Node
+}
+
+// Nodes returns the nodes of the ring in ring order, from the node lookups
+// go through, by following successor pointers
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	err := c.retry(ctx, func(ctx context.Context, hint string) error {
+		var err error
+		nodes, err = c.walk(ctx)
+		return err
+	})
+	return nodes, err
+}
+
+// walk follows successor pointers from the entry node until it is back at
+// the first node
+func (c *Client) walk(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	visited := make(map[string]bool)
+	address := c.entryAddr()
+	for len(nodes) < maxRingNodes {
+		if visited[address] {
+			return nodes, nil
+		}
+		visited[address] = true
+
+		resp, err := c.info(ctx, address)
+		if err != nil {
+			if len(nodes) == 0 {
+				c.skipEntry(address)
+			}
+			return nil, err
+		}
+		nodes = append(nodes, Node{ID: resp.Node.GetId(), Address: address})
+		if resp.Successor == nil {
+			return nil, &nodeError{address: address, err: errors.New("no successor")}
+		}
+		address = resp.Successor.Address
+	}
+	return nil, fmt.Errorf("walk of the ring did not close after %d nodes", maxRingNodes)
+}
+
+// info asks the node at address for its routing state
+func (c *Client) info(ctx context.Context, address string) (*pb.GetInfoResponse, error) {
+	client, err := c.client(address)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := client.GetInfo(ctx, &pb.GetInfoRequest{})
+	if err != nil {
+		return nil, &nodeError{address: address, err: err}
+	}
+	return resp, nil
+}
+
+// Scan calls fn for every key of the ring starting with the prefix of
+// opts, asking every node for the keys it owns. Nodes are listed several at
+// once, each in key order, and fn is called from one goroutine at a time.
+// Scan stops at the first error of fn or of a node. It is no snapshot: a
+// key written during the scan may or may not be found, and one whose range
+// moves between nodes meanwhile may be found twice or missed.
+func (c *Client) Scan(ctx context.Context, opts ScanOptions, fn func(Item) error) error {
+	nodes, err := c.Nodes(ctx)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultScanParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	emit := func(items []Item) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return firstErr
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				firstErr = err
+				cancel()
+				return err
+			}
+		}
+		return nil
+	}
+
+	slots := make(chan struct{}, parallelism)
+	for _, node := range nodes {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(node Node) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := c.scanNode(ctx, node, opts, emit); err != nil {
+				fail(err)
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// scanNode pages through the keys node owns, passing every page to emit
+func (c *Client) scanNode(ctx context.Context, node Node, opts ScanOptions, emit func([]Item) error) error {
+	client, err := c.client(node.Address)
+	if err != nil {
+		return err
+	}
+	for cursor := ""; ; {
+		var resp *pb.ListKeysResponse
+		err := c.retry(ctx, func(ctx context.Context, hint string) error {
+			rpcCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+			defer cancel()
+
+			var err error
+			resp, err = client.ListKeys(rpcCtx, &pb.ListKeysRequest{
+				Prefix: opts.Prefix,
+				Cursor: cursor,
+				Limit:  int32(opts.PageSize),
+				Values: opts.Values,
+			})
+			if err != nil {
+				return &nodeError{address: node.Address, err: err}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("list keys failed: %w", err)
+		}
+
+		items := make([]Item, len(resp.Items))
+		for i, kv := range resp.Items {
+			items[i] = Item{Key: kv.Key, Node: node}
+			if opts.Values {
+				items[i].Value = kv.Value
+			}
+		}
+		if err := emit(items); err != nil {
+			return err
+		}
+		if resp.NextCursor == "" {
+			return nil
+		}
+		cursor = resp.NextCursor
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "chord-dht/api/chord/v1"
+)
+
+func (f *fakeNode) GetInfo(ctx context.Context, req *pb.GetInfoRequest) (*pb.GetInfoResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	successor := f.successor
+	if successor == "" {
+		successor = f.addr
+	}
+	return &pb.GetInfoResponse{
+		Node:      &pb.Node{Id: f.addr, Address: f.addr},
+		Successor: &pb.Node{Id: successor, Address: successor},
+		Success:   true,
+	}, nil
+}
+
+// ListKeys pages through the stored keys with the last key as cursor
+func (f *fakeNode) ListKeys(ctx context.Context, req *pb.ListKeysRequest) (*pb.ListKeysResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.data {
+		if strings.HasPrefix(key, req.Prefix) && key > req.Cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	resp := &pb.ListKeysResponse{}
+	if req.Limit > 0 && len(keys) > int(req.Limit) {
+		keys = keys[:req.Limit]
+		resp.NextCursor = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		item := &pb.KeyValue{Key: key}
+		if req.Values {
+			item.Value = f.data[key]
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+func TestScan(t *testing.T) {
+	nodes := []*fakeNode{startFake(t), startFake(t), startFake(t)}
+	for i, node := range nodes {
+		node.successor = nodes[(i+1)%len(nodes)].addr
+	}
+	nodes[0].data = map[string][]byte{"users/a": []byte("1"), "users/b": []byte("2"), "other": []byte("3")}
+	nodes[1].data = map[string][]byte{"users/c": []byte("4")}
+	nodes[2].data = map[string][]byte{"users/d": []byte("5"), "users/e": []byte("6"), "users/f": []byte("7")}
+	c := dial(t, nodes[1].addr)
+	ctx := context.Background()
+
+	ring, err := c.Nodes(ctx)
+	if err != nil || len(ring) != 3 || ring[0].Address != nodes[1].addr || ring[1].Address != nodes[2].addr {
+		t.Fatalf("Nodes returned %v, %v", ring, err)
+	}
+
+	var mu sync.Mutex
+	found := make(map[string]string)
+	err = c.Scan(ctx, ScanOptions{Prefix: "users/", Values: true, PageSize: 2}, func(item Item) error {
+		mu.Lock()
+		defer mu.Unlock()
+		found[item.Key] = string(item.Value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	want := map[string]string{"users/a": "1", "users/b": "2", "users/c": "4", "users/d": "5", "users/e": "6", "users/f": "7"}
+	if len(found) != len(want) {
+		t.Errorf("Scan found %v, expected %v", found, want)
+	}
+	for key, value := range want {
+		if found[key] != value {
+			t.Errorf("Scan found %q for %s, expected %q", found[key], key, value)
+		}
+	}
+
+	// An error of fn stops the scan
+	stop := errors.New("stop")
+	calls := 0
+	err = c.Scan(ctx, ScanOptions{Parallelism: 1}, func(item Item) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Scan returned %v after %d calls, expected stop after 1", err, calls)
+	}
+}
@@ -0,0 +1,3 @@
+module chord-dht/pkg/hash
+
+go 1.24.0
@@ -1,8 +1,13 @@
 package hash
 
 import (
+	"bytes"
 	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"strconv"
 )
@@ -12,33 +17,34 @@ const (
 	M = 160
 	// MaxNodes is the maximum number of nodes in the hash ring (2^M)
 	MaxNodes = 1 << M
+	// Size is the length of a hash in bytes
+	Size = M / 8
 )
 
-// Hash represents a position on the Chord hash ring
+// ringSize is 2^M, the number of positions on the ring
+var ringSize = new(big.Int).Lsh(big.NewInt(1), M)
+
+// Hash represents a position on the Chord hash ring as a big-endian M-bit
+// number. Ring arithmetic works on the bytes directly, so comparisons and
+// distances do not allocate. The zero Hash is position 0.
 type Hash struct {
-	value *big.Int
+	value [Size]byte
 }
 
-// NewHash creates a new Hash from a big.Int value
+// NewHash creates a new Hash from a big.Int value, reduced modulo 2^M
 func NewHash(value *big.Int) *Hash {
+	h := &Hash{}
 	if value == nil {
-		value = big.NewInt(0)
+		return h
 	}
 	// Ensure the value is within the hash ring bounds
-	maxValue := new(big.Int).Lsh(big.NewInt(1), M) // 2^M
-	value.Mod(value, maxValue)
-	return &Hash{value: new(big.Int).Set(value)}
+	new(big.Int).Mod(value, ringSize).FillBytes(h.value[:])
+	return h
 }
 
 // NewHashFromString creates a new Hash by hashing a string
 func NewHashFromString(s string) *Hash {
-	hasher := sha1.New()
-	hasher.Write([]byte(s))
-	hashBytes := hasher.Sum(nil)
-	
-	// Convert bytes to big.Int
-	value := new(big.Int).SetBytes(hashBytes)
-	return NewHash(value)
+	return &Hash{value: sha1.Sum([]byte(s))}
 }
 
 // NewHashFromHex creates a new Hash from a hex string
@@ -51,35 +57,109 @@ func NewHashFromHex(hexStr string) (*Hash, error) {
 	return NewHash(value), nil
 }
 
-// String returns the hex representation of the hash
+// ShortLen is the number of hex digits Short returns
+const ShortLen = 8
+
+// String returns the hex representation of the hash, zero-padded to 2*Size
+// digits so that IDs have a constant width and sort like their values
 func (h *Hash) String() string {
-	return h.value.Text(16)
+	text, _ := h.MarshalText()
+	return string(text)
+}
+
+// Short returns the first ShortLen hex digits of the hash, for logs
+func (h *Hash) Short() string {
+	return h.String()[:ShortLen]
 }
 
-// Bytes returns the byte representation of the hash
+// Bytes returns the big-endian byte representation of the hash without
+// leading zeros, like big.Int.Bytes
 func (h *Hash) Bytes() []byte {
-	return h.value.Bytes()
+	i := 0
+	for i < Size && h.value[i] == 0 {
+		i++
+	}
+	return append([]byte{}, h.value[i:]...)
+}
+
+// MarshalBinary encodes the hash as Size big-endian bytes
+func (h *Hash) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, h.value[:]...), nil
+}
+
+// UnmarshalBinary decodes a hash encoded by MarshalBinary
+func (h *Hash) UnmarshalBinary(data []byte) error {
+	if len(data) != Size {
+		return fmt.Errorf("invalid hash length: %d bytes, expected %d", len(data), Size)
+	}
+	copy(h.value[:], data)
+	return nil
+}
+
+// MarshalText encodes the hash as 2*Size lowercase hex digits, zero-padded
+func (h *Hash) MarshalText() ([]byte, error) {
+	text := make([]byte, hex.EncodedLen(Size))
+	hex.Encode(text, h.value[:])
+	return text, nil
+}
+
+// UnmarshalText decodes a hex hash. Shorter forms without the leading zeros,
+// as printed by String, are accepted too.
+func (h *Hash) UnmarshalText(text []byte) error {
+	if len(text) == 0 || len(text) > hex.EncodedLen(Size) {
+		return fmt.Errorf("invalid hash length: %d hex digits", len(text))
+	}
+	value, ok := new(big.Int).SetString(string(text), 16)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("invalid hex string: %s", text)
+	}
+	value.FillBytes(h.value[:])
+	return nil
+}
+
+// MarshalJSON encodes the hash as a JSON string of its MarshalText form
+func (h *Hash) MarshalJSON() ([]byte, error) {
+	text, _ := h.MarshalText()
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes a JSON string with UnmarshalText
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid hash: %w", err)
+	}
+	return h.UnmarshalText([]byte(text))
 }
 
-// BigInt returns a copy of the underlying big.Int
+// BigInt returns the hash as a big.Int
 func (h *Hash) BigInt() *big.Int {
-	return new(big.Int).Set(h.value)
+	return new(big.Int).SetBytes(h.value[:])
 }
 
 // Add returns a new Hash that is the sum of this hash and the given value
 func (h *Hash) Add(value *big.Int) *Hash {
-	result := new(big.Int).Add(h.value, value)
+	result := new(big.Int).Add(h.BigInt(), value)
 	return NewHash(result)
 }
 
 // AddPowerOfTwo returns a new Hash that is this hash + 2^i (used for finger table)
 func (h *Hash) AddPowerOfTwo(i int) *Hash {
+	result := &Hash{value: h.value}
 	if i < 0 || i >= M {
-		return NewHash(new(big.Int).Set(h.value))
+		return result
 	}
 	
-	powerOfTwo := new(big.Int).Lsh(big.NewInt(1), uint(i)) // 2^i
-	return h.Add(powerOfTwo)
+	// Add the bit at its byte and carry towards the most significant byte;
+	// a carry out of the top byte wraps around the ring
+	index := Size - 1 - i/8
+	carry := uint16(1) << (i % 8)
+	for ; index >= 0 && carry > 0; index-- {
+		sum := uint16(result.value[index]) + carry
+		result.value[index] = byte(sum)
+		carry = sum >> 8
+	}
+	return result
 }
 
 // Equal checks if two hashes are equal
@@ -87,7 +167,7 @@ func (h *Hash) Equal(other *Hash) bool {
 	if other == nil {
 		return false
 	}
-	return h.value.Cmp(other.value) == 0
+	return h.value == other.value
 }
 
 // Less checks if this hash is less than the other hash
@@ -95,7 +175,24 @@ func (h *Hash) Less(other *Hash) bool {
 	if other == nil {
 		return false
 	}
-	return h.value.Cmp(other.value) < 0
+	return bytes.Compare(h.value[:], other.value[:]) < 0
+}
+
+// distance returns target - h modulo 2^M: subtracting with a borrow out of
+// the top byte wraps around the ring by itself
+func (h *Hash) distance(target *Hash) [Size]byte {
+	var result [Size]byte
+	borrow := 0
+	for i := Size - 1; i >= 0; i-- {
+		diff := int(target.value[i]) - int(h.value[i]) - borrow
+		borrow = 0
+		if diff < 0 {
+			diff += 256
+			borrow = 1
+		}
+		result[i] = byte(diff)
+	}
+	return result
 }
 
 // Distance calculates the clockwise distance from this hash to the target hash
@@ -103,16 +200,20 @@ func (h *Hash) Distance(target *Hash) *big.Int {
 	if target == nil {
 		return big.NewInt(0)
 	}
-	
-	distance := new(big.Int).Sub(target.value, h.value)
-	maxValue := new(big.Int).Lsh(big.NewInt(1), M) // 2^M
-	
-	// If distance is negative, wrap around the ring
-	if distance.Sign() < 0 {
-		distance.Add(distance, maxValue)
+	distance := h.distance(target)
+	return new(big.Int).SetBytes(distance[:])
+}
+
+// RingFraction returns the clockwise distance from this hash to the target
+// as a fraction of the whole ring, in [0, 1)
+func (h *Hash) RingFraction(target *Hash) float64 {
+	if target == nil {
+		return 0
 	}
-	
-	return distance
+	// A float64 holds 53 significant bits, so the top 64 bits of the
+	// distance are all that matter
+	distance := h.distance(target)
+	return float64(binary.BigEndian.Uint64(distance[:8])) / math.Exp2(64)
 }
 
 // InRange checks if this hash is in the range (start, end] on the hash ring
@@ -159,7 +260,7 @@ func (h *Hash) InRangeExclusive(start, end *Hash) bool {
 
 // Copy creates a copy of the hash
 func (h *Hash) Copy() *Hash {
-	return NewHash(new(big.Int).Set(h.value))
+	return &Hash{value: h.value}
 }
 
 // GenerateID generates a unique ID for a node based on its address
@@ -1,7 +1,9 @@
 package hash
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
 )
@@ -126,6 +128,23 @@ func TestHashDistance(t *testing.T) {
 	}
 }
 
+func TestHashRingFraction(t *testing.T) {
+	zero := NewHash(big.NewInt(0))
+	half := NewHash(new(big.Int).Lsh(big.NewInt(1), M-1))
+	
+	if f := zero.RingFraction(half); f != 0.5 {
+		t.Errorf("Fraction from 0 to 2^(M-1) should be 0.5, got %f", f)
+	}
+	
+	if f := half.RingFraction(zero); f != 0.5 {
+		t.Errorf("Wrap-around fraction should be 0.5, got %f", f)
+	}
+	
+	if f := half.RingFraction(half); f != 0 {
+		t.Errorf("Fraction to self should be 0, got %f", f)
+	}
+}
+
 func TestHashInRange(t *testing.T) {
 	// Test normal range (start < end)
 	start := NewHash(big.NewInt(100))
@@ -312,6 +331,154 @@ func TestHashRingProperties(t *testing.T) {
 	}
 }
 
+func TestHashString(t *testing.T) {
+	small := NewHash(big.NewInt(0xabc))
+	if expected := "0000000000000000000000000000000000000abc"; small.String() != expected {
+		t.Errorf("String() = %s, expected %s", small.String(), expected)
+	}
+	if small.Short() != "00000000" {
+		t.Errorf("Short() = %s, expected 00000000", small.Short())
+	}
+	
+	// Fixed-width strings sort like the values they encode
+	large := NewHashFromString("chord")
+	if len(large.String()) != 2*Size {
+		t.Errorf("String() of %s has %d digits, expected %d", large, len(large.String()), 2*Size)
+	}
+	if (small.String() < large.String()) != small.Less(large) {
+		t.Errorf("String order of %s and %s differs from value order", small, large)
+	}
+	if large.Short() != large.String()[:ShortLen] {
+		t.Errorf("Short() = %s, expected prefix of %s", large.Short(), large)
+	}
+}
+
+func TestHashArithmeticMatchesBigInt(t *testing.T) {
+	ringSize := new(big.Int).Lsh(big.NewInt(1), M)
+	maxHash := NewHash(new(big.Int).Sub(ringSize, big.NewInt(1)))
+	hashes := []*Hash{NewHash(big.NewInt(0)), NewHash(big.NewInt(255)), maxHash}
+	for i := 0; i < 20; i++ {
+		hashes = append(hashes, NewHashFromString(fmt.Sprintf("node-%d", i)))
+	}
+	
+	for _, a := range hashes {
+		for _, i := range []int{0, 7, 8, 63, 100, M - 1} {
+			expected := new(big.Int).Add(a.BigInt(), new(big.Int).Lsh(big.NewInt(1), uint(i)))
+			expected.Mod(expected, ringSize)
+			if got := a.AddPowerOfTwo(i).BigInt(); got.Cmp(expected) != 0 {
+				t.Errorf("%s + 2^%d = %x, expected %x", a, i, got, expected)
+			}
+		}
+		for _, b := range hashes {
+			expected := new(big.Int).Sub(b.BigInt(), a.BigInt())
+			expected.Mod(expected, ringSize)
+			if got := a.Distance(b); got.Cmp(expected) != 0 {
+				t.Errorf("Distance(%s, %s) = %x, expected %x", a, b, got, expected)
+			}
+			
+			fraction, _ := new(big.Float).Quo(new(big.Float).SetInt(expected), new(big.Float).SetInt(ringSize)).Float64()
+			if got := a.RingFraction(b); math.Abs(got-fraction) > 1e-15 {
+				t.Errorf("RingFraction(%s, %s) = %v, expected %v", a, b, got, fraction)
+			}
+			if a.Less(b) != (a.BigInt().Cmp(b.BigInt()) < 0) {
+				t.Errorf("Less(%s, %s) disagrees with big.Int", a, b)
+			}
+		}
+	}
+}
+
+func TestHashBinaryMarshaling(t *testing.T) {
+	for _, h := range []*Hash{NewHash(big.NewInt(0)), NewHash(big.NewInt(1)), NewHashFromString("chord")} {
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary(%s) failed: %v", h, err)
+		}
+		if len(data) != Size {
+			t.Errorf("MarshalBinary(%s) = %d bytes, expected %d", h, len(data), Size)
+		}
+		
+		var decoded Hash
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary(%x) failed: %v", data, err)
+		}
+		if !decoded.Equal(h) {
+			t.Errorf("binary round trip of %s gave %s", h, &decoded)
+		}
+	}
+	
+	var h Hash
+	if err := h.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Error("UnmarshalBinary should reject short input")
+	}
+}
+
+func TestHashTextMarshaling(t *testing.T) {
+	h := NewHash(big.NewInt(255))
+	text, err := h.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText failed: %v", err)
+	}
+	if expected := "00000000000000000000000000000000000000ff"; string(text) != expected {
+		t.Errorf("MarshalText = %s, expected %s", text, expected)
+	}
+	
+	tests := []struct {
+		input     string
+		shouldErr bool
+	}{
+		{"00000000000000000000000000000000000000ff", false},
+		{"ff", false},
+		{"FF", false},
+		{"", true},
+		{"-ff", true},
+		{"xyz", true},
+		{"100000000000000000000000000000000000000000", true}, // 41 digits
+	}
+	for _, test := range tests {
+		var decoded Hash
+		err := decoded.UnmarshalText([]byte(test.input))
+		if test.shouldErr {
+			if err == nil {
+				t.Errorf("UnmarshalText(%q) should have failed", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnmarshalText(%q) failed: %v", test.input, err)
+		} else if !decoded.Equal(h) {
+			t.Errorf("UnmarshalText(%q) = %s, expected %s", test.input, &decoded, h)
+		}
+	}
+}
+
+func TestHashJSONMarshaling(t *testing.T) {
+	type node struct {
+		ID      *Hash  `json:"id"`
+		Address string `json:"address"`
+	}
+	
+	original := node{ID: NewHash(big.NewInt(1)), Address: "localhost:8000"}
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if expected := `{"id":"0000000000000000000000000000000000000001","address":"localhost:8000"}`; string(data) != expected {
+		t.Errorf("Marshal = %s, expected %s", data, expected)
+	}
+	
+	var decoded node
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !decoded.ID.Equal(original.ID) {
+		t.Errorf("JSON round trip gave %s, expected %s", decoded.ID, original.ID)
+	}
+	
+	if err := json.Unmarshal([]byte(`{"id":42}`), &decoded); err == nil {
+		t.Error("Unmarshal should reject a non-string ID")
+	}
+}
+
 // Benchmark tests
 func BenchmarkNewHashFromString(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -338,4 +505,32 @@ func BenchmarkHashInRange(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		test.InRange(start, end)
 	}
-}
\ No newline at end of file
+}
+func BenchmarkHashLess(b *testing.B) {
+	hash1 := NewHashFromString("hash1")
+	hash2 := NewHashFromString("hash2")
+	
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash1.Less(hash2)
+	}
+}
+
+func BenchmarkFingerStart(b *testing.B) {
+	id := NewHashFromString("node")
+	
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FingerStart(id, i%M+1)
+	}
+}
+
+func BenchmarkHashRingFraction(b *testing.B) {
+	hash1 := NewHashFromString("hash1")
+	hash2 := NewHashFromString("hash2")
+	
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash1.RingFraction(hash2)
+	}
+}
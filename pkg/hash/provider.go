@@ -0,0 +1,48 @@
+package hash
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Provider maps keys to positions on the ring. Every node of a ring must use
+// the same provider, or they disagree on which node owns a key.
+type Provider interface {
+	// Hash returns the position of key
+	Hash(key string) *Hash
+}
+
+// ProviderFunc adapts a function to a Provider
+type ProviderFunc func(key string) *Hash
+
+// Hash implements Provider
+func (f ProviderFunc) Hash(key string) *Hash {
+	return f(key)
+}
+
+// SHA1 places keys at their SHA-1 hash, spreading them evenly over the ring.
+// It is the default.
+var SHA1 Provider = ProviderFunc(NewHashFromString)
+
+// Identity places keys at the position they name, so tests of routing and
+// ownership can assert exact placements. A key ending in a decimal number is
+// placed at that number: "42", "key-42" and "localhost:42" are all at
+// position 42. Any other key is placed at its last Size bytes read as a
+// big-endian number, so "a" is at 0x61.
+var Identity Provider = ProviderFunc(identity)
+
+// identity implements Identity
+func identity(key string) *Hash {
+	digits := len(key) - len(strings.TrimRight(key, "0123456789"))
+	if digits > 0 {
+		value, _ := new(big.Int).SetString(key[len(key)-digits:], 10)
+		return NewHash(value)
+	}
+
+	h := &Hash{}
+	if len(key) > Size {
+		key = key[len(key)-Size:]
+	}
+	copy(h.value[Size-len(key):], key)
+	return h
+}
@@ -0,0 +1,38 @@
+package hash
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIdentityProvider(t *testing.T) {
+	tests := []struct {
+		key  string
+		want *Hash
+	}{
+		{"0", NewHash(big.NewInt(0))},
+		{"42", NewHash(big.NewInt(42))},
+		{"key-42", NewHash(big.NewInt(42))},
+		{"localhost:8001", NewHash(big.NewInt(8001))},
+		{"a", NewHash(big.NewInt(0x61))},
+		{"", NewHash(big.NewInt(0))},
+		// Numbers beyond the ring wrap around it
+		{ringSize.String(), NewHash(big.NewInt(0))},
+	}
+	for _, tt := range tests {
+		if got := Identity.Hash(tt.key); !got.Equal(tt.want) {
+			t.Errorf("Identity.Hash(%q) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+
+	long := "abcdefghijklmnopqrstuvwxyz"
+	if got, want := Identity.Hash(long), Identity.Hash(long[len(long)-Size:]); !got.Equal(want) {
+		t.Errorf("Expected a long key to be placed by its last %d bytes, got %s, want %s", Size, got, want)
+	}
+}
+
+func TestSHA1Provider(t *testing.T) {
+	if got, want := SHA1.Hash("key"), NewHashFromString("key"); !got.Equal(want) {
+		t.Errorf("SHA1.Hash = %s, want %s", got, want)
+	}
+}
@@ -57,7 +57,7 @@ echo "🔌 Testing local node connectivity:"
 if command -v grpcurl >/dev/null 2>&1; then
     for port in 8000 8001 8002; do
         echo -n "Port $port: "
-        if timeout 3 grpcurl -plaintext -d '{}' localhost:$port proto.ChordService/GetInfo >/dev/null 2>&1; then
+        if timeout 3 grpcurl -plaintext -d '{}' localhost:$port chord.v1.ChordService/GetInfo >/dev/null 2>&1; then
             echo "✅ Responding"
         else
             echo "❌ Not responding"
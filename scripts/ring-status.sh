@@ -44,13 +44,13 @@ for node in "${NODES[@]}"; do
     # Ping test
     if timeout 5 grpcurl -plaintext -connect-timeout 3 \
         -d '{"requester":{"id":"monitor","address":"localhost:8000"}}' \
-        "$node" proto.ChordService/Ping >/dev/null 2>&1; then
+        "$node" chord.v1.ChordService/Ping >/dev/null 2>&1; then
         
         echo "✅ Status: ONLINE"
         
         # Get node info
         node_info=$(timeout 5 grpcurl -plaintext -connect-timeout 3 -d '{}' \
-            "$node" proto.ChordService/GetInfo 2>/dev/null)
+            "$node" chord.v1.ChordService/GetInfo 2>/dev/null)
         
         if [ $? -eq 0 ] && [ -n "$node_info" ]; then
             # Parsear la información (sin jq para máxima compatibilidad)
@@ -97,7 +97,7 @@ for node in "${NODES[@]}"; do
     if command -v grpcurl >/dev/null 2>&1 && \
        timeout 3 grpcurl -plaintext -connect-timeout 2 \
        -d '{"requester":{"id":"monitor","address":"localhost:8000"}}' \
-       "$node" proto.ChordService/Ping >/dev/null 2>&1; then
+       "$node" chord.v1.ChordService/Ping >/dev/null 2>&1; then
         ((online_count++))
     fi
 done
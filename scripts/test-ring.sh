@@ -41,7 +41,7 @@ online_nodes=()
 for node in "${TEST_NODES[@]}"; do
     if timeout 3 grpcurl -plaintext -connect-timeout 2 \
        -d '{"requester":{"id":"test","address":"localhost:8000"}}' \
-       "$node" proto.ChordService/Ping >/dev/null 2>&1; then
+       "$node" chord.v1.ChordService/Ping >/dev/null 2>&1; then
         online_nodes+=("$node")
     fi
 done
@@ -60,7 +60,7 @@ for node in "${online_nodes[@]}"; do
     echo -n "  Pinging $node: "
     if timeout 5 grpcurl -plaintext -connect-timeout 3 \
        -d '{"requester":{"id":"tester","address":"localhost:8000"}}' \
-       "$node" proto.ChordService/Ping >/dev/null 2>&1; then
+       "$node" chord.v1.ChordService/Ping >/dev/null 2>&1; then
         echo "✅ Success"
     else
         echo "❌ Failed"
@@ -73,7 +73,7 @@ echo "📋 Test 2: Get node information"
 for node in "${online_nodes[@]}"; do
     echo "  Node $node:"
     node_info=$(timeout 5 grpcurl -plaintext -connect-timeout 3 -d '{}' \
-        "$node" proto.ChordService/GetInfo 2>/dev/null)
+        "$node" chord.v1.ChordService/GetInfo 2>/dev/null)
     
     if [ $? -eq 0 ] && [ -n "$node_info" ]; then
         node_id=$(echo "$node_info" | grep -o '"id"[[:space:]]*:[[:space:]]*"[^"]*"' | cut -d'"' -f4 | head -1)
@@ -100,7 +100,7 @@ for key in "${test_keys[@]}"; do
     
     lookup_result=$(timeout 5 grpcurl -plaintext -connect-timeout 3 \
         -d "{\"key\":\"$key_hash\",\"requester\":{\"id\":\"tester\",\"address\":\"localhost:8000\"}}" \
-        "$test_node" proto.ChordService/FindSuccessor 2>/dev/null)
+        "$test_node" chord.v1.ChordService/FindSuccessor 2>/dev/null)
     
     if [ $? -eq 0 ] && [ -n "$lookup_result" ]; then
         # Check if successful
@@ -131,7 +131,7 @@ test_key=$(generate_hash "testkey123")
 echo -n "  Finding closest preceding finger for key ${test_key:0:8}...: "
 cpf_result=$(timeout 5 grpcurl -plaintext -connect-timeout 3 \
     -d "{\"key\":\"$test_key\"}" \
-    "$test_node" proto.ChordService/ClosestPrecedingFinger 2>/dev/null)
+    "$test_node" chord.v1.ChordService/ClosestPrecedingFinger 2>/dev/null)
 
 if [ $? -eq 0 ] && [ -n "$cpf_result" ]; then
     if echo "$cpf_result" | grep -q '"success"[[:space:]]*:[[:space:]]*true'; then
@@ -152,7 +152,7 @@ declare -A node_predecessors
 
 for node in "${online_nodes[@]}"; do
     node_info=$(timeout 5 grpcurl -plaintext -connect-timeout 3 -d '{}' \
-        "$node" proto.ChordService/GetInfo 2>/dev/null)
+        "$node" chord.v1.ChordService/GetInfo 2>/dev/null)
     
     if [ $? -eq 0 ] && [ -n "$node_info" ]; then
         node_id=$(echo "$node_info" | grep -o '"id"[[:space:]]*:[[:space:]]*"[^"]*"' | cut -d'"' -f4 | head -1)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -13,6 +15,10 @@ import (
 	"chord-dht/pkg/hash"
 )
 
+// hopFactor is the constant c in the bound on the average lookup path,
+// c·log2(N) hops
+const hopFactor = 2
+
 // TestIntegrationBasicRing tests basic ring formation and lookups
 func TestIntegrationBasicRing(t *testing.T) {
 	if testing.Short() {
@@ -70,12 +76,11 @@ func TestIntegrationBasicRing(t *testing.T) {
 			continue
 		}
 		log.Printf("Node %d joined ring", i)
-		time.Sleep(200 * time.Millisecond) // Small delay between joins
 	}
 
 	// Wait for stabilization
 	log.Printf("Waiting for ring stabilization...")
-	time.Sleep(15 * time.Second)
+	waitForRing(t, nodes)
 
 	// Verify ring structure
 	log.Printf("Verifying ring structure...")
@@ -89,7 +94,7 @@ func TestIntegrationBasicRing(t *testing.T) {
 
 		log.Printf("Node %d: ID=%s, Succ=%s, Pred=%s",
 			i,
-			node.GetID().String()[:8],
+			node.GetID().Short(),
 			getNodeIDString(successor),
 			getNodeIDString(predecessor))
 	}
@@ -111,13 +116,15 @@ func TestIntegrationLookupPerformance(t *testing.T) {
 	defer cleanupTestRing(nodes)
 
 	// Wait for stabilization
-	time.Sleep(20 * time.Second)
+	waitForRing(t, nodes)
 
 	// Perform many lookups
 	lookupCount := 50
 	var totalLatency time.Duration
+	var totalHops int
 	var lookupWg sync.WaitGroup
 	latencyChan := make(chan time.Duration, lookupCount)
+	hopsChan := make(chan int, lookupCount)
 
 	log.Printf("Performing %d lookups...", lookupCount)
 
@@ -136,25 +143,33 @@ func TestIntegrationLookupPerformance(t *testing.T) {
 
 			startTime := time.Now()
 			
-			// For now, just get successor (in full implementation, would call FindSuccessor)
-			successor := node.GetSuccessor()
+			owner, hops, err := node.LookupHops(keyHash)
 			
 			latency := time.Since(startTime)
+			if err != nil {
+				t.Errorf("Lookup %d failed: %v", lookupID, err)
+				return
+			}
 			latencyChan <- latency
+			hopsChan <- hops
 
-			if successor == nil {
-				t.Errorf("Lookup %d failed: no successor found", lookupID)
+			if expected := expectedOwner(nodes, keyHash); owner.Address != expected.Address {
+				t.Errorf("Lookup %d: expected owner %s, got %s", lookupID, expected.Address, owner.Address)
 			}
 
 			if lookupID%10 == 0 {
-				log.Printf("Lookup %d: key=%s, latency=%v", 
-					lookupID, keyHash.String()[:8], latency)
+				log.Printf("Lookup %d: key=%s, hops=%d, latency=%v", 
+					lookupID, keyHash.Short(), hops, latency)
 			}
 		}(i)
 	}
 
 	lookupWg.Wait()
 	close(latencyChan)
+	close(hopsChan)
+	for hops := range hopsChan {
+		totalHops += hops
+	}
 
 	// Calculate statistics
 	lookupCount = 0
@@ -172,6 +187,14 @@ func TestIntegrationLookupPerformance(t *testing.T) {
 		if avgLatency > time.Second {
 			t.Errorf("Average lookup latency too high: %v", avgLatency)
 		}
+
+		// Chord routes in O(log N) hops
+		avgHops := float64(totalHops) / float64(lookupCount)
+		bound := hopFactor * math.Log2(float64(nodeCount))
+		log.Printf("Lookup path: avg %.2f hops, bound %.2f", avgHops, bound)
+		if avgHops > bound {
+			t.Errorf("Average lookup path of %.2f hops exceeds %d·log2(%d) = %.2f", avgHops, hopFactor, nodeCount, bound)
+		}
 	}
 }
 
@@ -187,7 +210,7 @@ func TestIntegrationNodeFailure(t *testing.T) {
 	defer cleanupTestRing(nodes)
 
 	// Wait for initial stabilization
-	time.Sleep(15 * time.Second)
+	waitForRing(t, nodes)
 
 	log.Printf("Initial ring established")
 
@@ -197,16 +220,12 @@ func TestIntegrationNodeFailure(t *testing.T) {
 	nodes[failedNodeIdx].Stop()
 	nodes[failedNodeIdx] = nil
 
-	// Wait for failure detection and recovery
-	time.Sleep(30 * time.Second)
-
 	// Verify remaining nodes can still perform lookups
-	log.Printf("Testing lookups after node failure...")
 	activeNodes := make([]*chord.Node, 0, len(nodes)-1)
 	for i, node := range nodes {
 		if node != nil {
 			activeNodes = append(activeNodes, node)
-			log.Printf("Active node %d: ID=%s", i, node.GetID().String()[:8])
+			log.Printf("Active node %d: ID=%s", i, node.GetID().Short())
 		}
 	}
 
@@ -214,15 +233,24 @@ func TestIntegrationNodeFailure(t *testing.T) {
 		t.Fatal("No active nodes remaining")
 	}
 
+	// Wait for failure detection and recovery
+	waitForRing(t, activeNodes)
+	log.Printf("Testing lookups after node failure...")
+
 	// Perform lookups on remaining nodes
 	for i := 0; i < 10; i++ {
 		key := fmt.Sprintf("recovery_key_%d", i)
 		nodeIdx := rand.Intn(len(activeNodes))
 		node := activeNodes[nodeIdx]
 
-		successor := node.GetSuccessor()
-		if successor == nil {
-			t.Errorf("Lookup failed after node failure")
+		keyHash := hash.NewHashFromString(key)
+		owner, _, err := node.LookupHops(keyHash)
+		if err != nil {
+			t.Errorf("Lookup of %s failed after node failure: %v", key, err)
+			continue
+		}
+		if expected := expectedOwner(activeNodes, keyHash); owner.Address != expected.Address {
+			t.Errorf("Lookup of %s: expected owner %s, got %s", key, expected.Address, owner.Address)
 		}
 	}
 
@@ -273,6 +301,32 @@ func setupTestRing(t *testing.T, nodeCount, basePort int) []*chord.Node {
 	return nodes
 }
 
+// ringTimeout bounds the wait for a ring to stabilize
+const ringTimeout = 90 * time.Second
+
+// waitForRing waits until the successor of every node is the next node on
+// the ring, failing the test after ringTimeout
+func waitForRing(t *testing.T, nodes []*chord.Node) {
+	deadline := time.Now().Add(ringTimeout)
+	for {
+		settled := true
+		for _, node := range nodes {
+			expected := expectedOwner(nodes, node.GetID().AddPowerOfTwo(0))
+			if successor := node.GetSuccessor(); successor == nil || successor.Address != expected.Address {
+				settled = false
+				break
+			}
+		}
+		if settled {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Ring did not stabilize within %v", ringTimeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
 func cleanupTestRing(nodes []*chord.Node) {
 	for i, node := range nodes {
 		if node != nil {
@@ -294,23 +348,43 @@ func performLookupTests(t *testing.T, nodes []*chord.Node) {
 		nodeIdx := rand.Intn(len(nodes))
 		node := nodes[nodeIdx]
 		
-		// Perform lookup (simplified - just check successor)
-		successor := node.GetSuccessor()
-		if successor == nil {
-			t.Errorf("Lookup %d failed: no successor", i)
+		owner, hops, err := node.LookupHops(keyHash)
+		if err != nil {
+			t.Errorf("Lookup %d failed: %v", i, err)
 			continue
 		}
+		if expected := expectedOwner(nodes, keyHash); owner.Address != expected.Address {
+			t.Errorf("Lookup %d: expected owner %s, got %s", i, expected.Address, owner.Address)
+		}
 		
-		log.Printf("Lookup %d: key=%s -> successor=%s", 
-			i, keyHash.String()[:8], successor.ID.String()[:8])
+		log.Printf("Lookup %d: key=%s -> owner=%s in %d hops", 
+			i, keyHash.Short(), owner.ID.Short(), hops)
 	}
 }
 
+// expectedOwner returns the node a key belongs to: the first node at or
+// after the key on the ring
+func expectedOwner(nodes []*chord.Node, key *hash.Hash) *chord.NodeInfo {
+	infos := make([]*chord.NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		if node != nil {
+			infos = append(infos, node.GetNodeInfo())
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID.Less(infos[j].ID) })
+	for _, info := range infos {
+		if !info.ID.Less(key) {
+			return info
+		}
+	}
+	return infos[0]
+}
+
 func getNodeIDString(nodeInfo *chord.NodeInfo) string {
 	if nodeInfo == nil {
 		return "nil"
 	}
-	return nodeInfo.ID.String()[:8]
+	return nodeInfo.ID.Short()
 }
 
 // Benchmark tests
@@ -343,7 +417,11 @@ func BenchmarkRingLookup(b *testing.B) {
 	}
 
 	// Wait for stabilization
-	time.Sleep(5 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), ringTimeout)
+	defer cancel()
+	if err := chord.WaitConverged(ctx, nodes, chord.DefaultConvergedRounds); err != nil {
+		b.Fatalf("Ring did not converge: %v", err)
+	}
 
 	b.ResetTimer()
 
@@ -353,10 +431,8 @@ func BenchmarkRingLookup(b *testing.B) {
 		nodeIdx := i % nodeCount
 		node := nodes[nodeIdx]
 		
-		// Simplified lookup
-		successor := node.GetSuccessor()
-		if successor == nil {
-			b.Error("Lookup failed")
+		if _, err := node.Lookup(hash.NewHashFromString(key)); err != nil {
+			b.Errorf("Lookup failed: %v", err)
 		}
 	}
 }
\ No newline at end of file